@@ -1,6 +1,15 @@
 package assertionutil
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mustanish/common-utils/v2/encodingutil"
+	"github.com/mustanish/common-utils/v2/errorutil"
+)
 
 // AssertionClient defines the interface for safe type assertion operations
 type AssertionClient interface {
@@ -13,6 +22,7 @@ type AssertionClient interface {
 	GetSlice(m map[string]any, key string) ([]any, bool)
 	GetStringSlice(m map[string]any, key string) ([]string, bool)
 	GetBool(m map[string]any, key string) (bool, bool)
+	GetBytes(m map[string]any, key string) ([]byte, bool)
 
 	// Integer type getters
 	GetInt(m map[string]any, key string) (int, bool)
@@ -37,16 +47,34 @@ type AssertionClient interface {
 	HasNonEmptyString(m map[string]any, key string) bool
 	ValidateRequired(m map[string]any, keys ...string) error
 	GetKeys(m map[string]any) []string
+
+	// Size getters and guards
+	Len(m map[string]any, key string) (int, bool)
+	RequireMinItems(m map[string]any, key string, minItems int) error
+	RequireMaxItems(m map[string]any, key string, maxItems int) error
+
+	// Schema-driven coercion
+	Normalize(m map[string]any, schema Schema) (map[string]any, NormalizeReport)
 }
 
 // AssertionUtil provides safe type assertion utilities for map[string]any data structures
-type AssertionUtil struct{}
+type AssertionUtil struct {
+	// coerce lets the typed getters parse string values, e.g. "42" for GetInt (see NewCoercingAssertionUtil)
+	coerce bool
+}
 
 // NewAssertionUtil creates a new assertion utility instance
 func NewAssertionUtil() AssertionClient {
 	return &AssertionUtil{}
 }
 
+// NewCoercingAssertionUtil creates an assertion utility whose typed getters also accept strings
+// GetInt, GetInt64, GetFloat64 and GetBool parse them ("8080", "1.5", "yes"), and GetStringSlice splits
+// comma-separated lists, so string-only data such as environment variables reads like a JSON document.
+func NewCoercingAssertionUtil() AssertionClient {
+	return &AssertionUtil{coerce: true}
+}
+
 // GetString safely extracts a non-empty string value from a map
 func (a *AssertionUtil) GetString(m map[string]any, key string) (string, bool) {
 	if val, exists := m[key]; exists {
@@ -63,7 +91,8 @@ func (a *AssertionUtil) GetStringRequired(m map[string]any, key string) (string,
 	if str, ok := a.GetString(m, key); ok {
 		return str, nil
 	}
-	return "", fmt.Errorf("required field '%s' not found or empty", key)
+	return "", newValidationError(ErrRequired, fmt.Sprintf("required field '%s' not found or empty", key),
+		errorutil.FieldError{Field: key, Message: "required field not found or empty"})
 }
 
 // GetStringOrEmpty safely extracts a string value or returns empty string if not found
@@ -80,6 +109,9 @@ func (a *AssertionUtil) GetFloat64(m map[string]any, key string) (float64, bool)
 		if f, ok := val.(float64); ok {
 			return f, true
 		}
+		if f, ok := a.coerced(val, TypeFloat); ok {
+			return f.(float64), true
+		}
 	}
 	return 0, false
 }
@@ -124,6 +156,15 @@ func (a *AssertionUtil) GetStringSlice(m map[string]any, key string) ([]string,
 			}
 			return result, true
 		}
+		if str, ok := val.(string); ok && a.coerce {
+			var result []string
+			for _, item := range strings.Split(str, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					result = append(result, item)
+				}
+			}
+			return result, len(result) > 0
+		}
 	}
 	return nil, false
 }
@@ -134,10 +175,31 @@ func (a *AssertionUtil) GetBool(m map[string]any, key string) (bool, bool) {
 		if b, ok := val.(bool); ok {
 			return b, true
 		}
+		if b, ok := a.coerced(val, TypeBool); ok {
+			return b.(bool), true
+		}
 	}
 	return false, false
 }
 
+// GetBytes safely extracts a non-empty byte slice from a map
+// Handles both []byte and base64 strings, as encoding/json marshals []byte to base64
+func (a *AssertionUtil) GetBytes(m map[string]any, key string) ([]byte, bool) {
+	if val, exists := m[key]; exists {
+		switch v := val.(type) {
+		case []byte:
+			if len(v) > 0 {
+				return v, true
+			}
+		case string:
+			if decoded, err := encodingutil.NewEncodingUtil().DecodeBase64(v); err == nil && len(decoded) > 0 {
+				return decoded, true
+			}
+		}
+	}
+	return nil, false
+}
+
 // GetInt safely extracts an int value from a map
 // Handles both int and float64 types from JSON unmarshaling
 func (a *AssertionUtil) GetInt(m map[string]any, key string) (int, bool) {
@@ -151,6 +213,9 @@ func (a *AssertionUtil) GetInt(m map[string]any, key string) (int, bool) {
 				return int(v), true
 			}
 		}
+		if i, ok := a.coerced(val, TypeInt); ok {
+			return i.(int), true
+		}
 	}
 	return 0, false
 }
@@ -170,10 +235,23 @@ func (a *AssertionUtil) GetInt64(m map[string]any, key string) (int64, bool) {
 				return int64(v), true
 			}
 		}
+		if i, ok := a.coerced(val, TypeInt64); ok {
+			return i.(int64), true
+		}
 	}
 	return 0, false
 }
 
+// coerced parses a non-empty string value to the given type when coercion is enabled
+func (a *AssertionUtil) coerced(val any, to FieldType) (any, bool) {
+	str, ok := val.(string)
+	if !a.coerce || !ok || strings.TrimSpace(str) == "" {
+		return nil, false
+	}
+	converted, _, err := coerceScalar(str, to)
+	return converted, err == nil
+}
+
 // GetStringWithDefault safely extracts a string value with a fallback default
 func (a *AssertionUtil) GetStringWithDefault(m map[string]any, key, defaultValue string) string {
 	if val, ok := a.GetString(m, key); ok {
@@ -298,7 +376,11 @@ func (a *AssertionUtil) ValidateRequired(m map[string]any, keys ...string) error
 		}
 	}
 	if len(missing) > 0 {
-		return fmt.Errorf("required fields missing or empty: %v", missing)
+		fields := make([]errorutil.FieldError, len(missing))
+		for i, key := range missing {
+			fields[i] = errorutil.FieldError{Field: key, Message: "required field missing or empty"}
+		}
+		return newValidationError(ErrRequired, fmt.Sprintf("required fields missing or empty: %v", missing), fields...)
 	}
 	return nil
 }
@@ -311,3 +393,71 @@ func (a *AssertionUtil) GetKeys(m map[string]any) []string {
 	}
 	return keys
 }
+
+// ErrItemCount is wrapped by RequireMinItems and RequireMaxItems when a slice has the wrong number of items
+var ErrItemCount = errors.New("unexpected number of items")
+
+// Len returns the length of the string, slice, array or map stored at key
+// Strings are measured in characters (runes) rather than bytes. Returns false for missing keys, nil and other types.
+func (a *AssertionUtil) Len(m map[string]any, key string) (int, bool) {
+	switch v := m[key].(type) {
+	case nil:
+		return 0, false
+	case string:
+		return utf8.RuneCountInString(v), true
+	case []any:
+		return len(v), true
+	case map[string]any:
+		return len(v), true
+	}
+	rv := reflect.ValueOf(m[key])
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rv.Len(), true
+	}
+	return 0, false
+}
+
+// RequireMinItems checks that the slice at key has at least minItems items
+// A missing or nil value counts as an empty slice; a value that is not a slice is an error.
+func (a *AssertionUtil) RequireMinItems(m map[string]any, key string, minItems int) error {
+	n, err := itemCount(m, key)
+	if err != nil {
+		return err
+	}
+	if n < minItems {
+		return newValidationError(ErrItemCount, fmt.Sprintf("%v: field '%s' has %d items, want at least %d", ErrItemCount, key, n, minItems),
+			errorutil.FieldError{Field: key, Message: fmt.Sprintf("has %d items, want at least %d", n, minItems)})
+	}
+	return nil
+}
+
+// RequireMaxItems checks that the slice at key has at most maxItems items
+// A missing or nil value counts as an empty slice; a value that is not a slice is an error.
+func (a *AssertionUtil) RequireMaxItems(m map[string]any, key string, maxItems int) error {
+	n, err := itemCount(m, key)
+	if err != nil {
+		return err
+	}
+	if n > maxItems {
+		return newValidationError(ErrItemCount, fmt.Sprintf("%v: field '%s' has %d items, want at most %d", ErrItemCount, key, n, maxItems),
+			errorutil.FieldError{Field: key, Message: fmt.Sprintf("has %d items, want at most %d", n, maxItems)})
+	}
+	return nil
+}
+
+// itemCount returns the number of items in the slice at key, treating missing and nil values as empty
+func itemCount(m map[string]any, key string) (int, error) {
+	value := m[key]
+	if value == nil {
+		return 0, nil
+	}
+	if items, ok := value.([]any); ok {
+		return len(items), nil
+	}
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		return rv.Len(), nil
+	}
+	return 0, newValidationError(nil, fmt.Sprintf("field '%s' is not a slice: %T", key, value),
+		errorutil.FieldError{Field: key, Message: fmt.Sprintf("is not a slice: %T", value)})
+}
@@ -1,8 +1,15 @@
 package assertionutil
 
 import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/errorutil"
 )
 
 func TestNewAssertionUtil(t *testing.T) {
@@ -569,6 +576,77 @@ func TestGetSlice(t *testing.T) {
 	}
 }
 
+func TestGetBytes(t *testing.T) {
+	util := NewAssertionUtil()
+
+	tests := []struct {
+		name     string
+		data     map[string]any
+		key      string
+		expected []byte
+		ok       bool
+	}{
+		{
+			name:     "byte slice",
+			data:     map[string]any{"key": []byte("raw")},
+			key:      "key",
+			expected: []byte("raw"),
+			ok:       true,
+		},
+		{
+			name:     "base64 string from JSON",
+			data:     map[string]any{"key": "aGVsbG8="},
+			key:      "key",
+			expected: []byte("hello"),
+			ok:       true,
+		},
+		{
+			name:     "url-safe unpadded base64",
+			data:     map[string]any{"key": "-_-_AQI"},
+			key:      "key",
+			expected: []byte{0xfb, 0xff, 0xbf, 0x01, 0x02},
+			ok:       true,
+		},
+		{
+			name:     "invalid base64",
+			data:     map[string]any{"key": "not base64!"},
+			key:      "key",
+			expected: nil,
+			ok:       false,
+		},
+		{
+			name:     "empty byte slice",
+			data:     map[string]any{"key": []byte{}},
+			key:      "key",
+			expected: nil,
+			ok:       false,
+		},
+		{
+			name:     "missing key",
+			data:     map[string]any{},
+			key:      "key",
+			expected: nil,
+			ok:       false,
+		},
+		{
+			name:     "wrong type",
+			data:     map[string]any{"key": 42},
+			key:      "key",
+			expected: nil,
+			ok:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := util.GetBytes(tt.data, tt.key)
+			if !reflect.DeepEqual(result, tt.expected) || ok != tt.ok {
+				t.Errorf("GetBytes() = (%v, %v), want (%v, %v)", result, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
 func TestGetStringWithDefault(t *testing.T) {
 	util := NewAssertionUtil()
 
@@ -1111,6 +1189,232 @@ func TestValidateRequired(t *testing.T) {
 	}
 }
 
+func TestValidationError(t *testing.T) {
+	util := NewAssertionUtil()
+	errs := errorutil.NewErrorUtil(nil)
+
+	err := util.ValidateRequired(map[string]any{"name": ""}, "id", "name")
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || !errors.Is(err, ErrRequired) {
+		t.Fatalf("ValidateRequired() error = %v, want a *ValidationError wrapping ErrRequired", err)
+	}
+	want := []errorutil.FieldError{{Field: "id", Message: "required field missing or empty"}, {Field: "name", Message: "required field missing or empty"}}
+	if !reflect.DeepEqual(validationErr.FieldErrors(), want) {
+		t.Errorf("FieldErrors() = %v, want %v", validationErr.FieldErrors(), want)
+	}
+	if code := errs.CodeOf(err); code != errorutil.CodeInvalidArgument {
+		t.Errorf("CodeOf() = %s, want INVALID_ARGUMENT", code)
+	}
+
+	rec := httptest.NewRecorder()
+	errs.WriteError(rec, util.RequireMinItems(map[string]any{"tags": []any{}}, "tags", 1))
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"field":"tags"`) {
+		t.Errorf("WriteError() = %d %s, want 400 naming the tags field", rec.Code, rec.Body.String())
+	}
+
+	_, report := util.Normalize(map[string]any{"port": "http"}, Schema{"port": {Type: TypeInt}})
+	if !errors.As(report.Err(), &validationErr) || len(validationErr.Fields) != 1 || validationErr.Fields[0].Field != "port" {
+		t.Errorf("report.Err() = %v, want a *ValidationError for port", report.Err())
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	util := NewAssertionUtil()
+	schema := Schema{
+		"id":       {Type: TypeInt},
+		"score":    {Type: TypeFloat},
+		"active":   {Type: TypeBool},
+		"name":     {Type: TypeString},
+		"created":  {Type: TypeTime},
+		"updated":  {Type: TypeTime},
+		"big":      {Type: TypeInt64},
+		"optional": {Type: TypeInt},
+		"address": {Type: TypeObject, Fields: Schema{
+			"zip": {Type: TypeString},
+		}},
+		"tags":  {Type: TypeSlice, Items: &Field{Type: TypeString}},
+		"ports": {Type: TypeSlice, Items: &Field{Type: TypeInt}},
+	}
+	input := map[string]any{
+		"id":       "42",
+		"score":    "9.5",
+		"active":   "true",
+		"name":     float64(7),
+		"created":  float64(1700000000),
+		"updated":  "2024-01-02T03:04:05Z",
+		"big":      int64(1) << 40,
+		"optional": nil,
+		"extra":    "kept",
+		"address":  map[string]any{"zip": float64(12345), "city": "Pune"},
+		"tags":     []any{"a", true},
+		"ports":    []any{"80", "http", float64(443)},
+	}
+
+	got, report := util.Normalize(input, schema)
+
+	want := map[string]any{
+		"id":       42,
+		"score":    9.5,
+		"active":   true,
+		"name":     "7",
+		"created":  time.Unix(1700000000, 0).UTC(),
+		"updated":  time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC),
+		"big":      int64(1) << 40,
+		"optional": nil,
+		"extra":    "kept",
+		"address":  map[string]any{"zip": "12345", "city": "Pune"},
+		"tags":     []any{"a", "true"},
+		"ports":    []any{80, "http", 443},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Normalize() = %#v, want %#v", got, want)
+	}
+
+	coerced := make(map[string]bool)
+	for _, c := range report.Coerced {
+		coerced[c.Path] = true
+	}
+	for _, path := range []string{"id", "score", "active", "name", "created", "updated", "address.zip", "tags[1]", "ports[0]", "ports[2]"} {
+		if !coerced[path] {
+			t.Errorf("report.Coerced missing %q", path)
+		}
+	}
+	if coerced["big"] || coerced["tags[0]"] {
+		t.Error("values already of the declared type should not be reported as coerced")
+	}
+
+	if report.OK() || len(report.Failures) != 1 || report.Failures[0].Path != "ports[1]" {
+		t.Fatalf("report.Failures = %+v, want one failure at ports[1]", report.Failures)
+	}
+	if err := report.Err(); !errors.Is(err, ErrNormalize) || !strings.Contains(err.Error(), "ports[1]") {
+		t.Errorf("report.Err() = %v, want ErrNormalize mentioning ports[1]", err)
+	}
+
+	// The input document is left untouched
+	if input["id"] != "42" || input["address"].(map[string]any)["zip"] != float64(12345) {
+		t.Error("Normalize() modified its input")
+	}
+}
+
+func TestNormalize_Failures(t *testing.T) {
+	util := NewAssertionUtil()
+	schema := Schema{
+		"count":   {Type: TypeInt},
+		"when":    {Type: TypeTime},
+		"epoch":   {Type: TypeTime},
+		"profile": {Type: TypeObject},
+		"list":    {Type: TypeSlice},
+		"label":   {Type: TypeString},
+	}
+	input := map[string]any{
+		"count":   1.5,
+		"when":    "yesterday-ish",
+		"epoch":   1.5,
+		"profile": "not an object",
+		"list":    "not a list",
+		"label":   map[string]any{"x": 1},
+	}
+
+	got, report := util.Normalize(input, schema)
+	if len(report.Failures) != len(input) {
+		t.Errorf("len(report.Failures) = %d, want %d: %+v", len(report.Failures), len(input), report.Failures)
+	}
+	if !reflect.DeepEqual(got, input) {
+		t.Errorf("failed values should be kept unchanged, got %#v", got)
+	}
+
+	if _, report := util.Normalize(map[string]any{"a": 1}, nil); !report.OK() || report.Err() != nil {
+		t.Error("Normalize() with nil schema should succeed")
+	}
+}
+
+func TestLen(t *testing.T) {
+	util := NewAssertionUtil()
+	data := map[string]any{
+		"name":    "héllo",
+		"empty":   "",
+		"items":   []any{1, 2, 3},
+		"tags":    []string{"a", "b"},
+		"object":  map[string]any{"a": 1, "b": 2},
+		"counts":  map[string]int{"x": 1},
+		"fixed":   [2]int{1, 2},
+		"number":  42,
+		"nothing": nil,
+	}
+
+	tests := []struct {
+		key    string
+		want   int
+		wantOK bool
+	}{
+		{"name", 5, true},
+		{"empty", 0, true},
+		{"items", 3, true},
+		{"tags", 2, true},
+		{"object", 2, true},
+		{"counts", 1, true},
+		{"fixed", 2, true},
+		{"number", 0, false},
+		{"nothing", 0, false},
+		{"missing", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			got, ok := util.Len(data, tt.key)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Len() = (%d, %v), want (%d, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRequireMinMaxItems(t *testing.T) {
+	util := NewAssertionUtil()
+	data := map[string]any{
+		"items":   []any{1, 2, 3},
+		"tags":    []string{"a"},
+		"nothing": nil,
+		"name":    "not a slice",
+	}
+
+	tests := []struct {
+		name      string
+		key       string
+		min, max  int
+		minErr    bool
+		maxErr    bool
+		wantCount bool // errors wrap ErrItemCount rather than a type error
+	}{
+		{"within bounds", "items", 1, 5, false, false, true},
+		{"exact bounds", "items", 3, 3, false, false, true},
+		{"too few", "items", 4, 10, true, false, true},
+		{"too many", "items", 0, 2, false, true, true},
+		{"typed slice", "tags", 2, 0, true, true, true},
+		{"nil counts as empty", "nothing", 1, 0, true, false, true},
+		{"missing counts as empty", "missing", 0, 0, false, false, true},
+		{"not a slice", "name", 0, 10, true, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minErr := util.RequireMinItems(data, tt.key, tt.min)
+			if (minErr != nil) != tt.minErr {
+				t.Errorf("RequireMinItems() error = %v, wantErr %v", minErr, tt.minErr)
+			}
+			maxErr := util.RequireMaxItems(data, tt.key, tt.max)
+			if (maxErr != nil) != tt.maxErr {
+				t.Errorf("RequireMaxItems() error = %v, wantErr %v", maxErr, tt.maxErr)
+			}
+			for _, err := range []error{minErr, maxErr} {
+				if err != nil && errors.Is(err, ErrItemCount) != tt.wantCount {
+					t.Errorf("errors.Is(%v, ErrItemCount) = %v, want %v", err, !tt.wantCount, tt.wantCount)
+				}
+			}
+		})
+	}
+}
+
 // Benchmark tests
 func BenchmarkGetString(b *testing.B) {
 	util := NewAssertionUtil()
@@ -1122,6 +1426,89 @@ func BenchmarkGetString(b *testing.B) {
 	}
 }
 
+func TestCoercingAssertionUtil(t *testing.T) {
+	data := map[string]any{"port": "8080", "ratio": " 1.5 ", "debug": "yes", "hosts": "a, b,,c", "big": "9000000000", "bad": "x", "empty": ""}
+	util := NewCoercingAssertionUtil()
+
+	if got, ok := util.GetInt(data, "port"); !ok || got != 8080 {
+		t.Errorf("GetInt() = %v, %v; want 8080, true", got, ok)
+	}
+	if got, ok := util.GetInt64(data, "big"); !ok || got != 9000000000 {
+		t.Errorf("GetInt64() = %v, %v; want 9000000000, true", got, ok)
+	}
+	if got, ok := util.GetFloat64(data, "ratio"); !ok || got != 1.5 {
+		t.Errorf("GetFloat64() = %v, %v; want 1.5, true", got, ok)
+	}
+	if got, ok := util.GetBool(data, "debug"); !ok || !got {
+		t.Errorf("GetBool() = %v, %v; want true, true", got, ok)
+	}
+	if got, ok := util.GetStringSlice(data, "hosts"); !ok || !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("GetStringSlice() = %v, %v; want [a b c], true", got, ok)
+	}
+	for _, key := range []string{"bad", "empty", "missing"} {
+		if _, ok := util.GetInt(data, key); ok {
+			t.Errorf("GetInt(%q) should fail", key)
+		}
+		if _, ok := util.GetBool(data, key); ok {
+			t.Errorf("GetBool(%q) should fail", key)
+		}
+	}
+
+	// The default utility keeps strict type assertions
+	if _, ok := NewAssertionUtil().GetInt(data, "port"); ok {
+		t.Error("GetInt() without coercion should reject a string")
+	}
+}
+
+func TestFromStringMap(t *testing.T) {
+	source := map[string]string{"PORT": "9090", "DEBUG": "off", "TAGS": "x,y", "NAME": "svc"}
+	values := FromStringMap(source)
+	source["PORT"] = "1"
+
+	if got := values.GetIntWithDefault("PORT", 80); got != 9090 {
+		t.Errorf("GetIntWithDefault() = %d, want 9090", got)
+	}
+	if got := values.GetIntWithDefault("MISSING", 80); got != 80 {
+		t.Errorf("GetIntWithDefault(missing) = %d, want 80", got)
+	}
+	if got, ok := values.GetBool("DEBUG"); !ok || got {
+		t.Errorf("GetBool() = %v, %v; want false, true", got, ok)
+	}
+	if got, _ := values.GetStringSlice("TAGS"); !reflect.DeepEqual(got, []string{"x", "y"}) {
+		t.Errorf("GetStringSlice() = %v, want [x y]", got)
+	}
+	if got := values.GetStringOrEmpty("NAME"); got != "svc" {
+		t.Errorf("GetStringOrEmpty() = %q, want svc", got)
+	}
+
+	err := values.ValidateRequired("NAME", "DATABASE_URL")
+	var verr *ValidationError
+	if !errors.As(err, &verr) || !errors.Is(err, ErrRequired) || len(verr.FieldErrors()) != 1 || verr.FieldErrors()[0].Field != "DATABASE_URL" {
+		t.Errorf("ValidateRequired() = %v, want a ValidationError for DATABASE_URL", err)
+	}
+
+	normalized, report := values.Normalize(Schema{"PORT": {Type: TypeInt}, "DEBUG": {Type: TypeBool}})
+	if !report.OK() || normalized["PORT"] != 9090 || normalized["DEBUG"] != false {
+		t.Errorf("Normalize() = %v, %+v", normalized, report)
+	}
+}
+
+func TestFromEnviron(t *testing.T) {
+	t.Setenv("ASSERTIONUTIL_TEST_TIMEOUT", "30")
+	t.Setenv("ASSERTIONUTIL_TEST_DSN", "postgres://u@h/db?sslmode=disable")
+
+	env := FromEnviron()
+	if got, ok := env.GetInt("ASSERTIONUTIL_TEST_TIMEOUT"); !ok || got != 30 {
+		t.Errorf("GetInt() = %v, %v; want 30, true", got, ok)
+	}
+	if got, _ := env.GetString("ASSERTIONUTIL_TEST_DSN"); got != "postgres://u@h/db?sslmode=disable" {
+		t.Errorf("GetString() = %q, want the value with its '=' kept", got)
+	}
+	if !env.HasKey("ASSERTIONUTIL_TEST_DSN") || env.Map()["ASSERTIONUTIL_TEST_TIMEOUT"] != "30" {
+		t.Error("FromEnviron() should include the environment variables")
+	}
+}
+
 func BenchmarkGetNestedString(b *testing.B) {
 	util := NewAssertionUtil()
 	data := map[string]any{
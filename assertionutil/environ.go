@@ -0,0 +1,118 @@
+package assertionutil
+
+import (
+	"os"
+	"strings"
+)
+
+// Values is a flat string map, such as the environment, read through the AssertionClient getters with
+// coercion enabled, so "8080" satisfies GetInt, "yes" GetBool and "a,b" GetStringSlice:
+//
+//	env := assertionutil.FromEnviron()
+//	if err := env.ValidateRequired("DATABASE_URL"); err != nil { ... }
+//	port := env.GetIntWithDefault("PORT", 8080)
+type Values struct {
+	data   map[string]any
+	client AssertionClient
+}
+
+// FromStringMap wraps values for the coercing getters; the map is copied
+func FromStringMap(values map[string]string) *Values {
+	data := make(map[string]any, len(values))
+	for k, v := range values {
+		data[k] = v
+	}
+	return &Values{data: data, client: NewCoercingAssertionUtil()}
+}
+
+// FromEnviron wraps a snapshot of the process environment taken from os.Environ
+func FromEnviron() *Values {
+	env := os.Environ()
+	values := make(map[string]string, len(env))
+	for _, kv := range env {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			values[k] = v
+		}
+	}
+	return FromStringMap(values)
+}
+
+// Map returns the wrapped data as map[string]any, for the AssertionClient methods Values does not forward
+func (v *Values) Map() map[string]any {
+	return v.data
+}
+
+// GetString returns a non-empty value
+func (v *Values) GetString(key string) (string, bool) {
+	return v.client.GetString(v.data, key)
+}
+
+// GetStringRequired returns a non-empty value or a *ValidationError wrapping ErrRequired
+func (v *Values) GetStringRequired(key string) (string, error) {
+	return v.client.GetStringRequired(v.data, key)
+}
+
+// GetStringOrEmpty returns the value or ""
+func (v *Values) GetStringOrEmpty(key string) string {
+	return v.client.GetStringOrEmpty(v.data, key)
+}
+
+// GetInt parses the value as an int
+func (v *Values) GetInt(key string) (int, bool) {
+	return v.client.GetInt(v.data, key)
+}
+
+// GetInt64 parses the value as an int64
+func (v *Values) GetInt64(key string) (int64, bool) {
+	return v.client.GetInt64(v.data, key)
+}
+
+// GetFloat64 parses the value as a float64
+func (v *Values) GetFloat64(key string) (float64, bool) {
+	return v.client.GetFloat64(v.data, key)
+}
+
+// GetBool parses the value as a bool, accepting "true"/"yes"/"1"/"on" and their opposites
+func (v *Values) GetBool(key string) (bool, bool) {
+	return v.client.GetBool(v.data, key)
+}
+
+// GetStringSlice splits a comma-separated value, dropping blank items
+func (v *Values) GetStringSlice(key string) ([]string, bool) {
+	return v.client.GetStringSlice(v.data, key)
+}
+
+// GetStringWithDefault returns the value or defaultValue when it is missing or empty
+func (v *Values) GetStringWithDefault(key, defaultValue string) string {
+	return v.client.GetStringWithDefault(v.data, key, defaultValue)
+}
+
+// GetIntWithDefault returns the parsed value or defaultValue when it is missing or invalid
+func (v *Values) GetIntWithDefault(key string, defaultValue int) int {
+	return v.client.GetIntWithDefault(v.data, key, defaultValue)
+}
+
+// GetFloat64WithDefault returns the parsed value or defaultValue when it is missing or invalid
+func (v *Values) GetFloat64WithDefault(key string, defaultValue float64) float64 {
+	return v.client.GetFloat64WithDefault(v.data, key, defaultValue)
+}
+
+// GetBoolWithDefault returns the parsed value or defaultValue when it is missing or invalid
+func (v *Values) GetBoolWithDefault(key string, defaultValue bool) bool {
+	return v.client.GetBoolWithDefault(v.data, key, defaultValue)
+}
+
+// HasKey reports whether key is set, even to ""
+func (v *Values) HasKey(key string) bool {
+	return v.client.HasKey(v.data, key)
+}
+
+// ValidateRequired checks that all keys are set to non-empty values
+func (v *Values) ValidateRequired(keys ...string) error {
+	return v.client.ValidateRequired(v.data, keys...)
+}
+
+// Normalize converts the values to the types declared in schema (see AssertionUtil.Normalize)
+func (v *Values) Normalize(schema Schema) (map[string]any, NormalizeReport) {
+	return v.client.Normalize(v.data, schema)
+}
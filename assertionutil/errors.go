@@ -0,0 +1,46 @@
+package assertionutil
+
+import (
+	"errors"
+
+	"github.com/mustanish/common-utils/v2/errorutil"
+)
+
+// ErrRequired is wrapped by GetStringRequired and ValidateRequired when a required field is missing or empty
+var ErrRequired = errors.New("required field missing or empty")
+
+// ValidationError is returned by the validation helpers and names the offending fields
+// It implements errorutil.Coder (INVALID_ARGUMENT) and errorutil.FieldErrorer, so errorutil's WriteError
+// responds 400 with the fields listed. errors.Is matches the sentinel it wraps (ErrRequired, ErrItemCount
+// or ErrNormalize).
+type ValidationError struct {
+	Fields []errorutil.FieldError
+
+	msg  string
+	kind error
+}
+
+// newValidationError builds a ValidationError wrapping kind
+func newValidationError(kind error, msg string, fields ...errorutil.FieldError) *ValidationError {
+	return &ValidationError{Fields: fields, msg: msg, kind: kind}
+}
+
+// Error implements the error interface for ValidationError
+func (e *ValidationError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the sentinel describing the kind of failure, if any
+func (e *ValidationError) Unwrap() error {
+	return e.kind
+}
+
+// ErrorCode implements errorutil.Coder
+func (e *ValidationError) ErrorCode() errorutil.Code {
+	return errorutil.CodeInvalidArgument
+}
+
+// FieldErrors implements errorutil.FieldErrorer
+func (e *ValidationError) FieldErrors() []errorutil.FieldError {
+	return e.Fields
+}
@@ -0,0 +1,254 @@
+package assertionutil
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/collectionutil"
+	"github.com/mustanish/common-utils/v2/dateutil"
+	"github.com/mustanish/common-utils/v2/errorutil"
+)
+
+// FieldType is the type a document value is coerced to by Normalize
+type FieldType int
+
+const (
+	TypeAny    FieldType = iota // left as is
+	TypeString                  // fmt-style string form of scalars
+	TypeInt                     // int; floats must be whole, strings must parse
+	TypeInt64                   // int64
+	TypeFloat                   // float64
+	TypeBool                    // bool; accepts "true"/"yes"/"1"/"on" and their opposites
+	TypeTime                    // time.Time from date strings or Unix epoch seconds (numbers or numeric strings)
+	TypeObject                  // map[string]any normalized against Field.Fields
+	TypeSlice                   // []any with every item normalized against Field.Items
+)
+
+// String returns the type name used in reports
+func (t FieldType) String() string {
+	switch t {
+	case TypeAny:
+		return "any"
+	case TypeString:
+		return "string"
+	case TypeInt:
+		return "int"
+	case TypeInt64:
+		return "int64"
+	case TypeFloat:
+		return "float64"
+	case TypeBool:
+		return "bool"
+	case TypeTime:
+		return "time"
+	case TypeObject:
+		return "object"
+	case TypeSlice:
+		return "slice"
+	}
+	return fmt.Sprintf("FieldType(%d)", int(t))
+}
+
+// Field declares the expected type of a document value
+type Field struct {
+	Type   FieldType
+	Fields Schema // nested fields for TypeObject
+	Items  *Field // item type for TypeSlice (nil leaves items as is)
+}
+
+// Schema maps document keys to their expected types
+type Schema map[string]Field
+
+// Coercion records a value that was converted to its declared type
+type Coercion struct {
+	Path string
+	From string // Go type of the original value
+	To   FieldType
+}
+
+// CoercionFailure records a value that could not be converted; the original value is kept in the output
+type CoercionFailure struct {
+	Path  string
+	Value any
+	To    FieldType
+	Err   error
+}
+
+// NormalizeReport describes what Normalize changed and what it could not
+type NormalizeReport struct {
+	Coerced  []Coercion
+	Failures []CoercionFailure
+}
+
+// OK reports whether every value matched or was coerced to its declared type
+func (r NormalizeReport) OK() bool {
+	return len(r.Failures) == 0
+}
+
+// Err summarizes the failures as a *ValidationError listing every failed path, or returns nil when there are none
+func (r NormalizeReport) Err() error {
+	if r.OK() {
+		return nil
+	}
+	msgs := make([]string, len(r.Failures))
+	fields := make([]errorutil.FieldError, len(r.Failures))
+	for i, f := range r.Failures {
+		problem := fmt.Sprintf("cannot convert %T to %s: %v", f.Value, f.To, f.Err)
+		msgs[i] = f.Path + ": " + problem
+		fields[i] = errorutil.FieldError{Field: f.Path, Message: problem}
+	}
+	return newValidationError(ErrNormalize, fmt.Sprintf("%v: %s", ErrNormalize, strings.Join(msgs, "; ")), fields...)
+}
+
+// ErrNormalize is wrapped by NormalizeReport.Err
+var ErrNormalize = errors.New("document does not match schema")
+
+// converters used to coerce scalar values
+var (
+	converters = collectionutil.NewCollectionUtil()
+	dates      = dateutil.NewDateUtil()
+)
+
+// Normalize returns a copy of m with values coerced to the types declared in schema, plus a report
+// Keys absent from the schema are copied unchanged, missing keys are not added and nil values stay nil.
+// Nested objects and slices are copied as they are walked, so m itself is never modified.
+func (a *AssertionUtil) Normalize(m map[string]any, schema Schema) (map[string]any, NormalizeReport) {
+	var report NormalizeReport
+	return normalizeObject(m, schema, "", &report), report
+}
+
+// normalizeObject normalizes one level of a document
+func normalizeObject(m map[string]any, schema Schema, prefix string, report *NormalizeReport) map[string]any {
+	out := make(map[string]any, len(m))
+	for key, value := range m {
+		field, declared := schema[key]
+		if !declared {
+			out[key] = value
+			continue
+		}
+		out[key] = normalizeValue(value, field, joinPath(prefix, key), report)
+	}
+	return out
+}
+
+// normalizeValue coerces a single value, recording the outcome in report
+func normalizeValue(value any, field Field, path string, report *NormalizeReport) any {
+	if value == nil || field.Type == TypeAny {
+		return value
+	}
+
+	switch field.Type {
+	case TypeObject:
+		obj, ok := value.(map[string]any)
+		if !ok {
+			report.Failures = append(report.Failures, CoercionFailure{Path: path, Value: value, To: field.Type, Err: errors.New("not an object")})
+			return value
+		}
+		return normalizeObject(obj, field.Fields, path, report)
+	case TypeSlice:
+		items, ok := value.([]any)
+		if !ok {
+			report.Failures = append(report.Failures, CoercionFailure{Path: path, Value: value, To: field.Type, Err: errors.New("not a slice")})
+			return value
+		}
+		out := make([]any, len(items))
+		for i, item := range items {
+			if field.Items == nil {
+				out[i] = item
+				continue
+			}
+			out[i] = normalizeValue(item, *field.Items, path+"["+strconv.Itoa(i)+"]", report)
+		}
+		return out
+	}
+
+	converted, changed, err := coerceScalar(value, field.Type)
+	if err != nil {
+		report.Failures = append(report.Failures, CoercionFailure{Path: path, Value: value, To: field.Type, Err: err})
+		return value
+	}
+	if changed {
+		report.Coerced = append(report.Coerced, Coercion{Path: path, From: fmt.Sprintf("%T", value), To: field.Type})
+	}
+	return converted
+}
+
+// coerceScalar converts value to a scalar type, reporting whether a conversion was needed
+func coerceScalar(value any, to FieldType) (any, bool, error) {
+	switch to {
+	case TypeString:
+		if s, ok := value.(string); ok {
+			return s, false, nil
+		}
+		switch value.(type) {
+		case map[string]any, []any:
+			return nil, false, errors.New("not a scalar")
+		}
+		return converters.ConvertToString(value), true, nil
+	case TypeInt:
+		if i, ok := value.(int); ok {
+			return i, false, nil
+		}
+		i, err := converters.ConvertToInteger(value)
+		return i, true, err
+	case TypeInt64:
+		if i, ok := value.(int64); ok {
+			return i, false, nil
+		}
+		i, err := converters.ConvertToInt64(value)
+		return i, true, err
+	case TypeFloat:
+		if f, ok := value.(float64); ok {
+			return f, false, nil
+		}
+		f, err := converters.ConvertToFloat64(value)
+		return f, true, err
+	case TypeBool:
+		if b, ok := value.(bool); ok {
+			return b, false, nil
+		}
+		b, err := converters.ConvertToBool(value)
+		return b, true, err
+	case TypeTime:
+		t, changed, err := coerceTime(value)
+		return t, changed, err
+	}
+	return nil, false, fmt.Errorf("unsupported field type %s", to)
+}
+
+// coerceTime converts date strings and Unix epoch seconds to time.Time
+func coerceTime(value any) (time.Time, bool, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, false, nil
+	case string:
+		s := strings.TrimSpace(v)
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			t, err := dates.ParseUnix(s)
+			return t.UTC(), true, err
+		}
+		t, err := dates.Parse(s)
+		return t, true, err
+	case float64:
+		if v != float64(int64(v)) {
+			return time.Time{}, false, errors.New("epoch seconds must be whole")
+		}
+		t, err := dates.ParseUnix(v)
+		return t.UTC(), true, err
+	case int, int64:
+		t, err := dates.ParseUnix(v)
+		return t.UTC(), true, err
+	}
+	return time.Time{}, false, fmt.Errorf("unsupported time value %T", value)
+}
+
+// joinPath appends key to a dotted document path
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,155 @@
+package cacheutil
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// CacheConfig holds configuration for an in-memory cache
+type CacheConfig struct {
+	// MaxEntries bounds the cache size; the least recently used entry is evicted beyond it
+	MaxEntries int
+
+	// DefaultTTL applies to entries stored with Set (0 means entries never expire)
+	DefaultTTL time.Duration
+}
+
+// DefaultCacheConfig returns default configuration
+func DefaultCacheConfig() *CacheConfig {
+	return &CacheConfig{
+		MaxEntries: 1000,
+		DefaultTTL: 0, // no expiry
+	}
+}
+
+// Cache defines the interface shared by all cache implementations
+type Cache[K comparable, V any] interface {
+	Get(key K) (V, bool)
+	Set(key K, value V)
+	SetWithTTL(key K, value V, ttl time.Duration)
+	Delete(key K)
+	Len() int
+	Clear()
+}
+
+// MemoryCache is a concurrency-safe in-memory cache with per-entry TTL and LRU eviction
+type MemoryCache[K comparable, V any] struct {
+	maxEntries int
+	defaultTTL time.Duration
+
+	mu      sync.Mutex
+	entries map[K]*list.Element
+	order   *list.List // front is most recently used
+}
+
+// memoryEntry is a cached value with its optional expiry
+type memoryEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// expired reports whether the entry has passed its expiry time
+func (e *memoryEntry[K, V]) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && now.After(e.expiresAt)
+}
+
+// NewMemoryCache creates a new in-memory TTL + LRU cache
+// Pass nil for config to use all defaults, or pass config with only the properties you want to override
+func NewMemoryCache[K comparable, V any](config *CacheConfig) Cache[K, V] {
+	defaults := DefaultCacheConfig()
+
+	if config != nil {
+		if config.MaxEntries > 0 {
+			defaults.MaxEntries = config.MaxEntries
+		}
+		if config.DefaultTTL > 0 {
+			defaults.DefaultTTL = config.DefaultTTL
+		}
+	}
+
+	return &MemoryCache[K, V]{
+		maxEntries: defaults.MaxEntries,
+		defaultTTL: defaults.DefaultTTL,
+		entries:    make(map[K]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns the value for key if present and not expired, marking it as recently used
+func (c *MemoryCache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	elem, ok := c.entries[key]
+	if !ok {
+		return zero, false
+	}
+	entry := elem.Value.(*memoryEntry[K, V])
+	if entry.expired(time.Now()) {
+		c.remove(elem)
+		return zero, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.value, true
+}
+
+// Set stores value under key using the default TTL
+func (c *MemoryCache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, c.defaultTTL)
+}
+
+// SetWithTTL stores value under key, expiring it after ttl (0 means no expiry)
+func (c *MemoryCache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*memoryEntry[K, V])
+		entry.value, entry.expiresAt = value, expiresAt
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+// Delete removes key from the cache
+func (c *MemoryCache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		c.remove(elem)
+	}
+}
+
+// Len returns the number of entries, including expired entries not yet evicted
+func (c *MemoryCache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Clear removes all entries
+func (c *MemoryCache[K, V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[K]*list.Element)
+	c.order.Init()
+}
+
+// remove unlinks an element; c.mu must be held
+func (c *MemoryCache[K, V]) remove(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*memoryEntry[K, V]).key)
+}
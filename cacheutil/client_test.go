@@ -0,0 +1,549 @@
+package cacheutil
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// =================== Test MemoryCache ===================
+
+func TestDefaultCacheConfig(t *testing.T) {
+	config := DefaultCacheConfig()
+	if config.MaxEntries != 1000 || config.DefaultTTL != 0 {
+		t.Errorf("Unexpected defaults: %+v", config)
+	}
+}
+
+func TestNewMemoryCache(t *testing.T) {
+	cache := NewMemoryCache[string, int](nil)
+	if cache == nil {
+		t.Fatal("NewMemoryCache() returned nil")
+	}
+}
+
+func TestMemoryCache_GetSetDelete(t *testing.T) {
+	cache := NewMemoryCache[string, int](nil)
+
+	if _, ok := cache.Get("missing"); ok {
+		t.Error("Get() on empty cache returned ok")
+	}
+
+	cache.Set("a", 1)
+	cache.Set("b", 2)
+	if v, ok := cache.Get("a"); !ok || v != 1 {
+		t.Errorf("Get(a) = %v, %v; want 1, true", v, ok)
+	}
+
+	cache.Set("a", 10)
+	if v, _ := cache.Get("a"); v != 10 {
+		t.Errorf("Get(a) after overwrite = %v, want 10", v)
+	}
+
+	cache.Delete("a")
+	if _, ok := cache.Get("a"); ok {
+		t.Error("Get(a) after Delete() returned ok")
+	}
+	if cache.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", cache.Len())
+	}
+
+	cache.Clear()
+	if cache.Len() != 0 {
+		t.Errorf("Len() after Clear() = %d, want 0", cache.Len())
+	}
+}
+
+func TestMemoryCache_TTL(t *testing.T) {
+	cache := NewMemoryCache[string, string](&CacheConfig{DefaultTTL: 10 * time.Millisecond})
+
+	cache.Set("short", "v")
+	cache.SetWithTTL("long", "v", time.Hour)
+	time.Sleep(20 * time.Millisecond)
+
+	if _, ok := cache.Get("short"); ok {
+		t.Error("Expected entry with default TTL to expire")
+	}
+	if _, ok := cache.Get("long"); !ok {
+		t.Error("Expected entry with explicit TTL to survive")
+	}
+}
+
+func TestMemoryCache_LRUEviction(t *testing.T) {
+	cache := NewMemoryCache[int, int](&CacheConfig{MaxEntries: 2})
+
+	cache.Set(1, 1)
+	cache.Set(2, 2)
+	cache.Get(1) // 2 becomes least recently used
+	cache.Set(3, 3)
+
+	if _, ok := cache.Get(2); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	for _, key := range []int{1, 3} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("Expected key %d to remain cached", key)
+		}
+	}
+}
+
+// =================== Test LoadingCache ===================
+
+func TestLoadingCache_LoadsOnMiss(t *testing.T) {
+	var loads int32
+	cache := NewLoadingCache(func(ctx context.Context, key string) (int, error) {
+		atomic.AddInt32(&loads, 1)
+		return len(key), nil
+	}, nil)
+
+	for i := 0; i < 3; i++ {
+		v, err := cache.Get(context.Background(), "abc")
+		if err != nil || v != 3 {
+			t.Fatalf("Get() = %v, %v; want 3, nil", v, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("Expected 1 load, got %d", loads)
+	}
+
+	cache.Invalidate("abc")
+	_, _ = cache.Get(context.Background(), "abc")
+	if loads != 2 {
+		t.Errorf("Expected reload after Invalidate(), got %d loads", loads)
+	}
+}
+
+func TestLoadingCache_LoaderErrorNotCached(t *testing.T) {
+	var loads int32
+	loadErr := errors.New("backend down")
+	cache := NewLoadingCache(func(ctx context.Context, key string) (string, error) {
+		atomic.AddInt32(&loads, 1)
+		return "", loadErr
+	}, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.Get(context.Background(), "k"); !errors.Is(err, loadErr) {
+			t.Errorf("Expected loader error, got %v", err)
+		}
+	}
+	if loads != 2 {
+		t.Errorf("Expected failed loads not to be cached, got %d loads", loads)
+	}
+}
+
+func TestLoadingCache_Singleflight(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	cache := NewLoadingCache(func(ctx context.Context, key string) (string, error) {
+		atomic.AddInt32(&loads, 1)
+		<-release
+		return "value", nil
+	}, nil)
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = cache.Get(context.Background(), "k")
+		}(i)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if loads != 1 {
+		t.Errorf("Expected concurrent misses to share 1 load, got %d", loads)
+	}
+	for i, r := range results {
+		if r != "value" {
+			t.Errorf("results[%d] = %q, want %q", i, r, "value")
+		}
+	}
+}
+
+func TestLoadingCache_StaleWhileRevalidate(t *testing.T) {
+	var version int32
+	refreshed := make(chan struct{}, 1)
+	cache := NewLoadingCache(func(ctx context.Context, key string) (int32, error) {
+		v := atomic.AddInt32(&version, 1)
+		if v > 1 {
+			refreshed <- struct{}{}
+		}
+		return v, nil
+	}, &LoadingConfig{TTL: 10 * time.Millisecond, StaleWhileRevalidate: time.Hour})
+
+	if v, _ := cache.Get(context.Background(), "k"); v != 1 {
+		t.Fatalf("Get() = %d, want 1", v)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// Stale value is served immediately while the refresh runs
+	if v, _ := cache.Get(context.Background(), "k"); v != 1 {
+		t.Errorf("Get() on stale entry = %d, want 1", v)
+	}
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected background refresh")
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if v, _ := cache.Get(context.Background(), "k"); v != 2 {
+		t.Errorf("Get() after refresh = %d, want 2", v)
+	}
+}
+
+func TestLoadingCache_ExpiresWithoutStaleWindow(t *testing.T) {
+	var loads int32
+	cache := NewLoadingCache(func(ctx context.Context, key int) (int32, error) {
+		return atomic.AddInt32(&loads, 1), nil
+	}, &LoadingConfig{TTL: 10 * time.Millisecond})
+
+	_, _ = cache.Get(context.Background(), 1)
+	time.Sleep(20 * time.Millisecond)
+	if v, _ := cache.Get(context.Background(), 1); v != 2 {
+		t.Errorf("Expected synchronous reload after TTL, got %d", v)
+	}
+}
+
+func TestLoadingCache_RefreshErrorKeepsStale(t *testing.T) {
+	var calls int32
+	hookErrs := make(chan error, 1)
+	cache := NewLoadingCache(func(ctx context.Context, key string) (string, error) {
+		if atomic.AddInt32(&calls, 1) > 1 {
+			return "", errors.New("refresh failed")
+		}
+		return "original", nil
+	}, &LoadingConfig{
+		TTL:                  5 * time.Millisecond,
+		StaleWhileRevalidate: time.Hour,
+		RefreshErrorHook:     func(key any, err error) { hookErrs <- err },
+	})
+
+	_, _ = cache.Get(context.Background(), "k")
+	time.Sleep(10 * time.Millisecond)
+	_, _ = cache.Get(context.Background(), "k")
+
+	select {
+	case err := <-hookErrs:
+		if err == nil {
+			t.Error("Expected refresh error")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected RefreshErrorHook to be called")
+	}
+	if v, _ := cache.Get(context.Background(), "k"); v != "original" {
+		t.Errorf("Expected stale value to be kept, got %q", v)
+	}
+}
+
+// =================== Test Memoize ===================
+
+func TestMemoize(t *testing.T) {
+	ctx := WithMemo(context.Background())
+	calls := 0
+	lookup := func(context.Context) (string, error) {
+		calls++
+		return "alice", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		if got, err := Memoize(ctx, "user:1", lookup); err != nil || got != "alice" {
+			t.Fatalf("Memoize() = %q, %v, want alice", got, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("fn called %d times within one request, want 1", calls)
+	}
+
+	// Another request starts with an empty memo
+	_, _ = Memoize(WithMemo(context.Background()), "user:1", lookup)
+	if calls != 2 {
+		t.Errorf("fn called %d times across requests, want 2", calls)
+	}
+
+	// Without a memo every call computes
+	_, _ = Memoize(context.Background(), "user:1", lookup)
+	_, _ = Memoize(context.Background(), "user:1", lookup)
+	if calls != 4 {
+		t.Errorf("fn called %d times without a memo, want 4", calls)
+	}
+
+	if _, err := Memoize(ctx, "user:1", func(context.Context) (int, error) { return 1, nil }); err == nil {
+		t.Error("Memoize() with a different type for the same key should fail")
+	}
+}
+
+func TestMemoize_ErrorsNotMemoized(t *testing.T) {
+	ctx := WithMemo(context.Background())
+	calls := 0
+	fn := func(context.Context) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errors.New("downstream unavailable")
+		}
+		return 42, nil
+	}
+
+	if _, err := Memoize(ctx, "count", fn); err == nil {
+		t.Fatal("Memoize() should return the first error")
+	}
+	if got, err := Memoize(ctx, "count", fn); err != nil || got != 42 {
+		t.Errorf("Memoize() after error = %d, %v, want 42", got, err)
+	}
+	if got, _ := Memoize(ctx, "count", fn); got != 42 || calls != 2 {
+		t.Errorf("Memoize() = %d after %d calls, want 42 after 2", got, calls)
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("panic in fn should propagate")
+			}
+		}()
+		_, _ = Memoize(ctx, "panics", func(context.Context) (int, error) { panic("boom") })
+	}()
+	if got, err := Memoize(ctx, "panics", func(context.Context) (int, error) { return 7, nil }); err != nil || got != 7 {
+		t.Errorf("Memoize() after panic = %d, %v, want 7", got, err)
+	}
+}
+
+func TestMemoize_Concurrent(t *testing.T) {
+	ctx := WithMemo(context.Background())
+	var calls int32
+	release := make(chan struct{})
+	fn := func(context.Context) (int, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return 1, nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got, err := Memoize(ctx, "shared", fn); err != nil || got != 1 {
+				t.Errorf("Memoize() = %d, %v, want 1", got, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if calls != 1 {
+		t.Errorf("fn called %d times by concurrent callers, want 1", calls)
+	}
+
+	// A waiter gives up when its own context ends
+	blocked := make(chan struct{})
+	go func() { _, _ = Memoize(ctx, "slow", func(context.Context) (int, error) { <-blocked; return 0, nil }) }()
+	time.Sleep(10 * time.Millisecond)
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := Memoize(waitCtx, "slow", fn); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Memoize() waiting = %v, want DeadlineExceeded", err)
+	}
+	close(blocked)
+}
+
+func TestMemoMiddleware(t *testing.T) {
+	calls := 0
+	handler := MemoMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 2; i++ {
+			_, _ = Memoize(r.Context(), "config", func(context.Context) (bool, error) {
+				calls++
+				return true, nil
+			})
+		}
+	}))
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	if calls != 2 {
+		t.Errorf("fn called %d times for 2 requests, want 2", calls)
+	}
+}
+
+// =================== Test Stores ===================
+
+// fakeBackend records calls from the Redis and memcached adapters
+type fakeBackend struct {
+	data       map[string][]byte
+	lastTTL    time.Duration
+	lastExpiry int32
+	missErr    error
+	failErr    error
+}
+
+func newFakeBackend(missErr error) *fakeBackend {
+	return &fakeBackend{data: map[string][]byte{}, missErr: missErr}
+}
+
+func (f *fakeBackend) lookup(key string) ([]byte, error) {
+	if f.failErr != nil {
+		return nil, f.failErr
+	}
+	v, ok := f.data[key]
+	if !ok {
+		return nil, f.missErr
+	}
+	return v, nil
+}
+
+type fakeRedis struct{ *fakeBackend }
+
+func (f fakeRedis) Get(ctx context.Context, key string) ([]byte, error) { return f.lookup(key) }
+func (f fakeRedis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	f.data[key], f.lastTTL = value, ttl
+	return f.failErr
+}
+func (f fakeRedis) Del(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(f.data, key)
+	}
+	return f.failErr
+}
+
+type fakeMemcache struct{ *fakeBackend }
+
+func (f fakeMemcache) Get(key string) ([]byte, error) { return f.lookup(key) }
+func (f fakeMemcache) Set(key string, value []byte, expiration int32) error {
+	f.data[key], f.lastExpiry = value, expiration
+	return f.failErr
+}
+func (f fakeMemcache) Delete(key string) error {
+	if _, ok := f.data[key]; !ok {
+		return f.missErr
+	}
+	delete(f.data, key)
+	return f.failErr
+}
+
+func TestStores_Contract(t *testing.T) {
+	errRedisNil := errors.New("redis: nil")
+	errCacheMiss := errors.New("memcache: cache miss")
+
+	stores := map[string]Store{
+		"memory": NewMemoryStore(nil),
+		"redis": NewRedisStore(fakeRedis{newFakeBackend(errRedisNil)}, &RedisConfig{
+			KeyPrefix: "svc:",
+			IsMiss:    func(err error) bool { return errors.Is(err, errRedisNil) },
+		}),
+		"memcache": NewMemcacheStore(fakeMemcache{newFakeBackend(errCacheMiss)}, &MemcacheConfig{
+			KeyPrefix: "svc:",
+			IsMiss:    func(err error) bool { return errors.Is(err, errCacheMiss) },
+		}),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			if _, err := store.Get(ctx, "k"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get() on missing key error = %v, want ErrNotFound", err)
+			}
+			if err := store.Set(ctx, "k", []byte("v"), time.Minute); err != nil {
+				t.Fatalf("Set() unexpected error: %v", err)
+			}
+			if v, err := store.Get(ctx, "k"); err != nil || string(v) != "v" {
+				t.Errorf("Get() = %q, %v; want %q, nil", v, err, "v")
+			}
+			if err := store.Delete(ctx, "k"); err != nil {
+				t.Errorf("Delete() unexpected error: %v", err)
+			}
+			if err := store.Delete(ctx, "k"); err != nil {
+				t.Errorf("Delete() of missing key unexpected error: %v", err)
+			}
+			if _, err := store.Get(ctx, "k"); !errors.Is(err, ErrNotFound) {
+				t.Errorf("Get() after Delete() error = %v, want ErrNotFound", err)
+			}
+		})
+	}
+}
+
+func TestMemoryStore_CopiesValues(t *testing.T) {
+	store := NewMemoryStore(nil)
+	value := []byte("abc")
+	_ = store.Set(context.Background(), "k", value, 0)
+	value[0] = 'x'
+
+	got, _ := store.Get(context.Background(), "k")
+	if string(got) != "abc" {
+		t.Errorf("Expected stored value to be isolated from caller, got %q", got)
+	}
+}
+
+func TestRedisStore_PrefixAndErrors(t *testing.T) {
+	backend := newFakeBackend(ErrNotFound)
+	store := NewRedisStore(fakeRedis{backend}, &RedisConfig{KeyPrefix: "svc:"})
+
+	_ = store.Set(context.Background(), "k", []byte("v"), 5*time.Second)
+	if _, ok := backend.data["svc:k"]; !ok || backend.lastTTL != 5*time.Second {
+		t.Errorf("Expected prefixed key with TTL, got %v ttl=%v", backend.data, backend.lastTTL)
+	}
+
+	backend.failErr = errors.New("connection refused")
+	if _, err := store.Get(context.Background(), "k"); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected backend error to be surfaced, got %v", err)
+	}
+}
+
+func TestMemcacheStore_Expiration(t *testing.T) {
+	backend := newFakeBackend(ErrNotFound)
+	store := NewMemcacheStore(fakeMemcache{backend}, nil).(*MemcacheStore)
+	fixed := time.Unix(1700000000, 0)
+	store.now = func() time.Time { return fixed }
+
+	tests := []struct {
+		name     string
+		ttl      time.Duration
+		expected int32
+	}{
+		{"no expiry", 0, 0},
+		{"sub-second rounds up", 500 * time.Millisecond, 1},
+		{"relative seconds", time.Hour, 3600},
+		{"beyond 30 days is absolute", 31 * 24 * time.Hour, int32(fixed.Unix()) + 31*24*3600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_ = store.Set(context.Background(), "k", []byte("v"), tt.ttl)
+			if backend.lastExpiry != tt.expected {
+				t.Errorf("expiration = %d, want %d", backend.lastExpiry, tt.expected)
+			}
+		})
+	}
+}
+
+func TestMemcacheStore_InvalidKey(t *testing.T) {
+	store := NewMemcacheStore(fakeMemcache{newFakeBackend(ErrNotFound)}, nil)
+
+	for _, key := range []string{"", "has space", "line\nbreak", strings.Repeat("k", 251)} {
+		if err := store.Set(context.Background(), key, []byte("v"), 0); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Set(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkMemoryCache_Get(b *testing.B) {
+	cache := NewMemoryCache[int, int](nil)
+	for i := 0; i < 1000; i++ {
+		cache.Set(i, i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Get(i % 1000)
+	}
+}
@@ -0,0 +1,9 @@
+package cacheutil
+
+import "errors"
+
+// ErrNotFound is returned by a Store when a key is missing or expired
+var ErrNotFound = errors.New("cache key not found")
+
+// ErrInvalidKey is returned when a key cannot be stored by the backend
+var ErrInvalidKey = errors.New("invalid cache key")
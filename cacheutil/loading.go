@@ -0,0 +1,200 @@
+package cacheutil
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// LoaderFunc loads the value for a key on a cache miss
+type LoaderFunc[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// LoadingConfig holds configuration for a loading cache
+type LoadingConfig struct {
+	// MaxEntries bounds the cache size (see CacheConfig)
+	MaxEntries int
+
+	// TTL is how long a loaded value is considered fresh (0 means forever)
+	TTL time.Duration
+
+	// StaleWhileRevalidate serves an expired value for up to this long while it is
+	// reloaded in the background, instead of blocking callers on the loader
+	StaleWhileRevalidate time.Duration
+
+	// RefreshErrorHook is called when a background refresh fails; the stale value is kept
+	RefreshErrorHook func(key any, err error)
+}
+
+// LoadingCacheClient defines the interface for a cache that populates itself on misses
+type LoadingCacheClient[K comparable, V any] interface {
+	Get(ctx context.Context, key K) (V, error)
+	Set(key K, value V)
+	Invalidate(key K)
+	InvalidateAll()
+}
+
+// LoadingCache fills misses through a loader, deduplicating concurrent loads of the same key
+type LoadingCache[K comparable, V any] struct {
+	loader LoaderFunc[K, V]
+	config LoadingConfig
+	cache  Cache[K, *loadedValue[V]]
+	flight flightGroup[K, V]
+}
+
+// loadedValue is a cached value with the time it was loaded
+type loadedValue[V any] struct {
+	value    V
+	loadedAt time.Time
+}
+
+// NewLoadingCache creates a loading cache backed by an in-memory cache
+// Pass nil for config to keep loaded values until evicted by size.
+func NewLoadingCache[K comparable, V any](loader LoaderFunc[K, V], config *LoadingConfig) LoadingCacheClient[K, V] {
+	lc := &LoadingCache[K, V]{loader: loader}
+	if config != nil {
+		lc.config = *config
+	}
+
+	var retention time.Duration
+	if lc.config.TTL > 0 {
+		retention = lc.config.TTL + lc.config.StaleWhileRevalidate
+	}
+	lc.cache = NewMemoryCache[K, *loadedValue[V]](&CacheConfig{
+		MaxEntries: lc.config.MaxEntries,
+		DefaultTTL: retention,
+	})
+	return lc
+}
+
+// Get returns the cached value for key, loading it on a miss
+// Concurrent misses for the same key share a single loader call. Stale values within the
+// StaleWhileRevalidate window are returned immediately while a refresh runs in the background.
+func (c *LoadingCache[K, V]) Get(ctx context.Context, key K) (V, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if cached, ok := c.cache.Get(key); ok {
+		if c.config.TTL <= 0 || time.Since(cached.loadedAt) < c.config.TTL {
+			return cached.value, nil
+		}
+		c.refreshInBackground(key)
+		return cached.value, nil
+	}
+
+	return c.flight.do(ctx, key, func() (V, error) {
+		return c.load(ctx, key)
+	})
+}
+
+// Set stores a value directly, bypassing the loader
+func (c *LoadingCache[K, V]) Set(key K, value V) {
+	c.cache.Set(key, &loadedValue[V]{value: value, loadedAt: time.Now()})
+}
+
+// Invalidate removes key so the next Get reloads it
+func (c *LoadingCache[K, V]) Invalidate(key K) {
+	c.cache.Delete(key)
+}
+
+// InvalidateAll removes every entry
+func (c *LoadingCache[K, V]) InvalidateAll() {
+	c.cache.Clear()
+}
+
+// load calls the loader and caches a successful result
+func (c *LoadingCache[K, V]) load(ctx context.Context, key K) (V, error) {
+	value, err := c.loader(ctx, key)
+	if err != nil {
+		return value, err
+	}
+	c.Set(key, value)
+	return value, nil
+}
+
+// refreshInBackground reloads a stale key unless a load for it is already in flight
+func (c *LoadingCache[K, V]) refreshInBackground(key K) {
+	if !c.flight.start(key) {
+		return
+	}
+	go func() {
+		value, err := c.load(context.Background(), key)
+		c.flight.finish(key, value, err)
+		if err != nil && c.config.RefreshErrorHook != nil {
+			c.config.RefreshErrorHook(key, err)
+		}
+	}()
+}
+
+// flightGroup deduplicates concurrent loads of the same key
+type flightGroup[K comparable, V any] struct {
+	mu    sync.Mutex
+	calls map[K]*flightCall[V]
+}
+
+// flightCall is an in-progress load shared by every caller of the same key
+type flightCall[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+// do runs fn once per key at a time; other callers wait for its result or their ctx
+func (g *flightGroup[K, V]) do(ctx context.Context, key K, fn func() (V, error)) (V, error) {
+	g.mu.Lock()
+	if call, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.value, call.err
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+	g.register(key)
+	g.mu.Unlock()
+
+	completed := false
+	defer func() {
+		if !completed { // fn panicked: release waiters before the panic propagates
+			var zero V
+			g.finish(key, zero, fmt.Errorf("cache loader panicked for key %v", key))
+		}
+	}()
+	value, err := fn()
+	completed = true
+	g.finish(key, value, err)
+	return value, err
+}
+
+// start registers a load for key, reporting false if one is already in flight
+func (g *flightGroup[K, V]) start(key K) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, ok := g.calls[key]; ok {
+		return false
+	}
+	g.register(key)
+	return true
+}
+
+// register records a new in-flight call for key; g.mu must be held
+func (g *flightGroup[K, V]) register(key K) {
+	if g.calls == nil {
+		g.calls = make(map[K]*flightCall[V])
+	}
+	g.calls[key] = &flightCall[V]{done: make(chan struct{})}
+}
+
+// finish publishes the result of a load and releases waiters
+func (g *flightGroup[K, V]) finish(key K, value V, err error) {
+	g.mu.Lock()
+	call := g.calls[key]
+	delete(g.calls, key)
+	g.mu.Unlock()
+
+	call.value, call.err = value, err
+	close(call.done)
+}
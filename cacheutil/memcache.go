@@ -0,0 +1,131 @@
+package cacheutil
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// memcacheMaxKeyLength is the longest key memcached accepts
+	memcacheMaxKeyLength = 250
+
+	// memcacheMaxRelativeTTL is the longest expiration memcached treats as relative;
+	// larger values are interpreted as an absolute Unix timestamp
+	memcacheMaxRelativeTTL = 30 * 24 * time.Hour
+)
+
+// MemcacheClient is the subset of memcached operations used by MemcacheStore
+// Expiration follows memcached semantics: seconds, or a Unix timestamp beyond 30 days.
+// Wrap the memcached client of your choice (e.g. gomemcache) to satisfy it.
+type MemcacheClient interface {
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, expiration int32) error
+	Delete(key string) error
+}
+
+// MemcacheConfig holds configuration for a memcached-backed Store
+type MemcacheConfig struct {
+	// KeyPrefix namespaces every key, e.g. "myservice:"
+	KeyPrefix string
+
+	// IsMiss reports whether an error from MemcacheClient means the key is missing
+	// (e.g. errors.Is(err, memcache.ErrCacheMiss)); errors matching ErrNotFound are always misses.
+	IsMiss func(err error) bool
+}
+
+// MemcacheStore adapts a MemcacheClient to the Store interface
+type MemcacheStore struct {
+	client MemcacheClient
+	config MemcacheConfig
+	now    func() time.Time
+}
+
+// NewMemcacheStore creates a Store backed by memcached
+// Pass nil for config to use keys as-is and treat only ErrNotFound as a miss.
+func NewMemcacheStore(client MemcacheClient, config *MemcacheConfig) Store {
+	store := &MemcacheStore{client: client, now: time.Now}
+	if config != nil {
+		store.config = *config
+	}
+	return store
+}
+
+// Get returns the value for key or ErrNotFound
+func (s *MemcacheStore) Get(ctx context.Context, key string) ([]byte, error) {
+	fullKey, err := s.key(key)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	value, err := s.client.Get(fullKey)
+	if err != nil {
+		if isMiss(err, s.config.IsMiss) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("memcache get %q failed: %w", key, err)
+	}
+	return value, nil
+}
+
+// Set stores value under key with the given ttl
+func (s *MemcacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	fullKey, err := s.key(key)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.client.Set(fullKey, value, s.expiration(ttl)); err != nil {
+		return fmt.Errorf("memcache set %q failed: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes key; deleting a missing key is not an error
+func (s *MemcacheStore) Delete(ctx context.Context, key string) error {
+	fullKey, err := s.key(key)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := s.client.Delete(fullKey); err != nil && !isMiss(err, s.config.IsMiss) {
+		return fmt.Errorf("memcache delete %q failed: %w", key, err)
+	}
+	return nil
+}
+
+// key applies the prefix and checks memcached's key restrictions
+func (s *MemcacheStore) key(key string) (string, error) {
+	full := s.config.KeyPrefix + key
+	if full == "" || len(full) > memcacheMaxKeyLength {
+		return "", fmt.Errorf("%w: length must be 1-%d bytes", ErrInvalidKey, memcacheMaxKeyLength)
+	}
+	for i := 0; i < len(full); i++ {
+		if full[i] <= ' ' || full[i] == 0x7f {
+			return "", fmt.Errorf("%w: %q contains whitespace or control characters", ErrInvalidKey, full)
+		}
+	}
+	return full, nil
+}
+
+// expiration converts a ttl into memcached's expiration format
+func (s *MemcacheStore) expiration(ttl time.Duration) int32 {
+	if ttl <= 0 {
+		return 0
+	}
+	seconds := int64(math.Ceil(ttl.Seconds()))
+	if ttl > memcacheMaxRelativeTTL {
+		seconds = s.now().Unix() + seconds
+	}
+	if seconds > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	return int32(seconds)
+}
@@ -0,0 +1,107 @@
+package cacheutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+)
+
+// memoKey is the context key of the request-scoped memo installed by WithMemo
+type memoKey struct{}
+
+// memo holds the values computed within one request
+type memo struct {
+	mu      sync.Mutex
+	entries map[string]*memoEntry
+}
+
+// memoEntry is a computed value, or a computation in progress shared by concurrent callers
+type memoEntry struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+// WithMemo returns a context carrying an empty memo for Memoize
+// Values live exactly as long as the returned context is referenced, so there is nothing to invalidate:
+// install it once per request, e.g. with MemoMiddleware.
+func WithMemo(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, memoKey{}, &memo{entries: make(map[string]*memoEntry)})
+}
+
+// MemoMiddleware installs a fresh memo in the context of every request handled by next
+func MemoMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(WithMemo(r.Context())))
+	})
+}
+
+// Memoize returns the value computed for key within the current request, calling fn only on the first use
+// Concurrent calls for the same key share one fn call. Errors are not memoized, so a later call retries.
+// Without a memo in ctx (see WithMemo) fn is simply called every time. Keys are shared by every type
+// memoized in a request, so scope them, e.g. "user:42"; reusing a key for another type returns an error.
+func Memoize[V any](ctx context.Context, key string, fn func(ctx context.Context) (V, error)) (V, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	m, ok := ctx.Value(memoKey{}).(*memo)
+	if !ok {
+		return fn(ctx)
+	}
+
+	m.mu.Lock()
+	entry, found := m.entries[key]
+	if !found {
+		entry = &memoEntry{done: make(chan struct{})}
+		m.entries[key] = entry
+	}
+	m.mu.Unlock()
+
+	if !found {
+		computeMemo(ctx, m, key, entry, fn)
+	} else {
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			var zero V
+			return zero, ctx.Err()
+		}
+	}
+
+	var zero V
+	if entry.err != nil {
+		return zero, entry.err
+	}
+	if entry.value == nil {
+		return zero, nil
+	}
+	value, ok := entry.value.(V)
+	if !ok {
+		return zero, fmt.Errorf("cacheutil: memoized value for key %q is %T, not %v", key, entry.value, reflect.TypeOf(&zero).Elem())
+	}
+	return value, nil
+}
+
+// computeMemo runs fn for a new entry and releases waiters; entries that fail or panic are forgotten
+func computeMemo[V any](ctx context.Context, m *memo, key string, entry *memoEntry, fn func(ctx context.Context) (V, error)) {
+	completed := false
+	defer func() {
+		if !completed {
+			entry.err = fmt.Errorf("cacheutil: memoized function panicked for key %q", key)
+		}
+		if entry.err != nil {
+			m.mu.Lock()
+			delete(m.entries, key)
+			m.mu.Unlock()
+		}
+		close(entry.done)
+	}()
+	value, err := fn(ctx)
+	completed = true
+	entry.value, entry.err = value, err
+}
@@ -0,0 +1,79 @@
+package cacheutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RedisClient is the subset of Redis commands used by RedisStore
+// Wrap the Redis client of your choice (e.g. go-redis) to satisfy it, so this package
+// does not pull a Redis driver into every consumer.
+type RedisClient interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Del(ctx context.Context, keys ...string) error
+}
+
+// RedisConfig holds configuration for a Redis-backed Store
+type RedisConfig struct {
+	// KeyPrefix namespaces every key, e.g. "myservice:"
+	KeyPrefix string
+
+	// IsMiss reports whether an error from RedisClient.Get means the key is missing
+	// (e.g. errors.Is(err, redis.Nil)); errors matching ErrNotFound are always misses.
+	IsMiss func(err error) bool
+}
+
+// RedisStore adapts a RedisClient to the Store interface
+type RedisStore struct {
+	client RedisClient
+	config RedisConfig
+}
+
+// NewRedisStore creates a Store backed by Redis
+// Pass nil for config to use keys as-is and treat only ErrNotFound as a miss.
+func NewRedisStore(client RedisClient, config *RedisConfig) Store {
+	store := &RedisStore{client: client}
+	if config != nil {
+		store.config = *config
+	}
+	return store
+}
+
+// Get returns the value for key or ErrNotFound
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, err := s.client.Get(ctx, s.config.KeyPrefix+key)
+	if err != nil {
+		if isMiss(err, s.config.IsMiss) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("redis get %q failed: %w", key, err)
+	}
+	return value, nil
+}
+
+// Set stores value under key with the given ttl
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if ttl < 0 {
+		ttl = 0
+	}
+	if err := s.client.Set(ctx, s.config.KeyPrefix+key, value, ttl); err != nil {
+		return fmt.Errorf("redis set %q failed: %w", key, err)
+	}
+	return nil
+}
+
+// Delete removes key
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, s.config.KeyPrefix+key); err != nil {
+		return fmt.Errorf("redis delete %q failed: %w", key, err)
+	}
+	return nil
+}
+
+// isMiss reports whether err from a backend means the key does not exist
+func isMiss(err error, custom func(error) bool) bool {
+	return errors.Is(err, ErrNotFound) || (custom != nil && custom(err))
+}
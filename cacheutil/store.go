@@ -0,0 +1,46 @@
+package cacheutil
+
+import (
+	"context"
+	"time"
+)
+
+// Store defines a byte-oriented cache backend that may be shared between processes
+// Get returns ErrNotFound on a miss. A ttl of 0 means the entry does not expire.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+}
+
+// MemoryStore adapts MemoryCache to the Store interface for single-process use and tests
+type MemoryStore struct {
+	cache Cache[string, []byte]
+}
+
+// NewMemoryStore creates an in-process Store
+// Pass nil for config to use the MemoryCache defaults.
+func NewMemoryStore(config *CacheConfig) Store {
+	return &MemoryStore{cache: NewMemoryCache[string, []byte](config)}
+}
+
+// Get returns a copy of the stored bytes or ErrNotFound
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	value, ok := s.cache.Get(key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
+
+// Set stores a copy of value under key
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.cache.SetWithTTL(key, append([]byte(nil), value...), ttl)
+	return nil
+}
+
+// Delete removes key; deleting a missing key is not an error
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.cache.Delete(key)
+	return nil
+}
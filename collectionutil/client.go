@@ -3,10 +3,10 @@ package collectionutil
 import (
 	"fmt"
 	"reflect"
-	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/mustanish/common-utils/v2/mathutil"
 	"github.com/thoas/go-funk"
 )
 
@@ -54,6 +54,9 @@ type CollectionClient interface {
 
 type CollectionUtil struct{}
 
+// safeMath performs the overflow-checked numeric conversions
+var safeMath = mathutil.NewMathUtil()
+
 // NewCollectionUtil creates a new instance of CollectionUtil
 func NewCollectionUtil() CollectionClient {
 	return &CollectionUtil{}
@@ -103,6 +106,7 @@ func (c *CollectionUtil) KeyExistsAndNotEmpty(value map[string]string, key strin
 }
 
 // ConvertToInteger converts a value to an integer with error handling
+// Floats with a fractional part and values that do not fit in an int return an error instead of being truncated.
 func (c *CollectionUtil) ConvertToInteger(value any) (int, error) {
 	switch v := value.(type) {
 	case int:
@@ -110,14 +114,17 @@ func (c *CollectionUtil) ConvertToInteger(value any) (int, error) {
 	case int32:
 		return int(v), nil
 	case int64:
-		return int(v), nil
+		return safeMath.Int64ToInt(v)
 	case float32:
-		return int(v), nil
+		return safeMath.Float64ToInt(float64(v))
 	case float64:
-		return int(v), nil
+		return safeMath.Float64ToInt(v)
 	case string:
 		val, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64)
-		return int(val), err
+		if err != nil {
+			return 0, err
+		}
+		return safeMath.Int64ToInt(val)
 	case bool:
 		if v {
 			return 1, nil
@@ -129,6 +136,7 @@ func (c *CollectionUtil) ConvertToInteger(value any) (int, error) {
 }
 
 // ConvertToInt64 converts a value to int64
+// Floats with a fractional part and values that do not fit in an int64 return an error instead of being truncated.
 func (c *CollectionUtil) ConvertToInt64(value any) (int64, error) {
 	switch v := value.(type) {
 	case int:
@@ -138,9 +146,9 @@ func (c *CollectionUtil) ConvertToInt64(value any) (int64, error) {
 	case int64:
 		return v, nil
 	case float32:
-		return int64(v), nil
+		return safeMath.Float64ToInt64(float64(v))
 	case float64:
-		return int64(v), nil
+		return safeMath.Float64ToInt64(v)
 	case string:
 		return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
 	case bool:
@@ -223,20 +231,7 @@ func (c *CollectionUtil) ConvertToSlice(value any, separator string) ([]string,
 		return nil, fmt.Errorf("expected string value for slice conversion, got %T", value)
 	}
 
-	if separator == "" {
-		separator = ","
-	}
-
-	if strings.TrimSpace(strValue) == "" {
-		return []string{}, nil
-	}
-
-	parts := strings.Split(strValue, separator)
-	for i, part := range parts {
-		parts[i] = strings.TrimSpace(part)
-	}
-
-	return parts, nil
+	return SplitAndTrim(strValue, separator), nil
 }
 
 // ConvertToMap converts a slice of key-value maps to a single map
@@ -323,14 +318,12 @@ func (c *CollectionUtil) SliceChunk(slice []string, size int) [][]string {
 
 // MapKeys returns all keys from a map
 func (c *CollectionUtil) MapKeys(m map[string]any) []string {
-	keys := funk.Keys(m).([]string)
-	sort.Strings(keys) // Sort for consistent output
-	return keys
+	return Keys(m, Ascending[string]) // Sort for consistent output
 }
 
 // MapValues returns all values from a map
 func (c *CollectionUtil) MapValues(m map[string]any) []any {
-	return funk.Values(m).([]any)
+	return Values(m)
 }
 
 // MapMerge merges multiple maps into one (later maps override earlier ones)
@@ -1,7 +1,11 @@
 package collectionutil
 
 import (
+	"errors"
+	"math"
 	"reflect"
+	"sort"
+	"sync"
 	"testing"
 )
 
@@ -109,6 +113,9 @@ func TestConvertToInteger(t *testing.T) {
 		{"bool false", false, 0, false},
 		{"invalid string", "abc", 0, true},
 		{"slice", []string{"a"}, 0, true},
+		{"float64 fraction", 42.5, 0, true},
+		{"float64 overflow", 1e20, 0, true},
+		{"float64 NaN", math.NaN(), 0, true},
 	}
 
 	for _, tt := range tests {
@@ -295,6 +302,9 @@ func TestConvertToInt64(t *testing.T) {
 		{"int64", int64(42), 42, false},
 		{"string", "42", 42, false},
 		{"invalid string", "abc", 0, true},
+		{"float64", 42.0, 42, false},
+		{"float64 fraction", 42.5, 0, true},
+		{"float64 overflow", 1e19, 0, true},
 	}
 
 	for _, tt := range tests {
@@ -588,6 +598,383 @@ func TestMapOmit(t *testing.T) {
 	}
 }
 
+func TestKeys(t *testing.T) {
+	m := map[int]string{3: "c", 1: "a", 2: "b"}
+
+	tests := []struct {
+		name string
+		less []func(a, b int) bool
+		want []int
+	}{
+		{"ascending", []func(a, b int) bool{Ascending[int]}, []int{1, 2, 3}},
+		{"descending", []func(a, b int) bool{Descending[int]}, []int{3, 2, 1}},
+		{"custom", []func(a, b int) bool{func(a, b int) bool { return a%3 < b%3 }}, []int{3, 1, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Keys(m, tt.less...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Keys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	unordered := Keys(m)
+	sort.Ints(unordered)
+	if !reflect.DeepEqual(unordered, []int{1, 2, 3}) {
+		t.Errorf("Keys() without comparator = %v, want all keys", unordered)
+	}
+	if got := Keys(map[string]int(nil)); len(got) != 0 {
+		t.Errorf("Keys(nil) = %v, want empty", got)
+	}
+}
+
+func TestValues(t *testing.T) {
+	m := map[string]float64{"x": 2.5, "y": -1, "z": 10}
+
+	if got, want := Values(m, Ascending[float64]), []float64{-1, 2.5, 10}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Values(Ascending) = %v, want %v", got, want)
+	}
+	if got := Values(m); len(got) != 3 {
+		t.Errorf("Values() = %v, want 3 values", got)
+	}
+}
+
+func TestEntries(t *testing.T) {
+	got := Entries(map[string]int{"b": 2, "a": 1})
+	want := []Entry[string, int]{{Key: "a", Value: 1}, {Key: "b", Value: 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Entries() = %v, want %v", got, want)
+	}
+}
+
+func TestSortedByValue(t *testing.T) {
+	counts := map[string]int{"go": 5, "rust": 3, "zig": 5, "c": 9}
+
+	got := SortedByValue(counts, Descending[int])
+	want := []Entry[string, int]{{"c", 9}, {"go", 5}, {"zig", 5}, {"rust", 3}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SortedByValue() = %v, want %v", got, want)
+	}
+}
+
+// =================== Test String Helpers ===================
+
+func TestJoinNonEmpty(t *testing.T) {
+	tests := []struct {
+		name  string
+		sep   string
+		parts []string
+		want  string
+	}{
+		{"skips blanks", ", ", []string{"a", " ", "", "b "}, "a, b"},
+		{"all blank", ",", []string{"", "  "}, ""},
+		{"no parts", ",", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := JoinNonEmpty(tt.sep, tt.parts...); got != tt.want {
+				t.Errorf("JoinNonEmpty() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitAndTrim(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		sep          string
+		want         []string
+		wantNonEmpty []string
+	}{
+		{"default separator", " a , b,c ", "", []string{"a", "b", "c"}, []string{"a", "b", "c"}},
+		{"keeps empty parts", "gzip, , br", ",", []string{"gzip", "", "br"}, []string{"gzip", "br"}},
+		{"custom separator", "a | b", "|", []string{"a", "b"}, []string{"a", "b"}},
+		{"blank input", "   ", ",", []string{}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitAndTrim(tt.input, tt.sep); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitAndTrim() = %q, want %q", got, tt.want)
+			}
+			if got := SplitAndTrimNonEmpty(tt.input, tt.sep); !reflect.DeepEqual(got, tt.wantNonEmpty) {
+				t.Errorf("SplitAndTrimNonEmpty() = %q, want %q", got, tt.wantNonEmpty)
+			}
+		})
+	}
+}
+
+func TestSplitExactly(t *testing.T) {
+	got, err := SplitExactly("10 x 20", "x", 2)
+	if err != nil || !reflect.DeepEqual(got, []string{"10", "20"}) {
+		t.Errorf("SplitExactly() = %q, %v", got, err)
+	}
+
+	_, err = SplitExactly("a:b:c", ":", 2)
+	var splitErr *SplitError
+	if !errors.As(err, &splitErr) || splitErr.Want != 2 || splitErr.Got != 3 {
+		t.Errorf("SplitExactly() error = %v, want SplitError{Want: 2, Got: 3}", err)
+	}
+	if !errors.Is(err, ErrSplitCount) {
+		t.Errorf("SplitExactly() error should match ErrSplitCount")
+	}
+}
+
+func TestSplitAtMost(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		minParts int
+		maxParts int
+		want     []string
+		wantErr  bool
+	}{
+		{"remainder kept", "Bearer abc def", 2, 2, []string{"Bearer", "abc def"}, false},
+		{"fewer than max", "Basic", 1, 2, []string{"Basic"}, false},
+		{"below min", "Basic", 2, 2, nil, true},
+		{"no limit", "a b c", 1, 0, []string{"a", "b", "c"}, false},
+		{"blank", "", 1, 2, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SplitAtMost(tt.input, " ", tt.minParts, tt.maxParts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitAtMost() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitAtMost() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitPair(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantKey   string
+		wantValue string
+		wantErr   bool
+	}{
+		{"timeout = 30s", "timeout", "30s", false},
+		{"url=http://x?a=b", "url", "http://x?a=b", false},
+		{"flag=", "flag", "", false},
+		{"novalue", "", "", true},
+		{"=value", "", "", true},
+		{"", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			key, value, err := SplitPair(tt.input, "=")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SplitPair(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if key != tt.wantKey || value != tt.wantValue {
+				t.Errorf("SplitPair(%q) = %q, %q, want %q, %q", tt.input, key, value, tt.wantKey, tt.wantValue)
+			}
+			if err != nil && !errors.Is(err, ErrSplitCount) {
+				t.Errorf("SplitPair(%q) error should match ErrSplitCount", tt.input)
+			}
+		})
+	}
+}
+
+// =================== Test Index Builders ===================
+
+type indexedUser struct {
+	ID    int
+	Email string
+	Team  string
+}
+
+func TestBuildIndex(t *testing.T) {
+	users := []indexedUser{
+		{1, "a@x.io", "core"},
+		{2, "b@x.io", "web"},
+		{3, "a@x.io", "web"},
+		{4, "c@x.io", "core"},
+		{5, "a@x.io", "ops"},
+		{6, "b@x.io", "ops"},
+	}
+
+	byEmail, duplicates := BuildIndex(users, func(u indexedUser) string { return u.Email })
+	if len(byEmail) != 3 || byEmail["a@x.io"].ID != 1 || byEmail["b@x.io"].ID != 2 {
+		t.Errorf("BuildIndex() = %v, want the first user per email", byEmail)
+	}
+	if !reflect.DeepEqual(duplicates, []string{"a@x.io", "b@x.io"}) {
+		t.Errorf("BuildIndex() duplicates = %v, want [a@x.io b@x.io]", duplicates)
+	}
+
+	byID, idDuplicates := BuildIndex(users, func(u indexedUser) int { return u.ID })
+	if len(byID) != len(users) || idDuplicates != nil {
+		t.Errorf("BuildIndex() by ID = %d entries, duplicates %v", len(byID), idDuplicates)
+	}
+
+	empty, idDuplicates := BuildIndex[indexedUser, int](nil, func(u indexedUser) int { return u.ID })
+	if empty == nil || len(empty) != 0 || idDuplicates != nil {
+		t.Errorf("BuildIndex(nil) = %v, %v, want an empty map", empty, idDuplicates)
+	}
+}
+
+func TestBuildMultiIndex(t *testing.T) {
+	users := []indexedUser{
+		{1, "a@x.io", "core"},
+		{2, "b@x.io", "web"},
+		{3, "a@x.io", "web"},
+	}
+
+	indexes := BuildMultiIndex(users,
+		func(u indexedUser) string { return u.Team },
+		func(u indexedUser) string { return u.Email },
+	)
+	if len(indexes) != 2 {
+		t.Fatalf("len(BuildMultiIndex()) = %d, want 2", len(indexes))
+	}
+	byTeam, byEmail := indexes[0], indexes[1]
+	if got := byTeam["web"]; !reflect.DeepEqual(got, []indexedUser{users[1], users[2]}) {
+		t.Errorf("byTeam[web] = %v, want users 2 and 3 in order", got)
+	}
+	if got := byEmail["a@x.io"]; !reflect.DeepEqual(got, []indexedUser{users[0], users[2]}) {
+		t.Errorf("byEmail[a@x.io] = %v, want users 1 and 3 in order", got)
+	}
+	if len(BuildMultiIndex[indexedUser, string](users)) != 0 {
+		t.Error("BuildMultiIndex() without key functions should return no indexes")
+	}
+}
+
+// =================== Test Ring Buffer ===================
+
+func TestBinarySearchBy(t *testing.T) {
+	users := []indexedUser{{1, "a@x.io", "core"}, {3, "b@x.io", "web"}, {3, "c@x.io", "ops"}, {7, "d@x.io", "core"}}
+	byID := func(u indexedUser) int { return u.ID }
+
+	tests := []struct {
+		target int
+		index  int
+		found  bool
+	}{
+		{1, 0, true},
+		{3, 1, true},
+		{7, 3, true},
+		{0, 0, false},
+		{4, 3, false},
+		{9, 4, false},
+	}
+	for _, tt := range tests {
+		index, found := BinarySearchBy(users, tt.target, byID)
+		if index != tt.index || found != tt.found {
+			t.Errorf("BinarySearchBy(%d) = %d, %v; want %d, %v", tt.target, index, found, tt.index, tt.found)
+		}
+	}
+	if index, found := BinarySearchBy(nil, 1, byID); index != 0 || found {
+		t.Errorf("BinarySearchBy(nil) = %d, %v; want 0, false", index, found)
+	}
+}
+
+func TestInsertSorted(t *testing.T) {
+	var ids []int
+	for _, id := range []int{5, 1, 9, 3, 7} {
+		ids = InsertSorted(ids, id, Ascending[int])
+	}
+	if !reflect.DeepEqual(ids, []int{1, 3, 5, 7, 9}) {
+		t.Errorf("InsertSorted() = %v, want [1 3 5 7 9]", ids)
+	}
+
+	byTeam := func(a, b indexedUser) bool { return a.Team < b.Team }
+	var users []indexedUser
+	for _, u := range []indexedUser{{1, "a@x.io", "web"}, {2, "b@x.io", "core"}, {3, "c@x.io", "web"}, {4, "d@x.io", "core"}} {
+		users = InsertSorted(users, u, byTeam)
+	}
+	var order []int
+	for _, u := range users {
+		order = append(order, u.ID)
+	}
+	if !reflect.DeepEqual(order, []int{2, 4, 1, 3}) {
+		t.Errorf("InsertSorted() order = %v, want equal teams in insertion order [2 4 1 3]", order)
+	}
+}
+
+func TestRingBuffer(t *testing.T) {
+	tests := []struct {
+		name     string
+		capacity int
+		push     []int
+		want     []int
+	}{
+		{"empty", 3, nil, []int{}},
+		{"partially filled", 3, []int{1, 2}, []int{1, 2}},
+		{"exactly full", 3, []int{1, 2, 3}, []int{1, 2, 3}},
+		{"overwrites oldest", 3, []int{1, 2, 3, 4, 5}, []int{3, 4, 5}},
+		{"wraps several times", 2, []int{1, 2, 3, 4, 5, 6, 7}, []int{6, 7}},
+		{"capacity below one", 0, []int{1, 2}, []int{2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRingBuffer[int](tt.capacity)
+			for _, v := range tt.push {
+				r.Push(v)
+			}
+			if got := r.Snapshot(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Snapshot() = %v, want %v", got, tt.want)
+			}
+			if r.Len() != len(tt.want) {
+				t.Errorf("Len() = %d, want %d", r.Len(), len(tt.want))
+			}
+		})
+	}
+
+	r := NewRingBuffer[string](3)
+	r.Push("a", "b", "c", "d")
+	snap := r.Snapshot()
+	snap[0] = "changed"
+	if got := r.Snapshot(); !reflect.DeepEqual(got, []string{"b", "c", "d"}) {
+		t.Errorf("Snapshot() = %v, want a copy unaffected by callers", got)
+	}
+	r.Clear()
+	if r.Len() != 0 || r.Cap() != 3 || len(r.Snapshot()) != 0 {
+		t.Errorf("after Clear() Len = %d, Cap = %d, want 0 and 3", r.Len(), r.Cap())
+	}
+	r.Push("e")
+	if got := r.Snapshot(); !reflect.DeepEqual(got, []string{"e"}) {
+		t.Errorf("Snapshot() after Clear and Push = %v, want [e]", got)
+	}
+}
+
+func TestRingBuffer_ConcurrentWriters(t *testing.T) {
+	r := NewRingBuffer[int](50)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				r.Push(w*1000 + i)
+				_ = r.Snapshot()
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	snap := r.Snapshot()
+	if len(snap) != 50 {
+		t.Fatalf("len(Snapshot()) = %d, want 50", len(snap))
+	}
+	// Values from each writer must stay in push order
+	last := map[int]int{}
+	for _, v := range snap {
+		w, i := v/1000, v%1000
+		if prev, ok := last[w]; ok && i <= prev {
+			t.Errorf("writer %d values out of order: %d after %d", w, i, prev)
+		}
+		last[w] = i
+	}
+}
+
 // =================== Test Utility Methods ===================
 
 func TestFindInSlice(t *testing.T) {
@@ -658,3 +1045,60 @@ func BenchmarkConvertToInteger(b *testing.B) {
 		_, _ = util.ConvertToInteger("12345")
 	}
 }
+
+func TestMapDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new map[string]string
+		expected MapPatch
+	}{
+		{name: "both empty", expected: MapPatch{}},
+		{
+			name: "add remove change",
+			old:  map[string]string{"team": "core", "tier": "2", "legacy": "yes", "owner": "ann"},
+			new:  map[string]string{"team": "core", "tier": "1", "env": "prod", "owner": "bob"},
+			expected: MapPatch{
+				Added:   []Entry[string, string]{{Key: "env", Value: "prod"}},
+				Removed: []string{"legacy"},
+				Changed: []MapChange{{Key: "owner", Old: "ann", New: "bob"}, {Key: "tier", Old: "2", New: "1"}},
+			},
+		},
+		{
+			name:     "empty values are present",
+			old:      map[string]string{"a": ""},
+			new:      map[string]string{"b": ""},
+			expected: MapPatch{Added: []Entry[string, string]{{Key: "b", Value: ""}}, Removed: []string{"a"}},
+		},
+		{name: "equal maps", old: map[string]string{"a": "1"}, new: map[string]string{"a": "1"}, expected: MapPatch{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch := MapDiff(tt.old, tt.new)
+			if !reflect.DeepEqual(patch, tt.expected) {
+				t.Errorf("MapDiff() = %+v, want %+v", patch, tt.expected)
+			}
+			if patch.IsEmpty() != tt.expected.IsEmpty() {
+				t.Errorf("IsEmpty() = %v, want %v", patch.IsEmpty(), tt.expected.IsEmpty())
+			}
+			applied := ApplyMapPatch(tt.old, patch)
+			if len(applied) != len(tt.new) || (len(tt.new) > 0 && !reflect.DeepEqual(applied, tt.new)) {
+				t.Errorf("ApplyMapPatch(old, MapDiff(old, new)) = %v, want %v", applied, tt.new)
+			}
+		})
+	}
+}
+
+func TestApplyMapPatch_Drifted(t *testing.T) {
+	patch := MapDiff(map[string]string{"tier": "2", "legacy": "yes"}, map[string]string{"tier": "1", "env": "prod"})
+	target := map[string]string{"tier": "3", "region": "eu"}
+
+	got := ApplyMapPatch(target, patch)
+	want := map[string]string{"tier": "1", "env": "prod", "region": "eu"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ApplyMapPatch() = %v, want %v", got, want)
+	}
+	if target["tier"] != "3" || len(target) != 2 {
+		t.Errorf("ApplyMapPatch() modified its input: %v", target)
+	}
+}
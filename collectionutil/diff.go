@@ -0,0 +1,61 @@
+package collectionutil
+
+// MapChange is a key present in both maps with different values
+type MapChange struct {
+	Key string
+	Old string
+	New string
+}
+
+// MapPatch lists the edits that turn one string map into another, e.g. to sync labels or annotations
+type MapPatch struct {
+	Added   []Entry[string, string] // keys only in the new map, ordered by key
+	Removed []string                // keys only in the old map, ordered
+	Changed []MapChange             // keys whose value changed, ordered by key
+}
+
+// IsEmpty reports whether the patch makes no changes
+func (p MapPatch) IsEmpty() bool {
+	return len(p.Added) == 0 && len(p.Removed) == 0 && len(p.Changed) == 0
+}
+
+// MapDiff compares oldMap with newMap; every list in the result is sorted by key so it can be logged or compared
+// A key mapped to "" is present, so going from "" to missing is a removal rather than no change.
+func MapDiff(oldMap, newMap map[string]string) MapPatch {
+	var patch MapPatch
+	for _, key := range Keys(newMap, Ascending[string]) {
+		oldValue, ok := oldMap[key]
+		switch {
+		case !ok:
+			patch.Added = append(patch.Added, Entry[string, string]{Key: key, Value: newMap[key]})
+		case oldValue != newMap[key]:
+			patch.Changed = append(patch.Changed, MapChange{Key: key, Old: oldValue, New: newMap[key]})
+		}
+	}
+	for _, key := range Keys(oldMap, Ascending[string]) {
+		if _, ok := newMap[key]; !ok {
+			patch.Removed = append(patch.Removed, key)
+		}
+	}
+	return patch
+}
+
+// ApplyMapPatch returns a copy of m with patch applied, leaving m unchanged
+// Added and changed keys take their new value whatever m holds, so a patch computed against one system can
+// be applied to another that has drifted; removing a key m lacks is a no-op.
+func ApplyMapPatch(m map[string]string, patch MapPatch) map[string]string {
+	result := make(map[string]string, len(m)+len(patch.Added))
+	for k, v := range m {
+		result[k] = v
+	}
+	for _, key := range patch.Removed {
+		delete(result, key)
+	}
+	for _, entry := range patch.Added {
+		result[entry.Key] = entry.Value
+	}
+	for _, change := range patch.Changed {
+		result[change.Key] = change.New
+	}
+	return result
+}
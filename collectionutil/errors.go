@@ -0,0 +1,27 @@
+package collectionutil
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSplitCount is matched by errors.Is for every *SplitError
+var ErrSplitCount = errors.New("unexpected number of parts")
+
+// SplitError is returned when a string does not split into the expected number of parts
+type SplitError struct {
+	Input string
+	Sep   string
+	Want  int
+	Got   int
+}
+
+// Error implements the error interface for SplitError
+func (e *SplitError) Error() string {
+	return fmt.Sprintf("splitting %q on %q: want %d parts, got %d", e.Input, e.Sep, e.Want, e.Got)
+}
+
+// Is reports whether target is ErrSplitCount
+func (e *SplitError) Is(target error) bool {
+	return target == ErrSplitCount
+}
@@ -0,0 +1,38 @@
+package collectionutil
+
+// BuildIndex maps every item by the key returned by keyFn, keeping the first item for each key
+// Keys that occur more than once are returned in the order their first duplicate was seen, so
+// callers can reject or log ambiguous data, e.g. two users with the same email.
+func BuildIndex[T any, K comparable](items []T, keyFn func(T) K) (map[K]T, []K) {
+	index := make(map[K]T, len(items))
+	var duplicates []K
+	reported := map[K]bool{}
+	for _, item := range items {
+		key := keyFn(item)
+		if _, exists := index[key]; exists {
+			if !reported[key] {
+				reported[key] = true
+				duplicates = append(duplicates, key)
+			}
+			continue
+		}
+		index[key] = item
+	}
+	return index, duplicates
+}
+
+// BuildMultiIndex builds one multi-valued index per key function in a single pass over items
+// The i-th map groups items by keyFns[i], with each group in the original item order.
+func BuildMultiIndex[T any, K comparable](items []T, keyFns ...func(T) K) []map[K][]T {
+	indexes := make([]map[K][]T, len(keyFns))
+	for i := range indexes {
+		indexes[i] = make(map[K][]T)
+	}
+	for _, item := range items {
+		for i, keyFn := range keyFns {
+			key := keyFn(item)
+			indexes[i][key] = append(indexes[i][key], item)
+		}
+	}
+	return indexes
+}
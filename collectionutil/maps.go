@@ -0,0 +1,71 @@
+package collectionutil
+
+import "sort"
+
+// Ordered is satisfied by types that support the < operator
+type Ordered interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64 | ~string
+}
+
+// Entry is a single key/value pair of a map
+type Entry[K comparable, V any] struct {
+	Key   K
+	Value V
+}
+
+// Ascending is a less function ordering values from low to high
+func Ascending[T Ordered](a, b T) bool {
+	return a < b
+}
+
+// Descending is a less function ordering values from high to low
+func Descending[T Ordered](a, b T) bool {
+	return a > b
+}
+
+// Keys returns the keys of m
+// Without less the order is unspecified; with less the keys are sorted by it, e.g. Keys(m, Ascending[int]).
+func Keys[K comparable, V any](m map[K]V, less ...func(a, b K) bool) []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	if len(less) > 0 && less[0] != nil {
+		sort.Slice(keys, func(i, j int) bool { return less[0](keys[i], keys[j]) })
+	}
+	return keys
+}
+
+// Values returns the values of m
+// Without less the order is unspecified; with less the values are sorted by it.
+func Values[K comparable, V any](m map[K]V, less ...func(a, b V) bool) []V {
+	values := make([]V, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	if len(less) > 0 && less[0] != nil {
+		sort.SliceStable(values, func(i, j int) bool { return less[0](values[i], values[j]) })
+	}
+	return values
+}
+
+// Entries returns the key/value pairs of m ordered by key
+func Entries[K Ordered, V any](m map[K]V) []Entry[K, V] {
+	entries := make([]Entry[K, V], 0, len(m))
+	for _, k := range Keys(m, Ascending[K]) {
+		entries = append(entries, Entry[K, V]{Key: k, Value: m[k]})
+	}
+	return entries
+}
+
+// SortedByValue returns the entries of m ordered by value using less
+// Entries with equal values are ordered by key, so the result is deterministic.
+func SortedByValue[K Ordered, V any](m map[K]V, less func(a, b V) bool) []Entry[K, V] {
+	entries := Entries(m)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return less(entries[i].Value, entries[j].Value)
+	})
+	return entries
+}
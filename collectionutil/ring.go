@@ -0,0 +1,69 @@
+package collectionutil
+
+import "sync"
+
+// RingBuffer is a fixed-capacity buffer that keeps the most recent values
+// Once full, every Push overwrites the oldest value. It is safe for concurrent use.
+type RingBuffer[T any] struct {
+	mu    sync.Mutex
+	items []T
+	start int // index of the oldest value
+	size  int
+}
+
+// NewRingBuffer creates a ring buffer holding up to capacity values (at least one)
+func NewRingBuffer[T any](capacity int) *RingBuffer[T] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &RingBuffer[T]{items: make([]T, capacity)}
+}
+
+// Push appends values in order, overwriting the oldest ones when the buffer is full
+func (r *RingBuffer[T]) Push(values ...T) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range values {
+		end := (r.start + r.size) % len(r.items)
+		r.items[end] = v
+		if r.size < len(r.items) {
+			r.size++
+		} else {
+			r.start = (r.start + 1) % len(r.items)
+		}
+	}
+}
+
+// Snapshot returns a copy of the buffered values, oldest first
+func (r *RingBuffer[T]) Snapshot() []T {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make([]T, r.size)
+	for i := range out {
+		out[i] = r.items[(r.start+i)%len(r.items)]
+	}
+	return out
+}
+
+// Len returns the number of buffered values
+func (r *RingBuffer[T]) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.size
+}
+
+// Cap returns the maximum number of values the buffer holds
+func (r *RingBuffer[T]) Cap() int {
+	return len(r.items)
+}
+
+// Clear removes every value, releasing references held by the buffer
+func (r *RingBuffer[T]) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var zero T
+	for i := range r.items {
+		r.items[i] = zero
+	}
+	r.start, r.size = 0, 0
+}
@@ -0,0 +1,27 @@
+package collectionutil
+
+import "sort"
+
+// BinarySearchBy finds target in slice, which must be sorted in ascending order of keyFn
+// It returns the position of the first item whose key is target and true, or the position where an item
+// with that key would be inserted and false, as sort.Search does.
+func BinarySearchBy[T any, K Ordered](slice []T, target K, keyFn func(T) K) (int, bool) {
+	i := sort.Search(len(slice), func(i int) bool {
+		return !(keyFn(slice[i]) < target)
+	})
+	return i, i < len(slice) && keyFn(slice[i]) == target
+}
+
+// InsertSorted inserts item into slice, which must be sorted by less, and returns the updated slice
+// Equal items keep their insertion order: item goes after any it does not sort before. Like append, the
+// result may share the backing array of slice, so use the returned slice.
+func InsertSorted[T any](slice []T, item T, less func(a, b T) bool) []T {
+	i := sort.Search(len(slice), func(i int) bool {
+		return less(item, slice[i])
+	})
+	var zero T
+	slice = append(slice, zero)
+	copy(slice[i+1:], slice[i:])
+	slice[i] = item
+	return slice
+}
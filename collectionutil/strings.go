@@ -0,0 +1,92 @@
+package collectionutil
+
+import "strings"
+
+// JoinNonEmpty joins the parts that are not blank with sep
+// Parts are trimmed before joining, so JoinNonEmpty(", ", "a", " ", "b ") is "a, b".
+func JoinNonEmpty(sep string, parts ...string) string {
+	kept := make([]string, 0, len(parts))
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, sep)
+}
+
+// SplitAndTrim splits s on sep (default ",") and trims whitespace from every part
+// A blank s returns an empty slice; empty parts between separators are kept.
+func SplitAndTrim(s, sep string) []string {
+	if sep == "" {
+		sep = ","
+	}
+	if strings.TrimSpace(s) == "" {
+		return []string{}
+	}
+
+	parts := strings.Split(s, sep)
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return parts
+}
+
+// SplitAndTrimNonEmpty is like SplitAndTrim but drops parts that are empty after trimming
+// Suited to list-valued headers such as "gzip, , br".
+func SplitAndTrimNonEmpty(s, sep string) []string {
+	parts := SplitAndTrim(s, sep)
+	kept := parts[:0]
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// SplitExactly splits s on sep into exactly n trimmed parts
+// Any other count returns a *SplitError, so "a:b:c" with n=2 is rejected rather than truncated.
+func SplitExactly(s, sep string, n int) ([]string, error) {
+	parts := SplitAndTrim(s, sep)
+	if len(parts) != n {
+		return nil, &SplitError{Input: s, Sep: sep, Want: n, Got: len(parts)}
+	}
+	return parts, nil
+}
+
+// SplitAtMost splits s on sep into at most maxParts trimmed parts, leaving the remainder in the last part
+// It returns a *SplitError when fewer than minParts parts are found. A maxParts <= 0 means no limit.
+func SplitAtMost(s, sep string, minParts, maxParts int) ([]string, error) {
+	if sep == "" {
+		sep = ","
+	}
+	if maxParts <= 0 {
+		maxParts = -1
+	}
+	var parts []string
+	if strings.TrimSpace(s) != "" {
+		parts = strings.SplitN(s, sep, maxParts)
+		for i, part := range parts {
+			parts[i] = strings.TrimSpace(part)
+		}
+	}
+	if len(parts) < minParts {
+		return nil, &SplitError{Input: s, Sep: sep, Want: minParts, Got: len(parts)}
+	}
+	return parts, nil
+}
+
+// SplitPair splits s at the first sep into a trimmed key and value, e.g. "timeout = 30s"
+// A missing separator or empty key returns a *SplitError.
+func SplitPair(s, sep string) (string, string, error) {
+	key, value, found := strings.Cut(s, sep)
+	key = strings.TrimSpace(key)
+	if !found || key == "" {
+		got := 1
+		if strings.TrimSpace(s) == "" {
+			got = 0
+		}
+		return "", "", &SplitError{Input: s, Sep: sep, Want: 2, Got: got}
+	}
+	return key, strings.TrimSpace(value), nil
+}
@@ -0,0 +1,238 @@
+package compressutil
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// ZipDir writes a zip archive of srcDir's contents to w
+// Entry names are relative to srcDir; symlinks and other non-regular files are skipped.
+func (c *CompressUtil) ZipDir(srcDir string, w io.Writer) error {
+	writer := zip.NewWriter(w)
+	writer.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+		return flate.NewWriter(out, c.config.Level)
+	})
+
+	err := walkDir(srcDir, func(name string, info fs.FileInfo, file string) error {
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+			_, err = writer.CreateHeader(header)
+			return err
+		}
+		header.Method = zip.Deflate
+		entry, err := writer.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		return copyFile(entry, file)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to zip %s: %w", srcDir, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to zip %s: %w", srcDir, err)
+	}
+	return nil
+}
+
+// UnzipTo extracts the zip archive at zipPath into destDir
+// Entries escaping destDir fail with ErrUnsafePath, symlinks with ErrUnsupportedEntry, and the
+// total extracted size is bounded by MaxDecompressedSize.
+func (c *CompressUtil) UnzipTo(zipPath, destDir string) error {
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to open zip %s: %w", zipPath, err)
+	}
+	defer reader.Close()
+
+	budget := c.config.MaxDecompressedSize
+	for _, entry := range reader.File {
+		if err := c.extractZipEntry(entry, destDir, &budget); err != nil {
+			return fmt.Errorf("failed to unzip %s: %w", entry.Name, err)
+		}
+	}
+	return nil
+}
+
+// extractZipEntry writes a single zip entry under destDir, charging its size to budget
+func (c *CompressUtil) extractZipEntry(entry *zip.File, destDir string, budget *int64) error {
+	target, err := c.safeJoin(destDir, entry.Name)
+	if err != nil {
+		return err
+	}
+	mode := entry.Mode()
+	switch {
+	case mode.IsDir():
+		return os.MkdirAll(target, 0o755)
+	case !mode.IsRegular():
+		return ErrUnsupportedEntry
+	}
+
+	src, err := entry.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	return writeFile(target, mode, src, budget)
+}
+
+// TarGzDir writes a gzip-compressed tar archive of srcDir's contents to w
+// Entry names are relative to srcDir; symlinks and other non-regular files are skipped.
+func (c *CompressUtil) TarGzDir(srcDir string, w io.Writer) error {
+	gz, err := c.NewGzipWriter(w)
+	if err != nil {
+		return err
+	}
+	writer := tar.NewWriter(gz)
+
+	err = walkDir(srcDir, func(name string, info fs.FileInfo, file string) error {
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = name
+		if info.IsDir() {
+			header.Name += "/"
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		return copyFile(writer, file)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to tar %s: %w", srcDir, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to tar %s: %w", srcDir, err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to tar %s: %w", srcDir, err)
+	}
+	return nil
+}
+
+// UntarGzTo extracts a gzip-compressed tar stream into destDir
+// Entries escaping destDir fail with ErrUnsafePath, links and devices with ErrUnsupportedEntry, and the
+// total extracted size is bounded by MaxDecompressedSize.
+func (c *CompressUtil) UntarGzTo(r io.Reader, destDir string) error {
+	gz, err := c.NewGzipReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	budget := c.config.MaxDecompressedSize
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar: %w", err)
+		}
+
+		target, err := c.safeJoin(destDir, header.Name)
+		if err != nil {
+			return fmt.Errorf("failed to untar %s: %w", header.Name, err)
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			err = os.MkdirAll(target, 0o755)
+		case tar.TypeReg:
+			err = writeFile(target, header.FileInfo().Mode(), reader, &budget)
+		case tar.TypeXGlobalHeader:
+			continue
+		default:
+			err = ErrUnsupportedEntry
+		}
+		if err != nil {
+			return fmt.Errorf("failed to untar %s: %w", header.Name, err)
+		}
+	}
+}
+
+// walkDir calls fn for every directory and regular file below root with its slash-separated relative name
+func walkDir(root string, fn func(name string, info fs.FileInfo, file string) error) error {
+	return filepath.WalkDir(root, func(file string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if file == root {
+			return nil
+		}
+		if !d.IsDir() && !d.Type().IsRegular() {
+			return nil // symlinks, sockets, devices
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, file)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel), info, file)
+	})
+}
+
+// safeJoin resolves an archive entry name under destDir, rejecting empty names, absolute paths and ".." escapes
+func (c *CompressUtil) safeJoin(destDir, name string) (string, error) {
+	if name == "" {
+		return "", ErrUnsafePath
+	}
+	target, err := c.file.CleanJoin(destDir, name)
+	if err != nil {
+		return "", ErrUnsafePath
+	}
+	return target, nil
+}
+
+// copyFile copies the file at path into w
+func copyFile(w io.Writer, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// writeFile creates target with mode's permission bits and copies src into it, charging budget
+func writeFile(target string, mode fs.FileMode, src io.Reader, budget *int64) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	perm := mode.Perm()
+	if perm == 0 {
+		perm = 0o644
+	}
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+
+	limited := &limitedReader{r: src, remaining: *budget}
+	written, copyErr := io.Copy(out, limited)
+	*budget -= written
+	if closeErr := out.Close(); copyErr == nil {
+		copyErr = closeErr
+	}
+	return copyErr
+}
@@ -0,0 +1,157 @@
+package compressutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+
+	"github.com/mustanish/common-utils/v2/fileutil"
+)
+
+// CompressConfig holds configuration for compression and archive extraction
+type CompressConfig struct {
+	// Level is the gzip/deflate level (gzip.DefaultCompression when 0)
+	Level int
+
+	// MaxDecompressedSize bounds the bytes produced when decompressing or extracting,
+	// protecting against decompression bombs
+	MaxDecompressedSize int64
+}
+
+// DefaultCompressConfig returns default configuration
+func DefaultCompressConfig() *CompressConfig {
+	return &CompressConfig{
+		Level:               gzip.DefaultCompression,
+		MaxDecompressedSize: 1 << 30, // 1 GiB
+	}
+}
+
+// CompressClient defines the interface for gzip, zip and tar.gz helpers
+type CompressClient interface {
+	// Gzip
+	GzipBytes(data []byte) ([]byte, error)
+	GunzipBytes(data []byte) ([]byte, error)
+	NewGzipWriter(w io.Writer) (io.WriteCloser, error)
+	NewGzipReader(r io.Reader) (io.ReadCloser, error)
+
+	// Zip archives
+	ZipDir(srcDir string, w io.Writer) error
+	UnzipTo(zipPath, destDir string) error
+
+	// Tar.gz archives
+	TarGzDir(srcDir string, w io.Writer) error
+	UntarGzTo(r io.Reader, destDir string) error
+}
+
+// CompressUtil implements CompressClient
+type CompressUtil struct {
+	config CompressConfig
+	file   fileutil.FileClient
+}
+
+// NewCompressUtil creates a new compression utility instance
+// Pass nil for config to use all defaults, or pass config with only the properties you want to override
+func NewCompressUtil(config *CompressConfig) CompressClient {
+	defaults := DefaultCompressConfig()
+
+	if config != nil {
+		if config.Level != 0 {
+			defaults.Level = config.Level
+		}
+		if config.MaxDecompressedSize > 0 {
+			defaults.MaxDecompressedSize = config.MaxDecompressedSize
+		}
+	}
+
+	return &CompressUtil{config: *defaults, file: fileutil.NewFileUtil()}
+}
+
+// GzipBytes compresses data with gzip
+func (c *CompressUtil) GzipBytes(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := c.NewGzipWriter(&buf)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to gzip data: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to gzip data: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// GunzipBytes decompresses gzip data, failing with ErrSizeLimitExceeded beyond MaxDecompressedSize
+func (c *CompressUtil) GunzipBytes(data []byte) ([]byte, error) {
+	reader, err := c.NewGzipReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	out, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to gunzip data: %w", err)
+	}
+	return out, nil
+}
+
+// NewGzipWriter returns a streaming gzip writer at the configured level; Close flushes it
+func (c *CompressUtil) NewGzipWriter(w io.Writer) (io.WriteCloser, error) {
+	writer, err := gzip.NewWriterLevel(w, c.config.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gzip level %d: %w", c.config.Level, err)
+	}
+	return writer, nil
+}
+
+// NewGzipReader returns a streaming gzip reader that fails with ErrSizeLimitExceeded beyond MaxDecompressedSize
+func (c *CompressUtil) NewGzipReader(r io.Reader) (io.ReadCloser, error) {
+	reader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gzip header: %w", err)
+	}
+	return &limitedReadCloser{
+		limitedReader: limitedReader{r: reader, remaining: c.config.MaxDecompressedSize},
+		closer:        reader,
+	}, nil
+}
+
+// limitedReader reads up to remaining bytes and then fails instead of silently truncating
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+// Read implements io.Reader
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.remaining <= 0 {
+		// Probe for more data so input that ends exactly at the limit is still accepted
+		var probe [1]byte
+		if n, err := l.r.Read(probe[:]); n > 0 {
+			return 0, ErrSizeLimitExceeded
+		} else if err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+	if int64(len(p)) > l.remaining {
+		p = p[:l.remaining]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	return n, err
+}
+
+// limitedReadCloser pairs a limitedReader with the underlying closer
+type limitedReadCloser struct {
+	limitedReader
+	closer io.Closer
+}
+
+// Close implements io.Closer
+func (l *limitedReadCloser) Close() error {
+	return l.closer.Close()
+}
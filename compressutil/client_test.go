@@ -0,0 +1,289 @@
+package compressutil
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTree creates files (name -> content) below a new temp directory
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		file := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o640); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+// assertTree checks that files below root have the expected contents
+func assertTree(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			t.Errorf("reading %s: %v", name, err)
+			continue
+		}
+		if string(data) != content {
+			t.Errorf("%s = %q, want %q", name, data, content)
+		}
+	}
+}
+
+var sampleTree = map[string]string{
+	"a.txt":            "alpha",
+	"nested/b.txt":     strings.Repeat("bravo ", 100),
+	"nested/deep/c.md": "charlie",
+}
+
+func TestNewCompressUtil(t *testing.T) {
+	util := NewCompressUtil(nil).(*CompressUtil)
+	if util.config.Level != gzip.DefaultCompression || util.config.MaxDecompressedSize != 1<<30 {
+		t.Errorf("default config = %+v", util.config)
+	}
+
+	custom := NewCompressUtil(&CompressConfig{Level: gzip.BestSpeed}).(*CompressUtil)
+	if custom.config.Level != gzip.BestSpeed || custom.config.MaxDecompressedSize != 1<<30 {
+		t.Errorf("custom config = %+v", custom.config)
+	}
+}
+
+// =================== Test Gzip ===================
+
+func TestGzipRoundTrip(t *testing.T) {
+	util := NewCompressUtil(nil)
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"text", []byte("hello world")},
+		{"repetitive", bytes.Repeat([]byte("abc"), 10000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			compressed, err := util.GzipBytes(tt.data)
+			if err != nil {
+				t.Fatalf("GzipBytes() error = %v", err)
+			}
+			result, err := util.GunzipBytes(compressed)
+			if err != nil {
+				t.Fatalf("GunzipBytes() error = %v", err)
+			}
+			if !bytes.Equal(result, tt.data) {
+				t.Errorf("round trip = %d bytes, want %d", len(result), len(tt.data))
+			}
+		})
+	}
+
+	if _, err := util.GunzipBytes([]byte("not gzip")); err == nil {
+		t.Error("GunzipBytes() with invalid data expected error")
+	}
+	if _, err := NewCompressUtil(&CompressConfig{Level: 42}).GzipBytes([]byte("x")); err == nil {
+		t.Error("GzipBytes() with invalid level expected error")
+	}
+}
+
+func TestGunzipSizeLimit(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 1000)
+	compressed, _ := NewCompressUtil(nil).GzipBytes(data)
+
+	if _, err := NewCompressUtil(&CompressConfig{MaxDecompressedSize: 999}).GunzipBytes(compressed); !errors.Is(err, ErrSizeLimitExceeded) {
+		t.Errorf("GunzipBytes() over limit error = %v, want ErrSizeLimitExceeded", err)
+	}
+	if result, err := NewCompressUtil(&CompressConfig{MaxDecompressedSize: 1000}).GunzipBytes(compressed); err != nil || len(result) != 1000 {
+		t.Errorf("GunzipBytes() at limit = %d bytes, %v; want 1000, nil", len(result), err)
+	}
+}
+
+func TestGzipStreaming(t *testing.T) {
+	util := NewCompressUtil(nil)
+	var buf bytes.Buffer
+
+	writer, err := util.NewGzipWriter(&buf)
+	if err != nil {
+		t.Fatalf("NewGzipWriter() error = %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		_, _ = io.WriteString(writer, "chunk;")
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	reader, err := util.NewGzipReader(&buf)
+	if err != nil {
+		t.Fatalf("NewGzipReader() error = %v", err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil || string(data) != "chunk;chunk;chunk;" {
+		t.Errorf("streamed data = %q, %v", data, err)
+	}
+}
+
+// =================== Test Archives ===================
+
+func TestZipRoundTrip(t *testing.T) {
+	util := NewCompressUtil(nil)
+	src := writeTree(t, sampleTree)
+	zipPath := filepath.Join(t.TempDir(), "out.zip")
+
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := util.ZipDir(src, out); err != nil {
+		t.Fatalf("ZipDir() error = %v", err)
+	}
+	out.Close()
+
+	dest := t.TempDir()
+	if err := util.UnzipTo(zipPath, dest); err != nil {
+		t.Fatalf("UnzipTo() error = %v", err)
+	}
+	assertTree(t, dest, sampleTree)
+
+	info, err := os.Stat(filepath.Join(dest, "a.txt"))
+	if err == nil && info.Mode().Perm() != 0o640 {
+		t.Errorf("extracted mode = %v, want 0640", info.Mode().Perm())
+	}
+}
+
+func TestTarGzRoundTrip(t *testing.T) {
+	util := NewCompressUtil(nil)
+	src := writeTree(t, sampleTree)
+
+	var buf bytes.Buffer
+	if err := util.TarGzDir(src, &buf); err != nil {
+		t.Fatalf("TarGzDir() error = %v", err)
+	}
+
+	dest := t.TempDir()
+	if err := util.UntarGzTo(&buf, dest); err != nil {
+		t.Fatalf("UntarGzTo() error = %v", err)
+	}
+	assertTree(t, dest, sampleTree)
+}
+
+func TestUnzipRejectsUnsafeEntries(t *testing.T) {
+	util := NewCompressUtil(nil)
+
+	for _, name := range []string{"../evil.txt", "a/../../evil.txt", "/abs/evil.txt", `..\evil.txt`} {
+		t.Run(name, func(t *testing.T) {
+			zipPath := filepath.Join(t.TempDir(), "evil.zip")
+			out, _ := os.Create(zipPath)
+			writer := zip.NewWriter(out)
+			entry, _ := writer.Create(name)
+			_, _ = entry.Write([]byte("pwned"))
+			writer.Close()
+			out.Close()
+
+			dest := filepath.Join(t.TempDir(), "dest")
+			if err := util.UnzipTo(zipPath, dest); !errors.Is(err, ErrUnsafePath) {
+				t.Errorf("UnzipTo() error = %v, want ErrUnsafePath", err)
+			}
+			if _, err := os.Stat(filepath.Join(filepath.Dir(dest), "evil.txt")); err == nil {
+				t.Error("UnzipTo() wrote outside the destination")
+			}
+		})
+	}
+}
+
+// tarGz builds a tar.gz stream from the given headers, using body for regular files
+func tarGz(t *testing.T, headers []*tar.Header, body string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	writer := tar.NewWriter(gz)
+	for _, header := range headers {
+		if header.Typeflag == tar.TypeReg {
+			header.Size = int64(len(body))
+		}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag == tar.TypeReg {
+			_, _ = writer.Write([]byte(body))
+		}
+	}
+	writer.Close()
+	gz.Close()
+	return &buf
+}
+
+func TestUntarGzRejectsUnsafeEntries(t *testing.T) {
+	util := NewCompressUtil(nil)
+
+	tests := []struct {
+		name     string
+		header   *tar.Header
+		expected error
+	}{
+		{"parent traversal", &tar.Header{Name: "../evil.txt", Typeflag: tar.TypeReg, Mode: 0o644}, ErrUnsafePath},
+		{"absolute path", &tar.Header{Name: "/tmp/evil.txt", Typeflag: tar.TypeReg, Mode: 0o644}, ErrUnsafePath},
+		{"symlink", &tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}, ErrUnsupportedEntry},
+		{"hardlink", &tar.Header{Name: "hard", Typeflag: tar.TypeLink, Linkname: "../x"}, ErrUnsupportedEntry},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := tarGz(t, []*tar.Header{tt.header}, "pwned")
+			if err := util.UntarGzTo(stream, t.TempDir()); !errors.Is(err, tt.expected) {
+				t.Errorf("UntarGzTo() error = %v, want %v", err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestArchiveSizeLimit(t *testing.T) {
+	util := NewCompressUtil(&CompressConfig{MaxDecompressedSize: 1500})
+	body := strings.Repeat("x", 1000)
+
+	stream := tarGz(t, []*tar.Header{
+		{Name: "one.txt", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "two.txt", Typeflag: tar.TypeReg, Mode: 0o644},
+	}, body)
+	if err := util.UntarGzTo(stream, t.TempDir()); !errors.Is(err, ErrSizeLimitExceeded) {
+		t.Errorf("UntarGzTo() error = %v, want ErrSizeLimitExceeded", err)
+	}
+
+	src := writeTree(t, map[string]string{"one.txt": body, "two.txt": body})
+	zipPath := filepath.Join(t.TempDir(), "big.zip")
+	out, _ := os.Create(zipPath)
+	if err := NewCompressUtil(nil).ZipDir(src, out); err != nil {
+		t.Fatal(err)
+	}
+	out.Close()
+	if err := util.UnzipTo(zipPath, t.TempDir()); !errors.Is(err, ErrSizeLimitExceeded) {
+		t.Errorf("UnzipTo() error = %v, want ErrSizeLimitExceeded", err)
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkGzipBytes(b *testing.B) {
+	util := NewCompressUtil(nil)
+	data := bytes.Repeat([]byte("benchmark data "), 1000)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = util.GzipBytes(data)
+	}
+}
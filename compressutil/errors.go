@@ -0,0 +1,14 @@
+package compressutil
+
+import "errors"
+
+var (
+	// ErrUnsafePath is returned for archive entries that would be written outside the destination
+	ErrUnsafePath = errors.New("archive entry path escapes destination")
+
+	// ErrSizeLimitExceeded is returned when decompressed data exceeds MaxDecompressedSize
+	ErrSizeLimitExceeded = errors.New("decompressed size limit exceeded")
+
+	// ErrUnsupportedEntry is returned for archive entries other than regular files and directories
+	ErrUnsupportedEntry = errors.New("unsupported archive entry type")
+)
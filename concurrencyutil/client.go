@@ -0,0 +1,280 @@
+package concurrencyutil
+
+import (
+	"context"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// PoolConfig holds configuration for a worker pool
+type PoolConfig struct {
+	Workers     int
+	QueueSize   int
+	TaskTimeout time.Duration
+
+	// MaxErrors caps how many task errors Errors keeps; older ones are dropped and counted by DroppedErrors
+	MaxErrors int
+}
+
+// DefaultPoolConfig returns default configuration
+func DefaultPoolConfig() *PoolConfig {
+	return &PoolConfig{
+		Workers:     runtime.NumCPU(),
+		QueueSize:   100,
+		TaskTimeout: 0, // no per-task timeout
+		MaxErrors:   100,
+	}
+}
+
+// Task is a unit of work run by the pool with its own context
+type Task func(ctx context.Context) (any, error)
+
+// PoolClient defines the interface for a bounded worker pool
+type PoolClient interface {
+	Submit(ctx context.Context, task Task) (*Future, error)
+	SubmitWait(ctx context.Context, task Task) (any, error)
+	Shutdown(ctx context.Context) error
+	Errors() []error
+	DroppedErrors() int
+}
+
+// Pool runs tasks on a fixed number of workers fed by a bounded queue
+type Pool struct {
+	tasks       chan *queuedTask
+	taskTimeout time.Duration
+	maxErrors   int
+
+	mu         sync.Mutex
+	closed     bool
+	running    map[*queuedTask]context.CancelFunc
+	errs       []error
+	dropped    int
+	submitting sync.WaitGroup
+	workers    sync.WaitGroup
+
+	shutdownOnce sync.Once
+	drained      chan struct{}
+}
+
+// queuedTask pairs a task with the context it runs under and the future it completes
+type queuedTask struct {
+	ctx    context.Context
+	task   Task
+	future *Future
+}
+
+// NewPool creates a worker pool and starts its workers
+// Pass nil for config to use all defaults, or pass config with only the properties you want to override
+func NewPool(config *PoolConfig) PoolClient {
+	defaults := DefaultPoolConfig()
+
+	if config != nil {
+		if config.Workers > 0 {
+			defaults.Workers = config.Workers
+		}
+		if config.QueueSize > 0 {
+			defaults.QueueSize = config.QueueSize
+		}
+		if config.TaskTimeout > 0 {
+			defaults.TaskTimeout = config.TaskTimeout
+		}
+		if config.MaxErrors > 0 {
+			defaults.MaxErrors = config.MaxErrors
+		}
+	}
+
+	pool := &Pool{
+		tasks:       make(chan *queuedTask, defaults.QueueSize),
+		taskTimeout: defaults.TaskTimeout,
+		maxErrors:   defaults.MaxErrors,
+		running:     make(map[*queuedTask]context.CancelFunc),
+		drained:     make(chan struct{}),
+	}
+
+	pool.workers.Add(defaults.Workers)
+	for i := 0; i < defaults.Workers; i++ {
+		go pool.worker()
+	}
+
+	return pool
+}
+
+// Submit queues a task, blocking while the queue is full until ctx is done
+// The task runs with a context derived from ctx, so cancelling ctx also cancels the task.
+func (p *Pool) Submit(ctx context.Context, task Task) (*Future, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, ErrPoolClosed
+	}
+	p.submitting.Add(1)
+	p.mu.Unlock()
+	defer p.submitting.Done()
+
+	queued := &queuedTask{ctx: ctx, task: task, future: newFuture()}
+	select {
+	case p.tasks <- queued:
+		return queued.future, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SubmitWait queues a task and waits for its result
+func (p *Pool) SubmitWait(ctx context.Context, task Task) (any, error) {
+	future, err := p.Submit(ctx, task)
+	if err != nil {
+		return nil, err
+	}
+	return future.Wait(ctx)
+}
+
+// Shutdown stops accepting tasks and waits for queued and running tasks to finish
+// If ctx is done first, running tasks are cancelled and ctx.Err() is returned.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.shutdownOnce.Do(func() {
+		p.mu.Lock()
+		p.closed = true
+		p.mu.Unlock()
+
+		go func() {
+			p.submitting.Wait()
+			close(p.tasks)
+			p.workers.Wait()
+			close(p.drained)
+		}()
+	})
+
+	select {
+	case <-p.drained:
+		return nil
+	case <-ctx.Done():
+		p.cancelRunning()
+		return ctx.Err()
+	}
+}
+
+// Errors returns the errors of the most recent failed tasks, including recovered panics, oldest first
+// At most MaxErrors are kept, so a long-lived pool does not grow without bound; reading does not clear them.
+func (p *Pool) Errors() []error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]error(nil), p.errs...)
+}
+
+// DroppedErrors returns how many task errors were discarded to stay within MaxErrors
+func (p *Pool) DroppedErrors() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.dropped
+}
+
+// worker runs queued tasks until the queue is closed and drained
+func (p *Pool) worker() {
+	defer p.workers.Done()
+	for queued := range p.tasks {
+		p.run(queued)
+	}
+}
+
+// run executes a single task with panic recovery and records its outcome
+func (p *Pool) run(queued *queuedTask) {
+	var ctx context.Context
+	var cancel context.CancelFunc
+	if p.taskTimeout > 0 {
+		ctx, cancel = context.WithTimeout(queued.ctx, p.taskTimeout)
+	} else {
+		ctx, cancel = context.WithCancel(queued.ctx)
+	}
+	defer cancel()
+
+	p.mu.Lock()
+	p.running[queued] = cancel
+	p.mu.Unlock()
+
+	value, err := p.execute(ctx, queued.task)
+
+	p.mu.Lock()
+	delete(p.running, queued)
+	if err != nil {
+		p.recordError(err)
+	}
+	p.mu.Unlock()
+
+	queued.future.complete(value, err)
+}
+
+// recordError keeps err among the last maxErrors errors; p.mu must be held
+func (p *Pool) recordError(err error) {
+	if len(p.errs) < p.maxErrors {
+		p.errs = append(p.errs, err)
+		return
+	}
+	copy(p.errs, p.errs[1:])
+	p.errs[len(p.errs)-1] = err
+	p.dropped++
+}
+
+// execute calls the task, converting a panic into a *PanicError
+func (p *Pool) execute(ctx context.Context, task Task) (value any, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err // cancelled while queued
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			value, err = nil, &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return task(ctx)
+}
+
+// cancelRunning cancels the contexts of all running tasks
+func (p *Pool) cancelRunning() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, cancel := range p.running {
+		cancel()
+	}
+}
+
+// Future holds the eventual result of a submitted task
+type Future struct {
+	done  chan struct{}
+	value any
+	err   error
+}
+
+// newFuture creates an incomplete future
+func newFuture() *Future {
+	return &Future{done: make(chan struct{})}
+}
+
+// complete records the task outcome and releases waiters
+func (f *Future) complete(value any, err error) {
+	f.value, f.err = value, err
+	close(f.done)
+}
+
+// Done returns a channel closed when the task has finished
+func (f *Future) Done() <-chan struct{} {
+	return f.done
+}
+
+// Wait blocks until the task finishes or ctx is done
+func (f *Future) Wait(ctx context.Context) (any, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	select {
+	case <-f.done:
+		return f.value, f.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,697 @@
+package concurrencyutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDefaultPoolConfig(t *testing.T) {
+	config := DefaultPoolConfig()
+	if config.Workers <= 0 || config.QueueSize != 100 || config.TaskTimeout != 0 || config.MaxErrors != 100 {
+		t.Errorf("Unexpected defaults: %+v", config)
+	}
+}
+
+func TestNewPool(t *testing.T) {
+	pool := NewPool(nil)
+	if pool == nil {
+		t.Fatal("NewPool() returned nil")
+	}
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown() unexpected error: %v", err)
+	}
+}
+
+func TestPool_SubmitWait(t *testing.T) {
+	pool := NewPool(&PoolConfig{Workers: 2})
+	defer func() { _ = pool.Shutdown(context.Background()) }()
+
+	value, err := pool.SubmitWait(context.Background(), func(ctx context.Context) (any, error) {
+		return 42, nil
+	})
+	if err != nil || value != 42 {
+		t.Errorf("SubmitWait() = %v, %v; want 42, nil", value, err)
+	}
+
+	taskErr := errors.New("task failed")
+	_, err = pool.SubmitWait(context.Background(), func(ctx context.Context) (any, error) {
+		return nil, taskErr
+	})
+	if !errors.Is(err, taskErr) {
+		t.Errorf("Expected task error, got %v", err)
+	}
+}
+
+func TestPool_BoundedConcurrency(t *testing.T) {
+	pool := NewPool(&PoolConfig{Workers: 3, QueueSize: 10})
+
+	var active, peak int32
+	for i := 0; i < 10; i++ {
+		_, err := pool.Submit(context.Background(), func(ctx context.Context) (any, error) {
+			current := atomic.AddInt32(&active, 1)
+			for {
+				old := atomic.LoadInt32(&peak)
+				if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("Submit() unexpected error: %v", err)
+		}
+	}
+
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() unexpected error: %v", err)
+	}
+	if peak > 3 {
+		t.Errorf("Expected at most 3 concurrent tasks, got %d", peak)
+	}
+}
+
+func TestPool_PanicRecovery(t *testing.T) {
+	pool := NewPool(&PoolConfig{Workers: 1})
+	defer func() { _ = pool.Shutdown(context.Background()) }()
+
+	_, err := pool.SubmitWait(context.Background(), func(ctx context.Context) (any, error) {
+		panic("boom")
+	})
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) || panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+		t.Errorf("Expected PanicError, got %v", err)
+	}
+
+	// The worker must survive the panic
+	value, err := pool.SubmitWait(context.Background(), func(ctx context.Context) (any, error) {
+		return "ok", nil
+	})
+	if err != nil || value != "ok" {
+		t.Errorf("Expected pool to keep working after panic, got %v, %v", value, err)
+	}
+}
+
+func TestPool_ErrorsCollected(t *testing.T) {
+	pool := NewPool(&PoolConfig{Workers: 2})
+	for i := 0; i < 3; i++ {
+		fail := i != 1
+		_, _ = pool.Submit(context.Background(), func(ctx context.Context) (any, error) {
+			if fail {
+				return nil, errors.New("fail")
+			}
+			return nil, nil
+		})
+	}
+	_ = pool.Shutdown(context.Background())
+
+	if errs := pool.Errors(); len(errs) != 2 {
+		t.Errorf("Expected 2 collected errors, got %v", errs)
+	}
+}
+
+func TestPool_ErrorsCapped(t *testing.T) {
+	pool := NewPool(&PoolConfig{Workers: 1, MaxErrors: 2})
+	for i := 0; i < 5; i++ {
+		err := fmt.Errorf("fail %d", i)
+		_, _ = pool.Submit(context.Background(), func(ctx context.Context) (any, error) {
+			return nil, err
+		})
+	}
+	_ = pool.Shutdown(context.Background())
+
+	errs := pool.Errors()
+	if len(errs) != 2 || errs[0].Error() != "fail 3" || errs[1].Error() != "fail 4" {
+		t.Errorf("Errors() = %v, want the last 2 errors", errs)
+	}
+	if dropped := pool.DroppedErrors(); dropped != 3 {
+		t.Errorf("DroppedErrors() = %d, want 3", dropped)
+	}
+}
+
+func TestPool_TaskTimeout(t *testing.T) {
+	pool := NewPool(&PoolConfig{Workers: 1, TaskTimeout: 10 * time.Millisecond})
+	defer func() { _ = pool.Shutdown(context.Background()) }()
+
+	_, err := pool.SubmitWait(context.Background(), func(ctx context.Context) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected per-task deadline, got %v", err)
+	}
+}
+
+func TestPool_SubmitAfterShutdown(t *testing.T) {
+	pool := NewPool(nil)
+	_ = pool.Shutdown(context.Background())
+
+	if _, err := pool.Submit(context.Background(), func(ctx context.Context) (any, error) { return nil, nil }); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Expected ErrPoolClosed, got %v", err)
+	}
+	if err := pool.Shutdown(context.Background()); err != nil {
+		t.Errorf("Second Shutdown() unexpected error: %v", err)
+	}
+}
+
+func TestPool_SubmitBlocksWhenQueueFull(t *testing.T) {
+	pool := NewPool(&PoolConfig{Workers: 1, QueueSize: 1})
+	release := make(chan struct{})
+	defer func() {
+		close(release)
+		_ = pool.Shutdown(context.Background())
+	}()
+
+	blocker := func(ctx context.Context) (any, error) {
+		<-release
+		return nil, nil
+	}
+	_, _ = pool.Submit(context.Background(), blocker) // picked up by the worker
+	time.Sleep(10 * time.Millisecond)
+	_, _ = pool.Submit(context.Background(), blocker) // fills the queue
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Submit(ctx, blocker); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected Submit to block until ctx deadline, got %v", err)
+	}
+}
+
+func TestPool_ShutdownTimeoutCancelsRunningTasks(t *testing.T) {
+	pool := NewPool(&PoolConfig{Workers: 1})
+	started := make(chan struct{})
+
+	future, err := pool.Submit(context.Background(), func(ctx context.Context) (any, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != nil {
+		t.Fatalf("Submit() unexpected error: %v", err)
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := pool.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected shutdown deadline error, got %v", err)
+	}
+
+	if _, err := future.Wait(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected running task to be cancelled, got %v", err)
+	}
+}
+
+// =================== Test Semaphore ===================
+
+func TestSemaphore_AcquireRelease(t *testing.T) {
+	sem := NewSemaphore(3)
+
+	if err := sem.Acquire(context.Background(), 2); err != nil {
+		t.Fatalf("Acquire() unexpected error: %v", err)
+	}
+	if sem.TryAcquire(2) {
+		t.Error("TryAcquire(2) = true, want false with 1 unit free")
+	}
+	if !sem.TryAcquire(1) {
+		t.Error("TryAcquire(1) = false, want true")
+	}
+
+	sem.Release(3)
+	if !sem.TryAcquire(3) {
+		t.Error("TryAcquire(3) = false after release, want true")
+	}
+}
+
+func TestSemaphore_AcquireBlocksUntilRelease(t *testing.T) {
+	sem := NewSemaphore(1)
+	_ = sem.Acquire(context.Background(), 1)
+
+	acquired := make(chan struct{})
+	go func() {
+		_ = sem.Acquire(context.Background(), 1)
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Acquire() returned while semaphore was full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	sem.Release(1)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("Acquire() did not return after Release()")
+	}
+}
+
+func TestSemaphore_AcquireContextCancelled(t *testing.T) {
+	sem := NewSemaphore(2)
+	_ = sem.Acquire(context.Background(), 2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := sem.Acquire(ctx, 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline error, got %v", err)
+	}
+
+	// The cancelled waiter must not hold any units
+	sem.Release(2)
+	if !sem.TryAcquire(2) {
+		t.Error("Expected full capacity after cancelled Acquire()")
+	}
+}
+
+func TestSemaphore_AcquireMoreThanSize(t *testing.T) {
+	sem := NewSemaphore(1)
+	if err := sem.Acquire(context.Background(), 2); err == nil {
+		t.Error("Expected error acquiring more than semaphore size")
+	}
+}
+
+// =================== Test RunAll / RunLimited ===================
+
+func TestRunAll_Success(t *testing.T) {
+	var count int32
+	fn := func(ctx context.Context) error {
+		atomic.AddInt32(&count, 1)
+		return nil
+	}
+
+	if err := RunAll(context.Background(), fn, fn, fn); err != nil {
+		t.Errorf("RunAll() unexpected error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 functions to run, got %d", count)
+	}
+}
+
+func TestRunAll_CancelOnFirstError(t *testing.T) {
+	errFailed := errors.New("failed")
+	err := RunAll(context.Background(),
+		func(ctx context.Context) error { return errFailed },
+		func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	)
+
+	var multi *MultiError
+	if !errors.As(err, &multi) {
+		t.Fatalf("Expected MultiError, got %v", err)
+	}
+	if len(multi.Errors) != 1 || !errors.Is(err, errFailed) {
+		t.Errorf("Expected only the original failure, got %v", multi.Errors)
+	}
+}
+
+func TestRunGroup_ContinueOnError(t *testing.T) {
+	errA, errB := errors.New("a"), errors.New("b")
+	var ran int32
+	err := RunGroup(context.Background(), GroupOptions{Limit: 1, ContinueOnError: true},
+		func(ctx context.Context) error { atomic.AddInt32(&ran, 1); return errA },
+		func(ctx context.Context) error { atomic.AddInt32(&ran, 1); return nil },
+		func(ctx context.Context) error { atomic.AddInt32(&ran, 1); return errB },
+	)
+
+	if ran != 3 {
+		t.Errorf("Expected all 3 functions to run, got %d", ran)
+	}
+	var multi *MultiError
+	if !errors.As(err, &multi) || len(multi.Errors) != 2 {
+		t.Fatalf("Expected 2 aggregated errors, got %v", err)
+	}
+	if multi.Errors[0] != errA || multi.Errors[1] != errB {
+		t.Errorf("Expected errors in function order, got %v", multi.Errors)
+	}
+}
+
+func TestRunLimited_RespectsLimit(t *testing.T) {
+	var active, peak int32
+	fn := func(ctx context.Context) error {
+		current := atomic.AddInt32(&active, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if current <= old || atomic.CompareAndSwapInt32(&peak, old, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&active, -1)
+		return nil
+	}
+
+	fns := make([]func(ctx context.Context) error, 10)
+	for i := range fns {
+		fns[i] = fn
+	}
+	if err := RunLimited(context.Background(), 2, fns...); err != nil {
+		t.Fatalf("RunLimited() unexpected error: %v", err)
+	}
+	if peak > 2 {
+		t.Errorf("Expected at most 2 concurrent functions, got %d", peak)
+	}
+}
+
+func TestRunLimited_SkipsAfterFailure(t *testing.T) {
+	var ran int32
+	fns := []func(ctx context.Context) error{
+		func(ctx context.Context) error { atomic.AddInt32(&ran, 1); return errors.New("fail") },
+		func(ctx context.Context) error { atomic.AddInt32(&ran, 1); return nil },
+		func(ctx context.Context) error { atomic.AddInt32(&ran, 1); return nil },
+	}
+
+	if err := RunLimited(context.Background(), 1, fns...); err == nil {
+		t.Fatal("Expected error from RunLimited()")
+	}
+	if ran != 1 {
+		t.Errorf("Expected remaining functions to be skipped, %d ran", ran)
+	}
+}
+
+func TestRunAll_PanicRecovered(t *testing.T) {
+	err := RunAll(context.Background(), func(ctx context.Context) error { panic("boom") })
+
+	var panicErr *PanicError
+	if !errors.As(err, &panicErr) {
+		t.Errorf("Expected PanicError in MultiError, got %v", err)
+	}
+}
+
+func TestRunLimited_ParentCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := RunLimited(ctx, 1, func(ctx context.Context) error { return nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestMultiError_Error(t *testing.T) {
+	single := &MultiError{Errors: []error{errors.New("a")}}
+	if single.Error() != "a" {
+		t.Errorf("Error() = %q, want %q", single.Error(), "a")
+	}
+	multi := &MultiError{Errors: []error{errors.New("a"), errors.New("b")}}
+	if want := "2 errors occurred: a; b"; multi.Error() != want {
+		t.Errorf("Error() = %q, want %q", multi.Error(), want)
+	}
+}
+
+// =================== Test Scheduler ===================
+
+func TestScheduler_RunsIntervalJob(t *testing.T) {
+	var completed int32
+	scheduler := NewScheduler(&SchedulerConfig{
+		OnJobComplete: func(name string, duration time.Duration, err error) {
+			if name == "tick" && err == nil {
+				atomic.AddInt32(&completed, 1)
+			}
+		},
+	})
+
+	var runs int32
+	err := scheduler.Register(Job{
+		Name:     "tick",
+		Schedule: Every(5 * time.Millisecond),
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Register() unexpected error: %v", err)
+	}
+
+	scheduler.Start(context.Background())
+	time.Sleep(50 * time.Millisecond)
+	if err := scheduler.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() unexpected error: %v", err)
+	}
+
+	if atomic.LoadInt32(&runs) < 3 {
+		t.Errorf("Expected several runs, got %d", runs)
+	}
+	if atomic.LoadInt32(&completed) != atomic.LoadInt32(&runs) {
+		t.Errorf("Expected OnJobComplete for every run, got %d of %d", completed, runs)
+	}
+
+	after := atomic.LoadInt32(&runs)
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&runs) != after {
+		t.Error("Expected no runs after Stop()")
+	}
+}
+
+func TestScheduler_PreventsOverlap(t *testing.T) {
+	var skipped, active, peak int32
+	scheduler := NewScheduler(&SchedulerConfig{
+		OnJobSkipped: func(name string) { atomic.AddInt32(&skipped, 1) },
+	})
+
+	_ = scheduler.Register(Job{
+		Name:     "slow",
+		Schedule: Every(2 * time.Millisecond),
+		Run: func(ctx context.Context) error {
+			current := atomic.AddInt32(&active, 1)
+			if current > atomic.LoadInt32(&peak) {
+				atomic.StoreInt32(&peak, current)
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&active, -1)
+			return nil
+		},
+	})
+
+	scheduler.Start(context.Background())
+	time.Sleep(50 * time.Millisecond)
+	_ = scheduler.Stop(context.Background())
+
+	if atomic.LoadInt32(&peak) != 1 {
+		t.Errorf("Expected runs not to overlap, peak concurrency %d", peak)
+	}
+	if atomic.LoadInt32(&skipped) == 0 {
+		t.Error("Expected OnJobSkipped to be called for overlapping runs")
+	}
+}
+
+func TestScheduler_JobTimeoutAndPanic(t *testing.T) {
+	results := make(chan error, 10)
+	scheduler := NewScheduler(&SchedulerConfig{
+		OnJobComplete: func(name string, duration time.Duration, err error) { results <- err },
+	})
+
+	_ = scheduler.Register(Job{
+		Name:     "timeout",
+		Schedule: Every(5 * time.Millisecond),
+		Timeout:  5 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	})
+	_ = scheduler.Register(Job{
+		Name:     "panic",
+		Schedule: Every(5 * time.Millisecond),
+		Run:      func(ctx context.Context) error { panic("boom") },
+	})
+
+	scheduler.Start(context.Background())
+	var sawTimeout, sawPanic bool
+	deadline := time.After(time.Second)
+	for !(sawTimeout && sawPanic) {
+		select {
+		case err := <-results:
+			var panicErr *PanicError
+			sawTimeout = sawTimeout || errors.Is(err, context.DeadlineExceeded)
+			sawPanic = sawPanic || errors.As(err, &panicErr)
+		case <-deadline:
+			t.Fatalf("Expected timeout and panic results, got timeout=%v panic=%v", sawTimeout, sawPanic)
+		}
+	}
+	_ = scheduler.Stop(context.Background())
+}
+
+func TestScheduler_StopTimeoutCancelsRunningJobs(t *testing.T) {
+	started := make(chan struct{}, 1)
+	cancelled := make(chan struct{})
+	scheduler := NewScheduler(nil)
+	_ = scheduler.Register(Job{
+		Name:     "blocking",
+		Schedule: Every(time.Millisecond),
+		Run: func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			close(cancelled)
+			return ctx.Err()
+		},
+	})
+
+	scheduler.Start(context.Background())
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := scheduler.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline error from Stop(), got %v", err)
+	}
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected running job to be cancelled")
+	}
+}
+
+func TestScheduler_Register(t *testing.T) {
+	scheduler := NewScheduler(nil)
+
+	if err := scheduler.Register(Job{Name: "invalid"}); err == nil {
+		t.Error("Expected error registering job without schedule and run function")
+	}
+
+	_ = scheduler.Stop(context.Background())
+	err := scheduler.Register(Job{Name: "late", Schedule: Every(time.Second), Run: func(ctx context.Context) error { return nil }})
+	if !errors.Is(err, ErrSchedulerStopped) {
+		t.Errorf("Expected ErrSchedulerStopped, got %v", err)
+	}
+}
+
+func TestCron(t *testing.T) {
+	schedule, err := Cron("0 9 * * *")
+	if err != nil {
+		t.Fatalf("Cron() unexpected error: %v", err)
+	}
+	from := time.Date(2023, 10, 5, 10, 0, 0, 0, time.UTC)
+	if next := schedule.Next(from); !next.Equal(time.Date(2023, 10, 6, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("Next() = %v, want next day at 09:00", next)
+	}
+
+	if _, err := Cron("not a cron"); err == nil {
+		t.Error("Expected error for invalid expression")
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	t.Run("transforms through stages", func(t *testing.T) {
+		p := NewPipeline(context.Background())
+		numbers := From(p, 1, 2, 3, 4, 5, 6)
+		squares := AddStage(p, numbers, Stage[int, int]{Name: "square", Workers: 3, Fn: func(ctx context.Context, n int) (int, error) {
+			return n * n, nil
+		}})
+		evens := AddStage(p, squares, Stage[int, string]{Name: "format", Fn: func(ctx context.Context, n int) (string, error) {
+			if n%2 != 0 {
+				return "", ErrSkipItem
+			}
+			return fmt.Sprintf("#%d", n), nil
+		}})
+
+		got, err := Collect(p, evens)
+		if err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+		sort.Strings(got)
+		if want := []string{"#16", "#36", "#4"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("Collect() = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("first error stops every stage", func(t *testing.T) {
+		p := NewPipeline(context.Background())
+		var produced int32
+		source := Generate(p, 0, func(ctx context.Context, emit func(int) error) error {
+			for i := 0; ; i++ {
+				if err := emit(i); err != nil {
+					return err
+				}
+				atomic.AddInt32(&produced, 1)
+			}
+		})
+		boom := errors.New("boom")
+		failing := AddStage(p, source, Stage[int, int]{Name: "validate", Workers: 2, Fn: func(ctx context.Context, n int) (int, error) {
+			if n == 10 {
+				return 0, boom
+			}
+			return n, nil
+		}})
+		var sunk int32
+		Drain(p, failing, func(ctx context.Context, n int) error {
+			atomic.AddInt32(&sunk, 1)
+			return nil
+		})
+
+		err := p.Wait()
+		if !errors.Is(err, boom) || !strings.Contains(err.Error(), `stage "validate"`) {
+			t.Errorf("Wait() = %v, want boom from the validate stage", err)
+		}
+		if atomic.LoadInt32(&produced) > 100 {
+			t.Errorf("source produced %d items after the failure, want it stopped", produced)
+		}
+	})
+
+	t.Run("panics are reported", func(t *testing.T) {
+		p := NewPipeline(context.Background())
+		out := AddStage(p, From(p, "a"), Stage[string, string]{Fn: func(ctx context.Context, s string) (string, error) {
+			panic("bad item")
+		}})
+		_, err := Collect(p, out)
+		var panicErr *PanicError
+		if !errors.As(err, &panicErr) {
+			t.Errorf("Collect() error = %v, want *PanicError", err)
+		}
+	})
+
+	t.Run("backpressure bounds in-flight items", func(t *testing.T) {
+		p := NewPipeline(context.Background())
+		var produced int32
+		source := Generate(p, 1, func(ctx context.Context, emit func(int) error) error {
+			for i := 0; i < 100; i++ {
+				if err := emit(i); err != nil {
+					return err
+				}
+				atomic.AddInt32(&produced, 1)
+			}
+			return nil
+		})
+		passed := AddStage(p, source, Stage[int, int]{Buffer: 1, Fn: func(ctx context.Context, n int) (int, error) { return n, nil }})
+
+		time.Sleep(20 * time.Millisecond) // nobody reads the output yet
+		if got := atomic.LoadInt32(&produced); got > 5 {
+			t.Errorf("source produced %d items with a blocked consumer, want a handful", got)
+		}
+		items, err := Collect(p, passed)
+		if err != nil || len(items) != 100 {
+			t.Errorf("Collect() = %d items, %v, want 100", len(items), err)
+		}
+	})
+
+	t.Run("parent cancellation", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		p := NewPipeline(ctx)
+		source := Generate(p, 0, func(ctx context.Context, emit func(int) error) error {
+			for {
+				if err := emit(1); err != nil {
+					return err
+				}
+			}
+		})
+		Drain(p, source, func(ctx context.Context, n int) error {
+			cancel()
+			return nil
+		})
+		if err := p.Wait(); !errors.Is(err, context.Canceled) {
+			t.Errorf("Wait() = %v, want context.Canceled", err)
+		}
+	})
+}
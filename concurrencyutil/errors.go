@@ -0,0 +1,68 @@
+package concurrencyutil
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPoolClosed is returned when submitting to a pool that has been shut down
+var ErrPoolClosed = errors.New("pool is closed")
+
+// ErrSkipItem can be returned by a pipeline stage or sink to drop the current item without failing the pipeline
+var ErrSkipItem = errors.New("skip pipeline item")
+
+// PanicError is returned for a task that panicked
+// It contains the recovered value and the stack trace of the panicking goroutine.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+// Error implements the error interface for PanicError
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", e.Value)
+}
+
+// MultiError aggregates the errors returned by functions run concurrently
+// Errors are ordered by the position of the function that returned them.
+type MultiError struct {
+	Errors []error
+}
+
+// Error implements the error interface for MultiError
+func (e *MultiError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d errors occurred: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the aggregated errors
+func (e *MultiError) Unwrap() []error {
+	return e.Errors
+}
+
+// Is reports whether any aggregated error matches target
+func (e *MultiError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first aggregated error that matches target
+func (e *MultiError) As(target any) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
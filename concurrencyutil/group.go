@@ -0,0 +1,120 @@
+package concurrencyutil
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"sync"
+)
+
+// GroupOptions controls how RunGroup runs its functions
+type GroupOptions struct {
+	// Limit caps how many functions run at once (0 means no limit)
+	Limit int
+
+	// ContinueOnError keeps running the remaining functions after one fails
+	// By default the shared context is cancelled on the first error and
+	// functions that have not started yet are skipped.
+	ContinueOnError bool
+}
+
+// RunAll runs every function concurrently, cancelling the rest on the first error
+// It returns nil or a *MultiError holding every error returned.
+func RunAll(ctx context.Context, fns ...func(ctx context.Context) error) error {
+	return RunGroup(ctx, GroupOptions{}, fns...)
+}
+
+// RunLimited runs the functions with at most limit running at once, cancelling the rest on the first error
+// It returns nil or a *MultiError holding every error returned.
+func RunLimited(ctx context.Context, limit int, fns ...func(ctx context.Context) error) error {
+	return RunGroup(ctx, GroupOptions{Limit: limit}, fns...)
+}
+
+// RunGroup runs the functions concurrently according to opts and waits for all of them
+// Panics are recovered into *PanicError. When the group is cancelled because of a failure,
+// the resulting context.Canceled errors of the other functions are not reported.
+func RunGroup(ctx context.Context, opts GroupOptions, fns ...func(ctx context.Context) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	groupCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var sem SemaphoreClient
+	if opts.Limit > 0 {
+		sem = NewSemaphore(int64(opts.Limit))
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		failed  bool
+		skipped bool
+		errs    = make([]error, len(fns))
+	)
+
+	record := func(i int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if failed && errors.Is(err, context.Canceled) && ctx.Err() == nil {
+			return // fallout from our own cancellation
+		}
+		errs[i] = err
+		if !opts.ContinueOnError && !failed {
+			failed = true
+			cancel()
+		}
+	}
+
+	for i, fn := range fns {
+		if sem != nil {
+			if err := sem.Acquire(groupCtx, 1); err != nil {
+				skipped = true // cancelled: skip the functions that have not started
+				break
+			}
+		}
+		if !opts.ContinueOnError && groupCtx.Err() != nil {
+			if sem != nil {
+				sem.Release(1)
+			}
+			skipped = true
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, fn func(ctx context.Context) error) {
+			defer wg.Done()
+			if sem != nil {
+				defer sem.Release(1)
+			}
+			if err := runProtected(groupCtx, fn); err != nil {
+				record(i, err)
+			}
+		}(i, fn)
+	}
+	wg.Wait()
+
+	var collected []error
+	for _, err := range errs {
+		if err != nil {
+			collected = append(collected, err)
+		}
+	}
+	if len(collected) == 0 {
+		if skipped {
+			return ctx.Err() // parent cancelled before every function could start
+		}
+		return nil
+	}
+	return &MultiError{Errors: collected}
+}
+
+// runProtected calls fn, converting a panic into a *PanicError
+func runProtected(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fn(ctx)
+}
@@ -0,0 +1,208 @@
+package concurrencyutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Pipeline connects stages with bounded channels and stops every stage on the first error
+// Build it with From or Generate, chain AddStage calls, and finish with Drain or Collect:
+//
+//	p := NewPipeline(ctx)
+//	ids := From(p, 1, 2, 3)
+//	users := AddStage(p, ids, Stage[int, User]{Name: "fetch", Workers: 4, Fn: fetchUser})
+//	result, err := Collect(p, users)
+//
+// A full channel blocks the stage writing to it, so a slow stage slows its producers instead of
+// buffering without bound.
+type Pipeline struct {
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	once sync.Once
+	err  error
+}
+
+// Stage transforms items of type T into items of type R with a pool of workers
+// With more than one worker the output order is not preserved. Fn may return ErrSkipItem to drop an item;
+// any other error stops the pipeline.
+type Stage[T, R any] struct {
+	Name    string
+	Workers int // defaults to 1
+	Buffer  int // capacity of the output channel (defaults to Workers)
+	Fn      func(ctx context.Context, in T) (R, error)
+}
+
+// NewPipeline creates an empty pipeline bound to ctx
+func NewPipeline(ctx context.Context) *Pipeline {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	p := &Pipeline{parent: ctx}
+	p.ctx, p.cancel = context.WithCancel(ctx)
+	return p
+}
+
+// Context returns the pipeline context, cancelled when a stage fails or the parent context ends
+func (p *Pipeline) Context() context.Context {
+	return p.ctx
+}
+
+// Wait blocks until every stage has finished and returns the first stage error
+// When no stage failed it returns the parent context's error, if the pipeline was cut short by it.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+	if p.err != nil {
+		return p.err
+	}
+	return p.parent.Err()
+}
+
+// fail records the first error and cancels the remaining stages
+func (p *Pipeline) fail(err error) {
+	p.once.Do(func() {
+		p.err = err
+		p.cancel()
+	})
+}
+
+// spawn runs fn on a tracked goroutine, recording its error or panic under the stage name
+func (p *Pipeline) spawn(name string, fn func(ctx context.Context) error) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		if err := runProtected(p.ctx, fn); err != nil {
+			if errors.Is(err, context.Canceled) && p.ctx.Err() != nil {
+				return // fallout from cancellation, reported by Wait
+			}
+			if name != "" {
+				err = fmt.Errorf("stage %q: %w", name, err)
+			}
+			p.fail(err)
+		}
+	}()
+}
+
+// From returns a channel that yields items in order and is closed after the last one
+func From[T any](p *Pipeline, items ...T) <-chan T {
+	return Generate(p, 0, func(ctx context.Context, emit func(T) error) error {
+		for _, item := range items {
+			if err := emit(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Generate runs fn as the pipeline source, with buffer as the capacity of its output channel
+// emit blocks while the channel is full and returns the context error once the pipeline stops; fn should
+// return it. The channel is closed when fn returns.
+func Generate[T any](p *Pipeline, buffer int, fn func(ctx context.Context, emit func(T) error) error) <-chan T {
+	out := make(chan T, maxInt(buffer, 0))
+	p.spawn("source", func(ctx context.Context) error {
+		defer close(out)
+		return fn(ctx, func(item T) error {
+			select {
+			case out <- item:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	})
+	return out
+}
+
+// AddStage starts stage.Workers workers reading from in and returns their output channel
+// The output channel is closed once in is drained (or the pipeline stops) and every worker has returned.
+func AddStage[T, R any](p *Pipeline, in <-chan T, stage Stage[T, R]) <-chan R {
+	workers := maxInt(stage.Workers, 1)
+	buffer := stage.Buffer
+	if buffer <= 0 {
+		buffer = workers
+	}
+	out := make(chan R, buffer)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		p.spawn(stage.Name, func(ctx context.Context) error {
+			defer wg.Done()
+			for {
+				item, ok := receive(ctx, in)
+				if !ok {
+					return nil
+				}
+				result, err := stage.Fn(ctx, item)
+				if errors.Is(err, ErrSkipItem) {
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				select {
+				case out <- result:
+				case <-ctx.Done():
+					return nil
+				}
+			}
+		})
+	}
+	p.spawn("", func(ctx context.Context) error {
+		wg.Wait()
+		close(out)
+		return nil
+	})
+	return out
+}
+
+// Drain consumes in with fn on a single worker, e.g. to write results to a database
+// Call Wait to block until it has finished.
+func Drain[T any](p *Pipeline, in <-chan T, fn func(ctx context.Context, item T) error) {
+	p.spawn("sink", func(ctx context.Context) error {
+		for {
+			item, ok := receive(ctx, in)
+			if !ok {
+				return nil
+			}
+			if err := fn(ctx, item); err != nil && !errors.Is(err, ErrSkipItem) {
+				return err
+			}
+		}
+	})
+}
+
+// Collect reads every item from in and then waits for the pipeline
+// The items gathered so far are returned together with the error when the pipeline fails.
+func Collect[T any](p *Pipeline, in <-chan T) ([]T, error) {
+	var items []T
+	for item := range in {
+		items = append(items, item)
+	}
+	return items, p.Wait()
+}
+
+// receive takes the next item from in, reporting false when in is closed or ctx is done
+func receive[T any](ctx context.Context, in <-chan T) (T, bool) {
+	select {
+	case item, ok := <-in:
+		return item, ok
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
+
+// maxInt returns the larger of a and b
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,257 @@
+package concurrencyutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/dateutil"
+)
+
+// ErrSchedulerStopped is returned when registering a job on a stopped scheduler
+var ErrSchedulerStopped = errors.New("scheduler is stopped")
+
+// Schedule computes when a job should next run
+// *dateutil.CronSchedule satisfies it, as does the value returned by Every.
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// intervalSchedule runs a job at a fixed interval
+type intervalSchedule time.Duration
+
+// Next implements Schedule
+func (s intervalSchedule) Next(after time.Time) time.Time {
+	return after.Add(time.Duration(s))
+}
+
+// Every returns a Schedule that fires at a fixed interval
+func Every(interval time.Duration) Schedule {
+	return intervalSchedule(interval)
+}
+
+// Cron parses a five-field cron expression into a Schedule using dateutil's parser
+func Cron(expr string) (Schedule, error) {
+	schedule, err := dateutil.NewDateUtil().ParseCron(expr)
+	if err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Job describes a registered unit of periodic work
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      func(ctx context.Context) error
+
+	// Timeout bounds each run (0 means no timeout)
+	Timeout time.Duration
+
+	// Jitter adds a random delay in [0, Jitter) before each run to spread load
+	Jitter time.Duration
+
+	// AllowOverlap starts a new run even if the previous one is still going
+	// By default the run is skipped and OnJobSkipped is called instead.
+	AllowOverlap bool
+}
+
+// SchedulerConfig holds lifecycle hooks, typically used to record metrics
+type SchedulerConfig struct {
+	OnJobStart    func(name string)
+	OnJobComplete func(name string, duration time.Duration, err error)
+	OnJobSkipped  func(name string)
+}
+
+// SchedulerClient defines the interface for a periodic job scheduler
+type SchedulerClient interface {
+	Register(job Job) error
+	Start(ctx context.Context)
+	Stop(ctx context.Context) error
+}
+
+// Scheduler runs registered jobs according to their schedules
+type Scheduler struct {
+	config SchedulerConfig
+
+	mu      sync.Mutex
+	jobs    []*scheduledJob
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+	stopped bool
+	stopCh  chan struct{}
+
+	loops sync.WaitGroup
+	runs  sync.WaitGroup
+}
+
+// scheduledJob tracks the run state of a registered job
+type scheduledJob struct {
+	Job
+	mu      sync.Mutex
+	running int
+}
+
+// NewScheduler creates a scheduler; jobs only run once Start is called
+// Pass nil for config to run without lifecycle hooks.
+func NewScheduler(config *SchedulerConfig) SchedulerClient {
+	scheduler := &Scheduler{stopCh: make(chan struct{})}
+	if config != nil {
+		scheduler.config = *config
+	}
+	return scheduler
+}
+
+// Register adds a job; jobs registered after Start begin scheduling immediately
+func (s *Scheduler) Register(job Job) error {
+	if job.Schedule == nil || job.Run == nil {
+		return fmt.Errorf("job %q requires a schedule and a run function", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return ErrSchedulerStopped
+	}
+
+	scheduled := &scheduledJob{Job: job}
+	s.jobs = append(s.jobs, scheduled)
+	if s.started {
+		s.startLoop(scheduled)
+	}
+	return nil
+}
+
+// Start begins scheduling all registered jobs until ctx is cancelled or Stop is called
+// Each run's context derives from ctx.
+func (s *Scheduler) Start(ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.stopped {
+		return
+	}
+	s.started = true
+	s.ctx, s.cancel = context.WithCancel(ctx)
+	for _, job := range s.jobs {
+		s.startLoop(job)
+	}
+}
+
+// Stop stops scheduling new runs and waits for running jobs to finish
+// If ctx is done first, running jobs are cancelled and ctx.Err() is returned.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stopCh)
+	}
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	if cancel == nil {
+		return nil // never started
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.loops.Wait()
+		s.runs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		cancel()
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	}
+}
+
+// startLoop launches the scheduling goroutine for a job; s.mu must be held
+func (s *Scheduler) startLoop(job *scheduledJob) {
+	s.loops.Add(1)
+	go s.loop(s.ctx, job)
+}
+
+// loop waits for each scheduled time and triggers the job until the scheduler stops
+func (s *Scheduler) loop(ctx context.Context, job *scheduledJob) {
+	defer s.loops.Done()
+
+	for {
+		now := time.Now()
+		next := job.Schedule.Next(now)
+		if next.IsZero() {
+			return // schedule has no further runs
+		}
+		wait := next.Sub(now)
+		if job.Jitter > 0 {
+			wait += time.Duration(rand.Int63n(int64(job.Jitter))) // #nosec G404 -- jitter does not need crypto randomness
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.stopCh:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.trigger(ctx, job)
+	}
+}
+
+// trigger starts a run of job unless it would overlap a previous run that is still going
+func (s *Scheduler) trigger(ctx context.Context, job *scheduledJob) {
+	job.mu.Lock()
+	if job.running > 0 && !job.AllowOverlap {
+		job.mu.Unlock()
+		if s.config.OnJobSkipped != nil {
+			s.config.OnJobSkipped(job.Name)
+		}
+		return
+	}
+	job.running++
+	job.mu.Unlock()
+
+	s.runs.Add(1)
+	go func() {
+		defer s.runs.Done()
+		defer func() {
+			job.mu.Lock()
+			job.running--
+			job.mu.Unlock()
+		}()
+		s.execute(ctx, job)
+	}()
+}
+
+// execute performs a single run with timeout, panic recovery and lifecycle hooks
+func (s *Scheduler) execute(ctx context.Context, job *scheduledJob) {
+	if job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, job.Timeout)
+		defer cancel()
+	}
+
+	if s.config.OnJobStart != nil {
+		s.config.OnJobStart(job.Name)
+	}
+	start := time.Now()
+	err := runProtected(ctx, job.Run)
+	if s.config.OnJobComplete != nil {
+		s.config.OnJobComplete(job.Name, time.Since(start), err)
+	}
+}
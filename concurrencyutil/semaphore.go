@@ -0,0 +1,119 @@
+package concurrencyutil
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// SemaphoreClient defines the interface for a weighted semaphore
+type SemaphoreClient interface {
+	Acquire(ctx context.Context, n int64) error
+	TryAcquire(n int64) bool
+	Release(n int64)
+}
+
+// Semaphore limits access to a shared resource by weight
+// Waiters are served in FIFO order so a large request is not starved by smaller ones.
+type Semaphore struct {
+	size    int64
+	mu      sync.Mutex
+	current int64
+	waiters list.List
+}
+
+// semaphoreWaiter is a pending Acquire call
+type semaphoreWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// NewSemaphore creates a weighted semaphore with the given total capacity
+func NewSemaphore(size int64) SemaphoreClient {
+	return &Semaphore{size: size}
+}
+
+// Acquire blocks until n units are available or ctx is done
+// On failure nothing is acquired and ctx.Err() is returned.
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	s.mu.Lock()
+	if s.size-s.current >= n && s.waiters.Len() == 0 {
+		s.current += n
+		s.mu.Unlock()
+		return nil
+	}
+	if n > s.size {
+		s.mu.Unlock()
+		return fmt.Errorf("cannot acquire %d units from semaphore of size %d", n, s.size)
+	}
+
+	waiter := semaphoreWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(waiter)
+	s.mu.Unlock()
+
+	select {
+	case <-waiter.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		select {
+		case <-waiter.ready:
+			// Acquired just after cancellation, give the units back
+			s.current -= n
+			s.notifyWaiters()
+		default:
+			isFront := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			if isFront && s.size > s.current {
+				s.notifyWaiters()
+			}
+		}
+		s.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// TryAcquire acquires n units without blocking, reporting whether it succeeded
+func (s *Semaphore) TryAcquire(n int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size-s.current >= n && s.waiters.Len() == 0 {
+		s.current += n
+		return true
+	}
+	return false
+}
+
+// Release returns n units to the semaphore
+// Releasing more than is held panics, as it indicates a bug in the caller.
+func (s *Semaphore) Release(n int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.current -= n
+	if s.current < 0 {
+		panic("concurrencyutil: semaphore released more than held")
+	}
+	s.notifyWaiters()
+}
+
+// notifyWaiters wakes queued waiters in order while there is capacity for them
+func (s *Semaphore) notifyWaiters() {
+	for {
+		next := s.waiters.Front()
+		if next == nil {
+			return
+		}
+		waiter := next.Value.(semaphoreWaiter)
+		if s.size-s.current < waiter.n {
+			return
+		}
+		s.current += waiter.n
+		s.waiters.Remove(next)
+		close(waiter.ready)
+	}
+}
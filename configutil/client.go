@@ -0,0 +1,272 @@
+package configutil
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/collectionutil"
+	"github.com/mustanish/common-utils/v2/cryptoutil"
+)
+
+// ConfigClient defines the interface for typed configuration access
+type ConfigClient interface {
+	// Environment getters with default values
+	GetEnvString(key, defaultValue string) string
+	GetEnvInt(key string, defaultValue int) int
+	GetEnvBool(key string, defaultValue bool) bool
+	GetEnvDuration(key string, defaultValue time.Duration) time.Duration
+	GetEnvSlice(key string, defaultValue []string) []string
+	GetEnvSecret(key string, defaultValue cryptoutil.Secret) cryptoutil.Secret
+
+	// Required environment getters - failures are collected and reported by Err()
+	RequireEnvString(key string) string
+	RequireEnvInt(key string) int
+	RequireEnvBool(key string) bool
+	RequireEnvDuration(key string) time.Duration
+	RequireEnvSlice(key string) []string
+	RequireEnvSecret(key string) cryptoutil.Secret
+
+	// Error reporting
+	Err() error
+}
+
+// ConfigUtil provides typed environment variable access with aggregated error reporting
+type ConfigUtil struct {
+	collection collectionutil.CollectionClient
+
+	mu      sync.Mutex
+	missing []string
+	invalid map[string]error
+}
+
+// NewConfigUtil creates a new instance of ConfigUtil
+func NewConfigUtil() ConfigClient {
+	return &ConfigUtil{
+		collection: collectionutil.NewCollectionUtil(),
+		invalid:    make(map[string]error),
+	}
+}
+
+// GetEnvString returns the environment variable value or the default if unset or blank
+func (c *ConfigUtil) GetEnvString(key, defaultValue string) string {
+	if val, ok := c.lookup(key); ok {
+		return val
+	}
+	return defaultValue
+}
+
+// GetEnvInt returns the environment variable parsed as int or the default if unset or blank
+// An unparsable value is recorded as invalid and the default is returned.
+func (c *ConfigUtil) GetEnvInt(key string, defaultValue int) int {
+	if val, ok := c.parseInt(key); ok {
+		return val
+	}
+	return defaultValue
+}
+
+// GetEnvBool returns the environment variable parsed as bool or the default if unset or blank
+// Accepts the same values as collectionutil's ConvertToBool (true/false, 1/0, yes/no, on/off).
+func (c *ConfigUtil) GetEnvBool(key string, defaultValue bool) bool {
+	if val, ok := c.parseBool(key); ok {
+		return val
+	}
+	return defaultValue
+}
+
+// GetEnvDuration returns the environment variable parsed as time.Duration or the default if unset or blank
+func (c *ConfigUtil) GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if val, ok := c.parseDuration(key); ok {
+		return val
+	}
+	return defaultValue
+}
+
+// GetEnvSlice returns the comma-separated environment variable as a trimmed slice or the default if unset or blank
+func (c *ConfigUtil) GetEnvSlice(key string, defaultValue []string) []string {
+	if val, ok := c.parseSlice(key); ok {
+		return val
+	}
+	return defaultValue
+}
+
+// GetEnvSecret returns the environment variable wrapped in a cryptoutil.Secret or the default if unset or blank
+func (c *ConfigUtil) GetEnvSecret(key string, defaultValue cryptoutil.Secret) cryptoutil.Secret {
+	if val, ok := c.lookup(key); ok {
+		return cryptoutil.NewSecret(val)
+	}
+	return defaultValue
+}
+
+// RequireEnvString returns the environment variable value, recording it as missing if unset or blank
+func (c *ConfigUtil) RequireEnvString(key string) string {
+	val, ok := c.lookup(key)
+	if !ok {
+		c.recordMissing(key)
+	}
+	return val
+}
+
+// RequireEnvInt returns the environment variable parsed as int, recording it as missing or invalid
+func (c *ConfigUtil) RequireEnvInt(key string) int {
+	if !c.requirePresent(key) {
+		return 0
+	}
+	val, _ := c.parseInt(key)
+	return val
+}
+
+// RequireEnvBool returns the environment variable parsed as bool, recording it as missing or invalid
+func (c *ConfigUtil) RequireEnvBool(key string) bool {
+	if !c.requirePresent(key) {
+		return false
+	}
+	val, _ := c.parseBool(key)
+	return val
+}
+
+// RequireEnvDuration returns the environment variable parsed as time.Duration, recording it as missing or invalid
+func (c *ConfigUtil) RequireEnvDuration(key string) time.Duration {
+	if !c.requirePresent(key) {
+		return 0
+	}
+	val, _ := c.parseDuration(key)
+	return val
+}
+
+// RequireEnvSlice returns the comma-separated environment variable as a slice, recording it as missing if unset or blank
+func (c *ConfigUtil) RequireEnvSlice(key string) []string {
+	if !c.requirePresent(key) {
+		return nil
+	}
+	val, _ := c.parseSlice(key)
+	return val
+}
+
+// RequireEnvSecret returns the environment variable wrapped in a cryptoutil.Secret, recording it as missing if unset or blank
+func (c *ConfigUtil) RequireEnvSecret(key string) cryptoutil.Secret {
+	return cryptoutil.NewSecret(c.RequireEnvString(key))
+}
+
+// Err returns an *EnvError describing every missing or invalid variable seen so far, or nil
+func (c *ConfigUtil) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.missing) == 0 && len(c.invalid) == 0 {
+		return nil
+	}
+
+	envErr := &EnvError{
+		Missing: append([]string(nil), c.missing...),
+		Invalid: make(map[string]error, len(c.invalid)),
+	}
+	for k, v := range c.invalid {
+		envErr.Invalid[k] = v
+	}
+	return envErr
+}
+
+// lookup returns the trimmed value of an environment variable and whether it is set and non-blank
+func (c *ConfigUtil) lookup(key string) (string, bool) {
+	val, exists := os.LookupEnv(key)
+	if !exists {
+		return "", false
+	}
+	val = strings.TrimSpace(val)
+	return val, val != ""
+}
+
+// requirePresent records the key as missing when it is unset or blank
+func (c *ConfigUtil) requirePresent(key string) bool {
+	if _, ok := c.lookup(key); !ok {
+		c.recordMissing(key)
+		return false
+	}
+	return true
+}
+
+// parseInt parses an environment variable as int, recording parse failures
+func (c *ConfigUtil) parseInt(key string) (int, bool) {
+	raw, ok := c.lookup(key)
+	if !ok {
+		return 0, false
+	}
+	val, err := strconv.Atoi(raw)
+	if err != nil {
+		c.recordInvalid(key, fmt.Errorf("expected integer, got %q", raw))
+		return 0, false
+	}
+	return val, true
+}
+
+// parseBool parses an environment variable as bool, recording parse failures
+func (c *ConfigUtil) parseBool(key string) (bool, bool) {
+	raw, ok := c.lookup(key)
+	if !ok {
+		return false, false
+	}
+	val, err := c.collection.ConvertToBool(raw)
+	if err != nil {
+		c.recordInvalid(key, fmt.Errorf("expected boolean, got %q", raw))
+		return false, false
+	}
+	return val, true
+}
+
+// parseDuration parses an environment variable as time.Duration, recording parse failures
+func (c *ConfigUtil) parseDuration(key string) (time.Duration, bool) {
+	raw, ok := c.lookup(key)
+	if !ok {
+		return 0, false
+	}
+	val, err := time.ParseDuration(raw)
+	if err != nil {
+		c.recordInvalid(key, fmt.Errorf("expected duration, got %q", raw))
+		return 0, false
+	}
+	return val, true
+}
+
+// parseSlice splits a comma-separated environment variable, dropping blank entries
+func (c *ConfigUtil) parseSlice(key string) ([]string, bool) {
+	raw, ok := c.lookup(key)
+	if !ok {
+		return nil, false
+	}
+	parts, _ := c.collection.ConvertToSlice(raw, ",")
+	return c.collection.SliceFilter(parts, func(s string) bool { return s != "" }), true
+}
+
+// recordMissing notes a missing required variable once
+func (c *ConfigUtil) recordMissing(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, k := range c.missing {
+		if k == key {
+			return
+		}
+	}
+	c.missing = append(c.missing, key)
+}
+
+// recordInvalid notes an unparsable variable
+func (c *ConfigUtil) recordInvalid(key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.invalid[key] = err
+}
+
+// sortedKeys returns map keys in sorted order for stable error messages
+func sortedKeys(m map[string]error) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
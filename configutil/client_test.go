@@ -0,0 +1,633 @@
+package configutil
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/cryptoutil"
+	"github.com/mustanish/common-utils/v2/validationutil"
+)
+
+func TestNewConfigUtil(t *testing.T) {
+	util := NewConfigUtil()
+	if util == nil {
+		t.Error("NewConfigUtil() returned nil")
+	}
+}
+
+// =================== Test Environment Getters ===================
+
+func TestGetEnvWithDefaults(t *testing.T) {
+	t.Setenv("CU_STRING", " value ")
+	t.Setenv("CU_INT", "42")
+	t.Setenv("CU_BOOL", "yes")
+	t.Setenv("CU_DURATION", "1m30s")
+	t.Setenv("CU_SLICE", "a, b,,c ")
+	t.Setenv("CU_BLANK", "   ")
+
+	util := NewConfigUtil()
+
+	if got := util.GetEnvString("CU_STRING", "default"); got != "value" {
+		t.Errorf("GetEnvString() = %q, want %q", got, "value")
+	}
+	if got := util.GetEnvString("CU_BLANK", "default"); got != "default" {
+		t.Errorf("GetEnvString() for blank = %q, want default", got)
+	}
+	if got := util.GetEnvString("CU_UNSET", "default"); got != "default" {
+		t.Errorf("GetEnvString() for unset = %q, want default", got)
+	}
+	if got := util.GetEnvInt("CU_INT", 1); got != 42 {
+		t.Errorf("GetEnvInt() = %d, want 42", got)
+	}
+	if got := util.GetEnvInt("CU_UNSET", 7); got != 7 {
+		t.Errorf("GetEnvInt() for unset = %d, want 7", got)
+	}
+	if got := util.GetEnvBool("CU_BOOL", false); !got {
+		t.Error("GetEnvBool() = false, want true")
+	}
+	if got := util.GetEnvBool("CU_UNSET", true); !got {
+		t.Error("GetEnvBool() for unset = false, want default true")
+	}
+	if got := util.GetEnvDuration("CU_DURATION", time.Second); got != 90*time.Second {
+		t.Errorf("GetEnvDuration() = %v, want 1m30s", got)
+	}
+	if got := util.GetEnvDuration("CU_UNSET", time.Second); got != time.Second {
+		t.Errorf("GetEnvDuration() for unset = %v, want 1s", got)
+	}
+	if got := util.GetEnvSlice("CU_SLICE", nil); !reflect.DeepEqual(got, []string{"a", "b", "c"}) {
+		t.Errorf("GetEnvSlice() = %v, want [a b c]", got)
+	}
+	if got := util.GetEnvSlice("CU_UNSET", []string{"x"}); !reflect.DeepEqual(got, []string{"x"}) {
+		t.Errorf("GetEnvSlice() for unset = %v, want [x]", got)
+	}
+
+	if err := util.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+}
+
+func TestGetEnv_InvalidValuesFallBackAndRecord(t *testing.T) {
+	t.Setenv("CU_INT", "abc")
+	t.Setenv("CU_BOOL", "maybe")
+	t.Setenv("CU_DURATION", "soon")
+
+	util := NewConfigUtil()
+
+	if got := util.GetEnvInt("CU_INT", 5); got != 5 {
+		t.Errorf("GetEnvInt() = %d, want default 5", got)
+	}
+	if got := util.GetEnvBool("CU_BOOL", true); !got {
+		t.Error("GetEnvBool() = false, want default true")
+	}
+	if got := util.GetEnvDuration("CU_DURATION", time.Minute); got != time.Minute {
+		t.Errorf("GetEnvDuration() = %v, want default 1m", got)
+	}
+
+	var envErr *EnvError
+	if err := util.Err(); !errors.As(err, &envErr) {
+		t.Fatalf("Err() = %v, want *EnvError", err)
+	}
+	if len(envErr.Invalid) != 3 {
+		t.Errorf("Expected 3 invalid variables, got %v", envErr.Invalid)
+	}
+	if len(envErr.Missing) != 0 {
+		t.Errorf("Expected no missing variables, got %v", envErr.Missing)
+	}
+}
+
+func TestRequireEnv(t *testing.T) {
+	t.Setenv("CU_STRING", "value")
+	t.Setenv("CU_INT", "8080")
+	t.Setenv("CU_BOOL", "false")
+	t.Setenv("CU_DURATION", "5s")
+	t.Setenv("CU_SLICE", "x,y")
+
+	util := NewConfigUtil()
+
+	if got := util.RequireEnvString("CU_STRING"); got != "value" {
+		t.Errorf("RequireEnvString() = %q, want value", got)
+	}
+	if got := util.RequireEnvInt("CU_INT"); got != 8080 {
+		t.Errorf("RequireEnvInt() = %d, want 8080", got)
+	}
+	if got := util.RequireEnvBool("CU_BOOL"); got {
+		t.Error("RequireEnvBool() = true, want false")
+	}
+	if got := util.RequireEnvDuration("CU_DURATION"); got != 5*time.Second {
+		t.Errorf("RequireEnvDuration() = %v, want 5s", got)
+	}
+	if got := util.RequireEnvSlice("CU_SLICE"); !reflect.DeepEqual(got, []string{"x", "y"}) {
+		t.Errorf("RequireEnvSlice() = %v, want [x y]", got)
+	}
+	if got := util.RequireEnvSecret("CU_STRING"); got.Reveal() != "value" {
+		t.Errorf("RequireEnvSecret() = %q, want value", got.Reveal())
+	}
+	if got := util.GetEnvSecret("CU_UNSET", cryptoutil.NewSecret("fallback")); got.Reveal() != "fallback" {
+		t.Errorf("GetEnvSecret() = %q, want fallback", got.Reveal())
+	}
+	if err := util.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+}
+
+func TestRequireEnv_AggregatesErrors(t *testing.T) {
+	t.Setenv("CU_BAD_INT", "eighty")
+	t.Setenv("CU_BLANK", " ")
+
+	util := NewConfigUtil()
+
+	util.RequireEnvString("CU_MISSING_A")
+	util.RequireEnvInt("CU_MISSING_B")
+	util.RequireEnvBool("CU_MISSING_C")
+	util.RequireEnvDuration("CU_MISSING_D")
+	util.RequireEnvSlice("CU_BLANK")
+	util.RequireEnvString("CU_MISSING_A") // duplicate lookups are reported once
+	util.RequireEnvInt("CU_BAD_INT")
+
+	err := util.Err()
+	var envErr *EnvError
+	if !errors.As(err, &envErr) {
+		t.Fatalf("Err() = %v, want *EnvError", err)
+	}
+
+	expectedMissing := []string{"CU_MISSING_A", "CU_MISSING_B", "CU_MISSING_C", "CU_MISSING_D", "CU_BLANK"}
+	if !reflect.DeepEqual(envErr.Missing, expectedMissing) {
+		t.Errorf("Missing = %v, want %v", envErr.Missing, expectedMissing)
+	}
+	if _, ok := envErr.Invalid["CU_BAD_INT"]; !ok {
+		t.Errorf("Expected CU_BAD_INT to be invalid, got %v", envErr.Invalid)
+	}
+
+	msg := err.Error()
+	if !strings.Contains(msg, "required environment variables missing") || !strings.Contains(msg, "CU_BAD_INT") {
+		t.Errorf("Unexpected error message: %s", msg)
+	}
+}
+
+func TestEnvError_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *EnvError
+		expected string
+	}{
+		{
+			name:     "missing only",
+			err:      &EnvError{Missing: []string{"A", "B"}},
+			expected: "required environment variables missing: [A B]",
+		},
+		{
+			name:     "invalid only",
+			err:      &EnvError{Invalid: map[string]error{"B": errors.New("bad"), "A": errors.New("worse")}},
+			expected: "invalid environment variables: A (worse), B (bad)",
+		},
+		{
+			name:     "both",
+			err:      &EnvError{Missing: []string{"A"}, Invalid: map[string]error{"B": errors.New("bad")}},
+			expected: "required environment variables missing: [A]; invalid environment variables: B (bad)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.expected {
+				t.Errorf("Error() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+// =================== Test Loader ===================
+
+type testDatabaseConfig struct {
+	Host     string `json:"host" default:"localhost"`
+	Password string `json:"password" env:"DB_PASSWORD" secret:"true"`
+}
+
+type testServerConfig struct {
+	Port    int           `json:"port" env:"PORT" default:"8080"`
+	Timeout time.Duration `json:"timeout" default:"30s"`
+}
+
+type testAppConfig struct {
+	Name     string             `json:"name" default:"app"`
+	Debug    bool               `json:"debug" env:"DEBUG"`
+	Tags     []string           `json:"tags" env:"TAGS"`
+	Server   testServerConfig   `json:"server"`
+	Database testDatabaseConfig `json:"database"`
+	APIKey   string             `json:"api_key" env:"API_KEY" secret:"true"`
+}
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	return path
+}
+
+func TestLoader_DefaultTags(t *testing.T) {
+	var cfg testAppConfig
+	if err := NewLoader(nil).Load(&cfg); err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if cfg.Name != "app" || cfg.Server.Port != 8080 || cfg.Server.Timeout != 30*time.Second || cfg.Database.Host != "localhost" {
+		t.Errorf("Expected default tag values, got %+v", cfg)
+	}
+}
+
+func TestLoader_Precedence(t *testing.T) {
+	jsonFile := writeTestFile(t, "base.json", `{"name": "from-json", "server": {"port": 9000, "timeout": "10s"}, "tags": ["a"]}`)
+	yamlFile := writeTestFile(t, "override.yaml", "name: from-yaml\ndatabase:\n  host: db.internal\n")
+
+	t.Setenv("APP_PORT", "9100")
+	t.Setenv("APP_DEBUG", "true")
+	t.Setenv("APP_TAGS", "x, y")
+	t.Setenv("APP_DB_PASSWORD", "s3cret")
+
+	loader := NewLoader(&LoaderConfig{
+		Defaults:  map[string]any{"name": "from-defaults", "api_key": "default-key"},
+		Files:     []string{jsonFile, yamlFile},
+		EnvPrefix: "APP",
+		Overrides: map[string]any{"server": map[string]any{"timeout": "1m"}},
+	})
+
+	var cfg testAppConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+
+	if cfg.Name != "from-yaml" {
+		t.Errorf("Expected later file to override earlier sources, got %q", cfg.Name)
+	}
+	if cfg.Server.Port != 9100 {
+		t.Errorf("Expected env to override file, got %d", cfg.Server.Port)
+	}
+	if cfg.Server.Timeout != time.Minute {
+		t.Errorf("Expected override to win, got %v", cfg.Server.Timeout)
+	}
+	if cfg.Database.Host != "db.internal" || cfg.Database.Password != "s3cret" {
+		t.Errorf("Expected nested values from yaml and env, got %+v", cfg.Database)
+	}
+	if !cfg.Debug {
+		t.Error("Expected Debug to be bound from env")
+	}
+	if !reflect.DeepEqual(cfg.Tags, []string{"x", "y"}) {
+		t.Errorf("Expected env slice to override file, got %v", cfg.Tags)
+	}
+	if cfg.APIKey != "default-key" {
+		t.Errorf("Expected Defaults map value, got %q", cfg.APIKey)
+	}
+}
+
+type testFlagConfig struct {
+	Port    int           `json:"port" env:"PORT" flag:"port" default:"8080" usage:"listen port"`
+	Debug   bool          `json:"debug" flag:"debug"`
+	Hosts   []string      `json:"hosts" flag:"host"`
+	Timeout time.Duration `json:"timeout" env:"TIMEOUT" flag:"timeout" default:"30s"`
+	Server  struct {
+		Name string `json:"name" flag:"server-name"`
+	} `json:"server"`
+}
+
+func TestLoader_Flags(t *testing.T) {
+	file := writeTestFile(t, "app.json", `{"port": 9000, "timeout": "10s", "server": {"name": "from-file"}}`)
+	t.Setenv("APP_PORT", "9100")
+	t.Setenv("APP_TIMEOUT", "20s")
+
+	t.Run("flags override env, file and defaults", func(t *testing.T) {
+		loader := NewLoader(&LoaderConfig{
+			Files:     []string{file},
+			EnvPrefix: "APP",
+			Args:      []string{"-port", "9200", "-debug", "-host", "a", "-host", "b", "-server-name", "from-flag", "extra"},
+		})
+		var cfg testFlagConfig
+		if err := loader.Load(&cfg); err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if cfg.Port != 9200 || !cfg.Debug || cfg.Server.Name != "from-flag" {
+			t.Errorf("Expected flag values, got %+v", cfg)
+		}
+		if !reflect.DeepEqual(cfg.Hosts, []string{"a", "b"}) {
+			t.Errorf("Expected repeated flag to build a slice, got %v", cfg.Hosts)
+		}
+		if cfg.Timeout != 20*time.Second {
+			t.Errorf("Expected unset flag to keep the env value, got %v", cfg.Timeout)
+		}
+	})
+
+	t.Run("BindFlags on a caller FlagSet", func(t *testing.T) {
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		verbose := fs.Bool("v", false, "verbose output")
+		loader := NewLoader(&LoaderConfig{EnvPrefix: "APP"})
+		var cfg testFlagConfig
+		if err := loader.BindFlags(fs, &cfg); err != nil {
+			t.Fatalf("BindFlags() unexpected error: %v", err)
+		}
+
+		port := fs.Lookup("port")
+		if port == nil || port.DefValue != "8080" || port.Usage != "listen port (env APP_PORT)" {
+			t.Errorf("port flag = %+v, want default and usage from tags", port)
+		}
+		if err := fs.Parse([]string{"-v", "-timeout=1m"}); err != nil {
+			t.Fatalf("Parse() unexpected error: %v", err)
+		}
+		if err := loader.Load(&cfg); err != nil {
+			t.Fatalf("Load() unexpected error: %v", err)
+		}
+		if !*verbose || cfg.Timeout != time.Minute || cfg.Port != 9100 {
+			t.Errorf("Expected -timeout to override env and port to come from env, got %+v", cfg)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var cfg testFlagConfig
+		err := NewLoader(&LoaderConfig{Args: []string{"-port", "abc"}}).Load(&cfg)
+		if err == nil || !strings.Contains(err.Error(), "port") {
+			t.Errorf("Load() with invalid flag value error = %v, want port error", err)
+		}
+
+		err = NewLoader(&LoaderConfig{Args: []string{"-unknown"}}).Load(&cfg)
+		if err == nil || !strings.Contains(err.Error(), "failed to parse flags") {
+			t.Errorf("Load() with unknown flag error = %v, want parse error", err)
+		}
+
+		fs := flag.NewFlagSet("app", flag.ContinueOnError)
+		fs.String("port", "", "")
+		if err := NewLoader(nil).BindFlags(fs, &cfg); err == nil {
+			t.Error("BindFlags() with a conflicting flag expected error")
+		}
+	})
+}
+
+func TestLoader_Errors(t *testing.T) {
+	badJSON := writeTestFile(t, "bad.json", `{"name": `)
+	unsupported := writeTestFile(t, "config.toml", `name = "x"`)
+	badType := writeTestFile(t, "type.json", `{"server": {"port": "eighty"}}`)
+
+	tests := []struct {
+		name   string
+		config *LoaderConfig
+		target any
+	}{
+		{"non-pointer target", nil, testAppConfig{}},
+		{"missing file", &LoaderConfig{Files: []string{filepath.Join(t.TempDir(), "missing.json")}}, &testAppConfig{}},
+		{"malformed file", &LoaderConfig{Files: []string{badJSON}}, &testAppConfig{}},
+		{"unsupported format", &LoaderConfig{Files: []string{unsupported}}, &testAppConfig{}},
+		{"invalid field value", &LoaderConfig{Files: []string{badType}}, &testAppConfig{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := NewLoader(tt.config).Load(tt.target); err == nil {
+				t.Errorf("Load() expected error for %s", tt.name)
+			}
+		})
+	}
+}
+
+type validatedConfig struct {
+	Port int `json:"port"`
+}
+
+func (c *validatedConfig) Validate() error {
+	if c.Port <= 0 {
+		return errors.New("port must be positive")
+	}
+	return nil
+}
+
+func TestLoader_Validation(t *testing.T) {
+	hookCalled := false
+	loader := NewLoader(&LoaderConfig{
+		Validators: []Validator{
+			func(cfg any) error {
+				hookCalled = true
+				return errors.New("hook failed")
+			},
+		},
+	})
+
+	err := loader.Load(&validatedConfig{})
+	if err == nil {
+		t.Fatal("Load() expected validation error")
+	}
+	if !hookCalled {
+		t.Error("Expected validator hook to be called")
+	}
+	if !strings.Contains(err.Error(), "port must be positive") || !strings.Contains(err.Error(), "hook failed") {
+		t.Errorf("Expected all validation failures to be reported, got %v", err)
+	}
+
+	if err := NewLoader(&LoaderConfig{Overrides: map[string]any{"port": 80}}).Load(&validatedConfig{}); err != nil {
+		t.Errorf("Load() unexpected error: %v", err)
+	}
+}
+
+func TestLoader_ValidationRules(t *testing.T) {
+	type tlsConfig struct {
+		TLS      bool   `json:"tls"`
+		CertFile string `json:"cert_file"`
+	}
+	rules := ValidationRules(validationutil.RequiredIf("tls", true, "cert_file"))
+
+	err := NewLoader(&LoaderConfig{Overrides: map[string]any{"tls": true}, Validators: []Validator{rules}}).Load(&tlsConfig{})
+	if err == nil || !strings.Contains(err.Error(), "cert_file") {
+		t.Errorf("Load() error = %v, want cert_file reported", err)
+	}
+
+	overrides := map[string]any{"tls": true, "cert_file": "server.pem"}
+	if err := NewLoader(&LoaderConfig{Overrides: overrides, Validators: []Validator{rules}}).Load(&tlsConfig{}); err != nil {
+		t.Errorf("Load() unexpected error: %v", err)
+	}
+}
+
+func TestLoader_Dump(t *testing.T) {
+	cfg := testAppConfig{
+		Name:     "svc",
+		APIKey:   "top-secret",
+		Database: testDatabaseConfig{Host: "db", Password: "hunter2"},
+	}
+
+	dump, err := NewLoader(nil).Dump(&cfg)
+	if err != nil {
+		t.Fatalf("Dump() unexpected error: %v", err)
+	}
+	if dump["api_key"] != RedactedValue {
+		t.Errorf("Expected api_key to be redacted, got %v", dump["api_key"])
+	}
+	database, ok := dump["database"].(map[string]any)
+	if !ok || database["password"] != RedactedValue || database["host"] != "db" {
+		t.Errorf("Expected nested password to be redacted, got %v", dump["database"])
+	}
+	if dump["name"] != "svc" {
+		t.Errorf("Expected non-secret fields to be kept, got %v", dump["name"])
+	}
+	if cfg.APIKey != "top-secret" {
+		t.Error("Dump() must not modify the original config")
+	}
+}
+
+type testSecretConfig struct {
+	User     string            `json:"user"`
+	Password cryptoutil.Secret `json:"password" env:"PASSWORD" flag:"password"`
+	Token    cryptoutil.Secret `json:"token" flag:"token" default:"dev-token"`
+}
+
+func TestLoader_SecretFields(t *testing.T) {
+	path := writeTestFile(t, "creds.yaml", "user: bob\npassword: from-file\n")
+	t.Setenv("SECRET_PASSWORD", "from-env")
+
+	loader := NewLoader(&LoaderConfig{Files: []string{path}, EnvPrefix: "SECRET"})
+	var cfg testSecretConfig
+	if err := loader.Load(&cfg); err != nil {
+		t.Fatalf("Load() unexpected error: %v", err)
+	}
+	if cfg.User != "bob" || cfg.Password.Reveal() != "from-env" || cfg.Token.Reveal() != "dev-token" {
+		t.Errorf("Expected secrets bound from env and default tags, got user=%q password=%q token=%q",
+			cfg.User, cfg.Password.Reveal(), cfg.Token.Reveal())
+	}
+
+	dump, err := loader.Dump(&cfg)
+	if err != nil {
+		t.Fatalf("Dump() unexpected error: %v", err)
+	}
+	if dump["password"] != RedactedValue || dump["token"] != RedactedValue || dump["user"] != "bob" {
+		t.Errorf("Expected Secret fields to be redacted without a secret tag, got %v", dump)
+	}
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	if err := NewLoader(nil).BindFlags(fs, &testSecretConfig{}); err != nil {
+		t.Fatalf("BindFlags() unexpected error: %v", err)
+	}
+	if f := fs.Lookup("token"); f == nil || f.DefValue != "" {
+		t.Errorf("Expected secret flag without a shown default, got %+v", f)
+	}
+
+	next := cfg
+	next.Password = cryptoutil.NewSecret("rotated")
+	changes, err := loader.(*Loader).diff(&cfg, &next)
+	expected := []FieldChange{{Path: "password", Old: RedactedValue, New: RedactedValue}}
+	if err != nil || !reflect.DeepEqual(changes, expected) {
+		t.Errorf("diff() = %+v, %v, want a redacted password change", changes, err)
+	}
+}
+
+// =================== Test Hot Reload ===================
+
+func TestLoader_ReloadRequiresWatch(t *testing.T) {
+	if err := NewLoader(nil).Reload(); err == nil {
+		t.Error("Reload() expected error before Watch")
+	}
+}
+
+func TestLoader_WatchFileChange(t *testing.T) {
+	path := writeTestFile(t, "config.json", `{"name": "v1", "api_key": "old-key"}`)
+
+	loader := NewLoader(&LoaderConfig{Files: []string{path}, PollInterval: 10 * time.Millisecond})
+	changes := make(chan ConfigChange, 1)
+	loader.Subscribe(func(change ConfigChange) { changes <- change })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var cfg testAppConfig
+	if err := loader.Watch(ctx, &cfg); err != nil {
+		t.Fatalf("Watch() unexpected error: %v", err)
+	}
+	if cfg.Name != "v1" || loader.Current() != &cfg {
+		t.Fatalf("Expected initial load into target, got %+v", cfg)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"name": "version-2", "api_key": "new-key"}`), 0o600); err != nil {
+		t.Fatalf("failed to update config file: %v", err)
+	}
+
+	select {
+	case change := <-changes:
+		oldCfg, newCfg := change.Old.(*testAppConfig), change.New.(*testAppConfig)
+		if oldCfg.Name != "v1" || newCfg.Name != "version-2" {
+			t.Errorf("Unexpected snapshots: old=%q new=%q", oldCfg.Name, newCfg.Name)
+		}
+		expected := []FieldChange{
+			{Path: "api_key", Old: RedactedValue, New: RedactedValue},
+			{Path: "name", Old: "v1", New: "version-2"},
+		}
+		if !reflect.DeepEqual(change.Changes, expected) {
+			t.Errorf("Changes = %+v, want %+v", change.Changes, expected)
+		}
+		if loader.Current() != change.New {
+			t.Error("Expected Current() to return the new snapshot")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected subscriber to be notified of file change")
+	}
+
+	if cfg.Name != "v1" {
+		t.Error("Watch must not mutate the original target after the initial load")
+	}
+}
+
+func TestLoader_ReloadWithoutChanges(t *testing.T) {
+	path := writeTestFile(t, "config.json", `{"name": "same"}`)
+	loader := NewLoader(&LoaderConfig{Files: []string{path}, PollInterval: time.Hour})
+
+	notified := false
+	loader.Subscribe(func(ConfigChange) { notified = true })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var cfg testAppConfig
+	if err := loader.Watch(ctx, &cfg); err != nil {
+		t.Fatalf("Watch() unexpected error: %v", err)
+	}
+	if err := loader.Reload(); err != nil {
+		t.Fatalf("Reload() unexpected error: %v", err)
+	}
+	if notified {
+		t.Error("Expected no notification when nothing changed")
+	}
+}
+
+func TestLoader_ReloadErrorHook(t *testing.T) {
+	path := writeTestFile(t, "config.json", `{"name": "ok"}`)
+	errs := make(chan error, 1)
+	loader := NewLoader(&LoaderConfig{
+		Files:           []string{path},
+		PollInterval:    10 * time.Millisecond,
+		ReloadErrorHook: func(err error) { errs <- err },
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var cfg testAppConfig
+	if err := loader.Watch(ctx, &cfg); err != nil {
+		t.Fatalf("Watch() unexpected error: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(`{"name": `), 0o600); err != nil {
+		t.Fatalf("failed to update config file: %v", err)
+	}
+
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "config reload failed") {
+			t.Errorf("Unexpected reload error: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected reload error hook to be called")
+	}
+
+	if loader.Current() != &cfg {
+		t.Error("Expected failed reload to keep the previous snapshot")
+	}
+}
@@ -0,0 +1,29 @@
+package configutil
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnvError aggregates every missing or invalid environment variable seen by a ConfigUtil
+// It lets services report all configuration problems at startup instead of failing one at a time.
+type EnvError struct {
+	Missing []string
+	Invalid map[string]error
+}
+
+// Error implements the error interface for EnvError
+func (e *EnvError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, fmt.Sprintf("required environment variables missing: %v", e.Missing))
+	}
+	if len(e.Invalid) > 0 {
+		invalid := make([]string, 0, len(e.Invalid))
+		for _, key := range sortedKeys(e.Invalid) {
+			invalid = append(invalid, fmt.Sprintf("%s (%v)", key, e.Invalid[key]))
+		}
+		parts = append(parts, fmt.Sprintf("invalid environment variables: %s", strings.Join(invalid, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
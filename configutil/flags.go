@@ -0,0 +1,144 @@
+package configutil
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// flagValue holds the raw text of a config flag and whether it was given on the command line
+// Values stay strings until Load coerces them with the other sources, so every field type that env
+// variables support works as a flag too. Repeating a slice flag appends to it.
+type flagValue struct {
+	raw    string
+	set    bool
+	isBool bool
+	slice  bool
+}
+
+// String implements flag.Value
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.raw
+}
+
+// Set implements flag.Value
+func (v *flagValue) Set(s string) error {
+	if v.set && v.slice {
+		s = v.raw + "," + s
+	}
+	v.raw = s
+	v.set = true
+	return nil
+}
+
+// IsBoolFlag lets bool fields be given as -debug without a value
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// boundFlag ties a registered flag to the key path of its field
+type boundFlag struct {
+	name  string
+	keys  []string
+	field reflect.StructField
+	value *flagValue
+}
+
+// BindFlags registers a flag for every `flag`-tagged field of target on fs (flag.CommandLine when nil)
+// Call it before fs.Parse so -h lists the flags with their `usage` and (except for secrets) `default` tags; Load then applies
+// the flags that were given on the command line above env variables and files. Flags left unset do not
+// override lower layers.
+func (l *Loader) BindFlags(fs *flag.FlagSet, target any) error {
+	t, err := structPtrType(target)
+	if err != nil {
+		return err
+	}
+	if fs == nil {
+		fs = flag.CommandLine
+	}
+
+	var flags []boundFlag
+	walkFields(t, nil, func(keys []string, sf reflect.StructField) bool {
+		name, ok := sf.Tag.Lookup("flag")
+		if !ok || name == "" {
+			return true
+		}
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		value := &flagValue{isBool: ft.Kind() == reflect.Bool, slice: ft.Kind() == reflect.Slice}
+		flags = append(flags, boundFlag{name: name, keys: keys, field: sf, value: value})
+		return true
+	})
+
+	seen := make(map[string]bool, len(flags))
+	for _, f := range flags {
+		if seen[f.name] || fs.Lookup(f.name) != nil {
+			return fmt.Errorf("flag -%s is defined more than once", f.name)
+		}
+		seen[f.name] = true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, f := range flags {
+		fs.Var(f.value, f.name, l.flagUsage(f.keys, f.field))
+		if def, ok := f.field.Tag.Lookup("default"); ok && !isSecretField(f.field) {
+			fs.Lookup(f.name).DefValue = def
+		}
+		l.flags = append(l.flags, f)
+	}
+	return nil
+}
+
+// parseArgs binds the target's flags to a private FlagSet and parses LoaderConfig.Args, once per Loader
+func (l *Loader) parseArgs(target any) error {
+	if l.config.Args == nil {
+		return nil
+	}
+	l.argsOnce.Do(func() {
+		fs := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+		if l.argsErr = l.BindFlags(fs, target); l.argsErr != nil {
+			return
+		}
+		if err := fs.Parse(l.config.Args); err != nil {
+			l.argsErr = fmt.Errorf("failed to parse flags: %w", err)
+		}
+	})
+	return l.argsErr
+}
+
+// flagValues returns the flags given on the command line as a nested map of raw strings
+func (l *Loader) flagValues() map[string]any {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	values := map[string]any{}
+	for _, f := range l.flags {
+		if f.value.set {
+			setPath(values, f.keys, f.value.raw)
+		}
+	}
+	return values
+}
+
+// flagUsage builds the help text of a flag from its `usage` tag, config key and env variable
+func (l *Loader) flagUsage(keys []string, sf reflect.StructField) string {
+	usage := sf.Tag.Get("usage")
+	if usage == "" {
+		usage = "sets " + strings.Join(keys, ".")
+	}
+	if env := sf.Tag.Get("env"); env != "" {
+		if l.config.EnvPrefix != "" {
+			env = l.config.EnvPrefix + "_" + env
+		}
+		usage += " (env " + env + ")"
+	}
+	return usage
+}
@@ -0,0 +1,440 @@
+package configutil
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/collectionutil"
+	"github.com/mustanish/common-utils/v2/cryptoutil"
+	"github.com/mustanish/common-utils/v2/jsonutil"
+	"github.com/mustanish/common-utils/v2/validationutil"
+	"gopkg.in/yaml.v3"
+)
+
+// RedactedValue replaces secret-tagged fields in Dump output
+const RedactedValue = "[REDACTED]"
+
+// Validator is a hook run against the bound config struct after loading
+type Validator func(cfg any) error
+
+// ValidationRules returns a Validator checking the config struct against validationutil rules
+// Fields are named by their json tags, as in config files:
+//
+//	Validators: []configutil.Validator{configutil.ValidationRules(
+//		validationutil.RequiredIf("tls", true, "cert_file", "key_file"),
+//	)},
+func ValidationRules(rules ...validationutil.Rule) Validator {
+	return func(cfg any) error {
+		return validationutil.Validate(cfg, rules...)
+	}
+}
+
+// LoaderConfig holds the sources merged by a Loader, from lowest to highest precedence:
+// `default` struct tags, Defaults, Files (in order), environment variables (`env` struct tags),
+// command-line flags (`flag` struct tags), Overrides
+type LoaderConfig struct {
+	Defaults   map[string]any
+	Files      []string
+	EnvPrefix  string
+	Overrides  map[string]any
+	Validators []Validator
+
+	// Command-line arguments (e.g. os.Args[1:]) parsed against the `flag` tags on the first Load
+	// Leave nil when registering the flags on your own FlagSet with BindFlags.
+	Args []string
+
+	// Hot-reload settings used by Watch
+	PollInterval    time.Duration
+	ReloadErrorHook func(err error)
+}
+
+// LoaderClient defines the interface for layered configuration loading
+type LoaderClient interface {
+	Load(target any) error
+	Dump(target any) (map[string]any, error)
+	BindFlags(fs *flag.FlagSet, target any) error
+
+	// Hot reload
+	Watch(ctx context.Context, target any) error
+	Reload() error
+	Subscribe(fn func(change ConfigChange))
+	Current() any
+}
+
+// Loader merges layered configuration sources into a tagged struct
+//
+// Struct fields are keyed by their json tag. Additional tags:
+//   - `env:"PORT"` binds the field to an environment variable (prefixed with EnvPrefix + "_" when set)
+//   - `flag:"port"` binds the field to a command-line flag, described by an optional `usage:"..."` tag
+//   - `default:"8080"` provides the lowest-precedence value for the field
+//   - `secret:"true"` redacts the field in Dump output, as is done for every cryptoutil.Secret field
+type Loader struct {
+	config     LoaderConfig
+	json       jsonutil.JSONClient
+	collection collectionutil.CollectionClient
+
+	mu          sync.Mutex
+	targetType  reflect.Type
+	current     any
+	subscribers []func(ConfigChange)
+	flags       []boundFlag
+
+	argsOnce sync.Once
+	argsErr  error
+}
+
+// NewLoader creates a new configuration loader
+// Pass nil for config to bind only `default` tags and environment variables
+func NewLoader(config *LoaderConfig) LoaderClient {
+	loader := &Loader{
+		json:       jsonutil.NewJSONUtil(),
+		collection: collectionutil.NewCollectionUtil(),
+	}
+	if config != nil {
+		loader.config = *config
+	}
+	return loader
+}
+
+// Load merges all sources and binds the result onto target, which must be a pointer to a struct
+// Values already set on target act as a base layer beneath every source.
+func (l *Loader) Load(target any) error {
+	t, err := structPtrType(target)
+	if err != nil {
+		return err
+	}
+	if err := l.parseArgs(target); err != nil {
+		return err
+	}
+
+	merged, err := l.mergedSources(t)
+	if err != nil {
+		return err
+	}
+
+	if err := l.json.MapToStruct(merged, target); err != nil {
+		return fmt.Errorf("failed to bind config: %w", err)
+	}
+
+	return l.validate(target)
+}
+
+// Dump returns the config as a map keyed by json names with secret-tagged fields redacted
+func (l *Loader) Dump(target any) (map[string]any, error) {
+	m, err := l.json.StructToMap(target)
+	if err != nil {
+		return nil, err
+	}
+	t := reflect.TypeOf(target)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	redactSecrets(t, m)
+	return m, nil
+}
+
+// mergedSources reads every layer in precedence order and coerces the result to the target's field types
+func (l *Loader) mergedSources(t reflect.Type) (map[string]any, error) {
+	merged := map[string]any{}
+
+	deepMerge(merged, tagValues(t, "default", nil))
+	deepMerge(merged, l.config.Defaults)
+
+	for _, path := range l.config.Files {
+		fileValues, err := readConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		deepMerge(merged, fileValues)
+	}
+
+	deepMerge(merged, tagValues(t, "env", l.lookupEnv))
+	deepMerge(merged, l.flagValues())
+	deepMerge(merged, l.config.Overrides)
+
+	if err := l.coerceMap(t, merged, ""); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// lookupEnv resolves an `env` tag to its value, applying the configured prefix
+func (l *Loader) lookupEnv(name string) (string, bool) {
+	if l.config.EnvPrefix != "" {
+		name = l.config.EnvPrefix + "_" + name
+	}
+	val, exists := os.LookupEnv(name)
+	if !exists || strings.TrimSpace(val) == "" {
+		return "", false
+	}
+	return strings.TrimSpace(val), true
+}
+
+// validate runs the struct's own Validate method (if any) and all configured validators
+func (l *Loader) validate(target any) error {
+	var failures []string
+	if v, ok := target.(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	for _, validator := range l.config.Validators {
+		if err := validator(target); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("config validation failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// coerceMap converts string values (from env, tags or files) into the types of the matching fields
+func (l *Loader) coerceMap(t reflect.Type, m map[string]any, path string) error {
+	var err error
+	walkFields(t, nil, func(keys []string, sf reflect.StructField) bool {
+		if err != nil || len(keys) != 1 {
+			return false
+		}
+		key := keys[0]
+		val, exists := m[key]
+		if !exists {
+			return false
+		}
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		switch v := val.(type) {
+		case string:
+			m[key], err = l.parseString(ft, v)
+			if err != nil {
+				err = fmt.Errorf("invalid value for config field '%s': %w", joinKey(path, key), err)
+			}
+		case map[string]any:
+			if isNestedStruct(ft) {
+				err = l.coerceMap(ft, v, joinKey(path, key))
+			}
+		}
+		return false
+	})
+	return err
+}
+
+// parseString converts a raw string into a value assignable to type t
+func (l *Loader) parseString(t reflect.Type, s string) (any, error) {
+	if t == durationType {
+		return time.ParseDuration(s)
+	}
+	if reflect.PtrTo(t).Implements(textUnmarshalerType) {
+		return s, nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return s, nil
+	case reflect.Bool:
+		return l.collection.ConvertToBool(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseUint(strings.TrimSpace(s), 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(strings.TrimSpace(s), 64)
+	case reflect.Slice:
+		parts, _ := l.collection.ConvertToSlice(s, ",")
+		items := make([]any, 0, len(parts))
+		for _, part := range parts {
+			if part == "" {
+				continue
+			}
+			item, err := l.parseString(t.Elem(), part)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	}
+	return s, nil
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	secretType          = reflect.TypeOf(cryptoutil.Secret{})
+)
+
+// structPtrType validates that target is a non-nil pointer to a struct and returns the struct type
+func structPtrType(target any) (reflect.Type, error) {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected non-nil pointer to struct, got %T", target)
+	}
+	return rv.Elem().Type(), nil
+}
+
+// readConfigFile parses a JSON or YAML file into a map based on its extension
+func readConfigFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	values := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &values)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &values)
+	default:
+		return nil, fmt.Errorf("unsupported config file format: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// deepMerge copies src into dst, merging nested maps so later layers only override the keys they set
+func deepMerge(dst, src map[string]any) {
+	for k, v := range src {
+		srcMap, srcIsMap := v.(map[string]any)
+		dstMap, dstIsMap := dst[k].(map[string]any)
+		if srcIsMap && dstIsMap {
+			deepMerge(dstMap, srcMap)
+			continue
+		}
+		if srcIsMap {
+			copied := map[string]any{}
+			deepMerge(copied, srcMap)
+			dst[k] = copied
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// tagValues builds a nested map from a struct tag, e.g. `default` values or `env` lookups
+// When lookup is nil the tag value itself is used.
+func tagValues(t reflect.Type, tag string, lookup func(string) (string, bool)) map[string]any {
+	values := map[string]any{}
+	walkFields(t, nil, func(keys []string, sf reflect.StructField) bool {
+		name, ok := sf.Tag.Lookup(tag)
+		if !ok || name == "" {
+			return true
+		}
+		val := name
+		if lookup != nil {
+			if val, ok = lookup(name); !ok {
+				return true
+			}
+		}
+		setPath(values, keys, val)
+		return true
+	})
+	return values
+}
+
+// setPath stores val in a nested map at the given key path
+func setPath(m map[string]any, keys []string, val any) {
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := m[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[key] = next
+		}
+		m = next
+	}
+	m[keys[len(keys)-1]] = val
+}
+
+// redactSecrets replaces secret-tagged fields in a dumped map, descending into nested structs
+func redactSecrets(t reflect.Type, m map[string]any) {
+	walkFields(t, nil, func(keys []string, sf reflect.StructField) bool {
+		key := keys[0]
+		if _, exists := m[key]; !exists {
+			return false
+		}
+		if isSecretField(sf) {
+			m[key] = RedactedValue
+			return false
+		}
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+		if nested, ok := m[key].(map[string]any); ok && isNestedStruct(ft) {
+			redactSecrets(ft, nested)
+		}
+		return false
+	})
+}
+
+// isSecretField reports whether a field is tagged `secret:"true"` or holds a cryptoutil.Secret
+func isSecretField(sf reflect.StructField) bool {
+	secret, _ := strconv.ParseBool(sf.Tag.Get("secret"))
+	return secret || sf.Type == secretType
+}
+
+// walkFields visits exported fields keyed by json name, flattening untagged embedded structs
+// fn receives the key path of each field and returns whether to descend into nested struct fields.
+func walkFields(t reflect.Type, prefix []string, fn func(keys []string, sf reflect.StructField) bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+
+		ft := sf.Type
+		for ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			walkFields(ft, prefix, fn)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		keys := make([]string, len(prefix)+1)
+		copy(keys, prefix)
+		keys[len(prefix)] = name
+
+		if fn(keys, sf) && isNestedStruct(ft) {
+			walkFields(ft, keys, fn)
+		}
+	}
+}
+
+// isNestedStruct reports whether t is a plain struct whose fields are configured individually
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// joinKey builds a dotted key path for error messages
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,210 @@
+package configutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"syscall"
+	"time"
+)
+
+// defaultPollInterval is how often watched config files are checked for changes
+const defaultPollInterval = 2 * time.Second
+
+// FieldChange describes a single config value that differs between two snapshots
+// Values of secret-tagged fields are redacted.
+type FieldChange struct {
+	Path string
+	Old  any
+	New  any
+}
+
+// ConfigChange is delivered to subscribers after a reload produced a different config
+// Old and New are pointers to distinct snapshots of the watched struct type.
+type ConfigChange struct {
+	Old     any
+	New     any
+	Changes []FieldChange
+}
+
+// fileState captures what is compared between polls of a config file
+type fileState struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+// Subscribe registers a function called with old/new snapshots and a diff after each effective reload
+func (l *Loader) Subscribe(fn func(change ConfigChange)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.subscribers = append(l.subscribers, fn)
+}
+
+// Current returns the latest snapshot produced by Watch or Reload, or nil before Watch is called
+func (l *Loader) Current() any {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.current
+}
+
+// Watch loads target and then reloads on config file changes or SIGHUP until ctx is cancelled
+// target itself is never mutated after the initial load; each reload builds a fresh snapshot of the
+// same type which is exposed via Current() and delivered to subscribers.
+func (l *Loader) Watch(ctx context.Context, target any) error {
+	t, err := structPtrType(target)
+	if err != nil {
+		return err
+	}
+	if err := l.Load(target); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	l.targetType = t
+	l.current = target
+	l.mu.Unlock()
+
+	interval := l.config.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+
+	states := l.fileStates()
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		defer signal.Stop(hup)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hup:
+				states = l.fileStates()
+				l.reloadAndReport()
+			case <-ticker.C:
+				latest := l.fileStates()
+				if !reflect.DeepEqual(latest, states) {
+					states = latest
+					l.reloadAndReport()
+				}
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Reload re-reads all sources into a new snapshot and notifies subscribers if anything changed
+// Watch must have been called first so the loader knows which struct type to build.
+func (l *Loader) Reload() error {
+	l.mu.Lock()
+	t, old := l.targetType, l.current
+	l.mu.Unlock()
+
+	if t == nil {
+		return fmt.Errorf("reload requires Watch to be called first")
+	}
+
+	next := reflect.New(t).Interface()
+	if err := l.Load(next); err != nil {
+		return fmt.Errorf("config reload failed: %w", err)
+	}
+
+	changes, err := l.diff(old, next)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	l.current = next
+	subscribers := append([]func(ConfigChange){}, l.subscribers...)
+	l.mu.Unlock()
+
+	change := ConfigChange{Old: old, New: next, Changes: changes}
+	for _, fn := range subscribers {
+		fn(change)
+	}
+	return nil
+}
+
+// reloadAndReport reloads and hands any failure to the configured error hook
+func (l *Loader) reloadAndReport() {
+	if err := l.Reload(); err != nil && l.config.ReloadErrorHook != nil {
+		l.config.ReloadErrorHook(err)
+	}
+}
+
+// fileStates stats every configured file
+func (l *Loader) fileStates() map[string]fileState {
+	states := make(map[string]fileState, len(l.config.Files))
+	for _, path := range l.config.Files {
+		info, err := os.Stat(path)
+		if err != nil {
+			states[path] = fileState{}
+			continue
+		}
+		states[path] = fileState{modTime: info.ModTime(), size: info.Size(), exists: true}
+	}
+	return states
+}
+
+// diff compares two snapshots field by field, reporting redacted values for secret fields
+func (l *Loader) diff(old, next any) ([]FieldChange, error) {
+	oldRaw, err := l.json.StructToMap(old)
+	if err != nil {
+		return nil, err
+	}
+	nextRaw, err := l.json.StructToMap(next)
+	if err != nil {
+		return nil, err
+	}
+	oldDump, _ := l.Dump(old)
+	nextDump, _ := l.Dump(next)
+
+	oldFlat, nextFlat := flatten(oldRaw, ""), flatten(nextRaw, "")
+	oldShown, nextShown := flatten(oldDump, ""), flatten(nextDump, "")
+
+	paths := map[string]bool{}
+	for p := range oldFlat {
+		paths[p] = true
+	}
+	for p := range nextFlat {
+		paths[p] = true
+	}
+
+	var changes []FieldChange
+	for p := range paths {
+		if !reflect.DeepEqual(oldFlat[p], nextFlat[p]) {
+			changes = append(changes, FieldChange{Path: p, Old: oldShown[p], New: nextShown[p]})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// flatten converts nested maps into dotted paths, treating other values as leaves
+func flatten(m map[string]any, prefix string) map[string]any {
+	flat := map[string]any{}
+	for k, v := range m {
+		path := joinKey(prefix, k)
+		if nested, ok := v.(map[string]any); ok {
+			for nk, nv := range flatten(nested, path) {
+				flat[nk] = nv
+			}
+			continue
+		}
+		flat[path] = v
+	}
+	return flat
+}
@@ -0,0 +1,160 @@
+package contextutil
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/logutil"
+)
+
+// RequestIDHeader is the HTTP header used to propagate request IDs between services
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDField is the log field under which WithRequestID exposes the request ID
+const RequestIDField = "request_id"
+
+// requestIDKey stores the correlation ID of the current request
+var requestIDKey = NewKey[string]("request_id")
+
+// WithRequestID returns a copy of ctx carrying a request ID
+// The ID is also added to logutil's context fields so loggers bound with WithContext include it,
+// and httputil forwards it in the X-Request-ID header.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = requestIDKey.With(ctx, id)
+	return logutil.WithFields(ctx, logutil.Fields{RequestIDField: id})
+}
+
+// RequestIDFrom returns the request ID stored in ctx, or "" if there is none
+func RequestIDFrom(ctx context.Context) string {
+	id, _ := requestIDKey.From(ctx)
+	return id
+}
+
+// NewRequestID returns a random UUID (version 4) for use as a request ID
+func NewRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("contextutil: reading random bytes failed: " + err.Error())
+	}
+	b[6] = b[6]&0x0f | 0x40 // version 4
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant
+
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+// WithLogger returns a copy of ctx carrying logger (see logutil.NewContext)
+func WithLogger(ctx context.Context, logger logutil.Logger) context.Context {
+	return logutil.NewContext(ctx, logger)
+}
+
+// LoggerFrom returns the logger stored in ctx bound to its fields, or a no-op logger (see logutil.FromContext)
+func LoggerFrom(ctx context.Context) logutil.Logger {
+	return logutil.FromContext(ctx)
+}
+
+// Detach returns a context that keeps ctx's values but is never cancelled and has no deadline
+// Use it for work that must outlive the request, such as audit writes or async cleanup.
+func Detach(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return detachedContext{parent: ctx}
+}
+
+// detachedContext forwards values to its parent but ignores its cancellation
+type detachedContext struct {
+	parent context.Context
+}
+
+// Deadline implements context.Context; a detached context has no deadline
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+// Done implements context.Context; a detached context is never cancelled
+func (detachedContext) Done() <-chan struct{} { return nil }
+
+// Err implements context.Context
+func (detachedContext) Err() error { return nil }
+
+// Value implements context.Context by reading from the parent
+func (d detachedContext) Value(key any) any { return d.parent.Value(key) }
+
+// MergeCancel returns a context carrying ctx1's values that is cancelled when either ctx1 or ctx2 is done
+// The earlier of the two deadlines applies. Err reports why the context ended, e.g. DeadlineExceeded when
+// ctx2 expired. Call the returned cancel function to release resources.
+func MergeCancel(ctx1, ctx2 context.Context) (context.Context, context.CancelFunc) {
+	if ctx1 == nil {
+		ctx1 = context.Background()
+	}
+	if ctx2 == nil {
+		ctx2 = context.Background()
+	}
+
+	var base context.Context
+	var cancel context.CancelFunc
+	deadline1, ok1 := ctx1.Deadline()
+	deadline2, ok2 := ctx2.Deadline()
+	adopted := ok2 && (!ok1 || deadline2.Before(deadline1))
+	if adopted {
+		base, cancel = context.WithDeadline(ctx1, deadline2)
+	} else {
+		base, cancel = context.WithCancel(ctx1)
+	}
+	merged := &mergedContext{Context: base}
+
+	if done := ctx2.Done(); done != nil {
+		go func() {
+			select {
+			case <-done:
+			case <-base.Done():
+				return
+			}
+			err := ctx2.Err()
+			if adopted && err == context.DeadlineExceeded {
+				// base carries the same deadline and expires on its own
+				return
+			}
+			merged.mu.Lock()
+			if base.Err() == nil {
+				merged.err = err
+			}
+			merged.mu.Unlock()
+			cancel()
+		}()
+	}
+	return merged, cancel
+}
+
+// mergedContext reports ctx2's error when ctx2 ended a MergeCancel context
+type mergedContext struct {
+	context.Context
+
+	mu  sync.Mutex
+	err error
+}
+
+// Err implements context.Context
+func (m *mergedContext) Err() error {
+	err := m.Context.Err()
+	if err == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	return err
+}
@@ -0,0 +1,213 @@
+package contextutil
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/logutil"
+)
+
+// =================== Test Typed Keys ===================
+
+func TestKey(t *testing.T) {
+	userKey := NewKey[int]("user_id")
+	otherKey := NewKey[int]("user_id")
+
+	ctx := userKey.With(context.Background(), 42)
+	if value, ok := userKey.From(ctx); !ok || value != 42 {
+		t.Errorf("From() = %v, %v; want 42, true", value, ok)
+	}
+	if _, ok := otherKey.From(ctx); ok {
+		t.Error("keys with the same name should not collide")
+	}
+	if value, ok := userKey.From(context.Background()); ok || value != 0 {
+		t.Errorf("From(empty) = %v, %v; want 0, false", value, ok)
+	}
+	if _, ok := userKey.From(nil); ok {
+		t.Error("From(nil) should report absence")
+	}
+	if userKey.String() != "user_id" {
+		t.Errorf("String() = %q, want user_id", userKey.String())
+	}
+	if userKey.MustFrom(ctx) != 42 {
+		t.Error("MustFrom() returned the wrong value")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("MustFrom() on a missing value should panic")
+		}
+	}()
+	otherKey.MustFrom(ctx)
+}
+
+// =================== Test Request IDs and Loggers ===================
+
+func TestRequestID(t *testing.T) {
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	if id := RequestIDFrom(ctx); id != "req-1" {
+		t.Errorf("RequestIDFrom() = %q, want req-1", id)
+	}
+	if id := RequestIDFrom(context.Background()); id != "" {
+		t.Errorf("RequestIDFrom(empty) = %q, want empty", id)
+	}
+	if fields := logutil.FieldsFrom(ctx); fields[RequestIDField] != "req-1" {
+		t.Errorf("log fields = %v, want request_id", fields)
+	}
+}
+
+func TestNewRequestID(t *testing.T) {
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	first, second := NewRequestID(), NewRequestID()
+	for _, id := range []string{first, second} {
+		if !uuid.MatchString(id) {
+			t.Errorf("NewRequestID() = %q, want a version 4 UUID", id)
+		}
+	}
+	if first == second {
+		t.Errorf("NewRequestID() returned %q twice", first)
+	}
+}
+
+// recordingSink captures emitted entries
+type recordingSink struct {
+	fields []logutil.Fields
+}
+
+func (s *recordingSink) Enabled(logutil.Level) bool { return true }
+
+func (s *recordingSink) Emit(_ context.Context, _ logutil.Level, _ string, fields logutil.Fields) {
+	s.fields = append(s.fields, fields)
+}
+
+func TestLogger(t *testing.T) {
+	sink := &recordingSink{}
+	ctx := WithLogger(context.Background(), logutil.NewLogger(sink, nil))
+	ctx = WithRequestID(ctx, "req-2")
+
+	LoggerFrom(ctx).Info("handled")
+	if len(sink.fields) != 1 || sink.fields[0][RequestIDField] != "req-2" {
+		t.Errorf("logged fields = %v, want request_id from context", sink.fields)
+	}
+
+	// Without a logger a no-op logger is returned
+	LoggerFrom(context.Background()).Info("dropped")
+}
+
+// =================== Test Cancellation Helpers ===================
+
+func TestDetach(t *testing.T) {
+	parent, cancel := context.WithTimeout(WithRequestID(context.Background(), "req-3"), time.Millisecond)
+	cancel()
+
+	detached := Detach(parent)
+	if detached.Err() != nil || detached.Done() != nil {
+		t.Error("Detach() should not inherit cancellation")
+	}
+	if _, ok := detached.Deadline(); ok {
+		t.Error("Detach() should not inherit the deadline")
+	}
+	if RequestIDFrom(detached) != "req-3" {
+		t.Error("Detach() should keep values")
+	}
+
+	child, childCancel := context.WithCancel(detached)
+	childCancel()
+	if !errors.Is(child.Err(), context.Canceled) {
+		t.Error("contexts derived from a detached context should still be cancellable")
+	}
+}
+
+func TestMergeCancel(t *testing.T) {
+	t.Run("second context cancels", func(t *testing.T) {
+		values := WithRequestID(context.Background(), "req-4")
+		other, cancelOther := context.WithCancel(context.Background())
+		merged, cancel := MergeCancel(values, other)
+		defer cancel()
+
+		if RequestIDFrom(merged) != "req-4" {
+			t.Error("MergeCancel() should keep the first context's values")
+		}
+		cancelOther()
+		select {
+		case <-merged.Done():
+		case <-time.After(time.Second):
+			t.Fatal("merged context not cancelled by the second context")
+		}
+	})
+
+	t.Run("first context cancels", func(t *testing.T) {
+		first, cancelFirst := context.WithCancel(context.Background())
+		merged, cancel := MergeCancel(first, context.Background())
+		defer cancel()
+
+		cancelFirst()
+		<-merged.Done()
+		if !errors.Is(merged.Err(), context.Canceled) {
+			t.Errorf("Err() = %v, want context.Canceled", merged.Err())
+		}
+	})
+
+	t.Run("earliest deadline wins", func(t *testing.T) {
+		late, cancelLate := context.WithTimeout(context.Background(), time.Hour)
+		defer cancelLate()
+		// Already expired, so the result does not depend on timer scheduling
+		early, cancelEarly := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancelEarly()
+
+		merged, cancel := MergeCancel(late, early)
+		defer cancel()
+
+		deadline, ok := merged.Deadline()
+		expected, _ := early.Deadline()
+		if !ok || !deadline.Equal(expected) {
+			t.Errorf("Deadline() = %v, want %v", deadline, expected)
+		}
+		<-merged.Done()
+		if !errors.Is(merged.Err(), context.DeadlineExceeded) {
+			t.Errorf("Err() = %v, want context.DeadlineExceeded", merged.Err())
+		}
+	})
+
+	t.Run("second context error is passed on", func(t *testing.T) {
+		expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+		defer cancelExpired()
+
+		// Hiding the deadline makes MergeCancel learn of the expiry only through Done
+		merged, cancel := MergeCancel(context.Background(), hiddenDeadline{expired})
+		defer cancel()
+
+		<-merged.Done()
+		if !errors.Is(merged.Err(), context.DeadlineExceeded) {
+			t.Errorf("Err() = %v, want context.DeadlineExceeded", merged.Err())
+		}
+	})
+
+	t.Run("cancel releases", func(t *testing.T) {
+		merged, cancel := MergeCancel(context.Background(), context.Background())
+		cancel()
+		if !errors.Is(merged.Err(), context.Canceled) {
+			t.Errorf("Err() = %v, want context.Canceled", merged.Err())
+		}
+	})
+}
+
+// hiddenDeadline is a context that does not report its deadline
+type hiddenDeadline struct{ context.Context }
+
+// Deadline implements context.Context
+func (hiddenDeadline) Deadline() (time.Time, bool) { return time.Time{}, false }
+
+// =================== Benchmarks ===================
+
+func BenchmarkKeyFrom(b *testing.B) {
+	key := NewKey[string]("bench")
+	ctx := key.With(context.Background(), "value")
+	for i := 0; i < b.N; i++ {
+		_, _ = key.From(ctx)
+	}
+}
@@ -0,0 +1,53 @@
+package contextutil
+
+import (
+	"context"
+	"fmt"
+)
+
+// Key is a typed context key; values stored under it can only be read back as T
+// Distinct keys never collide, even when created with the same name.
+type Key[T any] struct {
+	name *string
+}
+
+// NewKey creates a typed context key; name is only used in error messages and String
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{name: &name}
+}
+
+// String returns the key's name
+func (k Key[T]) String() string {
+	if k.name == nil {
+		return "<unnamed>"
+	}
+	return *k.name
+}
+
+// With returns a copy of ctx carrying value under k
+func (k Key[T]) With(ctx context.Context, value T) context.Context {
+	return context.WithValue(ctx, k, value)
+}
+
+// From returns the value stored under k and whether it was present
+func (k Key[T]) From(ctx context.Context) (T, bool) {
+	var zero T
+	if ctx == nil {
+		return zero, false
+	}
+	value, ok := ctx.Value(k).(T)
+	if !ok {
+		return zero, false
+	}
+	return value, true
+}
+
+// MustFrom returns the value stored under k, panicking if it is absent
+// Use it only where a missing value is a programming error (e.g. after required middleware).
+func (k Key[T]) MustFrom(ctx context.Context) T {
+	value, ok := k.From(ctx)
+	if !ok {
+		panic(fmt.Sprintf("contextutil: no value for key %s", k))
+	}
+	return value
+}
@@ -0,0 +1,81 @@
+package cryptoutil
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+)
+
+// RedactedValue is what a Secret prints, marshals and logs as
+const RedactedValue = "[REDACTED]"
+
+// Secret wraps a sensitive string such as a password, API key or token so it cannot leak by accident
+// Every fmt verb, JSON and text marshaling (and so logrus, zap and slog fields) produce RedactedValue;
+// only Reveal returns the value. JSON, YAML and text unmarshaling read the plain value, so Secret fields
+// can be loaded from config files, environment variables and flags.
+type Secret struct {
+	value string
+}
+
+// NewSecret wraps value in a Secret
+func NewSecret(value string) Secret {
+	return Secret{value: value}
+}
+
+// Reveal returns the wrapped value; call it only where the value is actually used
+func (s Secret) Reveal() string {
+	return s.value
+}
+
+// IsZero reports whether the secret is empty
+func (s Secret) IsZero() bool {
+	return s.value == ""
+}
+
+// Equal compares two secrets in constant time
+func (s Secret) Equal(other Secret) bool {
+	return subtle.ConstantTimeCompare([]byte(s.value), []byte(other.value)) == 1
+}
+
+// String implements fmt.Stringer
+func (s Secret) String() string {
+	return RedactedValue
+}
+
+// Format implements fmt.Formatter so that no verb, including %d and %#v, prints the value
+func (s Secret) Format(f fmt.State, verb rune) {
+	if verb == 'q' {
+		fmt.Fprintf(f, "%q", RedactedValue)
+		return
+	}
+	fmt.Fprint(f, RedactedValue)
+}
+
+// MarshalJSON implements json.Marshaler
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(RedactedValue)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, reading a JSON string (null leaves the secret empty)
+func (s *Secret) UnmarshalJSON(data []byte) error {
+	var value *string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("secret must be a JSON string: %w", err)
+	}
+	s.value = ""
+	if value != nil {
+		s.value = *value
+	}
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(RedactedValue), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Secret) UnmarshalText(text []byte) error {
+	s.value = string(text)
+	return nil
+}
@@ -0,0 +1,125 @@
+package cryptoutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/mustanish/common-utils/v2/logutil"
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v3"
+)
+
+// =================== Test Secret ===================
+
+func TestSecret_Redaction(t *testing.T) {
+	secret := NewSecret("hunter2")
+
+	tests := []struct {
+		name string
+		got  string
+	}{
+		{name: "%v", got: fmt.Sprintf("%v", secret)},
+		{name: "%+v", got: fmt.Sprintf("%+v", secret)},
+		{name: "%#v", got: fmt.Sprintf("%#v", secret)},
+		{name: "%s", got: fmt.Sprintf("%s", secret)},
+		{name: "%q", got: fmt.Sprintf("%q", secret)},
+		{name: "%x", got: fmt.Sprintf("%x", secret)},
+		{name: "%d", got: fmt.Sprintf("%d", secret)},
+		{name: "nested struct", got: fmt.Sprintf("%+v", struct{ Password Secret }{secret})},
+		{name: "pointer in struct", got: fmt.Sprintf("%+v", struct{ Password *Secret }{&secret})},
+		{name: "String", got: secret.String()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if strings.Contains(tt.got, "hunter2") || !strings.Contains(tt.got, RedactedValue) {
+				t.Errorf("formatted secret = %q, want %s", tt.got, RedactedValue)
+			}
+		})
+	}
+
+	if secret.Reveal() != "hunter2" {
+		t.Errorf("Reveal() = %q, want hunter2", secret.Reveal())
+	}
+}
+
+func TestSecret_Methods(t *testing.T) {
+	if !(Secret{}).IsZero() || NewSecret("x").IsZero() {
+		t.Error("IsZero() should only report empty secrets")
+	}
+	if !NewSecret("a").Equal(NewSecret("a")) || NewSecret("a").Equal(NewSecret("b")) {
+		t.Error("Equal() should compare the wrapped values")
+	}
+}
+
+func TestSecret_Encoding(t *testing.T) {
+	type credentials struct {
+		User     string `json:"user" yaml:"user"`
+		Password Secret `json:"password" yaml:"password"`
+	}
+
+	t.Run("json round trip", func(t *testing.T) {
+		var creds credentials
+		if err := json.Unmarshal([]byte(`{"user":"bob","password":"hunter2"}`), &creds); err != nil {
+			t.Fatalf("Unmarshal() unexpected error: %v", err)
+		}
+		if creds.Password.Reveal() != "hunter2" {
+			t.Errorf("Unmarshal() password = %q, want hunter2", creds.Password.Reveal())
+		}
+		data, _ := json.Marshal(creds)
+		if want := `{"user":"bob","password":"[REDACTED]"}`; string(data) != want {
+			t.Errorf("Marshal() = %s, want %s", data, want)
+		}
+	})
+
+	t.Run("json errors and null", func(t *testing.T) {
+		creds := credentials{Password: NewSecret("old")}
+		if err := json.Unmarshal([]byte(`{"password":null}`), &creds); err != nil || !creds.Password.IsZero() {
+			t.Errorf("Unmarshal(null) = %v, %v, want empty secret", creds.Password.Reveal(), err)
+		}
+		if err := json.Unmarshal([]byte(`{"password":42}`), &creds); err == nil {
+			t.Error("Unmarshal() of a number expected error")
+		}
+	})
+
+	t.Run("yaml", func(t *testing.T) {
+		var creds credentials
+		if err := yaml.Unmarshal([]byte("user: bob\npassword: hunter2\n"), &creds); err != nil {
+			t.Fatalf("yaml.Unmarshal() unexpected error: %v", err)
+		}
+		if creds.Password.Reveal() != "hunter2" {
+			t.Errorf("yaml.Unmarshal() password = %q, want hunter2", creds.Password.Reveal())
+		}
+		data, _ := yaml.Marshal(creds)
+		if strings.Contains(string(data), "hunter2") {
+			t.Errorf("yaml.Marshal() leaked the secret: %s", data)
+		}
+	})
+}
+
+func TestSecret_Logging(t *testing.T) {
+	secret := NewSecret("hunter2")
+
+	for _, formatter := range []logrus.Formatter{&logrus.TextFormatter{DisableColors: true}, &logrus.JSONFormatter{}} {
+		var buf bytes.Buffer
+		l := logrus.New()
+		l.SetOutput(&buf)
+		l.SetFormatter(formatter)
+		logutil.NewLogrusLogger(l).WithFields(logutil.Fields{"password": secret}).Info("login")
+		if out := buf.String(); strings.Contains(out, "hunter2") || !strings.Contains(out, RedactedValue) {
+			t.Errorf("logrus %T output = %q, want the secret redacted", formatter, out)
+		}
+	}
+
+	var buf bytes.Buffer
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(&buf), zapcore.DebugLevel)
+	logutil.NewZapLogger(zap.New(core)).WithField("password", secret).Info("login")
+	if out := buf.String(); strings.Contains(out, "hunter2") || !strings.Contains(out, RedactedValue) {
+		t.Errorf("zap output = %q, want the secret redacted", out)
+	}
+}
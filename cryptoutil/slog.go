@@ -0,0 +1,10 @@
+//go:build go1.21
+
+package cryptoutil
+
+import "log/slog"
+
+// LogValue implements slog.LogValuer
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(RedactedValue)
+}
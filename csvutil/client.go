@@ -0,0 +1,233 @@
+package csvutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/mustanish/common-utils/v2/collectionutil"
+)
+
+// utf8BOM is the byte order mark some tools (notably Excel) put at the start of CSV files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// CSVConfig holds configuration for reading and writing CSV
+type CSVConfig struct {
+	Delimiter rune
+	Comment   rune // lines starting with this rune are ignored when reading (0 disables)
+	WriteBOM  bool // prefix written output with a UTF-8 BOM for spreadsheet compatibility
+	TrimSpace bool // trim surrounding whitespace from headers and values when reading
+}
+
+// DefaultCSVConfig returns default configuration
+func DefaultCSVConfig() *CSVConfig {
+	return &CSVConfig{
+		Delimiter: ',',
+		Comment:   0,
+		WriteBOM:  false,
+		TrimSpace: false,
+	}
+}
+
+// CSVClient defines the interface for CSV reading and writing
+type CSVClient interface {
+	// Map-based IO
+	ReadCSV(r io.Reader) ([]map[string]string, error)
+	StreamCSV(r io.Reader, fn func(row Row) error) error
+	WriteCSV(w io.Writer, rows []map[string]string, headers []string) error
+	TransformCSV(r io.Reader, w io.Writer, opts TransformOptions) (TransformStats, error)
+
+	// Struct-based IO using `csv` tags
+	ReadStructs(r io.Reader, out any) error
+	WriteStructs(w io.Writer, rows any) error
+}
+
+// CSVUtil reads and writes header-based CSV
+type CSVUtil struct {
+	config     CSVConfig
+	collection collectionutil.CollectionClient
+}
+
+// NewCSVUtil creates a new CSV utility instance
+// Pass nil for config to use all defaults, or pass config with only the properties you want to override
+func NewCSVUtil(config *CSVConfig) CSVClient {
+	defaults := DefaultCSVConfig()
+
+	if config != nil {
+		if config.Delimiter != 0 {
+			defaults.Delimiter = config.Delimiter
+		}
+		if config.Comment != 0 {
+			defaults.Comment = config.Comment
+		}
+		if config.WriteBOM {
+			defaults.WriteBOM = true
+		}
+		if config.TrimSpace {
+			defaults.TrimSpace = true
+		}
+	}
+
+	return &CSVUtil{config: *defaults, collection: collectionutil.NewCollectionUtil()}
+}
+
+// ReadCSV reads all rows keyed by the header line
+func (c *CSVUtil) ReadCSV(r io.Reader) ([]map[string]string, error) {
+	var rows []map[string]string
+	err := c.StreamCSV(r, func(row Row) error {
+		rows = append(rows, row.Values)
+		return nil
+	})
+	return rows, err
+}
+
+// StreamCSV calls fn for each row after the header without loading the whole file
+// Returning an error from fn stops reading; it is returned wrapped with the row's line number.
+func (c *CSVUtil) StreamCSV(r io.Reader, fn func(row Row) error) error {
+	return c.stream(r, nil, fn)
+}
+
+// stream reads the header, passing it to onHeader when set, and then calls fn for each row
+func (c *CSVUtil) stream(r io.Reader, onHeader func(headers []string) error, fn func(row Row) error) error {
+	reader := c.newReader(r)
+
+	record, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil // empty input has no rows
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	headers, err := c.headers(record)
+	if err != nil {
+		return err
+	}
+	if onHeader != nil {
+		if err := onHeader(headers); err != nil {
+			return err
+		}
+	}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read CSV row: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		values := make(map[string]string, len(headers))
+		for i, header := range headers {
+			values[header] = c.clean(record[i])
+		}
+		if err := fn(Row{Line: line, Values: values, collection: c.collection}); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+}
+
+// WriteCSV writes rows under the given headers, or under their sorted keys when headers is nil
+// Keys missing from a row are written as empty values.
+func (c *CSVUtil) WriteCSV(w io.Writer, rows []map[string]string, headers []string) error {
+	if headers == nil {
+		headers = unionKeys(rows)
+	}
+
+	records := make([][]string, 0, len(rows)+1)
+	records = append(records, headers)
+	for _, row := range rows {
+		record := make([]string, len(headers))
+		for i, header := range headers {
+			record[i] = row[header]
+		}
+		records = append(records, record)
+	}
+	return c.writeAll(w, records)
+}
+
+// newReader configures an encoding/csv reader, skipping a leading UTF-8 BOM
+func (c *CSVUtil) newReader(r io.Reader) *csv.Reader {
+	buffered := bufio.NewReader(r)
+	if prefix, err := buffered.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+		_, _ = buffered.Discard(len(utf8BOM))
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = c.config.Delimiter
+	reader.Comment = c.config.Comment
+	reader.ReuseRecord = true
+	return reader
+}
+
+// headers validates the header record and returns the cleaned column names
+func (c *CSVUtil) headers(record []string) ([]string, error) {
+	headers := make([]string, len(record))
+	seen := make(map[string]bool, len(record))
+	for i, name := range record {
+		name = c.clean(name)
+		if name == "" {
+			return nil, fmt.Errorf("CSV header column %d is empty", i+1)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate CSV header %q", name)
+		}
+		seen[name] = true
+		headers[i] = name
+	}
+	return headers, nil
+}
+
+// clean applies the configured whitespace trimming
+func (c *CSVUtil) clean(value string) string {
+	if c.config.TrimSpace {
+		return strings.TrimSpace(value)
+	}
+	return value
+}
+
+// writeAll writes records with the configured delimiter and optional BOM
+func (c *CSVUtil) writeAll(w io.Writer, records [][]string) error {
+	writer, err := c.newWriter(w)
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// newWriter writes the optional BOM and configures an encoding/csv writer
+func (c *CSVUtil) newWriter(w io.Writer) (*csv.Writer, error) {
+	if c.config.WriteBOM {
+		if _, err := w.Write(utf8BOM); err != nil {
+			return nil, fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = c.config.Delimiter
+	return writer, nil
+}
+
+// unionKeys returns every key used across rows in sorted order
+func unionKeys(rows []map[string]string) []string {
+	seen := map[string]bool{}
+	for _, row := range rows {
+		for k := range row {
+			seen[k] = true
+		}
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,382 @@
+package csvutil
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type person struct {
+	Name     string        `csv:"name"`
+	Age      int           `csv:"age"`
+	Score    float64       `csv:"score"`
+	Active   bool          `csv:"active"`
+	Nickname *string       `csv:"nickname"`
+	Timeout  time.Duration `csv:"timeout"`
+	Joined   time.Time     `csv:"joined"`
+	Secret   string        `csv:"-"`
+}
+
+func TestNewCSVUtil(t *testing.T) {
+	util := NewCSVUtil(nil)
+	if util == nil {
+		t.Fatal("NewCSVUtil() returned nil")
+	}
+	if got := util.(*CSVUtil).config.Delimiter; got != ',' {
+		t.Errorf("default Delimiter = %q, want ','", got)
+	}
+
+	custom := NewCSVUtil(&CSVConfig{Delimiter: ';', WriteBOM: true}).(*CSVUtil)
+	if custom.config.Delimiter != ';' || !custom.config.WriteBOM {
+		t.Errorf("custom config = %+v, want ';' delimiter with BOM", custom.config)
+	}
+}
+
+// =================== Test Map IO ===================
+
+func TestReadCSV(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *CSVConfig
+		input     string
+		expected  []map[string]string
+		expectErr bool
+	}{
+		{
+			name:     "basic",
+			input:    "name,age\nalice,30\nbob,25\n",
+			expected: []map[string]string{{"name": "alice", "age": "30"}, {"name": "bob", "age": "25"}},
+		},
+		{
+			name:     "bom and whitespace",
+			config:   &CSVConfig{TrimSpace: true},
+			input:    "\xEF\xBB\xBF name , age \n alice , 30 \n",
+			expected: []map[string]string{{"name": "alice", "age": "30"}},
+		},
+		{
+			name:     "semicolon delimiter",
+			config:   &CSVConfig{Delimiter: ';'},
+			input:    "a;b\n1;\"x;y\"\n",
+			expected: []map[string]string{{"a": "1", "b": "x;y"}},
+		},
+		{
+			name:     "comments",
+			config:   &CSVConfig{Comment: '#'},
+			input:    "a\n# skipped\n1\n",
+			expected: []map[string]string{{"a": "1"}},
+		},
+		{name: "empty input", input: "", expected: nil},
+		{name: "header only", input: "a,b\n", expected: nil},
+		{name: "duplicate header", input: "a,a\n1,2\n", expectErr: true},
+		{name: "empty header", input: "a,\n1,2\n", expectErr: true},
+		{name: "ragged row", input: "a,b\n1\n", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := NewCSVUtil(tt.config).ReadCSV(strings.NewReader(tt.input))
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ReadCSV() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if !tt.expectErr && !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("ReadCSV() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestStreamCSV(t *testing.T) {
+	util := NewCSVUtil(nil)
+	input := "id,qty,price,ok\n1,2,9.5,yes\n2,x,1,no\n"
+
+	var lines []int
+	err := util.StreamCSV(strings.NewReader(input), func(row Row) error {
+		lines = append(lines, row.Line)
+		if _, err := row.Int("id"); err != nil {
+			t.Errorf("Row.Int(id) error = %v", err)
+		}
+		if _, err := row.Int64("qty"); err != nil {
+			return err
+		}
+		if price, err := row.Float64("price"); err != nil || price != 9.5 {
+			t.Errorf("Row.Float64(price) = %v, %v; want 9.5, nil", price, err)
+		}
+		if ok, err := row.Bool("ok"); err != nil || !ok {
+			t.Errorf("Row.Bool(ok) = %v, %v; want true, nil", ok, err)
+		}
+		if _, err := row.Int("missing"); err == nil {
+			t.Error("Row.Int(missing) expected error")
+		}
+		return nil
+	})
+
+	if err == nil || !strings.Contains(err.Error(), "line 3") || !strings.Contains(err.Error(), `column "qty"`) {
+		t.Errorf("StreamCSV() error = %v, want line 3 qty conversion error", err)
+	}
+	if !reflect.DeepEqual(lines, []int{2, 3}) {
+		t.Errorf("StreamCSV() lines = %v, want [2 3]", lines)
+	}
+
+	stop := errors.New("stop")
+	count := 0
+	err = util.StreamCSV(strings.NewReader("a\n1\n2\n3\n"), func(Row) error {
+		count++
+		return stop
+	})
+	if !errors.Is(err, stop) || count != 1 {
+		t.Errorf("StreamCSV() = %v after %d rows, want stop after 1", err, count)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	rows := []map[string]string{{"b": "2", "a": "1"}, {"a": "x,y"}}
+
+	tests := []struct {
+		name     string
+		config   *CSVConfig
+		headers  []string
+		expected string
+	}{
+		{"sorted keys", nil, nil, "a,b\n1,2\n\"x,y\",\n"},
+		{"explicit headers", nil, []string{"b", "a"}, "b,a\n2,1\n,\"x,y\"\n"},
+		{"bom and delimiter", &CSVConfig{Delimiter: '\t', WriteBOM: true}, []string{"a"}, "\xEF\xBB\xBFa\n1\nx,y\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := NewCSVUtil(tt.config).WriteCSV(&buf, rows, tt.headers); err != nil {
+				t.Fatalf("WriteCSV() error = %v", err)
+			}
+			if buf.String() != tt.expected {
+				t.Errorf("WriteCSV() = %q, want %q", buf.String(), tt.expected)
+			}
+		})
+	}
+}
+
+// =================== Test Transform ===================
+
+func TestTransformCSV(t *testing.T) {
+	util := NewCSVUtil(nil)
+	input := "id,Full Name,qty,active,note\n007,Ann,3,yes,x\n8,Bob,0,no,y\n9,Cid,2.50,1,z\n"
+
+	var buf bytes.Buffer
+	stats, err := util.TransformCSV(strings.NewReader(input), &buf, TransformOptions{
+		Rename: map[string]string{"Full Name": "name"},
+		Types:  map[string]ColumnType{"id": ColumnInt, "qty": ColumnFloat64, "active": ColumnBool},
+		Steps: []Transform{
+			Filter(func(row Row) bool {
+				qty, _ := row.Float64("qty")
+				return qty > 0
+			}),
+			func(row *Row) (bool, error) {
+				delete(row.Values, "note")
+				row.Values["label"] = row.Values["name"] + "#" + row.Values["id"]
+				return true, nil
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("TransformCSV() error = %v", err)
+	}
+	want := "id,name,qty,active,label\n7,Ann,3,true,Ann#7\n9,Cid,2.5,true,Cid#9\n"
+	if buf.String() != want {
+		t.Errorf("TransformCSV() output = %q, want %q", buf.String(), want)
+	}
+	if stats.Read != 3 || stats.Written != 2 || stats.Dropped != 1 || stats.Bytes != int64(len(input)) {
+		t.Errorf("TransformCSV() stats = %+v, want 3 read, 2 written, 1 dropped, %d bytes", stats, len(input))
+	}
+}
+
+func TestTransformCSV_ColumnsAndProgress(t *testing.T) {
+	util := NewCSVUtil(&CSVConfig{Delimiter: ';'})
+	var input strings.Builder
+	input.WriteString("a;b;c\n")
+	for i := 0; i < 5; i++ {
+		input.WriteString("1;2;3\n")
+	}
+
+	var buf bytes.Buffer
+	var progress []int
+	stats, err := util.TransformCSV(strings.NewReader(input.String()), &buf, TransformOptions{
+		Columns:          []string{"c", "a"},
+		ProgressInterval: 2,
+		Progress: func(stats TransformStats) {
+			progress = append(progress, stats.Read)
+		},
+	})
+	if err != nil {
+		t.Fatalf("TransformCSV() error = %v", err)
+	}
+	if want := "c;a\n" + strings.Repeat("3;1\n", 5); buf.String() != want {
+		t.Errorf("TransformCSV() output = %q, want %q", buf.String(), want)
+	}
+	if !reflect.DeepEqual(progress, []int{2, 4, 5}) || stats.Written != 5 {
+		t.Errorf("Progress calls = %v with stats %+v, want [2 4 5]", progress, stats)
+	}
+
+	// Dropping every row still writes the header
+	buf.Reset()
+	_, err = util.TransformCSV(strings.NewReader(input.String()), &buf, TransformOptions{
+		Steps: []Transform{Filter(func(Row) bool { return false })},
+	})
+	if err != nil || buf.String() != "a;b;c\n" {
+		t.Errorf("TransformCSV(drop all) = %q, %v, want header only", buf.String(), err)
+	}
+
+	buf.Reset()
+	if stats, err := util.TransformCSV(strings.NewReader(""), &buf, TransformOptions{}); err != nil || buf.Len() != 0 || stats.Read != 0 {
+		t.Errorf("TransformCSV(empty) = %q, %+v, %v, want no output", buf.String(), stats, err)
+	}
+}
+
+func TestTransformCSV_Errors(t *testing.T) {
+	util := NewCSVUtil(nil)
+	stop := errors.New("stop")
+
+	tests := []struct {
+		name  string
+		input string
+		opts  TransformOptions
+		want  string
+	}{
+		{"coercion", "n\n1\nabc\n", TransformOptions{Types: map[string]ColumnType{"n": ColumnInt}}, `line 3: column "n"`},
+		{"rename collision", "a,b\n1,2\n", TransformOptions{Rename: map[string]string{"a": "b"}}, `duplicate column "b"`},
+		{"step error", "a\n1\n", TransformOptions{Steps: []Transform{func(*Row) (bool, error) { return false, stop }}}, "line 2: stop"},
+		{"unexpected column", "a\n1\n2\n", TransformOptions{Steps: []Transform{func(row *Row) (bool, error) {
+			if row.Line == 3 {
+				row.Values["extra"] = "x"
+			}
+			return true, nil
+		}}}, `line 3: column "extra"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := util.TransformCSV(strings.NewReader(tt.input), &bytes.Buffer{}, tt.opts)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("TransformCSV() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// =================== Test Struct IO ===================
+
+func TestReadStructs(t *testing.T) {
+	util := NewCSVUtil(nil)
+	input := "name,age,score,active,nickname,timeout,joined,extra\n" +
+		"alice,30,9.5,yes,ally,1m30s,2024-01-02T03:04:05Z,ignored\n" +
+		"bob,25,,0,,,,\n"
+
+	var people []person
+	if err := util.ReadStructs(strings.NewReader(input), &people); err != nil {
+		t.Fatalf("ReadStructs() error = %v", err)
+	}
+	if len(people) != 2 {
+		t.Fatalf("ReadStructs() len = %d, want 2", len(people))
+	}
+
+	alice := people[0]
+	if alice.Name != "alice" || alice.Age != 30 || alice.Score != 9.5 || !alice.Active ||
+		alice.Nickname == nil || *alice.Nickname != "ally" || alice.Timeout != 90*time.Second ||
+		!alice.Joined.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("ReadStructs() first row = %+v", alice)
+	}
+	if bob := people[1]; bob.Nickname != nil || bob.Active || bob.Score != 0 {
+		t.Errorf("ReadStructs() second row = %+v, want zero values for empty cells", bob)
+	}
+
+	var pointers []*person
+	if err := util.ReadStructs(strings.NewReader("name\ncarol\n"), &pointers); err != nil || pointers[0].Name != "carol" {
+		t.Errorf("ReadStructs() into []*person = %v, %v", pointers, err)
+	}
+}
+
+func TestReadStructsErrors(t *testing.T) {
+	util := NewCSVUtil(nil)
+
+	tests := []struct {
+		name  string
+		input string
+		out   any
+	}{
+		{"not a pointer", "name\na\n", []person{}},
+		{"not a slice of structs", "name\na\n", &[]string{}},
+		{"bad int", "age\nold\n", &[]person{}},
+		{"fractional int", "age\n1.5\n", &[]person{}},
+		{"int8 overflow", "small\n300\n", &[]struct {
+			Small int8 `csv:"small"`
+		}{}},
+		{"bad time", "joined\nyesterday\n", &[]person{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := util.ReadStructs(strings.NewReader(tt.input), tt.out); err == nil {
+				t.Error("ReadStructs() expected error")
+			}
+		})
+	}
+}
+
+func TestWriteStructs(t *testing.T) {
+	util := NewCSVUtil(nil)
+	nickname := "ally"
+	people := []person{{
+		Name:     "alice",
+		Age:      30,
+		Score:    9.5,
+		Active:   true,
+		Nickname: &nickname,
+		Timeout:  90 * time.Second,
+		Joined:   time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Secret:   "hidden",
+	}, {Name: "bob"}}
+
+	var buf bytes.Buffer
+	if err := util.WriteStructs(&buf, people); err != nil {
+		t.Fatalf("WriteStructs() error = %v", err)
+	}
+	expected := "name,age,score,active,nickname,timeout,joined\n" +
+		"alice,30,9.5,true,ally,1m30s,2024-01-02T03:04:05Z\n" +
+		"bob,0,0,false,,0s,0001-01-01T00:00:00Z\n"
+	if buf.String() != expected {
+		t.Errorf("WriteStructs() = %q, want %q", buf.String(), expected)
+	}
+
+	// Round trip
+	var decoded []person
+	if err := util.ReadStructs(&buf, &decoded); err != nil {
+		t.Fatalf("ReadStructs() round trip error = %v", err)
+	}
+	people[0].Secret = ""
+	if !reflect.DeepEqual(decoded[0], people[0]) {
+		t.Errorf("round trip = %+v, want %+v", decoded[0], people[0])
+	}
+
+	if err := util.WriteStructs(&buf, "nope"); err == nil {
+		t.Error("WriteStructs() with non-slice expected error")
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkStreamCSV(b *testing.B) {
+	util := NewCSVUtil(nil)
+	var sb strings.Builder
+	sb.WriteString("id,name,score\n")
+	for i := 0; i < 1000; i++ {
+		sb.WriteString("1,alice,9.5\n")
+	}
+	input := sb.String()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = util.StreamCSV(strings.NewReader(input), func(Row) error { return nil })
+	}
+}
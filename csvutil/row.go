@@ -0,0 +1,82 @@
+package csvutil
+
+import (
+	"fmt"
+
+	"github.com/mustanish/common-utils/v2/collectionutil"
+)
+
+// Row is a single CSV record keyed by header, with typed accessors
+type Row struct {
+	Line   int
+	Values map[string]string
+
+	collection collectionutil.CollectionClient
+}
+
+// Get returns the value of a column and whether the column exists
+func (r Row) Get(column string) (string, bool) {
+	value, ok := r.Values[column]
+	return value, ok
+}
+
+// Int converts a column to int using collectionutil's converters
+func (r Row) Int(column string) (int, error) {
+	value, err := r.lookup(column)
+	if err != nil {
+		return 0, err
+	}
+	converted, err := r.collection.ConvertToInteger(value)
+	if err != nil {
+		return 0, fmt.Errorf("column %q: %w", column, err)
+	}
+	return converted, nil
+}
+
+// Int64 converts a column to int64 using collectionutil's converters
+func (r Row) Int64(column string) (int64, error) {
+	value, err := r.lookup(column)
+	if err != nil {
+		return 0, err
+	}
+	converted, err := r.collection.ConvertToInt64(value)
+	if err != nil {
+		return 0, fmt.Errorf("column %q: %w", column, err)
+	}
+	return converted, nil
+}
+
+// Float64 converts a column to float64 using collectionutil's converters
+func (r Row) Float64(column string) (float64, error) {
+	value, err := r.lookup(column)
+	if err != nil {
+		return 0, err
+	}
+	converted, err := r.collection.ConvertToFloat64(value)
+	if err != nil {
+		return 0, fmt.Errorf("column %q: %w", column, err)
+	}
+	return converted, nil
+}
+
+// Bool converts a column to bool using collectionutil's converters ("yes", "1", "true", ...)
+func (r Row) Bool(column string) (bool, error) {
+	value, err := r.lookup(column)
+	if err != nil {
+		return false, err
+	}
+	converted, err := r.collection.ConvertToBool(value)
+	if err != nil {
+		return false, fmt.Errorf("column %q: %w", column, err)
+	}
+	return converted, nil
+}
+
+// lookup returns a column value or an error naming the missing column
+func (r Row) lookup(column string) (string, error) {
+	value, ok := r.Values[column]
+	if !ok {
+		return "", fmt.Errorf("column %q not found", column)
+	}
+	return value, nil
+}
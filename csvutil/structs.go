@@ -0,0 +1,247 @@
+package csvutil
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+)
+
+// column maps a CSV header to a struct field
+type column struct {
+	name  string
+	index int
+}
+
+// columnCache caches resolved columns per struct type
+var columnCache sync.Map // map[reflect.Type][]column
+
+// columnsOf returns the CSV columns for a struct type in field order
+// Fields are named by their `csv` tag, falling back to the field name; `csv:"-"` skips a field.
+func columnsOf(t reflect.Type) []column {
+	if cached, ok := columnCache.Load(t); ok {
+		return cached.([]column)
+	}
+	var columns []column
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Tag.Get("csv")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		columns = append(columns, column{name: name, index: i})
+	}
+	actual, _ := columnCache.LoadOrStore(t, columns)
+	return actual.([]column)
+}
+
+// ReadStructs decodes every row into out, which must be a pointer to a slice of structs
+// Columns without a matching field are ignored; fields without a matching column keep their zero value.
+func (c *CSVUtil) ReadStructs(r io.Reader, out any) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("out must be a non-nil pointer to a slice of structs, got %T", out)
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("out must be a non-nil pointer to a slice of structs, got %T", out)
+	}
+	columns := columnsOf(elemType)
+
+	decoded := reflect.MakeSlice(slice.Type(), 0, 0)
+	err := c.StreamCSV(r, func(row Row) error {
+		elem := reflect.New(elemType).Elem()
+		for _, col := range columns {
+			value, ok := row.Values[col.name]
+			if !ok {
+				continue
+			}
+			if err := c.setField(elem.Field(col.index), value); err != nil {
+				return fmt.Errorf("column %q: %w", col.name, err)
+			}
+		}
+		if isPtr {
+			elem = elem.Addr()
+		}
+		decoded = reflect.Append(decoded, elem)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	slice.Set(decoded)
+	return nil
+}
+
+// WriteStructs writes a slice of structs (or struct pointers) with a header derived from their `csv` tags
+func (c *CSVUtil) WriteStructs(w io.Writer, rows any) error {
+	rv := reflect.ValueOf(rows)
+	if rv.Kind() != reflect.Slice {
+		return fmt.Errorf("rows must be a slice of structs, got %T", rows)
+	}
+	elemType := rv.Type().Elem()
+	if elemType.Kind() == reflect.Ptr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return fmt.Errorf("rows must be a slice of structs, got %T", rows)
+	}
+	columns := columnsOf(elemType)
+
+	records := make([][]string, 0, rv.Len()+1)
+	header := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = col.name
+	}
+	records = append(records, header)
+
+	for i := 0; i < rv.Len(); i++ {
+		elem := rv.Index(i)
+		record := make([]string, len(columns))
+		if elem.Kind() == reflect.Ptr {
+			if elem.IsNil() {
+				records = append(records, record)
+				continue
+			}
+			elem = elem.Elem()
+		}
+		for j, col := range columns {
+			value, err := c.formatField(elem.Field(col.index))
+			if err != nil {
+				return fmt.Errorf("row %d column %q: %w", i+1, col.name, err)
+			}
+			record[j] = value
+		}
+		records = append(records, record)
+	}
+	return c.writeAll(w, records)
+}
+
+// setField converts a CSV value into the field's type using collectionutil's converters
+// Empty values leave the field at its zero value.
+func (c *CSVUtil) setField(field reflect.Value, value string) error {
+	if value == "" {
+		return nil
+	}
+	if field.Kind() == reflect.Ptr {
+		target := reflect.New(field.Type().Elem())
+		if err := c.setField(target.Elem(), value); err != nil {
+			return err
+		}
+		field.Set(target)
+		return nil
+	}
+	if reflect.PtrTo(field.Type()).Implements(textUnmarshalerType) {
+		return field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value))
+	}
+
+	switch {
+	case field.Type() == durationType:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration: %w", err)
+		}
+		field.SetInt(int64(d))
+		return nil
+	case field.Kind() == reflect.String:
+		field.SetString(value)
+		return nil
+	case field.Kind() == reflect.Bool:
+		b, err := c.collection.ConvertToBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := c.collection.ConvertToInt64(value)
+		if err != nil {
+			return err
+		}
+		if field.OverflowInt(n) {
+			return fmt.Errorf("value %d overflows %s", n, field.Type())
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("cannot convert %q to unsigned integer: %w", value, err)
+		}
+		if field.OverflowUint(n) {
+			return fmt.Errorf("value %d overflows %s", n, field.Type())
+		}
+		field.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := c.collection.ConvertToFloat64(value)
+		if err != nil {
+			return err
+		}
+		if field.OverflowFloat(f) {
+			return fmt.Errorf("value %v overflows %s", f, field.Type())
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return nil
+}
+
+// formatField renders a field value as a CSV string; nil pointers become empty values
+func (c *CSVUtil) formatField(field reflect.Value) (string, error) {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return "", nil
+		}
+		field = field.Elem()
+	}
+	if field.Type().Implements(textMarshalerType) {
+		text, err := field.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if field.CanAddr() && reflect.PtrTo(field.Type()).Implements(textMarshalerType) {
+		text, err := field.Addr().Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	if field.Type() == durationType {
+		return time.Duration(field.Int()).String(), nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		return field.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(field.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'f', -1, 64), nil
+	}
+	return "", fmt.Errorf("unsupported field type %s", field.Type())
+}
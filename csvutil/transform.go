@@ -0,0 +1,292 @@
+package csvutil
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+)
+
+// ColumnType is a type TransformCSV coerces a column to with collectionutil's converters
+type ColumnType int
+
+const (
+	ColumnInt     ColumnType = iota + 1 // "007" becomes "7"
+	ColumnInt64                         // as ColumnInt, for values beyond int range on 32-bit platforms
+	ColumnFloat64                       // "1.50" becomes "1.5"
+	ColumnBool                          // "yes", "1" and "true" become "true"
+)
+
+// Transform changes a row in place, e.g. by editing row.Values, or drops it by returning false
+type Transform func(row *Row) (keep bool, err error)
+
+// Filter returns a Transform that keeps the rows for which keep returns true
+func Filter(keep func(row Row) bool) Transform {
+	return func(row *Row) (bool, error) {
+		return keep(*row), nil
+	}
+}
+
+// TransformOptions configures TransformCSV
+type TransformOptions struct {
+	// Rename maps input column names to output names, applied before Types and Steps
+	Rename map[string]string
+
+	// Types coerces columns (by output name) to a normalised form; empty values stay empty and an
+	// unconvertible value fails the transformation
+	Types map[string]ColumnType
+
+	// Steps run in order on every row; a step returning false drops the row
+	Steps []Transform
+
+	// Columns selects and orders the output columns; nil writes the renamed input columns still present in
+	// the first written row, followed by the columns Steps added to it, sorted
+	Columns []string
+
+	// Progress is called every ProgressInterval input rows and once when the input is exhausted
+	Progress func(stats TransformStats)
+
+	// ProgressInterval is how many input rows pass between Progress calls (default 1000)
+	ProgressInterval int
+}
+
+// TransformStats counts the rows processed by TransformCSV
+type TransformStats struct {
+	Read    int   // data rows read from the input
+	Written int   // rows written to the output
+	Dropped int   // rows dropped by a step
+	Bytes   int64 // input bytes consumed so far, e.g. to compare with the file size
+}
+
+// TransformCSV streams rows from r through renaming, type coercion and the given steps into w
+// Only one row is held in memory at a time, so files of any size can be processed. Errors carry the
+// input line number; rows written before an error remain in w. Empty input produces no output.
+func (c *CSVUtil) TransformCSV(r io.Reader, w io.Writer, opts TransformOptions) (TransformStats, error) {
+	interval := opts.ProgressInterval
+	if interval <= 0 {
+		interval = 1000
+	}
+	counter := &countingReader{r: r}
+	t := &transformer{csv: c, opts: opts, columns: opts.Columns, fixed: opts.Columns != nil}
+
+	err := c.stream(counter, t.setHeaders, func(row Row) error {
+		t.stats.Read++
+		t.stats.Bytes = counter.n
+		if err := t.row(w, row); err != nil {
+			return err
+		}
+		if opts.Progress != nil && t.stats.Read%interval == 0 {
+			if err := t.flush(); err != nil {
+				return err
+			}
+			opts.Progress(t.stats)
+		}
+		return nil
+	})
+	if err == nil && t.headers != nil && t.writer == nil {
+		// Every row was dropped (or there were none): still write the header line
+		err = t.start(w, nil)
+	}
+	if err == nil {
+		err = t.flush()
+	}
+	t.stats.Bytes = counter.n
+	if err == nil && opts.Progress != nil {
+		opts.Progress(t.stats)
+	}
+	return t.stats, err
+}
+
+// transformer holds the state of one TransformCSV call
+type transformer struct {
+	csv  *CSVUtil
+	opts TransformOptions
+
+	headers []string // renamed input columns in input order
+	columns []string // output columns, known once the first row is written
+	known   map[string]bool
+	fixed   bool // columns were given by TransformOptions.Columns
+	writer  *csv.Writer
+	record  []string
+	stats   TransformStats
+}
+
+// setHeaders records the renamed input columns, rejecting renames that collide
+func (t *transformer) setHeaders(headers []string) error {
+	t.headers = make([]string, len(headers))
+	seen := make(map[string]bool, len(headers))
+	for i, name := range headers {
+		if renamed, ok := t.opts.Rename[name]; ok {
+			name = renamed
+		}
+		if seen[name] {
+			return fmt.Errorf("renaming CSV columns yields duplicate column %q", name)
+		}
+		seen[name] = true
+		t.headers[i] = name
+	}
+	return nil
+}
+
+// row renames, coerces and transforms one row and writes it unless a step drops it
+func (t *transformer) row(w io.Writer, row Row) error {
+	if len(t.opts.Rename) > 0 {
+		values := make(map[string]string, len(row.Values))
+		for name, value := range row.Values {
+			if renamed, ok := t.opts.Rename[name]; ok {
+				name = renamed
+			}
+			values[name] = value
+		}
+		row.Values = values
+	}
+	if err := coerce(row, t.opts.Types); err != nil {
+		return err
+	}
+	for _, step := range t.opts.Steps {
+		keep, err := step(&row)
+		if err != nil {
+			return err
+		}
+		if !keep {
+			t.stats.Dropped++
+			return nil
+		}
+	}
+
+	if t.writer == nil {
+		if err := t.start(w, row.Values); err != nil {
+			return err
+		}
+	}
+	if !t.fixed {
+		for name := range row.Values {
+			if !t.known[name] {
+				return fmt.Errorf("column %q is not among the output columns taken from the first written row; set TransformOptions.Columns", name)
+			}
+		}
+	}
+	for i, name := range t.columns {
+		t.record[i] = row.Values[name]
+	}
+	if err := t.writer.Write(t.record); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	t.stats.Written++
+	return nil
+}
+
+// start determines the output columns from the first written row and writes the header line
+func (t *transformer) start(w io.Writer, first map[string]string) error {
+	if !t.fixed {
+		t.columns = t.outputColumns(first)
+	}
+	writer, err := t.csv.newWriter(w)
+	if err != nil {
+		return err
+	}
+	t.writer = writer
+	t.record = make([]string, len(t.columns))
+	t.known = make(map[string]bool, len(t.columns))
+	for _, name := range t.columns {
+		t.known[name] = true
+	}
+	if err := t.writer.Write(t.columns); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// outputColumns lists the renamed input columns present in first, then the added ones sorted
+// A nil first row (nothing written) keeps every input column.
+func (t *transformer) outputColumns(first map[string]string) []string {
+	if first == nil {
+		return t.headers
+	}
+	columns := make([]string, 0, len(first))
+	for _, name := range t.headers {
+		if _, ok := first[name]; ok {
+			columns = append(columns, name)
+		}
+	}
+	var added []string
+	for name := range first {
+		if !contains(t.headers, name) {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(added)
+	return append(columns, added...)
+}
+
+// flush writes buffered rows to the output
+func (t *transformer) flush() error {
+	if t.writer == nil {
+		return nil
+	}
+	t.writer.Flush()
+	if err := t.writer.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
+
+// coerce normalises the typed columns of row through Row's collectionutil-backed accessors
+func coerce(row Row, types map[string]ColumnType) error {
+	for name, columnType := range types {
+		value, ok := row.Values[name]
+		if !ok || value == "" {
+			continue
+		}
+		var err error
+		switch columnType {
+		case ColumnInt:
+			var v int
+			v, err = row.Int(name)
+			value = strconv.Itoa(v)
+		case ColumnInt64:
+			var v int64
+			v, err = row.Int64(name)
+			value = strconv.FormatInt(v, 10)
+		case ColumnFloat64:
+			var v float64
+			v, err = row.Float64(name)
+			value = strconv.FormatFloat(v, 'f', -1, 64)
+		case ColumnBool:
+			var v bool
+			v, err = row.Bool(name)
+			value = strconv.FormatBool(v)
+		default:
+			return fmt.Errorf("column %q: unknown column type %d", name, columnType)
+		}
+		if err != nil {
+			return err
+		}
+		row.Values[name] = value
+	}
+	return nil
+}
+
+// contains reports whether names includes name
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// countingReader counts the bytes read from r for progress reporting
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read implements io.Reader for countingReader
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
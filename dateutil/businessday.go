@@ -0,0 +1,111 @@
+package dateutil
+
+import (
+	"fmt"
+	"time"
+)
+
+// BusinessDayConvention decides how a date falling on a weekend or holiday is moved to a business day
+type BusinessDayConvention int
+
+const (
+	Unadjusted        BusinessDayConvention = iota // keep the date as is
+	Following                                      // next business day
+	ModifiedFollowing                              // next business day, unless that is in the next month, then the previous one
+	Preceding                                      // previous business day
+	ModifiedPreceding                              // previous business day, unless that is in the previous month, then the next one
+)
+
+// String returns the convention name
+func (c BusinessDayConvention) String() string {
+	switch c {
+	case Unadjusted:
+		return "Unadjusted"
+	case Following:
+		return "Following"
+	case ModifiedFollowing:
+		return "ModifiedFollowing"
+	case Preceding:
+		return "Preceding"
+	case ModifiedPreceding:
+		return "ModifiedPreceding"
+	}
+	return fmt.Sprintf("BusinessDayConvention(%d)", int(c))
+}
+
+// HolidayCalendar reports non-working days in addition to weekends
+type HolidayCalendar interface {
+	IsHoliday(date time.Time) bool
+}
+
+// HolidayFunc adapts a function to HolidayCalendar
+type HolidayFunc func(date time.Time) bool
+
+// IsHoliday implements HolidayCalendar
+func (f HolidayFunc) IsHoliday(date time.Time) bool {
+	return f(date)
+}
+
+// Holidays is a HolidayCalendar of fixed calendar dates, compared by year, month and day
+type Holidays map[string]struct{}
+
+// NewHolidays creates a calendar from the given dates; their time of day and location are ignored
+func NewHolidays(dates ...time.Time) Holidays {
+	h := make(Holidays, len(dates))
+	for _, date := range dates {
+		h[date.Format(RFC3339Date)] = struct{}{}
+	}
+	return h
+}
+
+// IsHoliday implements HolidayCalendar
+func (h Holidays) IsHoliday(date time.Time) bool {
+	_, ok := h[date.Format(RFC3339Date)]
+	return ok
+}
+
+// maxAdjustmentDays bounds the search for a business day so a calendar without any cannot loop forever
+const maxAdjustmentDays = 366
+
+// AdjustToBusinessDay moves date to a business day according to convention
+// Business days are weekdays that holidays (which may be nil) does not report as holidays. A date that
+// already is a business day, the Unadjusted convention and unknown conventions return date unchanged;
+// the time of day is preserved.
+func (d *DateUtil) AdjustToBusinessDay(date time.Time, convention BusinessDayConvention, holidays HolidayCalendar) time.Time {
+	if d.isBusinessDay(date, holidays) {
+		return date
+	}
+	switch convention {
+	case Following:
+		return d.rollBusinessDay(date, 1, holidays)
+	case Preceding:
+		return d.rollBusinessDay(date, -1, holidays)
+	case ModifiedFollowing:
+		if next := d.rollBusinessDay(date, 1, holidays); next.Month() == date.Month() {
+			return next
+		}
+		return d.rollBusinessDay(date, -1, holidays)
+	case ModifiedPreceding:
+		if prev := d.rollBusinessDay(date, -1, holidays); prev.Month() == date.Month() {
+			return prev
+		}
+		return d.rollBusinessDay(date, 1, holidays)
+	}
+	return date
+}
+
+// isBusinessDay reports whether date is a weekday that is not a holiday
+func (d *DateUtil) isBusinessDay(date time.Time, holidays HolidayCalendar) bool {
+	return d.IsBusinessDay(date) && (holidays == nil || !holidays.IsHoliday(date))
+}
+
+// rollBusinessDay steps one day at a time in direction (+1 or -1) until it reaches a business day
+func (d *DateUtil) rollBusinessDay(date time.Time, direction int, holidays HolidayCalendar) time.Time {
+	for i := 1; i <= maxAdjustmentDays; i++ {
+		candidate := d.AddDays(date, i*direction)
+		if d.isBusinessDay(candidate, holidays) {
+			return candidate
+		}
+	}
+	return date
+}
@@ -1,6 +1,7 @@
 package dateutil
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"time"
@@ -65,17 +66,70 @@ type DateClient interface {
 	GetDaysInMonth(year, month int) int
 	IsBusinessDay(date time.Time) bool
 	NextBusinessDay(date time.Time) time.Time
+	AdjustToBusinessDay(date time.Time, convention BusinessDayConvention, holidays HolidayCalendar) time.Time
+
+	// Date ranges
+	MergeRanges(ranges []DateRange) []DateRange
+	FindGaps(ranges []DateRange, within DateRange) []DateRange
+	TotalCoverage(ranges []DateRange) time.Duration
 
 	// Essential formats
 	GetCommonFormats() []string
+
+	// Scheduling
+	ParseCron(expr string) (*CronSchedule, error)
+	NextOccurrenceOfTime(clock string, loc *time.Location, after time.Time) (time.Time, error)
+
+	// ISO 8601 durations
+	ParseISODuration(s string) (ISODuration, error)
+	FormatISODuration(years, months, days int, d time.Duration) string
+
+	// Context deadlines
+	ContextWithDeadlineAt(ctx context.Context, t time.Time) (context.Context, context.CancelFunc)
+	DeadlineRemaining(ctx context.Context) (time.Duration, bool)
+	UntilEndOfDay(loc *time.Location) time.Duration
+	ContextUntilEndOfDay(ctx context.Context, loc *time.Location) (context.Context, context.CancelFunc)
 }
 
+// Clock is the source of the current time used by the current time helpers
+// Tests can substitute a fake implementation such as testutil.FakeClock.
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock reads the wall clock
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// SystemClock is the Clock backed by time.Now
+var SystemClock Clock = systemClock{}
+
 // DateUtil provides comprehensive date utility operations
-type DateUtil struct{}
+type DateUtil struct {
+	clock Clock
+}
 
 // NewDateUtil creates a new instance of DateUtil
 func NewDateUtil() DateClient {
-	return &DateUtil{}
+	return &DateUtil{clock: SystemClock}
+}
+
+// NewDateUtilWithClock creates a DateUtil whose current time helpers read from clock
+// A nil clock falls back to SystemClock.
+func NewDateUtilWithClock(clock Clock) DateClient {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return &DateUtil{clock: clock}
+}
+
+// now returns the current time from the configured clock
+func (d *DateUtil) now() time.Time {
+	if d.clock == nil {
+		return time.Now()
+	}
+	return d.clock.Now()
 }
 
 // Parse attempts to parse a date string using the provided formats or common formats
@@ -258,37 +312,37 @@ func (d *DateUtil) NextBusinessDay(date time.Time) time.Time {
 
 // Now returns the current time
 func (d *DateUtil) Now() time.Time {
-	return time.Now()
+	return d.now()
 }
 
 // NowUTC returns the current time in UTC
 func (d *DateUtil) NowUTC() time.Time {
-	return time.Now().UTC()
+	return d.now().UTC()
 }
 
 // Today returns today's date at 00:00:00
 func (d *DateUtil) Today() time.Time {
-	return d.StartOfDay(time.Now())
+	return d.StartOfDay(d.now())
 }
 
 // Yesterday returns yesterday's date at 00:00:00
 func (d *DateUtil) Yesterday() time.Time {
-	return d.StartOfDay(d.AddDays(time.Now(), -1))
+	return d.StartOfDay(d.AddDays(d.now(), -1))
 }
 
 // Tomorrow returns tomorrow's date at 00:00:00
 func (d *DateUtil) Tomorrow() time.Time {
-	return d.StartOfDay(d.AddDays(time.Now(), 1))
+	return d.StartOfDay(d.AddDays(d.now(), 1))
 }
 
 // LastMonth returns the same day last month at 00:00:00
 func (d *DateUtil) LastMonth() time.Time {
-	return d.StartOfDay(d.AddMonths(time.Now(), -1))
+	return d.StartOfDay(d.AddMonths(d.now(), -1))
 }
 
 // NextMonth returns the same day next month at 00:00:00
 func (d *DateUtil) NextMonth() time.Time {
-	return d.StartOfDay(d.AddMonths(time.Now(), 1))
+	return d.StartOfDay(d.AddMonths(d.now(), 1))
 }
 
 // GetCommonFormats returns a list of commonly used date formats
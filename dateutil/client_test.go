@@ -1,8 +1,13 @@
 package dateutil
 
 import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
+	_ "time/tzdata" // DST tests must not depend on the host's zoneinfo
 )
 
 func TestNewDateUtil(t *testing.T) {
@@ -452,6 +457,41 @@ func TestCurrentTimeHelpers(t *testing.T) {
 	})
 }
 
+// fixedClock is a Clock that always reports the same instant
+type fixedClock struct{ t time.Time }
+
+func (c fixedClock) Now() time.Time { return c.t }
+
+func TestCurrentTimeHelpers_WithClock(t *testing.T) {
+	now := time.Date(2024, time.January, 31, 15, 4, 5, 0, time.UTC)
+	util := NewDateUtilWithClock(fixedClock{t: now})
+
+	tests := []struct {
+		name string
+		got  time.Time
+		want time.Time
+	}{
+		{"Now", util.Now(), now},
+		{"NowUTC", util.NowUTC(), now},
+		{"Today", util.Today(), time.Date(2024, time.January, 31, 0, 0, 0, 0, time.UTC)},
+		{"Yesterday", util.Yesterday(), time.Date(2024, time.January, 30, 0, 0, 0, 0, time.UTC)},
+		{"Tomorrow", util.Tomorrow(), time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"LastMonth", util.LastMonth(), time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.got.Equal(tt.want) {
+				t.Errorf("%s() = %v, want %v", tt.name, tt.got, tt.want)
+			}
+		})
+	}
+
+	if NewDateUtilWithClock(nil).Now().IsZero() {
+		t.Error("NewDateUtilWithClock(nil) should fall back to the system clock")
+	}
+}
+
 // =================== Test Utility Methods ===================
 
 func TestUtilityMethods(t *testing.T) {
@@ -532,6 +572,383 @@ func TestGetCommonFormats(t *testing.T) {
 	}
 }
 
+// =================== Test ParseCron ===================
+
+func TestParseCron_Next(t *testing.T) {
+	util := NewDateUtil()
+	from := time.Date(2023, 10, 5, 14, 30, 20, 0, time.UTC) // Thursday
+
+	tests := []struct {
+		name     string
+		expr     string
+		expected time.Time
+	}{
+		{"every minute", "* * * * *", time.Date(2023, 10, 5, 14, 31, 0, 0, time.UTC)},
+		{"every 15 minutes", "*/15 * * * *", time.Date(2023, 10, 5, 14, 45, 0, 0, time.UTC)},
+		{"daily at 09:00", "0 9 * * *", time.Date(2023, 10, 6, 9, 0, 0, 0, time.UTC)},
+		{"weekdays at 08:30", "30 8 * * 1-5", time.Date(2023, 10, 6, 8, 30, 0, 0, time.UTC)},
+		{"sunday as 7", "0 0 * * 7", time.Date(2023, 10, 8, 0, 0, 0, 0, time.UTC)},
+		{"list of hours", "0 6,18 * * *", time.Date(2023, 10, 5, 18, 0, 0, 0, time.UTC)},
+		{"first of month", "@monthly", time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC)},
+		{"yearly", "@yearly", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"day of month or weekday", "0 0 13 * 5", time.Date(2023, 10, 6, 0, 0, 0, 0, time.UTC)},
+		{"step with start", "5/20 * * * *", time.Date(2023, 10, 5, 14, 45, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := util.ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) unexpected error: %v", tt.expr, err)
+			}
+			if got := schedule.Next(from); !got.Equal(tt.expected) {
+				t.Errorf("Next() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	util := NewDateUtil()
+
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * * 13 *", "*/0 * * * *", "a * * * *", "5-1 * * * *"} {
+		if _, err := util.ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) expected error", expr)
+		}
+	}
+}
+
+func TestParseCron_NoMatch(t *testing.T) {
+	util := NewDateUtil()
+	schedule, err := util.ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("ParseCron() unexpected error: %v", err)
+	}
+	if next := schedule.Next(time.Now()); !next.IsZero() {
+		t.Errorf("Next() = %v, want zero time", next)
+	}
+}
+
+// =================== Test NextOccurrenceOfTime ===================
+
+func TestNextOccurrenceOfTime(t *testing.T) {
+	util := NewDateUtil()
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		clock string
+		loc   *time.Location
+		after time.Time
+		want  time.Time
+	}{
+		{
+			name:  "later today",
+			clock: "09:30",
+			loc:   ny,
+			after: time.Date(2024, time.June, 3, 8, 0, 0, 0, ny),
+			want:  time.Date(2024, time.June, 3, 9, 30, 0, 0, ny),
+		},
+		{
+			name:  "already passed rolls to tomorrow",
+			clock: "09:30",
+			loc:   ny,
+			after: time.Date(2024, time.June, 3, 9, 30, 0, 0, ny),
+			want:  time.Date(2024, time.June, 4, 9, 30, 0, 0, ny),
+		},
+		{
+			name:  "after given in another zone",
+			clock: "09:30:15",
+			loc:   ny,
+			after: time.Date(2024, time.June, 3, 12, 0, 0, 0, time.UTC), // 08:00 EDT
+			want:  time.Date(2024, time.June, 3, 13, 30, 15, 0, time.UTC),
+		},
+		{
+			name:  "skipped by spring forward moves past the gap",
+			clock: "02:30",
+			loc:   ny,
+			after: time.Date(2024, time.March, 10, 0, 0, 0, 0, ny),
+			want:  time.Date(2024, time.March, 10, 7, 30, 0, 0, time.UTC), // 03:30 EDT
+		},
+		{
+			name:  "repeated by fall back uses first occurrence",
+			clock: "01:30",
+			loc:   ny,
+			after: time.Date(2024, time.November, 3, 0, 0, 0, 0, ny),
+			want:  time.Date(2024, time.November, 3, 5, 30, 0, 0, time.UTC), // 01:30 EDT
+		},
+		{
+			name:  "repeated hour fires once per day",
+			clock: "01:30",
+			loc:   ny,
+			after: time.Date(2024, time.November, 3, 5, 45, 0, 0, time.UTC), // 01:45 EDT
+			want:  time.Date(2024, time.November, 4, 6, 30, 0, 0, time.UTC), // 01:30 EST next day
+		},
+		{
+			name:  "nil location uses after's location",
+			clock: "23:00",
+			after: time.Date(2024, time.January, 1, 23, 30, 0, 0, time.UTC),
+			want:  time.Date(2024, time.January, 2, 23, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := util.NextOccurrenceOfTime(tt.clock, tt.loc, tt.after)
+			if err != nil {
+				t.Fatalf("NextOccurrenceOfTime() error = %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("NextOccurrenceOfTime() = %v, want %v", got, tt.want.In(ny))
+			}
+		})
+	}
+
+	for _, clock := range []string{"", "9.30", "25:00", "09:60"} {
+		if _, err := util.NextOccurrenceOfTime(clock, ny, time.Now()); err == nil {
+			t.Errorf("NextOccurrenceOfTime(%q) should return error", clock)
+		}
+	}
+}
+
+// =================== Test ISO 8601 Durations ===================
+
+func TestParseISODuration(t *testing.T) {
+	util := NewDateUtil()
+
+	tests := []struct {
+		input   string
+		want    ISODuration
+		wantErr bool
+	}{
+		{"P1Y2M3DT4H", ISODuration{Years: 1, Months: 2, Days: 3, Duration: 4 * time.Hour}, false},
+		{"P1M", ISODuration{Months: 1}, false},
+		{"PT1M", ISODuration{Duration: time.Minute}, false},
+		{"P2W", ISODuration{Days: 14}, false},
+		{"PT1.5S", ISODuration{Duration: 1500 * time.Millisecond}, false},
+		{"PT0,5H", ISODuration{Duration: 30 * time.Minute}, false},
+		{"P1DT12H30M15S", ISODuration{Days: 1, Duration: 12*time.Hour + 30*time.Minute + 15*time.Second}, false},
+		{"-P1DT2H", ISODuration{Days: -1, Duration: -2 * time.Hour}, false},
+		{"P1M-2D", ISODuration{Months: 1, Days: -2}, false},
+		{"PT0S", ISODuration{}, false},
+		{"", ISODuration{}, true},
+		{"P", ISODuration{}, true},
+		{"PT", ISODuration{}, true},
+		{"1Y", ISODuration{}, true},
+		{"P1D2Y", ISODuration{}, true},
+		{"P1H", ISODuration{}, true},
+		{"PT1Y", ISODuration{}, true},
+		{"P1.5Y", ISODuration{}, true},
+		{"P1Y1Y", ISODuration{}, true},
+		{"PT1HT1M", ISODuration{}, true},
+		{"PTS", ISODuration{}, true},
+		{"PT9999999999H", ISODuration{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := util.ParseISODuration(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseISODuration(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseISODuration(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatISODuration(t *testing.T) {
+	util := NewDateUtil()
+
+	tests := []struct {
+		name                string
+		years, months, days int
+		d                   time.Duration
+		want                string
+	}{
+		{"zero", 0, 0, 0, 0, "PT0S"},
+		{"calendar only", 1, 2, 3, 0, "P1Y2M3D"},
+		{"clock only", 0, 0, 0, 90 * time.Minute, "PT1H30M"},
+		{"fractional seconds", 0, 0, 0, 1500 * time.Millisecond, "PT1.5S"},
+		{"mixed", 0, 1, 0, 26*time.Hour + 5*time.Second, "P1MT26H5S"},
+		{"negative", 0, 0, -1, -2 * time.Hour, "-P1DT2H"},
+		{"mixed signs", 0, 1, -2, 0, "P1M-2D"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := util.FormatISODuration(tt.years, tt.months, tt.days, tt.d)
+			if got != tt.want {
+				t.Errorf("FormatISODuration() = %q, want %q", got, tt.want)
+			}
+			parsed, err := util.ParseISODuration(got)
+			if err != nil {
+				t.Fatalf("ParseISODuration(%q) error = %v", got, err)
+			}
+			want := ISODuration{Years: tt.years, Months: tt.months, Days: tt.days, Duration: tt.d}
+			if parsed != want {
+				t.Errorf("round trip = %+v, want %+v", parsed, want)
+			}
+		})
+	}
+}
+
+func TestISODuration_AddTo(t *testing.T) {
+	util := NewDateUtil()
+	start := time.Date(2024, time.January, 31, 10, 0, 0, 0, time.UTC)
+
+	p, err := util.ParseISODuration("P1MT2H")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// AddDate normalizes February 31st to March 2nd in a leap year
+	want := time.Date(2024, time.March, 2, 12, 0, 0, 0, time.UTC)
+	if got := p.AddTo(start); !got.Equal(want) {
+		t.Errorf("AddTo() = %v, want %v", got, want)
+	}
+	if p.String() != "P1MT2H" {
+		t.Errorf("String() = %q, want %q", p.String(), "P1MT2H")
+	}
+	if p.IsZero() || !(ISODuration{}).IsZero() {
+		t.Error("IsZero() is wrong")
+	}
+}
+
+// =================== Test Business Day Conventions ===================
+
+func TestAdjustToBusinessDay(t *testing.T) {
+	util := NewDateUtil()
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 15, 0, 0, 0, time.UTC) }
+	christmas := NewHolidays(day(2024, time.December, 25), time.Date(2024, time.December, 26, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name       string
+		date       time.Time
+		convention BusinessDayConvention
+		holidays   HolidayCalendar
+		want       time.Time
+	}{
+		{"business day unchanged", day(2024, time.August, 28), Following, nil, day(2024, time.August, 28)},
+		{"unadjusted", day(2024, time.August, 31), Unadjusted, nil, day(2024, time.August, 31)},
+		{"following over weekend", day(2024, time.August, 31), Following, nil, day(2024, time.September, 2)},
+		{"modified following stays in month", day(2024, time.August, 31), ModifiedFollowing, nil, day(2024, time.August, 30)},
+		{"modified following within month", day(2024, time.August, 17), ModifiedFollowing, nil, day(2024, time.August, 19)},
+		{"preceding over weekend", day(2024, time.June, 2), Preceding, nil, day(2024, time.May, 31)},
+		{"modified preceding stays in month", day(2024, time.June, 1), ModifiedPreceding, nil, day(2024, time.June, 3)},
+		{"following over holidays", day(2024, time.December, 25), Following, christmas, day(2024, time.December, 27)},
+		{"preceding before holiday", day(2024, time.December, 26), Preceding, christmas, day(2024, time.December, 24)},
+		{"holiday func", day(2024, time.July, 4), Following, HolidayFunc(func(d time.Time) bool {
+			return d.Month() == time.July && d.Day() == 4
+		}), day(2024, time.July, 5)},
+		{"unknown convention", day(2024, time.August, 31), BusinessDayConvention(42), nil, day(2024, time.August, 31)},
+		{"calendar without business days", day(2024, time.August, 31), Following, HolidayFunc(func(time.Time) bool { return true }), day(2024, time.August, 31)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := util.AdjustToBusinessDay(tt.date, tt.convention, tt.holidays); !got.Equal(tt.want) {
+				t.Errorf("AdjustToBusinessDay(%s, %s) = %s, want %s", tt.date.Format("Mon 2006-01-02"), tt.convention, got.Format("Mon 2006-01-02 15:04"), tt.want.Format("Mon 2006-01-02 15:04"))
+			}
+		})
+	}
+
+	if got := BusinessDayConvention(42).String(); got != "BusinessDayConvention(42)" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+// =================== Test Date Ranges ===================
+
+func TestDateRanges(t *testing.T) {
+	util := NewDateUtil()
+	at := func(hour int) time.Time { return time.Date(2024, time.March, 1, hour, 0, 0, 0, time.UTC) }
+	rng := func(start, end int) DateRange { return DateRange{Start: at(start), End: at(end)} }
+
+	tests := []struct {
+		name     string
+		ranges   []DateRange
+		within   DateRange
+		merged   []DateRange
+		gaps     []DateRange
+		coverage time.Duration
+	}{
+		{
+			name:   "empty",
+			within: rng(0, 24),
+			merged: []DateRange{},
+			gaps:   []DateRange{rng(0, 24)},
+		},
+		{
+			name:     "overlapping, touching and unsorted",
+			ranges:   []DateRange{rng(10, 12), rng(2, 5), rng(4, 8), rng(8, 9)},
+			within:   rng(0, 24),
+			merged:   []DateRange{rng(2, 9), rng(10, 12)},
+			gaps:     []DateRange{rng(0, 2), rng(9, 10), rng(12, 24)},
+			coverage: 9 * time.Hour,
+		},
+		{
+			name:     "contained range and reversed range",
+			ranges:   []DateRange{rng(1, 10), rng(3, 4), {Start: at(14), End: at(12)}},
+			within:   rng(2, 13),
+			merged:   []DateRange{rng(1, 10), rng(12, 14)},
+			gaps:     []DateRange{rng(10, 12)},
+			coverage: 11 * time.Hour,
+		},
+		{
+			name:     "empty ranges dropped",
+			ranges:   []DateRange{rng(5, 5), rng(6, 7)},
+			within:   rng(6, 7),
+			merged:   []DateRange{rng(6, 7)},
+			gaps:     []DateRange{},
+			coverage: time.Hour,
+		},
+		{
+			name:     "ranges outside the window",
+			ranges:   []DateRange{rng(0, 1), rng(20, 22)},
+			within:   rng(5, 10),
+			merged:   []DateRange{rng(0, 1), rng(20, 22)},
+			gaps:     []DateRange{rng(5, 10)},
+			coverage: 3 * time.Hour,
+		},
+		{
+			name:     "empty window",
+			ranges:   []DateRange{rng(1, 2)},
+			within:   rng(5, 5),
+			merged:   []DateRange{rng(1, 2)},
+			gaps:     []DateRange{},
+			coverage: time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := util.MergeRanges(tt.ranges); !reflect.DeepEqual(got, tt.merged) {
+				t.Errorf("MergeRanges() = %v, want %v", got, tt.merged)
+			}
+			if got := util.FindGaps(tt.ranges, tt.within); !reflect.DeepEqual(got, tt.gaps) {
+				t.Errorf("FindGaps() = %v, want %v", got, tt.gaps)
+			}
+			if got := util.TotalCoverage(tt.ranges); got != tt.coverage {
+				t.Errorf("TotalCoverage() = %v, want %v", got, tt.coverage)
+			}
+		})
+	}
+
+	input := []DateRange{rng(3, 4), rng(1, 2)}
+	util.MergeRanges(input)
+	if !input[0].Start.Equal(at(3)) {
+		t.Error("MergeRanges() must not modify its input")
+	}
+	if r := rng(1, 3); !r.Contains(at(1)) || r.Contains(at(3)) || r.Duration() != 2*time.Hour {
+		t.Error("DateRange should be half-open [Start, End)")
+	}
+}
+
 // =================== Benchmarks ===================
 
 func BenchmarkParse(b *testing.B) {
@@ -574,3 +991,155 @@ func BenchmarkDaysBetween(b *testing.B) {
 		_ = util.DaysBetween(start, end)
 	}
 }
+
+// manualClock is a Clock moved forward by advance
+type manualClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *manualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *manualClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestStopwatch(t *testing.T) {
+	clock := &manualClock{now: time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)}
+	sw := NewStopwatch(clock)
+
+	clock.advance(time.Hour)
+	if got := sw.Elapsed(); got != 0 || sw.Running() {
+		t.Errorf("Elapsed() before Start = %v, running=%v, want 0 and stopped", got, sw.Running())
+	}
+
+	sw.Start()
+	clock.advance(2 * time.Second)
+	if got := sw.Lap(); got != 2*time.Second {
+		t.Errorf("first Lap() = %v, want 2s", got)
+	}
+	clock.advance(3 * time.Second)
+	if got := sw.Stop(); got != 5*time.Second {
+		t.Errorf("Stop() = %v, want 5s", got)
+	}
+
+	clock.advance(time.Minute) // paused time is not counted
+	sw.Start()
+	sw.Start() // no-op while running
+	clock.advance(time.Second)
+	if got := sw.Lap(); got != 4*time.Second {
+		t.Errorf("second Lap() = %v, want 4s of running time", got)
+	}
+	if got := sw.Elapsed(); got != 6*time.Second {
+		t.Errorf("Elapsed() = %v, want 6s", got)
+	}
+	if got := sw.Laps(); !reflect.DeepEqual(got, []time.Duration{2 * time.Second, 4 * time.Second}) {
+		t.Errorf("Laps() = %v, want [2s 4s]", got)
+	}
+
+	sw.Reset()
+	if sw.Elapsed() != 0 || sw.Running() || sw.Laps() != nil {
+		t.Errorf("after Reset() Elapsed=%v running=%v laps=%v, want a cleared stopwatch", sw.Elapsed(), sw.Running(), sw.Laps())
+	}
+}
+
+func TestStopwatch_Concurrent(t *testing.T) {
+	var sw Stopwatch // the zero value runs on SystemClock
+	sw.Start()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				sw.Lap()
+				_ = sw.Elapsed()
+			}
+		}()
+	}
+	wg.Wait()
+
+	laps := sw.Laps()
+	var sum time.Duration
+	for _, lap := range laps {
+		if lap < 0 {
+			t.Fatalf("Lap() = %v, want non-negative", lap)
+		}
+		sum += lap
+	}
+	if len(laps) != 800 || sum > sw.Stop() {
+		t.Errorf("laps = %d summing to %v, want 800 laps within the elapsed time", len(laps), sum)
+	}
+}
+
+func TestTimed(t *testing.T) {
+	errBoom := errors.New("boom")
+	took, err := Timed(func() error {
+		time.Sleep(5 * time.Millisecond)
+		return errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Errorf("Timed() error = %v, want %v", err, errBoom)
+	}
+	if took < 5*time.Millisecond {
+		t.Errorf("Timed() = %v, want at least 5ms", took)
+	}
+}
+
+func TestContextDeadlines(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("LoadLocation() error = %v", err)
+	}
+	// 22:30 in New York on the day before the spring-forward switch; 03:30 UTC
+	now := time.Date(2024, time.March, 9, 22, 30, 0, 0, ny)
+	util := NewDateUtilWithClock(fixedClock{t: now})
+
+	if got := util.UntilEndOfDay(ny); got != 90*time.Minute {
+		t.Errorf("UntilEndOfDay(New York) = %v, want 1h30m", got)
+	}
+	if got := util.UntilEndOfDay(time.UTC); got != 20*time.Hour+30*time.Minute {
+		t.Errorf("UntilEndOfDay(UTC) = %v, want 20h30m", got)
+	}
+	// The next day is 23 hours long in New York
+	dstEve := NewDateUtilWithClock(fixedClock{t: time.Date(2024, time.March, 10, 0, 0, 0, 0, ny)})
+	if got := dstEve.UntilEndOfDay(nil); got != 23*time.Hour {
+		t.Errorf("UntilEndOfDay() on the DST day = %v, want 23h", got)
+	}
+
+	ctx, cancel := util.ContextUntilEndOfDay(context.Background(), ny)
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || !deadline.Equal(time.Date(2024, time.March, 10, 0, 0, 0, 0, ny)) {
+		t.Errorf("ContextUntilEndOfDay() deadline = %v, %v; want New York midnight", deadline, ok)
+	}
+	if remaining, ok := util.DeadlineRemaining(ctx); !ok || remaining != 90*time.Minute {
+		t.Errorf("DeadlineRemaining() = %v, %v; want 1h30m, true", remaining, ok)
+	}
+
+	system := NewDateUtil()
+	ctx, cancel = system.ContextWithDeadlineAt(context.Background(), time.Now().Add(time.Hour))
+	defer cancel()
+	if remaining, ok := system.DeadlineRemaining(ctx); !ok || remaining <= 59*time.Minute || remaining > time.Hour {
+		t.Errorf("DeadlineRemaining() = %v, %v; want about 1h", remaining, ok)
+	}
+	expired, cancelExpired := system.ContextWithDeadlineAt(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	if remaining, ok := system.DeadlineRemaining(expired); !ok || remaining != 0 || expired.Err() == nil {
+		t.Errorf("DeadlineRemaining(expired) = %v, %v, err %v; want 0, true and a cancelled context", remaining, ok, expired.Err())
+	}
+
+	plain, cancelPlain := system.ContextWithDeadlineAt(context.Background(), time.Time{})
+	defer cancelPlain()
+	if _, ok := system.DeadlineRemaining(plain); ok {
+		t.Error("ContextWithDeadlineAt(zero) should not set a deadline")
+	}
+	if _, ok := system.DeadlineRemaining(context.Background()); ok {
+		t.Error("DeadlineRemaining(Background) should report no deadline")
+	}
+}
@@ -0,0 +1,165 @@
+package dateutil
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cronDescriptors maps the supported shorthand expressions to their five-field form
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// cronField describes the valid range of one cron field
+type cronField struct {
+	name     string
+	min, max int
+}
+
+var cronFields = []cronField{
+	{"minute", 0, 59},
+	{"hour", 0, 23},
+	{"day of month", 1, 31},
+	{"month", 1, 12},
+	{"day of week", 0, 7}, // 0 and 7 are both Sunday
+}
+
+// CronSchedule is a parsed five-field cron expression (minute hour day-of-month month day-of-week)
+type CronSchedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domAny/dowAny record a "*" day field; when both day fields are restricted,
+	// a time matches if either one matches, as in standard cron.
+	domAny, dowAny bool
+}
+
+// ParseCron parses a standard five-field cron expression
+// Fields accept "*", single values, ranges ("1-5"), lists ("1,15") and steps ("*/10", "0-30/5").
+// The descriptors @yearly, @annually, @monthly, @weekly, @daily, @midnight and @hourly are also accepted.
+func (d *DateUtil) ParseCron(expr string) (*CronSchedule, error) {
+	expr = strings.TrimSpace(expr)
+	if descriptor, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = descriptor
+	}
+
+	parts := strings.Fields(expr)
+	if len(parts) != len(cronFields) {
+		return nil, fmt.Errorf("invalid cron expression %q: expected %d fields, got %d", expr, len(cronFields), len(parts))
+	}
+
+	bits := make([]uint64, len(cronFields))
+	for i, part := range parts {
+		parsed, err := parseCronField(part, cronFields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+		bits[i] = parsed
+	}
+
+	// Fold day-of-week 7 into 0 so Sunday has a single representation
+	if bits[4]&(1<<7) != 0 {
+		bits[4] = bits[4]&^(1<<7) | 1
+	}
+
+	return &CronSchedule{
+		minute: bits[0],
+		hour:   bits[1],
+		dom:    bits[2],
+		month:  bits[3],
+		dow:    bits[4],
+		domAny: parts[2] == "*",
+		dowAny: parts[4] == "*",
+	}, nil
+}
+
+// Next returns the first time after t that matches the schedule, in t's location
+// It returns the zero time if no match exists within five years (e.g. "0 0 30 2 *").
+func (c *CronSchedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+	next := t.Truncate(time.Minute).Add(time.Minute)
+	limit := next.AddDate(5, 0, 0)
+
+	for next.Before(limit) {
+		if c.month&(1<<uint(next.Month())) == 0 {
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if !c.matchesDay(next) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(next.Hour())) == 0 {
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<uint(next.Minute())) == 0 {
+			next = next.Add(time.Minute)
+			continue
+		}
+		return next
+	}
+	return time.Time{}
+}
+
+// matchesDay applies cron's day-of-month / day-of-week rules
+func (c *CronSchedule) matchesDay(t time.Time) bool {
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	if !c.domAny && !c.dowAny {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+// parseCronField converts one comma-separated cron field into a bitset of allowed values
+func parseCronField(field string, spec cronField) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rangePart, step := item, 1
+		if idx := strings.Index(item, "/"); idx >= 0 {
+			rangePart = item[:idx]
+			var err error
+			if step, err = strconv.Atoi(item[idx+1:]); err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field: %q", spec.name, item)
+			}
+		}
+
+		low, high := spec.min, spec.max
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var errLow, errHigh error
+			low, errLow = strconv.Atoi(bounds[0])
+			high, errHigh = strconv.Atoi(bounds[1])
+			if errLow != nil || errHigh != nil {
+				return 0, fmt.Errorf("invalid range in %s field: %q", spec.name, item)
+			}
+		default:
+			value, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value in %s field: %q", spec.name, item)
+			}
+			low, high = value, value
+			if strings.Contains(item, "/") {
+				high = spec.max // "5/15" means every 15 starting at 5
+			}
+		}
+
+		if low < spec.min || high > spec.max || low > high {
+			return 0, fmt.Errorf("%s field out of range [%d-%d]: %q", spec.name, spec.min, spec.max, item)
+		}
+		for v := low; v <= high; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
@@ -0,0 +1,57 @@
+package dateutil
+
+import (
+	"context"
+	"time"
+)
+
+// ContextWithDeadlineAt returns a copy of ctx that is cancelled at t, e.g. a cut-off computed with
+// NextOccurrenceOfTime; a zero t only adds cancellation. An earlier deadline already on ctx still applies.
+// The deadline is enforced by the real clock whatever Clock the DateUtil reads.
+func (d *DateUtil) ContextWithDeadlineAt(ctx context.Context, t time.Time) (context.Context, context.CancelFunc) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if t.IsZero() {
+		return context.WithCancel(ctx)
+	}
+	return context.WithDeadline(ctx, t)
+}
+
+// DeadlineRemaining returns the time left before the deadline of ctx, or false when it has none
+// A deadline that has passed reports 0.
+func (d *DateUtil) DeadlineRemaining(ctx context.Context) (time.Duration, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	if remaining := deadline.Sub(d.now()); remaining > 0 {
+		return remaining, true
+	}
+	return 0, true
+}
+
+// UntilEndOfDay returns the time left until the next midnight in loc (nil uses the clock's location)
+// Midnight is computed on the calendar, so days lengthened or shortened by DST are honoured.
+func (d *DateUtil) UntilEndOfDay(loc *time.Location) time.Duration {
+	now := d.now()
+	return endOfDayAfter(now, loc).Sub(now)
+}
+
+// ContextUntilEndOfDay returns a copy of ctx that is cancelled at the next midnight in loc (nil uses the
+// clock's location), e.g. to stop a batch job at the end of the business day
+func (d *DateUtil) ContextUntilEndOfDay(ctx context.Context, loc *time.Location) (context.Context, context.CancelFunc) {
+	return d.ContextWithDeadlineAt(ctx, endOfDayAfter(d.now(), loc))
+}
+
+// endOfDayAfter returns the midnight that ends the day of now in loc
+func endOfDayAfter(now time.Time, loc *time.Location) time.Time {
+	if loc != nil {
+		now = now.In(loc)
+	}
+	year, month, day := now.Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, now.Location())
+}
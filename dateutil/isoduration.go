@@ -0,0 +1,200 @@
+package dateutil
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ISODuration is an ISO 8601 duration such as "P1Y2M3DT4H5M6.5S"
+// Calendar components are kept separate from the clock part because their length depends on the date they are
+// applied to (a month is 28 to 31 days, a day can be 23 or 25 hours across DST). Weeks are folded into Days.
+type ISODuration struct {
+	Years    int
+	Months   int
+	Days     int
+	Duration time.Duration
+}
+
+// AddTo applies the duration to t: calendar components first, then the clock part
+func (p ISODuration) AddTo(t time.Time) time.Time {
+	return t.AddDate(p.Years, p.Months, p.Days).Add(p.Duration)
+}
+
+// IsZero reports whether every component is zero
+func (p ISODuration) IsZero() bool {
+	return p.Years == 0 && p.Months == 0 && p.Days == 0 && p.Duration == 0
+}
+
+// String formats the duration in ISO 8601 form
+func (p ISODuration) String() string {
+	return formatISODuration(p.Years, p.Months, p.Days, p.Duration)
+}
+
+// isoDesignators lists the component designators in the order ISO 8601 requires
+var isoDesignators = []struct {
+	symbol byte
+	time   bool
+}{
+	{'Y', false}, {'M', false}, {'W', false}, {'D', false},
+	{'H', true}, {'M', true}, {'S', true},
+}
+
+// ParseISODuration parses an ISO 8601 duration such as "P1Y2M3DT4H", "P2W" or "PT1.5S"
+// A leading "-" negates every component, and fractions are accepted on hours, minutes and seconds.
+func (d *DateUtil) ParseISODuration(s string) (ISODuration, error) {
+	rest := strings.TrimSpace(s)
+	sign := 1
+	if strings.HasPrefix(rest, "-") {
+		sign, rest = -1, rest[1:]
+	} else if strings.HasPrefix(rest, "+") {
+		rest = rest[1:]
+	}
+	if !strings.HasPrefix(rest, "P") || len(rest) == 1 {
+		return ISODuration{}, fmt.Errorf("invalid ISO 8601 duration %q", s)
+	}
+	rest = rest[1:]
+
+	var result ISODuration
+	next := 0 // index into isoDesignators of the next allowed component
+	inTime := false
+	components := 0
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime {
+				return ISODuration{}, fmt.Errorf("invalid ISO 8601 duration %q: repeated 'T'", s)
+			}
+			inTime, rest = true, rest[1:]
+			for next < len(isoDesignators) && !isoDesignators[next].time {
+				next++
+			}
+			if rest == "" {
+				return ISODuration{}, fmt.Errorf("invalid ISO 8601 duration %q: 'T' without time components", s)
+			}
+			continue
+		}
+
+		end := strings.IndexFunc(rest, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.' && r != ',' && r != '-'
+		})
+		if end <= 0 {
+			return ISODuration{}, fmt.Errorf("invalid ISO 8601 duration %q: expected a number", s)
+		}
+		number, designator := strings.Replace(rest[:end], ",", ".", 1), rest[end]
+		rest = rest[end+1:]
+
+		idx := -1
+		for i := next; i < len(isoDesignators); i++ {
+			if isoDesignators[i].symbol == designator && isoDesignators[i].time == inTime {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			return ISODuration{}, fmt.Errorf("invalid ISO 8601 duration %q: unexpected or out-of-order '%c'", s, designator)
+		}
+		next = idx + 1
+		components++
+
+		if err := result.set(isoDesignators[idx].symbol, inTime, number, sign); err != nil {
+			return ISODuration{}, fmt.Errorf("invalid ISO 8601 duration %q: %v", s, err)
+		}
+	}
+	if components == 0 {
+		return ISODuration{}, fmt.Errorf("invalid ISO 8601 duration %q: no components", s)
+	}
+	return result, nil
+}
+
+// set stores one parsed component
+func (p *ISODuration) set(designator byte, inTime bool, number string, sign int) error {
+	if !inTime {
+		n, err := strconv.Atoi(number)
+		if err != nil {
+			return fmt.Errorf("%c component must be an integer: %q", designator, number)
+		}
+		n *= sign
+		switch designator {
+		case 'Y':
+			p.Years = n
+		case 'M':
+			p.Months = n
+		case 'W':
+			p.Days += n * 7
+		case 'D':
+			p.Days += n
+		}
+		return nil
+	}
+
+	unit := map[byte]time.Duration{'H': time.Hour, 'M': time.Minute, 'S': time.Second}[designator]
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %c component %q", designator, number)
+	}
+	component := value * float64(unit) * float64(sign)
+	total := float64(p.Duration) + component
+	if math.Abs(total) > math.MaxInt64 {
+		return fmt.Errorf("clock part overflows time.Duration")
+	}
+	p.Duration = time.Duration(math.Round(total))
+	return nil
+}
+
+// FormatISODuration formats calendar components and a clock duration as an ISO 8601 duration
+// When every component is zero or negative the result uses a leading "-" (e.g. "-P1DT2H"); mixed signs are
+// written per component ("P1M-2D"), which ParseISODuration accepts. A zero duration is "PT0S".
+func (d *DateUtil) FormatISODuration(years, months, days int, dur time.Duration) string {
+	return formatISODuration(years, months, days, dur)
+}
+
+// formatISODuration implements FormatISODuration
+func formatISODuration(years, months, days int, dur time.Duration) string {
+	if years == 0 && months == 0 && days == 0 && dur == 0 {
+		return "PT0S"
+	}
+
+	var b strings.Builder
+	if years <= 0 && months <= 0 && days <= 0 && dur <= 0 {
+		b.WriteByte('-')
+		years, months, days = -years, -months, -days
+		dur = -dur
+	}
+	b.WriteByte('P')
+
+	for _, c := range []struct {
+		n      int
+		symbol byte
+	}{{years, 'Y'}, {months, 'M'}, {days, 'D'}} {
+		if c.n != 0 {
+			b.WriteString(strconv.Itoa(c.n))
+			b.WriteByte(c.symbol)
+		}
+	}
+
+	if dur != 0 {
+		b.WriteByte('T')
+		sign := ""
+		if dur < 0 {
+			sign, dur = "-", -dur
+		}
+		hours := dur / time.Hour
+		dur -= hours * time.Hour
+		minutes := dur / time.Minute
+		dur -= minutes * time.Minute
+
+		if hours != 0 {
+			fmt.Fprintf(&b, "%s%dH", sign, hours)
+		}
+		if minutes != 0 {
+			fmt.Fprintf(&b, "%s%dM", sign, minutes)
+		}
+		if dur != 0 {
+			seconds := strconv.FormatFloat(dur.Seconds(), 'f', -1, 64)
+			fmt.Fprintf(&b, "%s%sS", sign, seconds)
+		}
+	}
+	return b.String()
+}
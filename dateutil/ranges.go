@@ -0,0 +1,88 @@
+package dateutil
+
+import (
+	"sort"
+	"time"
+)
+
+// DateRange is the half-open interval [Start, End)
+type DateRange struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Duration returns the length of the range, or zero when End is not after Start
+func (r DateRange) Duration() time.Duration {
+	if !r.End.After(r.Start) {
+		return 0
+	}
+	return r.End.Sub(r.Start)
+}
+
+// Contains reports whether t falls within [Start, End)
+func (r DateRange) Contains(t time.Time) bool {
+	return !t.Before(r.Start) && t.Before(r.End)
+}
+
+// MergeRanges normalizes ranges into sorted, non-overlapping ranges
+// Ranges with End before Start are swapped, empty ranges are dropped, and overlapping or touching
+// ranges (one ending exactly when the next starts) are joined. The input is not modified.
+func (d *DateUtil) MergeRanges(ranges []DateRange) []DateRange {
+	sorted := make([]DateRange, 0, len(ranges))
+	for _, r := range ranges {
+		if r.End.Before(r.Start) {
+			r.Start, r.End = r.End, r.Start
+		}
+		if r.End.After(r.Start) {
+			sorted = append(sorted, r)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	merged := make([]DateRange, 0, len(sorted))
+	for _, r := range sorted {
+		if n := len(merged); n > 0 && !r.Start.After(merged[n-1].End) {
+			if r.End.After(merged[n-1].End) {
+				merged[n-1].End = r.End
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// FindGaps returns the parts of within that no range covers, in order
+// Useful to spot holes in an on-call rotation or availability calendar over a period.
+func (d *DateUtil) FindGaps(ranges []DateRange, within DateRange) []DateRange {
+	gaps := []DateRange{}
+	if !within.End.After(within.Start) {
+		return gaps
+	}
+	cursor := within.Start
+	for _, r := range d.MergeRanges(ranges) {
+		if !r.End.After(cursor) {
+			continue
+		}
+		if !r.Start.Before(within.End) {
+			break
+		}
+		if r.Start.After(cursor) {
+			gaps = append(gaps, DateRange{Start: cursor, End: r.Start})
+		}
+		cursor = r.End
+	}
+	if cursor.Before(within.End) {
+		gaps = append(gaps, DateRange{Start: cursor, End: within.End})
+	}
+	return gaps
+}
+
+// TotalCoverage returns the total time covered by ranges, counting overlaps once
+func (d *DateUtil) TotalCoverage(ranges []DateRange) time.Duration {
+	var total time.Duration
+	for _, r := range d.MergeRanges(ranges) {
+		total += r.Duration()
+	}
+	return total
+}
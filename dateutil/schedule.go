@@ -0,0 +1,76 @@
+package dateutil
+
+import (
+	"fmt"
+	"time"
+)
+
+// clockLayouts are the accepted wall-clock formats for NextOccurrenceOfTime
+var clockLayouts = []string{"15:04", "15:04:05"}
+
+// NextOccurrenceOfTime returns the first instant after `after` at which the wall clock in loc reads clock ("09:30" or "09:30:15")
+// A nil loc uses after's location. The result fires once per calendar day and handles DST transitions:
+//   - a wall time skipped by a spring-forward transition is moved later by the length of the gap (02:30 becomes 03:30)
+//   - a wall time repeated by a fall-back transition resolves to its first (earlier) occurrence
+func (d *DateUtil) NextOccurrenceOfTime(clock string, loc *time.Location, after time.Time) (time.Time, error) {
+	var wall time.Time
+	var err error
+	for _, layout := range clockLayouts {
+		if wall, err = time.Parse(layout, clock); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid clock time %q: expected HH:MM or HH:MM:SS", clock)
+	}
+	if loc == nil {
+		loc = after.Location()
+	}
+
+	local := after.In(loc)
+	for day := 0; day <= 2; day++ {
+		candidate := resolveWallTime(local.Year(), local.Month(), local.Day()+day, wall.Hour(), wall.Minute(), wall.Second(), loc)
+		if candidate.After(after) {
+			return candidate, nil
+		}
+	}
+	// Unreachable: a day later the same wall time is always after `after`
+	return time.Time{}, fmt.Errorf("no occurrence of %s found after %v", clock, after)
+}
+
+// resolveWallTime converts a wall-clock time in loc to an instant with explicit DST rules
+// time.Date leaves skipped and repeated wall times unspecified, so the candidate offsets are checked by hand.
+func resolveWallTime(year int, month time.Month, day, hour, minute, second int, loc *time.Location) time.Time {
+	wall := time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+	u := wall.Unix()
+
+	// DST transitions are months apart, so at most one happens within a day either side
+	_, offBefore := time.Unix(u-86400, 0).In(loc).Zone()
+	_, offAfter := time.Unix(u+86400, 0).In(loc).Zone()
+
+	early := time.Unix(u-int64(offBefore), 0).In(loc)
+	late := time.Unix(u-int64(offAfter), 0).In(loc)
+	earlyValid := sameWallTime(early, wall)
+	lateValid := sameWallTime(late, wall)
+
+	switch {
+	case earlyValid && lateValid:
+		if late.Before(early) {
+			return late
+		}
+		return early
+	case earlyValid:
+		return early
+	case lateValid:
+		return late
+	}
+	// Skipped by a spring-forward gap: interpreting the wall time with the pre-transition
+	// offset lands the same distance past the gap as the wall time was into it
+	return early
+}
+
+// sameWallTime reports whether t reads the same date and clock time as wall
+func sameWallTime(t, wall time.Time) bool {
+	return t.Year() == wall.Year() && t.Month() == wall.Month() && t.Day() == wall.Day() &&
+		t.Hour() == wall.Hour() && t.Minute() == wall.Minute() && t.Second() == wall.Second()
+}
@@ -0,0 +1,117 @@
+package dateutil
+
+import (
+	"sync"
+	"time"
+)
+
+// Stopwatch measures elapsed time with laps, using the monotonic clock so wall-clock jumps do not skew it
+// The zero value is a stopped stopwatch on SystemClock. It is safe for concurrent use, e.g. one goroutine
+// recording laps while another reads Elapsed.
+type Stopwatch struct {
+	mu      sync.Mutex
+	clock   Clock
+	running bool
+	started time.Time     // start of the current running period
+	stored  time.Duration // elapsed time of the finished running periods
+	lapMark time.Duration // elapsed time at the previous lap
+	laps    []time.Duration
+}
+
+// NewStopwatch creates a stopped Stopwatch reading from clock (nil uses SystemClock)
+func NewStopwatch(clock Clock) *Stopwatch {
+	return &Stopwatch{clock: clock}
+}
+
+// StartStopwatch creates a Stopwatch on SystemClock that is already running
+func StartStopwatch() *Stopwatch {
+	s := &Stopwatch{}
+	s.Start()
+	return s
+}
+
+// now reads the stopwatch clock; callers hold s.mu
+func (s *Stopwatch) now() time.Time {
+	if s.clock == nil {
+		return SystemClock.Now()
+	}
+	return s.clock.Now()
+}
+
+// elapsed returns the total running time; callers hold s.mu
+func (s *Stopwatch) elapsed() time.Duration {
+	if !s.running {
+		return s.stored
+	}
+	return s.stored + s.now().Sub(s.started)
+}
+
+// Start starts or resumes the stopwatch; it does nothing while running
+func (s *Stopwatch) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.running {
+		s.running = true
+		s.started = s.now()
+	}
+}
+
+// Stop pauses the stopwatch and returns the total elapsed time; Start resumes it
+func (s *Stopwatch) Stop() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stored = s.elapsed()
+	s.running = false
+	return s.stored
+}
+
+// Lap records and returns the running time since the previous lap, or since the start for the first one
+func (s *Stopwatch) Lap() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total := s.elapsed()
+	lap := total - s.lapMark
+	s.lapMark = total
+	s.laps = append(s.laps, lap)
+	return lap
+}
+
+// Laps returns a copy of the recorded laps, e.g. to feed a mathutil.Welford accumulator
+func (s *Stopwatch) Laps() []time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]time.Duration(nil), s.laps...)
+}
+
+// Elapsed returns the total running time, excluding paused periods
+func (s *Stopwatch) Elapsed() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.elapsed()
+}
+
+// Running reports whether the stopwatch is running
+func (s *Stopwatch) Running() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.running
+}
+
+// Reset stops the stopwatch and clears the elapsed time and laps
+func (s *Stopwatch) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = false
+	s.stored, s.lapMark = 0, 0
+	s.laps = nil
+}
+
+// Timed runs fn and returns how long it took together with its error
+//
+//	took, err := dateutil.Timed(func() error { return client.Sync(ctx) })
+//	logger.WithFields(logutil.Fields{"took": took}).Info("Sync finished")
+func Timed(fn func() error) (time.Duration, error) {
+	start := time.Now()
+	err := fn()
+	return time.Since(start), err
+}
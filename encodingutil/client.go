@@ -0,0 +1,266 @@
+package encodingutil
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Encoding identifies a binary-to-text encoding
+type Encoding int
+
+const (
+	Base64Std    Encoding = iota // RFC 4648 standard alphabet with padding
+	Base64URL                    // URL-safe alphabet with padding
+	Base64RawStd                 // standard alphabet without padding
+	Base64RawURL                 // URL-safe alphabet without padding (JWTs, cursors)
+	Hex                          // lower-case hexadecimal
+	Plain                        // not encoded: Encode and Decode pass data through unchanged
+)
+
+// String returns the encoding name
+func (e Encoding) String() string {
+	switch e {
+	case Base64Std:
+		return "base64"
+	case Base64URL:
+		return "base64url"
+	case Base64RawStd:
+		return "base64raw"
+	case Base64RawURL:
+		return "base64rawurl"
+	case Hex:
+		return "hex"
+	case Plain:
+		return "plain"
+	}
+	return fmt.Sprintf("Encoding(%d)", int(e))
+}
+
+// EncodingClient defines the interface for base64 and hex encoding
+type EncodingClient interface {
+	// Base64
+	EncodeBase64(data []byte) string
+	EncodeBase64URL(data []byte) string
+	DecodeBase64(s string) ([]byte, error)
+
+	// Hex
+	EncodeHex(data []byte) string
+	DecodeHex(s string) ([]byte, error)
+
+	// Any supported encoding
+	Encode(data []byte, enc Encoding) (string, error)
+	Decode(s string, enc Encoding) ([]byte, error)
+	DetectAndDecode(s string) ([]byte, Encoding, error)
+	DetectEncoding(b []byte) Detection
+	DecodeAny(b []byte) ([]byte, Detection, error)
+
+	// Streaming
+	NewEncoder(w io.Writer, enc Encoding) (io.WriteCloser, error)
+	NewDecoder(r io.Reader, enc Encoding) (io.Reader, error)
+}
+
+// EncodingUtil implements EncodingClient
+type EncodingUtil struct{}
+
+// NewEncodingUtil creates a new encoding utility instance
+func NewEncodingUtil() EncodingClient {
+	return &EncodingUtil{}
+}
+
+// EncodeBase64 encodes data with the standard padded base64 alphabet
+func (e *EncodingUtil) EncodeBase64(data []byte) string {
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// EncodeBase64URL encodes data with the URL-safe alphabet and no padding
+func (e *EncodingUtil) EncodeBase64URL(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeBase64 decodes base64 in any of the four variants, detecting alphabet and padding
+// Surrounding whitespace and line breaks (as in PEM or MIME bodies) are ignored.
+func (e *EncodingUtil) DecodeBase64(s string) ([]byte, error) {
+	s = stripWhitespace(s)
+	enc, err := detectBase64(s)
+	if err != nil {
+		return nil, err
+	}
+	return e.Decode(s, enc)
+}
+
+// EncodeHex encodes data as lower-case hexadecimal
+func (e *EncodingUtil) EncodeHex(data []byte) string {
+	return hex.EncodeToString(data)
+}
+
+// DecodeHex decodes hexadecimal in either case, with an optional "0x" prefix
+func (e *EncodingUtil) DecodeHex(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		s = s[2:]
+	}
+	data, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	return data, nil
+}
+
+// Encode encodes data with the given encoding
+func (e *EncodingUtil) Encode(data []byte, enc Encoding) (string, error) {
+	switch enc {
+	case Hex:
+		return e.EncodeHex(data), nil
+	case Plain:
+		return string(data), nil
+	}
+	b64, err := base64Encoding(enc)
+	if err != nil {
+		return "", err
+	}
+	return b64.EncodeToString(data), nil
+}
+
+// Decode decodes s with exactly the given encoding
+func (e *EncodingUtil) Decode(s string, enc Encoding) ([]byte, error) {
+	switch enc {
+	case Hex:
+		return e.DecodeHex(s)
+	case Plain:
+		return []byte(s), nil
+	}
+	b64, err := base64Encoding(enc)
+	if err != nil {
+		return nil, err
+	}
+	data, err := b64.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	return data, nil
+}
+
+// DetectAndDecode decodes s as hex when it is an even-length run of hex digits, otherwise as base64
+// Hex wins for ambiguous input such as "deadbeef"; use Decode when the encoding is known.
+func (e *EncodingUtil) DetectAndDecode(s string) ([]byte, Encoding, error) {
+	s = stripWhitespace(s)
+	if s == "" {
+		return []byte{}, Base64Std, nil
+	}
+	if isHex(s) {
+		data, err := e.DecodeHex(s)
+		return data, Hex, err
+	}
+	enc, err := detectBase64(s)
+	if err != nil {
+		return nil, enc, err
+	}
+	data, err := e.Decode(s, enc)
+	return data, enc, err
+}
+
+// NewEncoder returns a writer that encodes everything written to it into w
+// Close must be called to flush any partially encoded block.
+func (e *EncodingUtil) NewEncoder(w io.Writer, enc Encoding) (io.WriteCloser, error) {
+	switch enc {
+	case Hex:
+		return nopCloser{hex.NewEncoder(w)}, nil
+	case Plain:
+		return nopCloser{w}, nil
+	}
+	b64, err := base64Encoding(enc)
+	if err != nil {
+		return nil, err
+	}
+	return base64.NewEncoder(b64, w), nil
+}
+
+// NewDecoder returns a reader that decodes the encoded stream r
+func (e *EncodingUtil) NewDecoder(r io.Reader, enc Encoding) (io.Reader, error) {
+	switch enc {
+	case Hex:
+		return hex.NewDecoder(r), nil
+	case Plain:
+		return r, nil
+	}
+	b64, err := base64Encoding(enc)
+	if err != nil {
+		return nil, err
+	}
+	return base64.NewDecoder(b64, r), nil
+}
+
+// base64Encoding maps a base64 Encoding to its standard library implementation
+func base64Encoding(enc Encoding) (*base64.Encoding, error) {
+	switch enc {
+	case Base64Std:
+		return base64.StdEncoding, nil
+	case Base64URL:
+		return base64.URLEncoding, nil
+	case Base64RawStd:
+		return base64.RawStdEncoding, nil
+	case Base64RawURL:
+		return base64.RawURLEncoding, nil
+	}
+	return nil, fmt.Errorf("%w: %v", ErrUnknownEncoding, enc)
+}
+
+// detectBase64 picks the base64 variant from the alphabet and padding used in s
+func detectBase64(s string) (Encoding, error) {
+	urlSafe := strings.ContainsAny(s, "-_")
+	standard := strings.ContainsAny(s, "+/")
+	padded := strings.HasSuffix(s, "=")
+
+	switch {
+	case urlSafe && standard:
+		return Base64Std, fmt.Errorf("%w: mixes standard and URL-safe base64 alphabets", ErrInvalidInput)
+	case urlSafe && padded:
+		return Base64URL, nil
+	case urlSafe:
+		return Base64RawURL, nil
+	case padded:
+		return Base64Std, nil
+	}
+	return Base64RawStd, nil
+}
+
+// isHex reports whether s is a non-empty, even-length string of hex digits (with optional "0x")
+func isHex(s string) bool {
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		s = s[2:]
+	}
+	if s == "" || len(s)%2 != 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// stripWhitespace removes all spaces and line breaks
+func stripWhitespace(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// nopCloser adds a no-op Close to encoders that need no flushing
+type nopCloser struct {
+	io.Writer
+}
+
+// Close implements io.Closer
+func (nopCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,290 @@
+package encodingutil
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// sample contains bytes that produce '+' and '/' in standard base64
+var sample = []byte{0xfb, 0xff, 0xbf, 0x01, 0x02}
+
+func TestNewEncodingUtil(t *testing.T) {
+	if util := NewEncodingUtil(); util == nil {
+		t.Error("NewEncodingUtil() returned nil")
+	}
+}
+
+func TestEncodingString(t *testing.T) {
+	if Base64RawURL.String() != "base64rawurl" || Hex.String() != "hex" || Encoding(99).String() != "Encoding(99)" {
+		t.Error("Encoding.String() returned unexpected names")
+	}
+}
+
+// =================== Test Base64 ===================
+
+func TestDecodeBase64Variants(t *testing.T) {
+	util := NewEncodingUtil()
+
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"standard padded", "+/+/AQI="},
+		{"standard raw", "+/+/AQI"},
+		{"url padded", "-_-_AQI="},
+		{"url raw", "-_-_AQI"},
+		{"with line breaks", "+/+/\nAQI=\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := util.DecodeBase64(tt.input)
+			if err != nil {
+				t.Fatalf("DecodeBase64(%q) error = %v", tt.input, err)
+			}
+			if !bytes.Equal(result, sample) {
+				t.Errorf("DecodeBase64(%q) = %x, want %x", tt.input, result, sample)
+			}
+		})
+	}
+
+	for _, invalid := range []string{"+/-_", "a", "!!!!"} {
+		if _, err := util.DecodeBase64(invalid); !errors.Is(err, ErrInvalidInput) {
+			t.Errorf("DecodeBase64(%q) error = %v, want ErrInvalidInput", invalid, err)
+		}
+	}
+}
+
+func TestEncodeBase64(t *testing.T) {
+	util := NewEncodingUtil()
+
+	if result := util.EncodeBase64(sample); result != "+/+/AQI=" {
+		t.Errorf("EncodeBase64() = %q, want %q", result, "+/+/AQI=")
+	}
+	if result := util.EncodeBase64URL(sample); result != "-_-_AQI" {
+		t.Errorf("EncodeBase64URL() = %q, want %q", result, "-_-_AQI")
+	}
+}
+
+// =================== Test Hex ===================
+
+func TestHex(t *testing.T) {
+	util := NewEncodingUtil()
+
+	if result := util.EncodeHex(sample); result != "fbffbf0102" {
+		t.Errorf("EncodeHex() = %q, want fbffbf0102", result)
+	}
+
+	for _, input := range []string{"fbffbf0102", "FBFFBF0102", "0xfbffbf0102", " fbffbf0102\n"} {
+		result, err := util.DecodeHex(input)
+		if err != nil || !bytes.Equal(result, sample) {
+			t.Errorf("DecodeHex(%q) = %x, %v; want %x", input, result, err, sample)
+		}
+	}
+	if _, err := util.DecodeHex("abc"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("DecodeHex(odd length) error = %v, want ErrInvalidInput", err)
+	}
+}
+
+// =================== Test Generic Encoding ===================
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	util := NewEncodingUtil()
+
+	for _, enc := range []Encoding{Base64Std, Base64URL, Base64RawStd, Base64RawURL, Hex} {
+		t.Run(enc.String(), func(t *testing.T) {
+			encoded, err := util.Encode(sample, enc)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			decoded, err := util.Decode(encoded, enc)
+			if err != nil || !bytes.Equal(decoded, sample) {
+				t.Errorf("Decode(Encode()) = %x, %v; want %x", decoded, err, sample)
+			}
+		})
+	}
+
+	if _, err := util.Encode(sample, Encoding(99)); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("Encode(unknown) error = %v, want ErrUnknownEncoding", err)
+	}
+	if _, err := util.Decode("", Encoding(99)); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("Decode(unknown) error = %v, want ErrUnknownEncoding", err)
+	}
+}
+
+func TestDetectAndDecode(t *testing.T) {
+	util := NewEncodingUtil()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []byte
+		encoding Encoding
+	}{
+		{"hex", "68656c6c6f", []byte("hello"), Hex},
+		{"hex with prefix", "0x68656C6C6F", []byte("hello"), Hex},
+		{"base64 std", "aGVsbG8=", []byte("hello"), Base64Std},
+		{"base64 raw", "aGVsbG8", []byte("hello"), Base64RawStd},
+		{"base64 url", "-_-_AQI", sample, Base64RawURL},
+		{"empty", "", []byte{}, Base64Std},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, enc, err := util.DetectAndDecode(tt.input)
+			if err != nil {
+				t.Fatalf("DetectAndDecode(%q) error = %v", tt.input, err)
+			}
+			if !bytes.Equal(result, tt.expected) || enc != tt.encoding {
+				t.Errorf("DetectAndDecode(%q) = %x, %v; want %x, %v", tt.input, result, enc, tt.expected, tt.encoding)
+			}
+		})
+	}
+
+	if _, _, err := util.DetectAndDecode("not valid!"); !errors.Is(err, ErrInvalidInput) {
+		t.Errorf("DetectAndDecode(invalid) error = %v, want ErrInvalidInput", err)
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	util := NewEncodingUtil()
+
+	tests := []struct {
+		name     string
+		input    string
+		encoding Encoding
+		minConf  float64
+	}{
+		{"hex", "deadbeefcafe0123", Hex, 0.6},
+		{"hex with prefix", "0xDEADBEEF", Hex, 0.95},
+		{"padded base64", "SGVsbG8gV29ybGQ=", Base64Std, 0.9},
+		{"jwt segment", "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9", Base64RawStd, 0.8},
+		{"url-safe base64", "3q2-7wAB_w8QIDBAUGBwgJ", Base64RawURL, 0.6},
+		{"wrapped base64", "SGVsbG8g\nV29ybGQ=", Base64Std, 0.9},
+		{"word", "password", Plain, 0.6},
+		{"identifier", "my-file_name", Plain, 0.5},
+		{"sentence", "hello world", Plain, 0.9},
+		{"punctuation", "Hello, World!", Plain, 0.99},
+		{"binary", "\x00\xff\x10", Plain, 0.99},
+		{"empty", "", Plain, 0.99},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detection := util.DetectEncoding([]byte(tt.input))
+			if detection.Encoding != tt.encoding || detection.Confidence < tt.minConf {
+				t.Errorf("DetectEncoding(%q) = %v (%.2f), want %v with confidence >= %.2f; candidates %v",
+					tt.input, detection.Encoding, detection.Confidence, tt.encoding, tt.minConf, detection.Candidates)
+			}
+			if detection.Candidates[0].Encoding != detection.Encoding || !hasPlain(detection.Candidates) {
+				t.Errorf("DetectEncoding(%q) candidates = %v, want most likely first and Plain included", tt.input, detection.Candidates)
+			}
+		})
+	}
+}
+
+func hasPlain(candidates []Candidate) bool {
+	for _, c := range candidates {
+		if c.Encoding == Plain {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDecodeAny(t *testing.T) {
+	util := NewEncodingUtil()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []byte
+		encoding Encoding
+	}{
+		{"hex", "68656c6c6f", []byte("hello"), Hex},
+		{"base64", "aGVsbG8gd29ybGQ=", []byte("hello world"), Base64Std},
+		{"plain", "hello world", []byte("hello world"), Plain},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, detection, err := util.DecodeAny([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("DecodeAny(%q) error = %v", tt.input, err)
+			}
+			if !bytes.Equal(result, tt.expected) || detection.Encoding != tt.encoding {
+				t.Errorf("DecodeAny(%q) = %q, %v; want %q, %v", tt.input, result, detection.Encoding, tt.expected, tt.encoding)
+			}
+		})
+	}
+
+	input := []byte("plain text")
+	result, _, _ := util.DecodeAny(input)
+	result[0] = 'X'
+	if input[0] != 'p' {
+		t.Error("DecodeAny() should return a copy of plain input")
+	}
+
+	if out, err := util.Encode([]byte("as is"), Plain); err != nil || out != "as is" {
+		t.Errorf("Encode(Plain) = %q, %v, want pass-through", out, err)
+	}
+}
+
+// =================== Test Streaming ===================
+
+func TestStreaming(t *testing.T) {
+	util := NewEncodingUtil()
+	payload := bytes.Repeat([]byte("stream me "), 1000)
+
+	for _, enc := range []Encoding{Base64Std, Base64RawURL, Hex} {
+		t.Run(enc.String(), func(t *testing.T) {
+			var buf bytes.Buffer
+			encoder, err := util.NewEncoder(&buf, enc)
+			if err != nil {
+				t.Fatalf("NewEncoder() error = %v", err)
+			}
+			if _, err := io.Copy(encoder, bytes.NewReader(payload)); err != nil {
+				t.Fatalf("encode error = %v", err)
+			}
+			if err := encoder.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			expected, _ := util.Encode(payload, enc)
+			if buf.String() != expected {
+				t.Errorf("streamed encoding differs from Encode()")
+			}
+
+			decoder, err := util.NewDecoder(strings.NewReader(buf.String()), enc)
+			if err != nil {
+				t.Fatalf("NewDecoder() error = %v", err)
+			}
+			decoded, err := io.ReadAll(decoder)
+			if err != nil || !bytes.Equal(decoded, payload) {
+				t.Errorf("streamed decode = %d bytes, %v; want %d bytes", len(decoded), err, len(payload))
+			}
+		})
+	}
+
+	if _, err := util.NewEncoder(io.Discard, Encoding(99)); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("NewEncoder(unknown) error = %v, want ErrUnknownEncoding", err)
+	}
+	if _, err := util.NewDecoder(strings.NewReader(""), Encoding(99)); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("NewDecoder(unknown) error = %v, want ErrUnknownEncoding", err)
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkDecodeBase64(b *testing.B) {
+	util := NewEncodingUtil()
+	encoded := util.EncodeBase64(bytes.Repeat([]byte("benchmark"), 100))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = util.DecodeBase64(encoded)
+	}
+}
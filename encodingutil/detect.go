@@ -0,0 +1,180 @@
+package encodingutil
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Detection reports how DetectEncoding classified its input
+type Detection struct {
+	// Encoding is the most likely encoding; Plain when the input does not look encoded
+	Encoding Encoding
+
+	// Confidence in Encoding, from 0 to 1
+	Confidence float64
+
+	// Candidates lists every encoding the input could be in, most likely first; Plain is always included
+	Candidates []Candidate
+}
+
+// Candidate is one possible encoding of sniffed input with its confidence
+type Candidate struct {
+	Encoding   Encoding
+	Confidence float64
+}
+
+// DetectEncoding sniffs whether b is hex, one of the base64 variants or plain text
+// The input is valid in every listed candidate; confidence weighs the evidence for each, such as
+// padding, alphabet-specific characters, character variety and whether the decoded bytes are text.
+// Short or purely alphabetic input is often valid base64 by accident, so it leans towards Plain.
+func (e *EncodingUtil) DetectEncoding(b []byte) Detection {
+	text := strings.TrimSpace(string(b))
+	s := stripWhitespace(text)
+	if s == "" || !utf8.ValidString(s) || !isASCIIPrintable(s) {
+		return plainDetection(nil)
+	}
+
+	var candidates []Candidate
+	if isHex(s) {
+		candidates = append(candidates, Candidate{Encoding: Hex, Confidence: hexConfidence(s)})
+	}
+	if enc, err := detectBase64(s); err == nil {
+		if decoded, err := e.Decode(s, enc); err == nil {
+			candidates = append(candidates, Candidate{Encoding: enc, Confidence: base64Confidence(s, enc, decoded)})
+		}
+	}
+
+	// Encoded blobs may wrap lines but do not contain spaces between words
+	if strings.ContainsAny(text, " \t") {
+		for i := range candidates {
+			candidates[i].Confidence *= 0.25
+		}
+	}
+	return plainDetection(candidates)
+}
+
+// DecodeAny decodes b with the encoding DetectEncoding considers most likely
+// Plain input is returned unchanged (as a copy), so DecodeAny can be applied to tokens and blobs of
+// unknown origin alike; check the returned Detection when a wrong guess matters.
+func (e *EncodingUtil) DecodeAny(b []byte) ([]byte, Detection, error) {
+	detection := e.DetectEncoding(b)
+	if detection.Encoding == Plain {
+		return append([]byte{}, b...), detection, nil
+	}
+	data, err := e.Decode(stripWhitespace(string(b)), detection.Encoding)
+	return data, detection, err
+}
+
+// plainDetection adds the Plain candidate, sorts the candidates and picks the most likely
+func plainDetection(candidates []Candidate) Detection {
+	best := 0.0
+	for _, c := range candidates {
+		if c.Confidence > best {
+			best = c.Confidence
+		}
+	}
+	candidates = append(candidates, Candidate{Encoding: Plain, Confidence: clampConfidence(1 - best)})
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Confidence > candidates[j].Confidence })
+	return Detection{Encoding: candidates[0].Encoding, Confidence: candidates[0].Confidence, Candidates: candidates}
+}
+
+// hexConfidence grows with length and drops for mixed-case digits; a "0x" prefix is near certain
+func hexConfidence(s string) float64 {
+	if len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return 0.99
+	}
+	confidence := 0.6 + 0.35*lengthFactor(s, 32)
+	if strings.ContainsAny(s, "abcdef") && strings.ContainsAny(s, "ABCDEF") {
+		confidence *= 0.8
+	}
+	return clampConfidence(confidence)
+}
+
+// base64Confidence weighs padding, alphabet-specific characters, character variety and decoded text
+func base64Confidence(s string, enc Encoding, decoded []byte) float64 {
+	confidence := 0.4
+	if enc == Base64Std || enc == Base64URL {
+		confidence += 0.3 // valid padding rarely happens by accident
+	}
+	if strings.ContainsAny(s, "+/-_") {
+		confidence += 0.15
+	}
+	// Encoded bytes mix cases and digits; identifiers and words usually do not
+	switch classes := charClasses(s); {
+	case classes >= 3:
+		confidence += 0.2
+	case classes == 2:
+		confidence += 0.1
+	default:
+		confidence -= 0.1
+	}
+	switch {
+	case len(s) < 8:
+		confidence -= 0.2
+	case len(s) >= 32:
+		confidence += 0.1
+	}
+	if len(decoded) > 0 && utf8.Valid(decoded) && isPrintable(string(decoded)) {
+		confidence += 0.15
+	}
+	return clampConfidence(confidence)
+}
+
+// charClasses counts which of upper-case letters, lower-case letters and digits occur in s
+func charClasses(s string) int {
+	var upper, lower, digit int
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case 'A' <= c && c <= 'Z':
+			upper = 1
+		case 'a' <= c && c <= 'z':
+			lower = 1
+		case '0' <= c && c <= '9':
+			digit = 1
+		}
+	}
+	return upper + lower + digit
+}
+
+// lengthFactor scales len(s) to 0..1, reaching 1 at full
+func lengthFactor(s string, full int) float64 {
+	if len(s) >= full {
+		return 1
+	}
+	return float64(len(s)) / float64(full)
+}
+
+// isASCIIPrintable reports whether s consists of printable ASCII characters only
+func isASCIIPrintable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// isPrintable reports whether s is text: printable runes and common whitespace
+func isPrintable(s string) bool {
+	for _, r := range s {
+		if !unicode.IsPrint(r) && r != '\n' && r != '\r' && r != '\t' {
+			return false
+		}
+	}
+	return true
+}
+
+// clampConfidence rounds a confidence to two decimals within 0.01 and 0.99; sniffing is never certain
+func clampConfidence(confidence float64) float64 {
+	confidence = math.Round(confidence*100) / 100
+	if confidence < 0.01 {
+		return 0.01
+	}
+	if confidence > 0.99 {
+		return 0.99
+	}
+	return confidence
+}
@@ -0,0 +1,11 @@
+package encodingutil
+
+import "errors"
+
+var (
+	// ErrInvalidInput is returned when input is not valid for the requested or detected encoding
+	ErrInvalidInput = errors.New("invalid encoded input")
+
+	// ErrUnknownEncoding is returned for an Encoding value this package does not support
+	ErrUnknownEncoding = errors.New("unknown encoding")
+)
@@ -0,0 +1,189 @@
+package errorutil
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"runtime"
+)
+
+// ErrorConfig holds configuration for error construction and mapping
+type ErrorConfig struct {
+	// CaptureStack records a stack trace when errors are created or wrapped
+	CaptureStack bool
+
+	// StatusMapping overrides the default HTTP status for specific codes
+	StatusMapping map[Code]int
+}
+
+// ErrorClient defines the interface for creating and inspecting typed errors
+type ErrorClient interface {
+	// Construction
+	New(code Code, message string) error
+	Wrap(err error, code Code, message string) error
+	WithCode(err error, code Code) error
+	WithDetails(err error, details map[string]any) error
+
+	// Inspection
+	Is(err, target error) bool
+	As(err error, target any) bool
+	CodeOf(err error) Code
+	DetailsOf(err error) map[string]any
+	StackTrace(err error) []string
+
+	// HTTP mapping
+	HTTPStatus(err error) int
+	CodeForStatus(status int) Code
+	ToResponse(err error) (int, ErrorResponse)
+	WriteError(w http.ResponseWriter, err error)
+}
+
+// ErrorUtil provides helpers around the shared Error type
+type ErrorUtil struct {
+	captureStack  bool
+	statusMapping map[Code]int
+}
+
+// NewErrorUtil creates a new error utility instance
+// Pass nil for config to use defaults (no stack capture, default status mapping)
+func NewErrorUtil(config *ErrorConfig) ErrorClient {
+	util := &ErrorUtil{statusMapping: make(map[Code]int, len(defaultStatusMapping))}
+	for code, status := range defaultStatusMapping {
+		util.statusMapping[code] = status
+	}
+	if config != nil {
+		util.captureStack = config.CaptureStack
+		for code, status := range config.StatusMapping {
+			util.statusMapping[code] = status
+		}
+	}
+	return util
+}
+
+// New creates an *Error with the given code and message
+func (u *ErrorUtil) New(code Code, message string) error {
+	return &Error{Code: code, Message: message, stack: u.callers()}
+}
+
+// Wrap annotates err with a code and message, keeping err as the cause
+// Returns nil if err is nil.
+func (u *ErrorUtil) Wrap(err error, code Code, message string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Code: code, Message: message, Cause: err, stack: u.callers()}
+}
+
+// WithCode returns err with its code replaced (for an *Error) or wrapped with the code otherwise
+// Returns nil if err is nil.
+func (u *ErrorUtil) WithCode(err error, code Code) error {
+	if err == nil {
+		return nil
+	}
+	if typed, ok := err.(*Error); ok {
+		copied := typed.clone()
+		copied.Code = code
+		return copied
+	}
+	return &Error{Code: code, Cause: err, stack: u.callers()}
+}
+
+// WithDetails merges details into err (for an *Error) or wraps err with the details otherwise
+// The original error is never modified. Returns nil if err is nil.
+func (u *ErrorUtil) WithDetails(err error, details map[string]any) error {
+	if err == nil {
+		return nil
+	}
+	var copied *Error
+	if typed, ok := err.(*Error); ok {
+		copied = typed.clone()
+	} else {
+		copied = &Error{Code: u.CodeOf(err), Cause: err, stack: u.callers()}
+	}
+	if copied.Details == nil {
+		copied.Details = make(map[string]any, len(details))
+	}
+	for k, v := range details {
+		copied.Details[k] = v
+	}
+	return copied
+}
+
+// Is reports whether any error in err's chain matches target (see errors.Is)
+func (u *ErrorUtil) Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As finds the first error in err's chain that matches target (see errors.As)
+func (u *ErrorUtil) As(err error, target any) bool {
+	return errors.As(err, target)
+}
+
+// CodeOf returns the code of the first Coder in err's chain
+// Context cancellation and deadline errors map to CodeCanceled and CodeDeadlineExceeded,
+// any other error maps to CodeUnknown, and nil returns an empty code.
+func (u *ErrorUtil) CodeOf(err error) Code {
+	if err == nil {
+		return ""
+	}
+	var coder Coder
+	if errors.As(err, &coder) {
+		return coder.ErrorCode()
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeDeadlineExceeded
+	case errors.Is(err, context.Canceled):
+		return CodeCanceled
+	}
+	return CodeUnknown
+}
+
+// DetailsOf merges the details of every *Error in err's chain, outer errors taking precedence
+func (u *ErrorUtil) DetailsOf(err error) map[string]any {
+	details := map[string]any{}
+	var chain []*Error
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		if typed, ok := current.(*Error); ok {
+			chain = append(chain, typed)
+		}
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		for k, v := range chain[i].Details {
+			details[k] = v
+		}
+	}
+	return details
+}
+
+// StackTrace returns the stack captured by the innermost *Error in err's chain that has one
+func (u *ErrorUtil) StackTrace(err error) []string {
+	var trace []string
+	for current := err; current != nil; current = errors.Unwrap(current) {
+		if typed, ok := current.(*Error); ok && len(typed.stack) > 0 {
+			trace = typed.StackTrace()
+		}
+	}
+	return trace
+}
+
+// HTTPStatus maps err's code to an HTTP status code, returning 200 for nil
+func (u *ErrorUtil) HTTPStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	if status, ok := u.statusMapping[u.CodeOf(err)]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// callers captures the caller's stack when stack capture is enabled
+func (u *ErrorUtil) callers() []uintptr {
+	if !u.captureStack {
+		return nil
+	}
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs) // skip runtime.Callers, callers and the ErrorUtil method
+	return pcs[:n]
+}
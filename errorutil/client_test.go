@@ -0,0 +1,303 @@
+package errorutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNewErrorUtil(t *testing.T) {
+	util := NewErrorUtil(nil)
+	if util == nil {
+		t.Error("NewErrorUtil() returned nil")
+	}
+}
+
+func TestError_Error(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      *Error
+		expected string
+	}{
+		{"code only", &Error{Code: CodeNotFound}, "NOT_FOUND"},
+		{"code and message", &Error{Code: CodeNotFound, Message: "user not found"}, "NOT_FOUND: user not found"},
+		{"with cause", &Error{Code: CodeInternal, Message: "query failed", Cause: errors.New("timeout")}, "INTERNAL: query failed: timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.expected {
+				t.Errorf("Error() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNewAndWrap(t *testing.T) {
+	util := NewErrorUtil(nil)
+
+	err := util.New(CodeInvalidArgument, "bad input")
+	if util.CodeOf(err) != CodeInvalidArgument {
+		t.Errorf("CodeOf() = %s, want %s", util.CodeOf(err), CodeInvalidArgument)
+	}
+
+	cause := errors.New("connection refused")
+	wrapped := util.Wrap(cause, CodeUnavailable, "db down")
+	if !errors.Is(wrapped, cause) {
+		t.Error("Expected wrapped error to match its cause")
+	}
+	if util.CodeOf(wrapped) != CodeUnavailable {
+		t.Errorf("CodeOf() = %s, want %s", util.CodeOf(wrapped), CodeUnavailable)
+	}
+
+	if util.Wrap(nil, CodeInternal, "x") != nil {
+		t.Error("Wrap(nil) should return nil")
+	}
+}
+
+func TestIsMatchesByCode(t *testing.T) {
+	util := NewErrorUtil(nil)
+	errNotFound := &Error{Code: CodeNotFound}
+
+	err := fmt.Errorf("handler: %w", util.New(CodeNotFound, "user 42"))
+	if !util.Is(err, errNotFound) {
+		t.Error("Expected code-only sentinel to match")
+	}
+	if util.Is(err, &Error{Code: CodeNotFound, Message: "user 7"}) {
+		t.Error("Expected different message not to match")
+	}
+	if util.Is(err, &Error{Code: CodeInternal}) {
+		t.Error("Expected different code not to match")
+	}
+
+	var typed *Error
+	if !util.As(err, &typed) || typed.Message != "user 42" {
+		t.Errorf("As() failed to extract *Error, got %v", typed)
+	}
+}
+
+func TestWithCode(t *testing.T) {
+	util := NewErrorUtil(nil)
+
+	original := util.New(CodeInternal, "boom")
+	recoded := util.WithCode(original, CodeUnavailable)
+	if util.CodeOf(recoded) != CodeUnavailable {
+		t.Errorf("CodeOf() = %s, want %s", util.CodeOf(recoded), CodeUnavailable)
+	}
+	if util.CodeOf(original) != CodeInternal {
+		t.Error("WithCode must not modify the original error")
+	}
+
+	plain := errors.New("plain")
+	coded := util.WithCode(plain, CodeNotFound)
+	if util.CodeOf(coded) != CodeNotFound || !errors.Is(coded, plain) {
+		t.Errorf("Expected plain error to be wrapped with code, got %v", coded)
+	}
+
+	if util.WithCode(nil, CodeNotFound) != nil {
+		t.Error("WithCode(nil) should return nil")
+	}
+}
+
+func TestWithDetails(t *testing.T) {
+	util := NewErrorUtil(nil)
+
+	inner := util.WithDetails(util.New(CodeInvalidArgument, "bad"), map[string]any{"field": "email", "reason": "format"})
+	outer := util.WithDetails(fmt.Errorf("wrapped: %w", inner), map[string]any{"reason": "outer"})
+
+	details := util.DetailsOf(outer)
+	if details["field"] != "email" || details["reason"] != "outer" {
+		t.Errorf("DetailsOf() = %v, expected merged details with outer precedence", details)
+	}
+	if util.CodeOf(outer) != CodeInvalidArgument {
+		t.Errorf("Expected wrapping to keep the inner code, got %s", util.CodeOf(outer))
+	}
+	if util.WithDetails(nil, map[string]any{"a": 1}) != nil {
+		t.Error("WithDetails(nil) should return nil")
+	}
+}
+
+func TestCodeOf(t *testing.T) {
+	util := NewErrorUtil(nil)
+
+	tests := []struct {
+		name     string
+		err      error
+		expected Code
+	}{
+		{"nil", nil, ""},
+		{"plain error", errors.New("x"), CodeUnknown},
+		{"deadline", fmt.Errorf("call: %w", context.DeadlineExceeded), CodeDeadlineExceeded},
+		{"canceled", context.Canceled, CodeCanceled},
+		{"custom coder", customCoderError{}, CodeResourceExhausted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := util.CodeOf(tt.err); got != tt.expected {
+				t.Errorf("CodeOf() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+type customCoderError struct{}
+
+func (customCoderError) Error() string   { return "quota" }
+func (customCoderError) ErrorCode() Code { return CodeResourceExhausted }
+
+func TestHTTPStatus(t *testing.T) {
+	util := NewErrorUtil(nil)
+
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{nil, http.StatusOK},
+		{util.New(CodeNotFound, ""), http.StatusNotFound},
+		{util.New(CodeInvalidArgument, ""), http.StatusBadRequest},
+		{util.New(CodeUnauthenticated, ""), http.StatusUnauthorized},
+		{util.New(Code("CUSTOM"), ""), http.StatusInternalServerError},
+		{errors.New("plain"), http.StatusInternalServerError},
+		{context.DeadlineExceeded, http.StatusGatewayTimeout},
+	}
+
+	for _, tt := range tests {
+		if got := util.HTTPStatus(tt.err); got != tt.expected {
+			t.Errorf("HTTPStatus(%v) = %d, want %d", tt.err, got, tt.expected)
+		}
+	}
+
+	custom := NewErrorUtil(&ErrorConfig{StatusMapping: map[Code]int{CodeNotFound: http.StatusGone}})
+	if got := custom.HTTPStatus(custom.New(CodeNotFound, "")); got != http.StatusGone {
+		t.Errorf("Expected overridden status 410, got %d", got)
+	}
+
+	if got := (&Error{Code: CodeConflict}).HTTPStatus(); got != http.StatusConflict {
+		t.Errorf("Error.HTTPStatus() = %d, want 409", got)
+	}
+}
+
+func TestCodeForStatus(t *testing.T) {
+	util := NewErrorUtil(nil)
+
+	tests := []struct {
+		status   int
+		expected Code
+	}{
+		{http.StatusNotFound, CodeNotFound},
+		{http.StatusConflict, CodeConflict},
+		{http.StatusTooManyRequests, CodeResourceExhausted},
+		{http.StatusInternalServerError, CodeInternal},
+		{http.StatusGatewayTimeout, CodeDeadlineExceeded},
+		{http.StatusBadGateway, CodeUnavailable},
+		{http.StatusTeapot, CodeInvalidArgument},
+		{599, CodeInternal},
+		{http.StatusOK, CodeUnknown},
+	}
+
+	for _, tt := range tests {
+		if got := util.CodeForStatus(tt.status); got != tt.expected {
+			t.Errorf("CodeForStatus(%d) = %s, want %s", tt.status, got, tt.expected)
+		}
+	}
+
+	custom := NewErrorUtil(&ErrorConfig{StatusMapping: map[Code]int{CodeNotFound: http.StatusGone}})
+	if got := custom.CodeForStatus(http.StatusGone); got != CodeNotFound {
+		t.Errorf("CodeForStatus(410) with overridden mapping = %s, want NOT_FOUND", got)
+	}
+}
+
+func TestStackTrace(t *testing.T) {
+	withoutStack := NewErrorUtil(nil)
+	if trace := withoutStack.StackTrace(withoutStack.New(CodeInternal, "x")); trace != nil {
+		t.Errorf("Expected no stack trace by default, got %v", trace)
+	}
+
+	util := NewErrorUtil(&ErrorConfig{CaptureStack: true})
+	err := util.New(CodeInternal, "x")
+	trace := util.StackTrace(fmt.Errorf("wrapped: %w", err))
+	if len(trace) == 0 {
+		t.Fatal("Expected a captured stack trace")
+	}
+	if !strings.Contains(trace[0], "TestStackTrace") {
+		t.Errorf("Expected first frame to be the caller, got %s", trace[0])
+	}
+}
+
+type invalidFieldsError struct{}
+
+func (invalidFieldsError) Error() string   { return "email is invalid" }
+func (invalidFieldsError) ErrorCode() Code { return CodeInvalidArgument }
+func (invalidFieldsError) FieldErrors() []FieldError {
+	return []FieldError{{Field: "email", Message: "invalid"}}
+}
+
+func TestToResponse(t *testing.T) {
+	util := NewErrorUtil(nil)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   Code
+		wantMsg    string
+		wantFields int
+	}{
+		{"typed error", util.Wrap(errors.New("sql: no rows"), CodeNotFound, "user not found"), http.StatusNotFound, CodeNotFound, "user not found", 0},
+		{"outer message wins", util.Wrap(util.New(CodeNotFound, "inner"), CodeNotFound, "outer"), http.StatusNotFound, CodeNotFound, "outer", 0},
+		{"internal cause hidden", errors.New("dial tcp 10.0.0.1:5432: refused"), http.StatusInternalServerError, CodeUnknown, "Internal Server Error", 0},
+		{"deadline", fmt.Errorf("query: %w", context.DeadlineExceeded), http.StatusGatewayTimeout, CodeDeadlineExceeded, "Gateway Timeout", 0},
+		{"client error text kept", fmt.Errorf("decode: %w", invalidFieldsError{}), http.StatusBadRequest, CodeInvalidArgument, "decode: email is invalid", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, resp := util.ToResponse(tt.err)
+			if status != tt.wantStatus || resp.Error.Code != tt.wantCode || resp.Error.Message != tt.wantMsg || len(resp.Error.Fields) != tt.wantFields {
+				t.Errorf("ToResponse() = %d %+v, want %d %s %q with %d fields", status, resp.Error, tt.wantStatus, tt.wantCode, tt.wantMsg, tt.wantFields)
+			}
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	util := NewErrorUtil(nil)
+	err := util.WithDetails(util.New(CodeResourceExhausted, "quota exceeded"), map[string]any{"limit": 100})
+
+	rec := httptest.NewRecorder()
+	util.WriteError(rec, err)
+	if rec.Code != http.StatusTooManyRequests {
+		t.Errorf("WriteError() status = %d, want 429", rec.Code)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	var body ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("response is not JSON: %v", err)
+	}
+	if body.Error.Code != CodeResourceExhausted || body.Error.Message != "quota exceeded" || body.Error.Details["limit"] != float64(100) {
+		t.Errorf("WriteError() body = %+v", body.Error)
+	}
+	if strings.Contains(rec.Body.String(), `"fields"`) {
+		t.Errorf("empty fields should be omitted: %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	util.WriteError(rec, invalidFieldsError{})
+	if !strings.Contains(rec.Body.String(), `"fields":[{"field":"email","message":"invalid"}]`) {
+		t.Errorf("WriteError() body = %s, want the field errors", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	util.WriteError(rec, nil)
+	if rec.Body.Len() != 0 || rec.Code != http.StatusOK {
+		t.Errorf("WriteError(nil) wrote %d %q, want nothing", rec.Code, rec.Body.String())
+	}
+}
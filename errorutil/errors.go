@@ -0,0 +1,133 @@
+package errorutil
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// Code identifies a category of error shared across services
+type Code string
+
+// Standard error codes
+const (
+	CodeUnknown            Code = "UNKNOWN"
+	CodeInvalidArgument    Code = "INVALID_ARGUMENT"
+	CodeNotFound           Code = "NOT_FOUND"
+	CodeAlreadyExists      Code = "ALREADY_EXISTS"
+	CodeConflict           Code = "CONFLICT"
+	CodePermissionDenied   Code = "PERMISSION_DENIED"
+	CodeUnauthenticated    Code = "UNAUTHENTICATED"
+	CodeFailedPrecondition Code = "FAILED_PRECONDITION"
+	CodeResourceExhausted  Code = "RESOURCE_EXHAUSTED"
+	CodeCanceled           Code = "CANCELED"
+	CodeDeadlineExceeded   Code = "DEADLINE_EXCEEDED"
+	CodeUnavailable        Code = "UNAVAILABLE"
+	CodeNotImplemented     Code = "NOT_IMPLEMENTED"
+	CodeInternal           Code = "INTERNAL"
+)
+
+// defaultStatusMapping maps error codes to HTTP status codes
+var defaultStatusMapping = map[Code]int{
+	CodeUnknown:            http.StatusInternalServerError,
+	CodeInvalidArgument:    http.StatusBadRequest,
+	CodeNotFound:           http.StatusNotFound,
+	CodeAlreadyExists:      http.StatusConflict,
+	CodeConflict:           http.StatusConflict,
+	CodePermissionDenied:   http.StatusForbidden,
+	CodeUnauthenticated:    http.StatusUnauthorized,
+	CodeFailedPrecondition: http.StatusPreconditionFailed,
+	CodeResourceExhausted:  http.StatusTooManyRequests,
+	CodeCanceled:           499, // client closed request
+	CodeDeadlineExceeded:   http.StatusGatewayTimeout,
+	CodeUnavailable:        http.StatusServiceUnavailable,
+	CodeNotImplemented:     http.StatusNotImplemented,
+	CodeInternal:           http.StatusInternalServerError,
+}
+
+// Coder is implemented by errors that carry an error Code
+// Error types from other packages can implement it to take part in the shared taxonomy.
+type Coder interface {
+	ErrorCode() Code
+}
+
+// Error is a typed error carrying a code, message, structured details and an optional cause
+// It contains an optional stack trace captured when the error was created.
+type Error struct {
+	Code    Code
+	Message string
+	Details map[string]any
+	Cause   error
+
+	stack []uintptr
+}
+
+// Error implements the error interface for Error
+func (e *Error) Error() string {
+	parts := []string{string(e.Code)}
+	if e.Message != "" {
+		parts = append(parts, e.Message)
+	}
+	if e.Cause != nil {
+		parts = append(parts, e.Cause.Error())
+	}
+	return strings.Join(parts, ": ")
+}
+
+// Unwrap returns the underlying cause so errors.Is/As can traverse the chain
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether target is an *Error with the same code (and message, when target sets one)
+// This lets code-only sentinels such as &Error{Code: CodeNotFound} match any NOT_FOUND error.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.Code == e.Code && (t.Message == "" || t.Message == e.Message)
+}
+
+// ErrorCode implements Coder
+func (e *Error) ErrorCode() Code {
+	return e.Code
+}
+
+// HTTPStatus returns the default HTTP status code for the error's code
+func (e *Error) HTTPStatus() int {
+	if status, ok := defaultStatusMapping[e.Code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// StackTrace returns the captured stack as "function file:line" entries, or nil if none was captured
+func (e *Error) StackTrace() []string {
+	if len(e.stack) == 0 {
+		return nil
+	}
+	frames := runtime.CallersFrames(e.stack)
+	var trace []string
+	for {
+		frame, more := frames.Next()
+		trace = append(trace, fmt.Sprintf("%s %s:%d", frame.Function, frame.File, frame.Line))
+		if !more {
+			break
+		}
+	}
+	return trace
+}
+
+// clone returns a shallow copy of the error with its own details map
+func (e *Error) clone() *Error {
+	copied := *e
+	if e.Details != nil {
+		copied.Details = make(map[string]any, len(e.Details))
+		for k, v := range e.Details {
+			copied.Details[k] = v
+		}
+	}
+	return &copied
+}
@@ -0,0 +1,108 @@
+package errorutil
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// FieldError describes a problem with a single input field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// FieldErrorer is implemented by validation errors that report per-field problems
+// Validation helpers in other packages implement it so WriteError can list the offending fields.
+type FieldErrorer interface {
+	FieldErrors() []FieldError
+}
+
+// ErrorResponse is the JSON envelope written by WriteError, e.g. {"error": {"code": "NOT_FOUND", ...}}
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody is the error object inside ErrorResponse
+type ErrorBody struct {
+	Code    Code           `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+	Fields  []FieldError   `json:"fields,omitempty"`
+}
+
+// ToResponse maps err to an HTTP status and the error envelope, for frameworks that write responses themselves
+// The message is the outermost *Error message; otherwise the error text for client errors (4xx) and the
+// generic status text for server errors, so internal causes are not leaked. Field errors anywhere in the
+// chain are listed under "fields".
+func (u *ErrorUtil) ToResponse(err error) (int, ErrorResponse) {
+	status := u.HTTPStatus(err)
+	if err == nil {
+		return status, ErrorResponse{}
+	}
+
+	body := ErrorBody{Code: u.CodeOf(err)}
+	for current := err; current != nil && body.Message == ""; current = errors.Unwrap(current) {
+		if typed, ok := current.(*Error); ok {
+			body.Message = typed.Message
+		}
+	}
+	if body.Message == "" {
+		if status < http.StatusInternalServerError {
+			body.Message = err.Error()
+		} else {
+			body.Message = http.StatusText(status)
+		}
+	}
+	if details := u.DetailsOf(err); len(details) > 0 {
+		body.Details = details
+	}
+	var fields FieldErrorer
+	if errors.As(err, &fields) {
+		body.Fields = fields.FieldErrors()
+	}
+	return status, ErrorResponse{Error: body}
+}
+
+// statusCodeOrder lists codes in the order CodeForStatus prefers them when several map to one status
+var statusCodeOrder = []Code{
+	CodeInvalidArgument, CodeNotFound, CodeConflict, CodeAlreadyExists, CodePermissionDenied, CodeUnauthenticated,
+	CodeFailedPrecondition, CodeResourceExhausted, CodeCanceled, CodeDeadlineExceeded, CodeUnavailable,
+	CodeNotImplemented, CodeInternal, CodeUnknown,
+}
+
+// CodeForStatus maps an HTTP status back to a code through the status mapping, e.g. for errors that describe
+// an upstream response. A status shared by several codes maps to the more general one (409 CONFLICT,
+// 500 INTERNAL). Unmapped statuses fall back by class: 408 and 504 DEADLINE_EXCEEDED, 502 UNAVAILABLE, other
+// 4xx INVALID_ARGUMENT, other 5xx INTERNAL and anything else UNKNOWN.
+func (u *ErrorUtil) CodeForStatus(status int) Code {
+	for _, code := range statusCodeOrder {
+		if mapped, ok := u.statusMapping[code]; ok && mapped == status {
+			return code
+		}
+	}
+	switch {
+	case status == http.StatusRequestTimeout || status == http.StatusGatewayTimeout:
+		return CodeDeadlineExceeded
+	case status == http.StatusBadGateway:
+		return CodeUnavailable
+	case status >= 400 && status < 500:
+		return CodeInvalidArgument
+	case status >= 500 && status < 600:
+		return CodeInternal
+	}
+	return CodeUnknown
+}
+
+// WriteError writes err as a JSON error envelope with the status code mapped from its code
+// A nil err writes nothing.
+func (u *ErrorUtil) WriteError(w http.ResponseWriter, err error) {
+	if err == nil {
+		return
+	}
+	status, response := u.ToResponse(err)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
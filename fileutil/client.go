@@ -0,0 +1,19 @@
+package fileutil
+
+// FileClient defines the interface for filesystem path safety helpers
+type FileClient interface {
+	// Paths
+	CleanJoin(base, userPath string) (string, error)
+
+	// File names
+	SanitizeFilename(name string) string
+	UniqueFilename(dir, name string) (string, error)
+}
+
+// FileUtil implements FileClient
+type FileUtil struct{}
+
+// NewFileUtil creates a new file utility instance
+func NewFileUtil() FileClient {
+	return &FileUtil{}
+}
@@ -0,0 +1,135 @@
+package fileutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestNewFileUtil(t *testing.T) {
+	if NewFileUtil() == nil {
+		t.Fatal("NewFileUtil() returned nil")
+	}
+}
+
+// =================== Test Paths ===================
+
+func TestCleanJoin(t *testing.T) {
+	util := NewFileUtil()
+	base := filepath.Join("srv", "uploads")
+
+	tests := []struct {
+		name     string
+		userPath string
+		expected string
+		wantErr  bool
+	}{
+		{name: "simple", userPath: "a.txt", expected: filepath.Join(base, "a.txt")},
+		{name: "nested", userPath: "docs/2024/a.txt", expected: filepath.Join(base, "docs", "2024", "a.txt")},
+		{name: "backslashes", userPath: `docs\a.txt`, expected: filepath.Join(base, "docs", "a.txt")},
+		{name: "dot-dot inside base", userPath: "docs/../a.txt", expected: filepath.Join(base, "a.txt")},
+		{name: "empty", userPath: "", expected: base},
+		{name: "dot", userPath: ".", expected: base},
+		{name: "dot-dot name", userPath: "..a.txt", expected: filepath.Join(base, "..a.txt")},
+		{name: "parent", userPath: "..", wantErr: true},
+		{name: "traversal", userPath: "../../etc/passwd", wantErr: true},
+		{name: "nested traversal", userPath: "docs/../../secret", wantErr: true},
+		{name: "backslash traversal", userPath: `..\..\windows`, wantErr: true},
+		{name: "absolute", userPath: "/etc/passwd", wantErr: true},
+		{name: "backslash absolute", userPath: `\etc\passwd`, wantErr: true},
+		{name: "drive letter", userPath: `C:\Windows`, wantErr: true},
+		{name: "drive relative", userPath: "c:evil", wantErr: true},
+		{name: "UNC", userPath: `\\server\share`, wantErr: true},
+		{name: "NUL byte", userPath: "a\x00.txt", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := util.CleanJoin(base, tt.userPath)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsafePath) {
+					t.Errorf("CleanJoin(%q) error = %v, want ErrUnsafePath", tt.userPath, err)
+				}
+				return
+			}
+			if err != nil || got != tt.expected {
+				t.Errorf("CleanJoin(%q) = %q, %v, want %q", tt.userPath, got, err, tt.expected)
+			}
+		})
+	}
+}
+
+// =================== Test File Names ===================
+
+func TestSanitizeFilename(t *testing.T) {
+	util := NewFileUtil()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{name: "plain", input: "report.pdf", expected: "report.pdf"},
+		{name: "unicode kept", input: "résumé 2024.pdf", expected: "résumé 2024.pdf"},
+		{name: "directories dropped", input: "../../etc/passwd", expected: "passwd"},
+		{name: "windows path", input: `C:\Users\bob\photo.jpg`, expected: "photo.jpg"},
+		{name: "reserved characters", input: `a<b>c:d"e|f?g*.txt`, expected: "a_b_c_d_e_f_g_.txt"},
+		{name: "control characters", input: "bad\x00name\n.txt", expected: "bad_name_.txt"},
+		{name: "trimmed dots and spaces", input: "  .hidden. ", expected: "hidden"},
+		{name: "device name", input: "con.txt", expected: "_con.txt"},
+		{name: "device name prefix only", input: "console.txt", expected: "console.txt"},
+		{name: "empty", input: "", expected: "unnamed"},
+		{name: "dot-dot", input: "..", expected: "unnamed"},
+		{name: "only reserved", input: "???", expected: "unnamed"},
+		{name: "trailing slash", input: "dir/", expected: "unnamed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := util.SanitizeFilename(tt.input); got != tt.expected {
+				t.Errorf("SanitizeFilename(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("long names keep the extension", func(t *testing.T) {
+		got := util.SanitizeFilename(strings.Repeat("é", 200) + ".pdf")
+		if len(got) > 255 || !strings.HasSuffix(got, ".pdf") || !strings.HasPrefix(got, "éé") {
+			t.Errorf("SanitizeFilename(long) = %q (%d bytes), want at most 255 bytes ending in .pdf", got, len(got))
+		}
+		if strings.ContainsRune(got, '\uFFFD') {
+			t.Errorf("SanitizeFilename(long) cut a rune in half: %q", got)
+		}
+	})
+}
+
+func TestUniqueFilename(t *testing.T) {
+	util := NewFileUtil()
+	dir := t.TempDir()
+	for _, name := range []string{"report.pdf", "report-1.pdf", "logs.tar.gz", ".env"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o600); err != nil {
+			t.Fatalf("failed to create %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "new.txt", expected: "new.txt"},
+		{input: "report.pdf", expected: "report-2.pdf"},
+		{input: "logs.tar.gz", expected: "logs-1.tar.gz"},
+		{input: ".env", expected: ".env-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := util.UniqueFilename(dir, tt.input)
+			if err != nil || got != tt.expected {
+				t.Errorf("UniqueFilename(%q) = %q, %v, want %q", tt.input, got, err, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package fileutil
+
+import "errors"
+
+var (
+	// ErrUnsafePath is returned when a user-supplied path would resolve outside its base directory
+	ErrUnsafePath = errors.New("path escapes base directory")
+
+	// ErrNoUniqueName is returned when UniqueFilename runs out of numbered candidates
+	ErrNoUniqueName = errors.New("no unused file name found")
+)
@@ -0,0 +1,136 @@
+package fileutil
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxUniqueAttempts bounds how many numbered names UniqueFilename tries
+const maxUniqueAttempts = 10000
+
+// maxFilenameBytes is the file name limit of common filesystems (ext4, APFS, NTFS in UTF-16 units)
+const maxFilenameBytes = 255
+
+// CleanJoin joins a user-supplied relative path (upload names, archive entries, URL paths) onto base
+// Both "/" and "\" separate segments, and ".." segments that stay inside base are resolved. Absolute
+// paths, drive or UNC prefixes, NUL bytes and paths climbing above base return ErrUnsafePath. An empty
+// path returns base. The check is lexical: symlinks already inside base are not resolved.
+func (u *FileUtil) CleanJoin(base, userPath string) (string, error) {
+	if strings.ContainsRune(userPath, 0) {
+		return "", fmt.Errorf("%w: %q contains a NUL byte", ErrUnsafePath, userPath)
+	}
+	slashed := strings.ReplaceAll(userPath, "\\", "/")
+	if path.IsAbs(slashed) || filepath.IsAbs(userPath) || filepath.VolumeName(userPath) != "" || hasDrivePrefix(slashed) {
+		return "", fmt.Errorf("%w: %q is absolute", ErrUnsafePath, userPath)
+	}
+	cleaned := path.Clean("./" + slashed)
+	if cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, userPath)
+	}
+	return filepath.Join(base, filepath.FromSlash(cleaned)), nil
+}
+
+// hasDrivePrefix reports whether p starts with a Windows drive letter such as "C:", on any platform
+func hasDrivePrefix(p string) bool {
+	return len(p) >= 2 && p[1] == ':' && (p[0] >= 'a' && p[0] <= 'z' || p[0] >= 'A' && p[0] <= 'Z')
+}
+
+// SanitizeFilename turns an untrusted name (e.g. from an upload) into a safe single path segment
+// Directory parts are dropped, control and reserved characters (<>:"/\|?*) become "_", leading and
+// trailing dots and spaces are trimmed, Windows device names such as CON or LPT1 get a "_" prefix, and
+// the result is cut to 255 bytes keeping the extension. Names with nothing left become "unnamed".
+func (u *FileUtil) SanitizeFilename(name string) string {
+	name = strings.ReplaceAll(name, "\\", "/")
+	name = name[strings.LastIndex(name, "/")+1:]
+
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r == utf8.RuneError, unicode.IsControl(r), strings.ContainsRune(`<>:"/\|?*`, r):
+			b.WriteByte('_')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	name = strings.Trim(b.String(), ". ")
+	if strings.Trim(name, "_") == "" {
+		return "unnamed"
+	}
+
+	stem := name
+	if i := strings.IndexByte(stem, '.'); i >= 0 {
+		stem = stem[:i]
+	}
+	if isReservedDeviceName(stem) {
+		name = "_" + name
+	}
+	return truncateFilename(name, maxFilenameBytes)
+}
+
+// isReservedDeviceName reports whether stem is a Windows device name, which cannot be used as a file name
+func isReservedDeviceName(stem string) bool {
+	switch strings.ToUpper(strings.TrimSpace(stem)) {
+	case "CON", "PRN", "AUX", "NUL",
+		"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+		"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9":
+		return true
+	}
+	return false
+}
+
+// truncateFilename shortens name to at most limit bytes on a rune boundary, keeping a short extension
+func truncateFilename(name string, limit int) string {
+	if len(name) <= limit {
+		return name
+	}
+	ext := filepath.Ext(name)
+	if len(ext) > 16 {
+		ext = ""
+	}
+	stem := name[:len(name)-len(ext)]
+	cut := limit - len(ext)
+	for cut > 0 && !utf8.RuneStart(stem[cut]) {
+		cut--
+	}
+	return stem[:cut] + ext
+}
+
+// UniqueFilename returns name, or name with a "-1", "-2", ... suffix before its extension, so that it
+// does not exist yet in dir, e.g. "report.pdf" becomes "report-1.pdf" and "logs.tar.gz" "logs-1.tar.gz"
+// The check and a later create are not atomic; create the file with os.O_EXCL and retry when another
+// writer may pick the same name. name is used as given, so sanitize untrusted names first.
+func (u *FileUtil) UniqueFilename(dir, name string) (string, error) {
+	stem, ext := splitExt(name)
+	candidate := name
+	for i := 1; i <= maxUniqueAttempts; i++ {
+		_, err := os.Lstat(filepath.Join(dir, candidate))
+		if errors.Is(err, fs.ErrNotExist) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to check %s: %w", candidate, err)
+		}
+		candidate = fmt.Sprintf("%s-%d%s", stem, i, ext)
+	}
+	return "", fmt.Errorf("%w for %s in %s", ErrNoUniqueName, name, dir)
+}
+
+// splitExt splits name into stem and extension, treating ".tar.*" as one extension and dotfiles as stems
+func splitExt(name string) (string, string) {
+	ext := filepath.Ext(name)
+	if ext == name {
+		return name, ""
+	}
+	stem := strings.TrimSuffix(name, ext)
+	if inner := filepath.Ext(stem); strings.EqualFold(inner, ".tar") && inner != stem {
+		return strings.TrimSuffix(stem, inner), inner + ext
+	}
+	return stem, ext
+}
@@ -0,0 +1,25 @@
+package healthutil
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/mustanish/common-utils/v2/httputil"
+)
+
+// HTTPCheck returns a check that GETs url through an httputil client and expects a 2xx response
+// Retries configured on the client happen within the check's timeout, so keep them short for upstream
+// health endpoints.
+func HTTPCheck(client httputil.HTTPClient, url string) CheckFunc {
+	return func(ctx context.Context) error {
+		resp, err := client.Get(ctx, url, nil)
+		defer client.CloseResponse(resp)
+		if err != nil {
+			return fmt.Errorf("upstream %s unreachable: %w", url, err)
+		}
+		if !client.IsSuccess(resp) {
+			return fmt.Errorf("upstream %s returned status %d", url, resp.StatusCode)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,226 @@
+package healthutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Status is the health of a check or of the whole service, as defined by the health+json format
+type Status string
+
+const (
+	StatusPass Status = "pass" // healthy
+	StatusWarn Status = "warn" // healthy with concerns (a non-critical check failed)
+	StatusFail Status = "fail" // unhealthy (a critical check failed)
+)
+
+// CheckFunc reports a component's health; a non-nil error marks the check as failed
+type CheckFunc func(ctx context.Context) error
+
+// Check is a named health check registered with a Registry
+type Check struct {
+	Name    string
+	Check   CheckFunc
+	Timeout time.Duration // 0 uses the registry's DefaultTimeout
+
+	// Critical checks fail the overall status; other failures only degrade it to warn
+	Critical bool
+
+	// ComponentType is reported as-is, e.g. "datastore", "component" or "system"
+	ComponentType string
+
+	// DependsOn names checks this one relies on, e.g. an API check depending on "dns"; they must be
+	// registered first. Failures are attributed to the failing dependencies at the root of the chain.
+	DependsOn []string
+}
+
+// CheckResult is the outcome of a single check in health+json form
+type CheckResult struct {
+	Status        Status    `json:"status"`
+	ComponentType string    `json:"componentType,omitempty"`
+	ObservedValue int64     `json:"observedValue"`
+	ObservedUnit  string    `json:"observedUnit"`
+	Time          time.Time `json:"time"`
+	Output        string    `json:"output,omitempty"`
+	Critical      bool      `json:"critical"`
+
+	// CausedBy lists the failing dependencies at the root of this check's failure or degradation
+	CausedBy []string `json:"causedBy,omitempty"`
+}
+
+// Report is the overall health of a service in health+json form
+// Checks are keyed by name; each holds a single result, as the format expects arrays.
+type Report struct {
+	Status      Status                   `json:"status"`
+	Version     string                   `json:"version,omitempty"`
+	ServiceID   string                   `json:"serviceId,omitempty"`
+	Description string                   `json:"description,omitempty"`
+	Checks      map[string][]CheckResult `json:"checks,omitempty"`
+
+	// RootCauses lists the failing checks whose dependencies all pass, i.e. where to start looking
+	RootCauses []string `json:"rootCauses,omitempty"`
+}
+
+// HealthConfig holds configuration for a health check registry
+type HealthConfig struct {
+	DefaultTimeout time.Duration
+
+	// Reported in every Report
+	Version     string
+	ServiceID   string
+	Description string
+}
+
+// DefaultHealthConfig returns default configuration
+func DefaultHealthConfig() *HealthConfig {
+	return &HealthConfig{
+		DefaultTimeout: 5 * time.Second,
+	}
+}
+
+// HealthClient defines the interface for registering and evaluating health checks
+type HealthClient interface {
+	Register(check Check) error
+	Evaluate(ctx context.Context) Report
+	Handler() http.Handler
+}
+
+// Registry holds named health checks and evaluates them concurrently
+type Registry struct {
+	config HealthConfig
+
+	mu     sync.RWMutex
+	checks map[string]Check
+}
+
+// NewRegistry creates a new health check registry
+// Pass nil for config to use all defaults, or pass config with only the properties you want to override
+func NewRegistry(config *HealthConfig) HealthClient {
+	defaults := DefaultHealthConfig()
+
+	if config != nil {
+		if config.DefaultTimeout > 0 {
+			defaults.DefaultTimeout = config.DefaultTimeout
+		}
+		defaults.Version = config.Version
+		defaults.ServiceID = config.ServiceID
+		defaults.Description = config.Description
+	}
+
+	return &Registry{config: *defaults, checks: make(map[string]Check)}
+}
+
+// Register adds a check; names must be unique and dependencies must already be registered
+func (r *Registry) Register(check Check) error {
+	if check.Name == "" || check.Check == nil {
+		return ErrInvalidCheck
+	}
+	if check.Timeout <= 0 {
+		check.Timeout = r.config.DefaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.checks[check.Name]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateCheck, check.Name)
+	}
+	for _, dep := range check.DependsOn {
+		if _, exists := r.checks[dep]; !exists {
+			return fmt.Errorf("%w: %s depends on %s", ErrUnknownDependency, check.Name, dep)
+		}
+	}
+	check.DependsOn = append([]string(nil), check.DependsOn...)
+	r.checks[check.Name] = check
+	return nil
+}
+
+// Evaluate runs every check concurrently and aggregates the results
+// The overall status is fail if any critical check failed, warn if any other check failed or was degraded by
+// a failing dependency, and pass otherwise.
+func (r *Registry) Evaluate(ctx context.Context) Report {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	r.mu.RLock()
+	checks := make([]Check, 0, len(r.checks))
+	for _, check := range r.checks {
+		checks = append(checks, check)
+	}
+	r.mu.RUnlock()
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check Check) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:      StatusPass,
+		Version:     r.config.Version,
+		ServiceID:   r.config.ServiceID,
+		Description: r.config.Description,
+		Checks:      make(map[string][]CheckResult, len(checks)),
+	}
+	report.RootCauses = attributeCauses(checks, results)
+	for i, check := range checks {
+		result := results[i]
+		report.Checks[check.Name] = []CheckResult{result}
+		switch {
+		case result.Status == StatusFail && check.Critical:
+			report.Status = StatusFail
+		case result.Status != StatusPass && report.Status == StatusPass:
+			report.Status = StatusWarn
+		}
+	}
+	return report
+}
+
+// runCheck runs a single check under its timeout, recovering panics
+// A check that ignores its context is abandoned once the timeout expires.
+func runCheck(ctx context.Context, check Check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, check.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- &CheckPanicError{Value: recovered}
+			}
+		}()
+		done <- check.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("health check did not complete: %w", ctx.Err())
+	}
+
+	result := CheckResult{
+		Status:        StatusPass,
+		ComponentType: check.ComponentType,
+		ObservedValue: time.Since(start).Milliseconds(),
+		ObservedUnit:  "ms",
+		Time:          start.UTC(),
+		Critical:      check.Critical,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Output = err.Error()
+	}
+	return result
+}
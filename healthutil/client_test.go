@@ -0,0 +1,267 @@
+package healthutil
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/httputil"
+)
+
+func passing(context.Context) error { return nil }
+
+func failing(context.Context) error { return errors.New("connection refused") }
+
+func TestNewRegistry(t *testing.T) {
+	registry := NewRegistry(nil).(*Registry)
+	if registry.config.DefaultTimeout != 5*time.Second {
+		t.Errorf("DefaultTimeout = %v, want 5s", registry.config.DefaultTimeout)
+	}
+
+	custom := NewRegistry(&HealthConfig{Version: "1.2.3"}).(*Registry)
+	if custom.config.Version != "1.2.3" || custom.config.DefaultTimeout != 5*time.Second {
+		t.Errorf("custom config = %+v", custom.config)
+	}
+}
+
+// =================== Test Registration ===================
+
+func TestRegister(t *testing.T) {
+	registry := NewRegistry(nil)
+
+	if err := registry.Register(Check{Name: "db", Check: passing}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if err := registry.Register(Check{Name: "db", Check: passing}); !errors.Is(err, ErrDuplicateCheck) {
+		t.Errorf("Register(duplicate) error = %v, want ErrDuplicateCheck", err)
+	}
+	if err := registry.Register(Check{Name: "", Check: passing}); !errors.Is(err, ErrInvalidCheck) {
+		t.Errorf("Register(no name) error = %v, want ErrInvalidCheck", err)
+	}
+	if err := registry.Register(Check{Name: "nil"}); !errors.Is(err, ErrInvalidCheck) {
+		t.Errorf("Register(nil func) error = %v, want ErrInvalidCheck", err)
+	}
+	if err := registry.Register(Check{Name: "api", Check: passing, DependsOn: []string{"dns"}}); !errors.Is(err, ErrUnknownDependency) {
+		t.Errorf("Register(unknown dependency) error = %v, want ErrUnknownDependency", err)
+	}
+	if err := registry.Register(Check{Name: "api", Check: passing, DependsOn: []string{"db"}}); err != nil {
+		t.Errorf("Register(registered dependency) error = %v", err)
+	}
+}
+
+// =================== Test Evaluation ===================
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		checks   []Check
+		expected Status
+	}{
+		{"no checks", nil, StatusPass},
+		{"all passing", []Check{{Name: "a", Check: passing, Critical: true}, {Name: "b", Check: passing}}, StatusPass},
+		{"non-critical failure", []Check{{Name: "a", Check: passing, Critical: true}, {Name: "cache", Check: failing}}, StatusWarn},
+		{"critical failure", []Check{{Name: "db", Check: failing, Critical: true}, {Name: "cache", Check: failing}}, StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(&HealthConfig{ServiceID: "svc"})
+			for _, check := range tt.checks {
+				if err := registry.Register(check); err != nil {
+					t.Fatalf("Register() error = %v", err)
+				}
+			}
+			report := registry.Evaluate(context.Background())
+			if report.Status != tt.expected {
+				t.Errorf("Evaluate() status = %v, want %v", report.Status, tt.expected)
+			}
+			if report.ServiceID != "svc" || len(report.Checks) != len(tt.checks) {
+				t.Errorf("Evaluate() = %+v, want serviceId and %d checks", report, len(tt.checks))
+			}
+		})
+	}
+}
+
+func TestEvaluateCheckDetails(t *testing.T) {
+	registry := NewRegistry(nil)
+	_ = registry.Register(Check{Name: "db", Check: failing, Critical: true, ComponentType: "datastore"})
+	_ = registry.Register(Check{Name: "slow", Timeout: 20 * time.Millisecond, Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	_ = registry.Register(Check{Name: "stuck", Timeout: 20 * time.Millisecond, Check: func(context.Context) error {
+		time.Sleep(200 * time.Millisecond) // ignores its context
+		return nil
+	}})
+	_ = registry.Register(Check{Name: "panics", Check: func(context.Context) error { panic("boom") }})
+
+	start := time.Now()
+	report := registry.Evaluate(context.Background())
+	if elapsed := time.Since(start); elapsed > 150*time.Millisecond {
+		t.Errorf("Evaluate() took %v, want checks bounded by their timeouts", elapsed)
+	}
+
+	db := report.Checks["db"][0]
+	if db.Status != StatusFail || db.Output != "connection refused" || !db.Critical || db.ComponentType != "datastore" || db.ObservedUnit != "ms" {
+		t.Errorf("db result = %+v", db)
+	}
+	for _, name := range []string{"slow", "stuck"} {
+		if result := report.Checks[name][0]; result.Status != StatusFail || !strings.Contains(result.Output, "deadline exceeded") {
+			t.Errorf("%s result = %+v, want deadline failure", name, result)
+		}
+	}
+	if result := report.Checks["panics"][0]; result.Status != StatusFail || !strings.Contains(result.Output, "boom") {
+		t.Errorf("panics result = %+v, want recovered panic", result)
+	}
+}
+
+func TestEvaluateDependencies(t *testing.T) {
+	registry := NewRegistry(nil)
+	for _, check := range []Check{
+		{Name: "dns", Check: failing},
+		{Name: "db", Check: failing, DependsOn: []string{"dns"}, Critical: true},
+		{Name: "api", Check: failing, DependsOn: []string{"db"}},
+		{Name: "cache", Check: passing, DependsOn: []string{"dns"}},
+		{Name: "disk", Check: failing},
+		{Name: "queue", Check: passing},
+	} {
+		if err := registry.Register(check); err != nil {
+			t.Fatalf("Register(%s) error = %v", check.Name, err)
+		}
+	}
+
+	report := registry.Evaluate(context.Background())
+	if report.Status != StatusFail {
+		t.Errorf("Evaluate() status = %v, want fail for the critical db check", report.Status)
+	}
+	if want := []string{"disk", "dns"}; !reflect.DeepEqual(report.RootCauses, want) {
+		t.Errorf("RootCauses = %v, want %v", report.RootCauses, want)
+	}
+
+	tests := []struct {
+		name     string
+		status   Status
+		causedBy []string
+		output   string
+	}{
+		{"dns", StatusFail, nil, "connection refused"},
+		{"db", StatusFail, []string{"dns"}, "connection refused; failing because dns is failing"},
+		{"api", StatusFail, []string{"dns"}, "connection refused; failing because dns is failing"},
+		{"cache", StatusWarn, []string{"dns"}, "degraded because dns is failing"},
+		{"disk", StatusFail, nil, "connection refused"},
+		{"queue", StatusPass, nil, ""},
+	}
+	for _, tt := range tests {
+		result := report.Checks[tt.name][0]
+		if result.Status != tt.status || !reflect.DeepEqual(result.CausedBy, tt.causedBy) || result.Output != tt.output {
+			t.Errorf("check %s = %s %v %q, want %s %v %q", tt.name, result.Status, result.CausedBy, result.Output, tt.status, tt.causedBy, tt.output)
+		}
+	}
+
+	body, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(body), `"rootCauses":["disk","dns"]`) || !strings.Contains(string(body), `"causedBy":["dns"]`) {
+		t.Errorf("JSON payload missing root cause attribution: %s", body)
+	}
+}
+
+func TestEvaluateDegradedOnly(t *testing.T) {
+	registry := NewRegistry(nil)
+	_ = registry.Register(Check{Name: "dns", Check: failing})
+	_ = registry.Register(Check{Name: "network", Check: failing})
+	_ = registry.Register(Check{Name: "api", Check: passing, DependsOn: []string{"dns", "network"}, Critical: true})
+
+	report := registry.Evaluate(context.Background())
+	if report.Status != StatusWarn {
+		t.Errorf("Evaluate() status = %v, want warn when a critical check is only degraded", report.Status)
+	}
+	if got := report.Checks["api"][0].Output; got != "degraded because dns, network are failing" {
+		t.Errorf("api output = %q", got)
+	}
+}
+
+// =================== Test Handler ===================
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		check          Check
+		expectedStatus int
+		expected       Status
+	}{
+		{"pass", Check{Name: "db", Check: passing, Critical: true}, http.StatusOK, StatusPass},
+		{"warn", Check{Name: "cache", Check: failing}, http.StatusOK, StatusWarn},
+		{"fail", Check{Name: "db", Check: failing, Critical: true}, http.StatusServiceUnavailable, StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(&HealthConfig{Version: "1"})
+			_ = registry.Register(tt.check)
+
+			rec := httptest.NewRecorder()
+			registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("Handler() status code = %d, want %d", rec.Code, tt.expectedStatus)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != ContentType {
+				t.Errorf("Handler() Content-Type = %q, want %q", ct, ContentType)
+			}
+			var body map[string]any
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("Handler() body is not JSON: %v", err)
+			}
+			if body["status"] != string(tt.expected) || body["version"] != "1" {
+				t.Errorf("Handler() body = %v, want status %v", body, tt.expected)
+			}
+			if _, ok := body["checks"].(map[string]any)[tt.check.Name].([]any); !ok {
+				t.Errorf("Handler() body checks = %v, want array under %q", body["checks"], tt.check.Name)
+			}
+		})
+	}
+}
+
+// =================== Test Built-in Checks ===================
+
+func TestHTTPCheck(t *testing.T) {
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer healthy.Close()
+	unhealthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer unhealthy.Close()
+
+	client := httputil.NewHTTPUtil(nil, &httputil.HTTPConfig{RetryOnStatus: []int{}})
+
+	if err := HTTPCheck(client, healthy.URL)(context.Background()); err != nil {
+		t.Errorf("HTTPCheck(healthy) error = %v", err)
+	}
+	if err := HTTPCheck(client, unhealthy.URL)(context.Background()); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("HTTPCheck(unhealthy) error = %v, want status 503", err)
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkEvaluate(b *testing.B) {
+	registry := NewRegistry(nil)
+	for _, name := range []string{"db", "cache", "queue", "search"} {
+		_ = registry.Register(Check{Name: name, Check: passing})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = registry.Evaluate(context.Background())
+	}
+}
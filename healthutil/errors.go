@@ -0,0 +1,27 @@
+package healthutil
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrDuplicateCheck is returned when registering a check name that is already taken
+	ErrDuplicateCheck = errors.New("health check already registered")
+
+	// ErrInvalidCheck is returned when registering a check without a name or function
+	ErrInvalidCheck = errors.New("health check requires a name and a function")
+
+	// ErrUnknownDependency is returned when registering a check that depends on an unregistered check
+	ErrUnknownDependency = errors.New("health check dependency not registered")
+)
+
+// CheckPanicError is reported for a check function that panicked
+type CheckPanicError struct {
+	Value any
+}
+
+// Error implements the error interface for CheckPanicError
+func (e *CheckPanicError) Error() string {
+	return fmt.Sprintf("health check panicked: %v", e.Value)
+}
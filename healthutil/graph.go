@@ -0,0 +1,82 @@
+package healthutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// attributeCauses traces failures through Check.DependsOn and returns the root causes of the report
+// A failing check whose dependencies also fail gets those root causes in CausedBy and its Output explains
+// them; a passing check with a failing dependency is degraded to warn. Root causes are the failing checks
+// none of whose own dependencies fail.
+func attributeCauses(checks []Check, results []CheckResult) []string {
+	index := make(map[string]int, len(checks))
+	for i, check := range checks {
+		index[check.Name] = i
+	}
+	failed := func(name string) bool {
+		return results[index[name]].Status == StatusFail
+	}
+
+	// failingDeps returns the failing checks reachable through the dependencies of name
+	// Registration only accepts dependencies that already exist, so the graph has no cycles.
+	memo := make(map[string][]string, len(checks))
+	var failingDeps func(name string) []string
+	failingDeps = func(name string) []string {
+		if deps, ok := memo[name]; ok {
+			return deps
+		}
+		set := make(map[string]bool)
+		for _, dep := range checks[index[name]].DependsOn {
+			if failed(dep) {
+				set[dep] = true
+			}
+			for _, upstream := range failingDeps(dep) {
+				set[upstream] = true
+			}
+		}
+		deps := make([]string, 0, len(set))
+		for dep := range set {
+			deps = append(deps, dep)
+		}
+		sort.Strings(deps)
+		memo[name] = deps
+		return deps
+	}
+	rootsOf := func(name string) []string {
+		var roots []string
+		for _, dep := range failingDeps(name) {
+			if len(failingDeps(dep)) == 0 {
+				roots = append(roots, dep)
+			}
+		}
+		return roots
+	}
+
+	var roots []string
+	for i, check := range checks {
+		result := &results[i]
+		causes := rootsOf(check.Name)
+		switch {
+		case len(causes) > 0 && result.Status == StatusFail:
+			result.CausedBy = causes
+			result.Output = fmt.Sprintf("%s; failing because %s", result.Output, describeFailing(causes))
+		case len(causes) > 0:
+			result.CausedBy = causes
+			result.Status = StatusWarn
+			result.Output = "degraded because " + describeFailing(causes)
+		case result.Status == StatusFail:
+			roots = append(roots, check.Name)
+		}
+	}
+	return roots
+}
+
+// describeFailing words a list of failing checks, e.g. "dns is failing" or "dns, network are failing"
+func describeFailing(names []string) string {
+	if len(names) == 1 {
+		return names[0] + " is failing"
+	}
+	return strings.Join(names, ", ") + " are failing"
+}
@@ -0,0 +1,29 @@
+package healthutil
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ContentType is the media type of health+json responses
+const ContentType = "application/health+json"
+
+// Handler serves the evaluated Report as health+json
+// It responds 200 for pass and warn, and 503 Service Unavailable for fail.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Evaluate(req.Context())
+
+		status := http.StatusOK
+		if report.Status == StatusFail {
+			status = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", ContentType)
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(status)
+		if req.Method != http.MethodHead {
+			_ = json.NewEncoder(w).Encode(report)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package httputil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// readCloser pairs a replacement reader with the original body's Close
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// TeeBody re-wraps resp.Body so that everything the caller reads is also written to sinks
+// Sinks only see the bytes actually read, so a body closed early is observed partially. A sink
+// error aborts the read with that error.
+func TeeBody(resp *http.Response, sinks ...io.Writer) {
+	if resp == nil || resp.Body == nil || len(sinks) == 0 {
+		return
+	}
+	resp.Body = &readCloser{Reader: io.TeeReader(resp.Body, io.MultiWriter(sinks...)), Closer: resp.Body}
+}
+
+// CaptureBody reads up to maxBytes of resp.Body and returns them, leaving the full body readable by the caller
+// The captured bytes are replayed ahead of the unread remainder, so nothing is lost. A maxBytes <= 0
+// captures the whole body.
+func CaptureBody(resp *http.Response, maxBytes int64) ([]byte, error) {
+	if resp == nil || resp.Body == nil {
+		return nil, nil
+	}
+	var src io.Reader = resp.Body
+	if maxBytes > 0 {
+		src = io.LimitReader(resp.Body, maxBytes)
+	}
+	captured, err := io.ReadAll(src)
+	resp.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(captured), resp.Body), Closer: resp.Body}
+	if err != nil {
+		return captured, fmt.Errorf("failed to capture response body: %w", err)
+	}
+	return captured, nil
+}
+
+// BodyCapture returns a Middleware that passes up to maxBytes of every response body to observe
+// The caller still receives the complete body. Capture errors are reported to observe as a nil body.
+func BodyCapture(maxBytes int64, observe func(resp *http.Response, body []byte)) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			resp, err := next(req)
+			if err != nil || resp == nil {
+				return resp, err
+			}
+			body, captureErr := CaptureBody(resp, maxBytes)
+			if captureErr != nil {
+				body = nil
+			}
+			observe(resp, body)
+			return resp, nil
+		}
+	}
+}
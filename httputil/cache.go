@@ -0,0 +1,267 @@
+package httputil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/cacheutil"
+)
+
+// CacheStatusHeader is set on responses served from the response cache
+const CacheStatusHeader = "X-Cache-Status"
+
+// Values of CacheStatusHeader
+const (
+	CacheStatusHit         = "HIT"         // a fresh stored response, served without contacting the server
+	CacheStatusStale       = "STALE"       // an expired stored response, served because the request failed
+	CacheStatusRevalidated = "REVALIDATED" // a stored response the server confirmed with 304 Not Modified
+)
+
+// ResponseCacheConfig enables caching of successful GET responses
+// The cache key is the request URL, so do not share one cache between callers that see different
+// responses for the same URL (e.g. per-user Authorization). Responses marked Cache-Control: no-store are skipped.
+type ResponseCacheConfig struct {
+	// Backend for stored responses; nil uses an in-memory cacheutil store
+	Store cacheutil.Store
+
+	// How long a stored response is served without contacting the server (0 always sends the request)
+	TTL time.Duration
+
+	// How long past TTL a stored response may still be served when the request fails after retries
+	// (stale-if-error, RFC 5861). 0 disables the fallback.
+	StaleIfError time.Duration
+
+	// Larger bodies are not stored (default 1 MiB)
+	MaxBodyBytes int64
+
+	// Send If-None-Match/If-Modified-Since once a stored response with an ETag or Last-Modified is past TTL,
+	// and serve the stored body when the server answers 304 Not Modified. With TTL 0 every request is
+	// conditional, which suits polling. Callers setting either header themselves get the 304 as is.
+	Revalidate bool
+
+	// How long a response with an ETag or Last-Modified is kept for revalidation (default 24 hours)
+	RetainFor time.Duration
+}
+
+// IsCached reports whether resp was served from the response cache, fresh or stale
+func IsCached(resp *http.Response) bool {
+	return resp != nil && resp.Header.Get(CacheStatusHeader) != ""
+}
+
+// IsStale reports whether resp is an expired cached response served because the request failed
+func IsStale(resp *http.Response) bool {
+	return resp != nil && resp.Header.Get(CacheStatusHeader) == CacheStatusStale
+}
+
+// responseCache stores GET responses for ResponseCacheConfig
+type responseCache struct {
+	store        cacheutil.Store
+	ttl          time.Duration
+	staleIfError time.Duration
+	maxBodyBytes int64
+	revalidate   bool
+	retainFor    time.Duration
+}
+
+// cachedResponse is the stored form of a response
+type cachedResponse struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"stored_at"`
+}
+
+// newResponseCache returns nil, disabling caching, when config is nil
+func newResponseCache(config *ResponseCacheConfig) *responseCache {
+	if config == nil {
+		return nil
+	}
+	c := &responseCache{
+		store:        config.Store,
+		ttl:          config.TTL,
+		staleIfError: config.StaleIfError,
+		maxBodyBytes: config.MaxBodyBytes,
+		revalidate:   config.Revalidate,
+		retainFor:    config.RetainFor,
+	}
+	if c.store == nil {
+		c.store = cacheutil.NewMemoryStore(nil)
+	}
+	if c.maxBodyBytes <= 0 {
+		c.maxBodyBytes = 1 << 20
+	}
+	if c.retainFor <= 0 {
+		c.retainFor = 24 * time.Hour
+	}
+	return c
+}
+
+// cacheKey returns the key for a request, or "" when the request is not cacheable
+func (c *responseCache) cacheKey(opts RequestOptions) string {
+	if c == nil || opts.Method != http.MethodGet || opts.stream {
+		return ""
+	}
+	return "httputil:" + opts.URL
+}
+
+// fresh returns a stored response younger than the TTL
+func (c *responseCache) fresh(opts RequestOptions) (*http.Response, bool) {
+	key := c.cacheKey(opts)
+	if key == "" || c.ttl <= 0 {
+		return nil, false
+	}
+	entry, ok := c.load(opts.Context, key)
+	if !ok || time.Since(entry.StoredAt) > c.ttl {
+		return nil, false
+	}
+	return entry.response(opts, CacheStatusHit), true
+}
+
+// stale returns a stored response still within the stale-if-error window
+func (c *responseCache) stale(opts RequestOptions) (*http.Response, bool) {
+	key := c.cacheKey(opts)
+	if key == "" || c.staleIfError <= 0 {
+		return nil, false
+	}
+	entry, ok := c.load(opts.Context, key)
+	if !ok || time.Since(entry.StoredAt) > c.ttl+c.staleIfError {
+		return nil, false
+	}
+	return entry.response(opts, CacheStatusStale), true
+}
+
+// conditional returns the stored response to revalidate, or nil when the request is sent unconditionally
+func (c *responseCache) conditional(opts RequestOptions) *cachedResponse {
+	key := c.cacheKey(opts)
+	if key == "" || !c.revalidate {
+		return nil
+	}
+	for name := range opts.Headers {
+		if strings.EqualFold(name, "If-None-Match") || strings.EqualFold(name, "If-Modified-Since") {
+			return nil
+		}
+	}
+	entry, ok := c.load(opts.Context, key)
+	if !ok || !entry.hasValidators() {
+		return nil
+	}
+	return entry
+}
+
+// revalidated answers a 304 for entry with the stored response, refreshing its headers and age
+// Headers of the 304 replace the stored ones (RFC 9111 section 4.3.4), except Content-Length.
+func (c *responseCache) revalidated(opts RequestOptions, entry *cachedResponse, notModified *http.Response) (*http.Response, error) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(notModified.Body, 64<<10))
+	_ = notModified.Body.Close()
+
+	updated := *entry
+	updated.Header = entry.Header.Clone()
+	for name, values := range notModified.Header {
+		if name != "Content-Length" {
+			updated.Header[name] = append([]string(nil), values...)
+		}
+	}
+	updated.StoredAt = time.Now()
+
+	resp := updated.response(opts, CacheStatusRevalidated)
+	resp.Header.Set("Age", "0")
+	return resp, c.store.Set(opts.Context, c.cacheKey(opts), updated.encode(), c.retention(&updated))
+}
+
+// retention is how long entry is kept in the store
+func (c *responseCache) retention(entry *cachedResponse) time.Duration {
+	keep := c.ttl + c.staleIfError
+	if c.revalidate && entry.hasValidators() && c.retainFor > keep {
+		keep = c.retainFor
+	}
+	return keep
+}
+
+// hasValidators reports whether the stored response can be revalidated with a conditional request
+func (e *cachedResponse) hasValidators() bool {
+	return e.Header.Get("ETag") != "" || e.Header.Get("Last-Modified") != ""
+}
+
+// setConditional adds the validators of e to req
+func (e *cachedResponse) setConditional(req *http.Request) {
+	if etag := e.Header.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if modified := e.Header.Get("Last-Modified"); modified != "" {
+		req.Header.Set("If-Modified-Since", modified)
+	}
+}
+
+// encode returns the stored form of e; the struct only holds JSON-safe values
+func (e *cachedResponse) encode() []byte {
+	data, _ := json.Marshal(e)
+	return data
+}
+
+// load reads and decodes a stored response; backend and decoding errors count as a miss
+func (c *responseCache) load(ctx context.Context, key string) (*cachedResponse, bool) {
+	data, err := c.store.Get(ctx, key)
+	if err != nil {
+		return nil, false
+	}
+	var entry cachedResponse
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// save stores a successful response, leaving its body fully readable by the caller
+func (c *responseCache) save(opts RequestOptions, resp *http.Response) error {
+	key := c.cacheKey(opts)
+	if key == "" || resp == nil || resp.StatusCode < 200 || resp.StatusCode > 299 || resp.StatusCode == http.StatusPartialContent {
+		return nil
+	}
+	if strings.Contains(strings.ToLower(resp.Header.Get("Cache-Control")), "no-store") {
+		return nil
+	}
+	body, err := CaptureBody(resp, c.maxBodyBytes+1)
+	if err != nil {
+		return err
+	}
+	if int64(len(body)) > c.maxBodyBytes {
+		return nil
+	}
+
+	entry := &cachedResponse{StatusCode: resp.StatusCode, Header: resp.Header.Clone(), Body: body, StoredAt: time.Now()}
+	keep := c.retention(entry)
+	if keep <= 0 {
+		return nil // nothing would ever be served from it
+	}
+	return c.store.Set(opts.Context, key, entry.encode(), keep)
+}
+
+// response rebuilds an *http.Response from the stored entry, flagged with status
+func (e *cachedResponse) response(opts RequestOptions, status string) *http.Response {
+	header := e.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	header.Set(CacheStatusHeader, status)
+	header.Set("Age", strconv.Itoa(int(time.Since(e.StoredAt)/time.Second)))
+
+	req, _ := http.NewRequestWithContext(opts.Context, opts.Method, opts.URL, nil)
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,236 @@
+package httputil
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mustanish/common-utils/v2/encodingutil"
+)
+
+// Supported checksum algorithms
+const (
+	ChecksumMD5    = "md5"
+	ChecksumSHA1   = "sha1"
+	ChecksumSHA256 = "sha256"
+	ChecksumSHA512 = "sha512"
+)
+
+// ErrNoChecksum is returned by VerifyChecksum when there is neither an expected digest nor a checksum header
+var ErrNoChecksum = errors.New("no checksum to verify")
+
+// digestPreference orders Digest header algorithms from strongest to weakest
+var digestPreference = []string{ChecksumSHA512, ChecksumSHA256, ChecksumSHA1, ChecksumMD5}
+
+// digestEncoding decodes caller-supplied digests given in hex or base64
+var digestEncoding = encodingutil.NewEncodingUtil()
+
+// Checksum is an expected digest of a response body
+// Value may be hex or base64 encoded.
+type Checksum struct {
+	Algorithm string
+	Value     string
+}
+
+// ChecksumOptions controls integrity verification of downloaded bodies
+type ChecksumOptions struct {
+	// Expected is a caller-supplied digest, e.g. from a release manifest
+	Expected *Checksum
+
+	// FromHeaders also verifies against the Digest or Content-MD5 response header when present
+	// Headers are ignored when the transport transparently decompressed the body, since they describe the encoded bytes.
+	FromHeaders bool
+}
+
+// ChecksumFromHeaders returns the strongest checksum advertised by Digest (RFC 3230) or Content-MD5
+func ChecksumFromHeaders(header http.Header) (*Checksum, bool) {
+	digests := make(map[string]string)
+	for _, value := range header.Values("Digest") {
+		for _, part := range strings.Split(value, ",") {
+			algo, digest, ok := strings.Cut(strings.TrimSpace(part), "=")
+			if !ok {
+				continue
+			}
+			digests[normalizeAlgorithm(algo)] = strings.TrimSpace(digest)
+		}
+	}
+	for _, algo := range digestPreference {
+		if digest, ok := digests[algo]; ok {
+			return &Checksum{Algorithm: algo, Value: digest}, true
+		}
+	}
+
+	if md5sum := strings.TrimSpace(header.Get("Content-MD5")); md5sum != "" {
+		return &Checksum{Algorithm: ChecksumMD5, Value: md5sum}, true
+	}
+	return nil, false
+}
+
+// normalizeAlgorithm maps spellings such as "SHA-256" to the package constants
+func normalizeAlgorithm(algo string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(algo), "-", ""))
+}
+
+// newHash returns a hash for a supported algorithm
+func newHash(algo string) (hash.Hash, error) {
+	switch normalizeAlgorithm(algo) {
+	case ChecksumMD5:
+		return md5.New(), nil
+	case ChecksumSHA1:
+		return sha1.New(), nil
+	case ChecksumSHA256:
+		return sha256.New(), nil
+	case ChecksumSHA512:
+		return sha512.New(), nil
+	}
+	return nil, fmt.Errorf("unsupported checksum algorithm %q", algo)
+}
+
+// decodeDigest decodes a hex or base64 digest, checking it has the hash's length
+func decodeDigest(value string, size int) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == hex.EncodedLen(size) {
+		if decoded, err := digestEncoding.DecodeHex(value); err == nil {
+			return decoded, nil
+		}
+	}
+	decoded, err := digestEncoding.DecodeBase64(value)
+	if err != nil || len(decoded) != size {
+		return nil, fmt.Errorf("invalid %d-byte digest %q", size, value)
+	}
+	return decoded, nil
+}
+
+// checksumVerifier hashes a body as it streams and compares it with the expected digests
+type checksumVerifier struct {
+	checks []verifyCheck
+}
+
+// verifyCheck pairs an expected digest with the running hash
+type verifyCheck struct {
+	algorithm string
+	expected  []byte
+	hash      hash.Hash
+}
+
+// newChecksumVerifier builds a verifier for resp, or returns nil when there is nothing to verify
+func newChecksumVerifier(resp *http.Response, opts *ChecksumOptions) (*checksumVerifier, error) {
+	if opts == nil {
+		return nil, nil
+	}
+
+	var sums []*Checksum
+	if opts.Expected != nil {
+		sums = append(sums, opts.Expected)
+	}
+	if opts.FromHeaders && !resp.Uncompressed {
+		if sum, ok := ChecksumFromHeaders(resp.Header); ok {
+			sums = append(sums, sum)
+		}
+	}
+	if len(sums) == 0 {
+		return nil, nil
+	}
+
+	v := &checksumVerifier{}
+	for _, sum := range sums {
+		h, err := newHash(sum.Algorithm)
+		if err != nil {
+			return nil, err
+		}
+		expected, err := decodeDigest(sum.Value, h.Size())
+		if err != nil {
+			return nil, err
+		}
+		v.checks = append(v.checks, verifyCheck{algorithm: normalizeAlgorithm(sum.Algorithm), expected: expected, hash: h})
+	}
+	return v, nil
+}
+
+// writer returns dst teed into every running hash
+func (v *checksumVerifier) writer(dst io.Writer) io.Writer {
+	if v == nil {
+		return dst
+	}
+	writers := []io.Writer{dst}
+	for _, c := range v.checks {
+		writers = append(writers, c.hash)
+	}
+	return io.MultiWriter(writers...)
+}
+
+// verify compares the computed digests with the expected ones
+func (v *checksumVerifier) verify() error {
+	if v == nil {
+		return nil
+	}
+	for _, c := range v.checks {
+		actual := c.hash.Sum(nil)
+		if !bytes.Equal(actual, c.expected) {
+			return &ChecksumMismatchError{
+				Algorithm: c.algorithm,
+				Expected:  hex.EncodeToString(c.expected),
+				Actual:    hex.EncodeToString(actual),
+			}
+		}
+	}
+	return nil
+}
+
+// VerifyChecksum makes reading resp.Body check it against expected and the Digest/Content-MD5 headers
+// The digests are computed while the body streams, so it can be decoded or copied as usual; the read that
+// reaches the end returns a *ChecksumMismatchError instead of io.EOF when a digest does not match. algo is
+// one of the Checksum* constants and expected is hex or base64; with expected empty only the headers are
+// used, and ErrNoChecksum is returned when there are none.
+func VerifyChecksum(resp *http.Response, algo, expected string) error {
+	opts := &ChecksumOptions{FromHeaders: true}
+	if expected != "" {
+		opts.Expected = &Checksum{Algorithm: algo, Value: expected}
+	}
+	verifier, err := newChecksumVerifier(resp, opts)
+	if err != nil {
+		return err
+	}
+	if verifier == nil {
+		return ErrNoChecksum
+	}
+	resp.Body = &verifyingBody{ReadCloser: resp.Body, verifier: verifier, hashes: verifier.writer(io.Discard)}
+	return nil
+}
+
+// verifyingBody hashes a response body as it is read and reports a mismatch at its end
+type verifyingBody struct {
+	io.ReadCloser
+	verifier *checksumVerifier
+	hashes   io.Writer
+	err      error // result of the verification, once the end was reached
+	done     bool
+}
+
+// Read implements io.Reader
+func (b *verifyingBody) Read(p []byte) (int, error) {
+	if b.done {
+		if b.err != nil {
+			return 0, b.err
+		}
+		return 0, io.EOF
+	}
+	n, err := b.ReadCloser.Read(p)
+	_, _ = b.hashes.Write(p[:n])
+	if err == io.EOF {
+		b.done = true
+		if b.err = b.verifier.verify(); b.err != nil {
+			return n, b.err
+		}
+	}
+	return n, err
+}
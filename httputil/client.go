@@ -2,12 +2,22 @@ package httputil
 
 import (
 	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"syscall"
 	"time"
 
-	"github.com/sirupsen/logrus"
+	"github.com/mustanish/common-utils/v2/cryptoutil"
+	"github.com/mustanish/common-utils/v2/logutil"
+	"github.com/mustanish/common-utils/v2/ratelimitutil"
 	"github.com/thoas/go-funk"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // HTTPConfig holds configuration for HTTP transport settings
@@ -30,6 +40,75 @@ type HTTPConfig struct {
 	InitialWait   time.Duration
 	MaxWait       time.Duration
 	RetryOnStatus []int
+
+	// Client-side rate limiting applied before every attempt, including retries (nil disables it)
+	// A limiter implementing ratelimitutil.FeedbackReceiver, e.g. NewAdaptiveLimiter, also receives every response.
+	RateLimiter ratelimitutil.Limiter
+
+	// Explicit HTTP/HTTPS/SOCKS5 proxies (nil connects directly; see ProxyConfig.FromEnvironment)
+	Proxy *ProxyConfig
+
+	// Custom CAs, client certificate for mutual TLS and minimum version (nil uses the system roots and Go defaults)
+	TLS *TLSConfig
+
+	// Send https requests over HTTP/3 with a caller-supplied QUIC round tripper, falling back to HTTP/2 (nil disables it)
+	HTTP3 *HTTP3Config
+
+	// OpenTelemetry spans for every request and attempt, with retries as events (nil disables tracing at no cost)
+	TracerProvider trace.TracerProvider
+
+	// Injects the trace context into attempt headers (default: W3C traceparent via propagation.TraceContext)
+	Propagator propagation.TextMapPropagator
+
+	// Generate a request ID for every call whose context and headers carry none (see RequestID)
+	// The ID is reused across retries, logged as request_id and sent in RequestIDHeader.
+	GenerateRequestID bool
+
+	// Header carrying the request ID (default contextutil.RequestIDHeader, "X-Request-ID")
+	RequestIDHeader string
+
+	// Creates IDs when GenerateRequestID is set (default contextutil.NewRequestID, a random UUID)
+	RequestIDGenerator func() string
+
+	// Cookie jar shared by every request, e.g. to keep a login session (nil disables cookies unless EnableCookies)
+	CookieJar http.CookieJar
+
+	// Store cookies in a built-in MemoryCookieJar when CookieJar is nil
+	EnableCookies bool
+
+	// Headers sent with every request unless the call sets them, e.g. {"User-Agent": "billing/1.4"}
+	// See SetHeader, SetBasicAuth and SetBearerToken to change them after the client is created.
+	Headers map[string]string
+
+	// Signs every attempt just before it is sent, e.g. &HMACSigner{...} or &SigV4Signer{...} (nil disables signing)
+	Signer Signer
+
+	// Collect a DNS/connect/TLS/TTFB breakdown for every attempt, read with GetTimings
+	EnableTimings bool
+
+	// Decode only what Content-Encoding declares in ReadBody/DecodeJSON, without checking the gzip magic bytes
+	DisableBodySniffing bool
+
+	// Gzip request bodies of at least this many bytes and send Content-Encoding: gzip (0 disables it)
+	// Only for servers that accept compressed requests; GetBody streams are never compressed (see WithGzipBody).
+	GzipRequestsAbove int64
+
+	// Token-bucket limit applied to every host separately, e.g. {RequestsPerSecond: 10, Burst: 20} (zero disables it)
+	PerHostRateLimit HostRateLimit
+
+	// Limits for specific hosts ("api.example.com" or "api.example.com:8443"), overriding PerHostRateLimit
+	// With either set, a 429 Retry-After also pauses all requests to that host for the given time.
+	HostRateLimits map[string]HostRateLimit
+
+	// Cache successful GET responses and serve them stale when a request fails (nil disables caching)
+	ResponseCache *ResponseCacheConfig
+
+	// Reconnect a StreamSSE stream that sends nothing, not even a heartbeat comment, for this long (0 disables it)
+	StreamIdleTimeout time.Duration
+
+	// Maximum in-flight requests per host, counted until the response body is closed (0 means unlimited)
+	// Unlike MaxIdleConnsPerHost this bounds concurrent requests, not pooled connections.
+	MaxConcurrentRequestsPerHost int
 }
 
 // DefaultHTTPConfig returns default configuration
@@ -60,13 +139,35 @@ func DefaultHTTPConfig() *HTTPConfig {
 
 // HTTPClient defines the interface for the custom HTTP client
 type HTTPClient interface {
-	Get(ctx context.Context, url string, headers map[string]string) (*http.Response, error)
-	Post(ctx context.Context, url string, body io.Reader, headers map[string]string) (*http.Response, error)
-	Put(ctx context.Context, url string, body io.Reader, headers map[string]string) (*http.Response, error)
-	Patch(ctx context.Context, url string, body io.Reader, headers map[string]string) (*http.Response, error)
-	Delete(ctx context.Context, url string, headers map[string]string) (*http.Response, error)
+	Get(ctx context.Context, url string, headers map[string]string, opts ...RequestOption) (*http.Response, error)
+	Post(ctx context.Context, url string, body io.Reader, headers map[string]string, opts ...RequestOption) (*http.Response, error)
+	Put(ctx context.Context, url string, body io.Reader, headers map[string]string, opts ...RequestOption) (*http.Response, error)
+	Patch(ctx context.Context, url string, body io.Reader, headers map[string]string, opts ...RequestOption) (*http.Response, error)
+	Delete(ctx context.Context, url string, headers map[string]string, opts ...RequestOption) (*http.Response, error)
+	Do(opts RequestOptions) (*http.Response, error)
+	StreamSSE(ctx context.Context, url string, headers map[string]string, handler func(event Event) error) error
+	PostMultipart(ctx context.Context, url string, fields map[string]string, files []FileField, headers map[string]string, opts ...RequestOption) (*http.Response, error)
 	SetRetryHook(hook func(attempt int, resp *http.Response, err error))
 	SetSuccessHook(hook func(resp *http.Response, options RequestOptions))
+	SetFailureHook(hook func(resp *http.Response, err error, options RequestOptions))
+	SetRetryDecisionHook(hook func(decision RetryDecision))
+	SetRateLimitFeedbackHook(hook func(feedback RateLimitFeedback))
+	SetRetryClassifier(classifier func(resp *http.Response, err error) bool)
+	Use(middleware ...Middleware)
+	SetTransport(rt http.RoundTripper)
+
+	// Default headers
+	SetHeader(key, value string)
+	SetBasicAuth(username string, password cryptoutil.Secret)
+	SetBearerToken(token cryptoutil.Secret)
+
+	// Cookies
+	Cookies(rawURL string) ([]*http.Cookie, error)
+	ClearCookies(domain string) error
+
+	// JSON round trips
+	GetJSON(ctx context.Context, url string, headers map[string]string, out any, opts ...RequestOption) error
+	PostJSON(ctx context.Context, url string, in any, headers map[string]string, out any, opts ...RequestOption) error
 
 	// Response helpers
 	ReadBody(resp *http.Response) ([]byte, error)
@@ -74,6 +175,18 @@ type HTTPClient interface {
 	IsSuccess(resp *http.Response) bool
 	GetHeader(resp *http.Response, key string) string
 	CloseResponse(resp *http.Response)
+
+	// Downloads
+	DownloadTo(ctx context.Context, url string, w io.Writer, headers map[string]string, verify *ChecksumOptions) (int64, error)
+	SaveToFile(resp *http.Response, path string, verify *ChecksumOptions) error
+	DownloadFile(ctx context.Context, url, destPath string, opts DownloadOptions) error
+
+	// Resources
+	Resource(baseURL string) *Resource
+	Endpoints(baseURL string) *Endpoints
+
+	// Pagination
+	GetAllPages(ctx context.Context, url string, opts PageOptions, appendFn func(items json.RawMessage) error) (int, error)
 }
 
 // HTTPUtil is a custom HTTP client with retry logic and enhanced logging
@@ -82,19 +195,91 @@ type HTTPUtil struct {
 	MaxRetries     int
 	InitialWait    time.Duration
 	MaxWait        time.Duration
-	Logger         *logrus.Logger
+	Logger         logutil.Logger
 	RequestTimeout time.Duration
 	RetryOnStatus  []int
+	RateLimiter    ratelimitutil.Limiter
+	Signer         Signer
+
+	// Skip gzip magic-byte detection when decoding bodies, set from HTTPConfig.DisableBodySniffing
+	DisableBodySniffing bool
+
+	// Gzip request bodies from this size on, set from HTTPConfig.GzipRequestsAbove
+	GzipRequestsAbove int64
+
+	// Trace every attempt with httptrace, set from HTTPConfig.EnableTimings
+	EnableTimings bool
+
+	// Reconnect silent event streams after this long, set from HTTPConfig.StreamIdleTimeout
+	StreamIdleTimeout time.Duration
+
+	// Per-host in-flight request limit, set from HTTPConfig.MaxConcurrentRequestsPerHost
+	hostSlots *hostSemaphores
+
+	// Per-host token buckets and Retry-After pauses, set from HTTPConfig.PerHostRateLimit/HostRateLimits
+	hostRate *hostRateLimiters
+
+	// Stored GET responses for TTL and stale-if-error serving, set from HTTPConfig.ResponseCache
+	cache *responseCache
+
+	// Proxy choice for every request, set from HTTPConfig.Proxy and overridden per call with WithProxy
+	proxy *proxySelector
+
+	// OpenTelemetry spans, set from HTTPConfig.TracerProvider (nil when tracing is off)
+	tracing *tracing
+
+	// Request ID header and generator, set from HTTPConfig.GenerateRequestID and RequestIDHeader
+	requestIDs requestIDs
+
+	// Invalid HTTPConfig.TLS (e.g. an unreadable CA file), reported by every request
+	tlsErr error
+
+	// Headers added to every request, set from HTTPConfig.Headers and the SetHeader family
+	headers defaultHeaders
+
+	// Middleware chain wrapped around every attempt, registered with Use
+	middlewares []Middleware
 
 	RetryHook   func(attempt int, resp *http.Response, err error)
 	SuccessHook func(resp *http.Response, options RequestOptions)
+
+	// Called when a request returns an error after it started, e.g. retries exhausted (nil skips it)
+	FailureHook func(resp *http.Response, err error, options RequestOptions)
+
+	// Decides retryability of every attempt in place of RetryOnStatus (nil uses the status list)
+	// It sees either a response (err nil) or a transport error (resp nil); see SetRetryClassifier.
+	RetryClassifier func(resp *http.Response, err error) bool
+
+	// Called for every failed attempt that has retries left, including those abandoned for the deadline (nil skips it)
+	RetryDecisionHook func(decision RetryDecision)
+
+	// Called with the status and Retry-After of every attempt's response (nil skips it)
+	RateLimitFeedbackHook func(feedback RateLimitFeedback)
+}
+
+// RetryDecision describes whether a failed attempt is retried given the time left before the context deadline
+// Retry is false when the backoff would outlast the deadline: the request then fails at once with a
+// *RetryExhaustedError (StoppedByDeadline set) instead of sleeping until the context expires.
+type RetryDecision struct {
+	Attempt     int            // zero-based attempt that failed
+	Response    *http.Response // nil after a transport error
+	Err         error
+	Wait        time.Duration // backoff before the next attempt
+	HasDeadline bool
+	Remaining   time.Duration // time left before the context deadline, when HasDeadline
+	Retry       bool
 }
 
 // NewHTTPUtil creates a new HTTP client with configuration
+// Pass nil for logger to disable logging, e.g. logutil.NewLogrusLogger(l) adapts an existing logrus logger
 // Pass nil for config to use all defaults, or pass config with only the properties you want to override
-func NewHTTPUtil(logger *logrus.Logger, config *HTTPConfig) HTTPClient {
+func NewHTTPUtil(logger logutil.Logger, config *HTTPConfig) HTTPClient {
 	defaults := DefaultHTTPConfig()
 
+	if logger == nil {
+		logger = logutil.NewNopLogger()
+	}
+
 	if config != nil {
 		if config.ClientTimeout != 0 {
 			defaults.ClientTimeout = config.ClientTimeout
@@ -129,30 +314,124 @@ func NewHTTPUtil(logger *logrus.Logger, config *HTTPConfig) HTTPClient {
 		if config.RetryOnStatus != nil {
 			defaults.RetryOnStatus = config.RetryOnStatus
 		}
+		if config.RateLimiter != nil {
+			defaults.RateLimiter = config.RateLimiter
+		}
+		if config.Signer != nil {
+			defaults.Signer = config.Signer
+		}
+		if config.Headers != nil {
+			defaults.Headers = config.Headers
+		}
+		if config.CookieJar != nil {
+			defaults.CookieJar = config.CookieJar
+		}
+		if config.Proxy != nil {
+			defaults.Proxy = config.Proxy
+		}
+		if config.TLS != nil {
+			defaults.TLS = config.TLS
+		}
+		if config.HTTP3 != nil {
+			defaults.HTTP3 = config.HTTP3
+		}
+		if config.TracerProvider != nil {
+			defaults.TracerProvider = config.TracerProvider
+		}
+		if config.Propagator != nil {
+			defaults.Propagator = config.Propagator
+		}
+		if config.RequestIDHeader != "" {
+			defaults.RequestIDHeader = config.RequestIDHeader
+		}
+		if config.RequestIDGenerator != nil {
+			defaults.RequestIDGenerator = config.RequestIDGenerator
+		}
+		if config.PerHostRateLimit.enabled() {
+			defaults.PerHostRateLimit = config.PerHostRateLimit
+		}
+		if config.HostRateLimits != nil {
+			defaults.HostRateLimits = config.HostRateLimits
+		}
+		if config.ResponseCache != nil {
+			defaults.ResponseCache = config.ResponseCache
+		}
+		if config.StreamIdleTimeout != 0 {
+			defaults.StreamIdleTimeout = config.StreamIdleTimeout
+		}
+		if config.MaxConcurrentRequestsPerHost != 0 {
+			defaults.MaxConcurrentRequestsPerHost = config.MaxConcurrentRequestsPerHost
+		}
+		if config.GzipRequestsAbove != 0 {
+			defaults.GzipRequestsAbove = config.GzipRequestsAbove
+		}
 
 		defaults.DisableCompression = config.DisableCompression
 		defaults.ForceAttemptHTTP2 = config.ForceAttemptHTTP2
+		defaults.DisableBodySniffing = config.DisableBodySniffing
+		defaults.EnableTimings = config.EnableTimings
+		defaults.EnableCookies = config.EnableCookies
+		defaults.GenerateRequestID = config.GenerateRequestID
+	}
+	if defaults.CookieJar == nil && defaults.EnableCookies {
+		defaults.CookieJar = NewMemoryCookieJar(nil)
+	}
+
+	proxy := newProxySelector(defaults.Proxy)
+
+	var tlsConfig *tls.Config
+	var tlsErr error
+	if defaults.TLS != nil {
+		if tlsConfig, tlsErr = NewTLSConfig(defaults.TLS); tlsErr != nil {
+			tlsErr = fmt.Errorf("invalid TLS configuration: %w", tlsErr)
+			logger.WithError(tlsErr).Error("Every request will fail until the TLS configuration is fixed")
+		} else if tlsConfig.InsecureSkipVerify {
+			logger.Warn("TLS certificate verification is disabled; connections can be intercepted")
+		}
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy.proxy,
+		TLSClientConfig:       tlsConfig,
+		DisableCompression:    defaults.DisableCompression,
+		ForceAttemptHTTP2:     defaults.ForceAttemptHTTP2,
+		MaxIdleConnsPerHost:   defaults.MaxIdleConnsPerHost,
+		MaxIdleConns:          defaults.MaxIdleConns,
+		IdleConnTimeout:       defaults.IdleConnTimeout,
+		TLSHandshakeTimeout:   defaults.TLSHandshakeTimeout,
+		ExpectContinueTimeout: defaults.ExpectContinueTimeout,
+		ResponseHeaderTimeout: defaults.ResponseHeaderTimeout,
 	}
 
 	client := &HTTPUtil{
 		Client: &http.Client{
-			Timeout: defaults.ClientTimeout,
-			Transport: &http.Transport{
-				DisableCompression:    defaults.DisableCompression,
-				ForceAttemptHTTP2:     defaults.ForceAttemptHTTP2,
-				MaxIdleConnsPerHost:   defaults.MaxIdleConnsPerHost,
-				MaxIdleConns:          defaults.MaxIdleConns,
-				IdleConnTimeout:       defaults.IdleConnTimeout,
-				TLSHandshakeTimeout:   defaults.TLSHandshakeTimeout,
-				ExpectContinueTimeout: defaults.ExpectContinueTimeout,
-				ResponseHeaderTimeout: defaults.ResponseHeaderTimeout,
-			},
+			Timeout:   defaults.ClientTimeout,
+			Jar:       defaults.CookieJar,
+			Transport: newHTTP3Transport(defaults.HTTP3, transport),
 		},
 		MaxRetries:    defaults.MaxRetries,
 		InitialWait:   defaults.InitialWait,
 		MaxWait:       defaults.MaxWait,
 		Logger:        logger,
 		RetryOnStatus: defaults.RetryOnStatus,
+		RateLimiter:   defaults.RateLimiter,
+		Signer:        defaults.Signer,
+
+		DisableBodySniffing: defaults.DisableBodySniffing,
+		GzipRequestsAbove:   defaults.GzipRequestsAbove,
+		EnableTimings:       defaults.EnableTimings,
+		StreamIdleTimeout:   defaults.StreamIdleTimeout,
+		hostSlots:           newHostSemaphores(defaults.MaxConcurrentRequestsPerHost),
+		hostRate:            newHostRateLimiters(defaults.PerHostRateLimit, defaults.HostRateLimits),
+		cache:               newResponseCache(defaults.ResponseCache),
+		proxy:               proxy,
+		tlsErr:              tlsErr,
+		tracing:             newTracing(defaults.TracerProvider, defaults.Propagator),
+		requestIDs:          newRequestIDs(defaults.RequestIDHeader, defaults.GenerateRequestID, defaults.RequestIDGenerator),
+	}
+
+	for k, v := range defaults.Headers {
+		client.headers.set(k, v)
 	}
 
 	// Set default hooks
@@ -166,15 +445,45 @@ func (h *HTTPUtil) SetRetryHook(hook func(attempt int, resp *http.Response, err
 	h.RetryHook = hook
 }
 
+// SetRetryDecisionHook sets a hook that observes every retry decision, e.g. to record deadline budget metrics
+func (h *HTTPUtil) SetRetryDecisionHook(hook func(decision RetryDecision)) {
+	h.RetryDecisionHook = hook
+}
+
+// SetRetryClassifier replaces the status-list retry decision with classifier; nil restores the default
+// It is called for every response (any status) and every transport error; returning false stops retrying
+// and returns that outcome as is. A classifier that inspects the body should read it with CaptureBody so the
+// caller still receives it. Per-request WithRetryOnStatus lists are ignored while a classifier is set.
+func (h *HTTPUtil) SetRetryClassifier(classifier func(resp *http.Response, err error) bool) {
+	h.RetryClassifier = classifier
+}
+
 // SetSuccessHook allows customizing the success hook
+// It also runs for responses served from the cache, with options.Stats.CacheStatus set.
 func (h *HTTPUtil) SetSuccessHook(hook func(resp *http.Response, options RequestOptions)) {
 	h.SuccessHook = hook
 }
 
+// SetFailureHook sets a hook called when a request fails, with options.Stats describing its attempts
+// resp is the last response when retries were exhausted on a status code, and nil otherwise.
+// It also runs when a stale cached response is returned in place of the failure, with options.Stats.CacheStatus
+// set to CacheStatusStale; the caller then receives the stale response and no error.
+func (h *HTTPUtil) SetFailureHook(hook func(resp *http.Response, err error, options RequestOptions)) {
+	h.FailureHook = hook
+}
+
+// SetTransport replaces the round tripper built from HTTPConfig, e.g. with a fault-injecting transport in tests
+// Retries, hooks, middleware and signing still run on top of rt, but the TLS, proxy, HTTP/3 and connection pool
+// settings of HTTPConfig only apply if rt wraps the previous HTTPUtil.Client.Transport. Call it before the
+// client is shared between goroutines.
+func (h *HTTPUtil) SetTransport(rt http.RoundTripper) {
+	h.Client.Transport = rt
+}
+
 // setDefaultHooks configures the default hook implementations
 func (h *HTTPUtil) setDefaultHooks() {
 	h.RetryHook = func(attempt int, resp *http.Response, err error) {
-		fields := logrus.Fields{
+		fields := logutil.Fields{
 			"attempt": attempt + 1,
 			"max":     h.MaxRetries,
 			"wait":    h.InitialWait,
@@ -184,19 +493,94 @@ func (h *HTTPUtil) setDefaultHooks() {
 		}
 		if resp != nil {
 			fields["status"] = resp.StatusCode
+			if resp.Request != nil {
+				if name := EndpointName(resp.Request.Context()); name != "" {
+					fields["endpoint"] = name
+				}
+			}
+		}
+		addTimingFields(fields, resp)
+		logger := h.Logger
+		if resp != nil && resp.Request != nil {
+			logger = logger.WithContext(resp.Request.Context())
 		}
-		h.Logger.WithFields(fields).Warn("Request failed, retrying")
+		logger.WithFields(fields).Warn("Request failed, retrying")
 	}
 
 	h.SuccessHook = func(resp *http.Response, options RequestOptions) {
-		h.Logger.WithFields(logrus.Fields{"method": options.Method, "url": options.URL, "status": resp.StatusCode}).Info("Request completed successfully")
+		fields := logutil.Fields{"method": options.Method, "url": options.URL, "status": resp.StatusCode,
+			"attempts": options.Stats.Attempts, "elapsed": options.Stats.Elapsed}
+		if name := EndpointName(options.Context); name != "" {
+			fields["endpoint"] = name
+		}
+		addTimingFields(fields, resp)
+		h.Logger.WithContext(options.Context).WithFields(fields).Info("Request completed successfully")
 	}
 }
 
 // shouldRetry determines if a request should be retried
 func (h *HTTPUtil) shouldRetry(resp *http.Response, err error) bool {
+	return h.retries(h.retryPolicyFor(RequestOptions{}), resp, err)
+}
+
+// retries classifies an attempt outcome: the RetryClassifier decides when set, otherwise every
+// transport error and the policy's status codes are retried
+func (h *HTTPUtil) retries(policy retryPolicy, resp *http.Response, err error) bool {
+	if h.RetryClassifier != nil {
+		return h.RetryClassifier(resp, err)
+	}
 	if err != nil {
 		return true // Always retry on errors
 	}
-	return funk.Contains(h.RetryOnStatus, resp.StatusCode)
+	return policy.retriesStatus(resp.StatusCode)
+}
+
+// IsTransientNetError reports whether err is a network timeout or a dropped connection
+// (reset, aborted, broken pipe, or closed mid-response), the usual building block of a RetryClassifier.
+func IsTransientNetError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// retryPolicy is the effective retry configuration of a single request
+type retryPolicy struct {
+	maxRetries  int
+	initialWait time.Duration
+	maxWait     time.Duration
+	retryOn     []int
+}
+
+// retryPolicyFor resolves the per-request overrides in opts against the client configuration
+func (h *HTTPUtil) retryPolicyFor(opts RequestOptions) retryPolicy {
+	policy := retryPolicy{
+		maxRetries:  h.MaxRetries,
+		initialWait: h.InitialWait,
+		maxWait:     h.MaxWait,
+		retryOn:     h.RetryOnStatus,
+	}
+	if opts.MaxRetries != nil {
+		policy.maxRetries = *opts.MaxRetries
+	}
+	if opts.InitialWait > 0 {
+		policy.initialWait = opts.InitialWait
+	}
+	if opts.MaxWait > 0 {
+		policy.maxWait = opts.MaxWait
+	}
+	if opts.RetryOnStatus != nil {
+		policy.retryOn = opts.RetryOnStatus
+	}
+	return policy
+}
+
+// retriesStatus reports whether a response with the given status code is retried
+func (p retryPolicy) retriesStatus(code int) bool {
+	return funk.Contains(p.retryOn, code)
 }
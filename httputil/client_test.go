@@ -2,17 +2,54 @@ package httputil
 
 import (
 	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
+	"fmt"
 	"io"
+	"math/big"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
+	"github.com/mustanish/common-utils/v2/contextutil"
+	"github.com/mustanish/common-utils/v2/cryptoutil"
+	"github.com/mustanish/common-utils/v2/errorutil"
+	"github.com/mustanish/common-utils/v2/logutil"
+	"github.com/mustanish/common-utils/v2/ratelimitutil"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 func TestIsSuccess(t *testing.T) {
@@ -92,10 +129,39 @@ func TestRetryExhaustedError_Error(t *testing.T) {
 	}
 }
 
+func TestHTTPErrors_ErrorCode(t *testing.T) {
+	codes := errorutil.NewErrorUtil(nil)
+
+	tests := []struct {
+		name     string
+		err      error
+		expected errorutil.Code
+		status   int
+	}{
+		{"status 404", &StatusError{StatusCode: http.StatusNotFound, Method: "GET", URL: "http://test"}, errorutil.CodeNotFound, http.StatusNotFound},
+		{"wrapped status 401", fmt.Errorf("load user: %w", &StatusError{StatusCode: http.StatusUnauthorized}), errorutil.CodeUnauthenticated, http.StatusUnauthorized},
+		{"exhausted on 503", &RetryExhaustedError{LastStatus: http.StatusServiceUnavailable}, errorutil.CodeUnavailable, http.StatusServiceUnavailable},
+		{"exhausted on 429", &RetryExhaustedError{LastStatus: http.StatusTooManyRequests}, errorutil.CodeResourceExhausted, http.StatusTooManyRequests},
+		{"exhausted by deadline", &RetryExhaustedError{StoppedByDeadline: true, LastError: errors.New("dial")}, errorutil.CodeDeadlineExceeded, http.StatusGatewayTimeout},
+		{"exhausted on transport error", &RetryExhaustedError{LastError: errors.New("connection refused")}, errorutil.CodeUnavailable, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := codes.CodeOf(tt.err); got != tt.expected {
+				t.Errorf("CodeOf() = %s, want %s", got, tt.expected)
+			}
+			if got := codes.HTTPStatus(tt.err); got != tt.status {
+				t.Errorf("HTTPStatus() = %d, want %d", got, tt.status)
+			}
+		})
+	}
+}
+
 func TestHTTPUtil_Get_Success(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
 		_, _ = w.Write([]byte("ok"))
@@ -114,7 +180,7 @@ func TestHTTPUtil_Get_Success(t *testing.T) {
 func TestHTTPUtil_Post_Success(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(201)
 		_, _ = w.Write([]byte("created"))
@@ -133,7 +199,7 @@ func TestHTTPUtil_Post_Success(t *testing.T) {
 func TestHTTPUtil_Put_Success(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(200)
 		_, _ = w.Write([]byte("updated"))
@@ -152,7 +218,7 @@ func TestHTTPUtil_Put_Success(t *testing.T) {
 func TestHTTPUtil_Patch_Success(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Verify method
 		if r.Method != http.MethodPatch {
@@ -192,7 +258,7 @@ func TestHTTPUtil_Patch_Success(t *testing.T) {
 func TestHTTPUtil_Patch_WithHeaders(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Verify headers
@@ -226,7 +292,7 @@ func TestHTTPUtil_Patch_WithHeaders(t *testing.T) {
 func TestHTTPUtil_Patch_ErrorHandling(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 
 	tests := []struct {
 		name           string
@@ -288,7 +354,7 @@ func TestHTTPUtil_Patch_ErrorHandling(t *testing.T) {
 func TestHTTPUtil_Patch_InvalidURL(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 
 	// Use a malformed URL that will fail immediately
 	_, err := util.Patch(context.Background(), "://invalid-url", nil, nil)
@@ -300,7 +366,7 @@ func TestHTTPUtil_Patch_InvalidURL(t *testing.T) {
 func TestHTTPUtil_Patch_ContextCancellation(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Simulate slow server
@@ -321,7 +387,7 @@ func TestHTTPUtil_Patch_ContextCancellation(t *testing.T) {
 func TestHTTPUtil_Patch_NilBody(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		body, err := io.ReadAll(r.Body)
@@ -350,7 +416,7 @@ func TestHTTPUtil_Patch_NilBody(t *testing.T) {
 func TestHTTPUtil_Delete_Success(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(204)
 	}))
@@ -366,7 +432,7 @@ func TestHTTPUtil_Delete_Success(t *testing.T) {
 
 func TestSetRetryHookAndSuccessHook(t *testing.T) {
 	logger := logrus.New()
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 	successCalled := false
 	util.SetSuccessHook(func(resp *http.Response, options RequestOptions) {
 		successCalled = true
@@ -386,7 +452,7 @@ func TestSetRetryHookAndSuccessHook(t *testing.T) {
 
 func TestShouldRetry(t *testing.T) {
 	logger := logrus.New()
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 	resp := &http.Response{StatusCode: http.StatusInternalServerError}
 	if !util.shouldRetry(resp, nil) {
 		t.Error("Expected shouldRetry to return true for retryable status")
@@ -400,201 +466,2742 @@ func TestShouldRetry(t *testing.T) {
 	}
 }
 
-func TestHTTPUtil_EmptyMethod(t *testing.T) {
-	logger := logrus.New()
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
-	opts := RequestOptions{
-		Method: "",
-		URL:    "http://example.com",
-	}
-	_, err := util.doRequest(opts)
-	if err == nil || !strings.Contains(err.Error(), "method cannot be empty") {
-		t.Errorf("Expected method validation error, got: %v", err)
+func TestHTTPUtil_RetryClassifier(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&attempts, 1)
+		switch {
+		case r.URL.Path == "/unavailable":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case n == 1:
+			_, _ = io.WriteString(w, `{"error":"TRANSIENT"}`)
+		default:
+			_, _ = io.WriteString(w, `{"ok":true}`)
+		}
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 3, InitialWait: time.Millisecond}).(*HTTPUtil)
+	var transportErrors int32
+	util.SetRetryClassifier(func(resp *http.Response, err error) bool {
+		if err != nil {
+			atomic.AddInt32(&transportErrors, 1)
+			return false
+		}
+		body, _ := CaptureBody(resp, 1024)
+		return strings.Contains(string(body), "TRANSIENT")
+	})
+
+	t.Run("retries on body content", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+		resp, err := util.Get(context.Background(), server.URL, nil)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		body, _ := util.ReadBody(resp)
+		if string(body) != `{"ok":true}` || atomic.LoadInt32(&attempts) != 2 {
+			t.Errorf("Get() = %s after %d attempts, want the second response", body, attempts)
+		}
+	})
+
+	t.Run("overrides the status list", func(t *testing.T) {
+		atomic.StoreInt32(&attempts, 0)
+		resp, err := util.Get(context.Background(), server.URL+"/unavailable", nil)
+		util.CloseResponse(resp)
+		if err != nil || resp.StatusCode != http.StatusServiceUnavailable || atomic.LoadInt32(&attempts) != 1 {
+			t.Errorf("Get() = %v after %d attempts, want a single unretried 503", err, attempts)
+		}
+	})
+
+	t.Run("stops on transport errors", func(t *testing.T) {
+		closed := httptest.NewServer(http.NotFoundHandler())
+		closed.Close()
+		_, err := util.Get(context.Background(), closed.URL, nil)
+		var exhausted *RetryExhaustedError
+		if err == nil || errors.As(err, &exhausted) {
+			t.Errorf("Get() error = %v, want the transport error without retries", err)
+		}
+		if got := atomic.LoadInt32(&transportErrors); got != 1 {
+			t.Errorf("classifier saw %d transport errors, want 1", got)
+		}
+	})
+
+	util.SetRetryClassifier(nil)
+	if !util.shouldRetry(&http.Response{StatusCode: http.StatusServiceUnavailable}, nil) {
+		t.Error("clearing the classifier should restore the status list")
 	}
 }
 
-func TestHTTPUtil_EmptyURL(t *testing.T) {
-	logger := logrus.New()
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
-	opts := RequestOptions{
-		Method: "GET",
-		URL:    "",
+func TestIsTransientNetError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"timeout", &net.DNSError{Err: "timeout", IsTimeout: true}, true},
+		{"deadline", context.DeadlineExceeded, true},
+		{"connection reset", &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}, true},
+		{"broken pipe", fmt.Errorf("write: %w", syscall.EPIPE), true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"connection refused", syscall.ECONNREFUSED, false},
+		{"other", errors.New("bad certificate"), false},
 	}
-	_, err := util.doRequest(opts)
-	if err == nil || !strings.Contains(err.Error(), "URL cannot be empty") {
-		t.Errorf("Expected URL validation error, got: %v", err)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsTransientNetError(tt.err); got != tt.want {
+				t.Errorf("IsTransientNetError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
 	}
 }
 
-func TestHTTPUtil_RetryLogic(t *testing.T) {
-	logger := logrus.New()
-	logger.SetLevel(logrus.ErrorLevel) // Reduce log noise in tests
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
-	util.MaxRetries = 2
-	util.InitialWait = 10 * time.Millisecond
-	util.MaxWait = 50 * time.Millisecond
+func TestSigV4Signer(t *testing.T) {
+	now := func() time.Time { return time.Date(2015, time.August, 30, 12, 36, 0, 0, time.UTC) }
 
-	retryCount := 0
+	// Vectors from the AWS Signature Version 4 test suite and documentation
+	tests := []struct {
+		name        string
+		url         string
+		service     string
+		contentType string
+		want        string
+	}{
+		{
+			name:    "get-vanilla",
+			url:     "https://example.amazonaws.com/",
+			service: "service",
+			want:    "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:        "iam ListUsers",
+			url:         "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08",
+			service:     "iam",
+			contentType: "application/x-www-form-urlencoded; charset=utf-8",
+			want:        "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, SignedHeaders=content-type;host;x-amz-date, Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			signer := &SigV4Signer{
+				AccessKeyID:     "AKIDEXAMPLE",
+				SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+				Region:          "us-east-1",
+				Service:         tt.service,
+				Now:             now,
+			}
+			if err := signer.Sign(req); err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if got := req.Header.Get("Authorization"); got != tt.want {
+				t.Errorf("Authorization =\n%s\nwant\n%s", got, tt.want)
+			}
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %q", got)
+			}
+		})
+	}
+
+	t.Run("s3 payload hash and session token", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/a key.txt", strings.NewReader("hello"))
+		signer := &SigV4Signer{AccessKeyID: "AKID", SecretAccessKey: "secret", SessionToken: "token", Region: "eu-west-1", Service: "s3", Now: now}
+		if err := signer.Sign(req); err != nil {
+			t.Fatalf("Sign() error = %v", err)
+		}
+		if got := req.Header.Get("X-Amz-Content-Sha256"); got != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+			t.Errorf("X-Amz-Content-Sha256 = %q, want the SHA-256 of the body", got)
+		}
+		if !strings.Contains(req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,") {
+			t.Errorf("Authorization = %q, want the x-amz headers signed", req.Header.Get("Authorization"))
+		}
+		if body, _ := io.ReadAll(req.Body); string(body) != "hello" {
+			t.Errorf("signing consumed the body, left %q", body)
+		}
+	})
+
+	t.Run("streamed body", func(t *testing.T) {
+		req, _ := http.NewRequest(http.MethodPut, "https://bucket.s3.amazonaws.com/k", io.NopCloser(strings.NewReader("x")))
+		signer := &SigV4Signer{AccessKeyID: "AKID", SecretAccessKey: "secret", Region: "eu-west-1", Service: "s3"}
+		if err := signer.Sign(req); err == nil {
+			t.Error("Sign() of a one-shot body should fail")
+		}
+		signer.UnsignedPayload = true
+		if err := signer.Sign(req); err != nil || req.Header.Get("X-Amz-Content-Sha256") != "UNSIGNED-PAYLOAD" {
+			t.Errorf("Sign() with UnsignedPayload = %v, hash %q", err, req.Header.Get("X-Amz-Content-Sha256"))
+		}
+	})
+}
+
+func TestHTTPUtil_Signer(t *testing.T) {
+	secret := []byte("s3cr3t")
+	var mu sync.Mutex
+	var timestamps []string
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		retryCount++
-		if retryCount < 3 {
-			w.WriteHeader(http.StatusInternalServerError)
-		} else {
-			w.WriteHeader(http.StatusOK)
+		body, _ := io.ReadAll(r.Body)
+		bodyHash := sha256.Sum256(body)
+		stringToSign := r.Method + "\n" + r.URL.RequestURI() + "\n" + r.Header.Get("X-Timestamp") + "\n" + hex.EncodeToString(bodyHash[:])
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(stringToSign))
+		if r.Header.Get("X-Signature") != hex.EncodeToString(mac.Sum(nil)) || r.Header.Get("X-Key-Id") != "client-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		mu.Lock()
+		timestamps = append(timestamps, r.Header.Get("X-Timestamp"))
+		first := len(timestamps) == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
 		}
+		w.WriteHeader(http.StatusOK)
 	}))
 	defer server.Close()
 
-	_, err := util.Get(context.Background(), server.URL, nil)
-	if err != nil {
-		t.Errorf("Expected success after retries, got error: %v", err)
+	var clock int64 = 1700000000
+	signer := &HMACSigner{KeyID: "client-1", Secret: secret, Now: func() time.Time {
+		return time.Unix(atomic.AddInt64(&clock, 1), 0)
+	}}
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 2, InitialWait: time.Millisecond, Signer: signer}).(*HTTPUtil)
+
+	resp, err := util.Post(context.Background(), server.URL+"/orders?id=7", strings.NewReader(`{"qty":1}`), nil)
+	util.CloseResponse(resp)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Post() = %v, %v, want a verified 200", resp, err)
 	}
-	if retryCount != 3 {
-		t.Errorf("Expected 3 attempts (1 + 2 retries), got %d", retryCount)
+	if len(timestamps) != 2 || timestamps[0] == timestamps[1] {
+		t.Errorf("timestamps = %v, want every attempt signed afresh", timestamps)
 	}
-}
 
-func TestHTTPUtil_RetryExhausted(t *testing.T) {
-	logger := logrus.New()
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
-	util.MaxRetries = 1
-	util.InitialWait = 1 * time.Millisecond
+	// A per-call signer replaces the client's, and signing errors are not retried
+	var calls int32
+	failing := SignerFunc(func(req *http.Request) error {
+		atomic.AddInt32(&calls, 1)
+		return errors.New("no credentials")
+	})
+	if _, err := util.Get(context.Background(), server.URL, nil, WithSigner(failing)); err == nil || !strings.Contains(err.Error(), "no credentials") {
+		t.Errorf("Get() with a failing signer error = %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("failing signer called %d times, want 1", calls)
+	}
+}
 
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusInternalServerError)
+func TestClientCredentialsSource(t *testing.T) {
+	var fetches int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&fetches, 1)
+		id, secret, _ := r.BasicAuth()
+		_ = r.ParseForm()
+		if id != "client" || secret != "s3cret" || r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("scope") != "read write" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = io.WriteString(w, `{"error":"invalid_client","error_description":"bad credentials"}`)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+		_, _ = fmt.Fprintf(w, `{"access_token":"tok-%d","token_type":"bearer","expires_in":3600}`, n)
 	}))
-	defer server.Close()
+	defer tokenServer.Close()
 
-	_, err := util.Get(context.Background(), server.URL, nil)
-	if err == nil {
-		t.Error("Expected error after retry exhaustion")
-	}
+	source := NewClientCredentialsSource(ClientCredentialsConfig{
+		TokenURL: tokenServer.URL, ClientID: "client", ClientSecret: cryptoutil.NewSecret("s3cret"), Scopes: []string{"read", "write"},
+	})
+	now := time.Now()
+	source.now = func() time.Time { return now }
 
-	var retryErr *RetryExhaustedError
-	if !errors.As(err, &retryErr) {
-		t.Errorf("Expected RetryExhaustedError, got %T", err)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if token, err := source.Token(context.Background()); err != nil || token.AccessToken.Reveal() != "tok-1" {
+				t.Errorf("Token() = %v, %v, want tok-1", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := atomic.LoadInt32(&fetches); got != 1 {
+		t.Errorf("token endpoint called %d times for concurrent callers, want 1", got)
 	}
-}
 
-func TestHTTPUtil_ContextCancellation(t *testing.T) {
-	logger := logrus.New()
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	token, _ := source.Token(context.Background())
+	if token.TokenType != "Bearer" || !token.Expiry.Equal(now.Add(time.Hour)) {
+		t.Errorf("Token() = %+v, want a Bearer token expiring in an hour", token)
+	}
+	if printed := fmt.Sprintf("%+v", token); strings.Contains(printed, "tok-1") {
+		t.Errorf("printing a Token leaked the access token: %s", printed)
+	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	cancel() // Cancel immediately
+	// Refreshed once inside the RefreshBefore window, not only at expiry
+	now = now.Add(59*time.Minute + time.Second)
+	if token, _ := source.Token(context.Background()); token.AccessToken.Reveal() != "tok-2" {
+		t.Errorf("Token() near expiry = %s, want a refreshed token", token.AccessToken.Reveal())
+	}
+	source.Invalidate("tok-1") // stale value: keeps tok-2
+	if token, _ := source.Token(context.Background()); token.AccessToken.Reveal() != "tok-2" {
+		t.Errorf("Invalidate() of an old token dropped the current one")
+	}
 
-	_, err := util.Get(ctx, "http://example.com", nil)
-	if err == nil {
-		t.Error("Expected context cancellation error")
+	bad := NewClientCredentialsSource(ClientCredentialsConfig{TokenURL: tokenServer.URL, ClientID: "client", ClientSecret: cryptoutil.NewSecret("wrong")})
+	if _, err := bad.Token(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid_client") {
+		t.Errorf("Token() with bad credentials error = %v, want invalid_client", err)
 	}
 }
 
-func TestHTTPUtil_RateLimitWithRetryAfter(t *testing.T) {
-	logger := logrus.New()
-	logger.SetLevel(logrus.ErrorLevel) // Reduce log noise
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
-	util.MaxRetries = 1
-	util.InitialWait = 1 * time.Millisecond
+func TestHTTPUtil_BearerAuth(t *testing.T) {
+	var fetches int32
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&fetches, 1)
+		_, _ = fmt.Fprintf(w, `{"access_token":"tok-%d","expires_in":3600}`, n)
+	}))
+	defer tokenServer.Close()
 
-	callCount := 0
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		callCount++
-		if callCount == 1 {
-			w.Header().Set("Retry-After", "1")
-			w.WriteHeader(http.StatusTooManyRequests)
-		} else {
-			w.WriteHeader(http.StatusOK)
+	var revoked atomic.Value
+	revoked.Store("tok-1")
+	var bodies []string
+	var mu sync.Mutex
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		mu.Unlock()
+		if r.Header.Get("Authorization") == "Bearer "+revoked.Load().(string) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
 		}
+		w.WriteHeader(http.StatusOK)
 	}))
-	defer server.Close()
+	defer api.Close()
 
-	start := time.Now()
-	_, err := util.Get(context.Background(), server.URL, nil)
-	duration := time.Since(start)
+	source := NewClientCredentialsSource(ClientCredentialsConfig{TokenURL: tokenServer.URL, ClientID: "c", ClientSecret: cryptoutil.NewSecret("s"), CredentialsInBody: true})
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 0}).(*HTTPUtil)
+	util.Use(BearerAuth(source, true))
 
-	if err != nil {
-		t.Errorf("Expected success after rate limit retry, got: %v", err)
+	resp, err := util.Post(context.Background(), api.URL, strings.NewReader("payload"), nil)
+	util.CloseResponse(resp)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Post() = %v, %v, want 200 after refreshing the revoked token", resp, err)
 	}
-	if duration < 800*time.Millisecond {
-		t.Errorf("Expected to wait for Retry-After header, but completed too quickly: %v", duration)
+	if len(bodies) != 2 || bodies[1] != "payload" {
+		t.Errorf("server saw bodies %q, want the request replayed with its body", bodies)
 	}
-}
-
-func TestDecodeJSON_InvalidJSON(t *testing.T) {
-	testCases := []struct {
-		name string
-		json string
-	}{
-		{"missing quote", `{"invalid": json}`},
-		{"missing comma", `{"a": "b" "c": "d"}`},
-		{"trailing comma", `{"valid": "json",}`},
-		{"empty body", ``},
+	if got := atomic.LoadInt32(&fetches); got != 2 {
+		t.Errorf("token fetches = %d, want 2", got)
 	}
 
-	util := &HTTPUtil{}
-	for _, tc := range testCases {
-		t.Run(tc.name, func(t *testing.T) {
-			resp := &http.Response{Body: io.NopCloser(bytes.NewBufferString(tc.json))}
-			var result map[string]any
-			err := util.DecodeJSON(resp, &result)
-			if err == nil {
-				t.Errorf("Expected JSON decode error for %s", tc.name)
-			}
-		})
+	// Without refreshOnUnauthorized the 401 is returned as is
+	revoked.Store("tok-2")
+	plain := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 0}).(*HTTPUtil)
+	plain.Use(BearerAuth(source, false))
+	resp, err = plain.Get(context.Background(), api.URL, nil)
+	util.CloseResponse(resp)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Get() = %v, %v, want the 401", resp, err)
 	}
 }
 
-func TestCloseResponse_NilResponse(t *testing.T) {
-	util := &HTTPUtil{}
-	util.CloseResponse(nil)
-}
+func TestHTTPUtil_DefaultHeaders(t *testing.T) {
+	var mu sync.Mutex
+	var seen http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = r.Header.Clone()
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
 
-func TestCloseResponse_NilBody(t *testing.T) {
-	util := &HTTPUtil{}
-	resp := &http.Response{Body: nil}
-	util.CloseResponse(resp)
-}
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{Headers: map[string]string{"user-agent": "billing/1.4", "X-Tenant": "acme"}})
+	get := func(headers map[string]string) http.Header {
+		t.Helper()
+		resp, err := util.Get(context.Background(), server.URL, headers)
+		if err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+		util.CloseResponse(resp)
+		mu.Lock()
+		defer mu.Unlock()
+		return seen
+	}
 
-func TestGetHeader_CaseInsensitive(t *testing.T) {
-	util := &HTTPUtil{}
-	resp := &http.Response{
-		Header: http.Header{
-			"Content-Type": []string{"application/json"},
+	tests := []struct {
+		name    string
+		setup   func()
+		headers map[string]string
+		want    map[string]string
+	}{
+		{
+			name: "config headers",
+			want: map[string]string{"User-Agent": "billing/1.4", "X-Tenant": "acme", "Authorization": ""},
+		},
+		{
+			name:  "basic auth",
+			setup: func() { util.SetBasicAuth("user", cryptoutil.NewSecret("pa:ss")) },
+			want:  map[string]string{"Authorization": "Basic dXNlcjpwYTpzcw=="},
+		},
+		{
+			name:  "bearer token replaces basic auth",
+			setup: func() { util.SetBearerToken(cryptoutil.NewSecret("abc")) },
+			want:  map[string]string{"Authorization": "Bearer abc"},
+		},
+		{
+			name:    "call headers take precedence",
+			headers: map[string]string{"Authorization": "Bearer call", "X-Tenant": "other"},
+			want:    map[string]string{"Authorization": "Bearer call", "X-Tenant": "other"},
+		},
+		{
+			name:  "empty values remove headers",
+			setup: func() { util.SetBearerToken(cryptoutil.Secret{}); util.SetHeader("X-Tenant", "") },
+			want:  map[string]string{"Authorization": "", "X-Tenant": "", "User-Agent": "billing/1.4"},
 		},
 	}
 
-	// Go HTTP headers are case-insensitive
-	if val := util.GetHeader(resp, "content-type"); val != "application/json" {
-		t.Errorf("Expected case-insensitive header lookup to work")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			got := get(tt.headers)
+			for k, want := range tt.want {
+				if got.Get(k) != want {
+					t.Errorf("header %s = %q, want %q", k, got.Get(k), want)
+				}
+			}
+		})
 	}
 }
 
-func TestHTTPUtil_WithCustomHeaders(t *testing.T) {
-	logger := logrus.New()
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
-
+func TestHTTPUtil_Cookies(t *testing.T) {
+	var attempts int32
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.Header.Get("X-Custom") != "test-value" {
-			t.Errorf("Expected custom header, got: %s", r.Header.Get("X-Custom"))
+		switch r.URL.Path {
+		case "/login":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+			w.WriteHeader(http.StatusNoContent)
+		case "/data":
+			if atomic.AddInt32(&attempts, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			if c, err := r.Cookie("session"); err != nil || c.Value != "abc" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
 		}
-		w.WriteHeader(http.StatusOK)
 	}))
 	defer server.Close()
 
-	headers := map[string]string{
-		"X-Custom": "test-value",
-	}
-
-	_, err := util.Get(context.Background(), server.URL, headers)
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{EnableCookies: true, MaxRetries: 2, InitialWait: time.Millisecond})
+	resp, err := util.Post(context.Background(), server.URL+"/login", nil, nil)
+	util.CloseResponse(resp)
 	if err != nil {
-		t.Errorf("Expected success with custom headers, got: %v", err)
+		t.Fatalf("Post(login) unexpected error: %v", err)
 	}
-}
 
-func TestNewHTTPUtil_DefaultSettings(t *testing.T) {
-	logger := logrus.New()
-	client := NewHTTPUtil(logger, nil)
-	util := client.(*HTTPUtil)
+	resp, err = util.Get(context.Background(), server.URL+"/data", nil)
+	util.CloseResponse(resp)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Get(data) = %v, %v, want 200 with the session cookie on the retry", resp, err)
+	}
+
+	cookies, err := util.Cookies(server.URL)
+	if err != nil || len(cookies) != 1 || cookies[0].Value != "abc" {
+		t.Errorf("Cookies() = %v, %v, want the session cookie", cookies, err)
+	}
+	if err := util.ClearCookies("127.0.0.1"); err != nil {
+		t.Fatalf("ClearCookies() unexpected error: %v", err)
+	}
+	if cookies, _ := util.Cookies(server.URL); len(cookies) != 0 {
+		t.Errorf("Cookies() after ClearCookies = %v, want none", cookies)
+	}
+
+	if cookies, err := NewHTTPUtil(nil, nil).Cookies(server.URL); err != nil || cookies != nil {
+		t.Errorf("Cookies() without a jar = %v, %v, want nil", cookies, err)
+	}
+	custom, _ := cookiejar.New(nil)
+	if err := NewHTTPUtil(nil, &HTTPConfig{CookieJar: custom}).ClearCookies(""); !errors.Is(err, ErrCookiesNotClearable) {
+		t.Errorf("ClearCookies() on a plain cookiejar error = %v, want ErrCookiesNotClearable", err)
+	}
+}
+
+func TestMemoryCookieJar_ClearDomain(t *testing.T) {
+	mustParse := func(raw string) *url.URL {
+		u, _ := url.Parse(raw)
+		return u
+	}
+	apex, sub, other := mustParse("https://example.com/"), mustParse("https://api.example.com/v1/"), mustParse("https://other.org/")
+
+	jar := NewMemoryCookieJar(nil)
+	fill := func() {
+		jar.SetCookies(apex, []*http.Cookie{{Name: "wide", Value: "1", Domain: "example.com"}})
+		jar.SetCookies(sub, []*http.Cookie{{Name: "api", Value: "2"}})
+		jar.SetCookies(other, []*http.Cookie{{Name: "o", Value: "3"}})
+	}
+	names := func(u *url.URL) []string {
+		var got []string
+		for _, c := range jar.Cookies(u) {
+			got = append(got, c.Name)
+		}
+		sort.Strings(got)
+		return got
+	}
+
+	fill()
+	if got := names(sub); !reflect.DeepEqual(got, []string{"api", "wide"}) {
+		t.Fatalf("Cookies(api.example.com) = %v, want [api wide]", got)
+	}
+
+	jar.ClearDomain("api.example.com")
+	if got := names(sub); !reflect.DeepEqual(got, []string{"wide"}) {
+		t.Errorf("after ClearDomain(api.example.com) = %v, want [wide]", got)
+	}
+
+	jar.ClearDomain(".Example.com")
+	if got := names(sub); len(got) != 0 {
+		t.Errorf("after ClearDomain(example.com) = %v, want none", got)
+	}
+	if got := names(other); !reflect.DeepEqual(got, []string{"o"}) {
+		t.Errorf("ClearDomain() removed cookies of another domain: %v", got)
+	}
+
+	fill()
+	jar.ClearDomain("")
+	if len(names(sub)) != 0 || len(names(other)) != 0 {
+		t.Error("ClearDomain(\"\") should empty the jar")
+	}
+}
+
+func TestHTTPUtil_Proxy(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "direct")
+	}))
+	defer target.Close()
+	newProxy := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// A forward proxy receives the absolute target URL
+			if r.URL.Host != strings.TrimPrefix(target.URL, "http://") {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			_, _ = io.WriteString(w, name+" "+r.Header.Get("Proxy-Authorization"))
+		}))
+	}
+	corp, other := newProxy("corp"), newProxy("other")
+	defer corp.Close()
+	defer other.Close()
+
+	corpURL := strings.Replace(corp.URL, "http://", "http://user:pass@", 1)
+	auth := "Basic " + base64.StdEncoding.EncodeToString([]byte("user:pass"))
+
+	tests := []struct {
+		name     string
+		config   *ProxyConfig
+		opts     []RequestOption
+		expected string
+	}{
+		{name: "no proxy configured", expected: "direct"},
+		{name: "configured proxy with credentials", config: &ProxyConfig{HTTP: corpURL}, expected: "corp " + auth},
+		{name: "https proxy does not apply to http", config: &ProxyConfig{HTTPS: corp.URL}, expected: "direct"},
+		{name: "NoProxy IP", config: &ProxyConfig{HTTP: corp.URL, NoProxy: []string{"127.0.0.1"}}, expected: "direct"},
+		{name: "NoProxy CIDR", config: &ProxyConfig{HTTP: corp.URL, NoProxy: []string{"127.0.0.0/8"}}, expected: "direct"},
+		{name: "NoProxy other domain", config: &ProxyConfig{HTTP: corp.URL, NoProxy: []string{".internal"}}, expected: "corp "},
+		{name: "per-request proxy", opts: []RequestOption{WithProxy(other.URL)}, expected: "other "},
+		{name: "per-request override", config: &ProxyConfig{HTTP: corp.URL}, opts: []RequestOption{WithProxy(other.URL)}, expected: "other "},
+		{name: "per-request direct", config: &ProxyConfig{HTTP: corp.URL}, opts: []RequestOption{WithProxy(DirectProxy)}, expected: "direct"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{Proxy: tt.config, MaxRetries: 0})
+			resp, err := util.Get(context.Background(), target.URL, nil, tt.opts...)
+			if err != nil {
+				t.Fatalf("Get() unexpected error: %v", err)
+			}
+			body, _ := util.ReadBody(resp)
+			if string(body) != tt.expected {
+				t.Errorf("Get() body = %q, want %q", body, tt.expected)
+			}
+		})
+	}
+
+	t.Run("invalid proxy URLs", func(t *testing.T) {
+		util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{Proxy: &ProxyConfig{HTTP: "ftp://proxy:21"}})
+		if _, err := util.Get(context.Background(), target.URL, nil); err == nil || !strings.Contains(err.Error(), "unsupported scheme") {
+			t.Errorf("Get() with an ftp proxy error = %v, want unsupported scheme", err)
+		}
+		util = NewHTTPUtil(logutil.NewNopLogger(), nil)
+		if _, err := util.Get(context.Background(), target.URL, nil, WithProxy("http://")); err == nil || !strings.Contains(err.Error(), "missing host") {
+			t.Errorf("Get() with an empty proxy host error = %v, want missing host", err)
+		}
+	})
+}
+
+func TestProxySelector(t *testing.T) {
+	selector := newProxySelector(&ProxyConfig{HTTP: "proxy.corp:3128", HTTPS: "socks5://gw:1080", NoProxy: []string{"corp.example", "10.0.0.0/8"}})
+	tests := []struct {
+		url      string
+		expected string
+	}{
+		{url: "http://api.example.com/", expected: "http://proxy.corp:3128"},
+		{url: "https://api.example.com/", expected: "socks5://gw:1080"},
+		{url: "https://corp.example/", expected: ""},
+		{url: "https://build.corp.example/", expected: ""},
+		{url: "https://notcorp.example/", expected: "socks5://gw:1080"},
+		{url: "http://10.1.2.3:8080/", expected: ""},
+		{url: "http://11.1.2.3/", expected: "http://proxy.corp:3128"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			got, err := selector.proxy(req)
+			gotURL := ""
+			if got != nil {
+				gotURL = got.String()
+			}
+			if err != nil || gotURL != tt.expected {
+				t.Errorf("proxy(%s) = %q, %v, want %q", tt.url, gotURL, err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestHTTPUtil_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "secure")
+	}))
+	defer server.Close()
+
+	serverPool := x509.NewCertPool()
+	serverPool.AddCert(server.Certificate())
+	serverPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	if err := os.WriteFile(caFile, serverPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Self-signed client certificate, trusted by the mutual TLS server below
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "billing"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	certFile, keyFile := filepath.Join(dir, "client.pem"), filepath.Join(dir, "client-key.pem")
+	if err := os.WriteFile(certFile, certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(certPEM)
+
+	mtls := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "hello "+r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	mtls.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs, MaxVersion: tls.VersionTLS12}
+	mtls.StartTLS()
+	defer mtls.Close()
+
+	tests := []struct {
+		name     string
+		url      string
+		config   *TLSConfig
+		expected string
+		wantErr  string
+	}{
+		{name: "system roots reject private CA", url: server.URL, wantErr: "certificate"},
+		{name: "RootCAs pool", url: server.URL, config: &TLSConfig{RootCAs: serverPool}, expected: "secure"},
+		{name: "CA PEM", url: server.URL, config: &TLSConfig{CAPEM: serverPEM}, expected: "secure"},
+		{name: "CA file", url: server.URL, config: &TLSConfig{CAFiles: []string{caFile}}, expected: "secure"},
+		{name: "insecure skip verify", url: server.URL, config: &TLSConfig{InsecureSkipVerify: true}, expected: "secure"},
+		{name: "missing CA file", url: server.URL, config: &TLSConfig{CAFiles: []string{filepath.Join(dir, "missing.pem")}}, wantErr: "invalid TLS configuration"},
+		{name: "CA file without certificates", url: server.URL, config: &TLSConfig{CAFiles: []string{keyFile}}, wantErr: "no PEM certificates"},
+		{name: "RootCAs with system roots", url: server.URL, config: &TLSConfig{RootCAs: serverPool, AppendSystemRoots: true}, wantErr: "cannot be combined"},
+		{name: "client certificate files", url: mtls.URL, config: &TLSConfig{RootCAs: mtlsPool(mtls), ClientCertFile: certFile, ClientKeyFile: keyFile}, expected: "hello billing"},
+		{name: "client certificate PEM", url: mtls.URL, config: &TLSConfig{RootCAs: mtlsPool(mtls), ClientCertPEM: certPEM, ClientKeyPEM: keyPEM}, expected: "hello billing"},
+		{name: "client certificate without key", url: mtls.URL, config: &TLSConfig{ClientCertFile: certFile}, wantErr: "needs both"},
+		{name: "client certificate as file and PEM", url: mtls.URL, config: &TLSConfig{ClientCertFile: certFile, ClientKeyFile: keyFile, ClientCertPEM: certPEM}, wantErr: "both as files and as PEM"},
+		{name: "server requires client certificate", url: mtls.URL, config: &TLSConfig{RootCAs: mtlsPool(mtls)}, wantErr: "tls"},
+		{name: "minimum version above server maximum", url: mtls.URL, config: &TLSConfig{RootCAs: mtlsPool(mtls), ClientCertPEM: certPEM, ClientKeyPEM: keyPEM, MinVersion: tls.VersionTLS13}, wantErr: "protocol version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{TLS: tt.config, MaxRetries: 1, InitialWait: time.Millisecond, MaxWait: time.Millisecond})
+			resp, err := util.Get(context.Background(), tt.url, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Get() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Get() unexpected error: %v", err)
+			}
+			body, _ := util.ReadBody(resp)
+			if string(body) != tt.expected {
+				t.Errorf("Get() body = %q, want %q", body, tt.expected)
+			}
+		})
+	}
+}
+
+// mtlsPool trusts the certificate of server
+func mtlsPool(server *httptest.Server) *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+	return pool
+}
+
+// fieldSink records the fields of every log entry
+type fieldSink struct {
+	mu      sync.Mutex
+	entries []logutil.Fields
+}
+
+func (s *fieldSink) Enabled(logutil.Level) bool { return true }
+
+func (s *fieldSink) Emit(_ context.Context, _ logutil.Level, msg string, fields logutil.Fields) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := logutil.Fields{"msg": msg}
+	for k, v := range fields {
+		entry[k] = v
+	}
+	s.entries = append(s.entries, entry)
+}
+
+func TestHTTPUtil_ContextLogFields(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sink := &fieldSink{}
+	util := NewHTTPUtil(logutil.NewLogger(sink, nil), &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond})
+	ctx := logutil.WithFields(context.Background(), logutil.Fields{"request_id": "req-7", "user_id": "u-1"})
+	if _, err := util.Get(ctx, server.URL, nil); err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+
+	messages := map[string]bool{}
+	for _, entry := range sink.entries {
+		messages[entry["msg"].(string)] = true
+		if entry["request_id"] != "req-7" || entry["user_id"] != "u-1" {
+			t.Errorf("log %q fields = %v, want request_id and user_id from the context", entry["msg"], entry)
+		}
+	}
+	for _, msg := range []string{"Starting HTTP request", "Request failed, retrying", "Request completed successfully"} {
+		if !messages[msg] {
+			t.Errorf("missing log %q, got %v", msg, messages)
+		}
+	}
+}
+
+func TestHTTPUtil_HTTP3(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Alt-Svc", `h3=":443"; ma=60`)
+		_, _ = io.WriteString(w, "tcp "+string(body))
+	}))
+	defer server.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	// fakeH3 stands in for a QUIC round tripper, failing while down is set
+	var h3Calls int32
+	var down atomic.Bool
+	fakeH3 := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&h3Calls, 1)
+		if down.Load() {
+			return nil, errors.New("quic: no recent network activity")
+		}
+		body := "h3"
+		if req.Body != nil {
+			data, _ := io.ReadAll(req.Body)
+			body += " " + string(data)
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}, Request: req}, nil
+	})
+	get := func(t *testing.T, util HTTPClient, opts ...RequestOption) string {
+		t.Helper()
+		resp, err := util.Get(context.Background(), server.URL, nil, opts...)
+		if err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+		body, _ := util.ReadBody(resp)
+		return string(body)
+	}
+	newUtil := func(config *HTTP3Config) HTTPClient {
+		return NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{TLS: &TLSConfig{RootCAs: pool}, HTTP3: config, MaxRetries: 1, InitialWait: time.Millisecond})
+	}
+
+	t.Run("HTTP/3 first", func(t *testing.T) {
+		atomic.StoreInt32(&h3Calls, 0)
+		down.Store(false)
+		util := newUtil(&HTTP3Config{Transport: fakeH3})
+		if got := get(t, util); got != "h3" {
+			t.Errorf("Get() body = %q, want h3", got)
+		}
+		resp, err := util.Post(context.Background(), server.URL, strings.NewReader("order"), map[string]string{"Content-Type": "text/plain"})
+		if err != nil {
+			t.Fatalf("Post() unexpected error: %v", err)
+		}
+		if body, _ := util.ReadBody(resp); string(body) != "h3 order" {
+			t.Errorf("Post() body = %q, want %q", body, "h3 order")
+		}
+	})
+
+	t.Run("falls back and remembers the failure", func(t *testing.T) {
+		atomic.StoreInt32(&h3Calls, 0)
+		down.Store(true)
+		util := newUtil(&HTTP3Config{Transport: fakeH3})
+		resp, err := util.Post(context.Background(), server.URL, strings.NewReader("order"), map[string]string{"Content-Type": "text/plain"})
+		if err != nil {
+			t.Fatalf("Post() unexpected error: %v", err)
+		}
+		if body, _ := util.ReadBody(resp); string(body) != "tcp order" {
+			t.Errorf("Post() body = %q, want the replayed body over TCP", body)
+		}
+		if got := get(t, util); got != "tcp " {
+			t.Errorf("Get() body = %q, want tcp", got)
+		}
+		if calls := atomic.LoadInt32(&h3Calls); calls != 1 {
+			t.Errorf("HTTP/3 attempts = %d, want 1 while the host is marked broken", calls)
+		}
+	})
+
+	t.Run("Alt-Svc discovery", func(t *testing.T) {
+		atomic.StoreInt32(&h3Calls, 0)
+		down.Store(false)
+		util := newUtil(&HTTP3Config{Transport: fakeH3, RequireAltSvc: true})
+		if got := get(t, util); got != "tcp " {
+			t.Errorf("first Get() body = %q, want tcp before any Alt-Svc", got)
+		}
+		if got := get(t, util); got != "h3" {
+			t.Errorf("second Get() body = %q, want h3 after Alt-Svc", got)
+		}
+	})
+
+	t.Run("disabled without a transport", func(t *testing.T) {
+		util := newUtil(&HTTP3Config{}).(*HTTPUtil)
+		if _, ok := util.Client.Transport.(*http.Transport); !ok {
+			t.Errorf("Transport = %T, want *http.Transport", util.Client.Transport)
+		}
+	})
+}
+
+// roundTripperFunc adapts a function to http.RoundTripper
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestParseAltSvcH3(t *testing.T) {
+	tests := []struct {
+		value  string
+		maxAge time.Duration
+		ok     bool
+	}{
+		{value: `h3=":443"`, maxAge: defaultAltSvcMaxAge, ok: true},
+		{value: `h2=":443", h3=":443"; ma=3600; persist=1`, maxAge: time.Hour, ok: true},
+		{value: `h3="alt.example.com:443"`},
+		{value: `h3-29=":443"`},
+		{value: `clear`},
+	}
+	for _, tt := range tests {
+		maxAge, ok := parseAltSvcH3(tt.value)
+		if maxAge != tt.maxAge || ok != tt.ok {
+			t.Errorf("parseAltSvcH3(%q) = %v, %v, want %v, %v", tt.value, maxAge, ok, tt.maxAge, tt.ok)
+		}
+	}
+}
+
+func TestHTTPUtil_Tracing(t *testing.T) {
+	var calls int32
+	var traceparents []string
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceparents = append(traceparents, r.Header.Get("Traceparent"))
+		mu.Unlock()
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{TracerProvider: provider, MaxRetries: 2, InitialWait: time.Millisecond})
+
+	resp, err := util.Get(context.Background(), strings.Replace(server.URL, "http://", "http://user:secret@", 1)+"/orders", nil)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	util.CloseResponse(resp)
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("ended spans = %d, want 2 attempts and 1 request", len(spans))
+	}
+	first, second, request := spans[0], spans[1], spans[2]
+	if request.SpanKind() != trace.SpanKindInternal || first.SpanKind() != trace.SpanKindClient {
+		t.Errorf("span kinds = %v/%v, want internal request and client attempts", request.SpanKind(), first.SpanKind())
+	}
+	for _, attempt := range []sdktrace.ReadOnlySpan{first, second} {
+		if attempt.Parent().SpanID() != request.SpanContext().SpanID() {
+			t.Errorf("attempt %q parent = %v, want the request span", attempt.Name(), attempt.Parent().SpanID())
+		}
+	}
+	if first.Status().Code != codes.Error || second.Status().Code != codes.Unset || request.Status().Code != codes.Unset {
+		t.Errorf("statuses = %v/%v/%v, want the 503 attempt failed and the rest unset", first.Status().Code, second.Status().Code, request.Status().Code)
+	}
+
+	attrs := func(span sdktrace.ReadOnlySpan) map[string]string {
+		m := map[string]string{}
+		for _, kv := range span.Attributes() {
+			m[string(kv.Key)] = kv.Value.Emit()
+		}
+		return m
+	}
+	if got := attrs(request); got["http.request.method"] != "GET" || got["http.response.status_code"] != "200" || strings.Contains(got["url.full"], "secret") {
+		t.Errorf("request attributes = %v, want method, final status and a URL without credentials", got)
+	}
+	if got := attrs(second); got["http.request.resend_count"] != "1" {
+		t.Errorf("retry attempt attributes = %v, want resend_count 1", got)
+	}
+	if events := request.Events(); len(events) != 1 || events[0].Name != "retry" {
+		t.Errorf("request events = %v, want one retry event", events)
+	}
+
+	// Each attempt sends its own span as the W3C traceparent parent ID
+	for i, attempt := range []sdktrace.ReadOnlySpan{first, second} {
+		want := fmt.Sprintf("00-%s-%s-01", attempt.SpanContext().TraceID(), attempt.SpanContext().SpanID())
+		if traceparents[i] != want {
+			t.Errorf("attempt %d traceparent = %q, want %q", i, traceparents[i], want)
+		}
+	}
+
+	t.Run("untraced clients send no traceparent", func(t *testing.T) {
+		mu.Lock()
+		traceparents = nil
+		mu.Unlock()
+		plain := NewHTTPUtil(logutil.NewNopLogger(), nil).(*HTTPUtil)
+		if plain.tracing != nil {
+			t.Error("tracing should be nil without a TracerProvider")
+		}
+		resp, err := plain.Get(context.Background(), server.URL, nil)
+		if err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+		plain.CloseResponse(resp)
+		if traceparents[0] != "" {
+			t.Errorf("traceparent = %q, want none", traceparents[0])
+		}
+	})
+}
+
+func TestHTTPUtil_RequestStats(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" || atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 2, InitialWait: 10 * time.Millisecond, MaxWait: 10 * time.Millisecond})
+	var success, failure RequestOptions
+	var failureErr error
+	var failureResp *http.Response
+	util.SetSuccessHook(func(resp *http.Response, options RequestOptions) { success = options })
+	util.SetFailureHook(func(resp *http.Response, err error, options RequestOptions) {
+		failure, failureErr, failureResp = options, err, resp
+	})
+
+	resp, err := util.Get(context.Background(), server.URL+"/flaky", nil)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	util.CloseResponse(resp)
+	stats := success.Stats
+	if stats.Attempts != 3 || len(stats.AttemptDurations) != 3 {
+		t.Errorf("success Stats = %+v, want 3 attempts with a duration each", stats)
+	}
+	var sum time.Duration
+	for _, d := range stats.AttemptDurations {
+		sum += d
+	}
+	if stats.Elapsed < sum+15*time.Millisecond {
+		t.Errorf("Elapsed = %v, want the attempts (%v) plus two backoff waits", stats.Elapsed, sum)
+	}
+	if failure.Method != "" {
+		t.Error("FailureHook should not run for a successful request")
+	}
+
+	resp, err = util.Get(context.Background(), server.URL+"/down", nil, WithMaxRetries(1))
+	util.CloseResponse(resp)
+	var exhausted *RetryExhaustedError
+	if !errors.As(err, &exhausted) || !errors.Is(failureErr, err) {
+		t.Fatalf("FailureHook error = %v, want the returned %v", failureErr, err)
+	}
+	if failure.Stats.Attempts != 2 || failure.URL != server.URL+"/down" || failureResp == nil || failureResp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("FailureHook got %d attempts for %s and resp %v, want 2 attempts and the last 503", failure.Stats.Attempts, failure.URL, failureResp)
+	}
+}
+
+func TestHTTPUtil_RequestID(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Get("X-Correlation-ID"))
+		mu.Unlock()
+		if atomic.AddInt32(&calls, 1) == 1 || r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var next int32
+	sink := &fieldSink{}
+	util := NewHTTPUtil(logutil.NewLogger(sink, nil), &HTTPConfig{
+		MaxRetries:         1,
+		InitialWait:        time.Millisecond,
+		GenerateRequestID:  true,
+		RequestIDHeader:    "x-correlation-id",
+		RequestIDGenerator: func() string { return fmt.Sprintf("gen-%d", atomic.AddInt32(&next, 1)) },
+	})
+
+	// A generated ID is reused by the retry, logged and returned with the response
+	resp, err := util.Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get() unexpected error: %v", err)
+	}
+	util.CloseResponse(resp)
+	if id := RequestID(resp); id != "gen-1" {
+		t.Errorf("RequestID() = %q, want gen-1", id)
+	}
+	if len(seen) != 2 || seen[0] != "gen-1" || seen[1] != "gen-1" {
+		t.Errorf("sent IDs = %v, want gen-1 on both attempts", seen)
+	}
+	for _, entry := range sink.entries {
+		if entry["request_id"] != "gen-1" {
+			t.Errorf("log %q fields = %v, want request_id gen-1", entry["msg"], entry)
+		}
+	}
+
+	// An ID from the context or the call's headers takes precedence over generation
+	seen = nil
+	resp, _ = util.Get(contextutil.WithRequestID(context.Background(), "ctx-id"), server.URL, nil)
+	util.CloseResponse(resp)
+	if RequestID(resp) != "ctx-id" || seen[0] != "ctx-id" {
+		t.Errorf("context ID: RequestID() = %q, sent %v; want ctx-id", RequestID(resp), seen)
+	}
+	seen = nil
+	resp, _ = util.Get(context.Background(), server.URL, map[string]string{"X-Correlation-Id": "hdr-id"})
+	util.CloseResponse(resp)
+	if RequestID(resp) != "hdr-id" || seen[0] != "hdr-id" {
+		t.Errorf("header ID: RequestID() = %q, sent %v; want hdr-id", RequestID(resp), seen)
+	}
+
+	// A failed request reports its ID in the error
+	_, err = util.Get(context.Background(), server.URL+"/down", nil)
+	var exhausted *RetryExhaustedError
+	if !errors.As(err, &exhausted) || exhausted.RequestID != "gen-2" {
+		t.Errorf("Get() error = %v, want *RetryExhaustedError with RequestID gen-2", err)
+	}
+
+	// Without GenerateRequestID no header is sent
+	seen = nil
+	resp, _ = NewHTTPUtil(nil, &HTTPConfig{RequestIDHeader: "X-Correlation-ID"}).Get(context.Background(), server.URL, nil)
+	if resp != nil {
+		_ = resp.Body.Close()
+	}
+	if RequestID(resp) != "" || seen[0] != "" {
+		t.Errorf("RequestID() = %q, sent %v; want no ID", RequestID(resp), seen)
+	}
+}
+
+func TestHTTPUtil_ContentTypePresets(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond})
+	tests := []struct {
+		name        string
+		option      RequestOption
+		contentType string
+		accept      string
+	}{
+		{"json", WithJSON(), "application/json", "application/json"},
+		{"form", WithFormURLEncoded(), "application/x-www-form-urlencoded", "application/json"},
+		{"text", WithPlainText(), "text/plain; charset=utf-8", "text/plain; charset=utf-8"},
+		{"binary", WithBinary("application/pdf"), "application/pdf", "application/pdf"},
+		{"binary default", WithBinary(""), "application/octet-stream", "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			headers := map[string]string{"content-type": "text/html", "X-Trace": "1"}
+			resp, err := util.Post(context.Background(), server.URL, strings.NewReader("body"), headers, tt.option)
+			if err != nil {
+				t.Fatalf("Post() unexpected error: %v", err)
+			}
+			util.CloseResponse(resp)
+			if got.Get("Content-Type") != tt.contentType || got.Get("Accept") != tt.accept {
+				t.Errorf("Content-Type, Accept = %q, %q; want %q, %q", got.Get("Content-Type"), got.Get("Accept"), tt.contentType, tt.accept)
+			}
+			if got.Get("X-Trace") != "1" {
+				t.Errorf("X-Trace = %q, want other headers kept", got.Get("X-Trace"))
+			}
+			if len(headers) != 2 || headers["content-type"] != "text/html" {
+				t.Errorf("caller headers modified: %v", headers)
+			}
+		})
+	}
+}
+
+func TestHTTPUtil_Do(t *testing.T) {
+	var calls int32
+	var gotMethod, gotDepth, gotQuery, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotMethod, gotDepth, gotQuery, gotBody = r.Method, r.Header.Get("Depth"), r.URL.RawQuery, string(body)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusMultiStatus)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond})
+	var hooked RequestOptions
+	util.SetSuccessHook(func(_ *http.Response, options RequestOptions) { hooked = options })
+
+	resp, err := util.Do(RequestOptions{
+		Method:  "PROPFIND",
+		URL:     server.URL + "/dav",
+		Body:    strings.NewReader("<propfind/>"),
+		Headers: map[string]string{"Depth": "1"},
+		Query:   url.Values{"v": {"2"}},
+	})
+	if err != nil {
+		t.Fatalf("Do() unexpected error: %v", err)
+	}
+	util.CloseResponse(resp)
+	if resp.StatusCode != http.StatusMultiStatus {
+		t.Errorf("Do() status = %d, want 207", resp.StatusCode)
+	}
+	if gotMethod != "PROPFIND" || gotDepth != "1" || gotQuery != "v=2" || gotBody != "<propfind/>" {
+		t.Errorf("server saw %s Depth=%q ?%s %q on the retry", gotMethod, gotDepth, gotQuery, gotBody)
+	}
+	if calls != 2 || hooked.Stats.Attempts != 2 || hooked.Method != "PROPFIND" {
+		t.Errorf("calls = %d, hook saw %s with %d attempts; want a retried PROPFIND", calls, hooked.Method, hooked.Stats.Attempts)
+	}
+
+	if _, err := util.Do(RequestOptions{URL: server.URL}); err == nil {
+		t.Error("Do() without a method should fail")
+	}
+}
+
+func TestHTTPUtil_UploadProgress(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 256*1024)
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if len(body) != len(payload) || (r.Method == http.MethodPut && r.ContentLength != int64(len(payload))) {
+			t.Errorf("server got %d bytes with Content-Length %d, want %d", len(body), r.ContentLength, len(payload))
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond})
+
+	type report struct{ sent, total int64 }
+	var reports []report
+	resp, err := util.Put(context.Background(), server.URL, bytes.NewReader(payload), nil, WithUploadProgress(func(sent, total int64) {
+		reports = append(reports, report{sent, total})
+	}))
+	if err != nil {
+		t.Fatalf("Put() unexpected error: %v", err)
+	}
+	util.CloseResponse(resp)
+
+	total := int64(len(payload))
+	resets, completed := 0, 0
+	var last int64
+	for i, r := range reports {
+		if r.total != total {
+			t.Fatalf("report %d total = %d, want %d", i, r.total, total)
+		}
+		switch {
+		case r.sent == 0:
+			resets++
+		case r.sent < last:
+			t.Fatalf("report %d sent = %d went back from %d without a reset", i, r.sent, last)
+		}
+		if r.sent == total {
+			completed++
+		}
+		last = r.sent
+	}
+	if resets != 1 || completed != 2 || reports[len(reports)-1] != (report{total, total}) {
+		t.Errorf("got %d resets and %d completed uploads ending at %v, want 1 reset and 2 uploads", resets, completed, reports[len(reports)-1])
+	}
+
+	// A streamed body of unknown length reports a total of -1
+	reports = nil
+	resp, err = util.Do(RequestOptions{
+		Method:         http.MethodPost,
+		URL:            server.URL,
+		GetBody:        func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(payload)), nil },
+		Headers:        map[string]string{},
+		UploadProgress: func(sent, total int64) { reports = append(reports, report{sent, total}) },
+	})
+	if err == nil {
+		util.CloseResponse(resp)
+	}
+	if len(reports) == 0 || reports[len(reports)-1] != (report{total, -1}) {
+		t.Errorf("streamed upload reports end at %v, want {%d -1}", reports, total)
+	}
+}
+
+func TestHTTPUtil_StreamSSE(t *testing.T) {
+	var connections int32
+	var lastEventIDs []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&connections, 1)
+		lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+		if r.Header.Get("Accept") != "text/event-stream" {
+			t.Errorf("Accept = %q, want text/event-stream", r.Header.Get("Accept"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+		flusher := w.(http.Flusher)
+		switch n {
+		case 1:
+			_, _ = io.WriteString(w, "\ufeff: welcome\r\nretry: 5\r\n\r\nid: 1\r\ndata: first\r\n\r\n")
+			flusher.Flush()
+			_, _ = io.WriteString(w, "event: update\rid: 2\rdata: line one\rdata:line two\r\rdata: cut off")
+		case 2:
+			_, _ = io.WriteString(w, ": heartbeat\n\nid: 3\ndata: {\"done\":true}\n\n")
+		}
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, &HTTPConfig{
+		MaxRetries:    1,
+		InitialWait:   time.Millisecond,
+		ResponseCache: &ResponseCacheConfig{TTL: time.Minute},
+	})
+	var events []Event
+	err := util.StreamSSE(context.Background(), server.URL, nil, func(event Event) error {
+		events = append(events, event)
+		if event.ID == "3" {
+			return ErrStopStream
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamSSE() unexpected error: %v", err)
+	}
+
+	want := []Event{
+		{ID: "1", Type: "message", Data: "first"},
+		{ID: "2", Type: "update", Data: "line one\nline two"},
+		{ID: "3", Type: "message", Data: `{"done":true}`},
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("StreamSSE() events = %+v, want %+v", events, want)
+	}
+	if !reflect.DeepEqual(lastEventIDs, []string{"", "2"}) {
+		t.Errorf("Last-Event-ID headers = %q, want none then 2", lastEventIDs)
+	}
+}
+
+func TestHTTPUtil_StreamSSE_IdleTimeout(t *testing.T) {
+	var connections int32
+	release := make(chan struct{})
+	defer close(release)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		if atomic.AddInt32(&connections, 1) == 1 {
+			_, _ = io.WriteString(w, "data: stalled\n\n")
+			w.(http.Flusher).Flush()
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+			return
+		}
+		_, _ = io.WriteString(w, "data: resumed\n\n")
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond, StreamIdleTimeout: 50 * time.Millisecond})
+	var data []string
+	err := util.StreamSSE(context.Background(), server.URL, nil, func(event Event) error {
+		data = append(data, event.Data)
+		if event.Data == "resumed" {
+			return ErrStopStream
+		}
+		return nil
+	})
+	if err != nil || !reflect.DeepEqual(data, []string{"stalled", "resumed"}) {
+		t.Errorf("StreamSSE() = %v with events %v, want a reconnection after the idle timeout", err, data)
+	}
+}
+
+func TestHTTPUtil_StreamSSE_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/json":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = io.WriteString(w, "{}")
+		case "/done":
+			w.WriteHeader(http.StatusNoContent)
+		case "/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = io.WriteString(w, "data: x\n\n")
+		}
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond})
+	noop := func(Event) error { return nil }
+
+	var statusErr *StatusError
+	if err := util.StreamSSE(context.Background(), server.URL+"/missing", nil, noop); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("StreamSSE(404) error = %v, want *StatusError 404", err)
+	}
+	if err := util.StreamSSE(context.Background(), server.URL+"/json", nil, noop); err == nil || !strings.Contains(err.Error(), "content type") {
+		t.Errorf("StreamSSE(json) error = %v, want a content type error", err)
+	}
+	if err := util.StreamSSE(context.Background(), server.URL+"/done", nil, noop); err != nil {
+		t.Errorf("StreamSSE(204) error = %v, want nil", err)
+	}
+
+	errHandler := errors.New("handler failed")
+	if err := util.StreamSSE(context.Background(), server.URL+"/events", nil, func(Event) error { return errHandler }); !errors.Is(err, errHandler) {
+		t.Errorf("StreamSSE() error = %v, want the handler error", err)
+	}
+
+	// The server keeps closing the stream, so only cancellation ends it
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	var count int32
+	err := util.StreamSSE(ctx, server.URL+"/events", nil, func(Event) error { atomic.AddInt32(&count, 1); return nil })
+	if !errors.Is(err, context.DeadlineExceeded) || atomic.LoadInt32(&count) < 2 {
+		t.Errorf("StreamSSE() = %v after %d events, want reconnections until the deadline", err, count)
+	}
+}
+
+// feedbackLimiter records the feedback httputil forwards to a RateLimiter
+type feedbackLimiter struct {
+	mu       sync.Mutex
+	statuses []int
+	waits    []time.Duration
+}
+
+func (l *feedbackLimiter) Allow() bool { return true }
+func (l *feedbackLimiter) Reserve() ratelimitutil.Reservation {
+	return ratelimitutil.Reservation{Allowed: true}
+}
+func (l *feedbackLimiter) Wait(ctx context.Context) error { return nil }
+func (l *feedbackLimiter) Feedback(statusCode int, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.statuses = append(l.statuses, statusCode)
+	l.waits = append(l.waits, retryAfter)
+}
+
+func TestHTTPUtil_RateLimitFeedback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/throttled":
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case "/unavailable":
+			w.Header().Set("Retry-After", "soon")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	limiter := &feedbackLimiter{}
+	util := NewHTTPUtil(nil, &HTTPConfig{RateLimiter: limiter})
+	var hooked []RateLimitFeedback
+	util.SetRateLimitFeedbackHook(func(feedback RateLimitFeedback) {
+		hooked = append(hooked, feedback)
+	})
+
+	for _, path := range []string{"/ok", "/throttled", "/unavailable"} {
+		resp, _ := util.Get(context.Background(), server.URL+path, nil, WithMaxRetries(0))
+		util.CloseResponse(resp)
+	}
+
+	wantStatuses := []int{http.StatusOK, http.StatusTooManyRequests, http.StatusServiceUnavailable}
+	wantWaits := []time.Duration{0, 7 * time.Second, 0}
+	if !reflect.DeepEqual(limiter.statuses, wantStatuses) || !reflect.DeepEqual(limiter.waits, wantWaits) {
+		t.Errorf("Feedback() got statuses %v waits %v, want %v %v", limiter.statuses, limiter.waits, wantStatuses, wantWaits)
+	}
+	host := strings.TrimPrefix(server.URL, "http://")
+	if len(hooked) != 3 || hooked[1] != (RateLimitFeedback{Host: host, StatusCode: http.StatusTooManyRequests, RetryAfter: 7 * time.Second}) {
+		t.Errorf("RateLimitFeedbackHook received %+v", hooked)
+	}
+
+	// An adaptive limiter installed as RateLimiter slows down after a 429
+	adaptive := ratelimitutil.NewAdaptiveLimiter(&ratelimitutil.AdaptiveConfig{InitialRate: 100, Burst: 10})
+	util = NewHTTPUtil(nil, &HTTPConfig{RateLimiter: adaptive})
+	resp, _ := util.Get(context.Background(), server.URL+"/unavailable", nil, WithMaxRetries(0))
+	util.CloseResponse(resp)
+	if rate := adaptive.Rate(); rate != 50 {
+		t.Errorf("Rate() after 503 = %v, want 50", rate)
+	}
+}
+
+func TestHTTPUtil_GzipRequestBody(t *testing.T) {
+	type received struct {
+		encoding string
+		length   int64
+		body     string
+	}
+	var mu sync.Mutex
+	var requests []received
+	failFirst := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reader io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("server could not read gzip body: %v", err)
+				return
+			}
+			reader = gz
+		}
+		body, _ := io.ReadAll(reader)
+		mu.Lock()
+		requests = append(requests, received{r.Header.Get("Content-Encoding"), r.ContentLength, string(body)})
+		fail := failFirst && r.URL.Path == "/flaky"
+		failFirst = failFirst && !fail
+		mu.Unlock()
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	large := strings.Repeat(`{"item":"value"},`, 200)
+	util := NewHTTPUtil(nil, &HTTPConfig{GzipRequestsAbove: 1024, MaxRetries: 2, InitialWait: time.Millisecond, MaxWait: time.Millisecond})
+
+	send := func(path, body string, headers map[string]string, opts ...RequestOption) {
+		t.Helper()
+		resp, err := util.Post(context.Background(), server.URL+path, strings.NewReader(body), headers, opts...)
+		if err != nil {
+			t.Fatalf("Post(%s) error = %v", path, err)
+		}
+		util.CloseResponse(resp)
+	}
+
+	// A retried request replays the same compressed body
+	send("/flaky", large, nil)
+	if len(requests) != 2 {
+		t.Fatalf("server received %d requests, want 2", len(requests))
+	}
+	for i, req := range requests {
+		if req.encoding != "gzip" || req.body != large || req.length <= 0 || req.length >= int64(len(large)) {
+			t.Errorf("attempt %d received encoding %q, length %d, body intact %v; want a smaller gzip body", i, req.encoding, req.length, req.body == large)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		headers  map[string]string
+		opts     []RequestOption
+		encoding string
+	}{
+		{"below threshold", `{"small":true}`, nil, nil, ""},
+		{"per call threshold", strings.Repeat("ab", 50), nil, []RequestOption{WithGzipBody(10)}, "gzip"},
+		{"disabled per call", large, nil, []RequestOption{WithGzipBody(-1)}, ""},
+		{"caller encoding kept", large, map[string]string{"content-encoding": "identity"}, nil, "identity"},
+		{"incompressible", string(incompressibleBytes(2048)), nil, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			requests = nil
+			mu.Unlock()
+			send("/", tt.body, tt.headers, tt.opts...)
+			if len(requests) != 1 || requests[0].encoding != tt.encoding || requests[0].body != tt.body {
+				t.Errorf("server received %+v, want Content-Encoding %q with the original body", requests, tt.encoding)
+			}
+		})
+	}
+}
+
+// incompressibleBytes returns n pseudo-random bytes that gzip cannot shrink
+func incompressibleBytes(n int) []byte {
+	data := make([]byte, n)
+	state := uint32(1)
+	for i := range data {
+		state = state*1664525 + 1013904223
+		data[i] = byte(state >> 24)
+	}
+	return data
+}
+
+func TestHTTPUtil_EmptyMethod(t *testing.T) {
+	logger := logrus.New()
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
+	opts := RequestOptions{
+		Method: "",
+		URL:    "http://example.com",
+	}
+	_, err := util.doRequest(opts)
+	if err == nil || !strings.Contains(err.Error(), "method cannot be empty") {
+		t.Errorf("Expected method validation error, got: %v", err)
+	}
+}
+
+func TestHTTPUtil_EmptyURL(t *testing.T) {
+	logger := logrus.New()
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
+	opts := RequestOptions{
+		Method: "GET",
+		URL:    "",
+	}
+	_, err := util.doRequest(opts)
+	if err == nil || !strings.Contains(err.Error(), "URL cannot be empty") {
+		t.Errorf("Expected URL validation error, got: %v", err)
+	}
+}
+
+func TestHTTPUtil_RetryLogic(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel) // Reduce log noise in tests
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
+	util.MaxRetries = 2
+	util.InitialWait = 10 * time.Millisecond
+	util.MaxWait = 50 * time.Millisecond
+
+	retryCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		retryCount++
+		if retryCount < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	_, err := util.Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Errorf("Expected success after retries, got error: %v", err)
+	}
+	if retryCount != 3 {
+		t.Errorf("Expected 3 attempts (1 + 2 retries), got %d", retryCount)
+	}
+}
+
+func TestHTTPUtil_RetryExhausted(t *testing.T) {
+	logger := logrus.New()
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
+	util.MaxRetries = 1
+	util.InitialWait = 1 * time.Millisecond
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := util.Get(context.Background(), server.URL, nil)
+	if err == nil {
+		t.Error("Expected error after retry exhaustion")
+	}
+
+	var retryErr *RetryExhaustedError
+	if !errors.As(err, &retryErr) {
+		t.Errorf("Expected RetryExhaustedError, got %T", err)
+	}
+}
+
+func TestHTTPUtil_ContextCancellation(t *testing.T) {
+	logger := logrus.New()
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel() // Cancel immediately
+
+	_, err := util.Get(ctx, "http://example.com", nil)
+	if err == nil {
+		t.Error("Expected context cancellation error")
+	}
+}
+
+func TestHTTPUtil_RateLimitWithRetryAfter(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel) // Reduce log noise
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
+	util.MaxRetries = 1
+	util.InitialWait = 1 * time.Millisecond
+
+	callCount := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		callCount++
+		if callCount == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	_, err := util.Get(context.Background(), server.URL, nil)
+	duration := time.Since(start)
+
+	if err != nil {
+		t.Errorf("Expected success after rate limit retry, got: %v", err)
+	}
+	if duration < 800*time.Millisecond {
+		t.Errorf("Expected to wait for Retry-After header, but completed too quickly: %v", duration)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"seconds", "120", 2 * time.Minute, true},
+		{"zero seconds", "0", 0, true},
+		{"padded seconds", " 5 ", 5 * time.Second, true},
+		{"IMF-fixdate", "Fri, 01 Mar 2024 12:00:30 GMT", 30 * time.Second, true},
+		{"RFC 850 date", "Friday, 01-Mar-24 12:01:00 GMT", time.Minute, true},
+		{"asctime date", "Fri Mar  1 12:00:10 2024", 10 * time.Second, true},
+		{"date in the past", "Fri, 01 Mar 2024 11:00:00 GMT", 0, true},
+		{"negative seconds", "-5", 0, false},
+		{"garbage", "soon", 0, false},
+		{"fractional seconds", "1.5", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value, now)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("parseRetryAfter(%q) = (%v, %v), want (%v, %v)", tt.value, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestHTTPUtil_RetryAfterHTTPDate(t *testing.T) {
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts == 1 {
+			w.Header().Set("Retry-After", time.Now().Add(2*time.Second).UTC().Format(http.TimeFormat))
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond}).(*HTTPUtil)
+	start := time.Now()
+	resp, err := util.Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	util.CloseResponse(resp)
+	// http.TimeFormat has second precision, so the wait is between 1 and 2 seconds
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond || elapsed > 5*time.Second {
+		t.Errorf("retry after an HTTP-date Retry-After took %v, want roughly 1-2s", elapsed)
+	}
+}
+
+func TestHTTPUtil_ClientSideRateLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// 20 requests per second with no burst beyond the first request
+	util := NewHTTPUtil(nil, &HTTPConfig{RateLimiter: ratelimitutil.NewTokenBucket(20, 1)})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := util.Get(context.Background(), server.URL, nil)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		util.CloseResponse(resp)
+	}
+	if duration := time.Since(start); duration < 90*time.Millisecond {
+		t.Errorf("Expected limiter to space out requests, completed in %v", duration)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := util.Get(ctx, server.URL, nil); err == nil {
+		t.Error("Expected error when the context ends while waiting for the limiter")
+	}
+}
+
+func TestHTTPUtil_PropagatesRequestID(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get(contextutil.RequestIDHeader))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, nil)
+	ctx := contextutil.WithRequestID(context.Background(), "req-123")
+
+	resp, err := util.Get(ctx, server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	util.CloseResponse(resp)
+
+	// An explicit header wins over the context value
+	resp, err = util.Get(ctx, server.URL, map[string]string{contextutil.RequestIDHeader: "explicit"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	util.CloseResponse(resp)
+
+	resp, err = util.Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	util.CloseResponse(resp)
+
+	expected := []string{"req-123", "explicit", ""}
+	for i := range expected {
+		if received[i] != expected[i] {
+			t.Errorf("request %d %s = %q, want %q", i, contextutil.RequestIDHeader, received[i], expected[i])
+		}
+	}
+}
+
+func TestHTTPUtil_QueryParams(t *testing.T) {
+	var rawQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, nil).(*HTTPUtil)
+
+	tests := []struct {
+		name string
+		url  string
+		opts []RequestOption
+		want string
+	}{
+		{
+			name: "no options keeps URL untouched",
+			url:  server.URL + "/items?b=2&a=1",
+			want: "b=2&a=1",
+		},
+		{
+			name: "values are escaped",
+			url:  server.URL + "/items",
+			opts: []RequestOption{WithQueryParam("q", "a b&c=d"), WithQueryParam("tag", "x/y")},
+			want: "q=a+b%26c%3Dd&tag=x%2Fy",
+		},
+		{
+			name: "merged with existing query",
+			url:  server.URL + "/items?page=1&sort=name",
+			opts: []RequestOption{WithQuery(url.Values{"page": {"2"}, "id": {"1", "2"}})},
+			want: "id=1&id=2&page=2&sort=name",
+		},
+		{
+			name: "nil option ignored",
+			url:  server.URL + "/items",
+			opts: []RequestOption{nil, WithQueryParam("a", "1")},
+			want: "a=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := util.Get(context.Background(), tt.url, nil, tt.opts...)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			util.CloseResponse(resp)
+			if rawQuery != tt.want {
+				t.Errorf("query = %q, want %q", rawQuery, tt.want)
+			}
+		})
+	}
+
+	resp, err := util.doRequest(RequestOptions{
+		Method: http.MethodDelete,
+		URL:    server.URL + "/items",
+		Query:  url.Values{"force": {"true"}},
+	})
+	if err != nil {
+		t.Fatalf("doRequest() error = %v", err)
+	}
+	util.CloseResponse(resp)
+	if rawQuery != "force=true" {
+		t.Errorf("query = %q, want %q", rawQuery, "force=true")
+	}
+
+	if _, err := util.Get(context.Background(), "http://[::1", nil, WithQueryParam("a", "1")); err == nil {
+		t.Error("Get() with an unparsable URL and query should return error")
+	}
+}
+
+func TestHTTPUtil_PerRequestRetryOverrides(t *testing.T) {
+	var attempts int
+	var status int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), nil).(*HTTPUtil)
+	util.MaxRetries = 2
+	util.InitialWait = time.Millisecond
+	util.MaxWait = 5 * time.Millisecond
+
+	tests := []struct {
+		name         string
+		status       int
+		opts         []RequestOption
+		wantAttempts int
+		wantErr      bool
+	}{
+		{"client defaults", http.StatusInternalServerError, nil, 3, true},
+		{"more retries", http.StatusInternalServerError, []RequestOption{WithMaxRetries(4)}, 5, true},
+		{"retries disabled", http.StatusInternalServerError, []RequestOption{WithMaxRetries(0)}, 1, true},
+		{"status not retried for this call", http.StatusInternalServerError, []RequestOption{WithRetryOnStatus(http.StatusConflict)}, 1, false},
+		{"extra status retried for this call", http.StatusConflict, []RequestOption{WithRetryOnStatus(http.StatusConflict), WithMaxRetries(1)}, 2, true},
+		{"no codes retries transport errors only", http.StatusServiceUnavailable, []RequestOption{WithRetryOnStatus()}, 1, false},
+		{"backoff override", http.StatusInternalServerError, []RequestOption{WithMaxRetries(1), WithBackoff(2*time.Millisecond, 0)}, 2, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts, status = 0, tt.status
+			resp, err := util.Get(context.Background(), server.URL, nil, tt.opts...)
+			util.CloseResponse(resp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+
+	if util.MaxRetries != 2 || len(util.RetryOnStatus) == 0 {
+		t.Error("per-request overrides must not change the client configuration")
+	}
+}
+
+func TestRetryPolicyFor(t *testing.T) {
+	util := &HTTPUtil{MaxRetries: 3, InitialWait: time.Second, MaxWait: time.Minute, RetryOnStatus: []int{500}}
+
+	got := util.retryPolicyFor(RequestOptions{})
+	if got.maxRetries != 3 || got.initialWait != time.Second || got.maxWait != time.Minute || !got.retriesStatus(500) {
+		t.Errorf("retryPolicyFor() with no overrides = %+v, want client settings", got)
+	}
+
+	got = util.retryPolicyFor(applyOptions(RequestOptions{}, []RequestOption{
+		WithMaxRetries(0), WithBackoff(10*time.Millisecond, 0), WithRetryOnStatus(409),
+	}))
+	if got.maxRetries != 0 || got.initialWait != 10*time.Millisecond || got.maxWait != time.Minute {
+		t.Errorf("retryPolicyFor() = %+v, want overrides with client MaxWait", got)
+	}
+	if got.retriesStatus(500) || !got.retriesStatus(409) {
+		t.Errorf("retryPolicyFor() retryOn = %v, want [409]", got.retryOn)
+	}
+}
+
+func TestHTTPUtil_Middleware(t *testing.T) {
+	var attempts int
+	var gotAuth []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		gotAuth = append(gotAuth, r.Header.Get("Authorization"))
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 2, InitialWait: time.Millisecond}).(*HTTPUtil)
+
+	var order []string
+	trace := func(name string) Middleware {
+		return func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name+">")
+				resp, err := next(req)
+				order = append(order, "<"+name)
+				return resp, err
+			}
+		}
+	}
+	auth := func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("Authorization", "Bearer token")
+			return next(req)
+		}
+	}
+	util.Use(trace("outer"), nil, trace("inner"))
+	util.Use(auth)
+
+	resp, err := util.Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	util.CloseResponse(resp)
+
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want 2", attempts)
+	}
+	wantOrder := []string{"outer>", "inner>", "<inner", "<outer", "outer>", "inner>", "<inner", "<outer"}
+	if !reflect.DeepEqual(order, wantOrder) {
+		t.Errorf("middleware order = %v, want %v", order, wantOrder)
+	}
+	if !reflect.DeepEqual(gotAuth, []string{"Bearer token", "Bearer token"}) {
+		t.Errorf("Authorization headers = %v, want the header on every attempt", gotAuth)
+	}
+
+	t.Run("short circuit", func(t *testing.T) {
+		util := NewHTTPUtil(logutil.NewNopLogger(), nil).(*HTTPUtil)
+		util.Use(func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusTeapot, Body: io.NopCloser(strings.NewReader("cached")), Request: req}, nil
+			}
+		})
+		before := attempts
+		resp, err := util.Get(context.Background(), server.URL, nil)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		defer util.CloseResponse(resp)
+		if resp.StatusCode != http.StatusTeapot || attempts != before {
+			t.Errorf("status = %d, server hits = %d, want %d and no hits", resp.StatusCode, attempts-before, http.StatusTeapot)
+		}
+	})
+
+	t.Run("nil response", func(t *testing.T) {
+		util := NewHTTPUtil(logutil.NewNopLogger(), nil).(*HTTPUtil)
+		util.Use(func(next RoundTripFunc) RoundTripFunc {
+			return func(req *http.Request) (*http.Response, error) { return nil, nil }
+		})
+		if _, err := util.Get(context.Background(), server.URL, nil); err == nil {
+			t.Error("Get() should fail when a middleware returns no response")
+		}
+	})
+}
+
+func TestHTTPUtil_GetJSONPostJSON(t *testing.T) {
+	type payload struct {
+		Name  string `json:"name"`
+		Count int    `json:"count"`
+	}
+
+	var gotContentType, gotAccept string
+	var gotBody payload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType, gotAccept = r.Header.Get("Content-Type"), r.Header.Get("Accept")
+		switch r.URL.Path {
+		case "/echo":
+			_ = json.NewDecoder(r.Body).Decode(&gotBody)
+			gotBody.Count++
+			_ = json.NewEncoder(w).Encode(gotBody)
+		case "/bad-json":
+			_, _ = w.Write([]byte("{not json"))
+		case "/empty":
+			w.WriteHeader(http.StatusOK)
+		case "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			_ = json.NewEncoder(w).Encode(payload{Name: "item", Count: 1})
+		}
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), nil).(*HTTPUtil)
+	ctx := context.Background()
+
+	var out payload
+	if err := util.GetJSON(ctx, server.URL+"/item", nil, &out); err != nil || out != (payload{Name: "item", Count: 1}) {
+		t.Errorf("GetJSON() = %+v, %v", out, err)
+	}
+	if gotAccept != "application/json" || gotContentType != "" {
+		t.Errorf("GetJSON() headers Accept = %q, Content-Type = %q", gotAccept, gotContentType)
+	}
+
+	out = payload{}
+	if err := util.PostJSON(ctx, server.URL+"/echo", payload{Name: "x", Count: 1}, map[string]string{"X-Trace": "1"}, &out); err != nil {
+		t.Fatalf("PostJSON() error = %v", err)
+	}
+	if gotContentType != "application/json" || gotBody.Name != "x" || out.Count != 2 {
+		t.Errorf("PostJSON() Content-Type = %q, server got %+v, out %+v", gotContentType, gotBody, out)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		out     any
+		wantErr bool
+	}{
+		{"nil out discards body", "/item", nil, false},
+		{"empty body", "/empty", &payload{}, false},
+		{"invalid JSON", "/bad-json", &payload{}, true},
+		{"non-2xx", "/forbidden", &payload{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := util.GetJSON(ctx, server.URL+tt.path, nil, tt.out)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	var statusErr *StatusError
+	if err := util.PostJSON(ctx, server.URL+"/forbidden", payload{}, nil, nil); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusForbidden {
+		t.Errorf("PostJSON() error = %v, want *StatusError 403", err)
+	}
+}
+
+func TestDo(t *testing.T) {
+	type item struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	var gotMethod, gotQuery, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotMethod, gotQuery, gotBody = r.Method, r.URL.RawQuery, string(body)
+		switch r.URL.Path {
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		case "/none":
+			w.WriteHeader(http.StatusNoContent)
+		case "/list":
+			_, _ = w.Write([]byte(`[{"id":1},{"id":2}]`))
+		default:
+			_, _ = w.Write([]byte(`{"id":7,"name":"widget"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond})
+	ctx := context.Background()
+
+	got, resp, err := Do[item](client, RequestOptions{Method: "patch", URL: server.URL + "/item", Body: strings.NewReader(`{"name":"w"}`), Context: ctx, Query: url.Values{"v": {"2"}}})
+	if err != nil || got != (item{ID: 7, Name: "widget"}) || resp.StatusCode != http.StatusOK {
+		t.Fatalf("Do[item]() = %+v, %v, %v", got, resp, err)
+	}
+	if gotMethod != http.MethodPatch || gotQuery != "v=2" || gotBody != `{"name":"w"}` {
+		t.Errorf("server saw %s ?%s %q", gotMethod, gotQuery, gotBody)
+	}
+
+	list, _, err := Do[[]item](client, RequestOptions{Method: http.MethodGet, URL: server.URL + "/list"})
+	if err != nil || len(list) != 2 {
+		t.Errorf("Do[[]item]() = %+v, %v", list, err)
+	}
+
+	none, resp, err := Do[*item](client, RequestOptions{Method: http.MethodDelete, URL: server.URL + "/none"})
+	if err != nil || none != nil || resp.StatusCode != http.StatusNoContent {
+		t.Errorf("Do[*item]() on 204 = %v, %v", none, err)
+	}
+
+	var statusErr *StatusError
+	if _, resp, err := Do[item](client, RequestOptions{Method: http.MethodGet, URL: server.URL + "/missing"}); !errors.As(err, &statusErr) || resp == nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Do[item]() on 404 error = %v, want *StatusError with the response", err)
+	}
+
+	if _, _, err := Do[item](client, RequestOptions{Method: "", URL: server.URL}); err == nil {
+		t.Error("Do[item]() with an empty method should fail")
+	}
+
+	report, _, err := Do[item](client, RequestOptions{Method: "report", URL: server.URL + "/item"})
+	if err != nil || report.ID != 7 || gotMethod != "REPORT" {
+		t.Errorf("Do[item]() with REPORT = %+v, %v; server saw %s", report, err, gotMethod)
+	}
+}
+
+func TestHTTPUtil_Resource(t *testing.T) {
+	type user struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	var mu sync.Mutex
+	users := map[string]user{"1": {ID: "1", Name: "Ada"}}
+	var lastAuth, lastQuery, lastContentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		lastAuth, lastQuery, lastContentType = r.Header.Get("Authorization"), r.URL.RawQuery, r.Header.Get("Content-Type")
+		id := strings.TrimPrefix(r.URL.EscapedPath(), "/users/")
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/users":
+			list := []user{}
+			for _, u := range users {
+				list = append(list, u)
+			}
+			_ = json.NewEncoder(w).Encode(list)
+		case r.Method == http.MethodPost && r.URL.Path == "/users":
+			var u user
+			_ = json.NewDecoder(r.Body).Decode(&u)
+			u.ID = "2"
+			users[u.ID] = u
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(u)
+		case r.Method == http.MethodGet:
+			u, ok := users[id]
+			if !ok {
+				http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(u)
+		case r.Method == http.MethodPut:
+			var u user
+			_ = json.NewDecoder(r.Body).Decode(&u)
+			u.ID = id
+			users[id] = u
+			_ = json.NewEncoder(w).Encode(u)
+		case r.Method == http.MethodDelete:
+			delete(users, id)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond}).(*HTTPUtil)
+	res := util.Resource(server.URL + "/users/")
+	res.Headers = map[string]string{"Authorization": "Bearer token"}
+	ctx := context.Background()
+
+	var got user
+	if err := res.Get(ctx, "1", &got); err != nil || got.Name != "Ada" {
+		t.Fatalf("Get() = %+v, %v, want Ada", got, err)
+	}
+	if lastAuth != "Bearer token" {
+		t.Errorf("Authorization = %q, want %q", lastAuth, "Bearer token")
+	}
+
+	var created user
+	if err := res.Create(ctx, user{Name: "Grace"}, &created); err != nil || created.ID != "2" {
+		t.Fatalf("Create() = %+v, %v, want ID 2", created, err)
+	}
+	if lastContentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", lastContentType)
+	}
+
+	var list []user
+	if err := res.List(ctx, url.Values{"sort": {"name"}}, &list); err != nil || len(list) != 2 {
+		t.Fatalf("List() = %+v, %v, want 2 users", list, err)
+	}
+	if lastQuery != "sort=name" {
+		t.Errorf("List() query = %q, want %q", lastQuery, "sort=name")
+	}
+
+	var updated user
+	if err := res.Update(ctx, "2", user{Name: "Grace Hopper"}, &updated); err != nil || updated.Name != "Grace Hopper" {
+		t.Fatalf("Update() = %+v, %v", updated, err)
+	}
+	if err := res.Delete(ctx, "2"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+
+	var statusErr *StatusError
+	if err := res.Get(ctx, "2", &got); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("Get() of deleted item error = %v, want *StatusError 404", err)
+	}
+
+	if got := res.URL("a/b c"); got != server.URL+"/users/a%2Fb%20c" {
+		t.Errorf("URL() = %q, want the id path-escaped", got)
+	}
+	if err := res.Create(ctx, func() {}, nil); err == nil {
+		t.Error("Create() with an unencodable body should fail")
+	}
+}
+
+func TestHTTPUtil_Endpoints(t *testing.T) {
+	type user struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	var mu sync.Mutex
+	var lastPath, lastQuery, lastAuth, lastVersion string
+	var attempts int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		lastPath, lastQuery = r.URL.EscapedPath(), r.URL.RawQuery
+		lastAuth, lastVersion = r.Header.Get("Authorization"), r.Header.Get("X-Api-Version")
+		switch {
+		case r.URL.Path == "/flaky":
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case r.Method == http.MethodPost:
+			var u user
+			_ = json.NewDecoder(r.Body).Decode(&u)
+			u.ID = "7"
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(u)
+		default:
+			_ = json.NewEncoder(w).Encode(user{ID: strings.TrimPrefix(r.URL.Path, "/users/"), Name: "Ada"})
+		}
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 3, InitialWait: time.Millisecond}).(*HTTPUtil)
+	var successEndpoint string
+	util.SetSuccessHook(func(resp *http.Response, options RequestOptions) {
+		successEndpoint = EndpointName(options.Context)
+	})
+
+	noRetries := 0
+	api := util.Endpoints(server.URL + "/")
+	api.Headers = map[string]string{"Authorization": "Bearer token", "X-Api-Version": "1"}
+	err := api.Register(
+		Endpoint{Name: "getUser", Path: "/users/{id}", Headers: map[string]string{"X-Api-Version": "2"}},
+		Endpoint{Name: "createUser", Method: "post", Path: "users", ExpectedStatus: []int{http.StatusCreated}},
+		Endpoint{Name: "flaky", Path: "/flaky", MaxRetries: &noRetries},
+		Endpoint{Name: "slow", Path: "/slow", Timeout: 50 * time.Millisecond, MaxRetries: &noRetries},
+		Endpoint{Name: "strict", Path: "/users/{id}", ExpectedStatus: []int{http.StatusAccepted}},
+	)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("path and query params", func(t *testing.T) {
+		var got user
+		if err := api.Call(ctx, "getUser", map[string]string{"id": "a/b", "fields": "name"}, &got); err != nil {
+			t.Fatalf("Call() error = %v", err)
+		}
+		if lastPath != "/users/a%2Fb" || lastQuery != "fields=name" {
+			t.Errorf("Call() requested %q?%q, want /users/a%%2Fb?fields=name", lastPath, lastQuery)
+		}
+		if got.Name != "Ada" {
+			t.Errorf("Call() decoded %+v, want Ada", got)
+		}
+		if lastAuth != "Bearer token" || lastVersion != "2" {
+			t.Errorf("headers = (%q, %q), want registry headers overridden by the endpoint", lastAuth, lastVersion)
+		}
+		if successEndpoint != "getUser" {
+			t.Errorf("EndpointName() in success hook = %q, want getUser", successEndpoint)
+		}
+	})
+
+	t.Run("body and expected status", func(t *testing.T) {
+		var created user
+		if err := api.CallWithBody(ctx, "createUser", nil, user{Name: "Grace"}, &created); err != nil || created.ID != "7" {
+			t.Fatalf("CallWithBody() = %+v, %v, want ID 7", created, err)
+		}
+		var statusErr *StatusError
+		if err := api.Call(ctx, "strict", map[string]string{"id": "1"}, nil); !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusOK {
+			t.Errorf("Call() with an unexpected 200 error = %v, want *StatusError 200", err)
+		}
+	})
+
+	t.Run("policies", func(t *testing.T) {
+		if err := api.Call(ctx, "flaky", nil, nil); err == nil {
+			t.Error("Call() of a failing endpoint should fail")
+		}
+		if attempts != 1 {
+			t.Errorf("flaky endpoint attempts = %d, want 1", attempts)
+		}
+		if err := api.Call(ctx, "slow", nil, nil); err == nil || !strings.Contains(err.Error(), "deadline exceeded") {
+			t.Errorf("Call() past the endpoint timeout error = %v, want a deadline error", err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if err := api.Call(ctx, "missing", nil, nil); !errors.Is(err, ErrUnknownEndpoint) {
+			t.Errorf("Call() of an unregistered endpoint error = %v, want ErrUnknownEndpoint", err)
+		}
+		if err := api.Call(ctx, "getUser", nil, nil); err == nil {
+			t.Error("Call() without a path parameter should fail")
+		}
+		if err := api.Register(Endpoint{Name: "getUser", Path: "/x"}); err == nil {
+			t.Error("Register() of a duplicate name should fail")
+		}
+		for _, path := range []string{"/users/{id", "/users/id}", "/users/{}"} {
+			if err := api.Register(Endpoint{Name: "bad", Path: path}); err == nil {
+				t.Errorf("Register() with template %q should fail", path)
+			}
+		}
+		if _, ok := api.Lookup("bad"); ok {
+			t.Error("a failed Register() should not add the endpoint")
+		}
+	})
+
+	if got := api.Names(); !reflect.DeepEqual(got, []string{"createUser", "flaky", "getUser", "slow", "strict"}) {
+		t.Errorf("Names() = %v", got)
+	}
+	if got, err := api.URL("getUser", map[string]string{"id": "42", "q": "x"}); err != nil || got != server.URL+"/users/42?q=x" {
+		t.Errorf("URL() = %q, %v", got, err)
+	}
+}
+
+func TestHTTPUtil_PostMultipart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.csv")
+	if err := os.WriteFile(path, []byte("a,b\n1,2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var attempts int
+	var failFirst bool
+	type received struct {
+		fields map[string]string
+		files  map[string]string // field -> filename:content-type:content
+	}
+	var got received
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if failFirst && attempts == 1 {
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		got = received{fields: map[string]string{}, files: map[string]string{}}
+		for k, v := range r.MultipartForm.Value {
+			got.fields[k] = v[0]
+		}
+		for k, fhs := range r.MultipartForm.File {
+			f, _ := fhs[0].Open()
+			content, _ := io.ReadAll(f)
+			f.Close()
+			got.files[k] = fhs[0].Filename + ":" + fhs[0].Header.Get("Content-Type") + ":" + string(content)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond}).(*HTTPUtil)
+	ctx := context.Background()
+
+	t.Run("fields and files", func(t *testing.T) {
+		attempts, failFirst = 0, true
+		logo := FileFromReader("logo", `lo"go.png`, strings.NewReader("PNG"))
+		logo.ContentType = "image/png"
+		resp, err := util.PostMultipart(ctx, server.URL, map[string]string{"title": "Q1", "owner": "ops"},
+			[]FileField{FileFromPath("report", path)}, map[string]string{"X-Trace": "1"})
+		if err != nil {
+			t.Fatalf("PostMultipart() error = %v", err)
+		}
+		util.CloseResponse(resp)
+		if attempts != 2 {
+			t.Errorf("attempts = %d, want 2 (file resent on retry)", attempts)
+		}
+		want := received{
+			fields: map[string]string{"title": "Q1", "owner": "ops"},
+			files:  map[string]string{"report": "report.csv:application/octet-stream:a,b\n1,2\n"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("received = %+v, want %+v", got, want)
+		}
+
+		attempts, failFirst = 0, false
+		resp, err = util.PostMultipart(ctx, server.URL, nil, []FileField{logo}, nil)
+		if err != nil {
+			t.Fatalf("PostMultipart() error = %v", err)
+		}
+		util.CloseResponse(resp)
+		if got.files["logo"] != `lo"go.png:image/png:PNG` {
+			t.Errorf("logo part = %q", got.files["logo"])
+		}
+	})
+
+	t.Run("reader cannot be replayed", func(t *testing.T) {
+		attempts, failFirst = 0, true
+		resp, err := util.PostMultipart(ctx, server.URL, nil, []FileField{FileFromReader("f", "f.txt", strings.NewReader("x"))}, nil)
+		util.CloseResponse(resp)
+		if err == nil {
+			t.Error("PostMultipart() should fail when a consumed reader must be resent")
+		}
+	})
+
+	t.Run("invalid file field", func(t *testing.T) {
+		if _, err := util.PostMultipart(ctx, server.URL, nil, []FileField{{FieldName: "f"}}, nil); err == nil {
+			t.Error("PostMultipart() without Open should fail")
+		}
+	})
+}
+
+func TestTeeBody(t *testing.T) {
+	resp := &http.Response{Body: io.NopCloser(strings.NewReader("hello world"))}
+	var a, b bytes.Buffer
+	TeeBody(resp, &a, &b)
+
+	util := &HTTPUtil{}
+	body, err := util.ReadBody(resp)
+	if err != nil || string(body) != "hello world" {
+		t.Fatalf("ReadBody() = %q, %v", body, err)
+	}
+	if a.String() != "hello world" || b.String() != "hello world" {
+		t.Errorf("sinks = %q, %q, want the full body in both", a.String(), b.String())
+	}
+
+	TeeBody(nil, &a)
+	TeeBody(&http.Response{}, &a)
+}
+
+func TestCaptureBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxBytes int64
+		want     string
+	}{
+		{"whole body", 0, "0123456789"},
+		{"limited", 4, "0123"},
+		{"limit beyond body", 100, "0123456789"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			closed := false
+			resp := &http.Response{Body: &readCloser{Reader: strings.NewReader("0123456789"), Closer: closerFunc(func() error { closed = true; return nil })}}
+			captured, err := CaptureBody(resp, tt.maxBytes)
+			if err != nil || string(captured) != tt.want {
+				t.Fatalf("CaptureBody() = %q, %v, want %q", captured, err, tt.want)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != "0123456789" {
+				t.Errorf("remaining body = %q, want the full body", body)
+			}
+			resp.Body.Close()
+			if !closed {
+				t.Error("closing the wrapped body should close the original")
+			}
+		})
+	}
+
+	if captured, err := CaptureBody(nil, 0); captured != nil || err != nil {
+		t.Errorf("CaptureBody(nil) = %q, %v", captured, err)
+	}
+}
+
+// closerFunc adapts a function to io.Closer
+type closerFunc func() error
+
+func (f closerFunc) Close() error { return f() }
+
+func TestHTTPUtil_BodyCapture(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"ok","items":[1,2,3]}`))
+	}))
+	defer server.Close()
+
+	var observed string
+	util := NewHTTPUtil(logutil.NewNopLogger(), nil).(*HTTPUtil)
+	util.Use(BodyCapture(11, func(resp *http.Response, body []byte) {
+		observed = fmt.Sprintf("%d %s", resp.StatusCode, body)
+	}))
+
+	resp, err := util.Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	var out map[string]any
+	if err := util.DecodeJSON(resp, &out); err != nil || out["status"] != "ok" {
+		t.Fatalf("DecodeJSON() = %v, %v, want the full body", out, err)
+	}
+	if observed != `200 {"status":"` {
+		t.Errorf("observed = %q", observed)
+	}
+}
+
+func TestReadBody_Decompression(t *testing.T) {
+	const plain = `{"message":"hello"}`
+	compress := func(w io.WriteCloser, buf *bytes.Buffer) []byte {
+		_, _ = w.Write([]byte(plain))
+		_ = w.Close()
+		return buf.Bytes()
+	}
+	var gzBuf, zlBuf, flBuf bytes.Buffer
+	gzipped := compress(gzip.NewWriter(&gzBuf), &gzBuf)
+	zlibbed := compress(zlib.NewWriter(&zlBuf), &zlBuf)
+	fw, _ := flate.NewWriter(&flBuf, flate.DefaultCompression)
+	deflated := compress(fw, &flBuf)
+
+	tests := []struct {
+		name         string
+		encoding     string
+		body         []byte
+		uncompressed bool
+		noSniff      bool
+		want         string
+		wantErr      bool
+	}{
+		{"plain", "", []byte(plain), false, false, plain, false},
+		{"gzip labelled", "gzip", gzipped, false, false, plain, false},
+		{"x-gzip labelled", "X-Gzip", gzipped, false, false, plain, false},
+		{"gzip unlabelled", "", gzipped, false, false, plain, false},
+		{"gzip unlabelled without sniffing", "", gzipped, false, true, string(gzipped), false},
+		{"gzip mislabelled plain", "gzip", []byte(plain), false, false, plain, false},
+		{"gzip mislabelled plain without sniffing", "gzip", []byte(plain), false, true, "", true},
+		{"deflate zlib", "deflate", zlibbed, false, false, plain, false},
+		{"deflate raw", "deflate", deflated, false, false, plain, false},
+		{"already decompressed by transport", "", gzipped, true, false, string(gzipped), false},
+		{"unsupported encoding passed through", "br", []byte("raw"), false, false, "raw", false},
+		{"empty gzip body", "gzip", nil, false, true, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			util := &HTTPUtil{DisableBodySniffing: tt.noSniff}
+			resp := &http.Response{
+				Header:       http.Header{},
+				Body:         io.NopCloser(bytes.NewReader(tt.body)),
+				Uncompressed: tt.uncompressed,
+			}
+			if tt.encoding != "" {
+				resp.Header.Set("Content-Encoding", tt.encoding)
+			}
+			got, err := util.ReadBody(resp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ReadBody() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(got) != tt.want {
+				t.Errorf("ReadBody() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPUtil_DecodeJSON_DisableCompression(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte(`{"ok":true}`))
+		_ = gz.Close()
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{DisableCompression: true}).(*HTTPUtil)
+	resp, err := util.Get(context.Background(), server.URL, map[string]string{"Accept-Encoding": "gzip"})
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	var out map[string]bool
+	if err := util.DecodeJSON(resp, &out); err != nil || !out["ok"] {
+		t.Errorf("DecodeJSON() = %v, %v, want decompressed JSON", out, err)
+	}
+}
+
+func TestHTTPUtil_Timings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{EnableTimings: true}).(*HTTPUtil)
+	var hookTimings Timings
+	var hookOK bool
+	util.SetSuccessHook(func(resp *http.Response, _ RequestOptions) {
+		hookTimings, hookOK = GetTimings(resp)
+	})
+
+	for i, wantReused := range []bool{false, true} {
+		resp, err := util.Get(context.Background(), server.URL, nil)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		_, _ = util.ReadBody(resp)
+
+		got, ok := GetTimings(resp)
+		if !ok || !hookOK || got != hookTimings {
+			t.Fatalf("request %d: GetTimings() = %+v, %v; hook saw %+v, %v", i, got, ok, hookTimings, hookOK)
+		}
+		if got.TTFB < 5*time.Millisecond || got.Total < got.TTFB {
+			t.Errorf("request %d: TTFB = %v, Total = %v, want TTFB >= 5ms and Total >= TTFB", i, got.TTFB, got.Total)
+		}
+		if got.ConnReused != wantReused {
+			t.Errorf("request %d: ConnReused = %v, want %v", i, got.ConnReused, wantReused)
+		}
+		if !wantReused && got.Connect == 0 {
+			t.Errorf("request %d: Connect = 0 on a new connection", i)
+		}
+	}
+
+	plain := NewHTTPUtil(logutil.NewNopLogger(), nil)
+	resp, err := plain.Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	plain.CloseResponse(resp)
+	if _, ok := GetTimings(resp); ok {
+		t.Error("GetTimings() should report false when timings are disabled")
+	}
+	if _, ok := GetTimings(nil); ok {
+		t.Error("GetTimings(nil) should report false")
+	}
+}
+
+func TestDecodeJSON_InvalidJSON(t *testing.T) {
+	testCases := []struct {
+		name string
+		json string
+	}{
+		{"missing quote", `{"invalid": json}`},
+		{"missing comma", `{"a": "b" "c": "d"}`},
+		{"trailing comma", `{"valid": "json",}`},
+		{"empty body", ``},
+	}
+
+	util := &HTTPUtil{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{Body: io.NopCloser(bytes.NewBufferString(tc.json))}
+			var result map[string]any
+			err := util.DecodeJSON(resp, &result)
+			if err == nil {
+				t.Errorf("Expected JSON decode error for %s", tc.name)
+			}
+		})
+	}
+}
+
+func TestCloseResponse_NilResponse(t *testing.T) {
+	util := &HTTPUtil{}
+	util.CloseResponse(nil)
+}
+
+func TestCloseResponse_NilBody(t *testing.T) {
+	util := &HTTPUtil{}
+	resp := &http.Response{Body: nil}
+	util.CloseResponse(resp)
+}
+
+func TestGetHeader_CaseInsensitive(t *testing.T) {
+	util := &HTTPUtil{}
+	resp := &http.Response{
+		Header: http.Header{
+			"Content-Type": []string{"application/json"},
+		},
+	}
+
+	// Go HTTP headers are case-insensitive
+	if val := util.GetHeader(resp, "content-type"); val != "application/json" {
+		t.Errorf("Expected case-insensitive header lookup to work")
+	}
+}
+
+func TestHTTPUtil_WithCustomHeaders(t *testing.T) {
+	logger := logrus.New()
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Custom") != "test-value" {
+			t.Errorf("Expected custom header, got: %s", r.Header.Get("X-Custom"))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	headers := map[string]string{
+		"X-Custom": "test-value",
+	}
+
+	_, err := util.Get(context.Background(), server.URL, headers)
+	if err != nil {
+		t.Errorf("Expected success with custom headers, got: %v", err)
+	}
+}
+
+func TestNewHTTPUtil_DefaultSettings(t *testing.T) {
+	logger := logrus.New()
+	client := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil)
+	util := client.(*HTTPUtil)
 
 	if util.MaxRetries != 5 {
 		t.Errorf("Expected MaxRetries=5, got %d", util.MaxRetries)
@@ -610,7 +3217,7 @@ func TestNewHTTPUtil_DefaultSettings(t *testing.T) {
 func TestHTTPUtil_AllMethods(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 
 	testCases := []struct {
 		method   string
@@ -651,7 +3258,7 @@ func TestHTTPUtil_AllMethods(t *testing.T) {
 func TestHTTPUtil_RequestBody(t *testing.T) {
 	logger := logrus.New()
 	logger.SetLevel(logrus.ErrorLevel)
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
 
 	expectedBody := "test request body"
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -659,33 +3266,352 @@ func TestHTTPUtil_RequestBody(t *testing.T) {
 		if string(body) != expectedBody {
 			t.Errorf("Expected body '%s', got '%s'", expectedBody, string(body))
 		}
-		w.WriteHeader(http.StatusOK)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, err := util.Post(context.Background(), server.URL, bytes.NewBufferString(expectedBody), nil)
+	if err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+}
+
+func TestHTTPUtil_TimeoutHandling(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+	util := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil).(*HTTPUtil)
+	util.Client.Timeout = 100 * time.Millisecond
+	util.MaxRetries = 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	_, err := util.Get(context.Background(), server.URL, nil)
+	if err == nil {
+		t.Error("Expected timeout error")
+	}
+}
+
+func TestHTTPUtil_DeadlineAwareRetry(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 5, InitialWait: time.Millisecond}).(*HTTPUtil)
+	var decisions []RetryDecision
+	util.SetRetryDecisionHook(func(decision RetryDecision) {
+		decisions = append(decisions, decision)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	resp, err := util.Get(ctx, server.URL, nil, WithBackoff(time.Second, time.Second))
+	elapsed := time.Since(start)
+	util.CloseResponse(resp)
+
+	var exhausted *RetryExhaustedError
+	if !errors.As(err, &exhausted) || !exhausted.StoppedByDeadline {
+		t.Fatalf("Get() error = %v, want *RetryExhaustedError stopped by the deadline", err)
+	}
+	if exhausted.Attempts != 1 || exhausted.LastStatus != http.StatusServiceUnavailable {
+		t.Errorf("RetryExhaustedError = %+v, want 1 attempt ending in 503", exhausted)
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Get() should return the last response")
+	}
+	if elapsed > 250*time.Millisecond {
+		t.Errorf("Get() took %v, want an immediate return instead of waiting for the deadline", elapsed)
+	}
+	if got := atomic.LoadInt32(&attempts); got != 1 {
+		t.Errorf("server saw %d attempts, want 1", got)
+	}
+	if len(decisions) != 1 || decisions[0].Retry || !decisions[0].HasDeadline ||
+		decisions[0].Wait < time.Second || decisions[0].Remaining <= 0 || decisions[0].Remaining > 500*time.Millisecond {
+		t.Errorf("decisions = %+v, want one abandoned retry with the remaining budget", decisions)
+	}
+
+	// Without a deadline the decision hook sees every retry
+	decisions = nil
+	resp, err = util.Get(context.Background(), server.URL, nil, WithMaxRetries(2))
+	util.CloseResponse(resp)
+	if !errors.As(err, &exhausted) || exhausted.StoppedByDeadline || exhausted.Attempts != 3 {
+		t.Errorf("Get() error = %v, want exhaustion after 3 attempts", err)
+	}
+	if len(decisions) != 2 || !decisions[0].Retry || decisions[0].HasDeadline {
+		t.Errorf("decisions = %+v, want two retries without a deadline", decisions)
+	}
+}
+
+func TestHTTPUtil_ResponseCache(t *testing.T) {
+	var failing, noStore atomic.Bool
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if noStore.Load() {
+			w.Header().Set("Cache-Control", "no-store")
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, "v1 "+r.URL.Path)
+	}))
+	defer server.Close()
+
+	newUtil := func(cache *ResponseCacheConfig) *HTTPUtil {
+		return NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond, ResponseCache: cache}).(*HTTPUtil)
+	}
+	get := func(util *HTTPUtil, ctx context.Context, path string) (*http.Response, string, error) {
+		resp, err := util.Get(ctx, server.URL+path, nil)
+		if err != nil {
+			util.CloseResponse(resp)
+			return resp, "", err
+		}
+		body, _ := util.ReadBody(resp)
+		return resp, string(body), nil
+	}
+
+	t.Run("stale if error", func(t *testing.T) {
+		failing.Store(false)
+		util := newUtil(&ResponseCacheConfig{StaleIfError: time.Minute})
+		resp, body, err := get(util, context.Background(), "/a")
+		if err != nil || body != "v1 /a" || IsCached(resp) {
+			t.Fatalf("first Get() = %q, %v, cached=%v", body, err, IsCached(resp))
+		}
+
+		failing.Store(true)
+		resp, body, err = get(util, context.Background(), "/a")
+		if err != nil {
+			t.Fatalf("Get() with a failing upstream error = %v, want the stale response", err)
+		}
+		if !IsStale(resp) || body != "v1 /a" || resp.StatusCode != http.StatusOK {
+			t.Errorf("Get() = %d %q stale=%v, want the stored 200 flagged stale", resp.StatusCode, body, IsStale(resp))
+		}
+		if resp.Header.Get("Content-Type") != "text/plain" || resp.Header.Get("Age") == "" {
+			t.Errorf("stale headers = %v, want stored headers plus Age", resp.Header)
+		}
+
+		if _, _, err := get(util, context.Background(), "/never-cached"); err == nil {
+			t.Error("Get() of an uncached URL with a failing upstream should fail")
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, _, err := get(util, ctx, "/a"); err == nil {
+			t.Error("Get() with a cancelled context should not serve stale")
+		}
+	})
+
+	t.Run("fresh within TTL", func(t *testing.T) {
+		failing.Store(false)
+		util := newUtil(&ResponseCacheConfig{TTL: time.Hour})
+		_, _, _ = get(util, context.Background(), "/b")
+		before := atomic.LoadInt32(&hits)
+		resp, body, err := get(util, context.Background(), "/b")
+		if err != nil || body != "v1 /b" || resp.Header.Get(CacheStatusHeader) != CacheStatusHit {
+			t.Errorf("Get() = %q, %v, status %q, want a cache hit", body, err, resp.Header.Get(CacheStatusHeader))
+		}
+		if atomic.LoadInt32(&hits) != before {
+			t.Error("a fresh cached response should not contact the server")
+		}
+	})
+
+	t.Run("not stored", func(t *testing.T) {
+		failing.Store(false)
+		noStore.Store(true)
+		defer noStore.Store(false)
+		util := newUtil(&ResponseCacheConfig{TTL: time.Hour, StaleIfError: time.Hour})
+		_, _, _ = get(util, context.Background(), "/c")
+		if resp, _, _ := get(util, context.Background(), "/c"); IsCached(resp) {
+			t.Error("Cache-Control: no-store responses should not be cached")
+		}
+
+		noStore.Store(false)
+		resp, _ := util.Post(context.Background(), server.URL+"/d", strings.NewReader("x"), nil)
+		util.CloseResponse(resp)
+		failing.Store(true)
+		defer failing.Store(false)
+		if _, _, err := get(util, context.Background(), "/d"); err == nil {
+			t.Error("POST responses should not be served to GET requests")
+		}
+	})
+
+	t.Run("disabled fallback", func(t *testing.T) {
+		failing.Store(false)
+		util := newUtil(nil)
+		_, _, _ = get(util, context.Background(), "/e")
+		failing.Store(true)
+		defer failing.Store(false)
+		if _, _, err := get(util, context.Background(), "/e"); err == nil {
+			t.Error("Get() without a response cache should fail")
+		}
+	})
+}
+
+func TestHTTPUtil_ResponseCacheHooks(t *testing.T) {
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond,
+		ResponseCache: &ResponseCacheConfig{TTL: 50 * time.Millisecond, StaleIfError: time.Hour}})
+	var successes, failures []RequestStats
+	var failureErr error
+	util.SetSuccessHook(func(resp *http.Response, options RequestOptions) {
+		successes = append(successes, options.Stats)
+	})
+	util.SetFailureHook(func(resp *http.Response, err error, options RequestOptions) {
+		failures = append(failures, options.Stats)
+		failureErr = err
+	})
+	get := func() *http.Response {
+		resp, err := util.Get(context.Background(), server.URL, nil)
+		if err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+		util.CloseResponse(resp)
+		return resp
+	}
+
+	get()
+	if resp := get(); resp.Header.Get(CacheStatusHeader) != CacheStatusHit {
+		t.Fatalf("second Get() cache status = %q, want HIT", resp.Header.Get(CacheStatusHeader))
+	}
+	if len(successes) != 2 || successes[1].CacheStatus != CacheStatusHit || successes[1].Attempts != 0 {
+		t.Errorf("SuccessHook stats = %+v, want a HIT with no attempts", successes)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	failing.Store(true)
+	if resp := get(); !IsStale(resp) {
+		t.Fatal("Get() with a failing upstream should serve the stale response")
+	}
+	var exhausted *RetryExhaustedError
+	if len(failures) != 1 || failures[0].CacheStatus != CacheStatusStale || failures[0].Attempts != 2 {
+		t.Errorf("FailureHook stats = %+v, want one STALE report after 2 attempts", failures)
+	}
+	if !errors.As(failureErr, &exhausted) || exhausted.LastStatus != http.StatusServiceUnavailable {
+		t.Errorf("FailureHook error = %v, want *RetryExhaustedError with status 503", failureErr)
+	}
+	if len(successes) != 2 {
+		t.Errorf("SuccessHook calls = %d, want none for the stale response", len(successes))
+	}
+}
+
+func TestHTTPUtil_ResponseCacheRevalidate(t *testing.T) {
+	var version atomic.Int32
+	version.Store(1)
+	var hits, notModified int32
+	var lastConditional atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		lastConditional.Store(r.Header.Get("If-None-Match") + "|" + r.Header.Get("If-Modified-Since"))
+		etag := fmt.Sprintf(`"v%d"`, version.Load())
+		modified := time.Date(2024, time.January, int(version.Load()), 0, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+		switch r.URL.Path {
+		case "/etag":
+			w.Header().Set("ETag", etag)
+			if r.Header.Get("If-None-Match") == etag {
+				atomic.AddInt32(&notModified, 1)
+				w.Header().Set("X-Served", "304")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		case "/modified":
+			w.Header().Set("Last-Modified", modified)
+			if r.Header.Get("If-Modified-Since") == modified {
+				atomic.AddInt32(&notModified, 1)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		_, _ = io.WriteString(w, fmt.Sprintf("v%d %s", version.Load(), r.URL.Path))
 	}))
 	defer server.Close()
 
-	_, err := util.Post(context.Background(), server.URL, bytes.NewBufferString(expectedBody), nil)
-	if err != nil {
-		t.Errorf("Expected no error, got: %v", err)
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{ResponseCache: &ResponseCacheConfig{Revalidate: true}})
+	get := func(t *testing.T, path string, headers map[string]string) (*http.Response, string) {
+		t.Helper()
+		resp, err := util.Get(context.Background(), server.URL+path, headers)
+		if err != nil {
+			t.Fatalf("Get(%s) unexpected error: %v", path, err)
+		}
+		body, _ := util.ReadBody(resp)
+		return resp, string(body)
 	}
-}
 
-func TestHTTPUtil_TimeoutHandling(t *testing.T) {
-	logger := logrus.New()
-	logger.SetLevel(logrus.ErrorLevel)
-	util := NewHTTPUtil(logger, nil).(*HTTPUtil)
-	util.Client.Timeout = 100 * time.Millisecond
-	util.MaxRetries = 0
+	for _, path := range []string{"/etag", "/modified"} {
+		t.Run(path, func(t *testing.T) {
+			version.Store(1)
+			atomic.StoreInt32(&notModified, 0)
+			if resp, body := get(t, path, nil); body != "v1 "+path || IsCached(resp) {
+				t.Fatalf("first Get() = %q cached=%v, want a fresh v1 response", body, IsCached(resp))
+			}
 
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		time.Sleep(200 * time.Millisecond)
-		w.WriteHeader(http.StatusOK)
-	}))
-	defer server.Close()
+			resp, body := get(t, path, nil)
+			if body != "v1 "+path || resp.StatusCode != http.StatusOK || resp.Header.Get(CacheStatusHeader) != CacheStatusRevalidated {
+				t.Errorf("second Get() = %d %q, status %q, want the stored 200 revalidated", resp.StatusCode, body, resp.Header.Get(CacheStatusHeader))
+			}
+			if resp.Header.Get("Content-Type") != "text/plain" {
+				t.Errorf("revalidated headers = %v, want the stored Content-Type", resp.Header)
+			}
+			if atomic.LoadInt32(&notModified) != 1 {
+				t.Errorf("304 responses = %d, want 1", notModified)
+			}
 
-	_, err := util.Get(context.Background(), server.URL, nil)
-	if err == nil {
-		t.Error("Expected timeout error")
+			version.Store(2)
+			if resp, body := get(t, path, nil); body != "v2 "+path || IsCached(resp) {
+				t.Errorf("Get() after a change = %q cached=%v, want the new body", body, IsCached(resp))
+			}
+			if _, body := get(t, path, nil); body != "v2 "+path {
+				t.Errorf("Get() after the update was stored = %q, want v2", body)
+			}
+		})
 	}
+
+	t.Run("headers from 304 refresh the stored response", func(t *testing.T) {
+		version.Store(3)
+		get(t, "/etag", nil)
+		get(t, "/etag", nil)
+		if resp, _ := get(t, "/etag", nil); resp.Header.Get("X-Served") != "304" {
+			t.Errorf("X-Served = %q, want the header merged from the 304", resp.Header.Get("X-Served"))
+		}
+	})
+
+	t.Run("caller validators are passed through", func(t *testing.T) {
+		version.Store(4)
+		get(t, "/etag", nil)
+		resp, err := util.Get(context.Background(), server.URL+"/etag", map[string]string{"If-None-Match": `"v4"`})
+		if err != nil {
+			t.Fatalf("Get() unexpected error: %v", err)
+		}
+		defer util.CloseResponse(resp)
+		if resp.StatusCode != http.StatusNotModified || IsCached(resp) {
+			t.Errorf("Get() status = %d cached=%v, want the 304 itself", resp.StatusCode, IsCached(resp))
+		}
+	})
+
+	t.Run("responses without validators are sent unconditionally", func(t *testing.T) {
+		get(t, "/plain", nil)
+		get(t, "/plain", nil)
+		if got := lastConditional.Load(); got != "|" {
+			t.Errorf("conditional headers = %q, want none", got)
+		}
+	})
 }
 
 func TestReadBody_EmptyBody(t *testing.T) {
@@ -762,7 +3688,7 @@ func TestDefaultHTTPConfig(t *testing.T) {
 
 func TestNewHTTPUtil_NilConfig(t *testing.T) {
 	logger := logrus.New()
-	client := NewHTTPUtil(logger, nil)
+	client := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil)
 
 	if client == nil {
 		t.Fatal("Expected client to be created with nil config")
@@ -801,7 +3727,7 @@ func TestNewHTTPUtil_CustomConfig(t *testing.T) {
 		},
 	}
 
-	client := NewHTTPUtil(logger, config)
+	client := NewHTTPUtil(logutil.NewLogrusLogger(logger), config)
 	httpUtil := client.(*HTTPUtil)
 
 	// Test custom values were applied
@@ -848,7 +3774,7 @@ func TestNewHTTPUtil_DefaultConfiguration(t *testing.T) {
 	logger := logrus.New()
 
 	// Test with nil config uses defaults
-	client := NewHTTPUtil(logger, nil)
+	client := NewHTTPUtil(logutil.NewLogrusLogger(logger), nil)
 	httpUtil := client.(*HTTPUtil)
 
 	// Should have default values
@@ -869,7 +3795,7 @@ func TestNewHTTPUtil_PartialOverride(t *testing.T) {
 	logger := logrus.New()
 
 	// Test partial override - only set some properties
-	client := NewHTTPUtil(logger, &HTTPConfig{
+	client := NewHTTPUtil(logutil.NewLogrusLogger(logger), &HTTPConfig{
 		ClientTimeout:       3 * time.Minute,
 		MaxRetries:          2,
 		MaxIdleConnsPerHost: 30,
@@ -902,3 +3828,560 @@ func TestNewHTTPUtil_PartialOverride(t *testing.T) {
 		t.Errorf("Expected TLSHandshakeTimeout to remain default (30s), got %v", transport.TLSHandshakeTimeout)
 	}
 }
+
+func TestChecksumFromHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   http.Header
+		wantAlgo string
+		wantOK   bool
+	}{
+		{"none", http.Header{}, "", false},
+		{"content-md5", http.Header{"Content-Md5": {"rL0Y20zC+Fzt72VPzMSk2A=="}}, ChecksumMD5, true},
+		{"digest prefers strongest", http.Header{"Digest": {"md5=rL0Y20zC+Fzt72VPzMSk2A==, SHA-256=LCa0a2j/xo/5m0U8HTBBNBNCLXBkg7+g+YpeiGJm564="}}, ChecksumSHA256, true},
+		{"digest over content-md5", http.Header{"Digest": {"sha-512=abc"}, "Content-Md5": {"x"}}, ChecksumSHA512, true},
+		{"unknown digest falls back", http.Header{"Digest": {"crc32c=abc"}, "Content-Md5": {"x"}}, ChecksumMD5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sum, ok := ChecksumFromHeaders(tt.header)
+			if ok != tt.wantOK {
+				t.Fatalf("ChecksumFromHeaders() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && sum.Algorithm != tt.wantAlgo {
+				t.Errorf("ChecksumFromHeaders() algorithm = %q, want %q", sum.Algorithm, tt.wantAlgo)
+			}
+		})
+	}
+}
+
+func TestHTTPUtil_DownloadTo_Checksum(t *testing.T) {
+	body := []byte("artifact contents")
+	sha := sha256.Sum256(body)
+	md5sum := md5.Sum(body)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/good":
+			w.Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(sha[:]))
+		case "/tampered":
+			w.Header().Set("Content-MD5", base64.StdEncoding.EncodeToString(make([]byte, md5.Size)))
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond}).(*HTTPUtil)
+
+	tests := []struct {
+		name     string
+		path     string
+		verify   *ChecksumOptions
+		wantErr  bool
+		mismatch bool
+	}{
+		{"no verification", "/good", nil, false, false},
+		{"header digest matches", "/good", &ChecksumOptions{FromHeaders: true}, false, false},
+		{"expected hex matches", "/plain", &ChecksumOptions{Expected: &Checksum{Algorithm: "SHA-256", Value: hex.EncodeToString(sha[:])}}, false, false},
+		{"expected base64 md5 matches", "/plain", &ChecksumOptions{Expected: &Checksum{Algorithm: ChecksumMD5, Value: base64.StdEncoding.EncodeToString(md5sum[:])}}, false, false},
+		{"header mismatch", "/tampered", &ChecksumOptions{FromHeaders: true}, true, true},
+		{"expected mismatch", "/good", &ChecksumOptions{Expected: &Checksum{Algorithm: ChecksumSHA256, Value: strings.Repeat("0", 64)}}, true, true},
+		{"no header to verify", "/plain", &ChecksumOptions{FromHeaders: true}, false, false},
+		{"unsupported algorithm", "/plain", &ChecksumOptions{Expected: &Checksum{Algorithm: "crc32", Value: "00"}}, true, false},
+		{"malformed digest", "/plain", &ChecksumOptions{Expected: &Checksum{Algorithm: ChecksumSHA256, Value: "abc"}}, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := util.DownloadTo(context.Background(), server.URL+tt.path, &buf, nil, tt.verify)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DownloadTo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var mismatch *ChecksumMismatchError
+			if errors.As(err, &mismatch) != tt.mismatch {
+				t.Errorf("DownloadTo() error = %v, want ChecksumMismatchError: %v", err, tt.mismatch)
+			}
+			if !tt.wantErr && (n != int64(len(body)) || buf.String() != string(body)) {
+				t.Errorf("DownloadTo() wrote %d bytes %q, want %q", n, buf.String(), body)
+			}
+		})
+	}
+
+	_, err := util.DownloadTo(context.Background(), server.URL+"/missing", io.Discard, nil, nil)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("DownloadTo() error = %v, want StatusError 404", err)
+	}
+}
+
+func TestHTTPUtil_SaveToFile(t *testing.T) {
+	body := []byte("release tarball")
+	sha := sha256.Sum256(body)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, nil).(*HTTPUtil)
+	dir := t.TempDir()
+
+	t.Run("verified file is written", func(t *testing.T) {
+		path := filepath.Join(dir, "ok.tar")
+		resp, err := util.Get(context.Background(), server.URL, nil)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		verify := &ChecksumOptions{Expected: &Checksum{Algorithm: ChecksumSHA256, Value: hex.EncodeToString(sha[:])}}
+		if err := util.SaveToFile(resp, path, verify); err != nil {
+			t.Fatalf("SaveToFile() error = %v", err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil || string(got) != string(body) {
+			t.Errorf("file contents = %q, %v, want %q", got, err, body)
+		}
+	})
+
+	t.Run("mismatch leaves destination untouched", func(t *testing.T) {
+		path := filepath.Join(dir, "bad.tar")
+		if err := os.WriteFile(path, []byte("previous"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		resp, err := util.Get(context.Background(), server.URL, nil)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		verify := &ChecksumOptions{Expected: &Checksum{Algorithm: ChecksumSHA256, Value: strings.Repeat("a", 64)}}
+		var mismatch *ChecksumMismatchError
+		if err := util.SaveToFile(resp, path, verify); !errors.As(err, &mismatch) {
+			t.Fatalf("SaveToFile() error = %v, want ChecksumMismatchError", err)
+		}
+		if got, _ := os.ReadFile(path); string(got) != "previous" {
+			t.Errorf("destination = %q, want it untouched", got)
+		}
+		entries, _ := os.ReadDir(dir)
+		for _, e := range entries {
+			if strings.HasSuffix(e.Name(), ".tmp") {
+				t.Errorf("temporary file %s was not cleaned up", e.Name())
+			}
+		}
+	})
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	body := []byte("artifact contents")
+	sha := sha256.Sum256(body)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/good":
+			w.Header().Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(sha[:]))
+		case "/tampered":
+			w.Header().Set("Content-MD5", base64.StdEncoding.EncodeToString(make([]byte, md5.Size)))
+		}
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond})
+
+	tests := []struct {
+		name      string
+		path      string
+		algo      string
+		expected  string
+		verifyErr error
+		mismatch  bool
+	}{
+		{"expected matches", "/plain", ChecksumSHA256, hex.EncodeToString(sha[:]), nil, false},
+		{"header matches", "/good", "", "", nil, false},
+		{"expected mismatch", "/good", ChecksumSHA256, strings.Repeat("0", 64), nil, true},
+		{"header mismatch", "/tampered", ChecksumSHA256, hex.EncodeToString(sha[:]), nil, true},
+		{"nothing to verify", "/plain", "", "", ErrNoChecksum, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := util.Get(context.Background(), server.URL+tt.path, nil)
+			if err != nil {
+				t.Fatalf("Get() unexpected error: %v", err)
+			}
+			defer util.CloseResponse(resp)
+
+			if err := VerifyChecksum(resp, tt.algo, tt.expected); !errors.Is(err, tt.verifyErr) {
+				t.Fatalf("VerifyChecksum() error = %v, want %v", err, tt.verifyErr)
+			}
+			data, err := io.ReadAll(resp.Body)
+			var mismatch *ChecksumMismatchError
+			if errors.As(err, &mismatch) != tt.mismatch {
+				t.Errorf("ReadAll() error = %v, want ChecksumMismatchError: %v", err, tt.mismatch)
+			}
+			if !tt.mismatch && (err != nil || string(data) != string(body)) {
+				t.Errorf("ReadAll() = %q, %v; want the body", data, err)
+			}
+		})
+	}
+
+	resp, _ := util.Get(context.Background(), server.URL+"/plain", nil)
+	defer util.CloseResponse(resp)
+	if err := VerifyChecksum(resp, "crc32", "00"); err == nil {
+		t.Error("VerifyChecksum() with an unsupported algorithm should fail")
+	}
+}
+
+func TestHTTPUtil_DownloadFile(t *testing.T) {
+	body := bytes.Repeat([]byte("artifact-"), 20000) // larger than one copy buffer
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/chunked":
+			flusher := w.(http.Flusher)
+			_, _ = w.Write(body[:10])
+			flusher.Flush()
+			_, _ = w.Write(body[10:])
+		case "/missing":
+			http.NotFound(w, r)
+		default:
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			_, _ = w.Write(body)
+		}
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{MaxRetries: 1, InitialWait: time.Millisecond}).(*HTTPUtil)
+	dir := t.TempDir()
+
+	tests := []struct {
+		name      string
+		path      string
+		wantTotal int64
+	}{
+		{"known length", "/file", int64(len(body))},
+		{"unknown length", "/chunked", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(dir, strings.TrimPrefix(tt.path, "/"))
+			var calls int
+			var lastWritten, lastTotal int64
+			sha := sha256.Sum256(body)
+			err := util.DownloadFile(context.Background(), server.URL+tt.path, dest, DownloadOptions{
+				Verify: &ChecksumOptions{Expected: &Checksum{Algorithm: ChecksumSHA256, Value: hex.EncodeToString(sha[:])}},
+				Progress: func(written, total int64) {
+					if written < lastWritten {
+						t.Errorf("progress went backwards: %d after %d", written, lastWritten)
+					}
+					calls++
+					lastWritten, lastTotal = written, total
+				},
+			})
+			if err != nil {
+				t.Fatalf("DownloadFile() error = %v", err)
+			}
+			if calls < 2 || lastWritten != int64(len(body)) || lastTotal != tt.wantTotal {
+				t.Errorf("progress calls = %d, last = (%d, %d), want several calls ending at (%d, %d)", calls, lastWritten, lastTotal, len(body), tt.wantTotal)
+			}
+			if got, _ := os.ReadFile(dest); !bytes.Equal(got, body) {
+				t.Errorf("file has %d bytes, want %d", len(got), len(body))
+			}
+		})
+	}
+
+	t.Run("non-2xx", func(t *testing.T) {
+		dest := filepath.Join(dir, "missing")
+		var statusErr *StatusError
+		err := util.DownloadFile(context.Background(), server.URL+"/missing", dest, DownloadOptions{})
+		if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+			t.Fatalf("DownloadFile() error = %v, want *StatusError 404", err)
+		}
+		if _, err := os.Stat(dest); !os.IsNotExist(err) {
+			t.Error("destination should not be created on failure")
+		}
+	})
+}
+
+func TestParseLinkHeader(t *testing.T) {
+	header := `<https://api.x.com/items?page=2>; rel="next", <https://api.x.com/items?page=9>; rel="last", </items?page=1>; rel="first prev", <bad>`
+	got := ParseLinkHeader(header)
+	want := map[string]string{
+		"next":  "https://api.x.com/items?page=2",
+		"last":  "https://api.x.com/items?page=9",
+		"first": "/items?page=1",
+		"prev":  "/items?page=1",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseLinkHeader() = %v, want %v", got, want)
+	}
+	for rel, link := range want {
+		if got[rel] != link {
+			t.Errorf("ParseLinkHeader()[%q] = %q, want %q", rel, got[rel], link)
+		}
+	}
+	if got := ParseLinkHeader(""); len(got) != 0 {
+		t.Errorf("ParseLinkHeader(\"\") = %v, want empty", got)
+	}
+}
+
+func TestHTTPUtil_GetAllPages(t *testing.T) {
+	type item struct {
+		ID int `json:"id"`
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page := r.URL.Query().Get("page")
+		cursor := r.URL.Query().Get("cursor")
+		switch r.URL.Path {
+		case "/link":
+			switch page {
+			case "":
+				w.Header().Set("Link", `</link?page=2>; rel="next"`)
+				_, _ = w.Write([]byte(`[{"id":1},{"id":2}]`))
+			case "2":
+				_, _ = w.Write([]byte(`[{"id":3}]`))
+			}
+		case "/cursor":
+			switch cursor {
+			case "":
+				_, _ = w.Write([]byte(`{"data":{"items":[{"id":1}]},"meta":{"next":"abc"}}`))
+			case "abc":
+				_, _ = w.Write([]byte(`{"data":{"items":[{"id":2}]},"meta":{"next":null}}`))
+			}
+		case "/next-url":
+			if page == "" {
+				_, _ = w.Write([]byte(`{"items":[{"id":1}],"links":{"next":"/next-url?page=2"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"items":[],"links":{}}`))
+		case "/endless":
+			w.Header().Set("Link", fmt.Sprintf(`</endless?page=%s1>; rel="next"`, page))
+			_, _ = w.Write([]byte(`[{"id":0}]`))
+		case "/loop":
+			w.Header().Set("Link", `</loop>; rel="next"`)
+			_, _ = w.Write([]byte(`[]`))
+		case "/error":
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, nil)
+
+	tests := []struct {
+		name      string
+		path      string
+		opts      PageOptions
+		wantIDs   []int
+		wantErr   error
+		wantAnErr bool
+	}{
+		{"link header", "/link", PageOptions{}, []int{1, 2, 3}, nil, false},
+		{"json cursor", "/cursor", PageOptions{ItemsPath: "data.items", CursorPath: "meta.next"}, []int{1, 2}, nil, false},
+		{"json next url", "/next-url", PageOptions{ItemsPath: "items", NextURLPath: "links.next"}, []int{1}, nil, false},
+		{"max pages", "/endless", PageOptions{MaxPages: 3}, []int{0, 0, 0}, ErrTooManyPages, true},
+		{"loop", "/loop", PageOptions{}, nil, nil, true},
+		{"status error", "/error", PageOptions{}, nil, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := GetAll[item](context.Background(), util, server.URL+tt.path, tt.opts)
+			if (err != nil) != tt.wantAnErr {
+				t.Fatalf("GetAll() error = %v, wantErr %v", err, tt.wantAnErr)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetAll() error = %v, want %v", err, tt.wantErr)
+			}
+			var ids []int
+			for _, it := range items {
+				ids = append(ids, it.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("GetAll() ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+
+	t.Run("append error stops pagination", func(t *testing.T) {
+		stop := errors.New("stop")
+		pages, err := util.GetAllPages(context.Background(), server.URL+"/link", PageOptions{}, func(json.RawMessage) error {
+			return stop
+		})
+		if !errors.Is(err, stop) || pages != 1 {
+			t.Errorf("GetAllPages() = %d, %v, want 1, %v", pages, err, stop)
+		}
+	})
+}
+
+func TestHTTPUtil_MaxConcurrentRequestsPerHost(t *testing.T) {
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > peak {
+			peak = inFlight
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, &HTTPConfig{MaxConcurrentRequestsPerHost: 2})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := util.Get(context.Background(), server.URL, nil)
+			if err != nil {
+				t.Errorf("Get() error = %v", err)
+				return
+			}
+			util.CloseResponse(resp)
+		}()
+	}
+	wg.Wait()
+
+	if peak > 2 {
+		t.Errorf("peak concurrent requests = %d, want at most 2", peak)
+	}
+}
+
+func TestHTTPUtil_PerHostRateLimit(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) })
+	limited := httptest.NewServer(handler)
+	defer limited.Close()
+	other := httptest.NewServer(handler)
+	defer other.Close()
+
+	limitedHost := strings.TrimPrefix(limited.URL, "http://")
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{
+		HostRateLimits: map[string]HostRateLimit{limitedHost: {RequestsPerSecond: 20, Burst: 1}},
+	}).(*HTTPUtil)
+
+	timeRequests := func(url string, n int) time.Duration {
+		start := time.Now()
+		for i := 0; i < n; i++ {
+			resp, err := util.Get(context.Background(), url, nil)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			util.CloseResponse(resp)
+		}
+		return time.Since(start)
+	}
+
+	if elapsed := timeRequests(limited.URL, 3); elapsed < 90*time.Millisecond {
+		t.Errorf("3 requests at 20/s with burst 1 took %v, want >= 100ms", elapsed)
+	}
+	if elapsed := timeRequests(other.URL, 3); elapsed > 40*time.Millisecond {
+		t.Errorf("requests to an unlisted host took %v, want no limiting", elapsed)
+	}
+
+	// The default limit applies to every host separately, matched without the port too
+	perHost := newHostRateLimiters(HostRateLimit{RequestsPerSecond: 1, Burst: 1}, map[string]HostRateLimit{"api.example.com": {RequestsPerSecond: 1000, Burst: 5}})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	for _, host := range []string{"a.example.com", "b.example.com", "API.example.com:8443", "api.example.com:8443"} {
+		if err := perHost.wait(ctx, host); err != nil {
+			t.Errorf("wait(%s) error = %v, want an immediate token", host, err)
+		}
+	}
+	if err := perHost.wait(ctx, "a.example.com"); err == nil {
+		t.Error("second wait for a.example.com should exceed the 1/s limit before the deadline")
+	}
+
+	if newHostRateLimiters(HostRateLimit{}, map[string]HostRateLimit{"x": {}}) != nil {
+		t.Error("newHostRateLimiters() without enabled limits should return nil")
+	}
+}
+
+func TestHTTPUtil_RetryAfterPausesHost(t *testing.T) {
+	var mu sync.Mutex
+	var hits []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits = append(hits, time.Now())
+		first := len(hits) == 1
+		mu.Unlock()
+		if first {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(logutil.NewNopLogger(), &HTTPConfig{
+		PerHostRateLimit: HostRateLimit{RequestsPerSecond: 1000, Burst: 100},
+		MaxRetries:       1,
+		InitialWait:      time.Millisecond,
+	}).(*HTTPUtil)
+
+	resp, err := util.Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	util.CloseResponse(resp)
+	host := strings.TrimPrefix(server.URL, "http://")
+	if util.hostRate.pausedUntil[host].IsZero() {
+		t.Error("a 429 with Retry-After should pause the host")
+	}
+
+	// Another caller is held back for the rest of a Retry-After window
+	pausedAt := time.Now()
+	util.hostRate.pause(host, 100*time.Millisecond)
+	resp, err = util.Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	util.CloseResponse(resp)
+	if waited := time.Since(pausedAt); waited < 90*time.Millisecond {
+		t.Errorf("request during pause went out after %v, want >= 100ms", waited)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(hits) != 3 || hits[1].Sub(hits[0]) < 900*time.Millisecond {
+		t.Errorf("hits = %d, retry after %v, want the retry to wait out Retry-After", len(hits), hits[1].Sub(hits[0]))
+	}
+}
+
+func TestHTTPUtil_HostSlotHeldUntilBodyClosed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	util := NewHTTPUtil(nil, &HTTPConfig{MaxConcurrentRequestsPerHost: 1})
+
+	first, err := util.Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := util.Get(ctx, server.URL, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() while slot is held error = %v, want context.DeadlineExceeded", err)
+	}
+
+	util.CloseResponse(first)
+	second, err := util.Get(context.Background(), server.URL, nil)
+	if err != nil {
+		t.Fatalf("Get() after releasing the slot error = %v", err)
+	}
+	util.CloseResponse(second)
+}
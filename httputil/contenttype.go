@@ -0,0 +1,52 @@
+package httputil
+
+import "net/http"
+
+// Media types set by the content type presets
+const (
+	ContentTypeJSON   = "application/json"
+	ContentTypeForm   = "application/x-www-form-urlencoded"
+	ContentTypeText   = "text/plain; charset=utf-8"
+	ContentTypeBinary = "application/octet-stream"
+)
+
+// WithJSON sends "Content-Type: application/json" and "Accept: application/json"
+func WithJSON() RequestOption {
+	return withContentType(ContentTypeJSON, ContentTypeJSON)
+}
+
+// WithFormURLEncoded sends a form-encoded body and accepts a JSON response, as OAuth2 and most form APIs reply
+func WithFormURLEncoded() RequestOption {
+	return withContentType(ContentTypeForm, ContentTypeJSON)
+}
+
+// WithPlainText sends and accepts UTF-8 plain text
+func WithPlainText() RequestOption {
+	return withContentType(ContentTypeText, ContentTypeText)
+}
+
+// WithBinary sends and accepts contentType, e.g. "application/pdf" (default application/octet-stream)
+func WithBinary(contentType string) RequestOption {
+	if contentType == "" {
+		contentType = ContentTypeBinary
+	}
+	return withContentType(contentType, contentType)
+}
+
+// withContentType sets Content-Type and Accept on a copy of the call's headers, replacing any spelling of
+// either header already there, so the preset wins over the header map and the caller's map is left untouched
+func withContentType(contentType, accept string) RequestOption {
+	return func(o *RequestOptions) {
+		headers := make(map[string]string, len(o.Headers)+2)
+		for k, v := range o.Headers {
+			switch http.CanonicalHeaderKey(k) {
+			case "Content-Type", "Accept":
+			default:
+				headers[k] = v
+			}
+		}
+		headers["Content-Type"] = contentType
+		headers["Accept"] = accept
+		o.Headers = headers
+	}
+}
@@ -0,0 +1,125 @@
+package httputil
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ErrCookiesNotClearable is returned by ClearCookies when the client's jar has no ClearDomain method
+var ErrCookiesNotClearable = errors.New("cookie jar does not support clearing cookies")
+
+// MemoryCookieJar is an in-memory http.CookieJar that can also forget the cookies of a domain
+// It stores cookies with net/http/cookiejar and remembers where each one was set so ClearDomain can
+// expire them, e.g. to log a session out without dropping the cookies of other services.
+type MemoryCookieJar struct {
+	psl cookiejar.PublicSuffixList
+
+	mu   sync.Mutex
+	jar  *cookiejar.Jar
+	seen map[string]setCookie
+}
+
+// setCookie records a cookie the jar accepted, as needed to expire it again
+type setCookie struct {
+	url    *url.URL
+	name   string
+	domain string
+	path   string
+}
+
+// NewMemoryCookieJar creates an empty jar
+// psl guards against cookies set for a public suffix such as co.uk (e.g. publicsuffix.List from
+// golang.org/x/net); nil accepts every domain the host sending the cookie belongs to.
+func NewMemoryCookieJar(psl cookiejar.PublicSuffixList) *MemoryCookieJar {
+	j := &MemoryCookieJar{psl: psl}
+	j.reset()
+	return j
+}
+
+// reset replaces the underlying jar with an empty one; callers hold mu except during construction
+func (j *MemoryCookieJar) reset() {
+	j.jar, _ = cookiejar.New(&cookiejar.Options{PublicSuffixList: j.psl}) // never fails
+	j.seen = map[string]setCookie{}
+}
+
+// SetCookies implements http.CookieJar
+func (j *MemoryCookieJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.jar.SetCookies(u, cookies)
+
+	host := strings.ToLower(u.Hostname())
+	for _, c := range cookies {
+		entry := setCookie{url: u, name: c.Name, domain: strings.TrimPrefix(strings.ToLower(c.Domain), "."), path: c.Path}
+		key := host + ";" + entry.domain + ";" + entry.path + ";" + entry.name
+		if c.MaxAge < 0 {
+			delete(j.seen, key)
+			continue
+		}
+		j.seen[key] = entry
+	}
+}
+
+// Cookies implements http.CookieJar
+func (j *MemoryCookieJar) Cookies(u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.jar.Cookies(u)
+}
+
+// ClearDomain expires every cookie set by or for domain and its subdomains; an empty domain clears the jar
+func (j *MemoryCookieJar) ClearDomain(domain string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	if domain == "" {
+		j.reset()
+		return
+	}
+	for key, c := range j.seen {
+		if !domainMatches(c.url.Hostname(), domain) && !domainMatches(c.domain, domain) {
+			continue
+		}
+		j.jar.SetCookies(c.url, []*http.Cookie{{Name: c.name, Domain: c.domain, Path: c.path, MaxAge: -1}})
+		delete(j.seen, key)
+	}
+}
+
+// domainMatches reports whether host is domain or one of its subdomains
+func domainMatches(host, domain string) bool {
+	host = strings.ToLower(host)
+	return host == domain || strings.HasSuffix(host, "."+domain)
+}
+
+// Cookies returns the cookies the client's jar would send to rawURL, or nil when it has no jar
+func (h *HTTPUtil) Cookies(rawURL string) ([]*http.Cookie, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cookie URL: %w", err)
+	}
+	if h.Client.Jar == nil {
+		return nil, nil
+	}
+	return h.Client.Jar.Cookies(u), nil
+}
+
+// ClearCookies forgets the session cookies of domain and its subdomains, or every cookie when domain is empty
+// It works with the built-in jar and with any custom jar that has a ClearDomain(domain string) method,
+// and returns ErrCookiesNotClearable for other jars.
+func (h *HTTPUtil) ClearCookies(domain string) error {
+	if h.Client.Jar == nil {
+		return nil
+	}
+	clearer, ok := h.Client.Jar.(interface{ ClearDomain(domain string) })
+	if !ok {
+		return ErrCookiesNotClearable
+	}
+	clearer.ClearDomain(domain)
+	return nil
+}
@@ -0,0 +1,125 @@
+package httputil
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// DownloadTo fetches url with GET and streams the body into w
+// Non-2xx responses return a *StatusError. With verify set, a digest mismatch returns a
+// *ChecksumMismatchError after the body has been written, so w should be discarded in that case.
+func (h *HTTPUtil) DownloadTo(ctx context.Context, url string, w io.Writer, headers map[string]string, verify *ChecksumOptions) (int64, error) {
+	resp, err := h.Get(ctx, url, headers)
+	if err != nil {
+		h.CloseResponse(resp)
+		return 0, err
+	}
+	defer h.CloseResponse(resp)
+
+	if !h.IsSuccess(resp) {
+		return 0, &StatusError{StatusCode: resp.StatusCode, Method: http.MethodGet, URL: url}
+	}
+	return copyVerified(w, resp, verify)
+}
+
+// DownloadOptions configures DownloadFile
+type DownloadOptions struct {
+	Headers map[string]string
+
+	// Checksum verification of the downloaded body (nil skips it)
+	Verify *ChecksumOptions
+
+	// Progress is called after every chunk written to disk with the bytes written so far and the
+	// expected total from Content-Length, which is -1 when unknown
+	Progress func(written, total int64)
+}
+
+// DownloadFile fetches url with GET and streams the body to destPath without buffering it in memory
+// Non-2xx responses return a *StatusError. The file is only moved into place once the download
+// completed (and matched opts.Verify), so destPath never holds a partial file.
+func (h *HTTPUtil) DownloadFile(ctx context.Context, url, destPath string, opts DownloadOptions) error {
+	resp, err := h.Get(ctx, url, opts.Headers)
+	if err != nil {
+		h.CloseResponse(resp)
+		return err
+	}
+	if !h.IsSuccess(resp) {
+		h.CloseResponse(resp)
+		return &StatusError{StatusCode: resp.StatusCode, Method: http.MethodGet, URL: url}
+	}
+	return h.saveToFile(resp, destPath, opts.Verify, opts.Progress)
+}
+
+// SaveToFile streams the response body to path and closes the body
+// The file is written to a temporary name in the same directory and renamed into place only after the
+// body has been fully read and, with verify set, its checksum matched; otherwise path is left untouched.
+func (h *HTTPUtil) SaveToFile(resp *http.Response, path string, verify *ChecksumOptions) error {
+	return h.saveToFile(resp, path, verify, nil)
+}
+
+// saveToFile implements SaveToFile with an optional progress callback
+func (h *HTTPUtil) saveToFile(resp *http.Response, path string, verify *ChecksumOptions, progress func(written, total int64)) error {
+	defer h.CloseResponse(resp)
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName) // no-op once renamed
+
+	var w io.Writer = tmp
+	if progress != nil {
+		w = &progressWriter{w: tmp, total: resp.ContentLength, report: progress}
+	}
+	if _, err := copyVerified(w, resp, verify); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	if err := os.Chmod(tmpName, 0o644); err != nil {
+		return fmt.Errorf("failed to set permissions on %s: %w", path, err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to move download into place: %w", err)
+	}
+	return nil
+}
+
+// copyVerified copies the response body into w while computing the digests requested by verify
+func copyVerified(w io.Writer, resp *http.Response, verify *ChecksumOptions) (int64, error) {
+	verifier, err := newChecksumVerifier(resp, verify)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(verifier.writer(w), resp.Body)
+	if err != nil {
+		return n, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return n, verifier.verify()
+}
+
+// progressWriter reports the running byte count after every write
+type progressWriter struct {
+	w       io.Writer
+	written int64
+	total   int64
+	report  func(written, total int64)
+}
+
+// Write implements io.Writer
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if n > 0 {
+		p.report(p.written, p.total)
+	}
+	return n, err
+}
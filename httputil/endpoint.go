@@ -0,0 +1,253 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownEndpoint is returned by Endpoints.Call for a name that was never registered
+var ErrUnknownEndpoint = errors.New("unknown endpoint")
+
+// Endpoint declares a named API call and the policies applied whenever it is made
+// Path is a URL template with {name} placeholders, e.g. "/users/{id}/orders". Relative paths are joined to the
+// registry base URL; absolute URLs are used as is. Zero-valued policy fields fall back to the client configuration.
+type Endpoint struct {
+	Name    string
+	Method  string // defaults to GET
+	Path    string
+	Headers map[string]string
+
+	// Bounds the whole call, including retries and backoff (0 means no limit beyond the caller's context)
+	Timeout time.Duration
+
+	// Retry overrides, as set by WithMaxRetries, WithBackoff and WithRetryOnStatus
+	MaxRetries    *int
+	InitialWait   time.Duration
+	MaxWait       time.Duration
+	RetryOnStatus []int
+
+	// Status codes treated as success; nil accepts any 2xx
+	ExpectedStatus []int
+}
+
+// Endpoints is a registry of named endpoints sharing a base URL and default headers
+// Every request it sends carries the endpoint name in its context (see EndpointName), so hooks and
+// middleware can label logs and metrics per endpoint rather than per URL.
+type Endpoints struct {
+	client  *HTTPUtil
+	baseURL string
+
+	// Headers sent with every call, e.g. Authorization; endpoint headers take precedence
+	Headers map[string]string
+
+	mu        sync.RWMutex
+	endpoints map[string]Endpoint
+}
+
+// Endpoints returns an empty endpoint registry for the API at baseURL
+func (h *HTTPUtil) Endpoints(baseURL string) *Endpoints {
+	return &Endpoints{client: h, baseURL: strings.TrimRight(baseURL, "/"), endpoints: map[string]Endpoint{}}
+}
+
+// Register adds endpoints to the registry
+// Names must be unique and templates well formed; on error nothing is registered.
+func (e *Endpoints) Register(endpoints ...Endpoint) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	seen := make(map[string]bool, len(endpoints))
+	for i, ep := range endpoints {
+		if ep.Name == "" {
+			return fmt.Errorf("endpoint %d: name cannot be empty", i)
+		}
+		if _, exists := e.endpoints[ep.Name]; exists || seen[ep.Name] {
+			return fmt.Errorf("endpoint %q is already registered", ep.Name)
+		}
+		if _, err := templateParams(ep.Path); err != nil {
+			return fmt.Errorf("endpoint %q: %w", ep.Name, err)
+		}
+		seen[ep.Name] = true
+	}
+	for _, ep := range endpoints {
+		ep.Method = strings.ToUpper(ep.Method)
+		if ep.Method == "" {
+			ep.Method = http.MethodGet
+		}
+		e.endpoints[ep.Name] = ep
+	}
+	return nil
+}
+
+// Lookup returns the endpoint registered under name
+func (e *Endpoints) Lookup(name string) (Endpoint, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	ep, ok := e.endpoints[name]
+	return ep, ok
+}
+
+// Names returns the registered endpoint names in sorted order
+func (e *Endpoints) Names() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	names := make([]string, 0, len(e.endpoints))
+	for name := range e.endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// URL expands the template of the named endpoint
+// Placeholder values are path-escaped; params without a placeholder are added as query parameters.
+func (e *Endpoints) URL(name string, params map[string]string) (string, error) {
+	ep, ok := e.Lookup(name)
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownEndpoint, name)
+	}
+	rawURL, query, err := e.expand(ep, params)
+	if err != nil {
+		return "", err
+	}
+	if len(query) == 0 {
+		return rawURL, nil
+	}
+	return mergeQuery(rawURL, query)
+}
+
+// Call invokes the named endpoint and decodes a successful JSON response into out
+// For example, Call(ctx, "getUser", map[string]string{"id": "42"}, &user). Per-call options are applied after
+// the endpoint's own policies, so they take precedence.
+func (e *Endpoints) Call(ctx context.Context, name string, params map[string]string, out any, opts ...RequestOption) error {
+	return e.CallWithBody(ctx, name, params, nil, out, opts...)
+}
+
+// CallWithBody is like Call but sends in as the JSON request body
+func (e *Endpoints) CallWithBody(ctx context.Context, name string, params map[string]string, in, out any, opts ...RequestOption) error {
+	ep, ok := e.Lookup(name)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownEndpoint, name)
+	}
+	rawURL, query, err := e.expand(ep, params)
+	if err != nil {
+		return err
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx = context.WithValue(ctx, endpointKey{}, ep.Name)
+	if ep.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ep.Timeout)
+		defer cancel()
+	}
+
+	headers := make(map[string]string, len(e.Headers)+len(ep.Headers))
+	for k, v := range e.Headers {
+		headers[k] = v
+	}
+	for k, v := range ep.Headers {
+		headers[k] = v
+	}
+
+	policy := func(o *RequestOptions) {
+		if len(query) > 0 {
+			WithQuery(query)(o)
+		}
+		if ep.MaxRetries != nil {
+			WithMaxRetries(*ep.MaxRetries)(o)
+		}
+		o.InitialWait, o.MaxWait = ep.InitialWait, ep.MaxWait
+		if ep.RetryOnStatus != nil {
+			WithRetryOnStatus(ep.RetryOnStatus...)(o)
+		}
+		if ep.ExpectedStatus != nil {
+			WithExpectedStatus(ep.ExpectedStatus...)(o)
+		}
+	}
+	return e.client.doJSON(ctx, ep.Method, rawURL, headers, in, out, append([]RequestOption{policy}, opts...))
+}
+
+// expand fills the endpoint template from params and returns the unused params as a query
+func (e *Endpoints) expand(ep Endpoint, params map[string]string) (string, url.Values, error) {
+	names, err := templateParams(ep.Path)
+	if err != nil {
+		return "", nil, fmt.Errorf("endpoint %q: %w", ep.Name, err)
+	}
+
+	path := ep.Path
+	used := make(map[string]bool, len(names))
+	for _, name := range names {
+		value, ok := params[name]
+		if !ok {
+			return "", nil, fmt.Errorf("endpoint %q: missing path parameter %q", ep.Name, name)
+		}
+		path = strings.Replace(path, "{"+name+"}", url.PathEscape(value), 1)
+		used[name] = true
+	}
+
+	var query url.Values
+	for k, v := range params {
+		if used[k] {
+			continue
+		}
+		if query == nil {
+			query = url.Values{}
+		}
+		query.Set(k, v)
+	}
+
+	if strings.Contains(path, "://") {
+		return path, query, nil
+	}
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return e.baseURL + path, query, nil
+}
+
+// templateParams returns the placeholder names of a URL template in order of appearance
+func templateParams(tmpl string) ([]string, error) {
+	var names []string
+	rest := tmpl
+	for {
+		open := strings.IndexAny(rest, "{}")
+		if open < 0 {
+			return names, nil
+		}
+		if rest[open] == '}' {
+			return nil, fmt.Errorf("unbalanced '}' in path template %q", tmpl)
+		}
+		end := strings.IndexAny(rest[open+1:], "{}")
+		if end < 0 || rest[open+1+end] != '}' {
+			return nil, fmt.Errorf("unterminated placeholder in path template %q", tmpl)
+		}
+		name := rest[open+1 : open+1+end]
+		if name == "" {
+			return nil, fmt.Errorf("empty placeholder in path template %q", tmpl)
+		}
+		names = append(names, name)
+		rest = rest[open+1+end+1:]
+	}
+}
+
+// endpointKey is the context key holding the name of the endpoint being called
+type endpointKey struct{}
+
+// EndpointName returns the name of the registered endpoint a request was made for, or "" for other requests
+// Use it in hooks (via options.Context or resp.Request.Context()) and middleware as a metrics label.
+func EndpointName(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	name, _ := ctx.Value(endpointKey{}).(string)
+	return name
+}
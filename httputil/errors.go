@@ -1,6 +1,16 @@
 package httputil
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/errorutil"
+)
+
+// errorCodes maps HTTP statuses to errorutil codes for the error types below
+var errorCodes = errorutil.NewErrorUtil(nil)
 
 // RetryExhaustedError is returned when all retry attempts are exhausted
 // It contains details about the last error, status code, number of attempts, URL, and method.
@@ -11,9 +21,78 @@ type RetryExhaustedError struct {
 	Attempts   int
 	URL        string
 	Method     string
+
+	// RequestID is the correlation ID sent with every attempt, or "" if the request had none
+	RequestID string
+
+	// StoppedByDeadline is set when retries remained but the next backoff would have outlasted the context deadline
+	StoppedByDeadline bool
 }
 
 // Error implements the error interface for RetryExhaustedError
 func (e *RetryExhaustedError) Error() string {
+	if e.StoppedByDeadline {
+		return fmt.Sprintf("retry stopped after %d attempts for %s %s, next wait exceeds the context deadline: HTTP %d: %v", e.Attempts, e.Method, e.URL, e.LastStatus, e.LastError)
+	}
 	return fmt.Sprintf("retry exhausted after %d attempts for %s %s: HTTP %d: %v", e.Attempts, e.Method, e.URL, e.LastStatus, e.LastError)
 }
+
+// ErrorCode implements errorutil.Coder from the last status, or the last error when no response arrived
+func (e *RetryExhaustedError) ErrorCode() errorutil.Code {
+	switch {
+	case e.LastStatus > 0:
+		return errorCodes.CodeForStatus(e.LastStatus)
+	case e.StoppedByDeadline || errors.Is(e.LastError, context.DeadlineExceeded):
+		return errorutil.CodeDeadlineExceeded
+	case errors.Is(e.LastError, context.Canceled):
+		return errorutil.CodeCanceled
+	}
+	return errorutil.CodeUnavailable
+}
+
+// retryableStatusError signals a retryable HTTP status to the retry loop
+// It carries the Retry-After wait for 429 responses so the backoff can honour it.
+type retryableStatusError struct {
+	status     int
+	retryAfter time.Duration
+}
+
+// Error implements the error interface for retryableStatusError
+func (e *retryableStatusError) Error() string {
+	return fmt.Sprintf("retryable HTTP status %d", e.status)
+}
+
+// RetryAfter implements retryutil.RetryAfterHinter
+func (e *retryableStatusError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// ChecksumMismatchError is returned when a downloaded body does not match its expected digest
+// Expected and Actual are hex encoded.
+type ChecksumMismatchError struct {
+	Algorithm string
+	Expected  string
+	Actual    string
+}
+
+// Error implements the error interface for ChecksumMismatchError
+func (e *ChecksumMismatchError) Error() string {
+	return fmt.Sprintf("%s checksum mismatch: expected %s, got %s", e.Algorithm, e.Expected, e.Actual)
+}
+
+// StatusError is returned by helpers that require a successful (2xx) response
+type StatusError struct {
+	StatusCode int
+	Method     string
+	URL        string
+}
+
+// Error implements the error interface for StatusError
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected HTTP %d for %s %s", e.StatusCode, e.Method, e.URL)
+}
+
+// ErrorCode implements errorutil.Coder, e.g. NOT_FOUND for a 404
+func (e *StatusError) ErrorCode() errorutil.Code {
+	return errorCodes.CodeForStatus(e.StatusCode)
+}
@@ -0,0 +1,64 @@
+package httputil
+
+import (
+	"encoding/base64"
+	"net/http"
+	"sync"
+
+	"github.com/mustanish/common-utils/v2/cryptoutil"
+)
+
+// defaultHeaders holds the headers sent with every request, guarded so credentials can be rotated while
+// requests are in flight
+type defaultHeaders struct {
+	mu     sync.RWMutex
+	header http.Header
+}
+
+// set stores value under key, or removes key when value is empty
+func (d *defaultHeaders) set(key, value string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if value == "" {
+		d.header.Del(key)
+		return
+	}
+	if d.header == nil {
+		d.header = http.Header{}
+	}
+	d.header.Set(key, value)
+}
+
+// apply copies the default headers onto req without replacing headers it already has
+func (d *defaultHeaders) apply(req *http.Request) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for key, values := range d.header {
+		if _, exists := req.Header[key]; !exists {
+			req.Header[key] = append([]string(nil), values...)
+		}
+	}
+}
+
+// SetHeader sends key: value with every request; an empty value removes the header
+// Headers passed to a call take precedence, and Signer or middleware such as BearerAuth run afterwards, so
+// they override it too. It is safe to call while requests are in flight, e.g. to rotate an API key.
+func (h *HTTPUtil) SetHeader(key, value string) {
+	h.headers.set(key, value)
+}
+
+// SetBasicAuth sends HTTP Basic credentials with every request, replacing any default Authorization header
+// The password is a cryptoutil.Secret, so it stays redacted wherever the caller logs or dumps it.
+func (h *HTTPUtil) SetBasicAuth(username string, password cryptoutil.Secret) {
+	h.headers.set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(username+":"+password.Reveal())))
+}
+
+// SetBearerToken sends "Authorization: Bearer <token>" with every request; an empty token removes it
+// Use BearerAuth with a TokenSource instead when tokens expire and must be refreshed.
+func (h *HTTPUtil) SetBearerToken(token cryptoutil.Secret) {
+	if token.IsZero() {
+		h.headers.set("Authorization", "")
+		return
+	}
+	h.headers.set("Authorization", "Bearer "+token.Reveal())
+}
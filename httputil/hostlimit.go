@@ -0,0 +1,62 @@
+package httputil
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// hostSemaphores bounds in-flight requests per host
+// A slot is held from just before Client.Do until the response body is closed,
+// so requests still streaming their body count against the limit.
+type hostSemaphores struct {
+	limit int
+
+	mu    sync.Mutex
+	slots map[string]chan struct{}
+}
+
+// newHostSemaphores creates per-host semaphores, or returns nil when limit is not positive
+func newHostSemaphores(limit int) *hostSemaphores {
+	if limit <= 0 {
+		return nil
+	}
+	return &hostSemaphores{limit: limit, slots: make(map[string]chan struct{})}
+}
+
+// acquire waits for a free slot for host and returns the function that releases it
+func (s *hostSemaphores) acquire(ctx context.Context, host string) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+
+	s.mu.Lock()
+	slot, ok := s.slots[host]
+	if !ok {
+		slot = make(chan struct{}, s.limit)
+		s.slots[host] = slot
+	}
+	s.mu.Unlock()
+
+	select {
+	case slot <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() { once.Do(func() { <-slot }) }, nil
+}
+
+// releaseOnClose releases a host slot when the response body is closed
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+// Close closes the body and frees the slot
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
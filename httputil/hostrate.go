@@ -0,0 +1,136 @@
+package httputil
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/ratelimitutil"
+)
+
+// HostRateLimit is a token-bucket limit for outbound requests to one host
+type HostRateLimit struct {
+	RequestsPerSecond float64 // steady rate; zero or negative disables the limit
+	Burst             int     // requests allowed at once after an idle period (at least 1)
+}
+
+// enabled reports whether the limit restricts anything
+func (l HostRateLimit) enabled() bool {
+	return l.RequestsPerSecond > 0
+}
+
+// hostRateLimiters applies per-host token buckets and Retry-After pauses
+// A 429 with Retry-After pauses every request to that host, not only the one being retried,
+// so concurrent callers stop hitting an upstream that already asked for a break.
+type hostRateLimiters struct {
+	defaults ratelimitutil.KeyedLimiterClient // nil when hosts without an explicit limit are unlimited
+	hosts    map[string]ratelimitutil.Limiter
+
+	mu          sync.Mutex
+	pausedUntil map[string]time.Time
+}
+
+// newHostRateLimiters creates the per-host limiters, or returns nil when no limit is configured
+func newHostRateLimiters(perHost HostRateLimit, hosts map[string]HostRateLimit) *hostRateLimiters {
+	l := &hostRateLimiters{hosts: make(map[string]ratelimitutil.Limiter), pausedUntil: make(map[string]time.Time)}
+	for host, limit := range hosts {
+		if limit.enabled() {
+			l.hosts[strings.ToLower(host)] = ratelimitutil.NewTokenBucket(limit.RequestsPerSecond, limit.Burst)
+		}
+	}
+	if perHost.enabled() {
+		l.defaults = ratelimitutil.NewKeyedLimiter(func() ratelimitutil.Limiter {
+			return ratelimitutil.NewTokenBucket(perHost.RequestsPerSecond, perHost.Burst)
+		}, nil)
+	}
+	if l.defaults == nil && len(l.hosts) == 0 {
+		return nil
+	}
+	return l
+}
+
+// wait blocks until host is not paused and a token for it is available, or ctx is done
+func (l *hostRateLimiters) wait(ctx context.Context, host string) error {
+	if l == nil {
+		return nil
+	}
+	host = strings.ToLower(host)
+
+	l.mu.Lock()
+	until := l.pausedUntil[host]
+	l.mu.Unlock()
+	if d := time.Until(until); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if limiter, ok := l.hosts[host]; ok {
+		return limiter.Wait(ctx)
+	}
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		if limiter, ok := l.hosts[name]; ok {
+			return limiter.Wait(ctx)
+		}
+	}
+	if l.defaults != nil {
+		return l.defaults.Wait(ctx, host)
+	}
+	return nil
+}
+
+// pause holds back requests to host for d, extending but never shortening an existing pause
+func (l *hostRateLimiters) pause(host string, d time.Duration) {
+	if l == nil || d <= 0 {
+		return
+	}
+	host = strings.ToLower(host)
+	until := time.Now().Add(d)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until.After(l.pausedUntil[host]) {
+		l.pausedUntil[host] = until
+	}
+}
+
+// RateLimitFeedback describes one response as a rate limit signal, see SetRateLimitFeedbackHook
+type RateLimitFeedback struct {
+	Host       string
+	StatusCode int
+	RetryAfter time.Duration // parsed Retry-After of a 429 or 503 response, 0 when absent or unparsable
+}
+
+// SetRateLimitFeedbackHook sets a hook that receives every response as a rate limit signal, e.g. to drive
+// one ratelimitutil.AdaptiveLimiter per host. A RateLimiter implementing ratelimitutil.FeedbackReceiver
+// (such as ratelimitutil.NewAdaptiveLimiter) is fed automatically, with or without a hook.
+func (h *HTTPUtil) SetRateLimitFeedbackHook(hook func(feedback RateLimitFeedback)) {
+	h.RateLimitFeedbackHook = hook
+}
+
+// reportRateLimit passes the status and Retry-After of an attempt's response to the feedback receivers
+func (h *HTTPUtil) reportRateLimit(host string, resp *http.Response) {
+	receiver, _ := h.RateLimiter.(ratelimitutil.FeedbackReceiver)
+	if receiver == nil && h.RateLimitFeedbackHook == nil {
+		return
+	}
+	feedback := RateLimitFeedback{Host: host, StatusCode: resp.StatusCode}
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			feedback.RetryAfter = wait
+		}
+	}
+	if receiver != nil {
+		receiver.Feedback(feedback.StatusCode, feedback.RetryAfter)
+	}
+	if h.RateLimitFeedbackHook != nil {
+		h.RateLimitFeedbackHook(feedback)
+	}
+}
@@ -0,0 +1,169 @@
+package httputil
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTP3Config sends https requests over HTTP/3 (QUIC), falling back to the standard transport
+// The QUIC implementation is supplied by the caller so this module does not depend on one, e.g.
+//
+//	&HTTPConfig{HTTP3: &HTTP3Config{Transport: &http3.Transport{TLSClientConfig: tlsConfig}}}
+//
+// with github.com/quic-go/quic-go/http3. HTTPConfig.TLS and Proxy do not apply to Transport, so pass it
+// its own TLS configuration. Retries, hooks and middleware see one attempt whichever protocol served it.
+type HTTP3Config struct {
+	// HTTP/3 round tripper (required; a nil Transport disables HTTP/3)
+	Transport http.RoundTripper
+
+	// Only use HTTP/3 for hosts that advertised it with an Alt-Svc h3 header on an earlier response
+	// (default: try HTTP/3 first for every https request)
+	RequireAltSvc bool
+
+	// How long a host is sent over the fallback transport after an HTTP/3 failure (default 5 minutes)
+	FallbackDuration time.Duration
+}
+
+// defaultAltSvcMaxAge is how long an Alt-Svc advertisement without ma= is remembered (RFC 7838)
+const defaultAltSvcMaxAge = 24 * time.Hour
+
+// http3Transport tries HTTP/3 first and sends the request over fallback when it fails
+// The fallback is the standard transport, which negotiates HTTP/2 over TLS when ForceAttemptHTTP2 is set.
+type http3Transport struct {
+	h3       http.RoundTripper
+	fallback http.RoundTripper
+	config   HTTP3Config
+	now      func() time.Time
+
+	mu     sync.Mutex
+	broken map[string]time.Time // host -> time HTTP/3 may be tried again
+	altSvc map[string]time.Time // host -> expiry of its h3 advertisement
+}
+
+// newHTTP3Transport wraps fallback with HTTP/3 support, or returns fallback when config has no Transport
+func newHTTP3Transport(config *HTTP3Config, fallback http.RoundTripper) http.RoundTripper {
+	if config == nil || config.Transport == nil {
+		return fallback
+	}
+	c := *config
+	if c.FallbackDuration <= 0 {
+		c.FallbackDuration = 5 * time.Minute
+	}
+	return &http3Transport{
+		h3:       c.Transport,
+		fallback: fallback,
+		config:   c,
+		now:      time.Now,
+		broken:   make(map[string]time.Time),
+		altSvc:   make(map[string]time.Time),
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *http3Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if req.URL.Scheme != "https" || !t.useHTTP3(host) {
+		resp, err := t.fallback.RoundTrip(req)
+		if err == nil {
+			t.recordAltSvc(host, resp.Header)
+		}
+		return resp, err
+	}
+
+	// The fallback needs a fresh body, so a body that can only be read once is sent over HTTP/3 only
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	resp, err := t.h3.RoundTrip(req)
+	if err == nil || !replayable || req.Context().Err() != nil {
+		return resp, err
+	}
+
+	t.markBroken(host)
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return nil, fmt.Errorf("HTTP/3 request failed (%v) and the body could not be replayed: %w", err, bodyErr)
+		}
+		retry.Body = body
+	}
+	return t.fallback.RoundTrip(retry)
+}
+
+// useHTTP3 reports whether host should be tried over HTTP/3
+func (t *http3Transport) useHTTP3(host string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	if until, ok := t.broken[host]; ok {
+		if now.Before(until) {
+			return false
+		}
+		delete(t.broken, host)
+	}
+	if !t.config.RequireAltSvc {
+		return true
+	}
+	expiry, ok := t.altSvc[host]
+	if ok && !now.Before(expiry) {
+		delete(t.altSvc, host)
+		return false
+	}
+	return ok
+}
+
+// markBroken sends host over the fallback transport for FallbackDuration
+func (t *http3Transport) markBroken(host string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.broken[host] = t.now().Add(t.config.FallbackDuration)
+}
+
+// recordAltSvc remembers or forgets the h3 advertisement in an Alt-Svc header
+func (t *http3Transport) recordAltSvc(host string, header http.Header) {
+	if !t.config.RequireAltSvc {
+		return
+	}
+	values := header.Values("Alt-Svc")
+	if len(values) == 0 {
+		return
+	}
+	maxAge, ok := parseAltSvcH3(strings.Join(values, ","))
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !ok {
+		delete(t.altSvc, host)
+		return
+	}
+	t.altSvc[host] = t.now().Add(maxAge)
+}
+
+// parseAltSvcH3 returns the max age of the h3 alternative in an Alt-Svc value
+// Only alternatives on the same host are honoured, as the HTTP/3 transport dials the request host;
+// "clear" and values without h3 report false.
+func parseAltSvcH3(value string) (time.Duration, bool) {
+	for _, alt := range strings.Split(value, ",") {
+		params := strings.Split(alt, ";")
+		protocol, authority, found := strings.Cut(strings.TrimSpace(params[0]), "=")
+		if !found || protocol != "h3" {
+			continue
+		}
+		if authority = strings.Trim(authority, `"`); !strings.HasPrefix(authority, ":") {
+			continue
+		}
+		maxAge := defaultAltSvcMaxAge
+		for _, param := range params[1:] {
+			key, val, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if key == "ma" {
+				if seconds, err := strconv.ParseInt(val, 10, 64); err == nil && seconds >= 0 {
+					maxAge = time.Duration(seconds) * time.Second
+				}
+			}
+		}
+		return maxAge, true
+	}
+	return 0, false
+}
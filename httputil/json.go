@@ -0,0 +1,105 @@
+package httputil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// GetJSON sends a GET request and decodes a successful JSON response into out
+// Non-2xx responses return a *StatusError; the body is always closed. A nil out discards the body.
+func (h *HTTPUtil) GetJSON(ctx context.Context, url string, headers map[string]string, out any, opts ...RequestOption) error {
+	return h.doJSON(ctx, http.MethodGet, url, headers, nil, out, opts)
+}
+
+// PostJSON marshals in as the JSON request body, sends a POST and decodes a successful JSON response into out
+// Non-2xx responses return a *StatusError; the body is always closed. A nil out discards the body.
+func (h *HTTPUtil) PostJSON(ctx context.Context, url string, in any, headers map[string]string, out any, opts ...RequestOption) error {
+	return h.doJSON(ctx, http.MethodPost, url, headers, in, out, opts)
+}
+
+// Do performs the request described by opts and decodes a successful JSON response into T
+// The body is always closed; resp is returned for its status and headers. Non-2xx responses return a
+// *StatusError (or, with opts.ExpectedStatus set, any status not listed), and an empty body (e.g. 204) yields the zero T.
+// Any method is accepted, e.g. PROPFIND or REPORT; it is upper-cased before sending through HTTPClient.Do.
+func Do[T any](client HTTPClient, opts RequestOptions) (T, *http.Response, error) {
+	var out T
+	opts.Method = strings.ToUpper(opts.Method)
+	resp, err := client.Do(opts)
+	if err != nil {
+		client.CloseResponse(resp)
+		return out, resp, err
+	}
+	if !statusExpected(client, resp, opts.ExpectedStatus) {
+		client.CloseResponse(resp)
+		return out, resp, &StatusError{StatusCode: resp.StatusCode, Method: opts.Method, URL: opts.URL}
+	}
+	if err := client.DecodeJSON(resp, &out); err != nil && !errors.Is(err, io.EOF) {
+		return out, resp, fmt.Errorf("failed to decode response from %s %s: %w", opts.Method, opts.URL, err)
+	}
+	return out, resp, nil
+}
+
+// doJSON sends in as a JSON body (when non-nil) and decodes a successful response into out (when non-nil)
+func (h *HTTPUtil) doJSON(ctx context.Context, method, rawURL string, headers map[string]string, in, out any, opts []RequestOption) error {
+	reqHeaders := map[string]string{"Accept": ContentTypeJSON}
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+		body = bytes.NewReader(payload)
+		reqHeaders["Content-Type"] = ContentTypeJSON
+	}
+	for k, v := range headers {
+		reqHeaders[k] = v
+	}
+
+	options := applyOptions(RequestOptions{
+		Method:  method,
+		URL:     rawURL,
+		Body:    body,
+		Headers: reqHeaders,
+		Context: ctx,
+	}, opts)
+	resp, err := h.doRequest(options)
+	if err != nil {
+		h.CloseResponse(resp)
+		return err
+	}
+	defer h.CloseResponse(resp)
+
+	if !statusExpected(h, resp, options.ExpectedStatus) {
+		return &StatusError{StatusCode: resp.StatusCode, Method: method, URL: rawURL}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	respBody, err := h.decodedBody(resp)
+	if err != nil {
+		return err
+	}
+	if err := json.NewDecoder(respBody).Decode(out); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode response from %s %s: %w", method, rawURL, err)
+	}
+	return nil
+}
+
+// statusExpected reports whether resp has one of the expected status codes, or any 2xx when none are given
+func statusExpected(client HTTPClient, resp *http.Response, expected []int) bool {
+	if len(expected) == 0 {
+		return client.IsSuccess(resp)
+	}
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
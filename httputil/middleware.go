@@ -0,0 +1,48 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+)
+
+// RoundTripFunc sends a single HTTP request attempt and returns its response
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a RoundTripFunc to inspect or modify requests and responses
+// A middleware may return without calling next, e.g. to serve a cached response or reject a request.
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// attemptKey carries the zero-based attempt number in the request context
+type attemptKey struct{}
+
+// Attempt returns the zero-based attempt number of a request sent by HTTPUtil, e.g. for metrics middleware
+// It is 0 for the first try and for requests that did not come from HTTPUtil.
+func Attempt(ctx context.Context) int {
+	n, _ := ctx.Value(attemptKey{}).(int)
+	return n
+}
+
+// Use appends middlewares to the chain wrapped around every attempt
+// The first middleware registered is the outermost. The retry loop runs outside the chain, so each
+// retry passes through every middleware again and sees a fresh *http.Request.
+// Use is meant to be called while setting up the client, not concurrently with requests.
+func (h *HTTPUtil) Use(middleware ...Middleware) {
+	for _, m := range middleware {
+		if m != nil {
+			h.middlewares = append(h.middlewares, m)
+		}
+	}
+}
+
+// roundTrip sends req through the middleware chain, ending in the underlying http.Client
+// Timing collection sits innermost so middleware latency is not counted.
+func (h *HTTPUtil) roundTrip(req *http.Request) (*http.Response, error) {
+	next := RoundTripFunc(h.Client.Do)
+	if h.EnableTimings {
+		next = timedDo(next)
+	}
+	for i := len(h.middlewares) - 1; i >= 0; i-- {
+		next = h.middlewares[i](next)
+	}
+	return next(req)
+}
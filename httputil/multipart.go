@@ -0,0 +1,141 @@
+package httputil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// FileField is a file part of a multipart/form-data body
+type FileField struct {
+	FieldName   string
+	FileName    string
+	ContentType string // defaults to application/octet-stream
+
+	// Open returns the file content; it is called once per attempt so retries can resend the file
+	Open func() (io.ReadCloser, error)
+}
+
+// FileFromPath returns a FileField that reads the file at path, named after its base name
+func FileFromPath(fieldName, path string) FileField {
+	return FileField{
+		FieldName: fieldName,
+		FileName:  filepath.Base(path),
+		Open: func() (io.ReadCloser, error) {
+			return os.Open(path)
+		},
+	}
+}
+
+// FileFromReader returns a FileField that streams r
+// A reader can only be consumed once, so a retry after r was read fails instead of sending a truncated file.
+func FileFromReader(fieldName, fileName string, r io.Reader) FileField {
+	var once sync.Once
+	return FileField{
+		FieldName: fieldName,
+		FileName:  fileName,
+		Open: func() (io.ReadCloser, error) {
+			var rc io.ReadCloser
+			once.Do(func() { rc = io.NopCloser(r) })
+			if rc == nil {
+				return nil, errors.New("file reader already consumed by a previous attempt")
+			}
+			return rc, nil
+		},
+	}
+}
+
+// quoteEscaper escapes values placed in Content-Disposition parameters, as mime/multipart does
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+// PostMultipart sends a multipart/form-data POST with the given form fields and files
+// The body is generated while it is sent, so files are streamed rather than loaded into memory.
+// Fields are written in key order, followed by the files in the order given.
+func (h *HTTPUtil) PostMultipart(ctx context.Context, url string, fields map[string]string, files []FileField, headers map[string]string, opts ...RequestOption) (*http.Response, error) {
+	for i, f := range files {
+		if f.FieldName == "" || f.Open == nil {
+			return nil, fmt.Errorf("file %d: field name and Open are required", i)
+		}
+	}
+
+	boundary := multipart.NewWriter(io.Discard).Boundary()
+	reqHeaders := make(map[string]string, len(headers)+1)
+	for k, v := range headers {
+		reqHeaders[k] = v
+	}
+	reqHeaders["Content-Type"] = "multipart/form-data; boundary=" + boundary
+
+	return h.doRequest(applyOptions(RequestOptions{
+		Method:  http.MethodPost,
+		URL:     url,
+		Headers: reqHeaders,
+		Context: ctx,
+		GetBody: func() (io.ReadCloser, error) {
+			pr, pw := io.Pipe()
+			mw := multipart.NewWriter(pw)
+			if err := mw.SetBoundary(boundary); err != nil {
+				return nil, err
+			}
+			go func() {
+				pw.CloseWithError(writeMultipart(mw, fields, files))
+			}()
+			return pr, nil
+		},
+	}, opts))
+}
+
+// writeMultipart writes every field and file part and the closing boundary
+func writeMultipart(mw *multipart.Writer, fields map[string]string, files []FileField) error {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := mw.WriteField(k, fields[k]); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range files {
+		if err := writeFilePart(mw, f); err != nil {
+			return err
+		}
+	}
+	return mw.Close()
+}
+
+// writeFilePart copies a single file into its own part
+func writeFilePart(mw *multipart.Writer, f FileField) error {
+	contentType := f.ContentType
+	if contentType == "" {
+		contentType = ContentTypeBinary
+	}
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+		quoteEscaper.Replace(f.FieldName), quoteEscaper.Replace(f.FileName)))
+	header.Set("Content-Type", contentType)
+
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		return err
+	}
+	content, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.FieldName, err)
+	}
+	defer content.Close()
+	if _, err := io.Copy(part, content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.FieldName, err)
+	}
+	return nil
+}
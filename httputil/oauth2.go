@@ -0,0 +1,246 @@
+package httputil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/cryptoutil"
+)
+
+// Token is an OAuth2 access token; the token value is redacted when the Token is printed or logged
+type Token struct {
+	AccessToken cryptoutil.Secret
+	TokenType   string    // "Bearer" unless the server says otherwise
+	Expiry      time.Time // zero when the server sent no expires_in
+
+	refreshAt time.Time
+}
+
+// TokenSource supplies access tokens for BearerAuth
+// Invalidate discards the cached token if it is still accessToken, so the next Token call fetches a new one.
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+	Invalidate(accessToken string)
+}
+
+// ClientCredentialsConfig configures the OAuth2 client-credentials grant (RFC 6749 section 4.4)
+type ClientCredentialsConfig struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret cryptoutil.Secret
+	Scopes       []string
+	Params       url.Values // extra form parameters, e.g. audience
+
+	// Send client_id and client_secret as form fields instead of HTTP Basic authentication
+	CredentialsInBody bool
+
+	// How long before expiry a token is refreshed (default 1 minute, at most half the token lifetime)
+	RefreshBefore time.Duration
+
+	// Client used for token requests (default: a client with a 30 second timeout)
+	// Do not pass a client whose requests go through BearerAuth for the same source.
+	Client *http.Client
+}
+
+// ClientCredentialsSource fetches, caches and refreshes client-credentials tokens
+// Concurrent callers that find the token missing or due for refresh share a single token request.
+type ClientCredentialsSource struct {
+	config ClientCredentialsConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	token    *Token
+	fetching chan struct{} // closed when the in-flight token request finishes
+	fetchErr error         // result of the last token request
+}
+
+// NewClientCredentialsSource creates a token source for the client-credentials grant
+func NewClientCredentialsSource(config ClientCredentialsConfig) *ClientCredentialsSource {
+	if config.RefreshBefore <= 0 {
+		config.RefreshBefore = time.Minute
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &ClientCredentialsSource{config: config, now: time.Now}
+}
+
+// Token returns the cached token, fetching a new one when it is missing or due for refresh
+func (s *ClientCredentialsSource) Token(ctx context.Context) (*Token, error) {
+	for {
+		s.mu.Lock()
+		if s.usable(s.token) {
+			token := s.token
+			s.mu.Unlock()
+			return token, nil
+		}
+		if s.fetching == nil {
+			done := make(chan struct{})
+			s.fetching = done
+			s.mu.Unlock()
+
+			token, err := s.fetch(ctx)
+
+			s.mu.Lock()
+			if err == nil {
+				s.token = token
+			}
+			s.fetchErr = err
+			s.fetching = nil
+			close(done)
+			s.mu.Unlock()
+			return token, err
+		}
+		wait := s.fetching
+		s.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		s.mu.Lock()
+		token, err := s.token, s.fetchErr
+		s.mu.Unlock()
+		if s.usable(token) {
+			return token, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Invalidate discards the cached token if it is still accessToken
+func (s *ClientCredentialsSource) Invalidate(accessToken string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != nil && s.token.AccessToken.Reveal() == accessToken {
+		s.token = nil
+	}
+}
+
+// usable reports whether token exists and is not yet due for refresh
+func (s *ClientCredentialsSource) usable(token *Token) bool {
+	return token != nil && (token.refreshAt.IsZero() || s.now().Before(token.refreshAt))
+}
+
+// tokenResponse is the JSON body of a token endpoint response
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// fetch requests a new token from the token endpoint
+func (s *ClientCredentialsSource) fetch(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+	for k, vs := range s.config.Params {
+		form[k] = append([]string(nil), vs...)
+	}
+	if s.config.CredentialsInBody {
+		form.Set("client_id", s.config.ClientID)
+		form.Set("client_secret", s.config.ClientSecret.Reveal())
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", ContentTypeForm)
+	req.Header.Set("Accept", ContentTypeJSON)
+	if !s.config.CredentialsInBody {
+		req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret.Reveal()))
+	}
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body tokenResponse
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+	jsonErr := json.Unmarshal(data, &body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if jsonErr == nil && body.Error != "" {
+			return nil, fmt.Errorf("token request failed: HTTP %d: %s %s", resp.StatusCode, body.Error, body.ErrorDescription)
+		}
+		return nil, fmt.Errorf("token request failed: HTTP %d", resp.StatusCode)
+	}
+	if jsonErr != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", jsonErr)
+	}
+	if body.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+
+	token := &Token{AccessToken: cryptoutil.NewSecret(body.AccessToken), TokenType: body.TokenType}
+	if token.TokenType == "" || strings.EqualFold(token.TokenType, "bearer") {
+		token.TokenType = "Bearer"
+	}
+	if body.ExpiresIn > 0 {
+		now := s.now()
+		lifetime := time.Duration(body.ExpiresIn) * time.Second
+		token.Expiry = now.Add(lifetime)
+		early := s.config.RefreshBefore
+		if early > lifetime/2 {
+			early = lifetime / 2
+		}
+		token.refreshAt = token.Expiry.Add(-early)
+	}
+	return token, nil
+}
+
+// BearerAuth returns a Middleware that sets Authorization from source on every attempt
+// With refreshOnUnauthorized, a 401 response invalidates the token and the request is sent once more with
+// a freshly fetched one; requests whose body cannot be replayed are not resent.
+func BearerAuth(source TokenSource, refreshOnUnauthorized bool) Middleware {
+	return func(next RoundTripFunc) RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			token, err := source.Token(req.Context())
+			if err != nil {
+				return nil, fmt.Errorf("failed to obtain access token: %w", err)
+			}
+			req.Header.Set("Authorization", token.TokenType+" "+token.AccessToken.Reveal())
+			resp, err := next(req)
+			if err != nil || resp == nil || resp.StatusCode != http.StatusUnauthorized || !refreshOnUnauthorized {
+				return resp, err
+			}
+			if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+				return resp, nil
+			}
+
+			source.Invalidate(token.AccessToken.Reveal())
+			fresh, err := source.Token(req.Context())
+			if err != nil || fresh.AccessToken.Equal(token.AccessToken) {
+				return resp, nil
+			}
+			retry := req.Clone(req.Context())
+			if req.GetBody != nil {
+				if retry.Body, err = req.GetBody(); err != nil {
+					return resp, nil
+				}
+			}
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			_ = resp.Body.Close()
+			retry.Header.Set("Authorization", fresh.TokenType+" "+fresh.AccessToken.Reveal())
+			return next(retry)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package httputil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrTooManyPages is returned by GetAllPages when more pages remain after MaxPages
+// Items from the pages already fetched have been passed to the append function.
+var ErrTooManyPages = errors.New("pagination exceeded max pages")
+
+// PageOptions configures how GetAllPages finds items and the next page
+// The next page is located by CursorPath, then NextURLPath, then the Link header (rel="next").
+type PageOptions struct {
+	Headers map[string]string
+
+	// ItemsPath is the dot-separated path to the item array in each page, e.g. "data.items"
+	// Empty means the response body itself is the array.
+	ItemsPath string
+
+	// CursorPath is the dot-separated path to the next-page cursor, e.g. "meta.next_cursor"
+	// The cursor is sent back in the CursorParam query parameter; an empty or null cursor ends pagination.
+	CursorPath  string
+	CursorParam string
+
+	// NextURLPath is the dot-separated path to an absolute or relative next-page URL, e.g. "links.next"
+	NextURLPath string
+
+	// MaxPages bounds the number of requests (0 uses the default of 100)
+	MaxPages int
+}
+
+// defaultMaxPages bounds GetAllPages when PageOptions.MaxPages is unset
+const defaultMaxPages = 100
+
+// GetAllPages follows pagination from url, passing each page's item array to appendFn
+// It returns the number of pages fetched. Non-2xx responses return a *StatusError.
+func (h *HTTPUtil) GetAllPages(ctx context.Context, url string, opts PageOptions, appendFn func(items json.RawMessage) error) (int, error) {
+	maxPages := opts.MaxPages
+	if maxPages <= 0 {
+		maxPages = defaultMaxPages
+	}
+	cursorParam := opts.CursorParam
+	if cursorParam == "" {
+		cursorParam = "cursor"
+	}
+
+	seen := make(map[string]bool)
+	next := url
+	for pages := 0; next != ""; pages++ {
+		if pages == maxPages {
+			return pages, fmt.Errorf("%w: stopped after %d pages", ErrTooManyPages, maxPages)
+		}
+		if seen[next] {
+			return pages, fmt.Errorf("pagination loop detected at %s", next)
+		}
+		seen[next] = true
+
+		body, header, err := h.fetchPage(ctx, next, opts.Headers)
+		if err != nil {
+			return pages, err
+		}
+
+		items, err := jsonAtPath(body, opts.ItemsPath)
+		if err != nil {
+			return pages, fmt.Errorf("failed to read items from %s: %w", next, err)
+		}
+		if items != nil {
+			if err := appendFn(items); err != nil {
+				return pages + 1, err
+			}
+		}
+
+		if next, err = nextPageURL(next, body, header, opts, cursorParam); err != nil {
+			return pages + 1, err
+		}
+	}
+	return len(seen), nil
+}
+
+// GetAll follows pagination like GetAllPages and decodes every page's items into a single slice
+func GetAll[T any](ctx context.Context, client HTTPClient, url string, opts PageOptions) ([]T, error) {
+	var all []T
+	_, err := client.GetAllPages(ctx, url, opts, func(items json.RawMessage) error {
+		var page []T
+		if err := json.Unmarshal(items, &page); err != nil {
+			return fmt.Errorf("failed to decode page items: %w", err)
+		}
+		all = append(all, page...)
+		return nil
+	})
+	return all, err
+}
+
+// fetchPage performs a GET and returns the body and headers of a successful response
+func (h *HTTPUtil) fetchPage(ctx context.Context, pageURL string, headers map[string]string) (json.RawMessage, http.Header, error) {
+	resp, err := h.Get(ctx, pageURL, headers)
+	if err != nil {
+		h.CloseResponse(resp)
+		return nil, nil, err
+	}
+	if !h.IsSuccess(resp) {
+		h.CloseResponse(resp)
+		return nil, nil, &StatusError{StatusCode: resp.StatusCode, Method: http.MethodGet, URL: pageURL}
+	}
+	body, err := h.ReadBody(resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, resp.Header, nil
+}
+
+// nextPageURL works out the URL of the page after current, or "" when pagination is done
+func nextPageURL(current string, body json.RawMessage, header http.Header, opts PageOptions, cursorParam string) (string, error) {
+	switch {
+	case opts.CursorPath != "":
+		cursor, err := stringAtPath(body, opts.CursorPath)
+		if err != nil || cursor == "" {
+			return "", err
+		}
+		return mergeQuery(current, url.Values{cursorParam: {cursor}})
+	case opts.NextURLPath != "":
+		next, err := stringAtPath(body, opts.NextURLPath)
+		if err != nil || next == "" {
+			return "", err
+		}
+		return resolveURL(current, next)
+	}
+
+	if next := ParseLinkHeader(header.Get("Link"))["next"]; next != "" {
+		return resolveURL(current, next)
+	}
+	return "", nil
+}
+
+// ParseLinkHeader parses an RFC 8288 Link header into a map of rel to URL
+// Links with several space-separated relations are recorded under each of them.
+func ParseLinkHeader(header string) map[string]string {
+	links := make(map[string]string)
+	for _, part := range strings.Split(header, ",") {
+		segments := strings.Split(part, ";")
+		target := strings.TrimSpace(segments[0])
+		if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+			continue
+		}
+		target = target[1 : len(target)-1]
+
+		for _, param := range segments[1:] {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(key), "rel") {
+				continue
+			}
+			for _, rel := range strings.Fields(strings.Trim(strings.TrimSpace(value), `"`)) {
+				links[strings.ToLower(rel)] = target
+			}
+		}
+	}
+	return links
+}
+
+// resolveURL resolves ref against base so relative next links work
+func resolveURL(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	r, err := url.Parse(ref)
+	if err != nil {
+		return "", fmt.Errorf("invalid next page URL %q: %w", ref, err)
+	}
+	return b.ResolveReference(r).String(), nil
+}
+
+// jsonAtPath returns the raw JSON value at a dot-separated path, or nil when it is absent or null
+func jsonAtPath(body json.RawMessage, path string) (json.RawMessage, error) {
+	current := body
+	if path != "" {
+		for _, key := range strings.Split(path, ".") {
+			var object map[string]json.RawMessage
+			if err := json.Unmarshal(current, &object); err != nil {
+				return nil, fmt.Errorf("%q is not inside a JSON object: %w", key, err)
+			}
+			value, ok := object[key]
+			if !ok {
+				return nil, nil
+			}
+			current = value
+		}
+	}
+	if bytes.Equal(bytes.TrimSpace(current), []byte("null")) {
+		return nil, nil
+	}
+	return current, nil
+}
+
+// stringAtPath returns the string or number at a dot-separated path, or "" when it is absent or null
+func stringAtPath(body json.RawMessage, path string) (string, error) {
+	raw, err := jsonAtPath(body, path)
+	if err != nil || raw == nil {
+		return "", err
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s, nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String(), nil
+	}
+	return "", fmt.Errorf("value at %q is not a string or number", path)
+}
@@ -3,15 +3,18 @@ package httputil
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"math"
-	"math/rand"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/sirupsen/logrus"
+	"github.com/mustanish/common-utils/v2/contextutil"
+	"github.com/mustanish/common-utils/v2/logutil"
+	"github.com/mustanish/common-utils/v2/retryutil"
 )
 
 // RequestOptions holds options for the HTTP request
@@ -23,66 +26,180 @@ type RequestOptions struct {
 	Body    io.Reader
 	Headers map[string]string
 	Context context.Context
+
+	// Query parameters merged into URL; a key set here replaces the same key already in the URL
+	Query url.Values
+
+	// GetBody, when set, supplies a fresh body for every attempt instead of Body being buffered for retries
+	// It suits large or generated bodies that should be streamed rather than held in memory.
+	GetBody func() (io.ReadCloser, error)
+
+	// Per-request retry overrides; nil or zero values fall back to the client configuration
+	MaxRetries    *int          // a pointer so that 0 can disable retries for one call
+	InitialWait   time.Duration // initial backoff between attempts
+	MaxWait       time.Duration // upper bound on the backoff
+	RetryOnStatus []int         // a non-nil empty slice retries on transport errors only
+
+	// Signer for this call, overriding the client's Signer
+	Signer Signer
+
+	// Status codes the JSON helpers (GetJSON, PostJSON, Do, Resource, Endpoints) accept; nil accepts any 2xx
+	ExpectedStatus []int
+
+	// Called as the body is sent with the bytes sent so far and the total (-1 when unknown); see WithUploadProgress
+	UploadProgress func(sent, total int64)
+
+	// Gzip the body from this many bytes on; 0 uses the client's GzipRequestsAbove and negative disables it
+	GzipAbove int64
+
+	// Proxy URL for this call, or DirectProxy to bypass the client's proxy; empty uses the client setting
+	Proxy string
+
+	// How the request went, filled in for SuccessHook and FailureHook; values set by callers are ignored
+	Stats RequestStats
+
+	// stream marks a body read incrementally (StreamSSE), which must bypass the response cache
+	stream bool
+}
+
+// RequestStats describes the attempts behind a response, e.g. for client-side SLO reporting from hooks
+type RequestStats struct {
+	Attempts         int             // attempts sent, including the first
+	Elapsed          time.Duration   // from the start of the request to its outcome, including backoff waits
+	AttemptDurations []time.Duration // time of each attempt until response headers or a transport error
+	CacheStatus      string          // CacheStatusHeader value when the response came from the cache, else ""
+}
+
+// RequestOption customizes a single call made through the convenience methods
+type RequestOption func(*RequestOptions)
+
+// WithQuery merges the given query parameters into the request URL
+func WithQuery(values url.Values) RequestOption {
+	return func(o *RequestOptions) {
+		if o.Query == nil {
+			o.Query = url.Values{}
+		}
+		for k, vs := range values {
+			o.Query[k] = append([]string(nil), vs...)
+		}
+	}
+}
+
+// WithQueryParam adds a single query parameter to the request URL
+func WithQueryParam(key, value string) RequestOption {
+	return func(o *RequestOptions) {
+		if o.Query == nil {
+			o.Query = url.Values{}
+		}
+		o.Query.Add(key, value)
+	}
+}
+
+// WithMaxRetries overrides the client's MaxRetries for a single call; 0 disables retries
+func WithMaxRetries(n int) RequestOption {
+	return func(o *RequestOptions) {
+		o.MaxRetries = &n
+	}
+}
+
+// WithBackoff overrides the client's InitialWait and MaxWait for a single call
+// A zero value keeps the corresponding client setting.
+func WithBackoff(initialWait, maxWait time.Duration) RequestOption {
+	return func(o *RequestOptions) {
+		o.InitialWait = initialWait
+		o.MaxWait = maxWait
+	}
+}
+
+// WithRetryOnStatus replaces the client's retryable status codes for a single call
+// Calling it without codes retries only on transport errors.
+func WithRetryOnStatus(codes ...int) RequestOption {
+	return func(o *RequestOptions) {
+		o.RetryOnStatus = append([]int{}, codes...)
+	}
+}
+
+// WithExpectedStatus restricts the status codes the JSON helpers treat as success for a single call
+// Any other status, including other 2xx codes, returns a *StatusError.
+func WithExpectedStatus(codes ...int) RequestOption {
+	return func(o *RequestOptions) {
+		o.ExpectedStatus = append([]int{}, codes...)
+	}
+}
+
+// applyOptions applies per-call options on top of the base request options
+func applyOptions(base RequestOptions, opts []RequestOption) RequestOptions {
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&base)
+		}
+	}
+	return base
+}
+
+// Do sends the request described by opts with the same retries, hooks, middleware and caching as Get or Post
+// Use it for methods without a helper, e.g. PROPFIND, REPORT or a custom verb; opts.Method is sent as given.
+// A nil opts.Context means context.Background().
+func (h *HTTPUtil) Do(opts RequestOptions) (*http.Response, error) {
+	return h.doRequest(opts)
 }
 
 // Get sends an HTTP GET request
-func (h *HTTPUtil) Get(ctx context.Context, url string, headers map[string]string) (*http.Response, error) {
-	return h.doRequest(RequestOptions{
+func (h *HTTPUtil) Get(ctx context.Context, url string, headers map[string]string, opts ...RequestOption) (*http.Response, error) {
+	return h.doRequest(applyOptions(RequestOptions{
 		Method:  http.MethodGet,
 		URL:     url,
 		Headers: headers,
 		Context: ctx,
-	})
+	}, opts))
 }
 
 // Post sends an HTTP POST request
-func (h *HTTPUtil) Post(ctx context.Context, url string, body io.Reader, headers map[string]string) (*http.Response, error) {
-	return h.doRequest(RequestOptions{
+func (h *HTTPUtil) Post(ctx context.Context, url string, body io.Reader, headers map[string]string, opts ...RequestOption) (*http.Response, error) {
+	return h.doRequest(applyOptions(RequestOptions{
 		Method:  http.MethodPost,
 		URL:     url,
 		Body:    body,
 		Headers: headers,
 		Context: ctx,
-	})
+	}, opts))
 }
 
 // Put sends an HTTP PUT request
-func (h *HTTPUtil) Put(ctx context.Context, url string, body io.Reader, headers map[string]string) (*http.Response, error) {
-	return h.doRequest(RequestOptions{
+func (h *HTTPUtil) Put(ctx context.Context, url string, body io.Reader, headers map[string]string, opts ...RequestOption) (*http.Response, error) {
+	return h.doRequest(applyOptions(RequestOptions{
 		Method:  http.MethodPut,
 		URL:     url,
 		Body:    body,
 		Headers: headers,
 		Context: ctx,
-	})
+	}, opts))
 }
 
 // Patch sends an HTTP PATCH request
-func (h *HTTPUtil) Patch(ctx context.Context, url string, body io.Reader, headers map[string]string) (*http.Response, error) {
-	return h.doRequest(RequestOptions{
+func (h *HTTPUtil) Patch(ctx context.Context, url string, body io.Reader, headers map[string]string, opts ...RequestOption) (*http.Response, error) {
+	return h.doRequest(applyOptions(RequestOptions{
 		Method:  http.MethodPatch,
 		URL:     url,
 		Body:    body,
 		Headers: headers,
 		Context: ctx,
-	})
+	}, opts))
 }
 
 // Delete sends an HTTP DELETE request
-func (h *HTTPUtil) Delete(ctx context.Context, url string, headers map[string]string) (*http.Response, error) {
-	return h.doRequest(RequestOptions{
+func (h *HTTPUtil) Delete(ctx context.Context, url string, headers map[string]string, opts ...RequestOption) (*http.Response, error) {
+	return h.doRequest(applyOptions(RequestOptions{
 		Method:  http.MethodDelete,
 		URL:     url,
 		Headers: headers,
 		Context: ctx,
-	})
+	}, opts))
 }
 
 // doRequest performs an HTTP request with retry logic
-func (h *HTTPUtil) doRequest(opts RequestOptions) (*http.Response, error) {
-	var err error
+func (h *HTTPUtil) doRequest(opts RequestOptions) (resp *http.Response, err error) {
 	var bodyBytes []byte
-	var resp *http.Response
 
 	if opts.Method == "" {
 		return nil, fmt.Errorf("method cannot be empty")
@@ -90,110 +207,362 @@ func (h *HTTPUtil) doRequest(opts RequestOptions) (*http.Response, error) {
 	if opts.URL == "" {
 		return nil, fmt.Errorf("URL cannot be empty")
 	}
+	if len(opts.Query) > 0 {
+		if opts.URL, err = mergeQuery(opts.URL, opts.Query); err != nil {
+			return nil, err
+		}
+	}
 
-	if opts.Body != nil {
+	if opts.Body != nil && opts.GetBody == nil {
 		bodyBytes, err = io.ReadAll(opts.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read request body: %w", err)
 		}
+		if bodyBytes, err = h.gzipRequestBody(&opts, bodyBytes); err != nil {
+			return nil, err
+		}
 	}
 
 	if opts.Context == nil {
 		opts.Context = context.Background()
 	}
+	if h.tlsErr != nil {
+		return nil, h.tlsErr
+	}
+	if h.proxy != nil {
+		if opts.Context, err = h.proxy.withOverride(opts.Context, opts.Proxy); err != nil {
+			return nil, err
+		}
+	}
+
+	// The request ID is fixed before the span and logger so retries and log lines share it
+	h.requestIDs.bind(&opts)
+
+	// One span covers the logical request; each attempt gets a child span
+	span := h.tracing.startRequest(&opts)
+	if span != nil {
+		defer func() { span.end(resp, err) }()
+	}
+
+	// Attempt timings feed opts.Stats for the hooks
+	started := time.Now()
+	var attemptDurations []time.Duration
+	stats := func() RequestStats {
+		return RequestStats{Attempts: len(attemptDurations), Elapsed: time.Since(started), AttemptDurations: attemptDurations}
+	}
+	opts.Stats = RequestStats{}
+	if failureHook := h.FailureHook; failureHook != nil {
+		defer func() {
+			if err != nil {
+				opts.Stats = stats()
+				failureHook(resp, err, opts)
+			}
+		}()
+	}
+
+	// Request-scoped fields (e.g. a request ID) are picked up from the context
+	logger := h.Logger.WithContext(opts.Context)
 
 	// Log request start
-	h.Logger.WithFields(logrus.Fields{"method": opts.Method, "url": opts.URL, "max_retries": h.MaxRetries}).Debug("Starting HTTP request")
-	currentWait := h.InitialWait
+	policy := h.retryPolicyFor(opts)
+	logger.WithFields(logutil.Fields{"method": opts.Method, "url": opts.URL, "max_retries": policy.maxRetries}).Debug("Starting HTTP request")
 
-	// Execute request with retries
-	for attempt := 0; attempt <= h.MaxRetries; attempt++ {
+	if cached, ok := h.cache.fresh(opts); ok {
+		logger.WithFields(logutil.Fields{"method": opts.Method, "url": opts.URL, "age": cached.Header.Get("Age")}).Debug("Serving response from cache")
+		opts.Stats = stats()
+		opts.Stats.CacheStatus = CacheStatusHit
+		h.SuccessHook(cached, opts)
+		return cached, nil
+	}
+	// A stored response past its TTL is revalidated with its ETag/Last-Modified when enabled
+	revalidating := h.cache.conditional(opts)
+
+	// The last attempt's outcome is tracked for hooks, logging and RetryExhaustedError
+	var lastResp *http.Response
+	var lastErr error
+	attemptNum := -1
+
+	attempt := func(ctx context.Context) (*http.Response, error) {
+		// A new attempt supersedes the previous response, so release its connection
+		h.CloseResponse(lastResp)
+		lastResp, lastErr = nil, nil
+		attemptNum++
+		ctx = context.WithValue(ctx, attemptKey{}, attemptNum)
+
+		if h.RateLimiter != nil {
+			if err := h.RateLimiter.Wait(ctx); err != nil {
+				return nil, retryutil.Permanent(fmt.Errorf("rate limiter wait failed: %w", err))
+			}
+		}
 
 		var bodyReader io.Reader
 		if bodyBytes != nil {
 			bodyReader = bytes.NewReader(bodyBytes)
 		}
+		if opts.GetBody != nil {
+			body, err := opts.GetBody()
+			if err != nil {
+				return nil, retryutil.Permanent(fmt.Errorf("failed to open request body: %w", err))
+			}
+			bodyReader = body
+		}
 
-		req, err := http.NewRequestWithContext(opts.Context, opts.Method, opts.URL, bodyReader)
+		req, err := http.NewRequestWithContext(ctx, opts.Method, opts.URL, bodyReader)
 		if err != nil {
-			h.Logger.WithFields(logrus.Fields{"error": err, "method": opts.Method, "url": opts.URL, "attempt": attempt + 1}).Error("Failed to create request")
-			return nil, fmt.Errorf("failed to create request: %w", err)
+			if body, ok := bodyReader.(io.Closer); ok {
+				_ = body.Close()
+			}
+			logger.WithFields(logutil.Fields{"error": err, "method": opts.Method, "url": opts.URL}).Error("Failed to create request")
+			return nil, retryutil.Permanent(fmt.Errorf("failed to create request: %w", err))
 		}
 
+		withUploadProgress(req, attemptNum, opts.UploadProgress)
+
 		for k, v := range opts.Headers {
 			req.Header.Set(k, v)
 		}
-
-		resp, err = h.Client.Do(req)
-		if err == nil && !h.shouldRetry(resp, err) {
-			h.SuccessHook(resp, opts)
-			return resp, nil
+		h.headers.apply(req)
+		if revalidating != nil {
+			revalidating.setConditional(req)
 		}
+		// Propagate the correlation ID unless the header was set explicitly
+		h.requestIDs.apply(req)
 
-		if attempt >= h.MaxRetries {
-			break
+		if err := h.hostRate.wait(ctx, req.URL.Host); err != nil {
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			return nil, retryutil.Permanent(fmt.Errorf("host rate limiter wait for %s failed: %w", req.URL.Host, err))
 		}
 
-		h.RetryHook(attempt, resp, err)
-
-		// Calculate wait time with exponential backoff and jitter
-		jitter := time.Duration(rand.Float64() * float64(currentWait) * 0.1)
-		waitTime := currentWait + jitter
-
-		if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
-			rateLimitWait := 60 * time.Second
+		release, err := h.hostSlots.acquire(ctx, req.URL.Host)
+		if err != nil {
+			if req.Body != nil {
+				_ = req.Body.Close()
+			}
+			return nil, retryutil.Permanent(fmt.Errorf("waiting for a connection slot to %s failed: %w", req.URL.Host, err))
+		}
 
-			h.Logger.WithFields(logrus.Fields{"status": resp.StatusCode, "url": opts.URL}).Warn("Received 429 Too Many Requests")
-			if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
-				if seconds, parseErr := strconv.Atoi(retryAfter); parseErr == nil {
-					rateLimitWait = time.Duration(seconds) * time.Second
+		if signer := h.signerFor(opts); signer != nil {
+			if err := signer.Sign(req); err != nil {
+				release()
+				if req.Body != nil {
+					_ = req.Body.Close()
 				}
+				return nil, retryutil.Permanent(fmt.Errorf("failed to sign request: %w", err))
 			}
+		}
 
-			h.Logger.WithFields(logrus.Fields{"wait_time": rateLimitWait}).Info("Respecting Retry-After header wait time")
-			if rateLimitWait > waitTime {
-				waitTime = rateLimitWait
+		req, attemptSpan := h.tracing.startAttempt(req, attemptNum)
+		attemptStart := time.Now()
+		lastResp, lastErr = h.roundTrip(req)
+		attemptDurations = append(attemptDurations, time.Since(attemptStart))
+		endAttempt(attemptSpan, lastResp, lastErr)
+		if lastErr != nil {
+			release()
+			if !h.retries(policy, nil, lastErr) {
+				return nil, retryutil.Permanent(lastErr)
+			}
+			return nil, lastErr
+		}
+		if lastResp == nil {
+			release()
+			return nil, retryutil.Permanent(errors.New("middleware returned neither a response nor an error"))
+		}
+		h.reportRateLimit(req.URL.Host, lastResp)
+		if h.hostSlots != nil {
+			lastResp.Body = &releaseOnClose{ReadCloser: lastResp.Body, release: release}
+		}
+		if h.retries(policy, lastResp, nil) {
+			statusErr := h.retryableStatus(lastResp, opts)
+			if lastResp.StatusCode == http.StatusTooManyRequests {
+				h.hostRate.pause(req.URL.Host, statusErr.retryAfter)
 			}
+			return lastResp, statusErr
 		}
+		return lastResp, nil
+	}
+
+	retryOpts := retryutil.Options{
+		MaxRetries:         policy.maxRetries,
+		InitialWait:        policy.initialWait,
+		MaxWait:            policy.maxWait,
+		Multiplier:         1.5,
+		Jitter:             0.1,
+		StopBeforeDeadline: true,
+		OnRetry: func(attempt int, _ error, wait time.Duration) {
+			h.RetryHook(attempt, lastResp, lastErr)
+			span.retry(attempt, wait, lastResp, lastErr)
+			h.decideRetry(opts.Context, attempt, lastResp, lastErr, wait, true)
+			logger.WithFields(logutil.Fields{"wait_time": wait}).Info("Waiting before next retry")
+		},
+		OnDeadlineStop: func(attempt int, _ error, wait, remaining time.Duration) {
+			h.decideRetry(opts.Context, attempt, lastResp, lastErr, wait, false)
+			span.deadlineStop(attempt, wait, remaining)
+			logger.WithFields(logutil.Fields{"wait_time": wait, "remaining": remaining}).Warn("Next retry would exceed the context deadline, giving up")
+		},
+	}
 
-		h.Logger.WithFields(logrus.Fields{"wait_time": waitTime}).Info("Waiting before next retry")
-		select {
-		case <-opts.Context.Done():
-			h.Logger.WithError(opts.Context.Err()).Warn("Request cancelled during retry wait")
-			return nil, fmt.Errorf("context cancelled during retry: %w", opts.Context.Err())
-		case <-time.After(waitTime):
-			currentWait = time.Duration(math.Min(float64(currentWait)*1.5, float64(h.MaxWait)))
+	resp, err = retryutil.RetryWithResult(opts.Context, attempt, retryOpts)
+	if err == nil && revalidating != nil && resp.StatusCode == http.StatusNotModified {
+		var cacheErr error
+		if resp, cacheErr = h.cache.revalidated(opts, revalidating, resp); cacheErr != nil {
+			logger.WithError(cacheErr).Warn("Failed to refresh cached response")
+		}
+		logger.WithFields(logutil.Fields{"method": opts.Method, "url": opts.URL}).Debug("Serving revalidated response from cache")
+		opts.Stats = stats()
+		opts.Stats.CacheStatus = CacheStatusRevalidated
+		h.SuccessHook(resp, opts)
+		return resp, nil
+	}
+	if err == nil {
+		if cacheErr := h.cache.save(opts, resp); cacheErr != nil {
+			logger.WithError(cacheErr).Warn("Failed to cache response")
 		}
+		opts.Stats = stats()
+		h.SuccessHook(resp, opts)
+		return resp, nil
 	}
 
-	h.Logger.WithFields(logrus.Fields{
-		"method":  opts.Method,
-		"url":     opts.URL,
-		"retries": h.MaxRetries,
-		"error":   err,
-		"status": func() int {
-			if resp != nil {
-				return resp.StatusCode
+	var exhausted *retryutil.ExhaustedError
+
+	// stale-if-error: a failed GET falls back to a stored response unless the caller gave up
+	// The caller sees no error, so the failure is still reported to FailureHook.
+	if opts.Context.Err() == nil {
+		if stale, ok := h.cache.stale(opts); ok {
+			logger.WithFields(logutil.Fields{"error": err, "age": stale.Header.Get("Age")}).Warn("Request failed, serving stale cached response")
+			if h.FailureHook != nil {
+				reported := err
+				if errors.As(err, &exhausted) {
+					reported = h.exhaustedError(opts, exhausted, lastResp, lastErr)
+				}
+				opts.Stats = stats()
+				opts.Stats.CacheStatus = CacheStatusStale
+				h.FailureHook(lastResp, reported, opts)
 			}
-			return 0
-		}(),
+			h.CloseResponse(lastResp)
+			return stale, nil
+		}
+	}
+
+	if !errors.As(err, &exhausted) {
+		if opts.Context.Err() != nil {
+			logger.WithError(opts.Context.Err()).Warn("Request cancelled during retry wait")
+			h.CloseResponse(lastResp)
+			return nil, err
+		}
+		return resp, err
+	}
+
+	logger.WithFields(logutil.Fields{
+		"method":   opts.Method,
+		"url":      opts.URL,
+		"retries":  policy.maxRetries,
+		"attempts": exhausted.Attempts,
+		"error":    lastErr,
+		"status":   statusOf(lastResp),
 	}).Error("Request failed after all retries")
 
-	return resp, &RetryExhaustedError{
-		URL:      opts.URL,
-		Method:   opts.Method,
-		Attempts: h.MaxRetries + 1,
-		LastStatus: func() int {
-			if resp != nil {
-				return resp.StatusCode
-			}
-			return 0
-		}(),
+	return lastResp, h.exhaustedError(opts, exhausted, lastResp, lastErr)
+}
+
+// exhaustedError describes a request that failed after its last attempt
+func (h *HTTPUtil) exhaustedError(opts RequestOptions, exhausted *retryutil.ExhaustedError, lastResp *http.Response, lastErr error) *RetryExhaustedError {
+	return &RetryExhaustedError{
+		URL:               opts.URL,
+		Method:            opts.Method,
+		RequestID:         contextutil.RequestIDFrom(opts.Context),
+		Attempts:          exhausted.Attempts,
+		StoppedByDeadline: exhausted.StoppedByDeadline,
+		LastStatus:        statusOf(lastResp),
 		LastError: func() error {
-			if err != nil {
-				return err
+			if lastErr != nil {
+				return lastErr
 			}
-			return fmt.Errorf("unknown error after %d attempts", h.MaxRetries+1)
+			return fmt.Errorf("unknown error after %d attempts", exhausted.Attempts)
 		}(),
 	}
 }
+
+// signerFor returns the signer for a request: the per-call override, else the client's Signer
+func (h *HTTPUtil) signerFor(opts RequestOptions) Signer {
+	if opts.Signer != nil {
+		return opts.Signer
+	}
+	return h.Signer
+}
+
+// decideRetry reports a retry decision to RetryDecisionHook, adding the deadline budget of ctx
+func (h *HTTPUtil) decideRetry(ctx context.Context, attempt int, resp *http.Response, err error, wait time.Duration, retry bool) {
+	if h.RetryDecisionHook == nil {
+		return
+	}
+	decision := RetryDecision{Attempt: attempt, Response: resp, Err: err, Wait: wait, Retry: retry}
+	if deadline, ok := ctx.Deadline(); ok {
+		decision.HasDeadline, decision.Remaining = true, time.Until(deadline)
+	}
+	h.RetryDecisionHook(decision)
+}
+
+// retryableStatus builds the error reported to the retry loop for a retryable status code
+// For 429 responses it honours the Retry-After header (seconds or HTTP-date), defaulting to 60 seconds.
+func (h *HTTPUtil) retryableStatus(resp *http.Response, opts RequestOptions) *retryableStatusError {
+	statusErr := &retryableStatusError{status: resp.StatusCode}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return statusErr
+	}
+
+	statusErr.retryAfter = 60 * time.Second
+	logger := h.Logger.WithContext(opts.Context)
+	logger.WithFields(logutil.Fields{"status": resp.StatusCode, "url": opts.URL}).Warn("Received 429 Too Many Requests")
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if wait, ok := parseRetryAfter(retryAfter, time.Now()); ok {
+			statusErr.retryAfter = wait
+		} else {
+			logger.WithFields(logutil.Fields{"retry_after": retryAfter}).Warn("Ignoring unparsable Retry-After header")
+		}
+	}
+	logger.WithFields(logutil.Fields{"wait_time": statusErr.retryAfter}).Info("Respecting Retry-After header wait time")
+	return statusErr
+}
+
+// parseRetryAfter parses a Retry-After value given as delay-seconds or as an HTTP-date (RFC 7231 7.1.3)
+// A date in the past yields a zero wait, so the regular backoff applies.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// mergeQuery adds query to rawURL, replacing parameters that are already present
+func mergeQuery(rawURL string, query url.Values) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	q := u.Query()
+	for k, vs := range query {
+		q[k] = vs
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// statusOf returns the response status code, or 0 when there is no response
+func statusOf(resp *http.Response) int {
+	if resp != nil {
+		return resp.StatusCode
+	}
+	return 0
+}
@@ -0,0 +1,70 @@
+package httputil
+
+import (
+	"net/http"
+
+	"github.com/mustanish/common-utils/v2/contextutil"
+)
+
+// requestIDs chooses the correlation ID of every logical request and the header that carries it
+type requestIDs struct {
+	header   string
+	generate func() string // nil unless HTTPConfig.GenerateRequestID is set
+}
+
+// newRequestIDs applies the defaults for the request ID header and generator
+func newRequestIDs(header string, generate bool, generator func() string) requestIDs {
+	if header == "" {
+		header = contextutil.RequestIDHeader
+	}
+	ids := requestIDs{header: http.CanonicalHeaderKey(header)}
+	if generate {
+		ids.generate = generator
+		if ids.generate == nil {
+			ids.generate = contextutil.NewRequestID
+		}
+	}
+	return ids
+}
+
+// bind stores the request ID in opts.Context once per logical request, so every attempt sends the same ID
+// and every log line carries it. An ID already in the context wins, then one set in the call's headers,
+// then a generated one.
+func (r requestIDs) bind(opts *RequestOptions) {
+	if contextutil.RequestIDFrom(opts.Context) != "" {
+		return
+	}
+	var id string
+	for k, v := range opts.Headers {
+		if r.header != "" && http.CanonicalHeaderKey(k) == r.header {
+			id = v
+			break
+		}
+	}
+	if id == "" && r.generate != nil {
+		id = r.generate()
+	}
+	if id != "" {
+		opts.Context = contextutil.WithRequestID(opts.Context, id)
+	}
+}
+
+// apply sets the request ID header on an attempt unless the call or a default header already set it
+func (r requestIDs) apply(req *http.Request) {
+	header := r.header
+	if header == "" {
+		header = contextutil.RequestIDHeader // HTTPUtil built without NewHTTPUtil
+	}
+	if id := contextutil.RequestIDFrom(req.Context()); id != "" && req.Header.Get(header) == "" {
+		req.Header.Set(header, id)
+	}
+}
+
+// RequestID returns the request ID sent with the request that produced resp, or "" if it had none
+// It works for responses served from the cache too, and for the last response of a failed request.
+func RequestID(resp *http.Response) string {
+	if resp == nil || resp.Request == nil {
+		return ""
+	}
+	return contextutil.RequestIDFrom(resp.Request.Context())
+}
@@ -0,0 +1,57 @@
+package httputil
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Resource issues JSON CRUD calls against a REST collection such as "https://api.example.com/users"
+// Items are addressed as "<base>/<id>" with id path-escaped. Every call returns a *StatusError for
+// non-2xx responses and decodes 2xx bodies into out unless out is nil or the body is empty.
+type Resource struct {
+	client  *HTTPUtil
+	baseURL string
+
+	// Headers sent with every call, e.g. Authorization
+	Headers map[string]string
+}
+
+// Resource returns a CRUD helper for the collection at baseURL
+func (h *HTTPUtil) Resource(baseURL string) *Resource {
+	return &Resource{client: h, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// URL returns the URL of the item with the given id, or of the collection when id is empty
+func (r *Resource) URL(id string) string {
+	if id == "" {
+		return r.baseURL
+	}
+	return r.baseURL + "/" + url.PathEscape(id)
+}
+
+// Get fetches a single item into out
+func (r *Resource) Get(ctx context.Context, id string, out any, opts ...RequestOption) error {
+	return r.client.doJSON(ctx, http.MethodGet, r.URL(id), r.Headers, nil, out, opts)
+}
+
+// List fetches the collection, filtered by query, into out
+func (r *Resource) List(ctx context.Context, query url.Values, out any, opts ...RequestOption) error {
+	return r.client.doJSON(ctx, http.MethodGet, r.URL(""), r.Headers, nil, out, append([]RequestOption{WithQuery(query)}, opts...))
+}
+
+// Create posts in to the collection and decodes the created item into out
+func (r *Resource) Create(ctx context.Context, in, out any, opts ...RequestOption) error {
+	return r.client.doJSON(ctx, http.MethodPost, r.URL(""), r.Headers, in, out, opts)
+}
+
+// Update replaces the item with the given id using PUT and decodes the result into out
+func (r *Resource) Update(ctx context.Context, id string, in, out any, opts ...RequestOption) error {
+	return r.client.doJSON(ctx, http.MethodPut, r.URL(id), r.Headers, in, out, opts)
+}
+
+// Delete removes the item with the given id
+func (r *Resource) Delete(ctx context.Context, id string, opts ...RequestOption) error {
+	return r.client.doJSON(ctx, http.MethodDelete, r.URL(id), r.Headers, nil, nil, opts)
+}
@@ -1,21 +1,90 @@
 package httputil
 
 import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strings"
 )
 
 // ReadBody reads and returns the response body as bytes.
+// Compressed bodies are decompressed transparently, see decodedBody.
 func (h *HTTPUtil) ReadBody(resp *http.Response) ([]byte, error) {
 	defer resp.Body.Close()
-	return io.ReadAll(resp.Body)
+	body, err := h.decodedBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(body)
 }
 
 // DecodeJSON decodes the response body into the provided struct.
+// Compressed bodies are decompressed transparently, see decodedBody.
 func (h *HTTPUtil) DecodeJSON(resp *http.Response, v any) error {
 	defer resp.Body.Close()
-	return json.NewDecoder(resp.Body).Decode(v)
+	body, err := h.decodedBody(resp)
+	if err != nil {
+		return err
+	}
+	return json.NewDecoder(body).Decode(v)
+}
+
+// decodedBody returns a reader over the decompressed response body
+// Bodies the transport already decompressed are returned as is. Otherwise gzip and deflate are
+// decoded according to Content-Encoding, which matters when DisableCompression is set. Unless
+// DisableBodySniffing is set the gzip magic bytes are checked as well, so a body labelled gzip
+// that is actually plain is passed through and an unlabelled gzip body is still decompressed.
+// Other encodings (e.g. br) are returned undecoded.
+func (h *HTTPUtil) decodedBody(resp *http.Response) (io.Reader, error) {
+	if resp.Uncompressed {
+		return resp.Body, nil
+	}
+
+	br := bufio.NewReader(resp.Body)
+	magic, _ := br.Peek(2)
+	if len(magic) == 0 {
+		return br, nil
+	}
+	isGzip := len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b
+	sniff := !h.DisableBodySniffing
+
+	var reader io.Reader
+	var err error
+	switch strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))) {
+	case "gzip", "x-gzip":
+		if isGzip || !sniff {
+			reader, err = gzip.NewReader(br)
+		} else {
+			reader = br
+		}
+	case "deflate":
+		// HTTP deflate is zlib-wrapped, but some servers send raw deflate streams
+		if isZlibHeader(magic) {
+			reader, err = zlib.NewReader(br)
+		} else {
+			reader = flate.NewReader(br)
+		}
+	default:
+		if isGzip && sniff {
+			reader, err = gzip.NewReader(br)
+		} else {
+			reader = br
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress response body: %w", err)
+	}
+	return reader, nil
+}
+
+// isZlibHeader reports whether b starts with a zlib header (deflate method and a valid check value)
+func isZlibHeader(b []byte) bool {
+	return len(b) == 2 && b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
 }
 
 // IsSuccess returns true if the response status code is 2xx.
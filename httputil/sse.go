@@ -0,0 +1,273 @@
+package httputil
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/logutil"
+)
+
+// ErrStopStream ends StreamSSE without an error when returned by the event handler
+var ErrStopStream = errors.New("stop stream")
+
+// maxSSELineBytes bounds a single line of an event stream, so a misbehaving server cannot exhaust memory
+const maxSSELineBytes = 1 << 20
+
+// Event is one message of a text/event-stream
+type Event struct {
+	ID    string        // last event ID seen on the stream, sent back as Last-Event-ID on reconnection
+	Type  string        // event field, "message" when the server did not name it
+	Data  string        // data lines joined with "\n"
+	Retry time.Duration // reconnection delay requested with this event, 0 when not set
+}
+
+// StreamSSE connects to a Server-Sent Events endpoint and calls handler for every event until ctx is done
+// The connection goes through the regular request path, so retries, hooks, middleware and the transport
+// configuration apply to every (re)connection. When an established stream drops, it reconnects after the
+// server's retry delay (default InitialWait, doubling up to MaxWait while reconnections yield no events)
+// and sends Last-Event-ID so the server can resume. Comment lines count as heartbeats; with
+// HTTPConfig.StreamIdleTimeout set, a stream silent for that long is reconnected. ClientTimeout bounds a
+// single connection, so long-lived streams also reconnect when it expires.
+//
+// StreamSSE returns nil when handler returns ErrStopStream or the server answers 204 No Content, ctx's
+// error when ctx is done, and the error of handler, a failed connection or a non-2xx status otherwise.
+func (h *HTTPUtil) StreamSSE(ctx context.Context, url string, headers map[string]string, handler func(event Event) error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger := h.Logger.WithContext(ctx)
+	stream := &sseStream{handler: handler}
+	failures := 0 // reconnections in a row that yielded no events
+	for {
+		received, err := h.streamOnce(ctx, url, headers, stream)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var disconnect *sseDisconnect
+		if !errors.As(err, &disconnect) {
+			if errors.Is(err, ErrStopStream) {
+				return nil
+			}
+			return err
+		}
+
+		// Back off while reconnections yield nothing; the server's retry field takes precedence
+		if received {
+			failures = 0
+		}
+		delay := stream.retry
+		if delay <= 0 {
+			delay = sseBackoff(h.InitialWait, h.MaxWait, failures)
+		}
+		failures++
+		logger.WithFields(logutil.Fields{"url": url, "error": disconnect.err, "wait": delay, "last_event_id": stream.lastID}).Warn("Event stream disconnected, reconnecting")
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// sseBackoff doubles initial for every failure, capped at maxWait
+func sseBackoff(initial, maxWait time.Duration, failures int) time.Duration {
+	if initial <= 0 {
+		initial = time.Second
+	}
+	delay := initial
+	for i := 0; i < failures && (maxWait <= 0 || delay < maxWait); i++ {
+		delay *= 2
+	}
+	if maxWait > 0 && delay > maxWait {
+		delay = maxWait
+	}
+	return delay
+}
+
+// sseDisconnect marks a dropped stream that StreamSSE reconnects
+type sseDisconnect struct {
+	err error
+}
+
+// Error implements the error interface for sseDisconnect
+func (e *sseDisconnect) Error() string {
+	return fmt.Sprintf("event stream disconnected: %v", e.err)
+}
+
+// streamOnce opens one connection and dispatches its events, reporting whether any event was received
+func (h *HTTPUtil) streamOnce(ctx context.Context, url string, headers map[string]string, stream *sseStream) (bool, error) {
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream.discard() // an event cut off by the previous disconnect is dropped
+
+	reqHeaders := map[string]string{"Accept": "text/event-stream", "Cache-Control": "no-cache"}
+	for k, v := range headers {
+		reqHeaders[k] = v
+	}
+	if stream.lastID != "" {
+		reqHeaders["Last-Event-ID"] = stream.lastID
+	}
+	resp, err := h.doRequest(RequestOptions{Method: http.MethodGet, URL: url, Headers: reqHeaders, Context: connCtx, stream: true})
+	if err != nil {
+		h.CloseResponse(resp)
+		return false, err
+	}
+	defer h.CloseResponse(resp)
+
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return false, ErrStopStream
+	case !h.IsSuccess(resp):
+		return false, &StatusError{StatusCode: resp.StatusCode, Method: http.MethodGet, URL: url}
+	}
+	if mediaType, _, _ := strings.Cut(resp.Header.Get("Content-Type"), ";"); !strings.EqualFold(strings.TrimSpace(mediaType), "text/event-stream") {
+		return false, fmt.Errorf("unexpected content type %q for event stream %s", resp.Header.Get("Content-Type"), url)
+	}
+
+	// The idle timer cancels the connection when neither events nor heartbeats arrive
+	idle := h.StreamIdleTimeout
+	var idleTimer *time.Timer
+	if idle > 0 {
+		idleTimer = time.AfterFunc(idle, cancel)
+		defer idleTimer.Stop()
+	}
+
+	body, err := h.decodedBody(resp)
+	if err != nil {
+		return false, &sseDisconnect{err: err}
+	}
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 4096), maxSSELineBytes)
+	scanner.Split(scanSSELines)
+
+	received := false
+	first := true
+	for scanner.Scan() {
+		if idleTimer != nil {
+			idleTimer.Reset(idle)
+		}
+		line := scanner.Text()
+		if first {
+			line = strings.TrimPrefix(line, "\ufeff")
+			first = false
+		}
+		event, ok := stream.line(line)
+		if !ok {
+			continue
+		}
+		received = true
+		if err := stream.handler(event); err != nil {
+			return true, err
+		}
+	}
+	err = scanner.Err()
+	if err == nil {
+		err = io.EOF
+	}
+	if errors.Is(err, bufio.ErrTooLong) {
+		return received, fmt.Errorf("event stream line exceeds %d bytes: %w", maxSSELineBytes, err)
+	}
+	return received, &sseDisconnect{err: err}
+}
+
+// sseStream holds the parser state that survives reconnections
+type sseStream struct {
+	handler func(event Event) error
+
+	lastID string
+	retry  time.Duration
+
+	eventType  string
+	data       strings.Builder
+	hasData    bool
+	eventRetry time.Duration
+}
+
+// line processes one line of the stream and returns an event when a blank line completes one
+func (s *sseStream) line(line string) (Event, bool) {
+	if line == "" {
+		return s.dispatch()
+	}
+	if strings.HasPrefix(line, ":") {
+		return Event{}, false // comment, used as a heartbeat
+	}
+	field, value, _ := strings.Cut(line, ":")
+	value = strings.TrimPrefix(value, " ")
+	switch field {
+	case "event":
+		s.eventType = value
+	case "data":
+		s.data.WriteString(value)
+		s.data.WriteByte('\n')
+		s.hasData = true
+	case "id":
+		if !strings.Contains(value, "\x00") {
+			s.lastID = value
+		}
+	case "retry":
+		if ms, err := strconv.ParseUint(value, 10, 63); err == nil {
+			s.retry = time.Duration(ms) * time.Millisecond
+			s.eventRetry = s.retry
+		}
+	}
+	return Event{}, false
+}
+
+// dispatch completes the pending event; blocks without data lines only reset the event type
+func (s *sseStream) dispatch() (Event, bool) {
+	defer s.discard()
+	if !s.hasData {
+		return Event{}, false
+	}
+	event := Event{ID: s.lastID, Type: s.eventType, Data: strings.TrimSuffix(s.data.String(), "\n"), Retry: s.eventRetry}
+	if event.Type == "" {
+		event.Type = "message"
+	}
+	return event, true
+}
+
+// discard drops the fields of an incomplete event
+func (s *sseStream) discard() {
+	s.eventType = ""
+	s.data.Reset()
+	s.hasData = false
+	s.eventRetry = 0
+}
+
+// scanSSELines splits an event stream into lines ending in "\r\n", "\n" or "\r"
+func scanSSELines(data []byte, atEOF bool) (int, []byte, error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		return 0, nil, nil // a "\r" at the end of the buffer may be followed by "\n"
+	}
+	if atEOF {
+		// An unterminated final line is an incomplete event and is discarded
+		return len(data), nil, nil
+	}
+	return 0, nil, nil
+}
@@ -0,0 +1,123 @@
+package httputil
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/logutil"
+)
+
+// Timings is the latency breakdown of a single attempt, collected when timings are enabled
+// Phases that did not happen (e.g. DNS and Connect on a reused connection) are zero. After a
+// redirect the phases describe the last request in the chain.
+type Timings struct {
+	DNS        time.Duration // DNS lookup
+	Connect    time.Duration // TCP connect
+	TLS        time.Duration // TLS handshake
+	TTFB       time.Duration // from sending the request to the first response byte
+	Total      time.Duration // from sending the request to the response headers being returned
+	ConnReused bool          // whether an idle pooled connection was used
+}
+
+// timingsKey is the context key under which the collector is stored on the request
+type timingsKey struct{}
+
+// timingCollector records httptrace events; callbacks may run on transport goroutines
+type timingCollector struct {
+	mu                                   sync.Mutex
+	start, dnsStart, connStart, tlsStart time.Time
+	timings                              Timings
+}
+
+// GetTimings returns the timing breakdown of the attempt that produced resp
+// It reports false when timings were not enabled for the client or resp did not come from it.
+func GetTimings(resp *http.Response) (Timings, bool) {
+	if resp == nil || resp.Request == nil {
+		return Timings{}, false
+	}
+	c, ok := resp.Request.Context().Value(timingsKey{}).(*timingCollector)
+	if !ok {
+		return Timings{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.timings, true
+}
+
+// addTimingFields adds the timing breakdown of resp to log fields when it was collected
+func addTimingFields(fields logutil.Fields, resp *http.Response) {
+	t, ok := GetTimings(resp)
+	if !ok {
+		return
+	}
+	fields["dns"] = t.DNS
+	fields["connect"] = t.Connect
+	fields["tls"] = t.TLS
+	fields["ttfb"] = t.TTFB
+	fields["total"] = t.Total
+	fields["conn_reused"] = t.ConnReused
+}
+
+// timedDo wraps do so every request is traced with httptrace
+func timedDo(do RoundTripFunc) RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		c := &timingCollector{start: time.Now()}
+		ctx := httptrace.WithClientTrace(req.Context(), c.trace())
+		req = req.WithContext(context.WithValue(ctx, timingsKey{}, c))
+
+		resp, err := do(req)
+
+		c.mu.Lock()
+		c.timings.Total = time.Since(c.start)
+		c.mu.Unlock()
+		return resp, err
+	}
+}
+
+// trace returns the httptrace hooks that fill in the collector
+func (c *timingCollector) trace() *httptrace.ClientTrace {
+	record := func(fn func()) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		fn()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			record(func() { c.dnsStart = time.Now() })
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			record(func() { c.timings.DNS = time.Since(c.dnsStart) })
+		},
+		ConnectStart: func(string, string) {
+			// Dual-stack dialing may start several connects; time from the first
+			record(func() {
+				if c.connStart.IsZero() {
+					c.connStart = time.Now()
+				}
+			})
+		},
+		ConnectDone: func(_, _ string, err error) {
+			record(func() {
+				if err == nil && c.timings.Connect == 0 {
+					c.timings.Connect = time.Since(c.connStart)
+				}
+			})
+		},
+		TLSHandshakeStart: func() {
+			record(func() { c.tlsStart = time.Now() })
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			record(func() { c.timings.TLS = time.Since(c.tlsStart) })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			record(func() { c.timings.ConnReused = info.Reused })
+		},
+		GotFirstResponseByte: func() {
+			record(func() { c.timings.TTFB = time.Since(c.start) })
+		},
+	}
+}
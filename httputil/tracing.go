@@ -0,0 +1,164 @@
+package httputil
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans created by this package
+const tracerName = "github.com/mustanish/common-utils/v2/httputil"
+
+// tracing creates the spans configured by HTTPConfig.TracerProvider; a nil *tracing disables them
+type tracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// newTracing returns nil when no TracerProvider is configured, so untraced clients skip all span work
+func newTracing(provider trace.TracerProvider, propagator propagation.TextMapPropagator) *tracing {
+	if provider == nil {
+		return nil
+	}
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+	return &tracing{tracer: provider.Tracer(tracerName), propagator: propagator}
+}
+
+// requestSpan is the span of one logical request, covering every attempt and backoff; nil when untraced
+type requestSpan struct {
+	span trace.Span
+}
+
+// startRequest starts the logical request span and stores it in opts.Context as the parent of the attempts
+func (t *tracing) startRequest(opts *RequestOptions) *requestSpan {
+	if t == nil {
+		return nil
+	}
+	name := opts.Method
+	if endpoint := EndpointName(opts.Context); endpoint != "" {
+		name += " " + endpoint
+	}
+	var span trace.Span
+	opts.Context, span = t.tracer.Start(opts.Context, name,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(requestAttributes(opts.Method, opts.URL)...))
+	return &requestSpan{span: span}
+}
+
+// retry records a scheduled retry as a span event
+func (s *requestSpan) retry(attempt int, wait time.Duration, resp *http.Response, err error) {
+	if s == nil {
+		return
+	}
+	attrs := []attribute.KeyValue{
+		attribute.Int("http.request.resend_count", attempt+1),
+		attribute.Int64("retry.wait_ms", wait.Milliseconds()),
+	}
+	s.span.AddEvent("retry", trace.WithAttributes(append(attrs, outcomeAttributes(resp, err)...)...))
+}
+
+// deadlineStop records that retrying stopped because the backoff would outlast the context deadline
+func (s *requestSpan) deadlineStop(attempt int, wait, remaining time.Duration) {
+	if s == nil {
+		return
+	}
+	s.span.AddEvent("retry abandoned before deadline", trace.WithAttributes(
+		attribute.Int("http.request.resend_count", attempt+1),
+		attribute.Int64("retry.wait_ms", wait.Milliseconds()),
+		attribute.Int64("deadline.remaining_ms", remaining.Milliseconds()),
+	))
+}
+
+// end finishes the span with the final outcome of the request
+func (s *requestSpan) end(resp *http.Response, err error) {
+	if s == nil {
+		return
+	}
+	if resp != nil {
+		s.span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+		if status := resp.Header.Get(CacheStatusHeader); status != "" {
+			s.span.SetAttributes(attribute.String("http.cache_status", status))
+		}
+	}
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
+
+// startAttempt starts a client span for one attempt and injects its trace context into the request headers
+func (t *tracing) startAttempt(req *http.Request, attempt int) (*http.Request, trace.Span) {
+	if t == nil {
+		return req, nil
+	}
+	attrs := requestAttributes(req.Method, req.URL.String())
+	if attempt > 0 {
+		attrs = append(attrs, attribute.Int("http.request.resend_count", attempt))
+	}
+	ctx, span := t.tracer.Start(req.Context(), req.Method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	req = req.WithContext(ctx)
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return req, span
+}
+
+// endAttempt finishes an attempt span; 4xx and 5xx responses mark it as failed, as for any HTTP client span
+func endAttempt(span trace.Span, resp *http.Response, err error) {
+	if span == nil {
+		return
+	}
+	span.SetAttributes(outcomeAttributes(resp, err)...)
+	switch {
+	case err != nil:
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	case resp != nil && resp.StatusCode >= 400:
+		span.SetStatus(codes.Error, "")
+	}
+	span.End()
+}
+
+// requestAttributes describes the request target; credentials in the URL are dropped
+func requestAttributes(method, rawURL string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{attribute.String("http.request.method", method)}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return attrs
+	}
+	u.User = nil
+	attrs = append(attrs, attribute.String("url.full", u.String()), attribute.String("server.address", u.Hostname()))
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	if n, err := strconv.Atoi(port); err == nil {
+		attrs = append(attrs, attribute.Int("server.port", n))
+	}
+	return attrs
+}
+
+// outcomeAttributes describes an attempt's response status or error type
+func outcomeAttributes(resp *http.Response, err error) []attribute.KeyValue {
+	if err != nil {
+		errType := "error"
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			errType = "timeout"
+		}
+		return []attribute.KeyValue{attribute.String("error.type", errType)}
+	}
+	if resp != nil {
+		return []attribute.KeyValue{attribute.Int("http.response.status_code", resp.StatusCode)}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package httputil
+
+import (
+	"io"
+	"net/http"
+)
+
+// WithUploadProgress calls report as the request body is sent, with the bytes sent so far and the total
+// from the body length, which is -1 when unknown (e.g. a GetBody stream without Content-Length)
+// Every attempt sends the body again, so a retry first reports (0, total) and then counts up from zero.
+// report runs on the goroutine writing the request and should return quickly.
+func WithUploadProgress(report func(sent, total int64)) RequestOption {
+	return func(o *RequestOptions) {
+		o.UploadProgress = report
+	}
+}
+
+// progressBody reports the running byte count of a request body after every read
+type progressBody struct {
+	io.ReadCloser
+	sent   int64
+	total  int64
+	report func(sent, total int64)
+}
+
+// withUploadProgress wraps the body of req in a progressBody for one attempt
+// req.GetBody is kept, so signers and redirects still read the body without reporting progress.
+func withUploadProgress(req *http.Request, attempt int, report func(sent, total int64)) {
+	if report == nil || req.Body == nil || req.Body == http.NoBody {
+		return
+	}
+	total := req.ContentLength
+	if total <= 0 {
+		total = -1
+	}
+	if attempt > 0 {
+		report(0, total)
+	}
+	req.Body = &progressBody{ReadCloser: req.Body, total: total, report: report}
+}
+
+// Read implements io.Reader
+func (p *progressBody) Read(b []byte) (int, error) {
+	n, err := p.ReadCloser.Read(b)
+	p.sent += int64(n)
+	if n > 0 {
+		p.report(p.sent, p.total)
+	}
+	return n, err
+}
@@ -0,0 +1,383 @@
+package jsonutil
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// JSONClient defines the interface for JSON-oriented data conversion operations
+type JSONClient interface {
+	// Struct/map bridging
+	StructToMap(v any) (map[string]any, error)
+	MapToStruct(m map[string]any, out any) error
+
+	// Streaming
+	DecodeArrayStream(r io.Reader, fn func(json.RawMessage) error) error
+
+	// Validation
+	ValidateSchema(doc []byte, schema []byte) error
+}
+
+// JSONUtil provides JSON-aware conversions between typed structs and map[string]any documents
+type JSONUtil struct{}
+
+// NewJSONUtil creates a new instance of JSONUtil
+func NewJSONUtil() JSONClient {
+	return &JSONUtil{}
+}
+
+var (
+	jsonMarshalerType   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// StructToMap converts a struct (or pointer to struct) into a map keyed by JSON field names
+// It honors json tags, omitempty and embedded structs without marshaling to bytes. Nested structs
+// become nested maps, while values with custom JSON or text marshalers are kept as-is.
+func (j *JSONUtil) StructToMap(v any) (map[string]any, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("cannot convert nil %T to map", v)
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected struct for map conversion, got %T", v)
+	}
+	return structToMap(rv), nil
+}
+
+// MapToStruct populates the struct pointed to by out from a map keyed by JSON field names
+// Keys are matched like encoding/json (exact first, then case-insensitive) and unknown keys are
+// ignored. Values are converted where safe, e.g. integral float64 values into int fields.
+func (j *JSONUtil) MapToStruct(m map[string]any, out any) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("expected non-nil pointer to struct, got %T", out)
+	}
+	rv = rv.Elem()
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("expected non-nil pointer to struct, got %T", out)
+	}
+	return mapToStruct(rv, m, "")
+}
+
+// structToMap converts a struct value using the cached field list for its type
+func structToMap(rv reflect.Value) map[string]any {
+	fields := cachedFields(rv.Type())
+	result := make(map[string]any, len(fields))
+	for _, f := range fields {
+		fv, ok := fieldByIndex(rv, f.index)
+		if !ok {
+			continue // field lives behind a nil embedded pointer
+		}
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		result[f.name] = toMapValue(fv)
+	}
+	return result
+}
+
+// toMapValue converts a field value into its map representation
+func toMapValue(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if hasCustomMarshaler(v.Type()) {
+		return v.Interface()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return toMapValue(v.Elem())
+	case reflect.Struct:
+		return structToMap(v)
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		if !needsConversion(v.Type().Elem()) {
+			return v.Interface()
+		}
+		items := make([]any, v.Len())
+		for i := range items {
+			items[i] = toMapValue(v.Index(i))
+		}
+		return items
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Key().Kind() != reflect.String || !needsConversion(v.Type().Elem()) {
+			return v.Interface()
+		}
+		converted := make(map[string]any, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			converted[iter.Key().String()] = toMapValue(iter.Value())
+		}
+		return converted
+	default:
+		return v.Interface()
+	}
+}
+
+// needsConversion reports whether values of type t may contain structs that must become maps
+func needsConversion(t reflect.Type) bool {
+	if hasCustomMarshaler(t) {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return needsConversion(t.Elem())
+	case reflect.Map:
+		return true
+	case reflect.Struct, reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+// hasCustomMarshaler reports whether t (or *t) controls its own JSON representation
+func hasCustomMarshaler(t reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return false
+	}
+	pt := reflect.PtrTo(t)
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) ||
+		pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType)
+}
+
+// isEmptyValue mirrors the omitempty semantics of encoding/json
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
+
+// fieldByIndex walks an index path, returning false when a nil embedded pointer is encountered
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// fieldByIndexAlloc walks an index path, allocating nil embedded pointers along the way
+func fieldByIndexAlloc(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %s", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// mapToStruct assigns map entries onto the matching fields of a struct value
+func mapToStruct(rv reflect.Value, m map[string]any, path string) error {
+	fields := cachedFields(rv.Type())
+	for key, val := range m {
+		f, ok := lookupField(fields, key)
+		if !ok {
+			continue
+		}
+		fv, err := fieldByIndexAlloc(rv, f.index)
+		if err != nil {
+			return err
+		}
+		if err := assign(fv, val, joinPath(path, key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinPath builds a dotted field path for error messages
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// assign stores src into dst, converting between compatible representations
+func assign(dst reflect.Value, src any, path string) error {
+	if src == nil {
+		switch dst.Kind() {
+		case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+			dst.Set(reflect.Zero(dst.Type()))
+		}
+		return nil
+	}
+
+	sv := reflect.ValueOf(src)
+	if sv.Type().AssignableTo(dst.Type()) {
+		dst.Set(sv)
+		return nil
+	}
+
+	if dst.Kind() == reflect.Ptr {
+		elem := reflect.New(dst.Type().Elem())
+		if err := assign(elem.Elem(), src, path); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+
+	pt := reflect.PtrTo(dst.Type())
+	if pt.Implements(jsonUnmarshalerType) || pt.Implements(textUnmarshalerType) {
+		return assignViaJSON(dst, src, path)
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		nested, ok := src.(map[string]any)
+		if !ok {
+			return assignViaJSON(dst, src, path)
+		}
+		return mapToStruct(dst, nested, path)
+
+	case reflect.Slice, reflect.Array:
+		if sv.Kind() != reflect.Slice && sv.Kind() != reflect.Array {
+			return fmt.Errorf("cannot assign %T to field '%s' of type %s", src, path, dst.Type())
+		}
+		target := dst
+		if dst.Kind() == reflect.Slice {
+			target = reflect.MakeSlice(dst.Type(), sv.Len(), sv.Len())
+		} else if sv.Len() > dst.Len() {
+			return fmt.Errorf("cannot assign %d items to field '%s' of type %s", sv.Len(), path, dst.Type())
+		}
+		for i := 0; i < sv.Len(); i++ {
+			if err := assign(target.Index(i), sv.Index(i).Interface(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+		if dst.Kind() == reflect.Slice {
+			dst.Set(target)
+		}
+		return nil
+
+	case reflect.Map:
+		if sv.Kind() != reflect.Map || sv.Type().Key().Kind() != reflect.String || dst.Type().Key().Kind() != reflect.String {
+			return assignViaJSON(dst, src, path)
+		}
+		target := reflect.MakeMapWithSize(dst.Type(), sv.Len())
+		iter := sv.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := assign(elem, iter.Value().Interface(), joinPath(path, key)); err != nil {
+				return err
+			}
+			target.SetMapIndex(reflect.ValueOf(key).Convert(dst.Type().Key()), elem)
+		}
+		dst.Set(target)
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := toInt64(sv)
+		if !ok || dst.OverflowInt(n) {
+			return fmt.Errorf("cannot assign %v (%T) to field '%s' of type %s", src, src, path, dst.Type())
+		}
+		dst.SetInt(n)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := toInt64(sv)
+		if !ok || n < 0 || dst.OverflowUint(uint64(n)) {
+			return fmt.Errorf("cannot assign %v (%T) to field '%s' of type %s", src, src, path, dst.Type())
+		}
+		dst.SetUint(uint64(n))
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		switch sv.Kind() {
+		case reflect.Float32, reflect.Float64:
+			dst.SetFloat(sv.Float())
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			dst.SetFloat(float64(sv.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			dst.SetFloat(float64(sv.Uint()))
+		default:
+			return fmt.Errorf("cannot assign %T to field '%s' of type %s", src, path, dst.Type())
+		}
+		return nil
+	}
+
+	if sv.Type().ConvertibleTo(dst.Type()) && sv.Kind() == dst.Kind() {
+		dst.Set(sv.Convert(dst.Type()))
+		return nil
+	}
+
+	return assignViaJSON(dst, src, path)
+}
+
+// toInt64 converts an integer or integral float value to int64
+func toInt64(v reflect.Value) (int64, bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		if u > 1<<63-1 {
+			return 0, false
+		}
+		return int64(u), true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if f != float64(int64(f)) {
+			return 0, false
+		}
+		return int64(f), true
+	}
+	return 0, false
+}
+
+// assignViaJSON falls back to a JSON round trip for the single value, e.g. for custom unmarshalers
+func assignViaJSON(dst reflect.Value, src any, path string) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return fmt.Errorf("cannot assign %T to field '%s': %w", src, path, err)
+	}
+	if err := json.Unmarshal(data, dst.Addr().Interface()); err != nil {
+		return fmt.Errorf("cannot assign %T to field '%s' of type %s: %w", src, path, dst.Type(), err)
+	}
+	return nil
+}
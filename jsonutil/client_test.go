@@ -0,0 +1,563 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testAddress struct {
+	City string `json:"city"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type testBase struct {
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type testUser struct {
+	testBase
+	Name     string            `json:"name"`
+	Email    string            `json:"email,omitempty"`
+	Age      int               `json:"age"`
+	Score    float64           `json:"score"`
+	Active   bool              `json:"active"`
+	Tags     []string          `json:"tags"`
+	Address  *testAddress      `json:"address,omitempty"`
+	Previous []testAddress     `json:"previous,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+func TestNewJSONUtil(t *testing.T) {
+	util := NewJSONUtil()
+	if util == nil {
+		t.Error("NewJSONUtil() returned nil")
+	}
+}
+
+func TestStructToMap(t *testing.T) {
+	util := NewJSONUtil()
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	user := testUser{
+		testBase: testBase{ID: 7, CreatedAt: created},
+		Name:     "John",
+		Age:      30,
+		Tags:     []string{"a", "b"},
+		Address:  &testAddress{City: "Pune"},
+		Previous: []testAddress{{City: "Delhi", Zip: "110001"}},
+		Secret:   "hidden",
+		internal: "hidden",
+	}
+
+	result, err := util.StructToMap(&user)
+	if err != nil {
+		t.Fatalf("StructToMap() unexpected error: %v", err)
+	}
+
+	if result["id"] != 7 {
+		t.Errorf("Expected promoted embedded field id=7, got %v", result["id"])
+	}
+	if result["created_at"] != created {
+		t.Errorf("Expected time.Time to be kept as-is, got %v", result["created_at"])
+	}
+	if result["name"] != "John" {
+		t.Errorf("Expected name=John, got %v", result["name"])
+	}
+	if _, exists := result["email"]; exists {
+		t.Error("Expected empty email to be omitted")
+	}
+	if _, exists := result["Secret"]; exists {
+		t.Error("Expected json:\"-\" field to be skipped")
+	}
+	if _, exists := result["internal"]; exists {
+		t.Error("Expected unexported field to be skipped")
+	}
+	if !reflect.DeepEqual(result["tags"], []string{"a", "b"}) {
+		t.Errorf("Expected tags to be kept as []string, got %#v", result["tags"])
+	}
+
+	address, ok := result["address"].(map[string]any)
+	if !ok || address["city"] != "Pune" {
+		t.Errorf("Expected nested address map, got %#v", result["address"])
+	}
+	if _, exists := address["zip"]; exists {
+		t.Error("Expected empty nested zip to be omitted")
+	}
+
+	previous, ok := result["previous"].([]any)
+	if !ok || len(previous) != 1 {
+		t.Fatalf("Expected previous to be []any with 1 item, got %#v", result["previous"])
+	}
+	if first, ok := previous[0].(map[string]any); !ok || first["zip"] != "110001" {
+		t.Errorf("Expected converted slice element, got %#v", previous[0])
+	}
+}
+
+func TestStructToMap_InvalidInput(t *testing.T) {
+	util := NewJSONUtil()
+
+	tests := []struct {
+		name  string
+		input any
+	}{
+		{"nil pointer", (*testUser)(nil)},
+		{"non-struct", 42},
+		{"map", map[string]any{"a": 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := util.StructToMap(tt.input); err == nil {
+				t.Errorf("StructToMap(%v) expected error", tt.input)
+			}
+		})
+	}
+}
+
+func TestStructToMap_FieldConflicts(t *testing.T) {
+	type A struct {
+		Name string
+		Dup  string
+	}
+	type B struct {
+		Dup string
+	}
+	type C struct {
+		Tagged string `json:"Dup"`
+	}
+	type Outer struct {
+		A
+		B
+		Name string
+	}
+	type Outer2 struct {
+		A
+		C
+	}
+
+	util := NewJSONUtil()
+
+	result, err := util.StructToMap(Outer{A: A{Name: "inner", Dup: "a"}, B: B{Dup: "b"}, Name: "outer"})
+	if err != nil {
+		t.Fatalf("StructToMap() unexpected error: %v", err)
+	}
+	if result["Name"] != "outer" {
+		t.Errorf("Expected shallow field to win, got %v", result["Name"])
+	}
+	if _, exists := result["Dup"]; exists {
+		t.Error("Expected ambiguous untagged fields to be dropped")
+	}
+
+	result, err = util.StructToMap(Outer2{A: A{Dup: "a"}, C: C{Tagged: "c"}})
+	if err != nil {
+		t.Fatalf("StructToMap() unexpected error: %v", err)
+	}
+	if result["Dup"] != "c" {
+		t.Errorf("Expected tagged field to win, got %v", result["Dup"])
+	}
+}
+
+func TestMapToStruct(t *testing.T) {
+	util := NewJSONUtil()
+
+	data := map[string]any{
+		"id":         float64(7),
+		"created_at": "2024-01-02T03:04:05Z",
+		"NAME":       "John",
+		"age":        float64(30),
+		"score":      42,
+		"active":     true,
+		"tags":       []any{"a", "b"},
+		"address":    map[string]any{"city": "Pune", "zip": "411001"},
+		"previous":   []any{map[string]any{"city": "Delhi"}},
+		"labels":     map[string]any{"env": "prod"},
+		"unknown":    "ignored",
+	}
+
+	var user testUser
+	if err := util.MapToStruct(data, &user); err != nil {
+		t.Fatalf("MapToStruct() unexpected error: %v", err)
+	}
+
+	if user.ID != 7 {
+		t.Errorf("Expected ID=7, got %d", user.ID)
+	}
+	if !user.CreatedAt.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Expected CreatedAt to be parsed, got %v", user.CreatedAt)
+	}
+	if user.Name != "John" {
+		t.Errorf("Expected case-insensitive match for name, got %q", user.Name)
+	}
+	if user.Age != 30 || user.Score != 42 || !user.Active {
+		t.Errorf("Unexpected scalar values: %+v", user)
+	}
+	if !reflect.DeepEqual(user.Tags, []string{"a", "b"}) {
+		t.Errorf("Expected tags [a b], got %v", user.Tags)
+	}
+	if user.Address == nil || user.Address.City != "Pune" || user.Address.Zip != "411001" {
+		t.Errorf("Expected address to be populated, got %+v", user.Address)
+	}
+	if len(user.Previous) != 1 || user.Previous[0].City != "Delhi" {
+		t.Errorf("Expected previous addresses, got %+v", user.Previous)
+	}
+	if user.Labels["env"] != "prod" {
+		t.Errorf("Expected labels to be populated, got %v", user.Labels)
+	}
+}
+
+func TestMapToStruct_Errors(t *testing.T) {
+	util := NewJSONUtil()
+
+	tests := []struct {
+		name string
+		data map[string]any
+		out  any
+	}{
+		{"non-pointer", map[string]any{}, testUser{}},
+		{"nil pointer", map[string]any{}, (*testUser)(nil)},
+		{"pointer to non-struct", map[string]any{}, new(int)},
+		{"fractional float to int", map[string]any{"age": 1.5}, &testUser{}},
+		{"string to int", map[string]any{"age": "thirty"}, &testUser{}},
+		{"scalar to slice", map[string]any{"tags": "a"}, &testUser{}},
+		{"wrong nested element", map[string]any{"tags": []any{1}}, &testUser{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := util.MapToStruct(tt.data, tt.out); err == nil {
+				t.Errorf("MapToStruct() expected error for %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestMapToStruct_NilValues(t *testing.T) {
+	util := NewJSONUtil()
+	user := testUser{Address: &testAddress{City: "Pune"}, Name: "John"}
+
+	if err := util.MapToStruct(map[string]any{"address": nil, "name": nil}, &user); err != nil {
+		t.Fatalf("MapToStruct() unexpected error: %v", err)
+	}
+	if user.Address != nil {
+		t.Error("Expected nil to clear pointer field")
+	}
+	if user.Name != "John" {
+		t.Error("Expected nil to leave non-nullable field unchanged")
+	}
+}
+
+func TestStructToMap_RoundTrip(t *testing.T) {
+	util := NewJSONUtil()
+	original := testUser{
+		testBase: testBase{ID: 1},
+		Name:     "Jane",
+		Tags:     []string{"x"},
+		Address:  &testAddress{City: "Mumbai"},
+		Labels:   map[string]string{"team": "core"},
+	}
+
+	m, err := util.StructToMap(original)
+	if err != nil {
+		t.Fatalf("StructToMap() unexpected error: %v", err)
+	}
+
+	var decoded testUser
+	if err := util.MapToStruct(m, &decoded); err != nil {
+		t.Fatalf("MapToStruct() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(original, decoded) {
+		t.Errorf("Round trip mismatch:\noriginal %+v\ndecoded  %+v", original, decoded)
+	}
+}
+
+func BenchmarkStructToMap(b *testing.B) {
+	util := NewJSONUtil()
+	user := testUser{Name: "John", Age: 30, Address: &testAddress{City: "Pune"}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = util.StructToMap(user)
+	}
+}
+
+func TestDecodeArrayStream(t *testing.T) {
+	util := NewJSONUtil()
+
+	input := `[{"city":"Pune"}, {"city":"Delhi"}, {"city":"Mumbai"}]`
+	var cities []string
+	err := util.DecodeArrayStream(strings.NewReader(input), func(raw json.RawMessage) error {
+		var addr testAddress
+		if err := json.Unmarshal(raw, &addr); err != nil {
+			return err
+		}
+		cities = append(cities, addr.City)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("DecodeArrayStream() unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cities, []string{"Pune", "Delhi", "Mumbai"}) {
+		t.Errorf("Expected all cities in order, got %v", cities)
+	}
+}
+
+func TestDecodeArrayStream_EmptyArray(t *testing.T) {
+	util := NewJSONUtil()
+	calls := 0
+	err := util.DecodeArrayStream(strings.NewReader(`[]`), func(json.RawMessage) error {
+		calls++
+		return nil
+	})
+	if err != nil || calls != 0 {
+		t.Errorf("Expected no calls and no error, got calls=%d err=%v", calls, err)
+	}
+}
+
+func TestDecodeArrayStream_Errors(t *testing.T) {
+	util := NewJSONUtil()
+	noop := func(json.RawMessage) error { return nil }
+
+	tests := []struct {
+		name  string
+		input string
+		fn    func(json.RawMessage) error
+	}{
+		{"empty input", ``, noop},
+		{"object instead of array", `{"a":1}`, noop},
+		{"scalar", `42`, noop},
+		{"malformed element", `[{"a":1}, {"a":}]`, noop},
+		{"unterminated array", `[1, 2`, noop},
+		{"nil callback", `[1]`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := util.DecodeArrayStream(strings.NewReader(tt.input), tt.fn); err == nil {
+				t.Errorf("DecodeArrayStream(%q) expected error", tt.input)
+			}
+		})
+	}
+}
+
+func TestDecodeArrayStream_CallbackError(t *testing.T) {
+	util := NewJSONUtil()
+	stop := errors.New("stop")
+	calls := 0
+
+	err := util.DecodeArrayStream(strings.NewReader(`[1, 2, 3]`), func(json.RawMessage) error {
+		calls++
+		if calls == 2 {
+			return stop
+		}
+		return nil
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("Expected callback error to be returned, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected iteration to stop after 2 calls, got %d", calls)
+	}
+}
+
+// =================== Test Schema Validation ===================
+
+const testOrderSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["id", "status", "items"],
+	"additionalProperties": false,
+	"properties": {
+		"id": {"type": "integer", "minimum": 1},
+		"status": {"enum": ["open", "paid", "shipped"]},
+		"email": {"type": "string", "format": "email", "pattern": "^[^@]+@[^@]+$"},
+		"note": {"type": ["string", "null"], "maxLength": 5},
+		"total": {"type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01},
+		"tags": {"type": "array", "items": {"type": "string", "minLength": 1}, "uniqueItems": true, "maxItems": 3},
+		"items": {"type": "array", "minItems": 1, "items": {"$ref": "#/$defs/item"}},
+		"shipping": {"oneOf": [{"$ref": "#/$defs/address"}, {"const": "pickup"}]}
+	},
+	"$defs": {
+		"item": {
+			"type": "object",
+			"required": ["sku", "qty"],
+			"properties": {"sku": {"type": "string"}, "qty": {"type": "integer", "minimum": 1, "maximum": 100}}
+		},
+		"address": {"type": "object", "required": ["city"], "properties": {"city": {"type": "string"}}}
+	}
+}`
+
+func TestValidateSchema(t *testing.T) {
+	util := NewJSONUtil()
+
+	tests := []struct {
+		name     string
+		doc      string
+		expected []SchemaViolation
+	}{
+		{
+			name: "valid",
+			doc: `{"id": 7, "status": "paid", "email": "a@b.c", "note": null, "total": 19.99, "tags": ["x", "y"],
+				"items": [{"sku": "A1", "qty": 2}], "shipping": {"city": "Oslo"}}`,
+		},
+		{
+			name: "integral float is an integer",
+			doc:  `{"id": 7.0, "status": "open", "items": [{"sku": "A1", "qty": 1}], "shipping": "pickup"}`,
+		},
+		{
+			name: "root type",
+			doc:  `[1, 2]`,
+			expected: []SchemaViolation{
+				{Path: "", Keyword: "type", Message: "must be of type object, got array"},
+			},
+		},
+		{
+			name: "missing and unknown properties",
+			doc:  `{"id": 0, "extra": true, "items": []}`,
+			expected: []SchemaViolation{
+				{Path: "status", Keyword: "required", Message: "is required"},
+				{Path: "extra", Keyword: "additionalProperties", Message: "is not an allowed property"},
+				{Path: "id", Keyword: "minimum", Message: "must be >= 1"},
+				{Path: "items", Keyword: "minItems", Message: "must have at least 1 items"},
+			},
+		},
+		{
+			name: "nested paths through $ref",
+			doc:  `{"id": 1, "status": "lost", "items": [{"sku": "A1", "qty": 1}, {"qty": 101.5}]}`,
+			expected: []SchemaViolation{
+				{Path: "items[1].sku", Keyword: "required", Message: "is required"},
+				{Path: "items[1].qty", Keyword: "type", Message: "must be of type integer, got number"},
+				{Path: "items[1].qty", Keyword: "maximum", Message: "must be <= 100"},
+				{Path: "status", Keyword: "enum", Message: `must be one of ["open","paid","shipped"]`},
+			},
+		},
+		{
+			name: "string, number and array keywords",
+			doc: `{"id": 1, "status": "open", "items": [{"sku": "A", "qty": 1}], "email": "nope", "note": "too long",
+				"total": 1.005, "tags": ["a", "", "a", "b"], "shipping": "courier"}`,
+			expected: []SchemaViolation{
+				{Path: "email", Keyword: "pattern", Message: "must match pattern ^[^@]+@[^@]+$"},
+				{Path: "note", Keyword: "maxLength", Message: "must have at most 5 characters"},
+				{Path: "shipping", Keyword: "oneOf", Message: "must match exactly one schema in oneOf, matched 0"},
+				{Path: "tags", Keyword: "maxItems", Message: "must have at most 3 items"},
+				{Path: "tags[1]", Keyword: "minLength", Message: "must have at least 1 characters"},
+				{Path: "tags[2]", Keyword: "uniqueItems", Message: "duplicates item 0"},
+				{Path: "total", Keyword: "multipleOf", Message: "must be a multiple of 0.01"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := util.ValidateSchema([]byte(tt.doc), []byte(testOrderSchema))
+			if tt.expected == nil {
+				if err != nil {
+					t.Errorf("ValidateSchema() unexpected error: %v", err)
+				}
+				return
+			}
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) || !errors.Is(err, ErrSchemaViolation) {
+				t.Fatalf("ValidateSchema() error = %v, want *SchemaError", err)
+			}
+			if !reflect.DeepEqual(schemaErr.Violations, tt.expected) {
+				t.Errorf("Violations =\n%+v\nwant\n%+v", schemaErr.Violations, tt.expected)
+			}
+			if fields := schemaErr.FieldErrors(); len(fields) != len(tt.expected) || fields[0].Field != tt.expected[0].Path {
+				t.Errorf("FieldErrors() = %+v, want one entry per violation", fields)
+			}
+		})
+	}
+}
+
+func TestValidateSchema_Combinators(t *testing.T) {
+	util := NewJSONUtil()
+	schema := `{
+		"allOf": [{"type": "object"}],
+		"anyOf": [{"required": ["email"]}, {"required": ["phone"]}],
+		"not": {"required": ["banned"]},
+		"if": {"properties": {"country": {"const": "US"}}, "required": ["country"]},
+		"then": {"required": ["zip"]},
+		"else": {"required": ["postcode"]},
+		"properties": {"tags": {"contains": {"const": "primary"}}},
+		"patternProperties": {"^x-": {"type": "string"}}
+	}`
+
+	tests := []struct {
+		name     string
+		doc      string
+		keywords []string
+	}{
+		{name: "valid then branch", doc: `{"email": "a", "country": "US", "zip": "1", "x-id": "7"}`},
+		{name: "valid else branch", doc: `{"phone": "1", "postcode": "N1", "tags": ["primary"]}`},
+		{name: "anyOf", doc: `{"postcode": "N1"}`, keywords: []string{"anyOf"}},
+		{name: "not", doc: `{"email": "a", "postcode": "N1", "banned": true}`, keywords: []string{"not"}},
+		{name: "then", doc: `{"email": "a", "country": "US"}`, keywords: []string{"required"}},
+		{name: "contains and pattern properties", doc: `{"email": "a", "postcode": "N1", "tags": [], "x-id": 7}`, keywords: []string{"contains", "type"}},
+		// required holds for non-objects, so "not" rejects any string
+		{name: "allOf", doc: `"text"`, keywords: []string{"type", "not"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := util.ValidateSchema([]byte(tt.doc), []byte(schema))
+			var got []string
+			var schemaErr *SchemaError
+			if errors.As(err, &schemaErr) {
+				for _, v := range schemaErr.Violations {
+					got = append(got, v.Keyword)
+				}
+			} else if err != nil {
+				t.Fatalf("ValidateSchema() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.keywords) {
+				t.Errorf("violated keywords = %v, want %v (%v)", got, tt.keywords, err)
+			}
+		})
+	}
+}
+
+func TestValidateSchema_Errors(t *testing.T) {
+	util := NewJSONUtil()
+
+	tests := []struct {
+		name       string
+		doc        string
+		schema     string
+		wantSchema bool
+	}{
+		{name: "malformed schema", doc: `{}`, schema: `{"type":`, wantSchema: true},
+		{name: "bad keyword value", doc: `"x"`, schema: `{"minLength": -1}`, wantSchema: true},
+		{name: "bad pattern", doc: `"x"`, schema: `{"pattern": "("}`, wantSchema: true},
+		{name: "remote ref", doc: `1`, schema: `{"$ref": "https://example.com/s.json"}`, wantSchema: true},
+		{name: "missing ref", doc: `1`, schema: `{"$ref": "#/$defs/missing"}`, wantSchema: true},
+		{name: "recursive ref", doc: `1`, schema: `{"$ref": "#"}`, wantSchema: true},
+		{name: "malformed document", doc: `{"a": }`, schema: `{}`},
+		{name: "trailing data", doc: `{} {}`, schema: `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := util.ValidateSchema([]byte(tt.doc), []byte(tt.schema))
+			if err == nil || errors.Is(err, ErrSchemaViolation) {
+				t.Fatalf("ValidateSchema() error = %v, want a non-violation error", err)
+			}
+			if errors.Is(err, ErrInvalidSchema) != tt.wantSchema {
+				t.Errorf("ValidateSchema() error = %v, ErrInvalidSchema match want %v", err, tt.wantSchema)
+			}
+		})
+	}
+
+	if err := util.ValidateSchema([]byte(`{"anything": 1}`), []byte(`true`)); err != nil {
+		t.Errorf("ValidateSchema() with schema true error = %v, want nil", err)
+	}
+	if err := util.ValidateSchema([]byte(`1`), []byte(`false`)); !errors.Is(err, ErrSchemaViolation) {
+		t.Errorf("ValidateSchema() with schema false error = %v, want a violation", err)
+	}
+}
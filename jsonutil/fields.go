@@ -0,0 +1,168 @@
+package jsonutil
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// fieldInfo describes a single JSON-visible struct field
+type fieldInfo struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	tagged    bool
+}
+
+// fieldCache caches resolved field lists per struct type
+var fieldCache sync.Map // map[reflect.Type][]fieldInfo
+
+// cachedFields returns the JSON-visible fields for a struct type, resolving them once per type
+func cachedFields(t reflect.Type) []fieldInfo {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]fieldInfo)
+	}
+	fields := typeFields(t)
+	actual, _ := fieldCache.LoadOrStore(t, fields)
+	return actual.([]fieldInfo)
+}
+
+// parseTag splits a json struct tag into its name and omitempty option
+func parseTag(tag string) (string, bool) {
+	parts := strings.Split(tag, ",")
+	omitEmpty := false
+	for _, opt := range parts[1:] {
+		if opt == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return parts[0], omitEmpty
+}
+
+// typeFields walks a struct type breadth-first, promoting fields of untagged embedded structs
+// Name conflicts follow encoding/json rules: the shallowest field wins, a tagged field beats an
+// untagged one at the same depth, and remaining ties are dropped.
+func typeFields(t reflect.Type) []fieldInfo {
+	type queued struct {
+		typ   reflect.Type
+		index []int
+	}
+
+	var fields []fieldInfo
+	current := []queued{}
+	next := []queued{{typ: t}}
+	visited := map[reflect.Type]bool{}
+
+	for len(next) > 0 {
+		current, next = next, current[:0]
+		levelFields := map[string][]fieldInfo{}
+		var levelOrder []string
+
+		for _, q := range current {
+			if visited[q.typ] {
+				continue
+			}
+			visited[q.typ] = true
+
+			for i := 0; i < q.typ.NumField(); i++ {
+				sf := q.typ.Field(i)
+				tag := sf.Tag.Get("json")
+				if tag == "-" {
+					continue
+				}
+
+				ft := sf.Type
+				if ft.Kind() == reflect.Ptr {
+					ft = ft.Elem()
+				}
+
+				if sf.Anonymous {
+					if !sf.IsExported() && ft.Kind() != reflect.Struct {
+						continue
+					}
+				} else if !sf.IsExported() {
+					continue
+				}
+
+				index := make([]int, len(q.index)+1)
+				copy(index, q.index)
+				index[len(q.index)] = i
+
+				name, omitEmpty := parseTag(tag)
+
+				// Untagged embedded structs have their fields promoted to the parent
+				if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+					next = append(next, queued{typ: ft, index: index})
+					continue
+				}
+
+				tagged := name != ""
+				if !tagged {
+					name = sf.Name
+				}
+				if _, seen := levelFields[name]; !seen {
+					levelOrder = append(levelOrder, name)
+				}
+				levelFields[name] = append(levelFields[name], fieldInfo{
+					name:      name,
+					index:     index,
+					omitEmpty: omitEmpty,
+					tagged:    tagged,
+				})
+			}
+		}
+
+		for _, name := range levelOrder {
+			if fieldByName(fields, name) >= 0 {
+				continue // a shallower field already claimed this name
+			}
+			if field, ok := dominantField(levelFields[name]); ok {
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	return fields
+}
+
+// dominantField picks the winning field among same-depth candidates sharing a name
+func dominantField(candidates []fieldInfo) (fieldInfo, bool) {
+	if len(candidates) == 1 {
+		return candidates[0], true
+	}
+	var winner fieldInfo
+	taggedCount := 0
+	for _, c := range candidates {
+		if c.tagged {
+			winner = c
+			taggedCount++
+		}
+	}
+	if taggedCount == 1 {
+		return winner, true
+	}
+	return fieldInfo{}, false
+}
+
+// fieldByName returns the position of the field with the given name, or -1
+func fieldByName(fields []fieldInfo, name string) int {
+	for i, f := range fields {
+		if f.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// lookupField finds a field by exact name first, then case-insensitively like encoding/json
+func lookupField(fields []fieldInfo, name string) (fieldInfo, bool) {
+	if i := fieldByName(fields, name); i >= 0 {
+		return fields[i], true
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, name) {
+			return f, true
+		}
+	}
+	return fieldInfo{}, false
+}
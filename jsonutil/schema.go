@@ -0,0 +1,686 @@
+package jsonutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mustanish/common-utils/v2/errorutil"
+)
+
+var (
+	// ErrInvalidSchema is returned when a schema is not valid JSON or uses a supported keyword incorrectly
+	ErrInvalidSchema = errors.New("invalid JSON schema")
+
+	// ErrSchemaViolation is wrapped by *SchemaError when a document does not match its schema
+	ErrSchemaViolation = errors.New("document does not match schema")
+)
+
+// maxSchemaDepth bounds nested schema evaluation so that recursive $refs cannot loop forever
+const maxSchemaDepth = 256
+
+// SchemaViolation describes one way a document fails its schema
+// Path addresses the offending value like "items[2].price", with "" for the document itself.
+type SchemaViolation struct {
+	Path    string
+	Keyword string
+	Message string
+}
+
+// SchemaError lists every violation found by ValidateSchema
+// It implements errorutil.Coder (INVALID_ARGUMENT) and errorutil.FieldErrorer, so errorutil's WriteError
+// responds 400 with one field entry per violation.
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+// Error implements the error interface for SchemaError
+func (e *SchemaError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = describePath(v.Path) + " " + v.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrSchemaViolation, strings.Join(parts, "; "))
+}
+
+// Unwrap returns ErrSchemaViolation
+func (e *SchemaError) Unwrap() error {
+	return ErrSchemaViolation
+}
+
+// ErrorCode implements errorutil.Coder
+func (e *SchemaError) ErrorCode() errorutil.Code {
+	return errorutil.CodeInvalidArgument
+}
+
+// FieldErrors implements errorutil.FieldErrorer
+func (e *SchemaError) FieldErrors() []errorutil.FieldError {
+	fields := make([]errorutil.FieldError, len(e.Violations))
+	for i, v := range e.Violations {
+		fields[i] = errorutil.FieldError{Field: v.Path, Message: v.Message}
+	}
+	return fields
+}
+
+// describePath names a violation path in error messages
+func describePath(path string) string {
+	if path == "" {
+		return "document"
+	}
+	return path
+}
+
+// ValidateSchema checks doc against a JSON Schema (draft 2020-12) and returns a *SchemaError listing
+// every violation, nil when doc matches, or an error wrapping ErrInvalidSchema for a malformed schema
+//
+// Supported keywords: type, enum, const; minimum, maximum, exclusiveMinimum, exclusiveMaximum,
+// multipleOf; minLength, maxLength, pattern; properties, required, additionalProperties,
+// patternProperties, minProperties, maxProperties; items, prefixItems, minItems, maxItems, uniqueItems,
+// contains; allOf, anyOf, oneOf, not, if/then/else; and $ref to "#" pointers such as "#/$defs/address".
+// Other keywords, including format, are ignored as annotations. Patterns use Go's RE2 syntax.
+func (j *JSONUtil) ValidateSchema(doc []byte, schema []byte) error {
+	schemaValue, err := decodeExact(schema)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+	value, err := decodeExact(doc)
+	if err != nil {
+		return fmt.Errorf("invalid JSON document: %w", err)
+	}
+
+	v := &schemaValidator{root: schemaValue, patterns: map[string]*regexp.Regexp{}}
+	if err := v.validate(schemaValue, value, "", 0); err != nil {
+		return err
+	}
+	if len(v.violations) > 0 {
+		return &SchemaError{Violations: v.violations}
+	}
+	return nil
+}
+
+// decodeExact decodes a single JSON value, keeping numbers exact as json.Number
+func decodeExact(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the JSON value")
+	}
+	return value, nil
+}
+
+// schemaValidator evaluates one schema against one document, collecting violations
+type schemaValidator struct {
+	root       any
+	patterns   map[string]*regexp.Regexp
+	violations []SchemaViolation
+}
+
+// fail records a violation
+func (v *schemaValidator) fail(path, keyword, format string, args ...any) {
+	v.violations = append(v.violations, SchemaViolation{Path: path, Keyword: keyword, Message: fmt.Sprintf(format, args...)})
+}
+
+// matches reports whether value satisfies schema without recording violations
+func (v *schemaValidator) matches(schema, value any, path string, depth int) (bool, error) {
+	sub := &schemaValidator{root: v.root, patterns: v.patterns}
+	if err := sub.validate(schema, value, path, depth); err != nil {
+		return false, err
+	}
+	return len(sub.violations) == 0, nil
+}
+
+// validate applies every supported keyword of schema to value
+func (v *schemaValidator) validate(schema, value any, path string, depth int) error {
+	if depth > maxSchemaDepth {
+		return fmt.Errorf("%w: schema nesting exceeds %d levels (recursive $ref?)", ErrInvalidSchema, maxSchemaDepth)
+	}
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.fail(path, "false", "is not allowed")
+		}
+		return nil
+	case map[string]any:
+		checks := []func(map[string]any, any, string, int) error{
+			v.checkRef, v.checkType, v.checkEnum, v.checkNumber, v.checkString,
+			v.checkObject, v.checkArray, v.checkCombinators,
+		}
+		for _, check := range checks {
+			if err := check(s, value, path, depth); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: schema at %s must be an object or boolean", ErrInvalidSchema, describePath(path))
+}
+
+// checkRef follows a local $ref
+func (v *schemaValidator) checkRef(s map[string]any, value any, path string, depth int) error {
+	raw, ok := s["$ref"]
+	if !ok {
+		return nil
+	}
+	ref, ok := raw.(string)
+	if !ok || !strings.HasPrefix(ref, "#") {
+		return fmt.Errorf("%w: only local $ref values starting with # are supported, got %v", ErrInvalidSchema, raw)
+	}
+	target, err := resolvePointer(v.root, strings.TrimPrefix(ref, "#"))
+	if err != nil {
+		return fmt.Errorf("%w: $ref %s: %v", ErrInvalidSchema, ref, err)
+	}
+	return v.validate(target, value, path, depth+1)
+}
+
+// resolvePointer finds the value a JSON Pointer (RFC 6901) addresses inside doc
+func resolvePointer(doc any, pointer string) (any, error) {
+	if pointer == "" {
+		return doc, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, errors.New("pointer must start with /")
+	}
+	current := doc
+	for _, token := range strings.Split(pointer[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch node := current.(type) {
+		case map[string]any:
+			next, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", token)
+			}
+			current = next
+		case []any:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("index %q out of range", token)
+			}
+			current = node[i]
+		default:
+			return nil, fmt.Errorf("cannot descend into %q", token)
+		}
+	}
+	return current, nil
+}
+
+// checkType applies the type keyword
+func (v *schemaValidator) checkType(s map[string]any, value any, path string, _ int) error {
+	raw, ok := s["type"]
+	if !ok {
+		return nil
+	}
+	var allowed []string
+	switch t := raw.(type) {
+	case string:
+		allowed = []string{t}
+	case []any:
+		for _, item := range t {
+			name, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("%w: type must be a string or an array of strings", ErrInvalidSchema)
+			}
+			allowed = append(allowed, name)
+		}
+	default:
+		return fmt.Errorf("%w: type must be a string or an array of strings", ErrInvalidSchema)
+	}
+
+	actual := jsonType(value)
+	for _, name := range allowed {
+		if name == actual || (name == "number" && actual == "integer") || (name == "integer" && isIntegral(value)) {
+			return nil
+		}
+	}
+	v.fail(path, "type", "must be of type %s, got %s", strings.Join(allowed, " or "), actual)
+	return nil
+}
+
+// jsonType names the JSON type of a decoded value, reporting integral numbers as integer
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if isIntegral(value) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// isIntegral reports whether value is a number without a fractional part, e.g. 3 or 3.0
+func isIntegral(value any) bool {
+	r, ok := toRat(value)
+	return ok && r.IsInt()
+}
+
+// toRat converts a JSON number to an exact rational
+func toRat(value any) (*big.Rat, bool) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return nil, false
+	}
+	return new(big.Rat).SetString(n.String())
+}
+
+// checkEnum applies the enum and const keywords
+func (v *schemaValidator) checkEnum(s map[string]any, value any, path string, _ int) error {
+	if raw, ok := s["enum"]; ok {
+		options, ok := raw.([]any)
+		if !ok {
+			return fmt.Errorf("%w: enum must be an array", ErrInvalidSchema)
+		}
+		found := false
+		for _, option := range options {
+			if jsonEqual(option, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "enum", "must be one of %s", compactJSON(options))
+		}
+	}
+	if expected, ok := s["const"]; ok && !jsonEqual(expected, value) {
+		v.fail(path, "const", "must be %s", compactJSON(expected))
+	}
+	return nil
+}
+
+// jsonEqual compares decoded JSON values, treating numbers by value (1 equals 1.0)
+func jsonEqual(a, b any) bool {
+	switch x := a.(type) {
+	case json.Number:
+		ra, okA := toRat(x)
+		rb, okB := toRat(b)
+		return okA && okB && ra.Cmp(rb) == 0
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !jsonEqual(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !jsonEqual(xv, yv) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+// compactJSON renders a decoded value for messages
+func compactJSON(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// checkNumber applies the numeric range keywords
+func (v *schemaValidator) checkNumber(s map[string]any, value any, path string, _ int) error {
+	n, ok := toRat(value)
+	if !ok {
+		return nil
+	}
+	bounds := []struct {
+		keyword string
+		fails   func(cmp int) bool
+		message string
+	}{
+		{"minimum", func(cmp int) bool { return cmp < 0 }, "must be >= %s"},
+		{"maximum", func(cmp int) bool { return cmp > 0 }, "must be <= %s"},
+		{"exclusiveMinimum", func(cmp int) bool { return cmp <= 0 }, "must be > %s"},
+		{"exclusiveMaximum", func(cmp int) bool { return cmp >= 0 }, "must be < %s"},
+	}
+	for _, bound := range bounds {
+		raw, ok := s[bound.keyword]
+		if !ok {
+			continue
+		}
+		limit, ok := toRat(raw)
+		if !ok {
+			return fmt.Errorf("%w: %s must be a number", ErrInvalidSchema, bound.keyword)
+		}
+		if bound.fails(n.Cmp(limit)) {
+			v.fail(path, bound.keyword, bound.message, raw)
+		}
+	}
+	if raw, ok := s["multipleOf"]; ok {
+		divisor, ok := toRat(raw)
+		if !ok || divisor.Sign() <= 0 {
+			return fmt.Errorf("%w: multipleOf must be a number greater than 0", ErrInvalidSchema)
+		}
+		if !new(big.Rat).Quo(n, divisor).IsInt() {
+			v.fail(path, "multipleOf", "must be a multiple of %s", raw)
+		}
+	}
+	return nil
+}
+
+// schemaCount reads a non-negative integer keyword such as minLength
+func schemaCount(s map[string]any, keyword string) (int, bool, error) {
+	raw, ok := s[keyword]
+	if !ok {
+		return 0, false, nil
+	}
+	r, isNumber := toRat(raw)
+	if !isNumber || !r.IsInt() || r.Sign() < 0 || !r.Num().IsInt64() {
+		return 0, false, fmt.Errorf("%w: %s must be a non-negative integer", ErrInvalidSchema, keyword)
+	}
+	return int(r.Num().Int64()), true, nil
+}
+
+// checkCounts applies a min/max keyword pair to a length
+func (v *schemaValidator) checkCounts(s map[string]any, length int, path, minKeyword, maxKeyword, noun string) error {
+	if limit, ok, err := schemaCount(s, minKeyword); err != nil {
+		return err
+	} else if ok && length < limit {
+		v.fail(path, minKeyword, "must have at least %d %s", limit, noun)
+	}
+	if limit, ok, err := schemaCount(s, maxKeyword); err != nil {
+		return err
+	} else if ok && length > limit {
+		v.fail(path, maxKeyword, "must have at most %d %s", limit, noun)
+	}
+	return nil
+}
+
+// checkString applies the string keywords
+func (v *schemaValidator) checkString(s map[string]any, value any, path string, _ int) error {
+	str, ok := value.(string)
+	if !ok {
+		return nil
+	}
+	if err := v.checkCounts(s, utf8.RuneCountInString(str), path, "minLength", "maxLength", "characters"); err != nil {
+		return err
+	}
+	if raw, ok := s["pattern"]; ok {
+		re, err := v.pattern(raw)
+		if err != nil {
+			return err
+		}
+		if !re.MatchString(str) {
+			v.fail(path, "pattern", "must match pattern %s", re)
+		}
+	}
+	return nil
+}
+
+// pattern compiles and caches a regular expression keyword value
+func (v *schemaValidator) pattern(raw any) (*regexp.Regexp, error) {
+	expr, ok := raw.(string)
+	if !ok {
+		return nil, fmt.Errorf("%w: pattern must be a string", ErrInvalidSchema)
+	}
+	if re, ok := v.patterns[expr]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("%w: pattern %q: %v", ErrInvalidSchema, expr, err)
+	}
+	v.patterns[expr] = re
+	return re, nil
+}
+
+// checkObject applies the object keywords
+func (v *schemaValidator) checkObject(s map[string]any, value any, path string, depth int) error {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+	if err := v.checkCounts(s, len(obj), path, "minProperties", "maxProperties", "properties"); err != nil {
+		return err
+	}
+
+	if raw, ok := s["required"]; ok {
+		required, ok := raw.([]any)
+		if !ok {
+			return fmt.Errorf("%w: required must be an array of strings", ErrInvalidSchema)
+		}
+		for _, item := range required {
+			name, ok := item.(string)
+			if !ok {
+				return fmt.Errorf("%w: required must be an array of strings", ErrInvalidSchema)
+			}
+			if _, exists := obj[name]; !exists {
+				v.fail(joinPath(path, name), "required", "is required")
+			}
+		}
+	}
+
+	properties, err := schemaMap(s, "properties")
+	if err != nil {
+		return err
+	}
+	patternProperties, err := schemaMap(s, "patternProperties")
+	if err != nil {
+		return err
+	}
+	additional, hasAdditional := s["additionalProperties"]
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		childPath := joinPath(path, key)
+		matched := false
+		if sub, ok := properties[key]; ok {
+			matched = true
+			if err := v.validate(sub, obj[key], childPath, depth+1); err != nil {
+				return err
+			}
+		}
+		for expr, sub := range patternProperties {
+			re, err := v.pattern(expr)
+			if err != nil {
+				return err
+			}
+			if re.MatchString(key) {
+				matched = true
+				if err := v.validate(sub, obj[key], childPath, depth+1); err != nil {
+					return err
+				}
+			}
+		}
+		if matched || !hasAdditional {
+			continue
+		}
+		if allowed, ok := additional.(bool); ok && !allowed {
+			v.fail(childPath, "additionalProperties", "is not an allowed property")
+			continue
+		}
+		if err := v.validate(additional, obj[key], childPath, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schemaMap reads a keyword whose value maps names to subschemas, such as properties
+func schemaMap(s map[string]any, keyword string) (map[string]any, error) {
+	raw, ok := s[keyword]
+	if !ok {
+		return nil, nil
+	}
+	m, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s must be an object", ErrInvalidSchema, keyword)
+	}
+	return m, nil
+}
+
+// schemaList reads a keyword whose value is a non-empty array of subschemas, such as allOf
+func schemaList(s map[string]any, keyword string) ([]any, bool, error) {
+	raw, ok := s[keyword]
+	if !ok {
+		return nil, false, nil
+	}
+	list, ok := raw.([]any)
+	if !ok || len(list) == 0 {
+		return nil, false, fmt.Errorf("%w: %s must be a non-empty array", ErrInvalidSchema, keyword)
+	}
+	return list, true, nil
+}
+
+// indexPath addresses an array element
+func indexPath(path string, i int) string {
+	return fmt.Sprintf("%s[%d]", path, i)
+}
+
+// checkArray applies the array keywords
+func (v *schemaValidator) checkArray(s map[string]any, value any, path string, depth int) error {
+	arr, ok := value.([]any)
+	if !ok {
+		return nil
+	}
+	if err := v.checkCounts(s, len(arr), path, "minItems", "maxItems", "items"); err != nil {
+		return err
+	}
+
+	prefix, _, err := schemaList(s, "prefixItems")
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(prefix) && i < len(arr); i++ {
+		if err := v.validate(prefix[i], arr[i], indexPath(path, i), depth+1); err != nil {
+			return err
+		}
+	}
+	if items, ok := s["items"]; ok {
+		for i := len(prefix); i < len(arr); i++ {
+			if err := v.validate(items, arr[i], indexPath(path, i), depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	if unique, _ := s["uniqueItems"].(bool); unique {
+	outer:
+		for i := 1; i < len(arr); i++ {
+			for k := 0; k < i; k++ {
+				if jsonEqual(arr[k], arr[i]) {
+					v.fail(indexPath(path, i), "uniqueItems", "duplicates item %d", k)
+					break outer
+				}
+			}
+		}
+	}
+
+	if contains, ok := s["contains"]; ok {
+		found := false
+		for i, item := range arr {
+			if found, err = v.matches(contains, item, indexPath(path, i), depth+1); err != nil {
+				return err
+			} else if found {
+				break
+			}
+		}
+		if !found {
+			v.fail(path, "contains", "must contain a matching item")
+		}
+	}
+	return nil
+}
+
+// checkCombinators applies allOf, anyOf, oneOf, not and if/then/else
+func (v *schemaValidator) checkCombinators(s map[string]any, value any, path string, depth int) error {
+	if all, ok, err := schemaList(s, "allOf"); err != nil {
+		return err
+	} else if ok {
+		for _, sub := range all {
+			if err := v.validate(sub, value, path, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, keyword := range []string{"anyOf", "oneOf"} {
+		list, ok, err := schemaList(s, keyword)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		matched := 0
+		for _, sub := range list {
+			ok, err := v.matches(sub, value, path, depth+1)
+			if err != nil {
+				return err
+			}
+			if ok {
+				matched++
+			}
+		}
+		switch {
+		case keyword == "anyOf" && matched == 0:
+			v.fail(path, keyword, "must match at least one schema in anyOf")
+		case keyword == "oneOf" && matched != 1:
+			v.fail(path, keyword, "must match exactly one schema in oneOf, matched %d", matched)
+		}
+	}
+
+	if not, ok := s["not"]; ok {
+		matched, err := v.matches(not, value, path, depth+1)
+		if err != nil {
+			return err
+		}
+		if matched {
+			v.fail(path, "not", "must not match the schema in not")
+		}
+	}
+
+	if cond, ok := s["if"]; ok {
+		matched, err := v.matches(cond, value, path, depth+1)
+		if err != nil {
+			return err
+		}
+		branch := "else"
+		if matched {
+			branch = "then"
+		}
+		if sub, ok := s[branch]; ok {
+			return v.validate(sub, value, path, depth+1)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package jsonutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// DecodeArrayStream iterates the elements of a top-level JSON array without loading the whole document
+// Each element is passed to fn as raw JSON; returning an error from fn stops the iteration and that
+// error is returned wrapped with the element index.
+func (j *JSONUtil) DecodeArrayStream(r io.Reader, fn func(json.RawMessage) error) error {
+	if fn == nil {
+		return fmt.Errorf("element callback cannot be nil")
+	}
+
+	dec := json.NewDecoder(r)
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to read array start: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("expected JSON array, got %v", tok)
+	}
+
+	for index := 0; dec.More(); index++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return fmt.Errorf("failed to decode element %d: %w", index, err)
+		}
+		if err := fn(raw); err != nil {
+			return fmt.Errorf("element %d: %w", index, err)
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("failed to read array end: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package logutil
+
+import (
+	"context"
+	"fmt"
+)
+
+// Level is the severity of a log entry
+type Level int8
+
+// Supported log levels, from most to least verbose
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+// String returns the lowercase name of the level
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	}
+	return fmt.Sprintf("level(%d)", int8(l))
+}
+
+// Fields holds structured key/value pairs attached to a log entry
+type Fields map[string]any
+
+// ErrorKey is the field name used by WithError
+const ErrorKey = "error"
+
+// Logger defines the logging facade consumed by the packages in this module
+// Loggers are immutable: the With* methods return a new Logger and leave the receiver unchanged.
+type Logger interface {
+	// Levels
+	Debug(msg string)
+	Info(msg string)
+	Warn(msg string)
+	Error(msg string)
+	Log(level Level, msg string)
+
+	// Field chaining
+	WithField(key string, value any) Logger
+	WithFields(fields Fields) Logger
+	WithError(err error) Logger
+
+	// Context propagation
+	WithContext(ctx context.Context) Logger
+}
+
+// Sink writes entries to a concrete logging backend
+// Implement it to plug another logging library into the facade.
+type Sink interface {
+	Enabled(level Level) bool
+	Emit(ctx context.Context, level Level, msg string, fields Fields)
+}
+
+// LoggerConfig holds the processing applied to entries before they reach the sink
+type LoggerConfig struct {
+	// Sampler drops a share of high-volume entries (nil keeps everything)
+	Sampler Sampler
+
+	// Hooks run in order over a copy of each entry's fields, e.g. to redact secrets
+	Hooks []FieldHook
+}
+
+// FieldLogger implements Logger on top of a Sink, accumulating fields until an entry is emitted
+type FieldLogger struct {
+	sink   Sink
+	config *LoggerConfig
+	fields Fields
+	ctx    context.Context
+}
+
+// NewLogger creates a Logger that writes to sink
+// Pass nil for config to emit every entry unchanged.
+func NewLogger(sink Sink, config *LoggerConfig) Logger {
+	if config == nil {
+		config = &LoggerConfig{}
+	}
+	return &FieldLogger{sink: sink, config: config}
+}
+
+// NewNopLogger creates a Logger that discards every entry
+func NewNopLogger() Logger {
+	return NewLogger(nopSink{}, nil)
+}
+
+// Debug logs msg at DebugLevel
+func (l *FieldLogger) Debug(msg string) {
+	l.log(DebugLevel, msg)
+}
+
+// Info logs msg at InfoLevel
+func (l *FieldLogger) Info(msg string) {
+	l.log(InfoLevel, msg)
+}
+
+// Warn logs msg at WarnLevel
+func (l *FieldLogger) Warn(msg string) {
+	l.log(WarnLevel, msg)
+}
+
+// Error logs msg at ErrorLevel
+func (l *FieldLogger) Error(msg string) {
+	l.log(ErrorLevel, msg)
+}
+
+// Log logs msg at the given level
+func (l *FieldLogger) Log(level Level, msg string) {
+	l.log(level, msg)
+}
+
+// WithField returns a Logger with key set to value
+func (l *FieldLogger) WithField(key string, value any) Logger {
+	return l.WithFields(Fields{key: value})
+}
+
+// WithFields returns a Logger with fields added, overriding existing keys
+func (l *FieldLogger) WithFields(fields Fields) Logger {
+	merged := make(Fields, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &FieldLogger{sink: l.sink, config: l.config, fields: merged, ctx: l.ctx}
+}
+
+// WithError returns a Logger with err stored under ErrorKey
+func (l *FieldLogger) WithError(err error) Logger {
+	return l.WithField(ErrorKey, err)
+}
+
+// WithContext returns a Logger bound to ctx, adding any fields stored with WithFields
+// Fields already set on the logger take precedence over context fields.
+func (l *FieldLogger) WithContext(ctx context.Context) Logger {
+	merged := make(Fields, len(l.fields))
+	for k, v := range FieldsFrom(ctx) {
+		merged[k] = v
+	}
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	return &FieldLogger{sink: l.sink, config: l.config, fields: merged, ctx: ctx}
+}
+
+// log emits an entry if the sink accepts the level and the sampler keeps it, after running the hooks
+// Every level method calls log directly so sinks can rely on a fixed call depth for caller reporting.
+func (l *FieldLogger) log(level Level, msg string) {
+	if !l.sink.Enabled(level) {
+		return
+	}
+	if l.config.Sampler != nil && !l.config.Sampler.Sample(level, msg) {
+		return
+	}
+
+	fields := l.fields
+	if len(l.config.Hooks) > 0 {
+		fields = make(Fields, len(l.fields))
+		for k, v := range l.fields {
+			fields[k] = v
+		}
+		for _, hook := range l.config.Hooks {
+			fields = hook(level, fields)
+		}
+	}
+
+	ctx := l.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	l.sink.Emit(ctx, level, msg, fields)
+}
+
+// nopSink discards everything
+type nopSink struct{}
+
+// Enabled implements Sink
+func (nopSink) Enabled(Level) bool { return false }
+
+// Emit implements Sink
+func (nopSink) Emit(context.Context, Level, string, Fields) {}
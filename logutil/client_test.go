@@ -0,0 +1,310 @@
+package logutil
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// recordedEntry is an entry captured by recordingSink
+type recordedEntry struct {
+	ctx    context.Context
+	level  Level
+	msg    string
+	fields Fields
+}
+
+// recordingSink captures emitted entries for assertions
+type recordingSink struct {
+	minLevel Level
+	entries  []recordedEntry
+}
+
+func (s *recordingSink) Enabled(level Level) bool { return level >= s.minLevel }
+
+func (s *recordingSink) Emit(ctx context.Context, level Level, msg string, fields Fields) {
+	s.entries = append(s.entries, recordedEntry{ctx: ctx, level: level, msg: msg, fields: fields})
+}
+
+// =================== Test Logger ===================
+
+func TestNewLogger(t *testing.T) {
+	if NewLogger(&recordingSink{}, nil) == nil {
+		t.Error("NewLogger() returned nil")
+	}
+	if NewNopLogger() == nil {
+		t.Error("NewNopLogger() returned nil")
+	}
+}
+
+func TestLevel_String(t *testing.T) {
+	tests := map[Level]string{
+		DebugLevel: "debug",
+		InfoLevel:  "info",
+		WarnLevel:  "warn",
+		ErrorLevel: "error",
+		Level(42):  "level(42)",
+	}
+	for level, expected := range tests {
+		if got := level.String(); got != expected {
+			t.Errorf("String() = %q, want %q", got, expected)
+		}
+	}
+}
+
+func TestLogger_Levels(t *testing.T) {
+	sink := &recordingSink{minLevel: InfoLevel}
+	logger := NewLogger(sink, nil)
+
+	logger.Debug("dropped")
+	logger.Info("info")
+	logger.Warn("warn")
+	logger.Error("error")
+	logger.Log(WarnLevel, "log")
+
+	if len(sink.entries) != 4 {
+		t.Fatalf("Expected 4 entries above the minimum level, got %d", len(sink.entries))
+	}
+	expected := []Level{InfoLevel, WarnLevel, ErrorLevel, WarnLevel}
+	for i, entry := range sink.entries {
+		if entry.level != expected[i] {
+			t.Errorf("entries[%d].level = %v, want %v", i, entry.level, expected[i])
+		}
+	}
+}
+
+func TestLogger_FieldChaining(t *testing.T) {
+	sink := &recordingSink{}
+	base := NewLogger(sink, nil).WithField("service", "api")
+	errBoom := errors.New("boom")
+
+	base.WithFields(Fields{"attempt": 2, "service": "worker"}).WithError(errBoom).Info("retrying")
+	base.Info("base")
+
+	first := sink.entries[0].fields
+	if first["service"] != "worker" || first["attempt"] != 2 || first[ErrorKey] != errBoom {
+		t.Errorf("Unexpected chained fields: %v", first)
+	}
+	if second := sink.entries[1].fields; len(second) != 1 || second["service"] != "api" {
+		t.Errorf("Expected base logger to be unchanged, got %v", second)
+	}
+}
+
+func TestLogger_ContextPropagation(t *testing.T) {
+	sink := &recordingSink{}
+	ctx := WithFields(context.Background(), Fields{"request_id": "abc", "service": "ctx"})
+	ctx = WithFields(ctx, Fields{"user": "u1"})
+
+	NewLogger(sink, nil).WithField("service", "api").WithContext(ctx).Info("handled")
+
+	entry := sink.entries[0]
+	if entry.ctx != ctx {
+		t.Error("Expected sink to receive the bound context")
+	}
+	if entry.fields["request_id"] != "abc" || entry.fields["user"] != "u1" {
+		t.Errorf("Expected context fields, got %v", entry.fields)
+	}
+	if entry.fields["service"] != "api" {
+		t.Errorf("Expected logger fields to win over context fields, got %v", entry.fields["service"])
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	sink := &recordingSink{}
+	ctx := NewContext(context.Background(), NewLogger(sink, nil))
+	ctx = WithFields(ctx, Fields{"request_id": "abc"})
+
+	FromContext(ctx).Info("from context")
+	if len(sink.entries) != 1 || sink.entries[0].fields["request_id"] != "abc" {
+		t.Errorf("Expected context logger with context fields, got %v", sink.entries)
+	}
+
+	// Missing logger falls back to a no-op logger
+	FromContext(context.Background()).Error("discarded")
+}
+
+func TestFieldsFrom(t *testing.T) {
+	base := WithFields(context.Background(), Fields{"request_id": "abc"})
+	child := WithFields(base, Fields{"user_id": "u1", "request_id": "def"})
+
+	if got := FieldsFrom(base); len(got) != 1 || got["request_id"] != "abc" {
+		t.Errorf("FieldsFrom(base) = %v, want only request_id abc", got)
+	}
+	if got := FieldsFrom(child); len(got) != 2 || got["request_id"] != "def" || got["user_id"] != "u1" {
+		t.Errorf("FieldsFrom(child) = %v, want request_id def and user_id u1", got)
+	}
+	if got := FieldsFrom(context.Background()); got != nil {
+		t.Errorf("FieldsFrom(empty) = %v, want nil", got)
+	}
+	//nolint:staticcheck // the deprecated names must keep working
+	if got := FieldsFromContext(ContextWithFields(nil, Fields{"a": 1})); got["a"] != 1 {
+		t.Errorf("FieldsFromContext(ContextWithFields()) = %v, want a=1", got)
+	}
+}
+
+// =================== Test Adapters ===================
+
+func TestLogrusLogger(t *testing.T) {
+	var buf bytes.Buffer
+	base := logrus.New()
+	base.SetOutput(&buf)
+	base.SetFormatter(&logrus.JSONFormatter{})
+	base.SetLevel(logrus.InfoLevel)
+
+	logger := NewLogrusLogger(base)
+	logger.Debug("dropped")
+	logger.WithFields(Fields{"attempt": 1}).WithError(errors.New("boom")).Warn("retrying")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 log line, got %d: %q", len(lines), buf.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Invalid JSON log line: %v", err)
+	}
+	if entry["level"] != "warning" || entry["msg"] != "retrying" || entry["attempt"] != float64(1) || entry["error"] != "boom" {
+		t.Errorf("Unexpected logrus entry: %v", entry)
+	}
+}
+
+func TestZapLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	logger := NewZapLogger(zap.New(core, zap.AddCaller()))
+
+	logger.Debug("dropped")
+	logger.WithFields(Fields{"b": 2, "a": 1}).Error("failed")
+
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.Level != zapcore.ErrorLevel || entry.Message != "failed" {
+		t.Errorf("Unexpected zap entry: %v %q", entry.Level, entry.Message)
+	}
+	if fields := entry.ContextMap(); fields["a"] != int64(1) || fields["b"] != int64(2) {
+		t.Errorf("Unexpected zap fields: %v", fields)
+	}
+	if !strings.HasSuffix(entry.Caller.File, "client_test.go") {
+		t.Errorf("Expected caller in test file, got %s", entry.Caller.File)
+	}
+}
+
+// =================== Test Sampling ===================
+
+func TestEveryNSampler(t *testing.T) {
+	sink := &recordingSink{}
+	logger := NewLogger(sink, &LoggerConfig{Sampler: NewEveryNSampler(3, InfoLevel)})
+
+	for i := 0; i < 7; i++ {
+		logger.Debug("Request failed, retrying")
+		logger.Warn("kept")
+	}
+
+	var debug, warn int
+	for _, entry := range sink.entries {
+		if entry.level == DebugLevel {
+			debug++
+		} else {
+			warn++
+		}
+	}
+	if debug != 3 { // entries 1, 4 and 7
+		t.Errorf("Expected 3 sampled debug entries, got %d", debug)
+	}
+	if warn != 7 {
+		t.Errorf("Expected entries above maxLevel to be kept, got %d", warn)
+	}
+}
+
+func TestEveryNSampler_PerMessage(t *testing.T) {
+	sampler := NewEveryNSampler(100, DebugLevel)
+	if !sampler.Sample(DebugLevel, "first message") || !sampler.Sample(DebugLevel, "second message") {
+		t.Error("Expected the first entry of each message to be kept")
+	}
+}
+
+func TestRateSampler(t *testing.T) {
+	sampler := NewRateSampler(2, WarnLevel).(*RateSampler)
+	current := time.Unix(1700000000, 0)
+	sampler.now = func() time.Time { return current }
+
+	results := []bool{
+		sampler.Sample(WarnLevel, "retry"),
+		sampler.Sample(WarnLevel, "retry"),
+		sampler.Sample(WarnLevel, "retry"),
+		sampler.Sample(ErrorLevel, "retry"),
+	}
+	expected := []bool{true, true, false, true}
+	for i := range expected {
+		if results[i] != expected[i] {
+			t.Errorf("Sample() #%d = %v, want %v", i+1, results[i], expected[i])
+		}
+	}
+
+	current = current.Add(time.Second)
+	if !sampler.Sample(WarnLevel, "retry") {
+		t.Error("Expected a new second to reset the budget")
+	}
+}
+
+// =================== Test Redaction ===================
+
+func TestRedactKeys(t *testing.T) {
+	sink := &recordingSink{}
+	logger := NewLogger(sink, &LoggerConfig{Hooks: []FieldHook{RedactKeys("password", "Authorization")}})
+
+	base := logger.WithFields(Fields{"Password": "hunter2", "authorization": "Bearer x", "user": "bob"})
+	base.Info("login")
+
+	fields := sink.entries[0].fields
+	if fields["Password"] != RedactedValue || fields["authorization"] != RedactedValue || fields["user"] != "bob" {
+		t.Errorf("Unexpected redacted fields: %v", fields)
+	}
+
+	// Hooks work on a copy, so the logger's own fields stay intact
+	base.WithField("extra", 1).Info("again")
+	if sink.entries[1].fields["Password"] != RedactedValue {
+		t.Error("Expected redaction to apply to every entry")
+	}
+}
+
+func TestRedactStrings(t *testing.T) {
+	sink := &recordingSink{}
+	redactor := RedactorFunc(func(s string) string { return strings.ReplaceAll(s, "secret-token", RedactedValue) })
+	logger := NewLogger(sink, &LoggerConfig{Hooks: []FieldHook{RedactStrings(redactor)}})
+
+	logger.WithFields(Fields{"url": "https://api?token=secret-token", "attempt": 1}).
+		WithError(errors.New("auth failed for secret-token")).
+		Warn("Request failed, retrying")
+
+	fields := sink.entries[0].fields
+	if fields["url"] != "https://api?token="+RedactedValue {
+		t.Errorf("Expected URL to be redacted, got %v", fields["url"])
+	}
+	if fields[ErrorKey] != "auth failed for "+RedactedValue {
+		t.Errorf("Expected error message to be redacted, got %v", fields[ErrorKey])
+	}
+	if fields["attempt"] != 1 {
+		t.Errorf("Expected non-string fields to be untouched, got %v", fields["attempt"])
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkLogger_DisabledLevel(b *testing.B) {
+	logger := NewLogger(&recordingSink{minLevel: ErrorLevel}, nil).WithField("service", "api")
+	for i := 0; i < b.N; i++ {
+		logger.Debug("dropped")
+	}
+}
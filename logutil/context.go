@@ -0,0 +1,69 @@
+package logutil
+
+import "context"
+
+// contextKey is the type of keys stored by this package in a context
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	fieldsKey
+)
+
+// NewContext returns a copy of ctx carrying logger
+func NewContext(ctx context.Context, logger Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext returns the Logger stored in ctx bound to ctx, or a no-op Logger if there is none
+func FromContext(ctx context.Context) Logger {
+	if ctx == nil {
+		return NewNopLogger()
+	}
+	logger, ok := ctx.Value(loggerKey).(Logger)
+	if !ok {
+		return NewNopLogger()
+	}
+	return logger.WithContext(ctx)
+}
+
+// WithFields returns a copy of ctx carrying fields (e.g. a request ID or user ID) merged over any fields
+// already stored. Every Logger bound with WithContext adds them, including the loggers httputil uses for
+// a request, so they are set once per request instead of passed to each log call.
+func WithFields(ctx context.Context, fields Fields) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	merged := make(Fields, len(fields))
+	for k, v := range FieldsFrom(ctx) {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsKey, merged)
+}
+
+// FieldsFrom returns the fields stored with WithFields, or nil
+// The map is shared by every context derived from ctx and must not be modified.
+func FieldsFrom(ctx context.Context) Fields {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey).(Fields)
+	return fields
+}
+
+// ContextWithFields returns a copy of ctx carrying fields merged over any fields already stored
+//
+// Deprecated: use WithFields.
+func ContextWithFields(ctx context.Context, fields Fields) context.Context {
+	return WithFields(ctx, fields)
+}
+
+// FieldsFromContext returns the fields stored with WithFields, or nil
+//
+// Deprecated: use FieldsFrom.
+func FieldsFromContext(ctx context.Context) Fields {
+	return FieldsFrom(ctx)
+}
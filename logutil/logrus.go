@@ -0,0 +1,50 @@
+package logutil
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+// LogrusSink writes entries to a logrus logger
+type LogrusSink struct {
+	logger *logrus.Logger
+}
+
+// NewLogrusLogger creates a Logger backed by logrus
+// Pass nil to use logrus.StandardLogger().
+func NewLogrusLogger(logger *logrus.Logger) Logger {
+	return NewLogger(NewLogrusSink(logger), nil)
+}
+
+// NewLogrusSink creates a Sink writing to logrus, for use with NewLogger and a LoggerConfig
+// Pass nil to use logrus.StandardLogger().
+func NewLogrusSink(logger *logrus.Logger) Sink {
+	if logger == nil {
+		logger = logrus.StandardLogger()
+	}
+	return &LogrusSink{logger: logger}
+}
+
+// Enabled implements Sink
+func (s *LogrusSink) Enabled(level Level) bool {
+	return s.logger.IsLevelEnabled(toLogrusLevel(level))
+}
+
+// Emit implements Sink
+func (s *LogrusSink) Emit(ctx context.Context, level Level, msg string, fields Fields) {
+	s.logger.WithContext(ctx).WithFields(logrus.Fields(fields)).Log(toLogrusLevel(level), msg)
+}
+
+// toLogrusLevel maps a Level to the equivalent logrus level
+func toLogrusLevel(level Level) logrus.Level {
+	switch level {
+	case DebugLevel:
+		return logrus.DebugLevel
+	case WarnLevel:
+		return logrus.WarnLevel
+	case ErrorLevel:
+		return logrus.ErrorLevel
+	}
+	return logrus.InfoLevel
+}
@@ -0,0 +1,56 @@
+package logutil
+
+import "strings"
+
+// RedactedValue replaces redacted field values
+const RedactedValue = "[REDACTED]"
+
+// FieldHook transforms the fields of an entry before it is emitted
+// Hooks receive a copy of the logger's fields and may modify and return it.
+type FieldHook func(level Level, fields Fields) Fields
+
+// Redactor masks sensitive content inside a string, e.g. tokens embedded in URLs
+// Any string redaction helper can be plugged into the logging pipeline via RedactStrings.
+type Redactor interface {
+	Redact(s string) string
+}
+
+// RedactorFunc adapts a function to the Redactor interface
+type RedactorFunc func(s string) string
+
+// Redact implements Redactor
+func (f RedactorFunc) Redact(s string) string {
+	return f(s)
+}
+
+// RedactKeys returns a hook replacing the values of the given field names (case-insensitive)
+func RedactKeys(keys ...string) FieldHook {
+	sensitive := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		sensitive[strings.ToLower(key)] = true
+	}
+	return func(_ Level, fields Fields) Fields {
+		for k := range fields {
+			if sensitive[strings.ToLower(k)] {
+				fields[k] = RedactedValue
+			}
+		}
+		return fields
+	}
+}
+
+// RedactStrings returns a hook passing every string and error field value through r
+// Error values are replaced by their redacted message.
+func RedactStrings(r Redactor) FieldHook {
+	return func(_ Level, fields Fields) Fields {
+		for k, v := range fields {
+			switch value := v.(type) {
+			case string:
+				fields[k] = r.Redact(value)
+			case error:
+				fields[k] = r.Redact(value.Error())
+			}
+		}
+		return fields
+	}
+}
@@ -0,0 +1,89 @@
+package logutil
+
+import (
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// samplerBuckets bounds sampler memory; messages are hashed into this many counters
+const samplerBuckets = 4096
+
+// Sampler decides whether an entry is emitted
+// Samplers are consulted before hooks run, so dropped entries cost almost nothing.
+type Sampler interface {
+	Sample(level Level, msg string) bool
+}
+
+// EveryNSampler keeps the first and then every Nth entry per message at or below MaxLevel
+type EveryNSampler struct {
+	n        uint64
+	maxLevel Level
+	counts   [samplerBuckets]uint64
+}
+
+// NewEveryNSampler creates a sampler keeping 1 in n entries of each message at or below maxLevel
+// Entries above maxLevel are always kept; n <= 1 keeps everything.
+func NewEveryNSampler(n int, maxLevel Level) Sampler {
+	if n < 1 {
+		n = 1
+	}
+	return &EveryNSampler{n: uint64(n), maxLevel: maxLevel}
+}
+
+// Sample implements Sampler
+func (s *EveryNSampler) Sample(level Level, msg string) bool {
+	if level > s.maxLevel {
+		return true
+	}
+	count := atomic.AddUint64(&s.counts[bucketOf(msg)], 1)
+	return (count-1)%s.n == 0
+}
+
+// RateSampler keeps at most PerSecond entries per message per second at or below MaxLevel
+type RateSampler struct {
+	perSecond int
+	maxLevel  Level
+	now       func() time.Time
+
+	mu      sync.Mutex
+	windows [samplerBuckets]rateWindow
+}
+
+// rateWindow counts entries seen during one second
+type rateWindow struct {
+	second int64
+	count  int
+}
+
+// NewRateSampler creates a sampler keeping at most perSecond entries of each message per second
+// at or below maxLevel. Entries above maxLevel are always kept; perSecond <= 0 keeps everything.
+func NewRateSampler(perSecond int, maxLevel Level) Sampler {
+	return &RateSampler{perSecond: perSecond, maxLevel: maxLevel, now: time.Now}
+}
+
+// Sample implements Sampler
+func (s *RateSampler) Sample(level Level, msg string) bool {
+	if level > s.maxLevel || s.perSecond <= 0 {
+		return true
+	}
+	second := s.now().Unix()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	window := &s.windows[bucketOf(msg)]
+	if window.second != second {
+		window.second, window.count = second, 0
+	}
+	window.count++
+	return window.count <= s.perSecond
+}
+
+// bucketOf hashes a message into a sampler bucket
+// Distinct messages may share a bucket, which only makes sampling slightly more aggressive.
+func bucketOf(msg string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(msg))
+	return h.Sum32() % samplerBuckets
+}
@@ -0,0 +1,63 @@
+//go:build go1.21
+
+package logutil
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+)
+
+// slogCallerSkip skips runtime.Callers, Emit, log and the level method so the record points at the caller
+const slogCallerSkip = 4
+
+// SlogSink writes entries to a log/slog logger
+type SlogSink struct {
+	logger *slog.Logger
+}
+
+// NewSlogLogger creates a Logger backed by log/slog (requires Go 1.21+)
+// Pass nil to use slog.Default().
+func NewSlogLogger(logger *slog.Logger) Logger {
+	return NewLogger(NewSlogSink(logger), nil)
+}
+
+// NewSlogSink creates a Sink writing to log/slog, for use with NewLogger and a LoggerConfig
+// Pass nil to use slog.Default().
+func NewSlogSink(logger *slog.Logger) Sink {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SlogSink{logger: logger}
+}
+
+// Enabled implements Sink
+func (s *SlogSink) Enabled(level Level) bool {
+	return s.logger.Enabled(context.Background(), toSlogLevel(level))
+}
+
+// Emit implements Sink
+func (s *SlogSink) Emit(ctx context.Context, level Level, msg string, fields Fields) {
+	var pcs [1]uintptr
+	runtime.Callers(slogCallerSkip, pcs[:])
+
+	record := slog.NewRecord(time.Now(), toSlogLevel(level), msg, pcs[0])
+	for _, key := range sortedKeys(fields) {
+		record.AddAttrs(slog.Any(key, fields[key]))
+	}
+	_ = s.logger.Handler().Handle(ctx, record)
+}
+
+// toSlogLevel maps a Level to the equivalent slog level
+func toSlogLevel(level Level) slog.Level {
+	switch level {
+	case DebugLevel:
+		return slog.LevelDebug
+	case WarnLevel:
+		return slog.LevelWarn
+	case ErrorLevel:
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
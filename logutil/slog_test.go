@@ -0,0 +1,36 @@
+//go:build go1.21
+
+package logutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogLogger(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo, AddSource: true})
+	logger := NewSlogLogger(slog.New(handler))
+
+	logger.Debug("dropped")
+	logger.WithFields(Fields{"attempt": 1}).Warn("retrying")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("Expected 1 log line, got %d: %q", len(lines), buf.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Invalid JSON log line: %v", err)
+	}
+	if entry["level"] != "WARN" || entry["msg"] != "retrying" || entry["attempt"] != float64(1) {
+		t.Errorf("Unexpected slog entry: %v", entry)
+	}
+	source, _ := entry["source"].(map[string]any)
+	if file, _ := source["file"].(string); !strings.HasSuffix(file, "slog_test.go") {
+		t.Errorf("Expected source in test file, got %v", entry["source"])
+	}
+}
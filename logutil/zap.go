@@ -0,0 +1,73 @@
+package logutil
+
+import (
+	"context"
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// zapCallerSkip skips the facade frames (level method, log, Emit) when zap reports the caller
+const zapCallerSkip = 3
+
+// ZapSink writes entries to a zap logger
+type ZapSink struct {
+	logger *zap.Logger
+}
+
+// NewZapLogger creates a Logger backed by zap
+// Pass nil to use zap.L().
+func NewZapLogger(logger *zap.Logger) Logger {
+	return NewLogger(NewZapSink(logger), nil)
+}
+
+// NewZapSink creates a Sink writing to zap, for use with NewLogger and a LoggerConfig
+// Pass nil to use zap.L().
+func NewZapSink(logger *zap.Logger) Sink {
+	if logger == nil {
+		logger = zap.L()
+	}
+	return &ZapSink{logger: logger.WithOptions(zap.AddCallerSkip(zapCallerSkip))}
+}
+
+// Enabled implements Sink
+func (s *ZapSink) Enabled(level Level) bool {
+	return s.logger.Core().Enabled(toZapLevel(level))
+}
+
+// Emit implements Sink
+func (s *ZapSink) Emit(_ context.Context, level Level, msg string, fields Fields) {
+	entry := s.logger.Check(toZapLevel(level), msg)
+	if entry == nil {
+		return
+	}
+	zapFields := make([]zap.Field, 0, len(fields))
+	for _, key := range sortedKeys(fields) {
+		zapFields = append(zapFields, zap.Any(key, fields[key]))
+	}
+	entry.Write(zapFields...)
+}
+
+// toZapLevel maps a Level to the equivalent zap level
+func toZapLevel(level Level) zapcore.Level {
+	switch level {
+	case DebugLevel:
+		return zapcore.DebugLevel
+	case WarnLevel:
+		return zapcore.WarnLevel
+	case ErrorLevel:
+		return zapcore.ErrorLevel
+	}
+	return zapcore.InfoLevel
+}
+
+// sortedKeys returns field names in a stable order for backends that preserve it
+func sortedKeys(fields Fields) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
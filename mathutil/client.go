@@ -0,0 +1,152 @@
+package mathutil
+
+import (
+	"fmt"
+	"math"
+)
+
+// MathClient defines the interface for numeric utility operations
+type MathClient interface {
+	// Overflow-checked conversions
+	Int64ToInt(v int64) (int, error)
+	UintToInt(v uint) (int, error)
+	Uint64ToInt64(v uint64) (int64, error)
+	Float64ToInt(v float64) (int, error)
+	Float64ToInt64(v float64) (int64, error)
+
+	// Bounding and rounding
+	Clamp(v, min, max float64) float64
+	ClampInt(v, min, max int) int
+	RoundTo(n float64, decimals int) float64
+
+	// Ratios
+	Percent(part, total float64) float64
+	RatioSafe(dividend, divisor float64) float64
+
+	// Descriptive statistics
+	Mean(values []float64) (float64, error)
+	Median(values []float64) (float64, error)
+	Mode(values []float64) ([]float64, error)
+	StdDev(values []float64) (float64, error)
+	Percentile(values []float64, p float64) (float64, error)
+}
+
+// MathUtil provides safe numeric conversions and helpers
+type MathUtil struct{}
+
+// NewMathUtil creates a new instance of MathUtil
+func NewMathUtil() MathClient {
+	return &MathUtil{}
+}
+
+// Int64ToInt converts v to int, returning ErrOverflow if it does not fit (32-bit platforms)
+func (m *MathUtil) Int64ToInt(v int64) (int, error) {
+	if v < math.MinInt || v > math.MaxInt {
+		return 0, fmt.Errorf("%w: %d overflows int", ErrOverflow, v)
+	}
+	return int(v), nil
+}
+
+// UintToInt converts v to int, returning ErrOverflow if it exceeds math.MaxInt
+func (m *MathUtil) UintToInt(v uint) (int, error) {
+	if v > math.MaxInt {
+		return 0, fmt.Errorf("%w: %d overflows int", ErrOverflow, v)
+	}
+	return int(v), nil
+}
+
+// Uint64ToInt64 converts v to int64, returning ErrOverflow if it exceeds math.MaxInt64
+func (m *MathUtil) Uint64ToInt64(v uint64) (int64, error) {
+	if v > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %d overflows int64", ErrOverflow, v)
+	}
+	return int64(v), nil
+}
+
+// Float64ToInt converts v to int, rejecting NaN/Inf, fractional values and values out of range
+func (m *MathUtil) Float64ToInt(v float64) (int, error) {
+	if err := checkIntegral(v); err != nil {
+		return 0, err
+	}
+	// float64(math.MinInt) is exact; -float64(math.MinInt) is the first value past math.MaxInt
+	if v < float64(math.MinInt) || v >= -float64(math.MinInt) {
+		return 0, fmt.Errorf("%w: %g overflows int", ErrOverflow, v)
+	}
+	return int(v), nil
+}
+
+// Float64ToInt64 converts v to int64, rejecting NaN/Inf, fractional values and values out of range
+func (m *MathUtil) Float64ToInt64(v float64) (int64, error) {
+	if err := checkIntegral(v); err != nil {
+		return 0, err
+	}
+	if v < math.MinInt64 || v >= -math.MinInt64 {
+		return 0, fmt.Errorf("%w: %g overflows int64", ErrOverflow, v)
+	}
+	return int64(v), nil
+}
+
+// Clamp limits v to the range [min, max]
+func (m *MathUtil) Clamp(v, min, max float64) float64 {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// ClampInt limits v to the range [min, max]
+func (m *MathUtil) ClampInt(v, min, max int) int {
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// RoundTo rounds n half away from zero to the given number of decimal places
+// Negative decimals round to tens, hundreds, etc. NaN and ±Inf are returned unchanged.
+func (m *MathUtil) RoundTo(n float64, decimals int) float64 {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return n
+	}
+	factor := math.Pow(10, float64(decimals))
+	scaled := n * factor
+	if math.IsInf(scaled, 0) {
+		return n // more decimals than float64 can hold; n is already as precise as it gets
+	}
+	return math.Round(scaled) / factor
+}
+
+// Percent returns part as a percentage of total, or 0 when total is 0
+func (m *MathUtil) Percent(part, total float64) float64 {
+	return m.RatioSafe(part, total) * 100
+}
+
+// RatioSafe divides dividend by divisor, returning 0 instead of ±Inf or NaN
+// when the divisor is 0 or either operand is not finite.
+func (m *MathUtil) RatioSafe(dividend, divisor float64) float64 {
+	if divisor == 0 {
+		return 0
+	}
+	ratio := dividend / divisor
+	if math.IsNaN(ratio) || math.IsInf(ratio, 0) {
+		return 0
+	}
+	return ratio
+}
+
+// checkIntegral rejects values that cannot be converted to an integer without loss
+func checkIntegral(v float64) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fmt.Errorf("%w: %g", ErrNotFinite, v)
+	}
+	if v != math.Trunc(v) {
+		return fmt.Errorf("%w: %g", ErrNotInteger, v)
+	}
+	return nil
+}
@@ -0,0 +1,315 @@
+package mathutil
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNewMathUtil(t *testing.T) {
+	util := NewMathUtil()
+	if util == nil {
+		t.Error("NewMathUtil() returned nil")
+	}
+}
+
+// =================== Test Conversions ===================
+
+func TestInt64ToInt(t *testing.T) {
+	util := NewMathUtil()
+
+	if v, err := util.Int64ToInt(42); err != nil || v != 42 {
+		t.Errorf("Int64ToInt(42) = %v, %v; want 42, nil", v, err)
+	}
+	if math.MaxInt == math.MaxInt32 {
+		if _, err := util.Int64ToInt(math.MaxInt64); !errors.Is(err, ErrOverflow) {
+			t.Errorf("Int64ToInt(MaxInt64) error = %v, want ErrOverflow", err)
+		}
+	}
+}
+
+func TestUintToInt(t *testing.T) {
+	util := NewMathUtil()
+
+	tests := []struct {
+		name      string
+		input     uint
+		expected  int
+		expectErr bool
+	}{
+		{"zero", 0, 0, false},
+		{"max int", uint(math.MaxInt), math.MaxInt, false},
+		{"overflow", uint(math.MaxInt) + 1, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := util.UintToInt(tt.input)
+			if (err != nil) != tt.expectErr {
+				t.Errorf("UintToInt(%v) error = %v, expectErr %v", tt.input, err, tt.expectErr)
+			}
+			if result != tt.expected {
+				t.Errorf("UintToInt(%v) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestUint64ToInt64(t *testing.T) {
+	util := NewMathUtil()
+
+	if v, err := util.Uint64ToInt64(math.MaxInt64); err != nil || v != math.MaxInt64 {
+		t.Errorf("Uint64ToInt64(MaxInt64) = %v, %v", v, err)
+	}
+	if _, err := util.Uint64ToInt64(math.MaxUint64); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Uint64ToInt64(MaxUint64) error = %v, want ErrOverflow", err)
+	}
+}
+
+func TestFloat64ToInt64(t *testing.T) {
+	util := NewMathUtil()
+
+	tests := []struct {
+		name     string
+		input    float64
+		expected int64
+		err      error
+	}{
+		{"integral", 42.0, 42, nil},
+		{"negative", -7.0, -7, nil},
+		{"min int64", math.MinInt64, math.MinInt64, nil},
+		{"fraction", 42.5, 0, ErrNotInteger},
+		{"2^63 overflows", math.Exp2(63), 0, ErrOverflow},
+		{"large negative", -1e19, 0, ErrOverflow},
+		{"NaN", math.NaN(), 0, ErrNotFinite},
+		{"+Inf", math.Inf(1), 0, ErrNotFinite},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := util.Float64ToInt64(tt.input)
+			if !errors.Is(err, tt.err) || (tt.err == nil && err != nil) {
+				t.Errorf("Float64ToInt64(%v) error = %v, want %v", tt.input, err, tt.err)
+			}
+			if result != tt.expected {
+				t.Errorf("Float64ToInt64(%v) = %v, want %v", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFloat64ToInt(t *testing.T) {
+	util := NewMathUtil()
+
+	if v, err := util.Float64ToInt(-3); err != nil || v != -3 {
+		t.Errorf("Float64ToInt(-3) = %v, %v; want -3, nil", v, err)
+	}
+	if _, err := util.Float64ToInt(1e300); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Float64ToInt(1e300) error = %v, want ErrOverflow", err)
+	}
+	if _, err := util.Float64ToInt(0.1); !errors.Is(err, ErrNotInteger) {
+		t.Errorf("Float64ToInt(0.1) error = %v, want ErrNotInteger", err)
+	}
+}
+
+// =================== Test Helpers ===================
+
+func TestClamp(t *testing.T) {
+	util := NewMathUtil()
+
+	tests := []struct {
+		v, min, max, expected float64
+	}{
+		{5, 0, 10, 5},
+		{-1, 0, 10, 0},
+		{11, 0, 10, 10},
+	}
+	for _, tt := range tests {
+		if got := util.Clamp(tt.v, tt.min, tt.max); got != tt.expected {
+			t.Errorf("Clamp(%v, %v, %v) = %v, want %v", tt.v, tt.min, tt.max, got, tt.expected)
+		}
+	}
+
+	if got := util.ClampInt(15, 1, 10); got != 10 {
+		t.Errorf("ClampInt(15, 1, 10) = %v, want 10", got)
+	}
+	if got := util.ClampInt(-5, 1, 10); got != 1 {
+		t.Errorf("ClampInt(-5, 1, 10) = %v, want 1", got)
+	}
+}
+
+func TestRoundTo(t *testing.T) {
+	util := NewMathUtil()
+
+	tests := []struct {
+		name     string
+		n        float64
+		decimals int
+		expected float64
+	}{
+		{"two decimals", 3.14159, 2, 3.14},
+		{"half away from zero", 2.345, 2, 2.35},
+		{"negative half", -2.5, 0, -3},
+		{"zero decimals", 2.5, 0, 3},
+		{"negative decimals", 1234.5, -2, 1200},
+		{"huge decimals", 1e300, 100, 1e300},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := util.RoundTo(tt.n, tt.decimals); math.Abs(got-tt.expected) > 1e-9 {
+				t.Errorf("RoundTo(%v, %d) = %v, want %v", tt.n, tt.decimals, got, tt.expected)
+			}
+		})
+	}
+
+	if got := util.RoundTo(math.NaN(), 2); !math.IsNaN(got) {
+		t.Errorf("RoundTo(NaN) = %v, want NaN", got)
+	}
+}
+
+func TestPercentAndRatioSafe(t *testing.T) {
+	util := NewMathUtil()
+
+	if got := util.Percent(25, 200); got != 12.5 {
+		t.Errorf("Percent(25, 200) = %v, want 12.5", got)
+	}
+	if got := util.Percent(5, 0); got != 0 {
+		t.Errorf("Percent(5, 0) = %v, want 0", got)
+	}
+	if got := util.RatioSafe(1, 4); got != 0.25 {
+		t.Errorf("RatioSafe(1, 4) = %v, want 0.25", got)
+	}
+	if got := util.RatioSafe(math.Inf(1), 2); got != 0 {
+		t.Errorf("RatioSafe(+Inf, 2) = %v, want 0", got)
+	}
+	if got := util.RatioSafe(math.MaxFloat64, 1e-10); got != 0 {
+		t.Errorf("RatioSafe overflow = %v, want 0", got)
+	}
+}
+
+// =================== Test Statistics ===================
+
+func TestStatistics(t *testing.T) {
+	util := NewMathUtil()
+	values := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+
+	if mean, err := util.Mean(values); err != nil || mean != 5 {
+		t.Errorf("Mean() = %v, %v; want 5, nil", mean, err)
+	}
+	if median, err := util.Median(values); err != nil || median != 4.5 {
+		t.Errorf("Median() = %v, %v; want 4.5, nil", median, err)
+	}
+	if stddev, err := util.StdDev(values); err != nil || stddev != 2 {
+		t.Errorf("StdDev() = %v, %v; want 2, nil", stddev, err)
+	}
+	if modes, err := util.Mode(values); err != nil || len(modes) != 1 || modes[0] != 4 {
+		t.Errorf("Mode() = %v, %v; want [4], nil", modes, err)
+	}
+	if modes, _ := util.Mode([]float64{3, 1, 1, 3}); len(modes) != 2 || modes[0] != 1 || modes[1] != 3 {
+		t.Errorf("Mode() with tie = %v, want [1 3]", modes)
+	}
+}
+
+func TestStatistics_Empty(t *testing.T) {
+	util := NewMathUtil()
+
+	if _, err := util.Mean(nil); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("Mean(nil) error = %v, want ErrEmptyInput", err)
+	}
+	if _, err := util.Median(nil); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("Median(nil) error = %v, want ErrEmptyInput", err)
+	}
+	if _, err := util.Mode(nil); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("Mode(nil) error = %v, want ErrEmptyInput", err)
+	}
+	if _, err := util.StdDev(nil); !errors.Is(err, ErrEmptyInput) {
+		t.Errorf("StdDev(nil) error = %v, want ErrEmptyInput", err)
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	util := NewMathUtil()
+	values := []float64{15, 20, 35, 40, 50}
+
+	tests := []struct {
+		p        float64
+		expected float64
+	}{
+		{0, 15},
+		{25, 20},
+		{50, 35},
+		{90, 46},
+		{100, 50},
+	}
+	for _, tt := range tests {
+		if got, err := util.Percentile(values, tt.p); err != nil || math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("Percentile(%v) = %v, %v; want %v", tt.p, got, err, tt.expected)
+		}
+	}
+
+	if _, err := util.Percentile(values, 101); err == nil {
+		t.Error("Expected error for percentile above 100")
+	}
+	if values[0] != 15 || values[4] != 50 {
+		t.Error("Percentile() must not reorder its input")
+	}
+}
+
+func TestWelford(t *testing.T) {
+	values := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+	acc := NewWelford()
+	for _, v := range values {
+		acc.Add(v)
+	}
+
+	if acc.Count() != 8 || acc.Mean() != 5 || acc.Variance() != 4 || acc.StdDev() != 2 {
+		t.Errorf("Unexpected stats: count=%d mean=%v var=%v std=%v", acc.Count(), acc.Mean(), acc.Variance(), acc.StdDev())
+	}
+	if math.Abs(acc.SampleVariance()-32.0/7) > 1e-12 {
+		t.Errorf("SampleVariance() = %v, want %v", acc.SampleVariance(), 32.0/7)
+	}
+	if acc.Min() != 2 || acc.Max() != 9 {
+		t.Errorf("Min/Max = %v/%v, want 2/9", acc.Min(), acc.Max())
+	}
+
+	var empty Welford
+	if empty.Variance() != 0 || empty.SampleVariance() != 0 || empty.Mean() != 0 {
+		t.Error("Expected zero stats for empty accumulator")
+	}
+}
+
+func TestWelford_Merge(t *testing.T) {
+	var left, right, all Welford
+	for i, v := range []float64{1, 8, 3, 12, 5, 7, 2, 10} {
+		all.Add(v)
+		if i < 3 {
+			left.Add(v)
+		} else {
+			right.Add(v)
+		}
+	}
+	left.Merge(&right)
+
+	if left.Count() != all.Count() || math.Abs(left.Mean()-all.Mean()) > 1e-12 || math.Abs(left.Variance()-all.Variance()) > 1e-12 {
+		t.Errorf("Merged stats differ: got mean=%v var=%v, want mean=%v var=%v", left.Mean(), left.Variance(), all.Mean(), all.Variance())
+	}
+	if left.Min() != 1 || left.Max() != 12 {
+		t.Errorf("Merged Min/Max = %v/%v, want 1/12", left.Min(), left.Max())
+	}
+
+	var empty Welford
+	empty.Merge(&all)
+	if empty.Count() != all.Count() {
+		t.Error("Expected merge into empty accumulator to copy stats")
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkWelford_Add(b *testing.B) {
+	var acc Welford
+	for i := 0; i < b.N; i++ {
+		acc.Add(float64(i))
+	}
+}
@@ -0,0 +1,15 @@
+package mathutil
+
+import "errors"
+
+// ErrOverflow is returned when a value does not fit in the target type
+var ErrOverflow = errors.New("value out of range")
+
+// ErrNotInteger is returned when converting a float with a fractional part to an integer type
+var ErrNotInteger = errors.New("value is not an integer")
+
+// ErrNotFinite is returned when converting NaN or ±Inf to an integer type
+var ErrNotFinite = errors.New("value is not finite")
+
+// ErrEmptyInput is returned when a statistic is requested for no values
+var ErrEmptyInput = errors.New("no values provided")
@@ -0,0 +1,178 @@
+package mathutil
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Mean returns the arithmetic mean of values
+func (m *MathUtil) Mean(values []float64) (float64, error) {
+	if len(values) == 0 {
+		return 0, ErrEmptyInput
+	}
+	var acc Welford
+	for _, v := range values {
+		acc.Add(v)
+	}
+	return acc.Mean(), nil
+}
+
+// Median returns the middle value of values, averaging the two middle values for even lengths
+func (m *MathUtil) Median(values []float64) (float64, error) {
+	return m.Percentile(values, 50)
+}
+
+// Mode returns the most frequent values in ascending order (several when tied)
+func (m *MathUtil) Mode(values []float64) ([]float64, error) {
+	if len(values) == 0 {
+		return nil, ErrEmptyInput
+	}
+	counts := make(map[float64]int, len(values))
+	best := 0
+	for _, v := range values {
+		counts[v]++
+		if counts[v] > best {
+			best = counts[v]
+		}
+	}
+	var modes []float64
+	for v, count := range counts {
+		if count == best {
+			modes = append(modes, v)
+		}
+	}
+	sort.Float64s(modes)
+	return modes, nil
+}
+
+// StdDev returns the population standard deviation of values
+func (m *MathUtil) StdDev(values []float64) (float64, error) {
+	if len(values) == 0 {
+		return 0, ErrEmptyInput
+	}
+	var acc Welford
+	for _, v := range values {
+		acc.Add(v)
+	}
+	return acc.StdDev(), nil
+}
+
+// Percentile returns the p-th percentile (0-100) of values using linear interpolation between ranks
+// values is not modified.
+func (m *MathUtil) Percentile(values []float64, p float64) (float64, error) {
+	if len(values) == 0 {
+		return 0, ErrEmptyInput
+	}
+	if math.IsNaN(p) || p < 0 || p > 100 {
+		return 0, fmt.Errorf("percentile must be between 0 and 100, got %g", p)
+	}
+
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	if lower == upper {
+		return sorted[lower], nil
+	}
+	fraction := rank - float64(lower)
+	return sorted[lower] + (sorted[upper]-sorted[lower])*fraction, nil
+}
+
+// Welford accumulates count, mean, variance, min and max in a single pass without storing samples
+// The zero value is ready to use. A Welford is not safe for concurrent use.
+type Welford struct {
+	count int64
+	mean  float64
+	m2    float64
+	min   float64
+	max   float64
+}
+
+// NewWelford creates an empty accumulator
+func NewWelford() *Welford {
+	return &Welford{}
+}
+
+// Add records a sample
+func (w *Welford) Add(x float64) {
+	w.count++
+	if w.count == 1 {
+		w.min, w.max = x, x
+	} else {
+		if x < w.min {
+			w.min = x
+		}
+		if x > w.max {
+			w.max = x
+		}
+	}
+	delta := x - w.mean
+	w.mean += delta / float64(w.count)
+	w.m2 += delta * (x - w.mean)
+}
+
+// Merge combines the samples of other into w, e.g. to aggregate per-worker accumulators
+func (w *Welford) Merge(other *Welford) {
+	if other == nil || other.count == 0 {
+		return
+	}
+	if w.count == 0 {
+		*w = *other
+		return
+	}
+	total := w.count + other.count
+	delta := other.mean - w.mean
+	w.m2 += other.m2 + delta*delta*float64(w.count)*float64(other.count)/float64(total)
+	w.mean += delta * float64(other.count) / float64(total)
+	w.count = total
+	if other.min < w.min {
+		w.min = other.min
+	}
+	if other.max > w.max {
+		w.max = other.max
+	}
+}
+
+// Count returns the number of samples
+func (w *Welford) Count() int64 {
+	return w.count
+}
+
+// Mean returns the running mean, or 0 with no samples
+func (w *Welford) Mean() float64 {
+	return w.mean
+}
+
+// Variance returns the population variance, or 0 with no samples
+func (w *Welford) Variance() float64 {
+	if w.count == 0 {
+		return 0
+	}
+	return w.m2 / float64(w.count)
+}
+
+// SampleVariance returns the sample (n-1) variance, or 0 with fewer than two samples
+func (w *Welford) SampleVariance() float64 {
+	if w.count < 2 {
+		return 0
+	}
+	return w.m2 / float64(w.count-1)
+}
+
+// StdDev returns the population standard deviation
+func (w *Welford) StdDev() float64 {
+	return math.Sqrt(w.Variance())
+}
+
+// Min returns the smallest sample, or 0 with no samples
+func (w *Welford) Min() float64 {
+	return w.min
+}
+
+// Max returns the largest sample, or 0 with no samples
+func (w *Welford) Max() float64 {
+	return w.max
+}
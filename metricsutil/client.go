@@ -0,0 +1,151 @@
+// Package metricsutil records Prometheus metrics for the other packages in this module.
+package metricsutil
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/httputil"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// HTTPMetricsConfig configures the collectors created by NewHTTPMetrics
+type HTTPMetricsConfig struct {
+	// Metric name prefix, e.g. "billing" for billing_http_client_requests_total
+	Namespace string
+
+	// Second name component (default "http_client")
+	Subsystem string
+
+	// Histogram buckets in seconds (default prometheus.DefBuckets)
+	Buckets []float64
+
+	// Labels added to every series, e.g. {"upstream": "payments"}
+	ConstLabels prometheus.Labels
+
+	// Where the collectors are registered (default prometheus.DefaultRegisterer, served by promhttp.Handler)
+	Registerer prometheus.Registerer
+}
+
+// DefaultHTTPMetricsConfig returns the default configuration
+func DefaultHTTPMetricsConfig() *HTTPMetricsConfig {
+	return &HTTPMetricsConfig{
+		Subsystem:  "http_client",
+		Buckets:    prometheus.DefBuckets,
+		Registerer: prometheus.DefaultRegisterer,
+	}
+}
+
+// HTTPMetrics records request counts, latencies and retries of httputil clients, labelled by method and host
+// Every attempt is counted, so a request that succeeds on its third try adds two retries and three
+// requests. Transport errors are counted with the code label "error".
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec   // method, host, code
+	duration *prometheus.HistogramVec // method, host
+	retries  *prometheus.CounterVec   // method, host
+	inFlight *prometheus.GaugeVec     // host
+}
+
+// NewHTTPMetrics creates and registers the HTTP client collectors
+// Pass nil for config to use all defaults, or pass config with only the properties you want to override.
+// Registering twice with the same registerer and names reuses the collectors already registered, so
+// several clients may share them.
+func NewHTTPMetrics(config *HTTPMetricsConfig) (*HTTPMetrics, error) {
+	defaults := DefaultHTTPMetricsConfig()
+	if config != nil {
+		defaults.Namespace = config.Namespace
+		defaults.ConstLabels = config.ConstLabels
+		if config.Subsystem != "" {
+			defaults.Subsystem = config.Subsystem
+		}
+		if config.Buckets != nil {
+			defaults.Buckets = config.Buckets
+		}
+		if config.Registerer != nil {
+			defaults.Registerer = config.Registerer
+		}
+	}
+
+	opts := func(name, help string) prometheus.Opts {
+		return prometheus.Opts{Namespace: defaults.Namespace, Subsystem: defaults.Subsystem, Name: name, Help: help, ConstLabels: defaults.ConstLabels}
+	}
+	m := &HTTPMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts(opts("requests_total",
+			"HTTP request attempts by method, host and status code.")), []string{"method", "host", "code"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace:   defaults.Namespace,
+			Subsystem:   defaults.Subsystem,
+			Name:        "request_duration_seconds",
+			Help:        "Latency of HTTP request attempts until response headers arrive.",
+			ConstLabels: defaults.ConstLabels,
+			Buckets:     defaults.Buckets,
+		}, []string{"method", "host"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts(opts("retries_total",
+			"HTTP request attempts after the first for the same request.")), []string{"method", "host"}),
+		inFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts(opts("in_flight_requests",
+			"HTTP request attempts waiting for response headers.")), []string{"host"}),
+	}
+
+	var err error
+	if m.requests, err = register(defaults.Registerer, m.requests); err != nil {
+		return nil, err
+	}
+	if m.duration, err = register(defaults.Registerer, m.duration); err != nil {
+		return nil, err
+	}
+	if m.retries, err = register(defaults.Registerer, m.retries); err != nil {
+		return nil, err
+	}
+	if m.inFlight, err = register(defaults.Registerer, m.inFlight); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// register adds c to r, returning the existing collector if an identical one is already registered
+func register[C prometheus.Collector](r prometheus.Registerer, c C) (C, error) {
+	if err := r.Register(c); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			if existing, ok := already.ExistingCollector.(C); ok {
+				return existing, nil
+			}
+		}
+		return c, err
+	}
+	return c, nil
+}
+
+// Middleware returns an httputil.Middleware recording every attempt, installed with HTTPClient.Use
+// The latency covers middleware registered after it and the round trip until response headers arrive.
+func (m *HTTPMetrics) Middleware() httputil.Middleware {
+	return func(next httputil.RoundTripFunc) httputil.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			host := req.URL.Host
+			if httputil.Attempt(req.Context()) > 0 {
+				m.retries.WithLabelValues(req.Method, host).Inc()
+			}
+
+			inFlight := m.inFlight.WithLabelValues(host)
+			inFlight.Inc()
+			start := time.Now()
+			resp, err := next(req)
+			m.duration.WithLabelValues(req.Method, host).Observe(time.Since(start).Seconds())
+			inFlight.Dec()
+
+			code := "error"
+			if err == nil && resp != nil {
+				code = strconv.Itoa(resp.StatusCode)
+			}
+			m.requests.WithLabelValues(req.Method, host, code).Inc()
+			return resp, err
+		}
+	}
+}
+
+// Instrument installs the metrics middleware on client
+func (m *HTTPMetrics) Instrument(client httputil.HTTPClient) {
+	client.Use(m.Middleware())
+}
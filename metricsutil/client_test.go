@@ -0,0 +1,109 @@
+package metricsutil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/httputil"
+	"github.com/mustanish/common-utils/v2/logutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	promtest "github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHTTPMetrics(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/flaky" && atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "http://")
+
+	registry := prometheus.NewRegistry()
+	metrics, err := NewHTTPMetrics(&HTTPMetricsConfig{Namespace: "billing", Registerer: registry})
+	if err != nil {
+		t.Fatalf("NewHTTPMetrics() error = %v", err)
+	}
+	client := httputil.NewHTTPUtil(logutil.NewNopLogger(), &httputil.HTTPConfig{MaxRetries: 3, InitialWait: time.Millisecond, MaxWait: time.Millisecond})
+	metrics.Instrument(client)
+
+	for _, path := range []string{"/flaky", "/ok"} {
+		resp, err := client.Get(context.Background(), server.URL+path, nil)
+		if err != nil {
+			t.Fatalf("Get(%s) error = %v", path, err)
+		}
+		client.CloseResponse(resp)
+	}
+	resp, err := client.Post(context.Background(), "http://127.0.0.1:1/unreachable", nil, nil, httputil.WithMaxRetries(0))
+	if err == nil {
+		client.CloseResponse(resp)
+		t.Fatal("Post() to a closed port should fail")
+	}
+
+	tests := []struct {
+		name     string
+		got      float64
+		expected float64
+	}{
+		{"GET 503 attempts", promtest.ToFloat64(metrics.requests.WithLabelValues("GET", host, "503")), 2},
+		{"GET 200 attempts", promtest.ToFloat64(metrics.requests.WithLabelValues("GET", host, "200")), 2},
+		{"POST transport errors", promtest.ToFloat64(metrics.requests.WithLabelValues("POST", "127.0.0.1:1", "error")), 1},
+		{"GET retries", promtest.ToFloat64(metrics.retries.WithLabelValues("GET", host)), 2},
+		{"POST retries", promtest.ToFloat64(metrics.retries.WithLabelValues("POST", "127.0.0.1:1")), 0},
+		{"in flight after completion", promtest.ToFloat64(metrics.inFlight.WithLabelValues(host)), 0},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.expected {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.expected)
+		}
+	}
+
+	// The series are exposed under the namespace and subsystem through promhttp
+	rec := httptest.NewRecorder()
+	promhttp.HandlerFor(registry, promhttp.HandlerOpts{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	for _, name := range []string{
+		"billing_http_client_requests_total",
+		"billing_http_client_request_duration_seconds_bucket",
+		"billing_http_client_retries_total",
+		"billing_http_client_in_flight_requests",
+	} {
+		if !strings.Contains(rec.Body.String(), name) {
+			t.Errorf("/metrics is missing %s", name)
+		}
+	}
+	if count := promtest.CollectAndCount(metrics.duration); count != 2 {
+		t.Errorf("duration series = %d, want 2 (GET and POST)", count)
+	}
+}
+
+func TestNewHTTPMetrics_SharedRegisterer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	first, err := NewHTTPMetrics(&HTTPMetricsConfig{Registerer: registry})
+	if err != nil {
+		t.Fatalf("NewHTTPMetrics() error = %v", err)
+	}
+	second, err := NewHTTPMetrics(&HTTPMetricsConfig{Registerer: registry})
+	if err != nil {
+		t.Fatalf("second NewHTTPMetrics() error = %v, want the registered collectors reused", err)
+	}
+	if first.requests != second.requests {
+		t.Error("second NewHTTPMetrics() should share the first one's collectors")
+	}
+
+	// Same name with different labels is a genuine conflict
+	conflict := prometheus.NewCounter(prometheus.CounterOpts{Subsystem: "http_client", Name: "retries_total", Help: "other"})
+	other := prometheus.NewRegistry()
+	other.MustRegister(conflict)
+	if _, err := NewHTTPMetrics(&HTTPMetricsConfig{Registerer: other}); err == nil {
+		t.Error("NewHTTPMetrics() with a conflicting collector should fail")
+	}
+}
@@ -0,0 +1,89 @@
+package moneyutil
+
+import "fmt"
+
+// MoneyConfig holds configuration for money construction and formatting
+type MoneyConfig struct {
+	DefaultLocale string
+}
+
+// DefaultMoneyConfig returns default configuration
+func DefaultMoneyConfig() *MoneyConfig {
+	return &MoneyConfig{
+		DefaultLocale: "en-US",
+	}
+}
+
+// MoneyClient defines the interface for creating, aggregating and formatting Money
+type MoneyClient interface {
+	// Construction
+	New(amount int64, code string) (Money, error)
+	Parse(amount, code string) (Money, error)
+
+	// Aggregation
+	Sum(amounts ...Money) (Money, error)
+
+	// Formatting
+	Format(m Money) string
+	FormatLocale(m Money, locale string) string
+}
+
+// MoneyUtil provides locale-aware helpers around the Money type
+type MoneyUtil struct {
+	defaultLocale string
+}
+
+// NewMoneyUtil creates a new money utility instance
+// Pass nil for config to use all defaults, or pass config with only the properties you want to override
+func NewMoneyUtil(config *MoneyConfig) MoneyClient {
+	defaults := DefaultMoneyConfig()
+
+	if config != nil {
+		if config.DefaultLocale != "" {
+			defaults.DefaultLocale = config.DefaultLocale
+		}
+	}
+
+	return &MoneyUtil{defaultLocale: defaults.DefaultLocale}
+}
+
+// New creates Money from an amount in minor units
+func (u *MoneyUtil) New(amount int64, code string) (Money, error) {
+	return New(amount, code)
+}
+
+// Parse creates Money from a decimal string
+func (u *MoneyUtil) Parse(amount, code string) (Money, error) {
+	return Parse(amount, code)
+}
+
+// Sum adds amounts that share a currency; at least one amount is required
+func (u *MoneyUtil) Sum(amounts ...Money) (Money, error) {
+	if len(amounts) == 0 {
+		return Money{}, fmt.Errorf("%w: nothing to sum", ErrInvalidAmount)
+	}
+	total := amounts[0]
+	for _, amount := range amounts[1:] {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Format renders m in the default locale, e.g. "$1,234.50"
+func (u *MoneyUtil) Format(m Money) string {
+	return u.FormatLocale(m, u.defaultLocale)
+}
+
+// FormatLocale renders m in the given locale, falling back to the default locale when it is unknown
+func (u *MoneyUtil) FormatLocale(m Money, locale string) string {
+	l, ok := lookupLocale(locale)
+	if !ok {
+		if l, ok = lookupLocale(u.defaultLocale); !ok {
+			l, _ = lookupLocale("en-US")
+		}
+	}
+	return l.format(m)
+}
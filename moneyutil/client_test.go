@@ -0,0 +1,350 @@
+package moneyutil
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/mustanish/common-utils/v2/mathutil"
+)
+
+func mustMoney(t *testing.T, amount int64, code string) Money {
+	t.Helper()
+	m, err := New(amount, code)
+	if err != nil {
+		t.Fatalf("New(%d, %s) unexpected error: %v", amount, code, err)
+	}
+	return m
+}
+
+func TestNewMoneyUtil(t *testing.T) {
+	util := NewMoneyUtil(nil)
+	if util == nil {
+		t.Error("NewMoneyUtil() returned nil")
+	}
+}
+
+// =================== Test Construction ===================
+
+func TestNew(t *testing.T) {
+	m, err := New(1050, "usd")
+	if err != nil || m.Amount() != 1050 || m.Currency().Code != "USD" {
+		t.Errorf("New() = %v, %v; want 10.50 USD", m, err)
+	}
+	if _, err := New(1, "XXX"); !errors.Is(err, ErrUnknownCurrency) {
+		t.Errorf("Expected ErrUnknownCurrency, got %v", err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		code      string
+		expected  int64
+		expectErr error
+	}{
+		{"two decimals", "10.50", "USD", 1050, nil},
+		{"one decimal", "10.5", "USD", 1050, nil},
+		{"whole", "10", "USD", 1000, nil},
+		{"negative", "-0.05", "USD", -5, nil},
+		{"leading dot", ".25", "USD", 25, nil},
+		{"trailing zeros allowed", "1.500", "USD", 150, nil},
+		{"zero exponent", "1234", "JPY", 1234, nil},
+		{"three decimals", "1.234", "KWD", 1234, nil},
+		{"too precise", "1.005", "USD", 0, ErrInvalidAmount},
+		{"garbage", "1,00", "USD", 0, ErrInvalidAmount},
+		{"empty", "", "USD", 0, ErrInvalidAmount},
+		{"overflow", "99999999999999999999", "USD", 0, mathutil.ErrOverflow},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Parse(tt.input, tt.code)
+			if tt.expectErr != nil {
+				if !errors.Is(err, tt.expectErr) {
+					t.Errorf("Parse(%q) error = %v, want %v", tt.input, err, tt.expectErr)
+				}
+				return
+			}
+			if err != nil || m.Amount() != tt.expected {
+				t.Errorf("Parse(%q) = %d, %v; want %d", tt.input, m.Amount(), err, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRegisterCurrency(t *testing.T) {
+	if err := RegisterCurrency(Currency{Code: "tst", Exponent: 4, Symbol: "T"}); err != nil {
+		t.Fatalf("RegisterCurrency() unexpected error: %v", err)
+	}
+	m, err := Parse("1.2345", "TST")
+	if err != nil || m.Amount() != 12345 {
+		t.Errorf("Parse() with custom currency = %v, %v", m, err)
+	}
+	if err := RegisterCurrency(Currency{Code: "TOOLONG"}); err == nil {
+		t.Error("Expected error for invalid currency code")
+	}
+}
+
+// =================== Test Arithmetic ===================
+
+func TestArithmetic(t *testing.T) {
+	a := mustMoney(t, 1050, "USD")
+	b := mustMoney(t, 250, "USD")
+
+	if sum, err := a.Add(b); err != nil || sum.Amount() != 1300 {
+		t.Errorf("Add() = %v, %v; want 13.00 USD", sum, err)
+	}
+	if diff, err := b.Subtract(a); err != nil || diff.Amount() != -800 {
+		t.Errorf("Subtract() = %v, %v; want -8.00 USD", diff, err)
+	}
+	if product, err := a.Multiply(3); err != nil || product.Amount() != 3150 {
+		t.Errorf("Multiply() = %v, %v; want 31.50 USD", product, err)
+	}
+	if neg, err := a.Negate(); err != nil || neg.Amount() != -1050 {
+		t.Errorf("Negate() = %v, %v", neg, err)
+	}
+}
+
+func TestArithmetic_Errors(t *testing.T) {
+	usd := mustMoney(t, 100, "USD")
+	eur := mustMoney(t, 100, "EUR")
+	max := mustMoney(t, math.MaxInt64, "USD")
+	min := mustMoney(t, math.MinInt64, "USD")
+
+	if _, err := usd.Add(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add() across currencies error = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := max.Add(usd); !errors.Is(err, mathutil.ErrOverflow) {
+		t.Errorf("Add() overflow error = %v, want ErrOverflow", err)
+	}
+	if _, err := min.Subtract(usd); !errors.Is(err, mathutil.ErrOverflow) {
+		t.Errorf("Subtract() overflow error = %v, want ErrOverflow", err)
+	}
+	if _, err := max.Multiply(2); !errors.Is(err, mathutil.ErrOverflow) {
+		t.Errorf("Multiply() overflow error = %v, want ErrOverflow", err)
+	}
+	if _, err := min.Negate(); !errors.Is(err, mathutil.ErrOverflow) {
+		t.Errorf("Negate() overflow error = %v, want ErrOverflow", err)
+	}
+}
+
+func TestMultiplyRatio(t *testing.T) {
+	tests := []struct {
+		name                   string
+		amount                 int64
+		numerator, denominator int64
+		expected               int64
+	}{
+		{"tax 8.25%", 1999, 825, 10000, 165},        // 164.9175 -> 165
+		{"half rounds up", 5, 1, 2, 3},              // 2.5 -> 3
+		{"negative half rounds away", -5, 1, 2, -3}, // -2.5 -> -3
+		{"below half rounds down", 14, 1, 10, 1},    // 1.4 -> 1
+		{"negative denominator", 10, 1, -4, -3},     // -2.5 -> -3
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := mustMoney(t, tt.amount, "USD")
+			result, err := m.MultiplyRatio(tt.numerator, tt.denominator)
+			if err != nil || result.Amount() != tt.expected {
+				t.Errorf("MultiplyRatio(%d, %d) = %d, %v; want %d", tt.numerator, tt.denominator, result.Amount(), err, tt.expected)
+			}
+		})
+	}
+
+	if _, err := mustMoney(t, 1, "USD").MultiplyRatio(1, 0); err == nil {
+		t.Error("Expected error for zero denominator")
+	}
+}
+
+// =================== Test Allocation ===================
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   int64
+		ratios   []int
+		expected []int64
+	}{
+		{"even", 100, []int{1, 1}, []int64{50, 50}},
+		{"remainder to first parts", 100, []int{1, 1, 1}, []int64{34, 33, 33}},
+		{"weighted", 5, []int{3, 7}, []int64{2, 3}},
+		{"negative amount", -100, []int{1, 1, 1}, []int64{-34, -33, -33}},
+		{"zero ratio gets nothing", 101, []int{0, 1, 1}, []int64{0, 51, 50}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := mustMoney(t, tt.amount, "USD").Allocate(tt.ratios...)
+			if err != nil {
+				t.Fatalf("Allocate() unexpected error: %v", err)
+			}
+			var sum int64
+			for i, part := range parts {
+				sum += part.Amount()
+				if part.Amount() != tt.expected[i] {
+					t.Errorf("parts[%d] = %d, want %d", i, part.Amount(), tt.expected[i])
+				}
+			}
+			if sum != tt.amount {
+				t.Errorf("parts sum to %d, want %d", sum, tt.amount)
+			}
+		})
+	}
+}
+
+func TestAllocate_Invalid(t *testing.T) {
+	m := mustMoney(t, 100, "USD")
+	for _, ratios := range [][]int{nil, {0, 0}, {1, -1}} {
+		if _, err := m.Allocate(ratios...); !errors.Is(err, ErrInvalidAllocation) {
+			t.Errorf("Allocate(%v) error = %v, want ErrInvalidAllocation", ratios, err)
+		}
+	}
+	if _, err := m.Split(0); !errors.Is(err, ErrInvalidAllocation) {
+		t.Errorf("Split(0) error = %v, want ErrInvalidAllocation", err)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	parts, err := mustMoney(t, 1000, "USD").Split(3)
+	if err != nil || len(parts) != 3 {
+		t.Fatalf("Split() = %v, %v", parts, err)
+	}
+	if parts[0].Amount() != 334 || parts[1].Amount() != 333 || parts[2].Amount() != 333 {
+		t.Errorf("Split(3) = %v, want [3.34 3.33 3.33]", parts)
+	}
+}
+
+// =================== Test Comparison ===================
+
+func TestCompare(t *testing.T) {
+	a := mustMoney(t, 100, "USD")
+	b := mustMoney(t, 200, "USD")
+
+	if c, _ := a.Compare(b); c != -1 {
+		t.Errorf("Compare() = %d, want -1", c)
+	}
+	if c, _ := b.Compare(a); c != 1 {
+		t.Errorf("Compare() = %d, want 1", c)
+	}
+	if c, _ := a.Compare(a); c != 0 {
+		t.Errorf("Compare() = %d, want 0", c)
+	}
+	if _, err := a.Compare(mustMoney(t, 100, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Compare() across currencies error = %v", err)
+	}
+	if !a.Equals(mustMoney(t, 100, "USD")) || a.Equals(mustMoney(t, 100, "EUR")) {
+		t.Error("Equals() must compare amount and currency")
+	}
+	if !a.IsPositive() || a.IsNegative() || a.IsZero() {
+		t.Error("Unexpected sign helpers for positive amount")
+	}
+}
+
+// =================== Test JSON ===================
+
+func TestJSON(t *testing.T) {
+	m := mustMoney(t, -1234505, "USD")
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("Marshal() unexpected error: %v", err)
+	}
+	if want := `{"amount":"-12345.05","currency":"USD"}`; string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	var decoded Money
+	if err := json.Unmarshal(data, &decoded); err != nil || !decoded.Equals(m) {
+		t.Errorf("Unmarshal() = %v, %v; want %v", decoded, err, m)
+	}
+
+	if err := json.Unmarshal([]byte(`{"amount":19.99,"currency":"EUR"}`), &decoded); err != nil || decoded.Amount() != 1999 {
+		t.Errorf("Unmarshal() of numeric amount = %v, %v", decoded, err)
+	}
+	if err := json.Unmarshal([]byte(`{"amount":"1.999","currency":"EUR"}`), &decoded); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Expected ErrInvalidAmount, got %v", err)
+	}
+}
+
+// =================== Test Formatting ===================
+
+func TestDecimalAndString(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		code     string
+		expected string
+	}{
+		{5, "USD", "0.05 USD"},
+		{-5, "USD", "-0.05 USD"},
+		{123456, "USD", "1234.56 USD"},
+		{1234, "JPY", "1234 JPY"},
+		{1, "KWD", "0.001 KWD"},
+		{math.MinInt64, "USD", "-92233720368547758.08 USD"},
+	}
+	for _, tt := range tests {
+		if got := mustMoney(t, tt.amount, tt.code).String(); got != tt.expected {
+			t.Errorf("String() = %q, want %q", got, tt.expected)
+		}
+	}
+}
+
+func TestFormatLocale(t *testing.T) {
+	util := NewMoneyUtil(nil)
+
+	tests := []struct {
+		name     string
+		amount   int64
+		code     string
+		locale   string
+		expected string
+	}{
+		{"en-US", 123456789, "USD", "en-US", "$1,234,567.89"},
+		{"negative", -123456, "USD", "en-US", "-$1,234.56"},
+		{"de-DE", 123456, "EUR", "de-DE", "1.234,56\u00a0€"},
+		{"fr-FR", 123456, "EUR", "fr_fr", "1\u00a0234,56\u00a0€"},
+		{"en-IN", 1234567800, "INR", "en-IN", "₹1,23,45,678.00"},
+		{"ja-JP", 1234567, "JPY", "ja-JP", "¥1,234,567"},
+		{"de-CH", 123456, "CHF", "de-CH", "CHF\u00a01’234.56"},
+		{"small", 5, "USD", "en-US", "$0.05"},
+		{"unknown locale falls back", 100, "USD", "xx-XX", "$1.00"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := util.FormatLocale(mustMoney(t, tt.amount, tt.code), tt.locale); got != tt.expected {
+				t.Errorf("FormatLocale() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFormat_DefaultLocale(t *testing.T) {
+	util := NewMoneyUtil(&MoneyConfig{DefaultLocale: "de-DE"})
+	if got := util.Format(mustMoney(t, 100050, "EUR")); got != "1.000,50\u00a0€" {
+		t.Errorf("Format() = %q, want %q", got, "1.000,50\u00a0€")
+	}
+
+	RegisterLocale("nl-NL", Locale{DecimalSeparator: ",", GroupSeparator: ".", Grouping: []int{3}, SymbolFirst: true, SymbolSpace: true})
+	if got := util.FormatLocale(mustMoney(t, 100050, "EUR"), "nl-NL"); got != "€\u00a01.000,50" {
+		t.Errorf("FormatLocale() with registered locale = %q", got)
+	}
+}
+
+func TestSum(t *testing.T) {
+	util := NewMoneyUtil(nil)
+
+	total, err := util.Sum(mustMoney(t, 100, "USD"), mustMoney(t, 250, "USD"), mustMoney(t, -50, "USD"))
+	if err != nil || total.Amount() != 300 {
+		t.Errorf("Sum() = %v, %v; want 3.00 USD", total, err)
+	}
+	if _, err := util.Sum(); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("Sum() with no amounts error = %v", err)
+	}
+	if _, err := util.Sum(mustMoney(t, 1, "USD"), mustMoney(t, 1, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sum() across currencies error = %v", err)
+	}
+}
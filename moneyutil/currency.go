@@ -0,0 +1,59 @@
+package moneyutil
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Currency describes an ISO 4217 currency
+type Currency struct {
+	Code     string
+	Exponent int // number of minor-unit digits, e.g. 2 for USD cents, 0 for JPY
+	Symbol   string
+}
+
+var (
+	currenciesMu sync.RWMutex
+	currencies   = map[string]Currency{
+		"AUD": {Code: "AUD", Exponent: 2, Symbol: "A$"},
+		"BHD": {Code: "BHD", Exponent: 3, Symbol: "BD"},
+		"BRL": {Code: "BRL", Exponent: 2, Symbol: "R$"},
+		"CAD": {Code: "CAD", Exponent: 2, Symbol: "CA$"},
+		"CHF": {Code: "CHF", Exponent: 2, Symbol: "CHF"},
+		"CNY": {Code: "CNY", Exponent: 2, Symbol: "CN¥"},
+		"EUR": {Code: "EUR", Exponent: 2, Symbol: "€"},
+		"GBP": {Code: "GBP", Exponent: 2, Symbol: "£"},
+		"INR": {Code: "INR", Exponent: 2, Symbol: "₹"},
+		"JPY": {Code: "JPY", Exponent: 0, Symbol: "¥"},
+		"KWD": {Code: "KWD", Exponent: 3, Symbol: "KD"},
+		"MXN": {Code: "MXN", Exponent: 2, Symbol: "MX$"},
+		"SEK": {Code: "SEK", Exponent: 2, Symbol: "kr"},
+		"SGD": {Code: "SGD", Exponent: 2, Symbol: "S$"},
+		"USD": {Code: "USD", Exponent: 2, Symbol: "$"},
+	}
+)
+
+// LookupCurrency returns the registered currency for an ISO code (case-insensitive)
+func LookupCurrency(code string) (Currency, error) {
+	currenciesMu.RLock()
+	defer currenciesMu.RUnlock()
+	currency, ok := currencies[strings.ToUpper(strings.TrimSpace(code))]
+	if !ok {
+		return Currency{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, code)
+	}
+	return currency, nil
+}
+
+// RegisterCurrency adds or replaces a currency definition
+func RegisterCurrency(currency Currency) error {
+	if len(currency.Code) != 3 || currency.Exponent < 0 || currency.Exponent > 8 {
+		return fmt.Errorf("invalid currency definition: %+v", currency)
+	}
+	currency.Code = strings.ToUpper(currency.Code)
+
+	currenciesMu.Lock()
+	defer currenciesMu.Unlock()
+	currencies[currency.Code] = currency
+	return nil
+}
@@ -0,0 +1,15 @@
+package moneyutil
+
+import "errors"
+
+// ErrCurrencyMismatch is returned when combining or comparing amounts in different currencies
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// ErrUnknownCurrency is returned for a currency code that is not registered
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// ErrInvalidAmount is returned when a decimal amount cannot be parsed exactly
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ErrInvalidAllocation is returned when allocation ratios or split counts are not usable
+var ErrInvalidAllocation = errors.New("invalid allocation")
@@ -0,0 +1,124 @@
+package moneyutil
+
+import (
+	"strings"
+	"sync"
+)
+
+// Locale describes how amounts are written in a region
+type Locale struct {
+	DecimalSeparator string
+	GroupSeparator   string
+
+	// Grouping lists digit group sizes from the right; the last size repeats.
+	// [3] gives 1,234,567 and [3, 2] gives Indian-style 12,34,567.
+	Grouping []int
+
+	SymbolFirst bool // "$1.00" rather than "1.00 $"
+	SymbolSpace bool // separate symbol and number with a no-break space
+}
+
+// nbsp is used between symbol and number, and as the French group separator
+const nbsp = "\u00a0"
+
+var (
+	localesMu sync.RWMutex
+	locales   = map[string]Locale{
+		"en-US": {DecimalSeparator: ".", GroupSeparator: ",", Grouping: []int{3}, SymbolFirst: true},
+		"en-GB": {DecimalSeparator: ".", GroupSeparator: ",", Grouping: []int{3}, SymbolFirst: true},
+		"en-IN": {DecimalSeparator: ".", GroupSeparator: ",", Grouping: []int{3, 2}, SymbolFirst: true},
+		"de-DE": {DecimalSeparator: ",", GroupSeparator: ".", Grouping: []int{3}, SymbolSpace: true},
+		"de-CH": {DecimalSeparator: ".", GroupSeparator: "’", Grouping: []int{3}, SymbolFirst: true, SymbolSpace: true},
+		"es-ES": {DecimalSeparator: ",", GroupSeparator: ".", Grouping: []int{3}, SymbolSpace: true},
+		"fr-FR": {DecimalSeparator: ",", GroupSeparator: nbsp, Grouping: []int{3}, SymbolSpace: true},
+		"ja-JP": {DecimalSeparator: ".", GroupSeparator: ",", Grouping: []int{3}, SymbolFirst: true},
+		"pt-BR": {DecimalSeparator: ",", GroupSeparator: ".", Grouping: []int{3}, SymbolFirst: true, SymbolSpace: true},
+	}
+)
+
+// RegisterLocale adds or replaces a locale under a BCP 47 style name such as "nl-NL"
+func RegisterLocale(name string, locale Locale) {
+	localesMu.Lock()
+	defer localesMu.Unlock()
+	locales[normalizeLocale(name)] = locale
+}
+
+// lookupLocale returns the named locale, reporting whether it is registered
+func lookupLocale(name string) (Locale, bool) {
+	localesMu.RLock()
+	defer localesMu.RUnlock()
+	locale, ok := locales[normalizeLocale(name)]
+	return locale, ok
+}
+
+// normalizeLocale converts "de_de" or "DE-de" into "de-DE"
+func normalizeLocale(name string) string {
+	parts := strings.SplitN(strings.ReplaceAll(strings.TrimSpace(name), "_", "-"), "-", 2)
+	if len(parts) == 1 {
+		return strings.ToLower(parts[0])
+	}
+	return strings.ToLower(parts[0]) + "-" + strings.ToUpper(parts[1])
+}
+
+// format renders m using the locale's separators, grouping and symbol placement
+func (l Locale) format(m Money) string {
+	decimal := m.Decimal()
+	negative := strings.HasPrefix(decimal, "-")
+	decimal = strings.TrimPrefix(decimal, "-")
+
+	whole, fraction := decimal, ""
+	if idx := strings.IndexByte(decimal, '.'); idx >= 0 {
+		whole, fraction = decimal[:idx], decimal[idx+1:]
+	}
+
+	number := l.group(whole)
+	if fraction != "" {
+		number += l.DecimalSeparator + fraction
+	}
+
+	symbol := m.currency.Symbol
+	if symbol == "" {
+		symbol = m.currency.Code
+	}
+	space := ""
+	if l.SymbolSpace {
+		space = nbsp
+	}
+
+	var formatted string
+	if l.SymbolFirst {
+		formatted = symbol + space + number
+	} else {
+		formatted = number + space + symbol
+	}
+	if negative {
+		formatted = "-" + formatted
+	}
+	return formatted
+}
+
+// group inserts group separators into a string of digits
+func (l Locale) group(digits string) string {
+	if len(l.Grouping) == 0 || l.GroupSeparator == "" {
+		return digits
+	}
+
+	var groups []string
+	for i := 0; len(digits) > 0; i++ {
+		size := l.Grouping[len(l.Grouping)-1]
+		if i < len(l.Grouping) {
+			size = l.Grouping[i]
+		}
+		if size <= 0 || size >= len(digits) {
+			groups = append(groups, digits)
+			break
+		}
+		groups = append(groups, digits[len(digits)-size:])
+		digits = digits[:len(digits)-size]
+	}
+
+	for i, j := 0, len(groups)-1; i < j; i, j = i+1, j-1 {
+		groups[i], groups[j] = groups[j], groups[i]
+	}
+	return strings.Join(groups, l.GroupSeparator)
+}
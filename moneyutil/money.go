@@ -0,0 +1,317 @@
+package moneyutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/mustanish/common-utils/v2/mathutil"
+)
+
+// Money is an amount in minor units (e.g. cents) of a currency
+// Money values are immutable; arithmetic returns a new value and reports overflow instead of wrapping.
+type Money struct {
+	amount   int64
+	currency Currency
+}
+
+// New creates Money from an amount in minor units, e.g. New(1050, "USD") is $10.50
+func New(amount int64, code string) (Money, error) {
+	currency, err := LookupCurrency(code)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: amount, currency: currency}, nil
+}
+
+// Parse creates Money from a decimal string such as "10.50" or "-3", without going through float64
+// More fractional digits than the currency allows is an error rather than a silent rounding.
+func Parse(amount, code string) (Money, error) {
+	currency, err := LookupCurrency(code)
+	if err != nil {
+		return Money{}, err
+	}
+	minor, err := parseMinor(amount, currency.Exponent)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{amount: minor, currency: currency}, nil
+}
+
+// Amount returns the amount in minor units
+func (m Money) Amount() int64 {
+	return m.amount
+}
+
+// Currency returns the currency of the amount
+func (m Money) Currency() Currency {
+	return m.currency
+}
+
+// Add returns m + other
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	if (other.amount > 0 && m.amount > math.MaxInt64-other.amount) ||
+		(other.amount < 0 && m.amount < math.MinInt64-other.amount) {
+		return Money{}, fmt.Errorf("%w: %s + %s", mathutil.ErrOverflow, m, other)
+	}
+	return Money{amount: m.amount + other.amount, currency: m.currency}, nil
+}
+
+// Subtract returns m - other
+func (m Money) Subtract(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	if (other.amount < 0 && m.amount > math.MaxInt64+other.amount) ||
+		(other.amount > 0 && m.amount < math.MinInt64+other.amount) {
+		return Money{}, fmt.Errorf("%w: %s - %s", mathutil.ErrOverflow, m, other)
+	}
+	return Money{amount: m.amount - other.amount, currency: m.currency}, nil
+}
+
+// Multiply returns m * factor
+func (m Money) Multiply(factor int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(factor))
+	if !product.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s * %d", mathutil.ErrOverflow, m, factor)
+	}
+	return Money{amount: product.Int64(), currency: m.currency}, nil
+}
+
+// MultiplyRatio returns m * numerator / denominator rounded half away from zero
+// Use it for rates and percentages, e.g. MultiplyRatio(825, 10000) for 8.25% tax.
+func (m Money) MultiplyRatio(numerator, denominator int64) (Money, error) {
+	if denominator == 0 {
+		return Money{}, fmt.Errorf("%w: zero denominator", ErrInvalidAllocation)
+	}
+	num := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(numerator))
+	den := big.NewInt(denominator)
+	if den.Sign() < 0 {
+		num.Neg(num)
+		den.Neg(den)
+	}
+
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	// Round half away from zero: compare 2*|remainder| with the denominator
+	if new(big.Int).Mul(new(big.Int).Abs(remainder), big.NewInt(2)).Cmp(den) >= 0 {
+		if num.Sign() < 0 {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	if !quotient.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s * %d / %d", mathutil.ErrOverflow, m, numerator, denominator)
+	}
+	return Money{amount: quotient.Int64(), currency: m.currency}, nil
+}
+
+// Negate returns -m
+func (m Money) Negate() (Money, error) {
+	if m.amount == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: -(%s)", mathutil.ErrOverflow, m)
+	}
+	return Money{amount: -m.amount, currency: m.currency}, nil
+}
+
+// Allocate splits m by the given ratios without losing minor units
+// Leftover units from rounding go one at a time to the leading parts, so the parts always sum to m.
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, fmt.Errorf("%w: no ratios given", ErrInvalidAllocation)
+	}
+	var total int64
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, fmt.Errorf("%w: negative ratio %d", ErrInvalidAllocation, ratio)
+		}
+		total += int64(ratio)
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("%w: ratios sum to zero", ErrInvalidAllocation)
+	}
+
+	parts := make([]Money, len(ratios))
+	remainder := m.amount
+	for i, ratio := range ratios {
+		share := new(big.Int).Mul(big.NewInt(m.amount), big.NewInt(int64(ratio)))
+		share.Quo(share, big.NewInt(total)) // truncates toward zero, so |sum of shares| <= |amount|
+		parts[i] = Money{amount: share.Int64(), currency: m.currency}
+		remainder -= share.Int64()
+	}
+
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].amount += step
+		remainder -= step
+	}
+	return parts, nil
+}
+
+// Split divides m into n parts that differ by at most one minor unit and sum to m
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: cannot split into %d parts", ErrInvalidAllocation, n)
+	}
+	ratios := make([]int, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// Compare returns -1, 0 or 1 as m is less than, equal to or greater than other
+func (m Money) Compare(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.amount < other.amount:
+		return -1, nil
+	case m.amount > other.amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Equals reports whether m and other have the same currency and amount
+func (m Money) Equals(other Money) bool {
+	return m.currency.Code == other.currency.Code && m.amount == other.amount
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.amount == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.amount < 0
+}
+
+// IsPositive reports whether the amount is above zero
+func (m Money) IsPositive() bool {
+	return m.amount > 0
+}
+
+// Decimal returns the amount as a plain decimal string, e.g. "-1234.50"
+func (m Money) Decimal() string {
+	digits := strconv.FormatUint(absUint(m.amount), 10)
+	sign := ""
+	if m.amount < 0 {
+		sign = "-"
+	}
+	exp := m.currency.Exponent
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// String returns the decimal amount followed by the currency code, e.g. "10.50 USD"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.currency.Code
+}
+
+// moneyJSON is the wire format of Money; the amount is a decimal string to avoid float rounding
+type moneyJSON struct {
+	Amount   json.Number `json:"amount"`
+	Currency string      `json:"currency"`
+}
+
+// MarshalJSON encodes Money as {"amount":"10.50","currency":"USD"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	}{Amount: m.Decimal(), Currency: m.currency.Code})
+}
+
+// UnmarshalJSON decodes Money from an amount given as a decimal string or JSON number
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var raw moneyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to decode money: %w", err)
+	}
+	parsed, err := Parse(raw.Amount.String(), raw.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// sameCurrency returns ErrCurrencyMismatch unless both amounts use the same currency
+func (m Money) sameCurrency(other Money) error {
+	if m.currency.Code != other.currency.Code {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency.Code, other.currency.Code)
+	}
+	return nil
+}
+
+// parseMinor converts a decimal string into minor units with the given number of fractional digits
+func parseMinor(s string, exponent int) (int64, error) {
+	s = strings.TrimSpace(s)
+	negative := strings.HasPrefix(s, "-")
+	unsigned := strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+
+	whole, fraction := unsigned, ""
+	if idx := strings.IndexByte(unsigned, '.'); idx >= 0 {
+		whole, fraction = unsigned[:idx], unsigned[idx+1:]
+	}
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if len(fraction) > exponent {
+		if strings.Trim(fraction[exponent:], "0") != "" {
+			return 0, fmt.Errorf("%w: %q has more than %d decimal places", ErrInvalidAmount, s, exponent)
+		}
+		fraction = fraction[:exponent]
+	}
+	fraction += strings.Repeat("0", exponent-len(fraction))
+
+	value, ok := new(big.Int).SetString("0"+whole+fraction, 10)
+	if !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if negative {
+		value.Neg(value)
+	}
+	if !value.IsInt64() {
+		return 0, fmt.Errorf("%w: %q", mathutil.ErrOverflow, s)
+	}
+	return value.Int64(), nil
+}
+
+// isDigits reports whether s consists only of ASCII digits (an empty string qualifies)
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// absUint returns |v| as a uint64, which also holds |math.MinInt64|
+func absUint(v int64) uint64 {
+	if v < 0 {
+		return uint64(-(v + 1)) + 1
+	}
+	return uint64(v)
+}
@@ -0,0 +1,231 @@
+package netutil
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NetConfig holds configuration for network helpers
+type NetConfig struct {
+	// MaxExpandAddresses bounds how many addresses ExpandCIDR may return
+	MaxExpandAddresses int
+
+	// DialTimeout bounds each connection attempt made by WaitForPort and DialWithRetry
+	DialTimeout time.Duration
+
+	// PollInterval is the delay between WaitForPort attempts
+	PollInterval time.Duration
+
+	// OutboundProbeAddr is the address GetOutboundIP "connects" to over UDP (no packets are sent)
+	OutboundProbeAddr string
+}
+
+// DefaultNetConfig returns default configuration
+func DefaultNetConfig() *NetConfig {
+	return &NetConfig{
+		MaxExpandAddresses: 65536,
+		DialTimeout:        time.Second,
+		PollInterval:       100 * time.Millisecond,
+		OutboundProbeAddr:  "8.8.8.8:80",
+	}
+}
+
+// NetClient defines the interface for IP and network helpers
+type NetClient interface {
+	// IP addresses
+	ParseIPSafe(s string) (net.IP, error)
+	IsPrivateIP(ip net.IP) bool
+
+	// CIDR ranges
+	CIDRContains(cidr, ip string) (bool, error)
+	ExpandCIDR(cidr string) ([]net.IP, error)
+
+	// Local networking
+	GetOutboundIP() (net.IP, error)
+	FreePort() (int, error)
+	WaitForPort(ctx context.Context, host string, port int) error
+
+	// Dialing
+	DialWithRetry(ctx context.Context, addr string, opts DialOptions) (net.Conn, error)
+	TLSCertExpiry(ctx context.Context, addr string) (time.Time, error)
+}
+
+// NetUtil implements NetClient
+type NetUtil struct {
+	config NetConfig
+}
+
+// nonPublicRanges are the ranges IsPrivateIP treats as private beyond net.IP's own checks
+var nonPublicRanges = mustParseCIDRs(
+	"100.64.0.0/10", // carrier-grade NAT (RFC 6598)
+	"0.0.0.0/8",     // "this" network
+)
+
+// NewNetUtil creates a new network utility instance
+// Pass nil for config to use all defaults, or pass config with only the properties you want to override
+func NewNetUtil(config *NetConfig) NetClient {
+	defaults := DefaultNetConfig()
+
+	if config != nil {
+		if config.MaxExpandAddresses > 0 {
+			defaults.MaxExpandAddresses = config.MaxExpandAddresses
+		}
+		if config.DialTimeout > 0 {
+			defaults.DialTimeout = config.DialTimeout
+		}
+		if config.PollInterval > 0 {
+			defaults.PollInterval = config.PollInterval
+		}
+		if config.OutboundProbeAddr != "" {
+			defaults.OutboundProbeAddr = config.OutboundProbeAddr
+		}
+	}
+
+	return &NetUtil{config: *defaults}
+}
+
+// ParseIPSafe parses an IPv4 or IPv6 address, returning an error instead of nil
+// Surrounding whitespace and IPv6 brackets ("[::1]") are accepted; IPv4 results are 4 bytes long.
+func (n *NetUtil) ParseIPSafe(s string) (net.IP, error) {
+	trimmed := strings.TrimSpace(s)
+	trimmed = strings.TrimSuffix(strings.TrimPrefix(trimmed, "["), "]")
+	ip := net.ParseIP(trimmed)
+	if ip == nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidIP, s)
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4, nil
+	}
+	return ip, nil
+}
+
+// IsPrivateIP reports whether ip is not publicly routable
+// This covers RFC 1918/4193 private ranges, loopback, link-local, unspecified and carrier-grade NAT addresses.
+func (n *NetUtil) IsPrivateIP(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, network := range nonPublicRanges {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// CIDRContains reports whether ip falls within cidr
+func (n *NetUtil) CIDRContains(cidr, ip string) (bool, error) {
+	_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+	if err != nil {
+		return false, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+	}
+	parsed, err := n.ParseIPSafe(ip)
+	if err != nil {
+		return false, err
+	}
+	return network.Contains(parsed), nil
+}
+
+// ExpandCIDR lists every address in cidr, including the network and broadcast addresses
+// Ranges larger than MaxExpandAddresses fail with ErrCIDRTooLarge.
+func (n *NetUtil) ExpandCIDR(cidr string) ([]net.IP, error) {
+	_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR %q: %w", cidr, err)
+	}
+
+	ones, bits := network.Mask.Size()
+	hostBits := bits - ones
+	if hostBits >= 63 || int64(1)<<hostBits > int64(n.config.MaxExpandAddresses) {
+		return nil, fmt.Errorf("%w: %s exceeds %d addresses", ErrCIDRTooLarge, cidr, n.config.MaxExpandAddresses)
+	}
+
+	ips := make([]net.IP, 1<<hostBits)
+	current := append(net.IP(nil), network.IP...)
+	for i := range ips {
+		ips[i] = append(net.IP(nil), current...)
+		incrementIP(current)
+	}
+	return ips, nil
+}
+
+// GetOutboundIP returns the local address used for outbound traffic
+// It opens a UDP socket towards OutboundProbeAddr, which selects a route without sending packets.
+func (n *NetUtil) GetOutboundIP() (net.IP, error) {
+	conn, err := net.Dial("udp", n.config.OutboundProbeAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine outbound IP: %w", err)
+	}
+	defer conn.Close()
+
+	addr, ok := conn.LocalAddr().(*net.UDPAddr)
+	if !ok {
+		return nil, fmt.Errorf("failed to determine outbound IP: unexpected address %v", conn.LocalAddr())
+	}
+	return addr.IP, nil
+}
+
+// FreePort asks the kernel for a free TCP port on the loopback interface
+// The port is released before returning, so another process could claim it first.
+func (n *NetUtil) FreePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("failed to find a free port: %w", err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// WaitForPort blocks until a TCP connection to host:port succeeds or ctx is done
+func (n *NetUtil) WaitForPort(ctx context.Context, host string, port int) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	dialer := &net.Dialer{Timeout: n.config.DialTimeout}
+
+	for {
+		conn, err := dialer.DialContext(ctx, "tcp", address)
+		if err == nil {
+			return conn.Close()
+		}
+
+		timer := time.NewTimer(n.config.PollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%s not reachable: %w (last error: %v)", address, ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+// incrementIP adds one to ip in place, carrying across bytes
+func incrementIP(ip net.IP) {
+	for i := len(ip) - 1; i >= 0; i-- {
+		ip[i]++
+		if ip[i] != 0 {
+			return
+		}
+	}
+}
+
+// mustParseCIDRs parses constant CIDR ranges, panicking on programmer error
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = network
+	}
+	return networks
+}
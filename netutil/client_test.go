@@ -0,0 +1,299 @@
+package netutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/retryutil"
+)
+
+func TestNewNetUtil(t *testing.T) {
+	util := NewNetUtil(nil).(*NetUtil)
+	if util.config.MaxExpandAddresses != 65536 || util.config.PollInterval != 100*time.Millisecond {
+		t.Errorf("default config = %+v", util.config)
+	}
+
+	custom := NewNetUtil(&NetConfig{MaxExpandAddresses: 16}).(*NetUtil)
+	if custom.config.MaxExpandAddresses != 16 || custom.config.DialTimeout != time.Second {
+		t.Errorf("custom config = %+v", custom.config)
+	}
+}
+
+// =================== Test IP Addresses ===================
+
+func TestParseIPSafe(t *testing.T) {
+	util := NewNetUtil(nil)
+
+	tests := []struct {
+		name      string
+		input     string
+		expected  string
+		length    int
+		expectErr bool
+	}{
+		{"ipv4", "192.168.1.10", "192.168.1.10", 4, false},
+		{"ipv4 with spaces", "  10.0.0.1 ", "10.0.0.1", 4, false},
+		{"ipv4-mapped ipv6", "::ffff:10.0.0.1", "10.0.0.1", 4, false},
+		{"ipv6", "2001:db8::1", "2001:db8::1", 16, false},
+		{"bracketed ipv6", "[::1]", "::1", 16, false},
+		{"hostname", "example.com", "", 0, true},
+		{"empty", "", "", 0, true},
+		{"out of range", "256.1.1.1", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := util.ParseIPSafe(tt.input)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ParseIPSafe(%q) error = %v, expectErr %v", tt.input, err, tt.expectErr)
+			}
+			if tt.expectErr {
+				if !errors.Is(err, ErrInvalidIP) {
+					t.Errorf("ParseIPSafe(%q) error = %v, want ErrInvalidIP", tt.input, err)
+				}
+				return
+			}
+			if result.String() != tt.expected || len(result) != tt.length {
+				t.Errorf("ParseIPSafe(%q) = %v (len %d), want %v (len %d)", tt.input, result, len(result), tt.expected, tt.length)
+			}
+		})
+	}
+}
+
+func TestIsPrivateIP(t *testing.T) {
+	util := NewNetUtil(nil)
+
+	tests := []struct {
+		ip       string
+		expected bool
+	}{
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"172.32.0.1", false},
+		{"192.168.0.1", true},
+		{"127.0.0.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"8.8.8.8", false},
+		{"fd00::1", true},
+		{"fe80::1", true},
+		{"::1", true},
+		{"2001:4860:4860::8888", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if result := util.IsPrivateIP(net.ParseIP(tt.ip)); result != tt.expected {
+				t.Errorf("IsPrivateIP(%s) = %v, want %v", tt.ip, result, tt.expected)
+			}
+		})
+	}
+
+	if util.IsPrivateIP(nil) {
+		t.Error("IsPrivateIP(nil) = true, want false")
+	}
+}
+
+// =================== Test CIDR Ranges ===================
+
+func TestCIDRContains(t *testing.T) {
+	util := NewNetUtil(nil)
+
+	tests := []struct {
+		name      string
+		cidr      string
+		ip        string
+		expected  bool
+		expectErr bool
+	}{
+		{"inside", "10.0.0.0/8", "10.20.30.40", true, false},
+		{"outside", "10.0.0.0/8", "11.0.0.1", false, false},
+		{"ipv6 inside", "2001:db8::/32", "2001:db8:1::5", true, false},
+		{"invalid cidr", "10.0.0.0/33", "10.0.0.1", false, true},
+		{"invalid ip", "10.0.0.0/8", "nope", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := util.CIDRContains(tt.cidr, tt.ip)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("CIDRContains() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if result != tt.expected {
+				t.Errorf("CIDRContains(%s, %s) = %v, want %v", tt.cidr, tt.ip, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestExpandCIDR(t *testing.T) {
+	util := NewNetUtil(&NetConfig{MaxExpandAddresses: 256})
+
+	ips, err := util.ExpandCIDR("192.168.0.254/31")
+	if err != nil || len(ips) != 2 || ips[0].String() != "192.168.0.254" || ips[1].String() != "192.168.0.255" {
+		t.Errorf("ExpandCIDR(/31) = %v, %v", ips, err)
+	}
+
+	ips, err = util.ExpandCIDR("10.0.0.0/24")
+	if err != nil || len(ips) != 256 || ips[255].String() != "10.0.0.255" {
+		t.Errorf("ExpandCIDR(/24) = %d addresses, %v", len(ips), err)
+	}
+
+	ips, err = util.ExpandCIDR("2001:db8::fe/127")
+	if err != nil || len(ips) != 2 || ips[1].String() != "2001:db8::ff" {
+		t.Errorf("ExpandCIDR(ipv6 /127) = %v, %v", ips, err)
+	}
+
+	if _, err := util.ExpandCIDR("10.0.0.0/23"); !errors.Is(err, ErrCIDRTooLarge) {
+		t.Errorf("ExpandCIDR(/23) error = %v, want ErrCIDRTooLarge", err)
+	}
+	if _, err := util.ExpandCIDR("2001:db8::/32"); !errors.Is(err, ErrCIDRTooLarge) {
+		t.Errorf("ExpandCIDR(ipv6 /32) error = %v, want ErrCIDRTooLarge", err)
+	}
+	if _, err := util.ExpandCIDR("bad"); err == nil {
+		t.Error("ExpandCIDR(bad) expected error")
+	}
+}
+
+// =================== Test Local Networking ===================
+
+func TestGetOutboundIP(t *testing.T) {
+	ip, err := NewNetUtil(nil).GetOutboundIP()
+	if err != nil {
+		t.Skipf("no outbound route in this environment: %v", err)
+	}
+	if ip == nil || ip.IsUnspecified() {
+		t.Errorf("GetOutboundIP() = %v, want a concrete address", ip)
+	}
+}
+
+func TestFreePortAndWaitForPort(t *testing.T) {
+	util := NewNetUtil(&NetConfig{PollInterval: 10 * time.Millisecond})
+
+	port, err := util.FreePort()
+	if err != nil || port <= 0 {
+		t.Fatalf("FreePort() = %d, %v", port, err)
+	}
+
+	// Nothing listens yet, so waiting with a short deadline fails
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := util.WaitForPort(ctx, "127.0.0.1", port); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("WaitForPort() before listen error = %v, want context.DeadlineExceeded", err)
+	}
+
+	// Start listening after a delay; WaitForPort should pick it up
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		if err != nil {
+			return
+		}
+		time.Sleep(time.Second)
+		listener.Close()
+	}()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := util.WaitForPort(ctx, "127.0.0.1", port); err != nil {
+		t.Errorf("WaitForPort() error = %v", err)
+	}
+}
+
+func TestDialWithRetry(t *testing.T) {
+	util := NewNetUtil(&NetConfig{PollInterval: 10 * time.Millisecond})
+	port, err := util.FreePort()
+	if err != nil {
+		t.Fatalf("FreePort() error = %v", err)
+	}
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+
+	// Nothing listens, so a single retry runs out
+	_, err = util.DialWithRetry(context.Background(), addr, DialOptions{Retry: &retryutil.Options{MaxRetries: 1, InitialWait: time.Millisecond}})
+	var exhausted *retryutil.ExhaustedError
+	if !errors.As(err, &exhausted) || exhausted.Attempts != 2 {
+		t.Errorf("DialWithRetry() before listen error = %v, want ExhaustedError after 2 attempts", err)
+	}
+
+	// Start listening after a delay; the default backoff should pick it up
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			return
+		}
+		defer listener.Close()
+		if conn, err := listener.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+
+	conn, err := util.DialWithRetry(context.Background(), addr, DialOptions{})
+	if err != nil {
+		t.Fatalf("DialWithRetry() error = %v", err)
+	}
+	conn.Close()
+}
+
+func TestDialWithRetryTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "https://")
+	util := NewNetUtil(nil)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+	conn, err := util.DialWithRetry(context.Background(), addr, DialOptions{TLS: &tls.Config{RootCAs: roots, ServerName: "example.com"}})
+	if err != nil {
+		t.Fatalf("DialWithRetry() with trusted root error = %v", err)
+	}
+	if _, ok := conn.(*tls.Conn); !ok {
+		t.Errorf("DialWithRetry() = %T, want *tls.Conn", conn)
+	}
+	conn.Close()
+
+	// An untrusted certificate fails at once instead of being retried
+	start := time.Now()
+	_, err = util.DialWithRetry(context.Background(), addr, DialOptions{TLS: &tls.Config{ServerName: "example.com"}})
+	var exhausted *retryutil.ExhaustedError
+	if err == nil || errors.As(err, &exhausted) {
+		t.Errorf("DialWithRetry() with untrusted certificate error = %v, want a permanent failure", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("DialWithRetry() with untrusted certificate took %v, want no retries", elapsed)
+	}
+}
+
+func TestTLSCertExpiry(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	addr := strings.TrimPrefix(server.URL, "https://")
+
+	expiry, err := NewNetUtil(nil).TLSCertExpiry(context.Background(), addr)
+	if err != nil {
+		t.Fatalf("TLSCertExpiry() error = %v", err)
+	}
+	if want := server.Certificate().NotAfter; !expiry.Equal(want) {
+		t.Errorf("TLSCertExpiry() = %v, want %v", expiry, want)
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkIsPrivateIP(b *testing.B) {
+	util := NewNetUtil(nil)
+	ip := net.ParseIP("100.64.1.1")
+	for i := 0; i < b.N; i++ {
+		util.IsPrivateIP(ip)
+	}
+}
@@ -0,0 +1,108 @@
+package netutil
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/retryutil"
+)
+
+// DialOptions configures DialWithRetry
+type DialOptions struct {
+	// Network is passed to net.Dialer, e.g. "tcp4" or "unix" (default "tcp")
+	Network string
+
+	// TLS, when set, makes a TLS handshake part of every attempt and DialWithRetry return a *tls.Conn
+	// An empty ServerName is taken from the address.
+	TLS *tls.Config
+
+	// Retry is the backoff between attempts; nil retries 10 times starting at PollInterval, doubling up to 5s
+	// Each attempt is bounded by DialTimeout.
+	Retry *retryutil.Options
+}
+
+// DialWithRetry connects to addr, retrying with retryutil backoff until it succeeds, retries run out or ctx is done
+// It suits bootstrap code waiting for a database or queue to come up. Certificate verification failures are
+// not retried, since waiting does not fix them. When retries run out the error wraps a *retryutil.ExhaustedError.
+func (n *NetUtil) DialWithRetry(ctx context.Context, addr string, opts DialOptions) (net.Conn, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	network := opts.Network
+	if network == "" {
+		network = "tcp"
+	}
+	retry := retryutil.Options{
+		MaxRetries:  10,
+		InitialWait: n.config.PollInterval,
+		MaxWait:     5 * time.Second,
+		Multiplier:  2,
+		Jitter:      0.1,
+	}
+	if opts.Retry != nil {
+		retry = *opts.Retry
+	}
+
+	dialer := &net.Dialer{Timeout: n.config.DialTimeout}
+	conn, err := retryutil.RetryWithResult(ctx, func(ctx context.Context) (net.Conn, error) {
+		var conn net.Conn
+		var err error
+		if opts.TLS != nil {
+			conn, err = (&tls.Dialer{NetDialer: dialer, Config: opts.TLS}).DialContext(ctx, network, addr)
+		} else {
+			conn, err = dialer.DialContext(ctx, network, addr)
+		}
+		if err != nil && (ctx.Err() != nil || isCertificateError(err)) {
+			return nil, retryutil.Permanent(err)
+		}
+		return conn, err
+	}, retry)
+	if err != nil {
+		return nil, fmt.Errorf("dial %s: %w", addr, err)
+	}
+	return conn, nil
+}
+
+// TLSCertExpiry returns the earliest expiry among the certificates addr presents, e.g. for certificate monitoring
+// The chain is read without being verified, so expired or untrusted certificates are still reported. An
+// address without a port defaults to 443. Connecting uses DialWithRetry's default backoff, bounded by ctx.
+func (n *NetUtil) TLSCertExpiry(ctx context.Context, addr string) (time.Time, error) {
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "443")
+	}
+	host, _, _ := net.SplitHostPort(addr)
+
+	conn, err := n.DialWithRetry(ctx, addr, DialOptions{TLS: &tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: true, // only the validity period is read
+	}})
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return time.Time{}, fmt.Errorf("%s presented no certificates", addr)
+	}
+	expiry := certs[0].NotAfter
+	for _, cert := range certs[1:] {
+		if cert.NotAfter.Before(expiry) {
+			expiry = cert.NotAfter
+		}
+	}
+	return expiry, nil
+}
+
+// isCertificateError reports whether err is a certificate verification failure
+func isCertificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid)
+}
@@ -0,0 +1,11 @@
+package netutil
+
+import "errors"
+
+var (
+	// ErrInvalidIP is returned when a string is not a valid IPv4 or IPv6 address
+	ErrInvalidIP = errors.New("invalid IP address")
+
+	// ErrCIDRTooLarge is returned when expanding a CIDR would exceed MaxExpandAddresses
+	ErrCIDRTooLarge = errors.New("CIDR range too large to expand")
+)
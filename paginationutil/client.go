@@ -0,0 +1,226 @@
+package paginationutil
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PaginationConfig holds configuration for pagination helpers
+type PaginationConfig struct {
+	DefaultLimit int
+	MaxLimit     int
+
+	// CursorSecret signs cursors with HMAC-SHA256 so clients cannot forge them (empty disables signing)
+	CursorSecret []byte
+
+	// Query parameter names shared by servers building links and clients following them
+	LimitParam  string
+	OffsetParam string
+	CursorParam string
+}
+
+// DefaultPaginationConfig returns default configuration
+func DefaultPaginationConfig() *PaginationConfig {
+	return &PaginationConfig{
+		DefaultLimit: 20,
+		MaxLimit:     100,
+		LimitParam:   "limit",
+		OffsetParam:  "offset",
+		CursorParam:  "cursor",
+	}
+}
+
+// PaginationClient defines the interface for pagination requests, cursors and links
+type PaginationClient interface {
+	// Requests
+	ParseRequest(query url.Values) (PageRequest, error)
+
+	// Cursors
+	EncodeCursor(keys any) (string, error)
+	DecodeCursor(cursor string, out any) error
+
+	// Links
+	BuildLinks(baseURL string, info PageInfo) (Links, error)
+	LinkHeader(links Links) string
+}
+
+// PaginationUtil implements PaginationClient
+type PaginationUtil struct {
+	config PaginationConfig
+}
+
+// NewPaginationUtil creates a new pagination utility instance
+// Pass nil for config to use all defaults, or pass config with only the properties you want to override
+func NewPaginationUtil(config *PaginationConfig) PaginationClient {
+	defaults := DefaultPaginationConfig()
+
+	if config != nil {
+		if config.DefaultLimit > 0 {
+			defaults.DefaultLimit = config.DefaultLimit
+		}
+		if config.MaxLimit > 0 {
+			defaults.MaxLimit = config.MaxLimit
+		}
+		if len(config.CursorSecret) > 0 {
+			defaults.CursorSecret = config.CursorSecret
+		}
+		if config.LimitParam != "" {
+			defaults.LimitParam = config.LimitParam
+		}
+		if config.OffsetParam != "" {
+			defaults.OffsetParam = config.OffsetParam
+		}
+		if config.CursorParam != "" {
+			defaults.CursorParam = config.CursorParam
+		}
+	}
+	if defaults.DefaultLimit > defaults.MaxLimit {
+		defaults.DefaultLimit = defaults.MaxLimit
+	}
+
+	return &PaginationUtil{config: *defaults}
+}
+
+// ParseRequest reads limit, offset and cursor from query parameters
+// A missing limit uses DefaultLimit and larger limits are capped at MaxLimit.
+func (p *PaginationUtil) ParseRequest(query url.Values) (PageRequest, error) {
+	req := PageRequest{Limit: p.config.DefaultLimit, Cursor: query.Get(p.config.CursorParam)}
+
+	if raw := query.Get(p.config.LimitParam); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return PageRequest{}, fmt.Errorf("%w: %s must be a positive integer, got %q", ErrInvalidPageRequest, p.config.LimitParam, raw)
+		}
+		if limit > p.config.MaxLimit {
+			limit = p.config.MaxLimit
+		}
+		req.Limit = limit
+	}
+
+	if raw := query.Get(p.config.OffsetParam); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return PageRequest{}, fmt.Errorf("%w: %s must be a non-negative integer, got %q", ErrInvalidPageRequest, p.config.OffsetParam, raw)
+		}
+		req.Offset = offset
+	}
+
+	if req.Cursor != "" && req.Offset > 0 {
+		return PageRequest{}, fmt.Errorf("%w: %s and %s cannot be combined", ErrInvalidPageRequest, p.config.CursorParam, p.config.OffsetParam)
+	}
+	return req, nil
+}
+
+// EncodeCursor encodes the key fields of the last item (a struct or map) as an opaque cursor
+// The cursor is URL-safe base64 JSON, followed by "." and an HMAC signature when a secret is configured.
+func (p *PaginationUtil) EncodeCursor(keys any) (string, error) {
+	payload, err := json.Marshal(keys)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	cursor := base64.RawURLEncoding.EncodeToString(payload)
+	if len(p.config.CursorSecret) > 0 {
+		cursor += "." + base64.RawURLEncoding.EncodeToString(p.sign(cursor))
+	}
+	return cursor, nil
+}
+
+// DecodeCursor verifies a cursor and decodes its key fields into out
+func (p *PaginationUtil) DecodeCursor(cursor string, out any) error {
+	encoded := cursor
+	if len(p.config.CursorSecret) > 0 {
+		var signature string
+		var found bool
+		encoded, signature, found = strings.Cut(cursor, ".")
+		if !found {
+			return fmt.Errorf("%w: missing signature", ErrInvalidCursor)
+		}
+		mac, err := base64.RawURLEncoding.DecodeString(signature)
+		if err != nil || !hmac.Equal(mac, p.sign(encoded)) {
+			return fmt.Errorf("%w: signature mismatch", ErrInvalidCursor)
+		}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if err := json.Unmarshal(payload, out); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return nil
+}
+
+// BuildLinks returns next/prev URLs for a page, keeping baseURL's other query parameters
+// Cursors take precedence; without them offset links are derived from Offset, Limit and HasMore.
+func (p *PaginationUtil) BuildLinks(baseURL string, info PageInfo) (Links, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return Links{}, fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	var links Links
+	cursorMode := info.NextCursor != "" || info.PrevCursor != ""
+
+	switch {
+	case info.NextCursor != "":
+		links.Next = p.link(base, info.Limit, -1, info.NextCursor)
+	case !cursorMode && info.HasMore:
+		links.Next = p.link(base, info.Limit, info.Offset+info.Limit, "")
+	}
+
+	switch {
+	case info.PrevCursor != "":
+		links.Prev = p.link(base, info.Limit, -1, info.PrevCursor)
+	case !cursorMode && info.Offset > 0:
+		prev := info.Offset - info.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		links.Prev = p.link(base, info.Limit, prev, "")
+	}
+	return links, nil
+}
+
+// LinkHeader formats links as an RFC 8288 Link header value, e.g. `<https://...>; rel="next"`
+func (p *PaginationUtil) LinkHeader(links Links) string {
+	var parts []string
+	if links.Next != "" {
+		parts = append(parts, fmt.Sprintf(`<%s>; rel="next"`, links.Next))
+	}
+	if links.Prev != "" {
+		parts = append(parts, fmt.Sprintf(`<%s>; rel="prev"`, links.Prev))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// link builds a page URL; a negative offset omits the offset parameter
+func (p *PaginationUtil) link(base *url.URL, limit, offset int, cursor string) string {
+	u := *base
+	query := u.Query()
+	query.Del(p.config.CursorParam)
+	query.Del(p.config.OffsetParam)
+	if limit > 0 {
+		query.Set(p.config.LimitParam, strconv.Itoa(limit))
+	}
+	if cursor != "" {
+		query.Set(p.config.CursorParam, cursor)
+	} else if offset > 0 {
+		query.Set(p.config.OffsetParam, strconv.Itoa(offset))
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// sign computes the HMAC-SHA256 of an encoded cursor payload
+func (p *PaginationUtil) sign(encoded string) []byte {
+	mac := hmac.New(sha256.New, p.config.CursorSecret)
+	mac.Write([]byte(encoded))
+	return mac.Sum(nil)
+}
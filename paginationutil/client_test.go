@@ -0,0 +1,253 @@
+package paginationutil
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type cursorKeys struct {
+	CreatedAt string `json:"created_at"`
+	ID        int64  `json:"id"`
+}
+
+func TestNewPaginationUtil(t *testing.T) {
+	util := NewPaginationUtil(nil).(*PaginationUtil)
+	if util.config.DefaultLimit != 20 || util.config.MaxLimit != 100 || util.config.CursorParam != "cursor" {
+		t.Errorf("default config = %+v", util.config)
+	}
+
+	custom := NewPaginationUtil(&PaginationConfig{DefaultLimit: 500, MaxLimit: 50, LimitParam: "per_page"}).(*PaginationUtil)
+	if custom.config.DefaultLimit != 50 || custom.config.LimitParam != "per_page" || custom.config.OffsetParam != "offset" {
+		t.Errorf("custom config = %+v, want DefaultLimit capped at 50", custom.config)
+	}
+}
+
+// =================== Test Requests ===================
+
+func TestParseRequest(t *testing.T) {
+	util := NewPaginationUtil(nil)
+
+	tests := []struct {
+		name      string
+		query     string
+		expected  PageRequest
+		expectErr bool
+	}{
+		{"defaults", "", PageRequest{Limit: 20}, false},
+		{"limit and offset", "limit=10&offset=30", PageRequest{Limit: 10, Offset: 30}, false},
+		{"limit capped", "limit=1000", PageRequest{Limit: 100}, false},
+		{"cursor", "cursor=abc&limit=5", PageRequest{Limit: 5, Cursor: "abc"}, false},
+		{"zero limit", "limit=0", PageRequest{}, true},
+		{"non-numeric limit", "limit=ten", PageRequest{}, true},
+		{"negative offset", "offset=-1", PageRequest{}, true},
+		{"cursor with offset", "cursor=abc&offset=10", PageRequest{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			result, err := util.ParseRequest(query)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("ParseRequest(%q) error = %v, expectErr %v", tt.query, err, tt.expectErr)
+			}
+			if tt.expectErr && !errors.Is(err, ErrInvalidPageRequest) {
+				t.Errorf("ParseRequest(%q) error = %v, want ErrInvalidPageRequest", tt.query, err)
+			}
+			if result != tt.expected {
+				t.Errorf("ParseRequest(%q) = %+v, want %+v", tt.query, result, tt.expected)
+			}
+		})
+	}
+}
+
+// =================== Test Cursors ===================
+
+func TestCursorRoundTrip(t *testing.T) {
+	keys := cursorKeys{CreatedAt: "2024-01-02T03:04:05Z", ID: 42}
+
+	for _, secret := range []string{"", "s3cret"} {
+		util := NewPaginationUtil(&PaginationConfig{CursorSecret: []byte(secret)})
+		cursor, err := util.EncodeCursor(keys)
+		if err != nil {
+			t.Fatalf("EncodeCursor() error = %v", err)
+		}
+		if strings.ContainsAny(cursor, "+/= ") {
+			t.Errorf("EncodeCursor() = %q, want URL-safe cursor", cursor)
+		}
+		if signed := strings.Contains(cursor, "."); signed != (secret != "") {
+			t.Errorf("EncodeCursor() = %q, signed = %v with secret %q", cursor, signed, secret)
+		}
+
+		var decoded cursorKeys
+		if err := util.DecodeCursor(cursor, &decoded); err != nil {
+			t.Fatalf("DecodeCursor() error = %v", err)
+		}
+		if decoded != keys {
+			t.Errorf("DecodeCursor() = %+v, want %+v", decoded, keys)
+		}
+	}
+}
+
+func TestDecodeCursorErrors(t *testing.T) {
+	signed := NewPaginationUtil(&PaginationConfig{CursorSecret: []byte("s3cret")})
+	other := NewPaginationUtil(&PaginationConfig{CursorSecret: []byte("other")})
+	unsigned := NewPaginationUtil(nil)
+
+	valid, _ := signed.EncodeCursor(cursorKeys{ID: 1})
+	forged, _ := unsigned.EncodeCursor(cursorKeys{ID: 2})
+	payload, signature, _ := strings.Cut(valid, ".")
+	tampered, _ := unsigned.EncodeCursor(cursorKeys{ID: 999})
+
+	tests := []struct {
+		name   string
+		util   PaginationClient
+		cursor string
+	}{
+		{"missing signature", signed, forged},
+		{"wrong secret", other, valid},
+		{"tampered payload", signed, tampered + "." + signature},
+		{"bad signature encoding", signed, payload + ".!!"},
+		{"bad base64", unsigned, "!!!"},
+		{"bad json", unsigned, "bm90LWpzb24"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out cursorKeys
+			if err := tt.util.DecodeCursor(tt.cursor, &out); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("DecodeCursor() error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+// =================== Test Pages and Links ===================
+
+func TestNewOffsetPage(t *testing.T) {
+	page := NewOffsetPage([]string{"a", "b"}, PageRequest{Limit: 2, Offset: 2}, 5)
+	if !page.HasMore || page.Total != 5 || page.Offset != 2 {
+		t.Errorf("NewOffsetPage() = %+v, want HasMore with total 5", page)
+	}
+	if last := NewOffsetPage([]string{"e"}, PageRequest{Limit: 2, Offset: 4}, 5); last.HasMore {
+		t.Error("NewOffsetPage() on last page should not have more")
+	}
+
+	data, err := json.Marshal(NewOffsetPage[int](nil, PageRequest{Limit: 10}, 0))
+	if err != nil {
+		t.Fatalf("json.Marshal(Page) error = %v", err)
+	}
+	if string(data) != `{"items":[],"limit":10,"has_more":false}` {
+		t.Errorf("json.Marshal(Page) = %s", data)
+	}
+}
+
+func TestTrimPage(t *testing.T) {
+	items, more := TrimPage([]int{1, 2, 3}, 2)
+	if !reflect.DeepEqual(items, []int{1, 2}) || !more {
+		t.Errorf("TrimPage(3 items, 2) = %v, %v; want [1 2], true", items, more)
+	}
+	items, more = TrimPage([]int{1, 2}, 2)
+	if !reflect.DeepEqual(items, []int{1, 2}) || more {
+		t.Errorf("TrimPage(2 items, 2) = %v, %v; want [1 2], false", items, more)
+	}
+}
+
+func TestBuildLinks(t *testing.T) {
+	util := NewPaginationUtil(nil)
+	base := "https://api.example.com/users?sort=name&offset=99"
+
+	tests := []struct {
+		name     string
+		info     PageInfo
+		expected Links
+	}{
+		{
+			name: "first offset page",
+			info: PageInfo{Limit: 10, HasMore: true},
+			expected: Links{
+				Next: "https://api.example.com/users?limit=10&offset=10&sort=name",
+			},
+		},
+		{
+			name: "middle offset page",
+			info: PageInfo{Limit: 10, Offset: 5, HasMore: true},
+			expected: Links{
+				Next: "https://api.example.com/users?limit=10&offset=15&sort=name",
+				Prev: "https://api.example.com/users?limit=10&sort=name",
+			},
+		},
+		{
+			name:     "last offset page",
+			info:     PageInfo{Limit: 10, Offset: 20},
+			expected: Links{Prev: "https://api.example.com/users?limit=10&offset=10&sort=name"},
+		},
+		{
+			name: "cursor page",
+			info: PageInfo{Limit: 10, Offset: 20, NextCursor: "n", PrevCursor: "p", HasMore: true},
+			expected: Links{
+				Next: "https://api.example.com/users?cursor=n&limit=10&sort=name",
+				Prev: "https://api.example.com/users?cursor=p&limit=10&sort=name",
+			},
+		},
+		{
+			name:     "last cursor page",
+			info:     PageInfo{Limit: 10, Offset: 20, PrevCursor: "p"},
+			expected: Links{Prev: "https://api.example.com/users?cursor=p&limit=10&sort=name"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := util.BuildLinks(base, tt.info)
+			if err != nil {
+				t.Fatalf("BuildLinks() error = %v", err)
+			}
+			if result != tt.expected {
+				t.Errorf("BuildLinks() = %+v, want %+v", result, tt.expected)
+			}
+		})
+	}
+
+	if _, err := util.BuildLinks("://bad", PageInfo{}); err == nil {
+		t.Error("BuildLinks() with invalid URL expected error")
+	}
+}
+
+func TestLinkHeader(t *testing.T) {
+	util := NewPaginationUtil(nil)
+
+	tests := []struct {
+		name     string
+		links    Links
+		expected string
+	}{
+		{"none", Links{}, ""},
+		{"next only", Links{Next: "https://x/?cursor=a"}, `<https://x/?cursor=a>; rel="next"`},
+		{"both", Links{Next: "https://x/n", Prev: "https://x/p"}, `<https://x/n>; rel="next", <https://x/p>; rel="prev"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := util.LinkHeader(tt.links); result != tt.expected {
+				t.Errorf("LinkHeader() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkSignedCursorRoundTrip(b *testing.B) {
+	util := NewPaginationUtil(&PaginationConfig{CursorSecret: []byte("s3cret")})
+	keys := cursorKeys{CreatedAt: "2024-01-02T03:04:05Z", ID: 42}
+
+	for i := 0; i < b.N; i++ {
+		cursor, _ := util.EncodeCursor(keys)
+		var out cursorKeys
+		_ = util.DecodeCursor(cursor, &out)
+	}
+}
@@ -0,0 +1,11 @@
+package paginationutil
+
+import "errors"
+
+var (
+	// ErrInvalidCursor is returned when a cursor is malformed or fails signature verification
+	ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+	// ErrInvalidPageRequest is returned when limit/offset/cursor query parameters are invalid
+	ErrInvalidPageRequest = errors.New("invalid page request")
+)
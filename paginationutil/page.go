@@ -0,0 +1,56 @@
+package paginationutil
+
+// PageRequest describes the page a caller asked for
+// Offset and Cursor are mutually exclusive; an empty Cursor with zero Offset is the first page.
+type PageRequest struct {
+	Limit  int    `json:"limit"`
+	Offset int    `json:"offset,omitempty"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// PageInfo carries the position of a page within the full result set
+type PageInfo struct {
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset,omitempty"`
+	Total      int64  `json:"total,omitempty"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+}
+
+// Page is a single page of results; PageInfo's fields are flattened into its JSON form
+type Page[T any] struct {
+	Items []T `json:"items"`
+	PageInfo
+}
+
+// Links holds the URLs of the neighbouring pages, empty when there is none
+type Links struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// NewOffsetPage builds an offset-based page from the items at req.Offset and the total count
+func NewOffsetPage[T any](items []T, req PageRequest, total int64) Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return Page[T]{
+		Items: items,
+		PageInfo: PageInfo{
+			Limit:   req.Limit,
+			Offset:  req.Offset,
+			Total:   total,
+			HasMore: int64(req.Offset+len(items)) < total,
+		},
+	}
+}
+
+// TrimPage supports the "fetch limit+1" pattern for cursor pagination
+// It returns at most limit items and whether more items were available.
+func TrimPage[T any](items []T, limit int) ([]T, bool) {
+	if limit < 0 || len(items) <= limit {
+		return items, false
+	}
+	return items[:limit], true
+}
@@ -0,0 +1,58 @@
+package ptrutil
+
+// Ptr returns a pointer to a copy of v
+// Useful for optional fields in API models, e.g. Request{Limit: ptrutil.Ptr(10)}.
+func Ptr[T any](v T) *T {
+	return &v
+}
+
+// Deref returns the value p points to, or def when p is nil
+func Deref[T any](p *T, def T) T {
+	if p == nil {
+		return def
+	}
+	return *p
+}
+
+// DerefZero returns the value p points to, or the zero value of T when p is nil
+func DerefZero[T any](p *T) T {
+	var zero T
+	return Deref(p, zero)
+}
+
+// Equal reports whether p1 and p2 are both nil or both non-nil with equal values
+func Equal[T comparable](p1, p2 *T) bool {
+	if p1 == nil || p2 == nil {
+		return p1 == p2
+	}
+	return *p1 == *p2
+}
+
+// ToPtrSlice returns a slice of pointers to copies of each element of values
+// A nil slice returns nil.
+func ToPtrSlice[T any](values []T) []*T {
+	if values == nil {
+		return nil
+	}
+	ptrs := make([]*T, len(values))
+	for i := range values {
+		v := values[i]
+		ptrs[i] = &v
+	}
+	return ptrs
+}
+
+// FromPtrSlice dereferences each pointer, skipping nil entries
+// A nil slice returns nil.
+func FromPtrSlice[T any](ptrs []*T) []T {
+	if ptrs == nil {
+		return nil
+	}
+	values := make([]T, 0, len(ptrs))
+	for _, p := range ptrs {
+		if p != nil {
+			values = append(values, *p)
+		}
+	}
+	return values
+}
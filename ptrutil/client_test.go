@@ -0,0 +1,103 @@
+package ptrutil
+
+import (
+	"reflect"
+	"testing"
+)
+
+// =================== Test Pointer Helpers ===================
+
+func TestPtr(t *testing.T) {
+	v := 42
+	p := Ptr(v)
+	if p == nil || *p != 42 {
+		t.Fatalf("Ptr(42) = %v, want pointer to 42", p)
+	}
+	if p == &v {
+		t.Error("Ptr() should return a pointer to a copy")
+	}
+	if s := Ptr("x"); *s != "x" {
+		t.Errorf("Ptr(\"x\") = %v, want x", *s)
+	}
+}
+
+func TestDeref(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    *string
+		def      string
+		expected string
+	}{
+		{"nil uses default", nil, "fallback", "fallback"},
+		{"non-nil value", Ptr("value"), "fallback", "value"},
+		{"non-nil empty value", Ptr(""), "fallback", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Deref(tt.input, tt.def); result != tt.expected {
+				t.Errorf("Deref() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+
+	if result := DerefZero[int](nil); result != 0 {
+		t.Errorf("DerefZero(nil) = %v, want 0", result)
+	}
+	if result := DerefZero(Ptr(7)); result != 7 {
+		t.Errorf("DerefZero(7) = %v, want 7", result)
+	}
+}
+
+func TestEqual(t *testing.T) {
+	tests := []struct {
+		name     string
+		p1, p2   *int
+		expected bool
+	}{
+		{"both nil", nil, nil, true},
+		{"first nil", nil, Ptr(1), false},
+		{"second nil", Ptr(1), nil, false},
+		{"equal values", Ptr(1), Ptr(1), true},
+		{"different values", Ptr(1), Ptr(2), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Equal(tt.p1, tt.p2); result != tt.expected {
+				t.Errorf("Equal() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPtrSlices(t *testing.T) {
+	values := []int{1, 2, 3}
+	ptrs := ToPtrSlice(values)
+	if len(ptrs) != 3 || *ptrs[0] != 1 || *ptrs[2] != 3 {
+		t.Fatalf("ToPtrSlice() = %v, want pointers to 1, 2, 3", ptrs)
+	}
+	*ptrs[0] = 100
+	if values[0] != 1 {
+		t.Error("ToPtrSlice() should point to copies, not the original elements")
+	}
+
+	if result := FromPtrSlice([]*int{Ptr(1), nil, Ptr(3)}); !reflect.DeepEqual(result, []int{1, 3}) {
+		t.Errorf("FromPtrSlice() = %v, want [1 3]", result)
+	}
+	if ToPtrSlice[int](nil) != nil || FromPtrSlice[int](nil) != nil {
+		t.Error("nil slices should convert to nil")
+	}
+	if result := FromPtrSlice([]*int{}); result == nil || len(result) != 0 {
+		t.Errorf("FromPtrSlice(empty) = %v, want empty non-nil slice", result)
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkToPtrSlice(b *testing.B) {
+	values := make([]int, 1000)
+	for i := 0; i < b.N; i++ {
+		_ = ToPtrSlice(values)
+	}
+}
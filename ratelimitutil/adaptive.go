@@ -0,0 +1,227 @@
+package ratelimitutil
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FeedbackReceiver is implemented by limiters that adapt to the responses of the upstream they guard
+// httputil forwards every response to a RateLimiter implementing it.
+type FeedbackReceiver interface {
+	// Feedback reports one upstream response; retryAfter is its parsed Retry-After header, 0 when absent
+	Feedback(statusCode int, retryAfter time.Duration)
+}
+
+// AdaptiveConfig holds configuration for an AdaptiveLimiter
+type AdaptiveConfig struct {
+	// InitialRate is the rate in requests per second before any feedback arrives
+	InitialRate float64
+
+	// MinRate and MaxRate bound the adjusted rate
+	MinRate float64
+	MaxRate float64
+
+	// Burst is how many requests are allowed at once after an idle period
+	Burst int
+
+	// DecreaseFactor multiplies the rate on a 429 or 503 response (between 0 and 1)
+	DecreaseFactor float64
+
+	// IncreaseStep is added to the rate after every IncreaseInterval without throttling
+	IncreaseStep float64
+
+	// IncreaseInterval is how long the rate is held before probing upward again; throttling responses
+	// arriving within it of the last decrease count as one signal, since they usually answer the same burst
+	IncreaseInterval time.Duration
+}
+
+// DefaultAdaptiveConfig returns default configuration
+func DefaultAdaptiveConfig() *AdaptiveConfig {
+	return &AdaptiveConfig{
+		InitialRate:      10,
+		MinRate:          0.1,
+		MaxRate:          1000,
+		Burst:            1,
+		DecreaseFactor:   0.5,
+		IncreaseStep:     1,
+		IncreaseInterval: time.Second,
+	}
+}
+
+// AdaptiveLimiterClient defines the interface for a limiter that tunes its own rate from upstream feedback
+type AdaptiveLimiterClient interface {
+	Limiter
+	FeedbackReceiver
+
+	// Rate returns the current rate in requests per second
+	Rate() float64
+}
+
+// AdaptiveLimiter is a token bucket whose rate follows the upstream's actual allowance
+// It backs off multiplicatively on 429 and 503 responses and probes upward additively while requests
+// succeed (AIMD), so the rate settles just below the point where the upstream starts throttling.
+// A Retry-After value pauses all requests for that long.
+type AdaptiveLimiter struct {
+	config AdaptiveConfig
+
+	mu           sync.Mutex
+	rate         float64
+	tokens       float64
+	last         time.Time
+	pausedUntil  time.Time
+	lastDecrease time.Time
+	lastIncrease time.Time
+	now          func() time.Time
+}
+
+// NewAdaptiveLimiter creates an adaptive limiter; feed it responses with Feedback or install it as
+// httputil's HTTPConfig.RateLimiter
+// Pass nil for config to use all defaults, or pass config with only the properties you want to override
+func NewAdaptiveLimiter(config *AdaptiveConfig) AdaptiveLimiterClient {
+	defaults := DefaultAdaptiveConfig()
+
+	if config != nil {
+		if config.InitialRate > 0 {
+			defaults.InitialRate = config.InitialRate
+		}
+		if config.MinRate > 0 {
+			defaults.MinRate = config.MinRate
+		}
+		if config.MaxRate > 0 {
+			defaults.MaxRate = config.MaxRate
+		}
+		if config.Burst > 0 {
+			defaults.Burst = config.Burst
+		}
+		if config.DecreaseFactor > 0 && config.DecreaseFactor < 1 {
+			defaults.DecreaseFactor = config.DecreaseFactor
+		}
+		if config.IncreaseStep > 0 {
+			defaults.IncreaseStep = config.IncreaseStep
+		}
+		if config.IncreaseInterval > 0 {
+			defaults.IncreaseInterval = config.IncreaseInterval
+		}
+	}
+	if defaults.MaxRate < defaults.MinRate {
+		defaults.MaxRate = defaults.MinRate
+	}
+
+	l := &AdaptiveLimiter{
+		config: *defaults,
+		tokens: float64(defaults.Burst),
+		now:    time.Now,
+	}
+	l.rate = l.clamp(defaults.InitialRate)
+	return l
+}
+
+// Allow takes a token and reports whether the request may proceed
+func (l *AdaptiveLimiter) Allow() bool {
+	return l.Reserve().Allowed
+}
+
+// Reserve takes a token when one is available and reports the decision
+func (l *AdaptiveLimiter) Reserve() Reservation {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Before(l.pausedUntil) {
+		return Reservation{RetryAfter: l.pausedUntil.Sub(now)}
+	}
+	l.refill(now)
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return Reservation{Allowed: true, Remaining: int(l.tokens)}
+	}
+	return Reservation{RetryAfter: durationFromSeconds((1 - l.tokens) / l.rate)}
+}
+
+// Wait blocks until a token is taken or ctx is done
+func (l *AdaptiveLimiter) Wait(ctx context.Context) error {
+	return wait(ctx, l.Reserve)
+}
+
+// Rate returns the current rate in requests per second
+func (l *AdaptiveLimiter) Rate() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rate
+}
+
+// Feedback adjusts the rate from one upstream response
+// 429 and 503 lower the rate and, with Retry-After, pause requests; other statuses below 500 count as
+// successes that let the rate grow. Remaining 5xx statuses say nothing about the allowance and are ignored.
+func (l *AdaptiveLimiter) Feedback(statusCode int, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	switch {
+	case statusCode == http.StatusTooManyRequests || statusCode == http.StatusServiceUnavailable:
+		l.throttled(now, retryAfter)
+	case statusCode > 0 && statusCode < http.StatusInternalServerError:
+		l.succeeded(now)
+	}
+}
+
+// throttled lowers the rate once per IncreaseInterval and honours retryAfter
+func (l *AdaptiveLimiter) throttled(now time.Time, retryAfter time.Duration) {
+	if retryAfter > 0 {
+		if until := now.Add(retryAfter); until.After(l.pausedUntil) {
+			l.pausedUntil = until
+		}
+		// Refill restarts after the pause, so it does not end with a burst
+		l.tokens = 0
+		l.last = l.pausedUntil
+	}
+	if !l.lastDecrease.IsZero() && now.Sub(l.lastDecrease) < l.config.IncreaseInterval {
+		return
+	}
+	l.refill(now)
+	l.rate = l.clamp(l.rate * l.config.DecreaseFactor)
+	l.lastDecrease = now
+	l.lastIncrease = now
+}
+
+// succeeded raises the rate by IncreaseStep when IncreaseInterval has passed since the last change
+func (l *AdaptiveLimiter) succeeded(now time.Time) {
+	if l.lastIncrease.IsZero() {
+		l.lastIncrease = now
+		return
+	}
+	if now.Sub(l.lastIncrease) < l.config.IncreaseInterval {
+		return
+	}
+	l.refill(now)
+	l.rate = l.clamp(l.rate + l.config.IncreaseStep)
+	l.lastIncrease = now
+}
+
+// refill adds the tokens earned at the current rate since the last update
+func (l *AdaptiveLimiter) refill(now time.Time) {
+	if !l.last.IsZero() && now.After(l.last) {
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if burst := float64(l.config.Burst); l.tokens > burst {
+			l.tokens = burst
+		}
+	}
+	if now.After(l.last) {
+		l.last = now
+	}
+}
+
+// clamp keeps rate within MinRate and MaxRate
+func (l *AdaptiveLimiter) clamp(rate float64) float64 {
+	if rate < l.config.MinRate {
+		return l.config.MinRate
+	}
+	if rate > l.config.MaxRate {
+		return l.config.MaxRate
+	}
+	return rate
+}
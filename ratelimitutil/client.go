@@ -0,0 +1,68 @@
+package ratelimitutil
+
+import (
+	"context"
+	"time"
+)
+
+// Reservation is the outcome of a single rate limit decision
+type Reservation struct {
+	// Allowed reports whether the request may proceed now
+	Allowed bool
+
+	// Remaining is how many more requests would be allowed right now
+	Remaining int
+
+	// RetryAfter is how long to wait before a request could be allowed (0 when Allowed)
+	RetryAfter time.Duration
+}
+
+// Limiter defines the interface shared by the in-process rate limiters
+type Limiter interface {
+	// Allow takes a token and reports whether the request may proceed
+	Allow() bool
+
+	// Reserve takes a token when one is available and reports the full decision,
+	// e.g. for Retry-After and X-RateLimit-Remaining headers
+	Reserve() Reservation
+
+	// Wait blocks until a token is taken or ctx is done
+	Wait(ctx context.Context) error
+}
+
+// wait repeatedly calls reserve, sleeping for RetryAfter between refusals, until allowed or ctx is done
+func wait(ctx context.Context, reserve func() Reservation) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		reservation := reserve()
+		if reservation.Allowed {
+			return nil
+		}
+
+		delay := reservation.RetryAfter
+		if delay <= 0 {
+			delay = time.Millisecond // guard against spinning on rounding at window edges
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// durationFromSeconds converts fractional seconds to a duration, rounding up so waits are never short
+func durationFromSeconds(seconds float64) time.Duration {
+	d := time.Duration(seconds * float64(time.Second))
+	if float64(d) < seconds*float64(time.Second) {
+		d++
+	}
+	return d
+}
@@ -0,0 +1,410 @@
+package ratelimitutil
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a manually advanced clock for deterministic limiter tests
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// allowCount calls Allow n times and returns how many were allowed
+func allowCount(limiter Limiter, n int) int {
+	allowed := 0
+	for i := 0; i < n; i++ {
+		if limiter.Allow() {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+// =================== Test TokenBucket ===================
+
+func TestTokenBucket(t *testing.T) {
+	clock := newFakeClock()
+	bucket := NewTokenBucket(10, 5).(*TokenBucket)
+	bucket.now = clock.Now
+
+	if allowed := allowCount(bucket, 10); allowed != 5 {
+		t.Errorf("initial burst allowed %d, want 5", allowed)
+	}
+
+	reservation := bucket.Reserve()
+	if reservation.Allowed || reservation.RetryAfter != 100*time.Millisecond {
+		t.Errorf("Reserve() on empty bucket = %+v, want refused with 100ms RetryAfter", reservation)
+	}
+
+	clock.Advance(250 * time.Millisecond)
+	if allowed := allowCount(bucket, 5); allowed != 2 {
+		t.Errorf("after 250ms allowed %d, want 2", allowed)
+	}
+
+	clock.Advance(time.Hour)
+	if reservation := bucket.Reserve(); !reservation.Allowed || reservation.Remaining != 4 {
+		t.Errorf("Reserve() after refill = %+v, want allowed with 4 remaining (capped at burst)", reservation)
+	}
+}
+
+func TestTokenBucketDisabled(t *testing.T) {
+	if allowed := allowCount(NewTokenBucket(0, 1), 100); allowed != 100 {
+		t.Errorf("disabled bucket allowed %d, want 100", allowed)
+	}
+}
+
+// =================== Test AdaptiveLimiter ===================
+
+func TestAdaptiveLimiter(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewAdaptiveLimiter(&AdaptiveConfig{InitialRate: 8, MinRate: 1, MaxRate: 10, Burst: 2, IncreaseStep: 1, IncreaseInterval: time.Second}).(*AdaptiveLimiter)
+	limiter.now = clock.Now
+
+	if allowed := allowCount(limiter, 5); allowed != 2 {
+		t.Errorf("initial burst allowed %d, want 2", allowed)
+	}
+
+	// Throttling halves the rate; a second 429 answering the same burst is ignored
+	limiter.Feedback(http.StatusTooManyRequests, 0)
+	limiter.Feedback(http.StatusTooManyRequests, 0)
+	if rate := limiter.Rate(); rate != 4 {
+		t.Errorf("Rate() after throttling = %v, want 4", rate)
+	}
+	if reservation := limiter.Reserve(); reservation.Allowed || reservation.RetryAfter != 250*time.Millisecond {
+		t.Errorf("Reserve() = %+v, want refused with 250ms RetryAfter at the lowered rate", reservation)
+	}
+
+	// Successes within the interval of the last change do not raise the rate
+	clock.Advance(500 * time.Millisecond)
+	limiter.Feedback(http.StatusOK, 0)
+	if rate := limiter.Rate(); rate != 4 {
+		t.Errorf("Rate() within interval = %v, want 4", rate)
+	}
+	clock.Advance(600 * time.Millisecond)
+	limiter.Feedback(http.StatusOK, 0)
+	if rate := limiter.Rate(); rate != 5 {
+		t.Errorf("Rate() after successful interval = %v, want 5", rate)
+	}
+
+	// Unrelated server errors carry no signal
+	clock.Advance(2 * time.Second)
+	limiter.Feedback(http.StatusInternalServerError, 0)
+	if rate := limiter.Rate(); rate != 5 {
+		t.Errorf("Rate() after 500 = %v, want 5", rate)
+	}
+
+	// Retry-After pauses requests and the refill restarts after it
+	limiter.Feedback(http.StatusServiceUnavailable, 3*time.Second)
+	if rate := limiter.Rate(); rate != 2.5 {
+		t.Errorf("Rate() after 503 = %v, want 2.5", rate)
+	}
+	if reservation := limiter.Reserve(); reservation.Allowed || reservation.RetryAfter != 3*time.Second {
+		t.Errorf("Reserve() during pause = %+v, want refused with 3s RetryAfter", reservation)
+	}
+	clock.Advance(3 * time.Second)
+	if limiter.Allow() {
+		t.Error("Allow() right after the pause should wait for a token")
+	}
+	clock.Advance(400 * time.Millisecond)
+	if !limiter.Allow() {
+		t.Error("Allow() should succeed once a token has refilled after the pause")
+	}
+}
+
+func TestAdaptiveLimiterBounds(t *testing.T) {
+	clock := newFakeClock()
+	limiter := NewAdaptiveLimiter(&AdaptiveConfig{InitialRate: 50, MinRate: 2, MaxRate: 3, IncreaseInterval: time.Second}).(*AdaptiveLimiter)
+	limiter.now = clock.Now
+
+	if rate := limiter.Rate(); rate != 3 {
+		t.Errorf("Rate() = %v, want InitialRate clamped to MaxRate 3", rate)
+	}
+	for i := 0; i < 5; i++ {
+		clock.Advance(2 * time.Second)
+		limiter.Feedback(http.StatusTooManyRequests, 0)
+	}
+	if rate := limiter.Rate(); rate != 2 {
+		t.Errorf("Rate() after repeated throttling = %v, want MinRate 2", rate)
+	}
+	for i := 0; i < 5; i++ {
+		clock.Advance(2 * time.Second)
+		limiter.Feedback(http.StatusOK, 0)
+	}
+	if rate := limiter.Rate(); rate != 3 {
+		t.Errorf("Rate() after repeated successes = %v, want MaxRate 3", rate)
+	}
+
+	defaults := NewAdaptiveLimiter(nil)
+	if rate := defaults.Rate(); rate != DefaultAdaptiveConfig().InitialRate {
+		t.Errorf("Rate() with nil config = %v, want %v", rate, DefaultAdaptiveConfig().InitialRate)
+	}
+	var _ FeedbackReceiver = defaults
+}
+
+// =================== Test Windows ===================
+
+func TestFixedWindow(t *testing.T) {
+	clock := newFakeClock()
+	window := NewFixedWindow(3, time.Minute).(*FixedWindow)
+	window.now = clock.Now
+
+	clock.Advance(50 * time.Second)
+	if allowed := allowCount(window, 5); allowed != 3 {
+		t.Errorf("first window allowed %d, want 3", allowed)
+	}
+	if reservation := window.Reserve(); reservation.Allowed || reservation.RetryAfter != 10*time.Second {
+		t.Errorf("Reserve() = %+v, want refused until the window resets in 10s", reservation)
+	}
+
+	clock.Advance(10 * time.Second)
+	if reservation := window.Reserve(); !reservation.Allowed || reservation.Remaining != 2 {
+		t.Errorf("Reserve() in new window = %+v, want allowed with 2 remaining", reservation)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	clock := newFakeClock()
+	window := NewSlidingWindow(10, time.Minute).(*SlidingWindow)
+	window.now = clock.Now
+
+	clock.Advance(50 * time.Second)
+	if allowed := allowCount(window, 20); allowed != 10 {
+		t.Errorf("first window allowed %d, want 10", allowed)
+	}
+
+	// 15s into the next window the previous count still weighs 75%, leaving room for 2
+	clock.Advance(25 * time.Second)
+	if allowed := allowCount(window, 10); allowed != 2 {
+		t.Errorf("boundary allowed %d, want 2 (fixed windows would allow 10)", allowed)
+	}
+
+	reservation := window.Reserve()
+	if reservation.Allowed || reservation.RetryAfter <= 0 {
+		t.Fatalf("Reserve() = %+v, want refused with a positive RetryAfter", reservation)
+	}
+	clock.Advance(reservation.RetryAfter)
+	if !window.Allow() {
+		t.Error("Allow() after RetryAfter = false, want true")
+	}
+
+	clock.Advance(3 * time.Minute)
+	if allowed := allowCount(window, 20); allowed != 10 {
+		t.Errorf("after idle period allowed %d, want 10", allowed)
+	}
+}
+
+func TestWait(t *testing.T) {
+	limiter := NewTokenBucket(100, 1)
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	start := time.Now()
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 5*time.Millisecond {
+		t.Errorf("Wait() returned after %v, want about 10ms", elapsed)
+	}
+
+	slow := NewFixedWindow(1, time.Hour)
+	slow.Allow()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := slow.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Wait() error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+// =================== Test KeyedLimiter ===================
+
+func TestKeyedLimiter(t *testing.T) {
+	keyed := NewKeyedLimiter(func() Limiter { return NewFixedWindow(2, time.Hour) }, &KeyedConfig{MaxKeys: 2})
+
+	for _, key := range []string{"a", "a", "b"} {
+		if !keyed.Allow(key) {
+			t.Errorf("Allow(%q) = false, want true", key)
+		}
+	}
+	if keyed.Allow("a") {
+		t.Error("Allow(a) third time = true, want false")
+	}
+	if reservation := keyed.Reserve("b"); !reservation.Allowed || reservation.Remaining != 0 {
+		t.Errorf("Reserve(b) = %+v, want allowed with 0 remaining", reservation)
+	}
+
+	// A third key evicts the least recently used one
+	keyed.Allow("c")
+	if keyed.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", keyed.Len())
+	}
+	if !keyed.Allow("a") {
+		t.Error("Allow(a) after eviction = false, want a fresh limiter")
+	}
+
+	if err := keyed.Wait(context.Background(), "d"); err != nil {
+		t.Errorf("Wait(d) error = %v", err)
+	}
+}
+
+func TestKeyedLimiterConcurrent(t *testing.T) {
+	keyed := NewKeyedLimiter(func() Limiter { return NewFixedWindow(50, time.Hour) }, nil)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if keyed.Allow("shared") {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 50 {
+		t.Errorf("concurrent Allow() allowed %d, want 50", allowed)
+	}
+}
+
+// =================== Test StoreLimiter ===================
+
+func TestStoreLimiter(t *testing.T) {
+	tests := []struct {
+		name   string
+		fixed  bool
+		second int // allowed in the following window, 15s in
+	}{
+		{"sliding", false, 2},
+		{"fixed", true, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := newFakeClock()
+			store := NewMemoryStore().(*MemoryStore)
+			store.now = clock.Now
+			limiter := NewStoreLimiter(store, &StoreLimiterConfig{Limit: 10, Window: time.Minute, Fixed: tt.fixed}).(*StoreLimiter)
+			limiter.now = clock.Now
+			ctx := context.Background()
+
+			count := func(key string, n int) int {
+				allowed := 0
+				for i := 0; i < n; i++ {
+					ok, err := limiter.Allow(ctx, key)
+					if err != nil {
+						t.Fatalf("Allow() error = %v", err)
+					}
+					if ok {
+						allowed++
+					}
+				}
+				return allowed
+			}
+
+			clock.Advance(50 * time.Second)
+			if allowed := count("user", 20); allowed != 10 {
+				t.Errorf("first window allowed %d, want 10", allowed)
+			}
+			if allowed := count("other", 3); allowed != 3 {
+				t.Errorf("other key allowed %d, want 3", allowed)
+			}
+
+			reservation, _ := limiter.Reserve(ctx, "user")
+			if reservation.Allowed || reservation.RetryAfter <= 0 {
+				t.Errorf("Reserve() = %+v, want refused with a positive RetryAfter", reservation)
+			}
+
+			clock.Advance(25 * time.Second)
+			if allowed := count("user", 20); allowed != tt.second {
+				t.Errorf("next window allowed %d, want %d", allowed, tt.second)
+			}
+		})
+	}
+}
+
+// failingStore is a Store whose operations always fail
+type failingStore struct{}
+
+func (failingStore) Increment(context.Context, string, int64, time.Duration) (int64, error) {
+	return 0, errors.New("store down")
+}
+
+func (failingStore) Get(context.Context, string) (int64, error) {
+	return 0, errors.New("store down")
+}
+
+func TestStoreLimiterErrors(t *testing.T) {
+	limiter := NewStoreLimiter(failingStore{}, nil)
+
+	if _, err := limiter.Allow(context.Background(), "user"); err == nil {
+		t.Error("Allow() expected store error")
+	}
+	if err := limiter.Wait(context.Background(), "user"); err == nil {
+		t.Error("Wait() expected store error")
+	}
+}
+
+func TestMemoryStoreExpiry(t *testing.T) {
+	clock := newFakeClock()
+	store := NewMemoryStore().(*MemoryStore)
+	store.now = clock.Now
+	ctx := context.Background()
+
+	if value, _ := store.Increment(ctx, "k", 2, time.Second); value != 2 {
+		t.Errorf("Increment() = %d, want 2", value)
+	}
+	if value, _ := store.Get(ctx, "k"); value != 2 {
+		t.Errorf("Get() = %d, want 2", value)
+	}
+
+	clock.Advance(time.Second)
+	if value, _ := store.Get(ctx, "k"); value != 0 {
+		t.Errorf("Get() after expiry = %d, want 0", value)
+	}
+	if value, _ := store.Increment(ctx, "k", 1, time.Second); value != 1 {
+		t.Errorf("Increment() after expiry = %d, want 1", value)
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkTokenBucketAllow(b *testing.B) {
+	limiter := NewTokenBucket(1e9, 1000)
+	for i := 0; i < b.N; i++ {
+		limiter.Allow()
+	}
+}
+
+func BenchmarkKeyedLimiterAllow(b *testing.B) {
+	keyed := NewKeyedLimiter(func() Limiter { return NewTokenBucket(1e9, 1000) }, nil)
+	for i := 0; i < b.N; i++ {
+		keyed.Allow("key")
+	}
+}
@@ -0,0 +1,98 @@
+package ratelimitutil
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/cacheutil"
+)
+
+// KeyedConfig holds configuration for a per-key limiter map
+type KeyedConfig struct {
+	// MaxKeys bounds how many limiters are kept; the least recently used key is evicted beyond it
+	MaxKeys int
+
+	// IdleTTL evicts a key's limiter after it has not been used for this long
+	// Keep it longer than the window (or refill time) so evicted keys do not regain a full allowance early.
+	IdleTTL time.Duration
+}
+
+// DefaultKeyedConfig returns default configuration
+func DefaultKeyedConfig() *KeyedConfig {
+	return &KeyedConfig{
+		MaxKeys: 10000,
+		IdleTTL: 10 * time.Minute,
+	}
+}
+
+// KeyedLimiterClient defines the interface for limiting per key, e.g. per client IP or API key
+type KeyedLimiterClient interface {
+	Allow(key string) bool
+	Reserve(key string) Reservation
+	Wait(ctx context.Context, key string) error
+	Len() int
+}
+
+// KeyedLimiter lazily creates one Limiter per key and evicts idle keys
+type KeyedLimiter struct {
+	factory func() Limiter
+	idleTTL time.Duration
+
+	mu       sync.Mutex
+	limiters cacheutil.Cache[string, Limiter]
+}
+
+// NewKeyedLimiter creates a per-key limiter map; factory builds the limiter for a new key
+// Pass nil for config to use all defaults, or pass config with only the properties you want to override
+func NewKeyedLimiter(factory func() Limiter, config *KeyedConfig) KeyedLimiterClient {
+	defaults := DefaultKeyedConfig()
+
+	if config != nil {
+		if config.MaxKeys > 0 {
+			defaults.MaxKeys = config.MaxKeys
+		}
+		if config.IdleTTL > 0 {
+			defaults.IdleTTL = config.IdleTTL
+		}
+	}
+
+	return &KeyedLimiter{
+		factory:  factory,
+		idleTTL:  defaults.IdleTTL,
+		limiters: cacheutil.NewMemoryCache[string, Limiter](&cacheutil.CacheConfig{MaxEntries: defaults.MaxKeys}),
+	}
+}
+
+// Allow takes a token for key and reports whether the request may proceed
+func (k *KeyedLimiter) Allow(key string) bool {
+	return k.limiter(key).Allow()
+}
+
+// Reserve takes a token for key when one is available and reports the decision
+func (k *KeyedLimiter) Reserve(key string) Reservation {
+	return k.limiter(key).Reserve()
+}
+
+// Wait blocks until a token for key is taken or ctx is done
+func (k *KeyedLimiter) Wait(ctx context.Context, key string) error {
+	return k.limiter(key).Wait(ctx)
+}
+
+// Len returns the number of keys currently tracked
+func (k *KeyedLimiter) Len() int {
+	return k.limiters.Len()
+}
+
+// limiter returns the limiter for key, creating it on first use and refreshing its idle expiry
+func (k *KeyedLimiter) limiter(key string) Limiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	limiter, ok := k.limiters.Get(key)
+	if !ok {
+		limiter = k.factory()
+	}
+	k.limiters.SetWithTTL(key, limiter, k.idleTTL)
+	return limiter
+}
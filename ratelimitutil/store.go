@@ -0,0 +1,204 @@
+package ratelimitutil
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Store holds rate limit counters that may be shared between processes
+// Increment must be atomic; with Redis it maps to INCRBY followed by PEXPIRE when the key is new.
+type Store interface {
+	// Increment adds n to the counter at key, creating it with the given expiry, and returns the new value
+	Increment(ctx context.Context, key string, n int64, expiry time.Duration) (int64, error)
+
+	// Get returns the counter at key, or 0 when it does not exist
+	Get(ctx context.Context, key string) (int64, error)
+}
+
+// MemoryStore is an in-process Store for single-instance use and tests
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]memoryCounter
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// memoryCounter is a counter value with its expiry
+type memoryCounter struct {
+	value     int64
+	expiresAt time.Time
+}
+
+// NewMemoryStore creates an in-process counter Store
+func NewMemoryStore() Store {
+	return &MemoryStore{counters: make(map[string]memoryCounter), now: time.Now}
+}
+
+// Increment adds n to the counter at key, creating it with the given expiry
+func (m *MemoryStore) Increment(ctx context.Context, key string, n int64, expiry time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+	counter, ok := m.counters[key]
+	if !ok || !now.Before(counter.expiresAt) {
+		counter = memoryCounter{expiresAt: now.Add(expiry)}
+	}
+	counter.value += n
+	m.counters[key] = counter
+	return counter.value, nil
+}
+
+// Get returns the counter at key, or 0 when it does not exist
+func (m *MemoryStore) Get(ctx context.Context, key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counter, ok := m.counters[key]
+	if !ok || !m.now().Before(counter.expiresAt) {
+		return 0, nil
+	}
+	return counter.value, nil
+}
+
+// sweep drops expired counters, at most once per second to keep Increment cheap
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < time.Second {
+		return
+	}
+	m.lastSweep = now
+	for key, counter := range m.counters {
+		if !now.Before(counter.expiresAt) {
+			delete(m.counters, key)
+		}
+	}
+}
+
+// StoreLimiterConfig holds configuration for a Store-backed limiter
+type StoreLimiterConfig struct {
+	Limit  int
+	Window time.Duration
+
+	// Fixed uses aligned fixed windows instead of the default sliding estimate (see SlidingWindow)
+	Fixed bool
+
+	// KeyPrefix namespaces counters when the store is shared with other data
+	KeyPrefix string
+}
+
+// DefaultStoreLimiterConfig returns default configuration
+func DefaultStoreLimiterConfig() *StoreLimiterConfig {
+	return &StoreLimiterConfig{
+		Limit:     60,
+		Window:    time.Minute,
+		Fixed:     false,
+		KeyPrefix: "ratelimit:",
+	}
+}
+
+// StoreLimiterClient defines the interface for limiting per key across processes
+type StoreLimiterClient interface {
+	Allow(ctx context.Context, key string) (bool, error)
+	Reserve(ctx context.Context, key string) (Reservation, error)
+	Wait(ctx context.Context, key string) error
+}
+
+// StoreLimiter applies fixed or sliding window limits using counters in a shared Store
+type StoreLimiter struct {
+	store  Store
+	config StoreLimiterConfig
+	now    func() time.Time
+}
+
+// NewStoreLimiter creates a distributed window limiter backed by store
+// Pass nil for config to use all defaults, or pass config with only the properties you want to override
+func NewStoreLimiter(store Store, config *StoreLimiterConfig) StoreLimiterClient {
+	defaults := DefaultStoreLimiterConfig()
+
+	if config != nil {
+		if config.Limit > 0 {
+			defaults.Limit = config.Limit
+		}
+		if config.Window > 0 {
+			defaults.Window = config.Window
+		}
+		if config.Fixed {
+			defaults.Fixed = true
+		}
+		if config.KeyPrefix != "" {
+			defaults.KeyPrefix = config.KeyPrefix
+		}
+	}
+
+	return &StoreLimiter{store: store, config: *defaults, now: time.Now}
+}
+
+// Allow takes a slot for key and reports whether the request may proceed
+func (s *StoreLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	reservation, err := s.Reserve(ctx, key)
+	return reservation.Allowed, err
+}
+
+// Reserve takes a slot for key when one is available and reports the decision
+// The slot is counted optimistically and given back when the request is refused.
+func (s *StoreLimiter) Reserve(ctx context.Context, key string) (Reservation, error) {
+	window := s.config.Window
+	now := s.now()
+	index := now.UnixNano() / int64(window)
+	elapsed := time.Duration(now.UnixNano() - index*int64(window))
+	currentKey := s.counterKey(key, index)
+
+	// Counters live for two windows so the sliding estimate can still read the previous one
+	current, err := s.store.Increment(ctx, currentKey, 1, 2*window)
+	if err != nil {
+		return Reservation{}, fmt.Errorf("failed to increment rate limit counter: %w", err)
+	}
+
+	var previous int64
+	if !s.config.Fixed {
+		previous, err = s.store.Get(ctx, s.counterKey(key, index-1))
+		if err != nil {
+			return Reservation{}, fmt.Errorf("failed to read rate limit counter: %w", err)
+		}
+	} else {
+		elapsed = 0 // without a previous count the estimate is just the current window
+	}
+
+	allowed := false
+	reservation := slidingDecision(s.config.Limit, window, elapsed, previous, current-1, func() { allowed = true })
+	if !allowed {
+		if _, err := s.store.Increment(ctx, currentKey, -1, 2*window); err != nil {
+			return Reservation{}, fmt.Errorf("failed to release rate limit counter: %w", err)
+		}
+		if s.config.Fixed {
+			reservation.RetryAfter = window - time.Duration(now.UnixNano()-index*int64(window))
+		}
+	}
+	return reservation, nil
+}
+
+// Wait blocks until a slot for key is taken, ctx is done, or the store fails
+func (s *StoreLimiter) Wait(ctx context.Context, key string) error {
+	var storeErr error
+	err := wait(ctx, func() Reservation {
+		reservation, err := s.Reserve(ctx, key)
+		if err != nil {
+			storeErr = err
+			return Reservation{Allowed: true} // stop waiting; the error is returned below
+		}
+		return reservation
+	})
+	if storeErr != nil {
+		return storeErr
+	}
+	return err
+}
+
+// counterKey names the counter for key in the window with the given index
+func (s *StoreLimiter) counterKey(key string, index int64) string {
+	return s.config.KeyPrefix + key + ":" + strconv.FormatInt(index, 10)
+}
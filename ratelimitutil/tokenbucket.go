@@ -0,0 +1,67 @@
+package ratelimitutil
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// TokenBucket refills at a steady rate up to a burst capacity
+type TokenBucket struct {
+	rate  float64 // tokens per second
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+// NewTokenBucket creates a token bucket allowing rate requests per second with bursts of up to burst
+// The bucket starts full. A non-positive rate disables limiting; burst is at least 1.
+func NewTokenBucket(rate float64, burst int) Limiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// Allow takes a token and reports whether the request may proceed
+func (b *TokenBucket) Allow() bool {
+	return b.Reserve().Allowed
+}
+
+// Reserve takes a token when one is available and reports the decision
+func (b *TokenBucket) Reserve() Reservation {
+	if b.rate <= 0 {
+		return Reservation{Allowed: true, Remaining: int(b.burst)}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return Reservation{Allowed: true, Remaining: int(b.tokens)}
+	}
+	return Reservation{RetryAfter: durationFromSeconds((1 - b.tokens) / b.rate)}
+}
+
+// Wait blocks until a token is taken or ctx is done
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	return wait(ctx, b.Reserve)
+}
@@ -0,0 +1,148 @@
+package ratelimitutil
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// FixedWindow allows up to limit requests per aligned window (e.g. per wall-clock minute)
+type FixedWindow struct {
+	limit  int
+	window time.Duration
+
+	mu    sync.Mutex
+	start time.Time
+	count int
+	now   func() time.Time
+}
+
+// NewFixedWindow creates a limiter allowing limit requests per window
+// A non-positive limit or window disables limiting.
+func NewFixedWindow(limit int, window time.Duration) Limiter {
+	return &FixedWindow{limit: limit, window: window, now: time.Now}
+}
+
+// Allow takes a slot and reports whether the request may proceed
+func (f *FixedWindow) Allow() bool {
+	return f.Reserve().Allowed
+}
+
+// Reserve takes a slot in the current window when one is available and reports the decision
+func (f *FixedWindow) Reserve() Reservation {
+	if f.limit <= 0 || f.window <= 0 {
+		return Reservation{Allowed: true, Remaining: f.limit}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	if start := now.Truncate(f.window); !start.Equal(f.start) {
+		f.start = start
+		f.count = 0
+	}
+
+	if f.count < f.limit {
+		f.count++
+		return Reservation{Allowed: true, Remaining: f.limit - f.count}
+	}
+	return Reservation{RetryAfter: f.start.Add(f.window).Sub(now)}
+}
+
+// Wait blocks until a slot is taken or ctx is done
+func (f *FixedWindow) Wait(ctx context.Context) error {
+	return wait(ctx, f.Reserve)
+}
+
+// SlidingWindow approximates a rolling window by weighting the previous window's count
+// This avoids the burst of up to 2x limit that a fixed window allows around window boundaries.
+type SlidingWindow struct {
+	limit  int
+	window time.Duration
+
+	mu       sync.Mutex
+	start    time.Time
+	current  int
+	previous int
+	now      func() time.Time
+}
+
+// NewSlidingWindow creates a limiter allowing about limit requests in any rolling window
+// A non-positive limit or window disables limiting.
+func NewSlidingWindow(limit int, window time.Duration) Limiter {
+	return &SlidingWindow{limit: limit, window: window, now: time.Now}
+}
+
+// Allow takes a slot and reports whether the request may proceed
+func (s *SlidingWindow) Allow() bool {
+	return s.Reserve().Allowed
+}
+
+// Reserve takes a slot when the weighted count allows it and reports the decision
+func (s *SlidingWindow) Reserve() Reservation {
+	if s.limit <= 0 || s.window <= 0 {
+		return Reservation{Allowed: true, Remaining: s.limit}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	start := now.Truncate(s.window)
+	switch {
+	case start.Equal(s.start):
+	case start.Equal(s.start.Add(s.window)):
+		s.previous, s.current = s.current, 0
+		s.start = start
+	default:
+		s.previous, s.current = 0, 0
+		s.start = start
+	}
+
+	return slidingDecision(s.limit, s.window, now.Sub(start), int64(s.previous), int64(s.current), func() {
+		s.current++
+	})
+}
+
+// Wait blocks until a slot is taken or ctx is done
+func (s *SlidingWindow) Wait(ctx context.Context) error {
+	return wait(ctx, s.Reserve)
+}
+
+// slidingDecision applies the sliding window estimate to the counts of the previous and current windows
+// take is called when the request is allowed so the caller can record it.
+func slidingDecision(limit int, window, elapsed time.Duration, previous, current int64, take func()) Reservation {
+	weight := 1 - float64(elapsed)/float64(window)
+	estimate := float64(previous)*weight + float64(current)
+
+	if estimate+1 <= float64(limit) {
+		take()
+		return Reservation{Allowed: true, Remaining: int(float64(limit) - estimate - 1)}
+	}
+	return Reservation{RetryAfter: slidingRetryAfter(limit, window, elapsed, previous, current)}
+}
+
+// slidingRetryAfter computes how long until the weighted estimate leaves room for one more request
+func slidingRetryAfter(limit int, window, elapsed time.Duration, previous, current int64) time.Duration {
+	room := float64(limit - 1)
+	w := window.Seconds()
+	e := elapsed.Seconds()
+
+	// Within the current window, as the previous window's weight decays
+	if float64(current) <= room && previous > 0 {
+		at := w * (1 - (room-float64(current))/float64(previous))
+		if at > e {
+			return durationFromSeconds(at - e)
+		}
+	}
+
+	// Otherwise in the next window, where the current count becomes the decaying previous one
+	next := w - e
+	if current > 0 {
+		if at := w * (1 - room/float64(current)); at > 0 {
+			next += at
+		}
+	}
+	return durationFromSeconds(next)
+}
@@ -0,0 +1,152 @@
+package retryutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Options holds retry and backoff settings
+// Fields are used as given; start from DefaultOptions() to only override what you need.
+type Options struct {
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+
+	// Backoff settings: each wait grows by Multiplier up to MaxWait, plus up to Jitter (fraction) extra
+	InitialWait time.Duration
+	MaxWait     time.Duration
+	Multiplier  float64
+	Jitter      float64
+
+	// RetryIf decides whether an error is retryable; nil retries every error
+	RetryIf func(err error) bool
+
+	// OnRetry is called before waiting for the next attempt (attempt is zero-based)
+	OnRetry func(attempt int, err error, wait time.Duration)
+
+	// StopBeforeDeadline gives up at once, instead of sleeping until the context expires, when the next wait
+	// would outlast the context deadline. The *ExhaustedError returned then has StoppedByDeadline set.
+	StopBeforeDeadline bool
+
+	// OnDeadlineStop is called when StopBeforeDeadline gives up, with the skipped wait and the time left
+	OnDeadlineStop func(attempt int, err error, wait, remaining time.Duration)
+}
+
+// DefaultOptions returns the default retry options, matching httputil's defaults
+func DefaultOptions() Options {
+	return Options{
+		MaxRetries:  5,
+		InitialWait: 5 * time.Second,
+		MaxWait:     60 * time.Second,
+		Multiplier:  1.5,
+		Jitter:      0.1,
+	}
+}
+
+// RetryAfterHinter is implemented by errors that know how long to wait before the next attempt
+// e.g. a rate-limit error carrying a Retry-After value. The hint is used when it exceeds the backoff.
+type RetryAfterHinter interface {
+	RetryAfter() time.Duration
+}
+
+// Retry calls fn until it succeeds, returns a non-retryable error, or retries are exhausted
+func Retry(ctx context.Context, fn func(ctx context.Context) error, opts Options) error {
+	_, err := RetryWithResult(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	}, opts)
+	return err
+}
+
+// RetryWithResult calls fn until it succeeds, returns a non-retryable error, or retries are exhausted
+// On exhaustion the result of the last attempt is returned together with an *ExhaustedError.
+// Non-retryable errors (see Options.RetryIf and Permanent) are returned as-is with their result.
+func RetryWithResult[T any](ctx context.Context, fn func(ctx context.Context) (T, error), opts Options) (T, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var zero T
+	multiplier := opts.Multiplier
+	if multiplier <= 0 {
+		multiplier = 1.5
+	}
+	currentWait := opts.InitialWait
+
+	for attempt := 0; ; attempt++ {
+		result, err := fn(ctx)
+		if err == nil {
+			return result, nil
+		}
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return result, permanent.err
+		}
+		if opts.RetryIf != nil && !opts.RetryIf(err) {
+			return result, err
+		}
+		if attempt >= opts.MaxRetries {
+			return result, &ExhaustedError{Attempts: attempt + 1, LastError: err}
+		}
+
+		// Calculate wait time with exponential backoff and jitter
+		jitter := time.Duration(rand.Float64() * float64(currentWait) * opts.Jitter)
+		waitTime := currentWait + jitter
+
+		var hinter RetryAfterHinter
+		if errors.As(err, &hinter) && hinter.RetryAfter() > waitTime {
+			waitTime = hinter.RetryAfter()
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && opts.StopBeforeDeadline {
+			if remaining := time.Until(deadline); waitTime >= remaining {
+				if opts.OnDeadlineStop != nil {
+					opts.OnDeadlineStop(attempt, err, waitTime, remaining)
+				}
+				return result, &ExhaustedError{Attempts: attempt + 1, LastError: err, StoppedByDeadline: true}
+			}
+		}
+		if opts.OnRetry != nil {
+			opts.OnRetry(attempt, err, waitTime)
+		}
+
+		timer := time.NewTimer(waitTime)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, fmt.Errorf("context cancelled during retry: %w", ctx.Err())
+		case <-timer.C:
+		}
+
+		currentWait = time.Duration(float64(currentWait) * multiplier)
+		if opts.MaxWait > 0 {
+			currentWait = time.Duration(math.Min(float64(currentWait), float64(opts.MaxWait)))
+		}
+	}
+}
+
+// Permanent wraps err so that Retry stops immediately and returns err unwrapped
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// permanentError marks an error as non-retryable
+type permanentError struct {
+	err error
+}
+
+// Error implements the error interface for permanentError
+func (p *permanentError) Error() string {
+	return p.err.Error()
+}
+
+// Unwrap returns the wrapped error
+func (p *permanentError) Unwrap() error {
+	return p.err
+}
@@ -0,0 +1,251 @@
+package retryutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func fastOptions(maxRetries int) Options {
+	opts := DefaultOptions()
+	opts.MaxRetries = maxRetries
+	opts.InitialWait = time.Millisecond
+	opts.MaxWait = 5 * time.Millisecond
+	return opts
+}
+
+func TestDefaultOptions(t *testing.T) {
+	opts := DefaultOptions()
+	if opts.MaxRetries != 5 || opts.InitialWait != 5*time.Second || opts.MaxWait != 60*time.Second {
+		t.Errorf("Unexpected defaults: %+v", opts)
+	}
+	if opts.Multiplier != 1.5 || opts.Jitter != 0.1 {
+		t.Errorf("Unexpected backoff defaults: %+v", opts)
+	}
+}
+
+func TestRetry_SucceedsAfterFailures(t *testing.T) {
+	calls := 0
+	err := Retry(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	}, fastOptions(5))
+
+	if err != nil {
+		t.Errorf("Expected success, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestRetryWithResult_Exhausted(t *testing.T) {
+	lastErr := errors.New("still failing")
+	calls := 0
+
+	result, err := RetryWithResult(context.Background(), func(ctx context.Context) (int, error) {
+		calls++
+		return calls, lastErr
+	}, fastOptions(2))
+
+	var exhausted *ExhaustedError
+	if !errors.As(err, &exhausted) {
+		t.Fatalf("Expected ExhaustedError, got %T: %v", err, err)
+	}
+	if exhausted.Attempts != 3 || calls != 3 {
+		t.Errorf("Expected 3 attempts, got attempts=%d calls=%d", exhausted.Attempts, calls)
+	}
+	if !errors.Is(err, lastErr) {
+		t.Error("Expected ExhaustedError to unwrap to the last error")
+	}
+	if result != 3 {
+		t.Errorf("Expected last attempt's result 3, got %d", result)
+	}
+	if exhausted.Error() == "" {
+		t.Error("Expected error message to be non-empty")
+	}
+}
+
+func TestRetry_RetryIfStopsOnNonRetryable(t *testing.T) {
+	fatal := errors.New("fatal")
+	calls := 0
+
+	opts := fastOptions(5)
+	opts.RetryIf = func(err error) bool { return !errors.Is(err, fatal) }
+
+	err := Retry(context.Background(), func(ctx context.Context) error {
+		calls++
+		return fatal
+	}, opts)
+
+	if err != fatal {
+		t.Errorf("Expected non-retryable error to be returned as-is, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestRetry_Permanent(t *testing.T) {
+	cause := errors.New("bad request")
+	calls := 0
+
+	err := Retry(context.Background(), func(ctx context.Context) error {
+		calls++
+		return Permanent(cause)
+	}, fastOptions(5))
+
+	if err != cause {
+		t.Errorf("Expected unwrapped permanent error, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) should return nil")
+	}
+}
+
+func TestRetry_OnRetryHook(t *testing.T) {
+	var attempts []int
+	opts := fastOptions(2)
+	opts.OnRetry = func(attempt int, err error, wait time.Duration) {
+		attempts = append(attempts, attempt)
+		if wait < time.Millisecond {
+			t.Errorf("Expected wait of at least InitialWait, got %v", wait)
+		}
+	}
+
+	_ = Retry(context.Background(), func(ctx context.Context) error {
+		return errors.New("fail")
+	}, opts)
+
+	if len(attempts) != 2 || attempts[0] != 0 || attempts[1] != 1 {
+		t.Errorf("Expected hook for attempts [0 1], got %v", attempts)
+	}
+}
+
+type hintError struct{ wait time.Duration }
+
+func (e hintError) Error() string             { return "throttled" }
+func (e hintError) RetryAfter() time.Duration { return e.wait }
+
+func TestRetry_RetryAfterHint(t *testing.T) {
+	var waits []time.Duration
+	opts := fastOptions(1)
+	opts.OnRetry = func(attempt int, err error, wait time.Duration) {
+		waits = append(waits, wait)
+	}
+
+	calls := 0
+	start := time.Now()
+	err := Retry(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return hintError{wait: 50 * time.Millisecond}
+		}
+		return nil
+	}, opts)
+
+	if err != nil {
+		t.Fatalf("Expected success, got %v", err)
+	}
+	if len(waits) != 1 || waits[0] != 50*time.Millisecond {
+		t.Errorf("Expected hinted wait of 50ms, got %v", waits)
+	}
+	if time.Since(start) < 50*time.Millisecond {
+		t.Error("Expected retry to honour the hint")
+	}
+}
+
+func TestRetry_ContextCancelledDuringWait(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := fastOptions(5)
+	opts.InitialWait = time.Hour
+	opts.OnRetry = func(int, error, time.Duration) { cancel() }
+
+	err := Retry(ctx, func(ctx context.Context) error {
+		return errors.New("fail")
+	}, opts)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context cancellation error, got %v", err)
+	}
+}
+
+func TestRetry_BackoffCappedAtMaxWait(t *testing.T) {
+	var waits []time.Duration
+	opts := Options{
+		MaxRetries:  4,
+		InitialWait: time.Millisecond,
+		MaxWait:     2 * time.Millisecond,
+		Multiplier:  2,
+		OnRetry: func(attempt int, err error, wait time.Duration) {
+			waits = append(waits, wait)
+		},
+	}
+
+	_ = Retry(context.Background(), func(ctx context.Context) error {
+		return errors.New("fail")
+	}, opts)
+
+	expected := []time.Duration{time.Millisecond, 2 * time.Millisecond, 2 * time.Millisecond, 2 * time.Millisecond}
+	if len(waits) != len(expected) {
+		t.Fatalf("Expected %d waits, got %v", len(expected), waits)
+	}
+	for i := range expected {
+		if waits[i] != expected[i] {
+			t.Errorf("wait[%d] = %v, want %v", i, waits[i], expected[i])
+		}
+	}
+}
+
+func TestRetry_StopBeforeDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	failure := errors.New("fail")
+	opts := fastOptions(5)
+	opts.InitialWait = time.Hour
+	opts.StopBeforeDeadline = true
+	var skipped, remaining time.Duration
+	opts.OnDeadlineStop = func(attempt int, err error, wait, left time.Duration) {
+		skipped, remaining = wait, left
+	}
+	opts.OnRetry = func(int, error, time.Duration) {
+		t.Error("OnRetry should not be called when the wait exceeds the deadline")
+	}
+
+	start := time.Now()
+	err := Retry(ctx, func(ctx context.Context) error { return failure }, opts)
+
+	var exhausted *ExhaustedError
+	if !errors.As(err, &exhausted) || !exhausted.StoppedByDeadline || exhausted.Attempts != 1 {
+		t.Fatalf("Expected ExhaustedError stopped by the deadline after 1 attempt, got %v", err)
+	}
+	if !errors.Is(err, failure) {
+		t.Error("Expected the error to unwrap to the last attempt's error")
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Expected an immediate return, took %v", elapsed)
+	}
+	if skipped < time.Hour || remaining <= 0 || remaining > 100*time.Millisecond {
+		t.Errorf("OnDeadlineStop got wait=%v remaining=%v", skipped, remaining)
+	}
+
+	// Waits that fit in the deadline still retry
+	calls := 0
+	opts = fastOptions(2)
+	opts.StopBeforeDeadline = true
+	_ = Retry(ctx, func(ctx context.Context) error {
+		calls++
+		return failure
+	}, opts)
+	if calls != 3 {
+		t.Errorf("Expected 3 calls within the deadline, got %d", calls)
+	}
+}
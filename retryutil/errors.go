@@ -0,0 +1,26 @@
+package retryutil
+
+import "fmt"
+
+// ExhaustedError is returned when all retry attempts are exhausted
+// It contains the number of attempts made and the error returned by the last one.
+type ExhaustedError struct {
+	Attempts  int
+	LastError error
+
+	// StoppedByDeadline is set when retries remained but the next wait would have outlasted the context deadline
+	StoppedByDeadline bool
+}
+
+// Error implements the error interface for ExhaustedError
+func (e *ExhaustedError) Error() string {
+	if e.StoppedByDeadline {
+		return fmt.Sprintf("retry stopped after %d attempts, next wait exceeds the context deadline: %v", e.Attempts, e.LastError)
+	}
+	return fmt.Sprintf("retry exhausted after %d attempts: %v", e.Attempts, e.LastError)
+}
+
+// Unwrap returns the last attempt's error so errors.Is/As can inspect it
+func (e *ExhaustedError) Unwrap() error {
+	return e.LastError
+}
@@ -0,0 +1,212 @@
+package semverutil
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// versionPattern is the Semantic Versioning 2.0.0 grammar with an optional leading "v"
+var versionPattern = regexp.MustCompile(`^[vV]?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// Version is a parsed semantic version
+// Build metadata is kept for display but ignored by comparisons, as the specification requires.
+type Version struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	Prerelease string
+	Build      string
+}
+
+// Parse parses a semantic version such as "1.2.3", "v1.2.3-rc.1" or "1.2.3+build.5"
+func Parse(s string) (Version, error) {
+	m := versionPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return Version{}, fmt.Errorf("%w: %q", ErrInvalidVersion, s)
+	}
+
+	var v Version
+	var err error
+	if v.Major, err = strconv.ParseUint(m[1], 10, 64); err != nil {
+		return Version{}, fmt.Errorf("%w: %q: %v", ErrInvalidVersion, s, err)
+	}
+	if v.Minor, err = strconv.ParseUint(m[2], 10, 64); err != nil {
+		return Version{}, fmt.Errorf("%w: %q: %v", ErrInvalidVersion, s, err)
+	}
+	if v.Patch, err = strconv.ParseUint(m[3], 10, 64); err != nil {
+		return Version{}, fmt.Errorf("%w: %q: %v", ErrInvalidVersion, s, err)
+	}
+	v.Prerelease = m[4]
+	v.Build = m[5]
+	return v, nil
+}
+
+// MustParse is like Parse but panics on invalid input
+// Intended for version literals in code and tests.
+func MustParse(s string) Version {
+	v, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// String returns the canonical form of v without a "v" prefix
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// IsPrerelease reports whether v carries a pre-release tag
+func (v Version) IsPrerelease() bool {
+	return v.Prerelease != ""
+}
+
+// Compare returns -1, 0 or 1 when v is lower than, equal to or higher than other
+func (v Version) Compare(other Version) int {
+	return Compare(v, other)
+}
+
+// LessThan reports whether v has lower precedence than other
+func (v Version) LessThan(other Version) bool {
+	return Compare(v, other) < 0
+}
+
+// Equal reports whether v and other have the same precedence
+func (v Version) Equal(other Version) bool {
+	return Compare(v, other) == 0
+}
+
+// Compare returns -1, 0 or 1 when a is lower than, equal to or higher than b
+// Pre-release versions sort before their release, e.g. 1.0.0-rc.1 < 1.0.0.
+func Compare(a, b Version) int {
+	if c := compareUint(a.Major, b.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(a.Minor, b.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(a.Patch, b.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(a.Prerelease, b.Prerelease)
+}
+
+// CompareStrings parses and compares two version strings
+func CompareStrings(a, b string) (int, error) {
+	va, err := Parse(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := Parse(b)
+	if err != nil {
+		return 0, err
+	}
+	return Compare(va, vb), nil
+}
+
+// Sort orders versions in ascending precedence in place
+func Sort(versions []Version) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		return Compare(versions[i], versions[j]) < 0
+	})
+}
+
+// SortStrings parses and sorts version strings in ascending precedence
+// The original spelling of each entry (including any "v" prefix) is preserved.
+func SortStrings(versions []string) ([]string, error) {
+	parsed := make([]Version, len(versions))
+	for i, s := range versions {
+		v, err := Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = v
+	}
+
+	indexes := make([]int, len(versions))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return Compare(parsed[indexes[i]], parsed[indexes[j]]) < 0
+	})
+
+	sorted := make([]string, len(versions))
+	for i, idx := range indexes {
+		sorted[i] = versions[idx]
+	}
+	return sorted, nil
+}
+
+// Latest returns the highest version, or false when versions is empty
+func Latest(versions []Version) (Version, bool) {
+	if len(versions) == 0 {
+		return Version{}, false
+	}
+	latest := versions[0]
+	for _, v := range versions[1:] {
+		if Compare(v, latest) > 0 {
+			latest = v
+		}
+	}
+	return latest, true
+}
+
+// compareUint compares two unsigned integers
+func compareUint(a, b uint64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// comparePrerelease compares dot-separated pre-release identifiers per the specification
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		if c := compareIdentifier(as[i], bs[i]); c != 0 {
+			return c
+		}
+	}
+	return compareUint(uint64(len(as)), uint64(len(bs)))
+}
+
+// compareIdentifier compares a single pre-release identifier
+// Numeric identifiers compare numerically and always sort before alphanumeric ones.
+func compareIdentifier(a, b string) int {
+	an, aErr := strconv.ParseUint(a, 10, 64)
+	bn, bErr := strconv.ParseUint(b, 10, 64)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareUint(an, bn)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,335 @@
+package semverutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// =================== Test Parse ===================
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Version
+		wantErr bool
+	}{
+		{"plain", "1.2.3", Version{Major: 1, Minor: 2, Patch: 3}, false},
+		{"v prefix", "v10.0.1", Version{Major: 10, Patch: 1}, false},
+		{"prerelease", "1.0.0-rc.1", Version{Major: 1, Prerelease: "rc.1"}, false},
+		{"build", "1.0.0+sha.abc", Version{Major: 1, Build: "sha.abc"}, false},
+		{"prerelease and build", "2.1.0-beta.2+exp", Version{Major: 2, Minor: 1, Prerelease: "beta.2", Build: "exp"}, false},
+		{"surrounding space", " 1.2.3 ", Version{Major: 1, Minor: 2, Patch: 3}, false},
+		{"missing patch", "1.2", Version{}, true},
+		{"leading zero", "01.2.3", Version{}, true},
+		{"leading zero prerelease", "1.2.3-01", Version{}, true},
+		{"empty prerelease", "1.2.3-", Version{}, true},
+		{"garbage", "latest", Version{}, true},
+		{"overflow", "99999999999999999999.0.0", Version{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidVersion) {
+					t.Errorf("Parse(%q) error = %v, want ErrInvalidVersion", tt.input, err)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersion_String(t *testing.T) {
+	for _, s := range []string{"1.2.3", "0.0.1-alpha.1", "1.0.0+build.7", "2.0.0-rc.1+sha.1"} {
+		if got := MustParse(s).String(); got != s {
+			t.Errorf("MustParse(%q).String() = %q", s, got)
+		}
+	}
+	if got := MustParse("v1.2.3").String(); got != "1.2.3" {
+		t.Errorf("String() = %q, want %q", got, "1.2.3")
+	}
+}
+
+func TestMustParse_Panics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MustParse() should panic on invalid input")
+		}
+	}()
+	MustParse("nope")
+}
+
+// =================== Test Compare ===================
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0.0", "2.0.0", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.0.10", "1.0.9", 1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-beta.11", 1},
+		{"1.0.0+build.1", "1.0.0+build.2", 0},
+		{"v1.2.3", "1.2.3", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			got, err := CompareStrings(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("CompareStrings() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CompareStrings(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+			if reverse := Compare(MustParse(tt.b), MustParse(tt.a)); reverse != -tt.want {
+				t.Errorf("Compare(%q, %q) = %d, want %d", tt.b, tt.a, reverse, -tt.want)
+			}
+		})
+	}
+
+	if _, err := CompareStrings("1.0.0", "bad"); err == nil {
+		t.Error("CompareStrings() with invalid input should return error")
+	}
+}
+
+func TestVersion_Helpers(t *testing.T) {
+	a, b := MustParse("1.2.3"), MustParse("1.3.0-rc.1")
+	if !a.LessThan(b) || b.LessThan(a) {
+		t.Error("LessThan() ordering is wrong")
+	}
+	if !a.Equal(MustParse("1.2.3+meta")) {
+		t.Error("Equal() should ignore build metadata")
+	}
+	if a.IsPrerelease() || !b.IsPrerelease() {
+		t.Error("IsPrerelease() is wrong")
+	}
+	if a.Compare(b) != -1 {
+		t.Errorf("Compare() = %d, want -1", a.Compare(b))
+	}
+}
+
+// =================== Test Sort ===================
+
+func TestSort(t *testing.T) {
+	versions := []Version{MustParse("1.10.0"), MustParse("1.2.0"), MustParse("1.2.0-rc.1"), MustParse("0.9.9")}
+	Sort(versions)
+
+	var got []string
+	for _, v := range versions {
+		got = append(got, v.String())
+	}
+	want := []string{"0.9.9", "1.2.0-rc.1", "1.2.0", "1.10.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Sort() = %v, want %v", got, want)
+	}
+}
+
+func TestSortStrings(t *testing.T) {
+	got, err := SortStrings([]string{"v2.0.0", "1.0.0", "v1.5.0"})
+	if err != nil {
+		t.Fatalf("SortStrings() error = %v", err)
+	}
+	want := []string{"1.0.0", "v1.5.0", "v2.0.0"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SortStrings() = %v, want %v", got, want)
+	}
+
+	if _, err := SortStrings([]string{"1.0.0", "x"}); err == nil {
+		t.Error("SortStrings() with invalid input should return error")
+	}
+}
+
+func TestLatest(t *testing.T) {
+	if _, ok := Latest(nil); ok {
+		t.Error("Latest(nil) should report false")
+	}
+	got, ok := Latest([]Version{MustParse("1.0.0"), MustParse("3.0.0-rc.1"), MustParse("2.5.0")})
+	if !ok || got.String() != "3.0.0-rc.1" {
+		t.Errorf("Latest() = %v, %v, want 3.0.0-rc.1, true", got, ok)
+	}
+}
+
+// =================== Test Constraints ===================
+
+func TestConstraint_Check(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		want       bool
+	}{
+		{">=1.2.0 <2.0.0", "1.2.0", true},
+		{">=1.2.0 <2.0.0", "1.9.9", true},
+		{">=1.2.0 <2.0.0", "2.0.0", false},
+		{">=1.2.0 <2.0.0", "1.1.9", false},
+		{">=1.2.0, <2.0.0", "1.5.0", true},
+		{">= 1.2.0 < 2.0.0", "1.5.0", true},
+		{">=1.2.0 <2.0.0", "2.0.0-rc.1", false},
+		{">=1.2.0-beta.1", "1.2.0-beta.2", true},
+		{">=1.2.0-beta.1", "1.3.0-beta.1", false},
+		{"1.2.3", "1.2.3", true},
+		{"=1.2.3", "1.2.4", false},
+		{"!=1.2.3", "1.2.4", true},
+		{"!=1.2.3", "1.2.3", false},
+		{">1.2.3", "1.2.4", true},
+		{">1.2", "1.2.9", false},
+		{">1.2", "1.3.0", true},
+		{"<=1.2", "1.2.9", true},
+		{"<=1.2", "1.3.0", false},
+		{"<2", "1.99.0", true},
+		{"1.2", "1.2.7", true},
+		{"1.2.x", "1.3.0", false},
+		{"1.x", "1.9.0", true},
+		{"*", "0.0.1", true},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"~>1.2", "1.2.0", true},
+		{"~1", "1.9.0", true},
+		{"~1", "2.0.0", false},
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "2.0.0", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{"^0", "0.9.0", true},
+		{"^1.2 || ^2.1", "2.3.0", true},
+		{"^1.2 || ^2.1", "2.0.0", false},
+		{"<1.0.0 || >=3.0.0", "3.1.0", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			got, err := Satisfies(tt.version, tt.constraint)
+			if err != nil {
+				t.Fatalf("Satisfies() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Satisfies(%q, %q) = %v, want %v", tt.version, tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseConstraint_Errors(t *testing.T) {
+	for _, expr := range []string{"", ">=", ">=abc", "1.2 ||", "!=1.2", "1.2-rc.1", "=>1.0.0"} {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseConstraint(expr); !errors.Is(err, ErrInvalidConstraint) {
+				t.Errorf("ParseConstraint(%q) error = %v, want ErrInvalidConstraint", expr, err)
+			}
+		})
+	}
+
+	if _, err := Satisfies("bad", ">=1.0.0"); !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("Satisfies() error = %v, want ErrInvalidVersion", err)
+	}
+}
+
+func TestConstraint_String(t *testing.T) {
+	if got := MustParseConstraint(" >=1.2.0 <2.0.0 ").String(); got != ">=1.2.0 <2.0.0" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestMaxSatisfying(t *testing.T) {
+	versions := []Version{MustParse("1.2.0"), MustParse("1.8.1"), MustParse("2.0.0"), MustParse("1.9.0-rc.1")}
+
+	got, ok := MaxSatisfying(versions, MustParseConstraint("^1.0"))
+	if !ok || got.String() != "1.8.1" {
+		t.Errorf("MaxSatisfying() = %v, %v, want 1.8.1, true", got, ok)
+	}
+	if _, ok := MaxSatisfying(versions, MustParseConstraint(">=3")); ok {
+		t.Error("MaxSatisfying() should report false when nothing matches")
+	}
+}
+
+// =================== Test Extract ===================
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"v1.2.3", "1.2.3", true},
+		{"api/v2.3.1", "2.3.1", true},
+		{"release-1.4.0-rc.2", "1.4.0-rc.2", true},
+		{"client/1.4.0 (linux)", "1.4.0", true},
+		{"Version 3.0.0.", "3.0.0", true},
+		{"build 1.2.3-", "1.2.3", true},
+		{"10.0.0.1", "", false},
+		{"no version here", "", false},
+		{"1.2", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, ok := Extract(tt.input)
+			if ok != tt.ok {
+				t.Fatalf("Extract(%q) ok = %v, want %v", tt.input, ok, tt.ok)
+			}
+			if ok && got.String() != tt.want {
+				t.Errorf("Extract(%q) = %q, want %q", tt.input, got.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractAll(t *testing.T) {
+	got := ExtractAll("upgrade from v1.2.3 to v1.3.0, skipping 1.2.10-beta")
+	var strs []string
+	for _, v := range got {
+		strs = append(strs, v.String())
+	}
+	want := []string{"1.2.3", "1.3.0", "1.2.10-beta"}
+	if !reflect.DeepEqual(strs, want) {
+		t.Errorf("ExtractAll() = %v, want %v", strs, want)
+	}
+}
+
+func TestLatestTag(t *testing.T) {
+	tags := []string{"v1.0.0", "v1.10.0", "v1.9.0", "v2.0.0-rc.1", "nightly"}
+
+	tag, v, ok := LatestTag(tags, false)
+	if !ok || tag != "v1.10.0" || v.String() != "1.10.0" {
+		t.Errorf("LatestTag(false) = %q, %v, %v", tag, v, ok)
+	}
+	tag, _, _ = LatestTag(tags, true)
+	if tag != "v2.0.0-rc.1" {
+		t.Errorf("LatestTag(true) = %q, want %q", tag, "v2.0.0-rc.1")
+	}
+	if _, _, ok := LatestTag([]string{"nightly"}, true); ok {
+		t.Error("LatestTag() should report false without versioned tags")
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkParse(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = Parse("v1.2.3-rc.1+build.5")
+	}
+}
+
+func BenchmarkConstraintCheck(b *testing.B) {
+	c := MustParseConstraint(">=1.2.0 <2.0.0 || ^3.1")
+	v := MustParse("3.4.5")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.Check(v)
+	}
+}
@@ -0,0 +1,290 @@
+package semverutil
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// partialPattern matches a possibly incomplete version used in constraints, e.g. "1", "1.2", "1.2.x" or "*"
+var partialPattern = regexp.MustCompile(`^[vV]?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?` +
+	`(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?$`)
+
+// operators lists the recognised comparison prefixes, longest first so ">=" wins over ">"
+var operators = []string{">=", "<=", "!=", "==", "~>", ">", "<", "=", "~", "^"}
+
+// comparator is a single primitive comparison against a full version
+type comparator struct {
+	op string
+	v  Version
+}
+
+// Constraint is a parsed version constraint such as ">=1.2.0 <2.0.0" or "^1.4 || ~2.0"
+// Comparators separated by spaces or commas must all match; groups separated by "||" are alternatives.
+// Supported operators are =, !=, >, >=, <, <=, ~ (or ~>) and ^, and versions may be partial ("1.2", "1.x").
+// As in npm and Cargo, a pre-release version only matches a group that names a pre-release of the same
+// major.minor.patch, so ">=1.2.0 <2.0.0" does not match 2.0.0-rc.1.
+type Constraint struct {
+	raw    string
+	groups [][]comparator
+}
+
+// ParseConstraint parses a constraint expression
+func ParseConstraint(s string) (*Constraint, error) {
+	raw := strings.TrimSpace(s)
+	if raw == "" {
+		return nil, fmt.Errorf("%w: empty expression", ErrInvalidConstraint)
+	}
+
+	c := &Constraint{raw: raw}
+	for _, group := range strings.Split(raw, "||") {
+		comparators, err := parseGroup(group)
+		if err != nil {
+			return nil, err
+		}
+		c.groups = append(c.groups, comparators)
+	}
+	return c, nil
+}
+
+// MustParseConstraint is like ParseConstraint but panics on invalid input
+func MustParseConstraint(s string) *Constraint {
+	c, err := ParseConstraint(s)
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// String returns the constraint as it was written
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// Check reports whether v satisfies the constraint
+func (c *Constraint) Check(v Version) bool {
+	for _, group := range c.groups {
+		if groupMatches(group, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// Satisfies parses version and constraint and reports whether the version satisfies it
+func Satisfies(version, constraint string) (bool, error) {
+	v, err := Parse(version)
+	if err != nil {
+		return false, err
+	}
+	c, err := ParseConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+	return c.Check(v), nil
+}
+
+// MaxSatisfying returns the highest version that satisfies c, or false when none does
+func MaxSatisfying(versions []Version, c *Constraint) (Version, bool) {
+	var best Version
+	found := false
+	for _, v := range versions {
+		if c.Check(v) && (!found || Compare(v, best) > 0) {
+			best, found = v, true
+		}
+	}
+	return best, found
+}
+
+// groupMatches reports whether v satisfies every comparator in a group
+func groupMatches(group []comparator, v Version) bool {
+	for _, cmp := range group {
+		if !cmp.matches(v) {
+			return false
+		}
+	}
+	if !v.IsPrerelease() {
+		return true
+	}
+	for _, cmp := range group {
+		if cmp.v.IsPrerelease() && cmp.v.Major == v.Major && cmp.v.Minor == v.Minor && cmp.v.Patch == v.Patch {
+			return true
+		}
+	}
+	return false
+}
+
+// matches applies a single comparator
+func (c comparator) matches(v Version) bool {
+	r := Compare(v, c.v)
+	switch c.op {
+	case "=":
+		return r == 0
+	case "!=":
+		return r != 0
+	case ">":
+		return r > 0
+	case ">=":
+		return r >= 0
+	case "<":
+		return r < 0
+	case "<=":
+		return r <= 0
+	}
+	return false
+}
+
+// parseGroup parses the space or comma separated comparators of one alternative
+func parseGroup(group string) ([]comparator, error) {
+	fields := strings.FieldsFunc(group, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("%w: empty alternative", ErrInvalidConstraint)
+	}
+
+	var comparators []comparator
+	for i := 0; i < len(fields); i++ {
+		term := fields[i]
+		// Allow a space between the operator and the version, e.g. ">= 1.2.0"
+		if isOperator(term) {
+			if i+1 >= len(fields) {
+				return nil, fmt.Errorf("%w: operator %q without a version", ErrInvalidConstraint, term)
+			}
+			i++
+			term += fields[i]
+		}
+		expanded, err := parseTerm(term)
+		if err != nil {
+			return nil, err
+		}
+		comparators = append(comparators, expanded...)
+	}
+	return comparators, nil
+}
+
+// isOperator reports whether s consists of an operator only
+func isOperator(s string) bool {
+	for _, op := range operators {
+		if s == op {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTerm expands one operator/version term into primitive comparators
+func parseTerm(term string) ([]comparator, error) {
+	op := ""
+	for _, candidate := range operators {
+		if strings.HasPrefix(term, candidate) {
+			op = candidate
+			break
+		}
+	}
+	v, parts, err := parsePartial(term[len(op):])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidConstraint, term)
+	}
+
+	lower := comparator{op: ">=", v: v}
+	switch op {
+	case "", "=", "==":
+		if parts == 3 {
+			return []comparator{{op: "=", v: v}}, nil
+		}
+		return bounded(lower, parts, bumpAt(v, parts)), nil
+	case "!=":
+		if parts != 3 {
+			return nil, fmt.Errorf("%w: %q needs a full version", ErrInvalidConstraint, term)
+		}
+		return []comparator{{op: "!=", v: v}}, nil
+	case ">":
+		if parts == 3 {
+			return []comparator{{op: ">", v: v}}, nil
+		}
+		if parts == 0 {
+			return []comparator{{op: "<", v: Version{}}}, nil
+		}
+		return []comparator{{op: ">=", v: bumpAt(v, parts)}}, nil
+	case ">=":
+		return []comparator{lower}, nil
+	case "<":
+		return []comparator{{op: "<", v: v}}, nil
+	case "<=":
+		if parts == 3 {
+			return []comparator{{op: "<=", v: v}}, nil
+		}
+		if parts == 0 {
+			return []comparator{{op: ">=", v: Version{}}}, nil
+		}
+		return []comparator{{op: "<", v: bumpAt(v, parts)}}, nil
+	case "~", "~>":
+		if parts == 1 {
+			return bounded(lower, parts, bumpAt(v, 1)), nil
+		}
+		return bounded(lower, parts, bumpAt(v, 2)), nil
+	case "^":
+		switch {
+		case v.Major > 0 || parts == 1:
+			return bounded(lower, parts, bumpAt(v, 1)), nil
+		case v.Minor > 0 || parts == 2:
+			return bounded(lower, parts, bumpAt(v, 2)), nil
+		}
+		return bounded(lower, parts, bumpAt(v, 3)), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrInvalidConstraint, term)
+}
+
+// bounded returns the lower bound plus an exclusive upper bound, or "any version" for a bare wildcard
+func bounded(lower comparator, parts int, upper Version) []comparator {
+	if parts == 0 {
+		return []comparator{{op: ">=", v: Version{}}}
+	}
+	return []comparator{lower, {op: "<", v: upper}}
+}
+
+// bumpAt returns the smallest version above every version sharing v's first n components
+// For example bumpAt(1.2.3, 1) is 2.0.0 and bumpAt(1.2.3, 2) is 1.3.0.
+func bumpAt(v Version, n int) Version {
+	switch n {
+	case 1:
+		return Version{Major: v.Major + 1}
+	case 2:
+		return Version{Major: v.Major, Minor: v.Minor + 1}
+	}
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+// parsePartial parses a version whose trailing components may be missing or wildcards
+// It returns the version with missing parts zeroed and how many leading components were given.
+func parsePartial(s string) (Version, int, error) {
+	m := partialPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Version{}, 0, ErrInvalidVersion
+	}
+
+	var nums [3]uint64
+	parts := 0
+	for i := 0; i < 3; i++ {
+		field := m[i+1]
+		if field == "" || field == "x" || field == "X" || field == "*" {
+			break
+		}
+		n, err := strconv.ParseUint(field, 10, 64)
+		if err != nil {
+			return Version{}, 0, err
+		}
+		nums[i] = n
+		parts++
+	}
+
+	v := Version{Major: nums[0], Minor: nums[1], Patch: nums[2]}
+	if parts == 3 {
+		v.Prerelease = m[4]
+	} else if m[4] != "" {
+		return Version{}, 0, ErrInvalidVersion
+	}
+	return v, parts, nil
+}
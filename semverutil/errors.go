@@ -0,0 +1,11 @@
+package semverutil
+
+import "errors"
+
+var (
+	// ErrInvalidVersion is returned when a string is not a valid semantic version
+	ErrInvalidVersion = errors.New("invalid semantic version")
+
+	// ErrInvalidConstraint is returned when a constraint expression cannot be parsed
+	ErrInvalidConstraint = errors.New("invalid version constraint")
+)
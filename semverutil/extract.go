@@ -0,0 +1,73 @@
+package semverutil
+
+import (
+	"regexp"
+	"strings"
+)
+
+// embeddedPattern finds version-like substrings that are not part of a longer dotted number such as an IP address
+var embeddedPattern = regexp.MustCompile(`(?:^|[^0-9.])([vV]?\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)`)
+
+// Extract returns the first semantic version embedded in s
+// Useful for tags ("api/v2.3.1"), user agents ("client/1.4.0 (linux)") and version endpoints.
+func Extract(s string) (Version, bool) {
+	versions := extract(s, 1)
+	if len(versions) == 0 {
+		return Version{}, false
+	}
+	return versions[0], true
+}
+
+// ExtractAll returns every semantic version embedded in s, in order of appearance
+func ExtractAll(s string) []Version {
+	return extract(s, -1)
+}
+
+// LatestTag returns the tag holding the highest version, ignoring tags without one
+// Pre-release tags are skipped unless includePrerelease is set.
+func LatestTag(tags []string, includePrerelease bool) (string, Version, bool) {
+	var bestTag string
+	var best Version
+	found := false
+	for _, tag := range tags {
+		v, ok := Extract(tag)
+		if !ok || (v.IsPrerelease() && !includePrerelease) {
+			continue
+		}
+		if !found || Compare(v, best) > 0 {
+			bestTag, best, found = tag, v, true
+		}
+	}
+	return bestTag, best, found
+}
+
+// extract returns up to n embedded versions, or all of them when n is negative
+func extract(s string, n int) []Version {
+	var versions []Version
+	for _, loc := range embeddedPattern.FindAllStringSubmatchIndex(s, -1) {
+		start, end := loc[2], loc[3]
+		// Reject "1.2.3.4" and similar where the match is followed by more dotted digits
+		if end+1 < len(s) && s[end] == '.' && s[end+1] >= '0' && s[end+1] <= '9' {
+			continue
+		}
+
+		candidate := strings.TrimRight(s[start:end], ".-")
+		v, err := Parse(candidate)
+		if err != nil {
+			// A pre-release suffix that is not valid semver (e.g. "1.2.3-") still carries a usable core version
+			core := candidate
+			if i := strings.IndexAny(core, "-+"); i >= 0 {
+				core = core[:i]
+			}
+			if v, err = Parse(core); err != nil {
+				continue
+			}
+		}
+
+		versions = append(versions, v)
+		if n > 0 && len(versions) == n {
+			break
+		}
+	}
+	return versions
+}
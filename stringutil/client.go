@@ -0,0 +1,26 @@
+package stringutil
+
+// StringClient defines the interface for string tokenization, similarity and grapheme helpers
+type StringClient interface {
+	// Tokenization
+	SplitQuoted(s, sep string) ([]string, error)
+	SplitCamelCase(s string) []string
+	FieldsN(s string, n int) []string
+
+	// Similarity
+	Levenshtein(a, b string) int
+	Similarity(a, b string) float64
+	DedupeSimilar(items []string, threshold float64) []SimilarityCluster
+
+	// User-perceived length
+	GraphemeLen(s string) int
+	TruncateGraphemes(s string, n int) string
+}
+
+// StringUtil implements StringClient
+type StringUtil struct{}
+
+// NewStringUtil creates a new string utility instance
+func NewStringUtil() StringClient {
+	return &StringUtil{}
+}
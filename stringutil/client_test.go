@@ -0,0 +1,224 @@
+package stringutil
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNewStringUtil(t *testing.T) {
+	if util := NewStringUtil(); util == nil {
+		t.Error("NewStringUtil() returned nil")
+	}
+}
+
+// =================== Test Tokenization ===================
+
+func TestSplitQuoted(t *testing.T) {
+	util := NewStringUtil()
+
+	tests := []struct {
+		name  string
+		input string
+		sep   string
+		want  []string
+	}{
+		{"plain", "a, b ,c", ",", []string{"a", "b", "c"}},
+		{"default separator", "a,b", "", []string{"a", "b"}},
+		{"blank", "  ", ",", []string{}},
+		{"empty fields kept", "a,,b,", ",", []string{"a", "", "b", ""}},
+		{"double quotes protect separator", `name="Smith, J", age=4`, ",", []string{"name=Smith, J", "age=4"}},
+		{"single quotes", `'a,b',c`, ",", []string{"a,b", "c"}},
+		{"quoted whitespace kept", `"  padded  " , x`, ",", []string{"  padded  ", "x"}},
+		{"doubled quote is literal", `"say ""hi""",next`, ",", []string{`say "hi"`, "next"}},
+		{"backslash escapes", `a\,b,c\"d,it\'s`, ",", []string{"a,b", `c"d`, "it's"}},
+		{"other quote inside quotes", `"it's",'say "x"'`, ",", []string{"it's", `say "x"`}},
+		{"multi-character separator", `a && "b && c" && d`, "&&", []string{"a", "b && c", "d"}},
+		{"empty quoted field", `"",x`, ",", []string{"", "x"}},
+		{"trailing backslash kept", `a\`, ",", []string{`a\`}},
+		{"unicode", `ä;"ö;ü"`, ";", []string{"ä", "ö;ü"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := util.SplitQuoted(tt.input, tt.sep)
+			if err != nil {
+				t.Fatalf("SplitQuoted() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitQuoted(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	for _, input := range []string{`a,"b`, `don't,x`} {
+		if _, err := util.SplitQuoted(input, ","); !errors.Is(err, ErrUnterminatedQuote) {
+			t.Errorf("SplitQuoted(%q) error = %v, want ErrUnterminatedQuote", input, err)
+		}
+	}
+}
+
+func TestSplitCamelCase(t *testing.T) {
+	util := NewStringUtil()
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", []string{}},
+		{"lower", []string{"lower"}},
+		{"camelCase", []string{"camel", "Case"}},
+		{"PascalCase", []string{"Pascal", "Case"}},
+		{"parseHTTPResponse2XX", []string{"parse", "HTTP", "Response", "2", "XX"}},
+		{"HTTPServer", []string{"HTTP", "Server"}},
+		{"userID", []string{"user", "ID"}},
+		{"snake_case-and kebab", []string{"snake", "case", "and", "kebab"}},
+		{"__leading", []string{"leading"}},
+		{"version10Beta", []string{"version", "10", "Beta"}},
+		{"ÄpfelÜber", []string{"Äpfel", "Über"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := util.SplitCamelCase(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitCamelCase(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFieldsN(t *testing.T) {
+	util := NewStringUtil()
+
+	tests := []struct {
+		name  string
+		input string
+		n     int
+		want  []string
+	}{
+		{"limit with remainder", "set  key  the   value ", 3, []string{"set", "key", "the   value"}},
+		{"fewer fields than limit", " a b ", 5, []string{"a", "b"}},
+		{"limit of one", "  whole  line ", 1, []string{"whole  line"}},
+		{"no limit", "a  b\tc\n", 0, []string{"a", "b", "c"}},
+		{"blank", "   ", 2, []string{}},
+		{"exact count", "a b", 2, []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := util.FieldsN(tt.input, tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FieldsN(%q, %d) = %q, want %q", tt.input, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+// =================== Test Similarity ===================
+
+func TestLevenshteinAndSimilarity(t *testing.T) {
+	util := NewStringUtil()
+
+	tests := []struct {
+		a, b       string
+		distance   int
+		similarity float64
+	}{
+		{"", "", 0, 1},
+		{"abc", "", 3, 0},
+		{"kitten", "sitting", 3, 1 - 3.0/7},
+		{"flaw", "lawn", 2, 0.5},
+		{"same", "same", 0, 1},
+		{"café", "cafe", 1, 0.75},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"/"+tt.b, func(t *testing.T) {
+			if got := util.Levenshtein(tt.a, tt.b); got != tt.distance {
+				t.Errorf("Levenshtein() = %d, want %d", got, tt.distance)
+			}
+			if got := util.Levenshtein(tt.b, tt.a); got != tt.distance {
+				t.Errorf("Levenshtein() reversed = %d, want %d", got, tt.distance)
+			}
+			if got := util.Similarity(tt.a, tt.b); got < tt.similarity-1e-9 || got > tt.similarity+1e-9 {
+				t.Errorf("Similarity() = %v, want %v", got, tt.similarity)
+			}
+		})
+	}
+}
+
+func TestDedupeSimilar(t *testing.T) {
+	util := NewStringUtil()
+	items := []string{"New York", "Boston", " new  york", "New Yrok", "Bostn", "Chicago"}
+
+	got := util.DedupeSimilar(items, 0.75)
+	want := []SimilarityCluster{
+		{Representative: "New York", Members: []string{"New York", " new  york", "New Yrok"}, Indexes: []int{0, 2, 3}},
+		{Representative: "Boston", Members: []string{"Boston", "Bostn"}, Indexes: []int{1, 4}},
+		{Representative: "Chicago", Members: []string{"Chicago"}, Indexes: []int{5}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("DedupeSimilar() = %+v, want %+v", got, want)
+	}
+
+	strict := util.DedupeSimilar(items, 1)
+	if len(strict) != 5 || len(strict[0].Members) != 2 {
+		t.Errorf("DedupeSimilar() with threshold 1 = %+v, want only normalized duplicates grouped", strict)
+	}
+	if got := util.DedupeSimilar(nil, 0.8); len(got) != 0 {
+		t.Errorf("DedupeSimilar(nil) = %+v, want no clusters", got)
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkSplitQuoted(b *testing.B) {
+	util := NewStringUtil()
+	input := `name="Smith, J", age=42, city='New York', note=a\,b`
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = util.SplitQuoted(input, ",")
+	}
+}
+
+func BenchmarkDedupeSimilar(b *testing.B) {
+	util := NewStringUtil()
+	items := []string{"New York", "Boston", "new york", "New Yrok", "Bostn", "Chicago", "Chicgo", "Seattle"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = util.DedupeSimilar(items, 0.8)
+	}
+}
+
+func TestGraphemes(t *testing.T) {
+	util := NewStringUtil()
+
+	tests := []struct {
+		name      string
+		s         string
+		length    int
+		truncate  int
+		truncated string
+	}{
+		{"ascii", "hello", 5, 3, "hel"},
+		{"empty", "", 0, 3, ""},
+		{"combining mark", "cafe\u0301!", 5, 4, "cafe\u0301"},
+		{"zwj sequence", "hi 👩‍💻 there", 10, 4, "hi 👩‍💻"},
+		{"skin tone", "👍🏽👍", 2, 1, "👍🏽"},
+		{"flags", "🇩🇪🇫🇷🇯🇵", 3, 2, "🇩🇪🇫🇷"},
+		{"crlf", "a\r\nb", 3, 2, "a\r\n"},
+		{"hangul jamo", "\u1100\u1161\u11a8x", 2, 1, "\u1100\u1161\u11a8"},
+		{"shorter than limit", "ok 🎉", 4, 10, "ok 🎉"},
+		{"zero limit", "abc", 3, 0, ""},
+		{"negative limit", "abc", 3, -1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := util.GraphemeLen(tt.s); got != tt.length {
+				t.Errorf("GraphemeLen(%q) = %d, want %d", tt.s, got, tt.length)
+			}
+			if got := util.TruncateGraphemes(tt.s, tt.truncate); got != tt.truncated {
+				t.Errorf("TruncateGraphemes(%q, %d) = %q, want %q", tt.s, tt.truncate, got, tt.truncated)
+			}
+		})
+	}
+}
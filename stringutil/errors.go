@@ -0,0 +1,6 @@
+package stringutil
+
+import "errors"
+
+// ErrUnterminatedQuote is returned when a quoted section is not closed before the end of the input
+var ErrUnterminatedQuote = errors.New("unterminated quote")
@@ -0,0 +1,24 @@
+package stringutil
+
+import "github.com/rivo/uniseg"
+
+// GraphemeLen returns the number of user-perceived characters in s (Unicode extended grapheme clusters)
+// An emoji with skin tone or ZWJ sequence ("👩‍💻"), a flag ("🇩🇪") or a letter with combining marks ("é" as
+// e + U+0301) counts once, where len counts bytes and utf8.RuneCountInString counts code points.
+func (u *StringUtil) GraphemeLen(s string) int {
+	return uniseg.GraphemeClusterCount(s)
+}
+
+// TruncateGraphemes returns the first n user-perceived characters of s, never splitting an emoji or
+// dropping combining marks from the last character; s is returned unchanged when it is not longer than n
+func (u *StringUtil) TruncateGraphemes(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	state := -1
+	rest := s
+	for i := 0; i < n && rest != ""; i++ {
+		_, rest, _, state = uniseg.FirstGraphemeClusterInString(rest, state)
+	}
+	return s[:len(s)-len(rest)]
+}
@@ -0,0 +1,95 @@
+package stringutil
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Levenshtein returns the edit distance between a and b, counted in runes
+func (u *StringUtil) Levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+	// Two rows of the DP table, sized by the shorter string
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// Similarity returns a score from 0 (nothing in common) to 1 (identical) based on the edit distance
+// Two empty strings are identical.
+func (u *StringUtil) Similarity(a, b string) float64 {
+	longest := utf8.RuneCountInString(a)
+	if n := utf8.RuneCountInString(b); n > longest {
+		longest = n
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(u.Levenshtein(a, b))/float64(longest)
+}
+
+// SimilarityCluster is a group of near-duplicate strings found by DedupeSimilar
+type SimilarityCluster struct {
+	Representative string   // the first member in input order
+	Members        []string // every member in input order, including the representative
+	Indexes        []int    // positions of Members in the input
+}
+
+// DedupeSimilar groups items whose Similarity to a cluster's representative is at least threshold
+// Items are compared case-insensitively with surrounding and repeated whitespace ignored, so
+// " New  York" and "new york" always match. Each item joins the first cluster it matches, in input
+// order, and clusters are returned in the order of their representatives. A threshold of 1 groups
+// only items that are equal after that normalization.
+func (u *StringUtil) DedupeSimilar(items []string, threshold float64) []SimilarityCluster {
+	clusters := []SimilarityCluster{}
+	normalized := []string{} // normalized representative of each cluster
+	for i, item := range items {
+		key := normalizeForSimilarity(item)
+		matched := -1
+		for c, rep := range normalized {
+			if rep == key || u.Similarity(rep, key) >= threshold {
+				matched = c
+				break
+			}
+		}
+		if matched < 0 {
+			clusters = append(clusters, SimilarityCluster{Representative: item})
+			normalized = append(normalized, key)
+			matched = len(clusters) - 1
+		}
+		clusters[matched].Members = append(clusters[matched].Members, item)
+		clusters[matched].Indexes = append(clusters[matched].Indexes, i)
+	}
+	return clusters
+}
+
+// normalizeForSimilarity lower-cases s and collapses its whitespace
+func normalizeForSimilarity(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+// minInt returns the smallest of its arguments
+func minInt(first int, rest ...int) int {
+	for _, v := range rest {
+		if v < first {
+			first = v
+		}
+	}
+	return first
+}
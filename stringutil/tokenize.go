@@ -0,0 +1,158 @@
+package stringutil
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SplitQuoted splits s on sep (default ",") like a CSV field list, leaving separators inside quotes alone
+// Double and single quotes group text and are removed, a doubled quote inside quotes is a literal quote,
+// and a backslash escapes the next character anywhere (write \' for an apostrophe). Whitespace around
+// each field is trimmed unless it is quoted. A blank s returns an empty slice; an unclosed quote returns
+// an error wrapping ErrUnterminatedQuote.
+func (u *StringUtil) SplitQuoted(s, sep string) ([]string, error) {
+	if sep == "" {
+		sep = ","
+	}
+	fields := []string{}
+	if strings.TrimSpace(s) == "" {
+		return fields, nil
+	}
+
+	var cur strings.Builder
+	protected := 0 // length of cur that came from quotes or escapes and must not be trimmed
+	var quote rune
+	quoteStart := 0
+	flush := func() {
+		field := cur.String()
+		fields = append(fields, field[:protected]+strings.TrimRightFunc(field[protected:], unicode.IsSpace))
+		cur.Reset()
+		protected = 0
+	}
+
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == '\\' && i+size < len(s):
+			next, nextSize := utf8.DecodeRuneInString(s[i+size:])
+			cur.WriteRune(next)
+			protected = cur.Len()
+			i += size + nextSize
+			continue
+		case quote != 0:
+			if r == quote {
+				if strings.HasPrefix(s[i+size:], string(quote)) {
+					cur.WriteRune(quote)
+					protected = cur.Len()
+					i += 2 * size
+					continue
+				}
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+			protected = cur.Len()
+		case r == '"' || r == '\'':
+			quote, quoteStart = r, i
+			protected = cur.Len()
+		case strings.HasPrefix(s[i:], sep):
+			flush()
+			i += len(sep)
+			continue
+		case cur.Len() == 0 && unicode.IsSpace(r):
+			// leading whitespace of an unquoted field
+		default:
+			cur.WriteRune(r)
+		}
+		i += size
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("%w: %c opened at offset %d", ErrUnterminatedQuote, quote, quoteStart)
+	}
+	flush()
+	return fields, nil
+}
+
+// runeClass groups runes for SplitCamelCase
+type runeClass int
+
+const (
+	classOther runeClass = iota
+	classLower
+	classUpper
+	classDigit
+)
+
+// classify returns the class of r
+func classify(r rune) runeClass {
+	switch {
+	case unicode.IsLower(r):
+		return classLower
+	case unicode.IsUpper(r), unicode.IsTitle(r):
+		return classUpper
+	case unicode.IsDigit(r):
+		return classDigit
+	}
+	return classOther
+}
+
+// SplitCamelCase splits an identifier into its words, e.g. "parseHTTPResponse2" into [parse HTTP Response 2]
+// Acronyms stay together, digit runs form their own words, and other characters ('_', '-', spaces, ...)
+// separate words and are dropped, so snake_case and kebab-case input splits as well.
+func (u *StringUtil) SplitCamelCase(s string) []string {
+	words := []string{}
+	var word []rune
+	prev := classOther
+	for _, r := range s {
+		class := classify(r)
+		switch {
+		case class == classOther:
+			if len(word) > 0 {
+				words = append(words, string(word))
+			}
+			word = nil
+		case len(word) == 0 || class == prev:
+			word = append(word, r)
+		case prev == classUpper && class == classLower:
+			// The last capital of an acronym starts the next word: "HTTPServer" -> HTTP, Server
+			if len(word) > 1 {
+				words = append(words, string(word[:len(word)-1]))
+				word = word[len(word)-1:]
+			}
+			word = append(word, r)
+		default:
+			words = append(words, string(word))
+			word = []rune{r}
+		}
+		prev = class
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// FieldsN splits s around whitespace like strings.Fields but returns at most n fields
+// The last field holds the unsplit remainder with its inner whitespace intact, which suits
+// "command arg rest of line" style input. A non-positive n returns all fields.
+func (u *StringUtil) FieldsN(s string, n int) []string {
+	if n <= 0 {
+		return strings.Fields(s)
+	}
+	fields := make([]string, 0, n)
+	rest := strings.TrimLeftFunc(s, unicode.IsSpace)
+	for rest != "" && len(fields) < n-1 {
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			break
+		}
+		fields = append(fields, rest[:end])
+		rest = strings.TrimLeftFunc(rest[end:], unicode.IsSpace)
+	}
+	if rest = strings.TrimRightFunc(rest, unicode.IsSpace); rest != "" {
+		fields = append(fields, rest)
+	}
+	return fields
+}
@@ -0,0 +1,167 @@
+package templateutil
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/template"
+
+	"github.com/mustanish/common-utils/v2/cacheutil"
+	"github.com/mustanish/common-utils/v2/collectionutil"
+	"github.com/mustanish/common-utils/v2/dateutil"
+)
+
+// TemplateConfig holds configuration for template rendering
+type TemplateConfig struct {
+	// Strict fails rendering when a map key is missing instead of printing "<no value>"
+	Strict bool
+
+	// Custom action delimiters (default "{{" and "}}")
+	LeftDelim  string
+	RightDelim string
+
+	// Funcs adds to, or overrides, the built-in helper functions
+	Funcs template.FuncMap
+
+	// CacheSize bounds how many parsed RenderString templates are kept
+	CacheSize int
+}
+
+// DefaultTemplateConfig returns default configuration
+func DefaultTemplateConfig() *TemplateConfig {
+	return &TemplateConfig{
+		Strict:     false,
+		LeftDelim:  "{{",
+		RightDelim: "}}",
+		CacheSize:  256,
+	}
+}
+
+// TemplateClient defines the interface for rendering text templates
+type TemplateClient interface {
+	RenderString(text string, data any) (string, error)
+	RenderFile(path string, data any) (string, error)
+	Render(w io.Writer, text string, data any) error
+
+	// FuncMap returns the helper functions, e.g. to register them on an html/template
+	FuncMap() template.FuncMap
+}
+
+// TemplateUtil renders text/template templates preloaded with helper functions
+type TemplateUtil struct {
+	config     TemplateConfig
+	funcs      template.FuncMap
+	parsed     cacheutil.Cache[string, *template.Template]
+	collection collectionutil.CollectionClient
+	date       dateutil.DateClient
+}
+
+// NewTemplateUtil creates a new template utility instance
+// Pass nil for config to use all defaults, or pass config with only the properties you want to override
+func NewTemplateUtil(config *TemplateConfig) TemplateClient {
+	defaults := DefaultTemplateConfig()
+
+	if config != nil {
+		if config.Strict {
+			defaults.Strict = true
+		}
+		if config.LeftDelim != "" {
+			defaults.LeftDelim = config.LeftDelim
+		}
+		if config.RightDelim != "" {
+			defaults.RightDelim = config.RightDelim
+		}
+		if config.Funcs != nil {
+			defaults.Funcs = config.Funcs
+		}
+		if config.CacheSize > 0 {
+			defaults.CacheSize = config.CacheSize
+		}
+	}
+
+	util := &TemplateUtil{
+		config:     *defaults,
+		parsed:     cacheutil.NewMemoryCache[string, *template.Template](&cacheutil.CacheConfig{MaxEntries: defaults.CacheSize}),
+		collection: collectionutil.NewCollectionUtil(),
+		date:       dateutil.NewDateUtil(),
+	}
+	util.funcs = util.builtinFuncs()
+	for name, fn := range defaults.Funcs {
+		util.funcs[name] = fn
+	}
+	return util
+}
+
+// RenderString renders a template given as text; parsed templates are cached by their text
+func (t *TemplateUtil) RenderString(text string, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Render(&buf, text, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Render renders a template given as text into w
+func (t *TemplateUtil) Render(w io.Writer, text string, data any) error {
+	tmpl, ok := t.parsed.Get(text)
+	if !ok {
+		var err error
+		tmpl, err = t.parse("inline", text)
+		if err != nil {
+			return err
+		}
+		t.parsed.Set(text, tmpl)
+	}
+	return t.execute(w, tmpl, data)
+}
+
+// RenderFile reads and renders the template at path
+// Files are parsed on every call so edits are picked up without a restart.
+func (t *TemplateUtil) RenderFile(path string, data any) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read template: %w", err)
+	}
+	tmpl, err := t.parse(filepath.Base(path), string(content))
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.execute(&buf, tmpl, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// FuncMap returns a copy of the helper functions available to templates
+func (t *TemplateUtil) FuncMap() template.FuncMap {
+	funcs := make(template.FuncMap, len(t.funcs))
+	for name, fn := range t.funcs {
+		funcs[name] = fn
+	}
+	return funcs
+}
+
+// parse parses text with the configured delimiters, helpers and missing-key mode
+func (t *TemplateUtil) parse(name, text string) (*template.Template, error) {
+	tmpl := template.New(name).Delims(t.config.LeftDelim, t.config.RightDelim).Funcs(t.funcs)
+	if t.config.Strict {
+		tmpl = tmpl.Option("missingkey=error")
+	}
+	parsed, err := tmpl.Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return parsed, nil
+}
+
+// execute runs a parsed template
+func (t *TemplateUtil) execute(w io.Writer, tmpl *template.Template, data any) error {
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render template: %w", err)
+	}
+	return nil
+}
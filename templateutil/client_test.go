@@ -0,0 +1,176 @@
+package templateutil
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestNewTemplateUtil(t *testing.T) {
+	util := NewTemplateUtil(nil).(*TemplateUtil)
+	if util.config.Strict || util.config.LeftDelim != "{{" || util.config.CacheSize != 256 {
+		t.Errorf("default config = %+v", util.config)
+	}
+
+	custom := NewTemplateUtil(&TemplateConfig{Strict: true, LeftDelim: "[[", RightDelim: "]]"}).(*TemplateUtil)
+	if !custom.config.Strict || custom.config.LeftDelim != "[[" || custom.config.CacheSize != 256 {
+		t.Errorf("custom config = %+v", custom.config)
+	}
+}
+
+// =================== Test Rendering ===================
+
+func TestRenderString(t *testing.T) {
+	util := NewTemplateUtil(nil)
+
+	tests := []struct {
+		name      string
+		text      string
+		data      any
+		expected  string
+		expectErr bool
+	}{
+		{"plain field", "Hello {{ .Name }}", map[string]any{"Name": "Ada"}, "Hello Ada", false},
+		{"missing key lenient", "Hello {{ .Name }}", map[string]any{}, "Hello <no value>", false},
+		{"parse error", "Hello {{ .Name", nil, "", true},
+		{"execution error", "{{ toInt .N }}", map[string]any{"N": "abc"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := util.RenderString(tt.text, tt.data)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("RenderString() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if result != tt.expected {
+				t.Errorf("RenderString() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRenderStringStrict(t *testing.T) {
+	util := NewTemplateUtil(&TemplateConfig{Strict: true})
+
+	if _, err := util.RenderString("Hello {{ .Name }}", map[string]any{}); err == nil {
+		t.Error("RenderString() in strict mode expected missing key error")
+	}
+	if result, err := util.RenderString("Hello {{ .Name }}", map[string]any{"Name": "Ada"}); err != nil || result != "Hello Ada" {
+		t.Errorf("RenderString() = %q, %v; want Hello Ada", result, err)
+	}
+}
+
+func TestRenderFile(t *testing.T) {
+	util := NewTemplateUtil(&TemplateConfig{LeftDelim: "[[", RightDelim: "]]"})
+	path := filepath.Join(t.TempDir(), "greeting.tmpl")
+	if err := os.WriteFile(path, []byte("Hi [[ .Name | upper ]] {{ literal }}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := util.RenderFile(path, map[string]string{"Name": "ada"})
+	if err != nil || result != "Hi ADA {{ literal }}" {
+		t.Errorf("RenderFile() = %q, %v; want %q", result, err, "Hi ADA {{ literal }}")
+	}
+
+	if _, err := util.RenderFile(filepath.Join(t.TempDir(), "missing.tmpl"), nil); err == nil {
+		t.Error("RenderFile() with missing file expected error")
+	}
+}
+
+func TestCustomFuncs(t *testing.T) {
+	util := NewTemplateUtil(&TemplateConfig{Funcs: template.FuncMap{
+		"shout": func(s string) string { return s + "!" },
+		"upper": func(s string) string { return "overridden" },
+	}})
+
+	result, err := util.RenderString("{{ shout .A }} {{ upper .A }}", map[string]string{"A": "hi"})
+	if err != nil || result != "hi! overridden" {
+		t.Errorf("RenderString() = %q, %v; want %q", result, err, "hi! overridden")
+	}
+	if _, ok := util.FuncMap()["formatDate"]; !ok {
+		t.Error("FuncMap() missing built-in formatDate")
+	}
+}
+
+// =================== Test Helper Functions ===================
+
+func TestBuiltinFuncs(t *testing.T) {
+	util := NewTemplateUtil(nil)
+	date := time.Date(2024, 3, 5, 14, 30, 0, 0, time.UTC)
+	data := map[string]any{
+		"Name":  "ada lovelace",
+		"Empty": "",
+		"Tags":  []string{"go", "csv", "go"},
+		"Nums":  []int{1, 2, 3},
+		"Date":  date,
+		"Ptr":   &date,
+		"Unix":  date.Unix(),
+		"Flag":  "yes",
+	}
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{`{{ .Name | upper }}`, "ADA LOVELACE"},
+		{`{{ .Name | title }}`, "Ada Lovelace"},
+		{`{{ "  x  " | trim }}`, "x"},
+		{`{{ .Name | replace "ada" "grace" }}`, "grace lovelace"},
+		{`{{ .Name | contains "love" }}`, "true"},
+		{`{{ .Name | hasPrefix "ada" }} {{ .Name | hasSuffix "x" }}`, "true false"},
+		{`{{ .Name | trimPrefix "ada " | trimSuffix "lace" }}`, "love"},
+		{`{{ "ab" | repeat 3 }}`, "ababab"},
+		{`{{ .Name | truncate 6 }}`, "ada..."},
+		{`{{ .Tags | join ", " }}`, "go, csv, go"},
+		{`{{ .Nums | join "+" }}`, "1+2+3"},
+		{`{{ "a, b,c" | split "," | join "|" }}`, "a|b|c"},
+		{`{{ .Tags | unique | join "," }}`, "go,csv"},
+		{`{{ .Tags | has "csv" }}`, "true"},
+		{`{{ .Empty | default "n/a" }} {{ .Name | default "n/a" }}`, "n/a ada lovelace"},
+		{`{{ .Missing | default "none" }}`, "none"},
+		{`{{ empty .Empty }}`, "true"},
+		{`{{ .Date | formatDate "2006-01-02" }}`, "2024-03-05"},
+		{`{{ .Ptr | formatDate "15:04" }}`, "14:30"},
+		{`{{ "2024-03-05" | formatDate "Jan 2, 2006" }}`, "Mar 5, 2024"},
+		{`{{ .Unix | formatDate "2006" }}`, "2024"},
+		{`{{ .Date | addDays 30 | formatDate "2006-01-02" }}`, "2024-04-04"},
+		{`{{ (parseDate "03/05/2024").Month }}`, "March"},
+		{`{{ toBool .Flag }} {{ toInt "42" }} {{ toFloat "1.5" }} {{ toString 7 }}`, "true 42 1.5 7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			result, err := util.RenderString(tt.text, data)
+			if err != nil {
+				t.Fatalf("RenderString(%s) error = %v", tt.text, err)
+			}
+			if result != tt.expected {
+				t.Errorf("RenderString(%s) = %q, want %q", tt.text, result, tt.expected)
+			}
+		})
+	}
+
+	if _, err := util.RenderString(`{{ 5 | join "," }}`, nil); err == nil || !strings.Contains(err.Error(), "join expects a slice") {
+		t.Errorf("join on non-slice error = %v", err)
+	}
+	if _, err := util.RenderString(`{{ "not a date" | formatDate "2006" }}`, nil); err == nil {
+		t.Error("formatDate with invalid date expected error")
+	}
+	if result, _ := util.RenderString(`{{ "now" | truncate 2 }}|{{ "hi" | truncate 5 }}`, nil); result != "no|hi" {
+		t.Errorf("truncate short cases = %q, want %q", result, "no|hi")
+	}
+}
+
+// =================== Benchmarks ===================
+
+func BenchmarkRenderStringCached(b *testing.B) {
+	util := NewTemplateUtil(nil)
+	data := map[string]any{"Name": "ada", "Tags": []string{"a", "b"}}
+
+	for i := 0; i < b.N; i++ {
+		_, _ = util.RenderString(`Hello {{ .Name | title }} ({{ .Tags | join ", " }})`, data)
+	}
+}
@@ -0,0 +1,136 @@
+package templateutil
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// builtinFuncs returns the helper functions available to every template
+// Argument order puts the piped value last, e.g. {{ .Tags | join ", " }} or {{ .Name | default "n/a" }}.
+func (t *TemplateUtil) builtinFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Strings
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"title":      title,
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"repeat":     func(count int, s string) string { return strings.Repeat(s, count) },
+		"truncate":   truncate,
+		"split":      t.split,
+		"join":       t.join,
+
+		// Dates
+		"now":        t.date.Now,
+		"formatDate": t.formatDate,
+		"parseDate":  func(s string) (time.Time, error) { return t.date.Parse(s) },
+		"addDays":    func(days int, date any) (time.Time, error) { return t.addDays(days, date) },
+
+		// Collections and conversion
+		"default":  t.defaultValue,
+		"empty":    t.collection.IsEmpty,
+		"unique":   t.collection.SliceUnique,
+		"has":      func(item string, slice []string) bool { return t.collection.SliceContains(slice, item) },
+		"toString": t.collection.ConvertToString,
+		"toInt":    t.collection.ConvertToInteger,
+		"toFloat":  t.collection.ConvertToFloat64,
+		"toBool":   t.collection.ConvertToBool,
+	}
+}
+
+// defaultValue returns def when value is empty according to collectionutil.IsEmpty
+func (t *TemplateUtil) defaultValue(def, value any) any {
+	if t.collection.IsEmpty(value) {
+		return def
+	}
+	return value
+}
+
+// join concatenates the elements of any slice or array using collectionutil's string conversion
+func (t *TemplateUtil) join(sep string, list any) (string, error) {
+	if list == nil {
+		return "", nil
+	}
+	rv := reflect.ValueOf(list)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return "", fmt.Errorf("join expects a slice, got %T", list)
+	}
+	parts := make([]string, rv.Len())
+	for i := range parts {
+		parts[i] = t.collection.ConvertToString(rv.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
+
+// split splits s on sep, trimming whitespace around each part
+func (t *TemplateUtil) split(sep, s string) ([]string, error) {
+	return t.collection.ConvertToSlice(s, sep)
+}
+
+// formatDate formats a time.Time, *time.Time, date string or Unix timestamp with a Go layout
+func (t *TemplateUtil) formatDate(layout string, date any) (string, error) {
+	parsed, err := t.toTime(date)
+	if err != nil {
+		return "", err
+	}
+	return t.date.Format(parsed, layout), nil
+}
+
+// addDays adds days to a date given in any form accepted by formatDate
+func (t *TemplateUtil) addDays(days int, date any) (time.Time, error) {
+	parsed, err := t.toTime(date)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return t.date.AddDays(parsed, days), nil
+}
+
+// toTime converts template values to time.Time using dateutil's parsers
+func (t *TemplateUtil) toTime(date any) (time.Time, error) {
+	switch v := date.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, fmt.Errorf("date is nil")
+		}
+		return *v, nil
+	case string:
+		return t.date.Parse(v)
+	default:
+		return t.date.ParseUnix(date)
+	}
+}
+
+// title upper-cases the first letter of each space-separated word
+func title(s string) string {
+	prev := ' '
+	return strings.Map(func(r rune) rune {
+		defer func() { prev = r }()
+		if unicode.IsSpace(prev) {
+			return unicode.ToTitle(r)
+		}
+		return r
+	}, s)
+}
+
+// truncate shortens s to at most length runes, ending with "..." when cut
+func truncate(length int, s string) string {
+	if length < 0 || utf8.RuneCountInString(s) <= length {
+		return s
+	}
+	if length <= 3 {
+		return string([]rune(s)[:length])
+	}
+	return string([]rune(s)[:length-3]) + "..."
+}
@@ -0,0 +1,50 @@
+// Package testutil provides fixtures and fakes for testing code built on the other packages in this module.
+package testutil
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a manually controlled clock satisfying dateutil.Clock
+// It is safe for concurrent use and only moves when Advance or Set is called.
+type FakeClock struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewFakeClock creates a FakeClock starting at the given time
+// A zero start time defaults to 2024-01-01T00:00:00Z so tests stay deterministic.
+func NewFakeClock(start time.Time) *FakeClock {
+	if start.IsZero() {
+		start = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	}
+	return &FakeClock{now: start}
+}
+
+// Now returns the clock's current time
+func (c *FakeClock) Now() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d and returns the new time
+func (c *FakeClock) Advance(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	return c.now
+}
+
+// Set moves the clock to t
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+// Since returns the time elapsed on the fake clock since t
+func (c *FakeClock) Since(t time.Time) time.Duration {
+	return c.Now().Sub(t)
+}
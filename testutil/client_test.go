@@ -0,0 +1,327 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/mustanish/common-utils/v2/assertionutil"
+	"github.com/mustanish/common-utils/v2/dateutil"
+	"github.com/mustanish/common-utils/v2/httputil"
+	"github.com/mustanish/common-utils/v2/logutil"
+)
+
+// =================== Test FakeClock ===================
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, time.March, 10, 9, 30, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	if got := clock.Now(); !got.Equal(start) {
+		t.Errorf("Now() = %v, want %v", got, start)
+	}
+	if got := clock.Advance(90 * time.Minute); !got.Equal(start.Add(90 * time.Minute)) {
+		t.Errorf("Advance() = %v, want %v", got, start.Add(90*time.Minute))
+	}
+	if got := clock.Since(start); got != 90*time.Minute {
+		t.Errorf("Since() = %v, want %v", got, 90*time.Minute)
+	}
+
+	later := start.AddDate(0, 1, 0)
+	clock.Set(later)
+	if got := clock.Now(); !got.Equal(later) {
+		t.Errorf("Now() after Set = %v, want %v", got, later)
+	}
+
+	if NewFakeClock(time.Time{}).Now().IsZero() {
+		t.Error("NewFakeClock(zero) should start at a fixed non-zero time")
+	}
+}
+
+func TestFakeClock_DateUtil(t *testing.T) {
+	var _ dateutil.Clock = (*FakeClock)(nil)
+
+	clock := NewFakeClock(time.Date(2024, time.February, 28, 23, 0, 0, 0, time.UTC))
+	util := dateutil.NewDateUtilWithClock(clock)
+
+	if got, want := util.Tomorrow(), time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Tomorrow() = %v, want %v", got, want)
+	}
+	clock.Advance(2 * time.Hour)
+	if got, want := util.Today(), time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("Today() after Advance = %v, want %v", got, want)
+	}
+}
+
+// =================== Test Server ===================
+
+func TestServer_JSONRoutes(t *testing.T) {
+	srv := NewServerBuilder().
+		JSON(http.MethodGet, "/users", http.StatusOK, []map[string]any{{"id": 1}}).
+		JSON(http.MethodPost, "/users", http.StatusCreated, `{"id":`).
+		Start(t)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"encoded body", http.MethodGet, "/users", http.StatusOK, `[{"id":1}]`},
+		{"raw body", http.MethodPost, "/users", http.StatusCreated, `{"id":`},
+		{"unknown path", http.MethodGet, "/missing", http.StatusNotFound, ""},
+		{"unknown method", http.MethodDelete, "/users", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, srv.URL+tt.path, nil)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+
+	if got := srv.Hits(http.MethodGet, "/users"); got != 1 {
+		t.Errorf("Hits() = %d, want 1", got)
+	}
+}
+
+func TestServer_Faults(t *testing.T) {
+	srv := NewServerBuilder().
+		JSON(http.MethodGet, "/flaky", http.StatusOK, map[string]string{"ok": "yes"}).
+		DropFirst(http.MethodGet, "/flaky", 1).
+		FailFirst(http.MethodGet, "/flaky", 2, http.StatusServiceUnavailable).
+		Start(t)
+
+	client := httputil.NewHTTPUtil(logutil.NewNopLogger(), &httputil.HTTPConfig{
+		MaxRetries:  3,
+		InitialWait: time.Millisecond,
+		MaxWait:     5 * time.Millisecond,
+	})
+
+	resp, err := client.Get(context.Background(), srv.URL+"/flaky", nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := srv.Hits(http.MethodGet, "/flaky"); got != 4 {
+		t.Errorf("Hits() = %d, want 4", got)
+	}
+}
+
+func TestServer_Latency(t *testing.T) {
+	srv := NewServerBuilder().
+		JSON(http.MethodGet, "/slow", http.StatusOK, "{}").
+		RouteLatency(http.MethodGet, "/slow", 200*time.Millisecond).
+		Start(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/slow", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err == nil {
+		resp.Body.Close()
+		t.Fatal("expected the request to time out")
+	}
+}
+
+// =================== Test FaultTransport ===================
+
+func TestFaultTransport_Script(t *testing.T) {
+	srv := NewServerBuilder().
+		JSON(http.MethodGet, "/orders", http.StatusOK, map[string]string{"ok": "yes"}).
+		Start(t)
+
+	ft := NewFaultTransport(FaultConfig{Script: []Fault{
+		{Status: http.StatusServiceUnavailable},
+		{Reset: true, Latency: time.Millisecond},
+		{Err: errors.New("dial tcp: no route to host")},
+	}})
+	client := httputil.NewHTTPUtil(logutil.NewNopLogger(), &httputil.HTTPConfig{
+		MaxRetries:  3,
+		InitialWait: time.Millisecond,
+		MaxWait:     5 * time.Millisecond,
+	})
+	client.SetTransport(ft)
+
+	resp, err := client.Get(context.Background(), srv.URL+"/orders", nil)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if ft.Requests() != 4 || ft.Injected() != 3 {
+		t.Errorf("Requests(), Injected() = %d, %d, want 4, 3", ft.Requests(), ft.Injected())
+	}
+	if got := srv.Hits(http.MethodGet, "/orders"); got != 1 {
+		t.Errorf("Hits() = %d, want 1", got)
+	}
+}
+
+func TestFaultTransport_Rates(t *testing.T) {
+	ok := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+	})
+	outcomes := func(seed int64) []string {
+		ft := NewFaultTransport(FaultConfig{Next: ok, ErrorRate: 0.2, ResetRate: 0.2, StatusRate: 0.2, Status: http.StatusBadGateway, Seed: seed})
+		var got []string
+		for i := 0; i < 200; i++ {
+			req, _ := http.NewRequest(http.MethodGet, "http://example.test/", nil)
+			resp, err := ft.RoundTrip(req)
+			switch {
+			case errors.Is(err, ErrInjectedFault):
+				got = append(got, "error")
+			case errors.Is(err, syscall.ECONNRESET):
+				got = append(got, "reset")
+			case err != nil:
+				t.Fatalf("RoundTrip() unexpected error: %v", err)
+			default:
+				got = append(got, strconv.Itoa(resp.StatusCode))
+			}
+		}
+		return got
+	}
+
+	first := outcomes(42)
+	if second := outcomes(42); !reflect.DeepEqual(first, second) {
+		t.Error("outcomes differ for the same seed")
+	}
+	counts := map[string]int{}
+	for _, outcome := range first {
+		counts[outcome]++
+	}
+	for _, outcome := range []string{"error", "reset", "502", "200"} {
+		if counts[outcome] < 20 {
+			t.Errorf("%s outcomes = %d of 200, want roughly a fifth or more", outcome, counts[outcome])
+		}
+	}
+}
+
+func TestFaultTransport_LatencyHonoursContext(t *testing.T) {
+	ft := NewFaultTransport(FaultConfig{MinLatency: time.Second, MaxLatency: 2 * time.Second})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://example.test/", nil)
+	start := time.Now()
+	if _, err := ft.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RoundTrip() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("RoundTrip() waited %v, want it to stop at the deadline", elapsed)
+	}
+}
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// =================== Test Golden Files ===================
+
+func TestAssertGolden(t *testing.T) {
+	previous := GoldenDir
+	GoldenDir = t.TempDir()
+	defer func() { GoldenDir = previous }()
+
+	t.Setenv(UpdateGoldenEnv, "1")
+	AssertGoldenJSON(t, "report", map[string]int{"b": 2, "a": 1})
+
+	data, err := os.ReadFile(filepath.Join(GoldenDir, "report.golden"))
+	if err != nil {
+		t.Fatalf("golden file not written: %v", err)
+	}
+	if want := "{\n  \"a\": 1,\n  \"b\": 2\n}\n"; string(data) != want {
+		t.Errorf("golden file = %q, want %q", data, want)
+	}
+
+	t.Setenv(UpdateGoldenEnv, "")
+	AssertGoldenJSON(t, "report", map[string]int{"a": 1, "b": 2})
+
+	if err := compareGolden(GoldenPath("report"), []byte("different")); err == nil {
+		t.Error("compareGolden() should report mismatched output")
+	}
+	if err := compareGolden(GoldenPath("missing"), nil); err == nil {
+		t.Error("compareGolden() should report a missing golden file")
+	}
+}
+
+// =================== Test Map Builders ===================
+
+func TestMapBuilder(t *testing.T) {
+	doc := NewMap().
+		Set("name", "alice").
+		Set("age", float64(30)).
+		SetNil("deleted_at").
+		Slice("tags", "a", "b").
+		Nested("address", func(b *MapBuilder) {
+			b.Set("city", "Pune")
+		}).
+		Build()
+
+	util := assertionutil.NewAssertionUtil()
+	if got, _ := util.GetString(doc, "name"); got != "alice" {
+		t.Errorf("GetString(name) = %q, want %q", got, "alice")
+	}
+	if got, _ := util.GetInt(doc, "age"); got != 30 {
+		t.Errorf("GetInt(age) = %d, want 30", got)
+	}
+	if got, _ := util.GetStringSlice(doc, "tags"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("GetStringSlice(tags) = %v, want [a b]", got)
+	}
+	if got, _ := util.GetNestedString(doc, "address", "city"); got != "Pune" {
+		t.Errorf("GetNestedString(address.city) = %q, want %q", got, "Pune")
+	}
+	if !util.HasKey(doc, "deleted_at") {
+		t.Error("HasKey(deleted_at) = false, want true")
+	}
+}
+
+func TestMap(t *testing.T) {
+	got := Map("a", 1, "b", "two")
+	want := map[string]any{"a": 1, "b": "two"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Map() = %v, want %v", got, want)
+	}
+
+	for _, args := range [][]any{{"a"}, {1, "a"}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Map(%v) should panic", args)
+				}
+			}()
+			Map(args...)
+		}()
+	}
+}
@@ -0,0 +1,66 @@
+package testutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// UpdateGoldenEnv is the environment variable that rewrites golden files instead of comparing them
+// Run `UPDATE_GOLDEN=1 go test ./...` after an intentional output change.
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// GoldenDir is the directory, relative to the package under test, that holds golden files
+var GoldenDir = filepath.Join("testdata", "golden")
+
+// GoldenPath returns the path of the golden file for name
+func GoldenPath(name string) string {
+	return filepath.Join(GoldenDir, name+".golden")
+}
+
+// AssertGolden compares got against the golden file for name
+// When UPDATE_GOLDEN is set the file is (re)written and the assertion passes.
+func AssertGolden(t testing.TB, name string, got []byte) {
+	t.Helper()
+
+	path := GoldenPath(name)
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("failed to create golden directory: %v", err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("failed to write golden file %s: %v", path, err)
+		}
+		return
+	}
+
+	if err := compareGolden(path, got); err != nil {
+		t.Error(err)
+	}
+}
+
+// compareGolden reports how got differs from the golden file at path
+func compareGolden(path string, got []byte) error {
+	want, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read golden file %s (set %s=1 to create it): %w", path, UpdateGoldenEnv, err)
+	}
+	if !bytes.Equal(got, want) {
+		return fmt.Errorf("output does not match golden file %s\n--- got ---\n%s\n--- want ---\n%s", path, got, want)
+	}
+	return nil
+}
+
+// AssertGoldenJSON marshals v as indented JSON and compares it against the golden file for name
+func AssertGoldenJSON(t testing.TB, name string, v any) {
+	t.Helper()
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal golden value: %v", err)
+	}
+	AssertGolden(t, name, append(data, '\n'))
+}
@@ -0,0 +1,66 @@
+package testutil
+
+import "fmt"
+
+// MapBuilder assembles map[string]any documents for assertionutil-style tests
+// Values are stored as given, so callers control the exact types under test.
+type MapBuilder struct {
+	m map[string]any
+}
+
+// NewMap creates an empty MapBuilder
+func NewMap() *MapBuilder {
+	return &MapBuilder{m: make(map[string]any)}
+}
+
+// Set stores value under key
+func (b *MapBuilder) Set(key string, value any) *MapBuilder {
+	b.m[key] = value
+	return b
+}
+
+// SetNil stores an explicit nil under key
+func (b *MapBuilder) SetNil(key string) *MapBuilder {
+	b.m[key] = nil
+	return b
+}
+
+// Nested stores the map built by fn under key
+func (b *MapBuilder) Nested(key string, fn func(*MapBuilder)) *MapBuilder {
+	child := NewMap()
+	fn(child)
+	b.m[key] = child.Build()
+	return b
+}
+
+// Slice stores values under key as a []any, the shape encoding/json produces
+func (b *MapBuilder) Slice(key string, values ...any) *MapBuilder {
+	b.m[key] = append([]any{}, values...)
+	return b
+}
+
+// Build returns a copy of the assembled map
+func (b *MapBuilder) Build() map[string]any {
+	out := make(map[string]any, len(b.m))
+	for k, v := range b.m {
+		out[k] = v
+	}
+	return out
+}
+
+// Map builds a map from alternating key/value arguments
+// It panics when the arguments are unbalanced or a key is not a string, since that is a bug in the test itself.
+func Map(kv ...any) map[string]any {
+	if len(kv)%2 != 0 {
+		panic(fmt.Sprintf("testutil.Map: odd number of arguments (%d)", len(kv)))
+	}
+	out := make(map[string]any, len(kv)/2)
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			panic(fmt.Sprintf("testutil.Map: key at position %d is %T, want string", i, kv[i]))
+		}
+		out[key] = kv[i+1]
+	}
+	return out
+}
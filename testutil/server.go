@@ -0,0 +1,212 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fault is a single injected failure consumed by the next request to a route
+type fault struct {
+	status int
+	drop   bool
+}
+
+// route is a canned endpoint registered on a ServerBuilder
+type route struct {
+	handler http.HandlerFunc
+	latency time.Duration
+	faults  []fault
+}
+
+// ServerBuilder declares canned routes and fault injection for an httptest server
+// Routes are matched on exact method and path; anything else returns 404.
+type ServerBuilder struct {
+	routes  map[string]*route
+	latency time.Duration
+	err     error
+}
+
+// Server is a running test server built by ServerBuilder
+type Server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	routes map[string]*route
+	hits   map[string]int
+}
+
+// NewServerBuilder creates an empty ServerBuilder
+func NewServerBuilder() *ServerBuilder {
+	return &ServerBuilder{routes: make(map[string]*route)}
+}
+
+// routeKey identifies a route by method and path
+func routeKey(method, path string) string {
+	return method + " " + path
+}
+
+// route returns the route for method and path, creating a 404 placeholder if needed
+func (b *ServerBuilder) route(method, path string) *route {
+	key := routeKey(method, path)
+	r, ok := b.routes[key]
+	if !ok {
+		r = &route{handler: http.NotFound}
+		b.routes[key] = r
+	}
+	return r
+}
+
+// JSON registers a route answering with status and body encoded as JSON
+// A []byte or string body is written verbatim, which allows malformed payloads in tests.
+func (b *ServerBuilder) JSON(method, path string, status int, body any) *ServerBuilder {
+	var data []byte
+	switch v := body.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			b.err = fmt.Errorf("failed to encode body for %s: %w", routeKey(method, path), err)
+			return b
+		}
+		data = encoded
+	}
+
+	b.route(method, path).handler = func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write(data)
+	}
+	return b
+}
+
+// Handle registers a custom handler for method and path
+func (b *ServerBuilder) Handle(method, path string, handler http.HandlerFunc) *ServerBuilder {
+	b.route(method, path).handler = handler
+	return b
+}
+
+// Latency delays every response by d
+func (b *ServerBuilder) Latency(d time.Duration) *ServerBuilder {
+	b.latency = d
+	return b
+}
+
+// RouteLatency delays responses for a single route by d, on top of any server-wide latency
+func (b *ServerBuilder) RouteLatency(method, path string, d time.Duration) *ServerBuilder {
+	b.route(method, path).latency = d
+	return b
+}
+
+// FailFirst makes the next n requests to a route answer with status before the canned response is served
+// Faults are consumed in the order they are registered.
+func (b *ServerBuilder) FailFirst(method, path string, n, status int) *ServerBuilder {
+	r := b.route(method, path)
+	for i := 0; i < n; i++ {
+		r.faults = append(r.faults, fault{status: status})
+	}
+	return b
+}
+
+// DropFirst makes the next n requests to a route close the connection without a response
+func (b *ServerBuilder) DropFirst(method, path string, n int) *ServerBuilder {
+	r := b.route(method, path)
+	for i := 0; i < n; i++ {
+		r.faults = append(r.faults, fault{drop: true})
+	}
+	return b
+}
+
+// Start launches the server and registers its shutdown with t.Cleanup
+func (b *ServerBuilder) Start(t testing.TB) *Server {
+	t.Helper()
+	if b.err != nil {
+		t.Fatalf("testutil: %v", b.err)
+	}
+
+	s := &Server{
+		routes: make(map[string]*route, len(b.routes)),
+		hits:   make(map[string]int),
+	}
+	for key, r := range b.routes {
+		copied := *r
+		copied.faults = append([]fault(nil), r.faults...)
+		s.routes[key] = &copied
+	}
+
+	latency := b.latency
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key := routeKey(req.Method, req.URL.Path)
+
+		s.mu.Lock()
+		s.hits[key]++
+		r, ok := s.routes[key]
+		var next *fault
+		if ok && len(r.faults) > 0 {
+			next = &r.faults[0]
+			r.faults = r.faults[1:]
+		}
+		s.mu.Unlock()
+
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		if !sleepContext(req, latency+r.latency) {
+			return
+		}
+		if next != nil {
+			if next.drop {
+				dropConnection(w)
+				return
+			}
+			w.WriteHeader(next.status)
+			return
+		}
+		r.handler(w, req)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Hits returns how many requests the server received for method and path
+func (s *Server) Hits(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[routeKey(method, path)]
+}
+
+// sleepContext waits for d unless the client goes away first
+func sleepContext(req *http.Request, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-req.Context().Done():
+		return false
+	}
+}
+
+// dropConnection closes the underlying connection so the client sees a transport error
+func dropConnection(w http.ResponseWriter) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic(http.ErrAbortHandler)
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		panic(http.ErrAbortHandler)
+	}
+	_ = conn.Close()
+}
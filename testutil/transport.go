@@ -0,0 +1,175 @@
+package testutil
+
+import (
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrInjectedFault is the transport error returned for requests failed by FaultTransport.ErrorRate
+var ErrInjectedFault = errors.New("testutil: injected transport fault")
+
+// Fault is a scripted outcome for a single request; the zero Fault passes the request through
+type Fault struct {
+	Latency time.Duration // delay before the outcome
+	Status  int           // answer with this status without calling Next
+	Err     error         // fail with this transport error
+	Reset   bool          // fail with a connection reset (syscall.ECONNRESET)
+}
+
+// FaultConfig configures a FaultTransport
+// Script is applied to the first len(Script) requests in order; later requests draw their outcome from the
+// rates, using a random source seeded with Seed so a run is reproducible.
+type FaultConfig struct {
+	// Transport that serves requests which are not failed (default http.DefaultTransport)
+	Next http.RoundTripper
+
+	// Outcomes of the first requests, e.g. []Fault{{Status: 503}, {Reset: true}} for two failures before success
+	Script []Fault
+
+	// Fractions of the remaining requests that fail, each between 0 and 1 and checked in this order
+	ErrorRate  float64 // fail with ErrInjectedFault
+	ResetRate  float64 // fail with a connection reset
+	StatusRate float64 // answer with Status
+
+	// Status for StatusRate failures (default 503 Service Unavailable)
+	Status int
+
+	// Uniform random latency added to every request that is not scripted
+	MinLatency time.Duration
+	MaxLatency time.Duration
+
+	// Seed of the random source; the same seed yields the same outcomes for the same request order
+	Seed int64
+}
+
+// FaultTransport is an http.RoundTripper that injects errors, resets, status codes and latency
+// Plug it into an httputil client with SetTransport to exercise retry and circuit-breaker paths:
+//
+//	ft := testutil.NewFaultTransport(testutil.FaultConfig{Script: []testutil.Fault{{Status: 503}, {Reset: true}}})
+//	client.SetTransport(ft)
+//
+// Latency honours the request context, so a timeout or cancellation ends the wait with the context error.
+type FaultTransport struct {
+	next   http.RoundTripper
+	config FaultConfig
+
+	mu       sync.Mutex
+	rng      *rand.Rand
+	requests int
+	injected int
+}
+
+// NewFaultTransport creates a FaultTransport from config
+func NewFaultTransport(config FaultConfig) *FaultTransport {
+	next := config.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	if config.Status == 0 {
+		config.Status = http.StatusServiceUnavailable
+	}
+	config.Script = append([]Fault(nil), config.Script...)
+	return &FaultTransport{
+		next:   next,
+		config: config,
+		rng:    rand.New(rand.NewSource(config.Seed)), // #nosec G404 -- deterministic test faults
+	}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *FaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault := t.nextFault()
+
+	if fault.Latency > 0 {
+		timer := time.NewTimer(fault.Latency)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			closeBody(req)
+			return nil, req.Context().Err()
+		}
+	}
+
+	switch {
+	case fault.Err != nil:
+		closeBody(req)
+		return nil, fault.Err
+	case fault.Reset:
+		closeBody(req)
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	case fault.Status != 0:
+		closeBody(req)
+		return &http.Response{
+			Status:        http.StatusText(fault.Status),
+			StatusCode:    fault.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"X-Injected-Fault": {"status"}},
+			Body:          io.NopCloser(strings.NewReader("")),
+			ContentLength: 0,
+			Request:       req,
+		}, nil
+	}
+	return t.next.RoundTrip(req)
+}
+
+// nextFault picks the outcome of the next request and updates the counters
+func (t *FaultTransport) nextFault() Fault {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.requests++
+
+	var fault Fault
+	if len(t.config.Script) > 0 {
+		fault = t.config.Script[0]
+		t.config.Script = t.config.Script[1:]
+	} else {
+		fault.Latency = t.config.MinLatency
+		if spread := t.config.MaxLatency - t.config.MinLatency; spread > 0 {
+			fault.Latency += time.Duration(t.rng.Int63n(int64(spread) + 1))
+		}
+		switch roll := t.rng.Float64(); {
+		case roll < t.config.ErrorRate:
+			fault.Err = ErrInjectedFault
+		case roll < t.config.ErrorRate+t.config.ResetRate:
+			fault.Reset = true
+		case roll < t.config.ErrorRate+t.config.ResetRate+t.config.StatusRate:
+			fault.Status = t.config.Status
+		}
+	}
+	if fault.Err != nil || fault.Reset || fault.Status != 0 {
+		t.injected++
+	}
+	return fault
+}
+
+// Requests returns how many requests reached the transport
+func (t *FaultTransport) Requests() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.requests
+}
+
+// Injected returns how many requests were failed with an error, reset or status
+func (t *FaultTransport) Injected() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.injected
+}
+
+// closeBody releases the request body, as a RoundTripper must even when it does not send the request
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
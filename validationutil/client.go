@@ -0,0 +1,121 @@
+// Package validationutil checks conditional and cross-field rules, such as "card_number is required when
+// type is card", against maps and structs.
+package validationutil
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/mustanish/common-utils/v2/errorutil"
+)
+
+// Rule checks one constraint of a document and returns the violations it finds
+type Rule func(doc Document) []errorutil.FieldError
+
+// Document gives rules access to the fields of a map or struct by name
+// Struct fields are found by their json tag name, falling back to the Go field name.
+type Document struct {
+	value reflect.Value
+}
+
+// Validate checks doc against every rule and returns a *ValidationError listing all violations, or nil
+// doc is a map with string keys (e.g. a decoded JSON object) or a struct or pointer to one:
+//
+//	err := validationutil.Validate(payment,
+//		validationutil.RequiredIf("type", "card", "card_number", "expiry"),
+//		validationutil.MutuallyExclusive("email", "phone"),
+//		validationutil.AtLeastOneOf("email", "phone"),
+//	)
+func Validate(doc any, rules ...Rule) error {
+	d, err := NewDocument(doc)
+	if err != nil {
+		return err
+	}
+	var fields []errorutil.FieldError
+	for _, rule := range rules {
+		fields = append(fields, rule(d)...)
+	}
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// NewDocument wraps a map with string keys or a struct (or pointer to one) for use with rules
+func NewDocument(doc any) (Document, error) {
+	v := reflect.ValueOf(doc)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return Document{}, fmt.Errorf("validationutil: nil document")
+		}
+		v = v.Elem()
+	}
+	switch {
+	case v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String:
+	case v.Kind() == reflect.Struct:
+	default:
+		return Document{}, fmt.Errorf("validationutil: unsupported document type %T", doc)
+	}
+	return Document{value: v}, nil
+}
+
+// Value returns the value of field and whether the document has it
+func (d Document) Value(field string) (any, bool) {
+	v, ok := d.field(field)
+	if !ok || !v.CanInterface() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+// Present reports whether field is set to a non-empty value
+// nil, zero values ("", 0, false), empty slices and maps and nil pointers count as absent.
+func (d Document) Present(field string) bool {
+	v, ok := d.field(field)
+	if !ok {
+		return false
+	}
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() > 0
+	case reflect.Invalid:
+		return false
+	}
+	return !v.IsZero()
+}
+
+// field finds the reflect.Value of a map key or struct field
+func (d Document) field(name string) (reflect.Value, bool) {
+	switch d.value.Kind() {
+	case reflect.Map:
+		v := d.value.MapIndex(reflect.ValueOf(name).Convert(d.value.Type().Key()))
+		return v, v.IsValid()
+	case reflect.Struct:
+		t := d.value.Type()
+		fallback := -1
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if tag == name {
+				return d.value.Field(i), true
+			}
+			if f.Name == name && tag != "-" && fallback < 0 {
+				fallback = i
+			}
+		}
+		if fallback >= 0 {
+			return d.value.Field(fallback), true
+		}
+	}
+	return reflect.Value{}, false
+}
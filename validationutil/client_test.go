@@ -0,0 +1,164 @@
+package validationutil
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mustanish/common-utils/v2/errorutil"
+)
+
+type payment struct {
+	Type       string  `json:"type"`
+	CardNumber string  `json:"card_number,omitempty"`
+	IBAN       string  `json:"iban"`
+	Email      *string `json:"email"`
+	Phone      string
+	Tags       []string `json:"tags"`
+	Internal   string   `json:"-"`
+	secret     string
+}
+
+func fieldNames(err error) []string {
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		return nil
+	}
+	names := make([]string, len(verr.Fields))
+	for i, f := range verr.Fields {
+		names[i] = f.Field
+	}
+	return names
+}
+
+func TestValidate(t *testing.T) {
+	email := "a@example.com"
+	empty := ""
+
+	tests := []struct {
+		name  string
+		doc   any
+		rules []Rule
+		want  []string
+	}{
+		{"required present", map[string]any{"name": "x"}, []Rule{Required("name")}, nil},
+		{"required missing and empty", map[string]any{"name": ""}, []Rule{Required("name", "age")}, []string{"name", "age"}},
+		{"required nil value", map[string]any{"name": nil}, []Rule{Required("name")}, []string{"name"}},
+		{"required empty slice", map[string]any{"tags": []any{}}, []Rule{Required("tags")}, []string{"tags"}},
+
+		{"required if matches", map[string]any{"type": "card"}, []Rule{RequiredIf("type", "card", "card_number", "expiry")}, []string{"card_number", "expiry"}},
+		{"required if satisfied", map[string]any{"type": "card", "card_number": "4242"}, []Rule{RequiredIf("type", "card", "card_number")}, nil},
+		{"required if other value", map[string]any{"type": "bank"}, []Rule{RequiredIf("type", "card", "card_number")}, nil},
+		{"required if field absent", map[string]any{}, []Rule{RequiredIf("type", "card", "card_number")}, nil},
+		{"required if json number", map[string]any{"version": float64(2)}, []Rule{RequiredIf("version", 2, "schema")}, []string{"schema"}},
+		{"required if bool", map[string]any{"shipping": true}, []Rule{RequiredIf("shipping", true, "address")}, []string{"address"}},
+
+		{"required with present", map[string]any{"password": "x"}, []Rule{RequiredWith("password", "password_confirm")}, []string{"password_confirm"}},
+		{"required with absent", map[string]any{}, []Rule{RequiredWith("password", "password_confirm")}, nil},
+
+		{"exclusive one", map[string]any{"email": "a"}, []Rule{MutuallyExclusive("email", "phone")}, nil},
+		{"exclusive none", map[string]any{}, []Rule{MutuallyExclusive("email", "phone")}, nil},
+		{"exclusive both", map[string]any{"email": "a", "phone": "1"}, []Rule{MutuallyExclusive("email", "phone")}, []string{"email", "phone"}},
+		{"exclusive empty ignored", map[string]any{"email": "a", "phone": ""}, []Rule{MutuallyExclusive("email", "phone")}, nil},
+
+		{"at least one present", map[string]any{"phone": "1"}, []Rule{AtLeastOneOf("email", "phone")}, nil},
+		{"at least one missing", map[string]any{"name": "x"}, []Rule{AtLeastOneOf("email", "phone")}, []string{"email", "phone"}},
+
+		{"exactly one", map[string]any{"email": "a"}, []Rule{ExactlyOneOf("email", "phone")}, nil},
+		{"exactly one missing", map[string]any{}, []Rule{ExactlyOneOf("email", "phone")}, []string{"email", "phone"}},
+		{"exactly one both", map[string]any{"email": "a", "phone": "1"}, []Rule{ExactlyOneOf("email", "phone")}, []string{"email", "phone"}},
+
+		{"multiple rules collected", map[string]any{"type": "card", "email": "a", "phone": "1"},
+			[]Rule{RequiredIf("type", "card", "card_number"), MutuallyExclusive("email", "phone")},
+			[]string{"card_number", "email", "phone"}},
+		{"string map", map[string]string{"type": "card"}, []Rule{RequiredIf("type", "card", "card_number")}, []string{"card_number"}},
+
+		{"struct json tags", payment{Type: "card"}, []Rule{RequiredIf("type", "card", "card_number")}, []string{"card_number"}},
+		{"struct pointer", &payment{Type: "card", CardNumber: "4242"}, []Rule{RequiredIf("type", "card", "card_number")}, nil},
+		{"struct nil pointer field", payment{}, []Rule{Required("email")}, []string{"email"}},
+		{"struct pointer field set", payment{Email: &email}, []Rule{Required("email")}, nil},
+		{"struct pointer to empty", payment{Email: &empty}, []Rule{Required("email")}, []string{"email"}},
+		{"struct go field name", payment{Phone: "1", Email: &email}, []Rule{MutuallyExclusive("email", "Phone")}, []string{"email", "Phone"}},
+		{"struct ignored and unexported", payment{Internal: "x", secret: "y"}, []Rule{AtLeastOneOf("Internal", "secret")}, []string{"Internal", "secret"}},
+		{"struct slice", payment{Tags: []string{"a"}}, []Rule{Required("tags")}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.doc, tt.rules...)
+			if got := fieldNames(err); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() fields = %v, want %v (err: %v)", got, tt.want, err)
+			}
+			if tt.want == nil && err != nil {
+				t.Errorf("Validate() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestValidate_DecodedJSON(t *testing.T) {
+	var doc map[string]any
+	if err := json.Unmarshal([]byte(`{"type":"card","email":"a@example.com","phone":"555"}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+	err := Validate(doc,
+		RequiredIf("type", "card", "card_number"),
+		MutuallyExclusive("email", "phone"),
+	)
+	if !errors.Is(err, ErrValidation) {
+		t.Fatalf("Validate() = %v, want ErrValidation", err)
+	}
+	want := "validation failed: card_number: is required when type is card; email: cannot be combined with phone; phone: cannot be combined with email"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	util := errorutil.NewErrorUtil(nil)
+	if code := util.CodeOf(err); code != errorutil.CodeInvalidArgument {
+		t.Errorf("CodeOf() = %s, want %s", code, errorutil.CodeInvalidArgument)
+	}
+	var fe errorutil.FieldErrorer
+	if !errors.As(err, &fe) || len(fe.FieldErrors()) != 3 {
+		t.Errorf("FieldErrors() = %v, want 3 field errors", fe)
+	}
+}
+
+func TestValidate_UnsupportedDocument(t *testing.T) {
+	var nilMap *map[string]any
+	tests := []struct {
+		name string
+		doc  any
+	}{
+		{"nil", nil},
+		{"nil pointer", nilMap},
+		{"int", 42},
+		{"int keyed map", map[int]string{1: "a"}},
+		{"slice", []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.doc, Required("a"))
+			if err == nil || errors.Is(err, ErrValidation) {
+				t.Errorf("Validate() = %v, want unsupported document error", err)
+			}
+		})
+	}
+}
+
+func TestDocument_Value(t *testing.T) {
+	doc, err := NewDocument(payment{Type: "card"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := doc.Value("type"); !ok || v != "card" {
+		t.Errorf("Value(type) = %v, %v, want card, true", v, ok)
+	}
+	if _, ok := doc.Value("missing"); ok {
+		t.Error("Value(missing) should report false")
+	}
+	if _, ok := doc.Value("Internal"); ok {
+		t.Error("Value() should skip fields tagged json:\"-\"")
+	}
+}
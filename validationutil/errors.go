@@ -0,0 +1,42 @@
+package validationutil
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/mustanish/common-utils/v2/errorutil"
+)
+
+// ErrValidation is wrapped by every ValidationError returned by Validate
+var ErrValidation = errors.New("validation failed")
+
+// ValidationError lists the rule violations found by Validate
+// It implements errorutil.Coder (INVALID_ARGUMENT) and errorutil.FieldErrorer, so errorutil's WriteError
+// responds 400 with the fields listed.
+type ValidationError struct {
+	Fields []errorutil.FieldError
+}
+
+// Error implements the error interface for ValidationError
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return ErrValidation.Error() + ": " + strings.Join(parts, "; ")
+}
+
+// Unwrap returns ErrValidation
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// ErrorCode implements errorutil.Coder
+func (e *ValidationError) ErrorCode() errorutil.Code {
+	return errorutil.CodeInvalidArgument
+}
+
+// FieldErrors implements errorutil.FieldErrorer
+func (e *ValidationError) FieldErrors() []errorutil.FieldError {
+	return e.Fields
+}
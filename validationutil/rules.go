@@ -0,0 +1,128 @@
+package validationutil
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mustanish/common-utils/v2/errorutil"
+)
+
+// Required reports every field that is missing or empty
+func Required(fields ...string) Rule {
+	return func(doc Document) []errorutil.FieldError {
+		var errs []errorutil.FieldError
+		for _, field := range fields {
+			if !doc.Present(field) {
+				errs = append(errs, errorutil.FieldError{Field: field, Message: "is required"})
+			}
+		}
+		return errs
+	}
+}
+
+// RequiredIf requires fields when field equals value, e.g. RequiredIf("type", "card", "card_number")
+// Values are compared by their formatted form, so the float64 1 decoded from JSON equals the int 1.
+func RequiredIf(field string, value any, required ...string) Rule {
+	return func(doc Document) []errorutil.FieldError {
+		actual, ok := doc.Value(field)
+		if !ok || !equal(actual, value) {
+			return nil
+		}
+		var errs []errorutil.FieldError
+		for _, name := range required {
+			if !doc.Present(name) {
+				errs = append(errs, errorutil.FieldError{Field: name, Message: fmt.Sprintf("is required when %s is %v", field, value)})
+			}
+		}
+		return errs
+	}
+}
+
+// RequiredWith requires fields whenever field is present, e.g. RequiredWith("password", "password_confirm")
+func RequiredWith(field string, required ...string) Rule {
+	return func(doc Document) []errorutil.FieldError {
+		if !doc.Present(field) {
+			return nil
+		}
+		var errs []errorutil.FieldError
+		for _, name := range required {
+			if !doc.Present(name) {
+				errs = append(errs, errorutil.FieldError{Field: name, Message: fmt.Sprintf("is required with %s", field)})
+			}
+		}
+		return errs
+	}
+}
+
+// MutuallyExclusive allows at most one of fields to be present
+// Every present field is reported, so a client sees all the fields it has to choose between.
+func MutuallyExclusive(fields ...string) Rule {
+	return func(doc Document) []errorutil.FieldError {
+		present := presentFields(doc, fields)
+		if len(present) < 2 {
+			return nil
+		}
+		errs := make([]errorutil.FieldError, len(present))
+		for i, name := range present {
+			errs[i] = errorutil.FieldError{Field: name, Message: "cannot be combined with " + joinOthers(present, name)}
+		}
+		return errs
+	}
+}
+
+// AtLeastOneOf requires one or more of fields to be present; the violation is reported on every field
+func AtLeastOneOf(fields ...string) Rule {
+	return func(doc Document) []errorutil.FieldError {
+		if len(presentFields(doc, fields)) > 0 {
+			return nil
+		}
+		return groupError(fields, "at least one of "+strings.Join(fields, ", ")+" is required")
+	}
+}
+
+// ExactlyOneOf requires exactly one of fields to be present, combining AtLeastOneOf and MutuallyExclusive
+func ExactlyOneOf(fields ...string) Rule {
+	atLeastOne, exclusive := AtLeastOneOf(fields...), MutuallyExclusive(fields...)
+	return func(doc Document) []errorutil.FieldError {
+		if errs := atLeastOne(doc); errs != nil {
+			return errs
+		}
+		return exclusive(doc)
+	}
+}
+
+// presentFields returns the fields that are present, in the given order
+func presentFields(doc Document, fields []string) []string {
+	var present []string
+	for _, field := range fields {
+		if doc.Present(field) {
+			present = append(present, field)
+		}
+	}
+	return present
+}
+
+// groupError reports the same message on every field of a group
+func groupError(fields []string, message string) []errorutil.FieldError {
+	errs := make([]errorutil.FieldError, len(fields))
+	for i, field := range fields {
+		errs[i] = errorutil.FieldError{Field: field, Message: message}
+	}
+	return errs
+}
+
+// joinOthers lists the fields other than exclude
+func joinOthers(fields []string, exclude string) string {
+	others := make([]string, 0, len(fields)-1)
+	for _, field := range fields {
+		if field != exclude {
+			others = append(others, field)
+		}
+	}
+	return strings.Join(others, ", ")
+}
+
+// equal compares a document value with an expected one by their formatted form
+func equal(actual, expected any) bool {
+	return fmt.Sprint(actual) == fmt.Sprint(expected)
+}